@@ -0,0 +1,317 @@
+package appapi
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ActionEventType is the state of a scheduled action on a system
+type ActionEventType string
+
+// The events of a scheduled action
+const (
+	ActionQueued    ActionEventType = "queued"
+	ActionPickedUp  ActionEventType = "picked up"
+	ActionCompleted ActionEventType = "completed"
+	ActionFailed    ActionEventType = "failed"
+)
+
+// ActionEvent reports that a scheduled action changed its state on a system
+type ActionEvent struct {
+	Type       ActionEventType
+	ActionID   int
+	ActionName string
+	SystemID   int
+	SystemName string
+	Message    string
+	Time       time.Time
+}
+
+// ActionWatcher tracks scheduled actions, e.g. patch installations, and emits an event for every
+// state change of an action on a system. Each poll lists the actions in progress once, the systems
+// of an action are only requested when its counters changed.
+type ActionWatcher struct {
+	sessioncookie string
+	susemgr       string
+	interval      time.Duration
+	verbose       bool
+	opts          []Option
+
+	mu      sync.Mutex
+	actions map[int]*watchedAction
+	err     error
+}
+
+type watchedAction struct {
+	name     string
+	counts   actionCounts
+	polled   bool
+	done     bool
+	systems  map[int]ActionEventType
+	sysnames map[int]string
+	pending  []ActionEvent
+}
+
+type actionCounts struct {
+	InProgress int `json:"inProgressSystems"`
+	Completed  int `json:"completedSystems"`
+	Failed     int `json:"failedSystems"`
+}
+
+type scheduledAction struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	actionCounts
+}
+
+type actionSystem struct {
+	ID      int    `json:"server_id"`
+	Name    string `json:"server_name"`
+	Message string `json:"message"`
+}
+
+// NewActionWatcher creates a watcher polling the SUSE Manager every interval for the actions
+func NewActionWatcher(sessioncookie, susemgr string, interval time.Duration, verbose bool, actionIDs ...int) *ActionWatcher {
+	w := &ActionWatcher{
+		sessioncookie: sessioncookie,
+		susemgr:       susemgr,
+		interval:      interval,
+		verbose:       verbose,
+		actions:       map[int]*watchedAction{},
+	}
+	w.Add(actionIDs...)
+	return w
+}
+
+// Add tracks more actions, also while the watcher is running
+func (w *ActionWatcher) Add(actionIDs ...int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range actionIDs {
+		if _, ok := w.actions[id]; !ok {
+			w.actions[id] = &watchedAction{systems: map[int]ActionEventType{}, sysnames: map[int]string{}}
+		}
+	}
+}
+
+// Err returns the error of the last failed poll, nil if it succeeded
+func (w *ActionWatcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Watch polls until all actions are completed or failed on all systems or the context is canceled.
+// The events are sent on the returned channel, which is closed when watching ends. Failed polls are
+// retried in the next interval, see Err.
+func (w *ActionWatcher) Watch(ctx context.Context) <-chan ActionEvent {
+	events := make(chan ActionEvent)
+
+	go func() {
+		defer close(events)
+
+		ticker := DefaultClock.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			pending, done, err := w.poll()
+
+			w.mu.Lock()
+			w.err = err
+			w.mu.Unlock()
+			if err != nil && w.verbose {
+				logDebugf("SUMAAPI ActionWatcher: poll failed: %v", err)
+			}
+
+			for _, event := range pending {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if done {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.Chan():
+			}
+		}
+	}()
+
+	return events
+}
+
+// poll returns the new events and if all actions are finished
+func (w *ActionWatcher) poll() (events []ActionEvent, done bool, err error) {
+	var inProgress []scheduledAction
+	if err := sumaListInProgressActions(w.sessioncookie, w.susemgr, &inProgress, w.verbose, w.opts...); err != nil {
+		return nil, false, err
+	}
+	running := make(map[int]scheduledAction, len(inProgress))
+	for _, a := range inProgress {
+		running[a.ID] = a
+	}
+
+	w.mu.Lock()
+	ids := make([]int, 0, len(w.actions))
+	for id := range w.actions {
+		ids = append(ids, id)
+	}
+	w.mu.Unlock()
+
+	done = true
+	for _, id := range sortedInts(ids) {
+		w.mu.Lock()
+		action := w.actions[id]
+		w.mu.Unlock()
+
+		if action.done {
+			continue
+		}
+		if err := w.pollAction(id, action, running); err != nil {
+			return events, false, err
+		}
+		events = append(events, action.pending...)
+		action.pending = nil
+		if !action.done {
+			done = false
+		}
+	}
+
+	return events, done, nil
+}
+
+// pollAction updates the state of the systems of an action
+func (w *ActionWatcher) pollAction(id int, action *watchedAction, running map[int]scheduledAction) error {
+	a, isRunning := running[id]
+	if !isRunning {
+		// the action is finished, a last look at the results
+		if err := w.updateSystems(id, action, "listCompletedSystems", ActionCompleted); err != nil {
+			return err
+		}
+		if err := w.updateSystems(id, action, "listFailedSystems", ActionFailed); err != nil {
+			return err
+		}
+		action.done = true
+		return nil
+	}
+
+	action.name = a.Name
+	first := !action.polled
+	changed := first || a.actionCounts != action.counts
+	action.counts = a.actionCounts
+	action.polled = true
+
+	if changed && a.InProgress > 0 {
+		if err := w.updateSystems(id, action, "listInProgressSystems", ActionQueued); err != nil {
+			return err
+		}
+	}
+	if (first && a.Completed > 0) || a.Completed != action.countOf(ActionCompleted) {
+		if err := w.updateSystems(id, action, "listCompletedSystems", ActionCompleted); err != nil {
+			return err
+		}
+	}
+	if (first && a.Failed > 0) || a.Failed != action.countOf(ActionFailed) {
+		if err := w.updateSystems(id, action, "listFailedSystems", ActionFailed); err != nil {
+			return err
+		}
+	}
+
+	// queued systems are asked if they picked up the action
+	for _, sid := range sortedInts(action.systemsIn(ActionQueued)) {
+		status, err := sumaGetEventStatus(w.sessioncookie, w.susemgr, sid, id, w.verbose, w.opts...)
+		if err != nil {
+			return err
+		}
+		if status == "Picked Up" {
+			action.emit(id, sid, ActionPickedUp, "")
+		}
+	}
+
+	return nil
+}
+
+// updateSystems requests the systems of an action in a state and emits an event for every new system
+func (w *ActionWatcher) updateSystems(id int, action *watchedAction, method string, state ActionEventType) error {
+	var systems []actionSystem
+	if err := sumaListActionSystems(w.sessioncookie, w.susemgr, method, id, &systems, w.verbose, w.opts...); err != nil {
+		return err
+	}
+	for _, s := range systems {
+		action.sysnames[s.ID] = s.Name
+		current, seen := action.systems[s.ID]
+		if seen && (current == state || (state == ActionQueued && current == ActionPickedUp)) {
+			continue
+		}
+		action.emit(id, s.ID, state, s.Message)
+	}
+	return nil
+}
+
+func (a *watchedAction) emit(id, sid int, state ActionEventType, message string) {
+	a.systems[sid] = state
+	a.pending = append(a.pending, ActionEvent{
+		Type:       state,
+		ActionID:   id,
+		ActionName: a.name,
+		SystemID:   sid,
+		SystemName: a.sysnames[sid],
+		Message:    message,
+		Time:       time.Now(),
+	})
+}
+
+func (a *watchedAction) systemsIn(state ActionEventType) []int {
+	var sids []int
+	for sid, s := range a.systems {
+		if s == state {
+			sids = append(sids, sid)
+		}
+	}
+	return sids
+}
+
+func (a *watchedAction) countOf(state ActionEventType) int {
+	return len(a.systemsIn(state))
+}
+
+func sortedInts(s []int) []int {
+	slices.Sort(s)
+	return s
+}
+
+func sumaListInProgressActions(sessioncookie, susemgr string, actions *[]scheduledAction, verbose bool, opts ...Option) error {
+	return sumaGet(sessioncookie, susemgr, "schedule/listInProgressActions", nil, actions, verbose, opts...)
+}
+
+func sumaListActionSystems(sessioncookie, susemgr, method string, actionID int, systems *[]actionSystem, verbose bool, opts ...Option) error {
+	query := url.Values{"actionId": {strconv.Itoa(actionID)}}
+	return sumaGet(sessioncookie, susemgr, "schedule/"+method, query, systems, verbose, opts...)
+}
+
+// sumaGetEventStatus returns the status of an action on a system, e.g. Queued or Picked Up
+func sumaGetEventStatus(sessioncookie, susemgr string, sid, actionID int, verbose bool, opts ...Option) (status string, err error) {
+	var event struct {
+		Status string `json:"status"`
+	}
+	query := url.Values{"sid": {strconv.Itoa(sid)}, "eid": {strconv.Itoa(actionID)}}
+	err = sumaGet(sessioncookie, susemgr, "system/getEventDetails", query, &event, verbose, opts...)
+	return event.Status, err
+}
+
+// WatchActions emits the events of scheduled actions until they are finished, see ActionWatcher
+func (c *SumaClient) WatchActions(ctx context.Context, interval time.Duration, actionIDs ...int) (*ActionWatcher, <-chan ActionEvent) {
+	verbose, opts := c.options(nil)
+	w := NewActionWatcher(c.SessionCookie(), c.URL, interval, verbose, actionIDs...)
+	w.opts = opts
+	return w, w.Watch(ctx)
+}
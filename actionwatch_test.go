@@ -0,0 +1,125 @@
+package appapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestActionWatcher(t *testing.T) {
+	// poll 1: both systems queued, 2: host1 picked up, 3: host1 completed and host2 picked up,
+	// 4: the action is finished with host2 failed
+	poll := 0
+	inProgress := map[int]string{
+		1: `[{"id": 7, "name": "Patch Update", "inProgressSystems": 2, "completedSystems": 0, "failedSystems": 0}]`,
+		2: `[{"id": 7, "name": "Patch Update", "inProgressSystems": 2, "completedSystems": 0, "failedSystems": 0}]`,
+		3: `[{"id": 7, "name": "Patch Update", "inProgressSystems": 1, "completedSystems": 1, "failedSystems": 0}]`,
+		4: `[]`,
+	}
+	status := map[int]map[string]string{
+		1: {"1": "Queued", "2": "Queued"},
+		2: {"1": "Picked Up", "2": "Queued"},
+		3: {"2": "Picked Up"},
+	}
+	systemLists := map[string]map[int]string{
+		"listInProgressSystems": {
+			1: `[{"server_id": 1, "server_name": "host1"}, {"server_id": 2, "server_name": "host2"}]`,
+			3: `[{"server_id": 2, "server_name": "host2"}]`,
+		},
+		"listCompletedSystems": {
+			3: `[{"server_id": 1, "server_name": "host1"}]`,
+			4: `[{"server_id": 1, "server_name": "host1"}]`,
+		},
+		"listFailedSystems": {
+			4: `[{"server_id": 2, "server_name": "host2", "message": "zypper failed"}]`,
+		},
+	}
+	var calls []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/schedule/listInProgressActions", func(w http.ResponseWriter, r *http.Request) {
+		poll++
+		fmt.Fprintf(w, `{"success": true, "result": %s}`, inProgress[poll])
+	})
+	mux.HandleFunc("/rhn/manager/api/schedule/{method}", func(w http.ResponseWriter, r *http.Request) {
+		method := r.PathValue("method")
+		calls = append(calls, fmt.Sprintf("%d %s", poll, method))
+		result, ok := systemLists[method][poll]
+		if !ok {
+			t.Errorf("unexpected call %s in poll %d", method, poll)
+			result = `[]`
+		}
+		fmt.Fprintf(w, `{"success": true, "result": %s}`, result)
+	})
+	mux.HandleFunc("/rhn/manager/api/system/getEventDetails", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("eid") != "7" {
+			t.Errorf("eid = %s, want 7", r.URL.Query().Get("eid"))
+		}
+		fmt.Fprintf(w, `{"success": true, "result": {"status": "%s"}}`, status[poll][r.URL.Query().Get("sid")])
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	w := NewActionWatcher("cookie", server.URL, time.Millisecond, false, 7)
+	var got []string
+	for event := range w.Watch(ctx) {
+		got = append(got, fmt.Sprintf("%s %s %s", event.SystemName, event.Type, event.Message))
+		if event.ActionID != 7 || event.ActionName != "Patch Update" {
+			t.Errorf("event = %+v, want action 7 Patch Update", event)
+		}
+	}
+
+	want := []string{
+		"host1 queued ",
+		"host2 queued ",
+		"host1 picked up ",
+		"host1 completed ",
+		"host2 picked up ",
+		"host2 failed zypper failed",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+	if err := w.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+
+	// the systems are only listed when the counters changed
+	wantCalls := []string{
+		"1 listInProgressSystems",
+		"3 listInProgressSystems",
+		"3 listCompletedSystems",
+		"4 listCompletedSystems",
+		"4 listFailedSystems",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %q, want %q", calls, wantCalls)
+	}
+}
+
+func TestActionWatcherCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := NewActionWatcher("cookie", server.URL, time.Millisecond, false, 1)
+	events := w.Watch(ctx)
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	for range events {
+		t.Errorf("got event from failing server")
+	}
+	if w.Err() == nil {
+		t.Errorf("Err() = nil, want HTTP error")
+	}
+}
@@ -0,0 +1,114 @@
+package appapi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// CVEPatchStatus is the state of a system or image regarding a CVE
+type CVEPatchStatus string
+
+// The patch states of the CVE audit
+const (
+	CVEAffectedFullPatchApplicable    CVEPatchStatus = "AFFECTED_FULL_PATCH_APPLICABLE"
+	CVEAffectedPartialPatchApplicable CVEPatchStatus = "AFFECTED_PARTIAL_PATCH_APPLICABLE"
+	CVEAffectedPatchInapplicable      CVEPatchStatus = "AFFECTED_PATCH_INAPPLICABLE"
+	CVEAffectedPatchUnavailable       CVEPatchStatus = "AFFECTED_PATCH_UNAVAILABLE"
+	CVENotAffected                    CVEPatchStatus = "NOT_AFFECTED"
+	CVEPatched                        CVEPatchStatus = "PATCHED"
+)
+
+// Affected reports if the system or image is affected by the CVE
+func (s CVEPatchStatus) Affected() bool {
+	return s != CVENotAffected && s != CVEPatched
+}
+
+// The kinds of the audited targets
+const (
+	CVETargetSystem = "system"
+	CVETargetImage  = "image"
+)
+
+// CVEAuditResult is the state of a system or an image regarding a CVE
+type CVEAuditResult struct {
+	CVE              string         `json:"cve"`
+	Target           string         `json:"target"`
+	ID               int            `json:"id"`
+	PatchStatus      CVEPatchStatus `json:"patchStatus" output:"Status"`
+	ChannelLabels    []string       `json:"channelLabels,omitempty" output:"Channels"`
+	ErrataAdvisories []string       `json:"errataAdvisories,omitempty" output:"Errata"`
+}
+
+var cvePattern = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// sumaAuditByPatchStatus calls audit.listSystemsByPatchStatus or audit.listImagesByPatchStatus
+func sumaAuditByPatchStatus(sessioncookie, susemgr, target, cve string, statuses []CVEPatchStatus, verbose bool, opts ...Option) (results []CVEAuditResult, err error) {
+	if !cvePattern.MatchString(cve) {
+		return nil, &ValidationError{Payload: "CVE audit", Field: "cveIdentifier", Reason: fmt.Sprintf("%q is not a CVE identifier like CVE-2024-1234", cve)}
+	}
+
+	var result []struct {
+		SystemID         int            `json:"system_id"`
+		ImageID          int            `json:"image_id"`
+		PatchStatus      CVEPatchStatus `json:"patch_status"`
+		ChannelLabels    []string       `json:"channel_labels"`
+		ErrataAdvisories []string       `json:"errata_advisories"`
+	}
+
+	method := "audit/listSystemsByPatchStatus"
+	if target == CVETargetImage {
+		method = "audit/listImagesByPatchStatus"
+	}
+	query := url.Values{"cveIdentifier": {cve}}
+	for _, s := range statuses {
+		query.Add("patchStatusLabels", string(s))
+	}
+	if err := sumaGet(sessioncookie, susemgr, method, query, &result, verbose, opts...); err != nil {
+		return nil, err
+	}
+
+	results = make([]CVEAuditResult, 0, len(result))
+	for _, r := range result {
+		id := r.SystemID
+		if target == CVETargetImage {
+			id = r.ImageID
+		}
+		results = append(results, CVEAuditResult{
+			CVE:              cve,
+			Target:           target,
+			ID:               id,
+			PatchStatus:      r.PatchStatus,
+			ChannelLabels:    r.ChannelLabels,
+			ErrataAdvisories: r.ErrataAdvisories,
+		})
+	}
+	return results, nil
+}
+
+// SumaAuditSystemsByPatchStatus returns the systems with one of the patch states regarding the CVE,
+// all states if statuses is empty
+func SumaAuditSystemsByPatchStatus(sessioncookie, susemgr, cve string, statuses []CVEPatchStatus, verbose bool, opts ...Option) (results []CVEAuditResult, err error) {
+	return sumaAuditByPatchStatus(sessioncookie, susemgr, CVETargetSystem, cve, statuses, verbose, opts...)
+}
+
+// SumaAuditImagesByPatchStatus returns the images built by SUSE Manager with one of the patch states
+// regarding the CVE, all states if statuses is empty
+func SumaAuditImagesByPatchStatus(sessioncookie, susemgr, cve string, statuses []CVEPatchStatus, verbose bool, opts ...Option) (results []CVEAuditResult, err error) {
+	return sumaAuditByPatchStatus(sessioncookie, susemgr, CVETargetImage, cve, statuses, verbose, opts...)
+}
+
+// AuditCVE returns the systems and the images with one of the patch states regarding the CVE, so
+// both are screened by the same compliance pipeline
+func (c *SumaClient) AuditCVE(cve string, statuses []CVEPatchStatus, opts ...Option) ([]CVEAuditResult, error) {
+	verbose, opts := c.options(opts)
+	systems, err := SumaAuditSystemsByPatchStatus(c.SessionCookie(), c.URL, cve, statuses, verbose, opts...)
+	if err != nil {
+		return nil, err
+	}
+	images, err := SumaAuditImagesByPatchStatus(c.SessionCookie(), c.URL, cve, statuses, verbose, opts...)
+	if err != nil {
+		return systems, err
+	}
+	return append(systems, images...), nil
+}
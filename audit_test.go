@@ -0,0 +1,50 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAuditCVE(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/audit/listSystemsByPatchStatus", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"system_id": 1, "patch_status": "PATCHED", "channel_labels": ["sles15-sp5-updates"], "errata_advisories": ["SUSE-2024-1"]}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/audit/listImagesByPatchStatus", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query()["patchStatusLabels"]; !reflect.DeepEqual(got, []string{"AFFECTED_FULL_PATCH_APPLICABLE", "PATCHED"}) {
+			t.Errorf("patchStatusLabels = %v", got)
+		}
+		if r.URL.Query().Get("cveIdentifier") != "CVE-2024-3094" {
+			t.Errorf("cveIdentifier = %s", r.URL.Query().Get("cveIdentifier"))
+		}
+		fmt.Fprint(w, `{"success": true, "result": [{"image_id": 5, "patch_status": "AFFECTED_FULL_PATCH_APPLICABLE", "errata_advisories": ["SUSE-2024-1"]}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	results, err := c.AuditCVE("CVE-2024-3094", []CVEPatchStatus{CVEAffectedFullPatchApplicable, CVEPatched})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []CVEAuditResult{
+		{CVE: "CVE-2024-3094", Target: CVETargetSystem, ID: 1, PatchStatus: CVEPatched, ChannelLabels: []string{"sles15-sp5-updates"}, ErrataAdvisories: []string{"SUSE-2024-1"}},
+		{CVE: "CVE-2024-3094", Target: CVETargetImage, ID: 5, PatchStatus: CVEAffectedFullPatchApplicable, ErrataAdvisories: []string{"SUSE-2024-1"}},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("got %+v, want %+v", results, want)
+	}
+	if results[0].PatchStatus.Affected() || !results[1].PatchStatus.Affected() {
+		t.Errorf("unexpected affected states")
+	}
+
+	var verr *ValidationError
+	if _, err := c.AuditCVE("2024-3094", nil); !errors.As(err, &verr) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The kinds of the built-in backends
+const (
+	BackendSuma      = "suma"
+	BackendMeshstack = "meshstack"
+)
+
+// Backend is a management API like SUSE Manager or Meshstack. Additional APIs, e.g. an Uyuni fork or
+// another cloud foundation, are plugged in with RegisterBackend. The typed operations of a backend
+// are the optional interfaces SystemBackend, UserBackend and BuildingBlockBackend.
+type Backend interface {
+	// Kind returns the kind the backend is registered with, e.g. BackendSuma
+	Kind() string
+	// Login starts a new session, e.g. after the session expired
+	Login() error
+	// Call calls an API method, e.g. GET "systemgroup/listAllGroups", with the query and the payload
+	// as JSON body and unmarshals the response into result
+	Call(httpMethod, path string, query url.Values, payload, result any, opts ...Option) error
+	// Close ends the session of the backend
+	Close() error
+}
+
+// SystemBackend is a backend managing systems in system groups
+type SystemBackend interface {
+	Backend
+	SystemGroups(opts ...Option) ([]string, error)
+	GroupSystems(group string, opts ...Option) ([]SystemInfo, error)
+	AddSystem(hostname, group, network string, opts ...Option) (SystemResult, error)
+	DeleteSystem(hostname, network string, opts ...Option) (SystemResult, error)
+}
+
+// UserBackend is a backend managing the users of system groups
+type UserBackend interface {
+	Backend
+	Users(opts ...Option) ([]string, error)
+	AddUser(group, grouppassword string, opts ...Option) (UserResult, error)
+	RemoveUser(group string, opts ...Option) error
+}
+
+// BuildingBlockBackend is a backend managing building blocks
+type BuildingBlockBackend interface {
+	Backend
+	ListBuildingBlocks(projectid string, opts ...Option) ([]BuildingBlockType, error)
+	GetBuildingBlockDetails(UUID string, opts ...Option) (BuildingBlockDetails, error)
+	CreateBuildingBlock(payload []byte, opts ...Option) (string, error)
+	DeleteBuildingBlock(UUID string, opts ...Option) error
+}
+
+var (
+	_ SystemBackend        = (*SumaClient)(nil)
+	_ UserBackend          = (*SumaClient)(nil)
+	_ BuildingBlockBackend = (*MsClient)(nil)
+)
+
+// BackendConfig is the configuration a backend is created with
+type BackendConfig struct {
+	URL         string
+	Credentials CredentialProvider
+	Options     []Option
+	// Settings are backend specific settings, e.g. the organization of an API
+	Settings map[string]string
+}
+
+// BackendFactory creates a backend, it logs in if the backend needs a session
+type BackendFactory func(cfg BackendConfig) (Backend, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{}
+)
+
+func init() {
+	// the clients are returned as nil interface on an error, not as typed nil
+	RegisterBackend(BackendSuma, func(cfg BackendConfig) (Backend, error) {
+		c, err := NewSumaClient(cfg.URL, cfg.Credentials, cfg.Options...)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	})
+	RegisterBackend(BackendMeshstack, func(cfg BackendConfig) (Backend, error) {
+		c, err := NewMsClient(cfg.URL, cfg.Credentials, cfg.Options...)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	})
+}
+
+// RegisterBackend makes a backend available by its kind, e.g. in the init function of the package
+// of the backend. It panics if the kind is registered twice.
+func RegisterBackend(kind string, factory BackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if factory == nil {
+		panic("appapi: RegisterBackend factory is nil")
+	}
+	if _, dup := backends[kind]; dup {
+		panic("appapi: RegisterBackend called twice for backend " + kind)
+	}
+	backends[kind] = factory
+}
+
+// Backends returns the registered kinds, sorted
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	kinds := make([]string, 0, len(backends))
+	for kind := range backends {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// NewBackend creates a backend of a registered kind
+func NewBackend(kind string, cfg BackendConfig) (Backend, error) {
+	backendsMu.RLock()
+	factory, ok := backends[kind]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q, registered are %s", kind, strings.Join(Backends(), ", "))
+	}
+	return factory(cfg)
+}
+
+// Kind returns BackendSuma
+func (c *SumaClient) Kind() string {
+	return BackendSuma
+}
+
+// Call calls a SUMA API method with GET or POST, see Backend
+func (c *SumaClient) Call(httpMethod, path string, query url.Values, payload, result any, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	switch httpMethod {
+	case http.MethodGet, http.MethodPost:
+		return sumaCall(c.SessionCookie(), c.URL, httpMethod, path, payload, result, verbose, opts...)
+	}
+	return fmt.Errorf("SUMA API methods are called with GET or POST, not %s", httpMethod)
+}
+
+// Kind returns BackendMeshstack
+func (c *MsClient) Kind() string {
+	return BackendMeshstack
+}
+
+// Call calls a Meshstack endpoint, see Backend. The media type of meshObject endpoints is derived
+// from the path, e.g. api/meshobjects/meshprojects is called with the meshproject media type.
+func (c *MsClient) Call(httpMethod, path string, query url.Values, payload, result any, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	mediaType := "application/json"
+	if rest, ok := strings.CutPrefix(path, "api/meshobjects/"); ok {
+		collection, _, _ := strings.Cut(rest, "/")
+		mediaType = msMediaType(strings.TrimSuffix(collection, "s"))
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return msCall(c.URL, c.Token(), httpMethod, path, mediaType, payload, result, verbose, opts...)
+}
+
+// Close does nothing, Meshstack access tokens expire on their own. It makes MsClient a Backend.
+func (c *MsClient) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+type fakeBackend struct {
+	cfg BackendConfig
+}
+
+func (b *fakeBackend) Kind() string { return "fake" }
+func (b *fakeBackend) Login() error { return nil }
+func (b *fakeBackend) Close() error { return nil }
+func (b *fakeBackend) Call(httpMethod, path string, query url.Values, payload, result any, opts ...Option) error {
+	*(result.(*string)) = httpMethod + " " + b.cfg.URL + "/" + path
+	return nil
+}
+
+func TestRegisterBackend(t *testing.T) {
+	RegisterBackend("fake", func(cfg BackendConfig) (Backend, error) { return &fakeBackend{cfg: cfg}, nil })
+	defer func() {
+		backendsMu.Lock()
+		delete(backends, "fake")
+		backendsMu.Unlock()
+	}()
+
+	if kinds := Backends(); !slices.Equal(kinds, []string{"fake", BackendMeshstack, BackendSuma}) {
+		t.Errorf("Backends() = %v", kinds)
+	}
+
+	b, err := NewBackend("fake", BackendConfig{URL: "https://api.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	if err := b.Call(http.MethodGet, "things", nil, nil, &got); err != nil || got != "GET https://api.example.com/things" {
+		t.Errorf("Call() = %q, %v", got, err)
+	}
+	if _, ok := b.(SystemBackend); ok {
+		t.Errorf("fake backend is a SystemBackend")
+	}
+
+	if _, err := NewBackend("unknown", BackendConfig{}); err == nil {
+		t.Errorf("NewBackend(unknown) error = nil")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterBackend() twice did not panic")
+		}
+	}()
+	RegisterBackend("fake", func(cfg BackendConfig) (Backend, error) { return nil, nil })
+}
+
+func TestBackendCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rhn/manager/api/systemgroup/getDetails":
+			fmt.Fprintf(w, `{"success": true, "result": {"name": %q}}`, r.URL.Query().Get("systemGroupName"))
+		case "/api/meshobjects/meshprojects/ws.web":
+			if accept := r.Header.Get("Accept"); accept != msMediaType("meshproject") {
+				t.Errorf("Accept = %q", accept)
+			}
+			fmt.Fprint(w, `{"metadata": {"name": "web"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var suma Backend = &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+	var group struct {
+		Name string `json:"name"`
+	}
+	if err := suma.Call(http.MethodGet, "systemgroup/getDetails", url.Values{"systemGroupName": {"web"}}, nil, &group); err != nil || group.Name != "web" {
+		t.Errorf("SUMA Call() = %+v, %v", group, err)
+	}
+	if err := suma.Call(http.MethodDelete, "systemgroup/delete", nil, nil, nil); err == nil {
+		t.Errorf("SUMA Call() with DELETE error = nil")
+	}
+
+	var ms Backend = &MsClient{URL: server.URL, token: "token"}
+	var project struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := ms.Call(http.MethodGet, "api/meshobjects/meshprojects/ws.web", nil, nil, &project); err != nil || project.Metadata.Name != "web" {
+		t.Errorf("Meshstack Call() = %+v, %v", project, err)
+	}
+}
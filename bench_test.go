@@ -0,0 +1,275 @@
+package appapi
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The benchmarks cover the hot paths of a reconciliation of 5,000 systems. Each has a budget on the
+// reference machine (1 core, go 1.24), see `make bench`. A benchmark exceeding its time budget
+// fails, the allocations are checked by TestPerformanceBudgets with every test run. Raise a budget
+// only together with the change which makes it necessary.
+//
+//	BenchmarkPlanGroupMembers        resolve and plan the membership of 5,000 systems   10ms  15,000 allocs
+//	BenchmarkBulk                    run 5,000 operations 10 at a time                  10ms     100 allocs
+//	BenchmarkDecodeGroupSystems      decode a list of 5,000 systems                     20ms   6,000 allocs
+//	BenchmarkListGroupSystems        call, decompress and decode 5,000 systems          40ms
+//	BenchmarkMarshalAddRemoveSystem  validate and marshal a change of 5,000 systems      2ms      10 allocs
+//	BenchmarkEncodeAddRemoveSystem   validate and encode it into a pooled buffer         1ms       1 alloc
+//	BenchmarkEncodeDeleteSystem      encode a system/deleteSystem payload                1µs       1 alloc
+//	BenchmarkMarshalBuildingBlock    validate and marshal a building block of 50 inputs 200µs    150 allocs
+const benchSystems = 5000
+
+// perfBudget is the budget of a benchmark per operation, 0 is not checked
+type perfBudget struct {
+	duration time.Duration
+	allocs   float64
+}
+
+var perfBudgets = map[string]perfBudget{
+	"BenchmarkPlanGroupMembers":       {10 * time.Millisecond, 15000},
+	"BenchmarkBulk":                   {10 * time.Millisecond, 100},
+	"BenchmarkDecodeGroupSystems":     {20 * time.Millisecond, 6000},
+	"BenchmarkListGroupSystems":       {40 * time.Millisecond, 0},
+	"BenchmarkMarshalAddRemoveSystem": {2 * time.Millisecond, 10},
+	"BenchmarkEncodeAddRemoveSystem":  {time.Millisecond, 1},
+	"BenchmarkEncodeDeleteSystem":     {time.Microsecond, 1},
+	"BenchmarkMarshalBuildingBlock":   {200 * time.Microsecond, 150},
+}
+
+// benchOps are the operations of the benchmarks without an HTTP server, the setup is not measured
+var benchOps = map[string]func(tb testing.TB) func(){
+	"BenchmarkPlanGroupMembers":       planGroupMembersOp,
+	"BenchmarkBulk":                   bulkOp,
+	"BenchmarkDecodeGroupSystems":     decodeGroupSystemsOp,
+	"BenchmarkMarshalAddRemoveSystem": marshalAddRemoveSystemOp,
+	"BenchmarkEncodeAddRemoveSystem":  encodeAddRemoveSystemOp,
+	"BenchmarkEncodeDeleteSystem":     encodeDeleteSystemOp,
+	"BenchmarkMarshalBuildingBlock":   marshalBuildingBlockOp,
+}
+
+func benchHostname(id int) string {
+	return fmt.Sprintf("host%05d.example.com", id)
+}
+
+// planGroupMembersOp plans a group of 5,000 systems with 4,500 members and 200 members to remove
+func planGroupMembersOp(tb testing.TB) func() {
+	ids := make(map[string]int, benchSystems)
+	desired := make([]string, 0, benchSystems)
+	for id := 1; id <= benchSystems; id++ {
+		ids[benchHostname(id)] = id
+		desired = append(desired, benchHostname(id))
+	}
+	var current []SystemInfo
+	for id := 1; id <= 4500; id++ {
+		current = append(current, SystemInfo{ID: id, Name: benchHostname(id)})
+	}
+	for id := 9001; id <= 9200; id++ {
+		current = append(current, SystemInfo{ID: id, Name: benchHostname(id)})
+	}
+	api := fakeSumaAPI{
+		systemID: func(hostname string) (int, error) { return ids[hostname], nil },
+		systemIP: func(id int) (string, error) {
+			return "10.0." + strconv.Itoa(id/250) + "." + strconv.Itoa(id%250+1), nil
+		},
+	}
+
+	return func() {
+		report, err := sumaPlanGroupMembers(api, &Plan{}, "cookie", "http://suma", "web", current, desired, "10.0.0.0/16", false)
+		if err != nil || len(report.Added) != 500 || len(report.Removed) != 200 {
+			tb.Fatalf("report = %d added, %d removed, %v", len(report.Added), len(report.Removed), err)
+		}
+	}
+}
+
+func bulkOp(tb testing.TB) func() {
+	targets := make([]string, benchSystems)
+	for i := range targets {
+		targets[i] = benchHostname(i)
+	}
+	return func() {
+		report := Bulk(targets, 10, func(target string) (int, error) { return len(target), nil })
+		if err := report.Err(); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// benchSystemsResponse is the response of listSystemsMinimal with 5,000 systems
+func benchSystemsResponse() []byte {
+	var b strings.Builder
+	b.WriteString(`{"success": true, "result": [`)
+	for id := 1; id <= benchSystems; id++ {
+		if id > 1 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"id": %d, "name": %q, "last_checkin": "Oct 16, 2026, 8:55:02 AM"}`, 1000010000+id, benchHostname(id))
+	}
+	b.WriteString("]}")
+	return []byte(b.String())
+}
+
+func decodeGroupSystemsOp(tb testing.TB) func() {
+	body := benchSystemsResponse()
+	resp := &http.Response{Header: http.Header{}}
+	return func() {
+		systems, err := decodeSumaResponse[[]struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}](resp, "systemgroup/listSystemsMinimal", body)
+		if err != nil || len(systems) != benchSystems {
+			tb.Fatalf("decoded %d systems: %v", len(systems), err)
+		}
+	}
+}
+
+func marshalAddRemoveSystemOp(tb testing.TB) func() {
+	ids := make([]int, benchSystems)
+	for i := range ids {
+		ids[i] = 1000010001 + i
+	}
+	return func() {
+		payload, err := NewSumaApiAddRemoveSystem("web", ids, true)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if _, err := json.Marshal(payload); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// encodeAddRemoveSystemOp builds the payload like sumaAddOrRemoveSystems, compare it with
+// BenchmarkMarshalAddRemoveSystem
+func encodeAddRemoveSystemOp(tb testing.TB) func() {
+	ids := make([]int, benchSystems)
+	for i := range ids {
+		ids[i] = 1000010001 + i
+	}
+	return func() {
+		payload, err := NewSumaApiAddRemoveSystem("web", ids, true)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		buf := getPayloadBuffer(payload.encodedSize())
+		*buf = payload.AppendJSON(*buf)
+		putPayloadBuffer(buf)
+	}
+}
+
+func encodeDeleteSystemOp(tb testing.TB) func() {
+	return func() {
+		buf := getPayloadBuffer(64)
+		*buf = appendDeleteSystem(*buf, 1000010001)
+		if len(*buf) == 0 {
+			tb.Fatal("empty payload")
+		}
+		putPayloadBuffer(buf)
+	}
+}
+
+func marshalBuildingBlockOp(tb testing.TB) func() {
+	inputs := make(map[string]any, 50)
+	for i := range 50 {
+		switch i % 3 {
+		case 0:
+			inputs[fmt.Sprintf("input%02d", i)] = benchHostname(i)
+		case 1:
+			inputs[fmt.Sprintf("input%02d", i)] = i
+		default:
+			inputs[fmt.Sprintf("input%02d", i)] = i%2 == 0
+		}
+	}
+	return func() {
+		p, err := NewMsApiCreateBuildingBlock("1c3c1b1e-6f8e-4b7a-9d39-2f0c7f5a1e10", 1, "clab.web", "web", inputs)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if _, err := p.Marshal(); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// runBenchmark runs the operation and fails if it exceeds the time budget of the benchmark
+func runBenchmark(b *testing.B, op func()) {
+	b.ReportAllocs()
+	for b.Loop() {
+		op()
+	}
+	budget := perfBudgets[b.Name()].duration
+	if perOp := b.Elapsed() / time.Duration(b.N); budget > 0 && b.N > 1 && perOp > budget {
+		b.Errorf("%v per operation exceeds the budget of %v", perOp, budget)
+	}
+}
+
+func BenchmarkPlanGroupMembers(b *testing.B) {
+	runBenchmark(b, planGroupMembersOp(b))
+}
+
+func BenchmarkBulk(b *testing.B) {
+	runBenchmark(b, bulkOp(b))
+}
+
+func BenchmarkDecodeGroupSystems(b *testing.B) {
+	runBenchmark(b, decodeGroupSystemsOp(b))
+}
+
+func BenchmarkListGroupSystems(b *testing.B) {
+	body := benchSystemsResponse()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write(body)
+		gz.Close()
+	}))
+	defer server.Close()
+
+	runBenchmark(b, func() {
+		systems, err := sumaListGroupSystems("cookie", server.URL, "web", false)
+		if err != nil || len(systems) != benchSystems {
+			b.Fatalf("listed %d systems: %v", len(systems), err)
+		}
+	})
+}
+
+func BenchmarkMarshalAddRemoveSystem(b *testing.B) {
+	runBenchmark(b, marshalAddRemoveSystemOp(b))
+}
+
+func BenchmarkEncodeAddRemoveSystem(b *testing.B) {
+	runBenchmark(b, encodeAddRemoveSystemOp(b))
+}
+
+func BenchmarkEncodeDeleteSystem(b *testing.B) {
+	runBenchmark(b, encodeDeleteSystemOp(b))
+}
+
+func BenchmarkMarshalBuildingBlock(b *testing.B) {
+	runBenchmark(b, marshalBuildingBlockOp(b))
+}
+
+// TestPerformanceBudgets checks the allocations of the benchmarks, which unlike their duration do
+// not depend on the machine. The race detector allocates in the instrumented code, so the budgets
+// are not checked with -race.
+func TestPerformanceBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not comparable with the race detector")
+	}
+	for name, newOp := range benchOps {
+		t.Run(name, func(t *testing.T) {
+			op := newOp(t)
+			budget := perfBudgets[name].allocs
+			if allocs := testing.AllocsPerRun(3, op); allocs > budget {
+				t.Errorf("%.0f allocations per operation exceed the budget of %.0f", allocs, budget)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const defaultBulkConcurrency = 10
+
+// BulkResult is the result of an operation on one target
+type BulkResult[T any] struct {
+	Target string
+	Value  T
+	Err    error
+}
+
+// BulkReport collects the results of a bulk operation in the order of the targets
+type BulkReport[T any] struct {
+	Results []BulkResult[T]
+}
+
+// Failed returns the results of the targets where the operation failed
+func (r BulkReport[T]) Failed() []BulkResult[T] {
+	var failed []BulkResult[T]
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Succeeded returns the targets where the operation succeeded
+func (r BulkReport[T]) Succeeded() []string {
+	var succeeded []string
+	for _, result := range r.Results {
+		if result.Err == nil {
+			succeeded = append(succeeded, result.Target)
+		}
+	}
+	return succeeded
+}
+
+// Err returns all errors of the failed targets joined into one error, nil if all succeeded
+func (r BulkReport[T]) Err() error {
+	var errs []error
+	for _, result := range r.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", result.Target, result.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// Bulk runs op for every target with at most concurrency operations at the same time. It does not stop
+// on the first error, the result and error of every target is collected in the report.
+// A concurrency < 1 uses the default of 10.
+func Bulk[T any](targets []string, concurrency int, op func(target string) (T, error)) BulkReport[T] {
+	if concurrency < 1 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	report := BulkReport[T]{Results: make([]BulkResult[T], len(targets))}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				value, err := op(targets[i])
+				report.Results[i] = BulkResult[T]{Target: targets[i], Value: value, Err: err}
+			}
+		}()
+	}
+
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return report
+}
+
+// SumaBulkAddSystems adds the systems to a system group, see SumaAddSystem
+func SumaBulkAddSystems(sessioncookie, susemgr string, hostnames []string, group, network string, concurrency int, verbose bool, opts ...Option) BulkReport[int] {
+	if verbose {
+		Logger().Debug("SUMAAPI SumaBulkAddSystems: add systems", "group", group, "systems", len(hostnames), "concurrency", concurrency)
+	}
+	return Bulk(hostnames, concurrency, func(hostname string) (int, error) {
+		return SumaAddSystem(sessioncookie, susemgr, hostname, group, network, verbose, opts...)
+	})
+}
+
+// SumaBulkDeleteSystems deletes the systems, see SumaDeleteSystem
+func SumaBulkDeleteSystems(sessioncookie, susemgr string, hostnames []string, network string, concurrency int, verbose bool, opts ...Option) BulkReport[int] {
+	if verbose {
+		Logger().Debug("SUMAAPI SumaBulkDeleteSystems: delete systems", "systems", len(hostnames), "concurrency", concurrency)
+	}
+	return Bulk(hostnames, concurrency, func(hostname string) (int, error) {
+		return SumaDeleteSystem(sessioncookie, susemgr, hostname, network, verbose, opts...)
+	})
+}
+
+// SumaBulkApplyErrata schedules the relevant errata of an advisory type on the systems, see
+// SumaApplyRelevantErrata
+func SumaBulkApplyErrata(sessioncookie, susemgr string, hostnames []string, advisoryType string, concurrency int, verbose bool, opts ...Option) BulkReport[ErrataResult] {
+	if verbose {
+		Logger().Debug("SUMAAPI SumaBulkApplyErrata: apply errata", "advisory_type", advisoryType, "systems", len(hostnames), "concurrency", concurrency)
+	}
+	return Bulk(hostnames, concurrency, func(hostname string) (ErrataResult, error) {
+		return SumaApplyRelevantErrata(sessioncookie, susemgr, hostname, advisoryType, verbose, opts...)
+	})
+}
+
+// BulkAddSystems adds the systems to a system group, see SumaBulkAddSystems
+func (c *SumaClient) BulkAddSystems(hostnames []string, group, network string, concurrency int, opts ...Option) BulkReport[SystemResult] {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheSumaGroupSystems + group)
+	report := Bulk(c.Hostnames.NormalizeAll(hostnames), concurrency, func(hostname string) (SystemResult, error) {
+		return sumaAddSystemResult(c.suma(), c.SessionCookie(), c.URL, hostname, group, network, verbose, opts...)
+	})
+	notify(c.Notifier, BulkSummary("add systems to group "+group, report))
+	return report
+}
+
+// BulkDeleteSystems deletes the systems, see SumaBulkDeleteSystems
+func (c *SumaClient) BulkDeleteSystems(hostnames []string, network string, concurrency int, opts ...Option) BulkReport[SystemResult] {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheSumaGroupSystems)
+	report := Bulk(c.Hostnames.NormalizeAll(hostnames), concurrency, func(hostname string) (SystemResult, error) {
+		return sumaDeleteSystemResult(c.suma(), c.SessionCookie(), c.URL, hostname, network, verbose, opts...)
+	})
+	notify(c.Notifier, BulkSummary("delete systems", report))
+	return report
+}
+
+// BulkApplyErrata schedules the relevant errata of an advisory type on the systems, see
+// SumaBulkApplyErrata
+func (c *SumaClient) BulkApplyErrata(hostnames []string, advisoryType string, concurrency int, opts ...Option) BulkReport[ErrataResult] {
+	verbose, opts := c.options(opts)
+	report := Bulk(c.Hostnames.NormalizeAll(hostnames), concurrency, func(hostname string) (ErrataResult, error) {
+		return sumaApplyRelevantErrata(c.suma(), c.SessionCookie(), c.URL, hostname, advisoryType, verbose, opts...)
+	})
+	notify(c.Notifier, BulkSummary("apply "+advisoryType+" errata", report))
+	return report
+}
@@ -0,0 +1,188 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBulk(t *testing.T) {
+	targets := []string{"a", "b", "fail1", "c", "d", "fail2", "e"}
+
+	var running, maxRunning atomic.Int32
+	report := Bulk(targets, 3, func(target string) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if strings.HasPrefix(target, "fail") {
+			return "", errors.New("failed")
+		}
+		return strings.ToUpper(target), nil
+	})
+
+	if got := maxRunning.Load(); got > 3 {
+		t.Errorf("max concurrent operations = %d, want <= 3", got)
+	}
+
+	if len(report.Results) != len(targets) {
+		t.Fatalf("got %d results, want %d", len(report.Results), len(targets))
+	}
+	for i, result := range report.Results {
+		if result.Target != targets[i] {
+			t.Errorf("result %d target = %s, want %s", i, result.Target, targets[i])
+		}
+	}
+
+	if got, want := report.Succeeded(), []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Succeeded() = %v, want %v", got, want)
+	}
+	if got := report.Results[3].Value; got != "C" {
+		t.Errorf("value of c = %q, want C", got)
+	}
+	if got := len(report.Failed()); got != 2 {
+		t.Errorf("len(Failed()) = %d, want 2", got)
+	}
+
+	err := report.Err()
+	if err == nil || !strings.Contains(err.Error(), "fail1: failed") || !strings.Contains(err.Error(), "fail2: failed") {
+		t.Errorf("Err() = %v, want errors of fail1 and fail2", err)
+	}
+}
+
+func TestBulkNoErrors(t *testing.T) {
+	report := Bulk([]string{"a"}, 0, func(target string) (int, error) { return 1, nil })
+	if err := report.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+
+	report = Bulk(nil, 5, func(target string) (int, error) { return 1, nil })
+	if len(report.Results) != 0 {
+		t.Errorf("got %d results for no targets", len(report.Results))
+	}
+}
+
+func TestSumaBulkAddSystems(t *testing.T) {
+	var changes []string
+	systems := map[string]int{"host1": 1, "host2": 2}
+	server := newFakeGroupServer(t, systems, nil, &changes)
+	defer server.Close()
+
+	report := SumaBulkAddSystems("cookie", server.URL, []string{"host1", "unknown", "host2"}, "group", "192.168.1.0/24", 2, false)
+
+	if got, want := report.Succeeded(), []string{"host1", "host2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Succeeded() = %v, want %v", got, want)
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0].Target != "unknown" {
+		t.Errorf("Failed() = %+v, want unknown", failed)
+	}
+
+	sort.Strings(changes)
+	if want := []string{"add=true [1]", "add=true [2]"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+}
+
+func TestSumaBulkAddSystemsLookupFailure(t *testing.T) {
+	var changes []string
+	systems := map[string]int{"host1": 1, "host2": 2, "broken": 3}
+	mux := newFakeGroupMux(t, systems, nil, &changes)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") == "broken" {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	report := SumaBulkAddSystems("cookie", server.URL, []string{"host1", "broken", "host2"}, "group", "192.168.1.0/24", 2, false)
+
+	if got, want := report.Succeeded(), []string{"host1", "host2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Succeeded() = %v, want %v", got, want)
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0].Target != "broken" || !strings.Contains(failed[0].Err.Error(), "HTTP/503") {
+		t.Errorf("Failed() = %+v, want the status error of broken", failed)
+	}
+}
+
+func TestSumaBulkApplyErrata(t *testing.T) {
+	systems := map[string]int{"host1": 1, "host2": 2, "locked": 3, "patched": 4}
+	var (
+		mu        sync.Mutex
+		scheduled []string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/system/getId", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if name == "faulty" {
+			fmt.Fprint(w, `{"success": false, "message": "internal error"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success": true, "result": [{"id": %d, "name": "%s"}]}`, systems[name], name)
+	})
+	mux.HandleFunc("/rhn/manager/api/system/getDetails", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success": true, "result": {"lock_status": %v}}`, r.URL.Query().Get("sid") == "3")
+	})
+	mux.HandleFunc("/rhn/manager/api/system/getRelevantErrataByType", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("advisoryType"); got != AdvisorySecurity {
+			t.Errorf("advisoryType = %q, want %q", got, AdvisorySecurity)
+		}
+		if r.URL.Query().Get("sid") == "4" {
+			fmt.Fprint(w, `{"success": true, "result": []}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success": true, "result": [{"id": 10%s, "advisory_name": "SUSE-1"}, {"id": 20%s, "advisory_name": "SUSE-2"}]}`, r.URL.Query().Get("sid"), r.URL.Query().Get("sid"))
+	})
+	mux.HandleFunc("/rhn/manager/api/system/scheduleApplyErrata", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Sid       int   `json:"sid"`
+			ErrataIds []int `json:"errataIds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		mu.Lock()
+		scheduled = append(scheduled, fmt.Sprintf("%d %v", payload.Sid, payload.ErrataIds))
+		mu.Unlock()
+		fmt.Fprintf(w, `{"success": true, "result": [%d]}`, 500+payload.Sid)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	report := SumaBulkApplyErrata("cookie", server.URL, []string{"host1", "faulty", "locked", "host2", "patched"}, AdvisorySecurity, 2, false)
+
+	if got, want := report.Succeeded(), []string{"host1", "host2", "patched"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Succeeded() = %v, want %v", got, want)
+	}
+	failed := report.Failed()
+	if len(failed) != 2 || failed[0].Target != "faulty" || failed[1].Target != "locked" || !errors.Is(failed[1].Err, ErrSystemLocked) {
+		t.Errorf("Failed() = %+v, want faulty and locked", failed)
+	}
+	if got, want := report.Results[0].Value, (ErrataResult{SystemID: 1, Hostname: "host1", Errata: 2, ActionIDs: []int{501}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("result of host1 = %+v, want %+v", got, want)
+	}
+	if got := report.Results[4].Value; got.Errata != 0 || got.ActionIDs != nil {
+		t.Errorf("result of patched = %+v, want nothing scheduled", got)
+	}
+
+	sort.Strings(scheduled)
+	if want := []string{"1 [101 201]", "2 [102 202]"}; !reflect.DeepEqual(scheduled, want) {
+		t.Errorf("scheduled = %v, want %v", scheduled, want)
+	}
+}
@@ -0,0 +1,98 @@
+package appapi
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// Channel is a software channel of SUSE Manager
+type Channel struct {
+	ID    int    `json:"id"`
+	Label string `json:"label"`
+	Name  string `json:"name"`
+	Arch  string `json:"arch_name"`
+}
+
+// SystemChannels are the software channels a system is subscribed to. Base is the zero Channel if the
+// system has no base channel.
+type SystemChannels struct {
+	SystemID int       `json:"systemId"`
+	Base     Channel   `json:"base"`
+	Children []Channel `json:"children"`
+}
+
+// ChildLabels returns the sorted labels of the child channels
+func (c SystemChannels) ChildLabels() []string {
+	labels := make([]string, len(c.Children))
+	for i, child := range c.Children {
+		labels[i] = child.Label
+	}
+	slices.Sort(labels)
+	return labels
+}
+
+// ChannelDrift are the differences between the channels of a system and the expected channels
+type ChannelDrift struct {
+	// Base is the label of the actual base channel if it is not the expected one
+	Base string
+	// Missing are the expected child channels the system is not subscribed to
+	Missing []string
+	// Unexpected are the child channels the system is subscribed to but which are not expected
+	Unexpected []string
+}
+
+// Drifted reports if the channels differ from the expected channels
+func (d ChannelDrift) Drifted() bool {
+	return d.Base != "" || len(d.Missing) > 0 || len(d.Unexpected) > 0
+}
+
+// Drift compares the channels with the expected base and child channel labels, e.g. those assigned by
+// the onboarding of the system
+func (c SystemChannels) Drift(base string, children []string) ChannelDrift {
+	var drift ChannelDrift
+	if c.Base.Label != base {
+		drift.Base = c.Base.Label
+		if drift.Base == "" {
+			drift.Base = "none"
+		}
+	}
+
+	actual := c.ChildLabels()
+	for _, label := range children {
+		if !slices.Contains(actual, label) {
+			drift.Missing = append(drift.Missing, label)
+		}
+	}
+	for _, label := range actual {
+		if !slices.Contains(children, label) {
+			drift.Unexpected = append(drift.Unexpected, label)
+		}
+	}
+	slices.Sort(drift.Missing)
+	return drift
+}
+
+// SumaGetSystemChannels returns the base and child channels a system is subscribed to
+func SumaGetSystemChannels(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (channels SystemChannels, err error) {
+	channels.SystemID = systemID
+	query := url.Values{"sid": {strconv.Itoa(systemID)}}
+
+	if err := sumaGet(sessioncookie, susemgr, "system/getSubscribedBaseChannel", query, &channels.Base, verbose, opts...); err != nil {
+		return channels, err
+	}
+	if err := sumaGet(sessioncookie, susemgr, "system/listSubscribedChildChannels", query, &channels.Children, verbose, opts...); err != nil {
+		return channels, err
+	}
+
+	if verbose {
+		Logger().Debug("SUMAAPI SumaGetSystemChannels: subscribed channels", "system_id", systemID, "base", channels.Base.Label, "children", channels.ChildLabels())
+	}
+	return channels, nil
+}
+
+// SystemChannels returns the base and child channels of a system, see SumaGetSystemChannels
+func (c *SumaClient) SystemChannels(systemID int, opts ...Option) (SystemChannels, error) {
+	verbose, opts := c.options(opts)
+	return SumaGetSystemChannels(c.SessionCookie(), c.URL, systemID, verbose, opts...)
+}
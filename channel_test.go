@@ -0,0 +1,48 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSumaGetSystemChannels(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/system/getSubscribedBaseChannel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": {"id": 101, "label": "sle-product-sles15-sp5-pool-x86_64", "name": "SLES15-SP5-Pool", "arch_name": "x86_64"}}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/system/listSubscribedChildChannels", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sid") != "42" {
+			t.Errorf("sid = %s, want 42", r.URL.Query().Get("sid"))
+		}
+		fmt.Fprint(w, `{"success": true, "result": [
+			{"id": 103, "label": "sle-module-basesystem15-sp5-updates-x86_64"},
+			{"id": 102, "label": "sle-manager-tools15-updates-x86_64-sp5"}
+		]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	channels, err := SumaGetSystemChannels("cookie", server.URL, 42, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if channels.Base.ID != 101 || channels.Base.Arch != "x86_64" || len(channels.Children) != 2 {
+		t.Fatalf("unexpected channels %+v", channels)
+	}
+
+	drift := channels.Drift("sle-product-sles15-sp5-pool-x86_64", []string{"sle-module-basesystem15-sp5-updates-x86_64", "sle-module-python3-15-sp5-updates-x86_64"})
+	want := ChannelDrift{
+		Missing:    []string{"sle-module-python3-15-sp5-updates-x86_64"},
+		Unexpected: []string{"sle-manager-tools15-updates-x86_64-sp5"},
+	}
+	if !reflect.DeepEqual(drift, want) || !drift.Drifted() {
+		t.Errorf("got drift %+v, want %+v", drift, want)
+	}
+
+	if drift := (SystemChannels{}).Drift("sle-product-sles15-sp5-pool-x86_64", nil); drift.Base != "none" {
+		t.Errorf("expected a missing base channel to drift, got %+v", drift)
+	}
+}
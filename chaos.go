@@ -0,0 +1,172 @@
+package appapi
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChaosFault is a failure injected by a ChaosTransport
+type ChaosFault string
+
+// The faults of a ChaosTransport
+const (
+	// ChaosTimeout fails the call with a timeout error without sending it
+	ChaosTimeout ChaosFault = "timeout"
+	// ChaosError answers the call with the StatusCode of the transport without sending it
+	ChaosError ChaosFault = "error"
+	// ChaosMalformed sends the call and truncates the body of the response, so it is no valid JSON
+	ChaosMalformed ChaosFault = "malformed"
+	// ChaosSlow sends the call after the Latency of the transport
+	ChaosSlow ChaosFault = "slow"
+)
+
+// ChaosTransport is a transport injecting failures at the given rates, so consumers can test how
+// they handle a degraded SUMA or Meshstack. A rate is the probability between 0 and 1 that a call
+// fails with the fault, at most one fault is injected per call.
+//
+//	chaos := &ChaosTransport{ErrorRate: 0.1, SlowRate: 0.2, Latency: 5 * time.Second}
+//	suma, err := NewSumaClient(url, creds, chaos.Option())
+//
+// The faults are injected below the retries, a StatusCode of 503 exercises the RetryPolicy.
+type ChaosTransport struct {
+	// Base is the transport of the calls, the default transport if nil
+	Base http.RoundTripper
+
+	TimeoutRate   float64
+	ErrorRate     float64
+	MalformedRate float64
+	SlowRate      float64
+
+	// StatusCode is the status of the ChaosError responses, 500 if 0
+	StatusCode int
+	// Latency is the delay of the ChaosSlow calls on the Clock
+	Latency time.Duration
+	// Clock is the clock of the Latency, DefaultClock if nil
+	Clock Clock
+	// Rand returns the random numbers in [0, 1) deciding on the faults, rand.Float64 if nil. A seeded
+	// source makes the faults reproducible.
+	Rand func() float64
+
+	mu       sync.Mutex
+	injected map[ChaosFault]int
+}
+
+// Option returns the option sending the calls through the transport, see WithTransport
+func (t *ChaosTransport) Option() Option {
+	return WithTransport(t)
+}
+
+// Injected returns how often the fault was injected
+func (t *ChaosTransport) Injected(fault ChaosFault) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.injected[fault]
+}
+
+func (t *ChaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = defaultTransport(AllowInsecure)
+	}
+
+	fault := t.fault()
+	if fault == "" {
+		return base.RoundTrip(req)
+	}
+
+	switch fault {
+	case ChaosTimeout:
+		closeRequestBody(req)
+		return nil, fmt.Errorf("chaos %s of %s %s: %w", fault, req.Method, req.URL.Path, os.ErrDeadlineExceeded)
+	case ChaosError:
+		closeRequestBody(req)
+		status := t.StatusCode
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		body := fmt.Sprintf(`{"success": false, "message": "injected %s"}`, fault)
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			StatusCode:    status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"application/json"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	case ChaosSlow:
+		clock := t.Clock
+		if clock == nil {
+			clock = DefaultClock
+		}
+		if err := sleep(req.Context(), clock, t.Latency); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+		return base.RoundTrip(req)
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := readResponseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	// half of the body, a JSON document without its end
+	body = body[:len(body)/2]
+	resp.Header = resp.Header.Clone()
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.Body = io.NopCloser(strings.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// fault draws the fault of a call, empty if the call is not disturbed
+func (t *ChaosTransport) fault() ChaosFault {
+	random := t.Rand
+	if random == nil {
+		random = rand.Float64
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := random()
+	for _, f := range []struct {
+		fault ChaosFault
+		rate  float64
+	}{
+		{ChaosTimeout, t.TimeoutRate},
+		{ChaosError, t.ErrorRate},
+		{ChaosMalformed, t.MalformedRate},
+		{ChaosSlow, t.SlowRate},
+	} {
+		if r < f.rate {
+			if t.injected == nil {
+				t.injected = map[ChaosFault]int{}
+			}
+			t.injected[f.fault]++
+			return f.fault
+		}
+		r -= f.rate
+	}
+	return ""
+}
+
+// closeRequestBody closes the body of a request which is not sent, as a RoundTripper must
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
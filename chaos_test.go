@@ -0,0 +1,137 @@
+package appapi
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// chaosRand returns the numbers in their order
+func chaosRand(numbers ...float64) func() float64 {
+	return func() float64 {
+		r := numbers[0]
+		numbers = numbers[1:]
+		return r
+	}
+}
+
+func TestChaosTransport(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"success": true, "result": "5.0.3"}`))
+	}))
+	defer server.Close()
+
+	chaos := &ChaosTransport{TimeoutRate: 0.1, ErrorRate: 0.1, MalformedRate: 0.1, SlowRate: 0.1}
+	tests := []struct {
+		name    string
+		random  float64
+		fault   ChaosFault
+		calls   int32
+		wantErr string
+	}{
+		{name: "timeout", random: 0.05, fault: ChaosTimeout, calls: 0, wantErr: "timeout"},
+		{name: "error", random: 0.15, fault: ChaosError, calls: 0, wantErr: "500"},
+		{name: "malformed", random: 0.25, fault: ChaosMalformed, calls: 1, wantErr: "unexpected end of JSON"},
+		{name: "slow", random: 0.35, fault: ChaosSlow, calls: 1},
+		{name: "undisturbed", random: 0.45, calls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			chaos.Rand = chaosRand(tt.random)
+			version, err := SumaGetVersion("cookie", server.URL, false, chaos.Option(), WithRetry(RetryPolicy{}))
+			switch {
+			case tt.wantErr == "" && (err != nil || version.Raw != "5.0.3"):
+				t.Errorf("SumaGetVersion() = %v, %v", version, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("SumaGetVersion() error = %v, want %q", err, tt.wantErr)
+			}
+			if n := calls.Load(); n != tt.calls {
+				t.Errorf("calls = %d, want %d", n, tt.calls)
+			}
+			if tt.fault != "" && chaos.Injected(tt.fault) != 1 {
+				t.Errorf("injected %s = %d, want 1", tt.fault, chaos.Injected(tt.fault))
+			}
+		})
+	}
+}
+
+func TestChaosTransportRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"success": true, "result": "5.0.3"}`))
+	}))
+	defer server.Close()
+
+	// the injected 503 is retried and the retry reaches the server
+	chaos := &ChaosTransport{ErrorRate: 0.5, StatusCode: http.StatusServiceUnavailable, Rand: chaosRand(0.1, 0.9)}
+	_, err := SumaGetVersion("cookie", server.URL, false, chaos.Option(), WithRetry(RetryPolicy{Retries: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chaos.Injected(ChaosError) != 1 || calls.Load() != 1 {
+		t.Errorf("injected = %d, calls = %d, want 1 and 1", chaos.Injected(ChaosError), calls.Load())
+	}
+}
+
+func TestChaosTransportSlow(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "result": "5.0.3"}`))
+	}))
+	defer server.Close()
+
+	chaos := &ChaosTransport{SlowRate: 1, Latency: time.Minute, Clock: clock}
+	done := make(chan error, 1)
+	go func() {
+		_, err := SumaGetVersion("cookie", server.URL, false, chaos.Option(), WithTimeout(0))
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	select {
+	case err := <-done:
+		t.Fatalf("the call returned before the latency: %v", err)
+	default:
+	}
+	clock.Advance(time.Minute)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChaosTransportRates(t *testing.T) {
+	chaos := &ChaosTransport{
+		TimeoutRate: 0.1,
+		ErrorRate:   0.2,
+		Base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("not injected")
+		}),
+		Rand: rand.New(rand.NewPCG(1, 2)).Float64,
+	}
+
+	for range 10000 {
+		req, _ := http.NewRequest(http.MethodGet, "http://suma/rhn/manager/api/api/systemVersion", nil)
+		resp, err := chaos.RoundTrip(req)
+		if err == nil {
+			resp.Body.Close()
+		} else if errors.Is(err, os.ErrDeadlineExceeded) == (err.Error() == "not injected") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	for fault, want := range map[ChaosFault]int{ChaosTimeout: 1000, ChaosError: 2000, ChaosMalformed: 0, ChaosSlow: 0} {
+		if n := chaos.Injected(fault); n < want*9/10 || n > want*11/10 {
+			t.Errorf("injected %s = %d, want about %d", fault, n, want)
+		}
+	}
+}
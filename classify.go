@@ -0,0 +1,76 @@
+package appapi
+
+// SystemClassification splits the members of a system group into physical and virtual systems, e.g.
+// to power cycle physical systems through their management controller and virtual systems through
+// their host
+type SystemClassification struct {
+	Group    string       `json:"group"`
+	Physical []SystemInfo `json:"physical"`
+	Virtual  []SystemInfo `json:"virtual"`
+}
+
+// IsVirtual reports if the system with the ID is a virtual member of the group
+func (c SystemClassification) IsVirtual(id int) bool {
+	for _, system := range c.Virtual {
+		if system.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sumaListPhysicalSystems returns the IDs of all systems which are not virtual
+func sumaListPhysicalSystems(sessioncookie, susemgr string, verbose bool, opts ...Option) (ids map[int]bool, err error) {
+	var systems []struct {
+		ID int `json:"id"`
+	}
+	if err := sumaGet(sessioncookie, susemgr, "system/listPhysicalSystems", nil, &systems, verbose, opts...); err != nil {
+		return nil, err
+	}
+	ids = make(map[int]bool, len(systems))
+	for _, s := range systems {
+		ids[s.ID] = true
+	}
+	return ids, nil
+}
+
+// SumaClassifyGroupSystems classifies the members of a system group as physical or virtual
+func SumaClassifyGroupSystems(sessioncookie, susemgr, group string, verbose bool, opts ...Option) (classification SystemClassification, err error) {
+	systems, err := sumaListGroupSystems(sessioncookie, susemgr, group, verbose, opts...)
+	if err != nil {
+		return SystemClassification{Group: group}, err
+	}
+	return sumaClassifySystems(sessioncookie, susemgr, group, systems, verbose, opts...)
+}
+
+func sumaClassifySystems(sessioncookie, susemgr, group string, systems []SystemInfo, verbose bool, opts ...Option) (classification SystemClassification, err error) {
+	classification.Group = group
+
+	physical, err := sumaListPhysicalSystems(sessioncookie, susemgr, verbose, opts...)
+	if err != nil {
+		return classification, err
+	}
+	for _, system := range systems {
+		if physical[system.ID] {
+			classification.Physical = append(classification.Physical, system)
+		} else {
+			classification.Virtual = append(classification.Virtual, system)
+		}
+	}
+
+	if verbose {
+		Logger().Debug("SUMAAPI SumaClassifyGroupSystems: classified systems", "group", group, "physical", len(classification.Physical), "virtual", len(classification.Virtual))
+	}
+	return classification, nil
+}
+
+// ClassifyGroupSystems classifies the members of a system group as physical or virtual, see
+// SumaClassifyGroupSystems
+func (c *SumaClient) ClassifyGroupSystems(group string, opts ...Option) (SystemClassification, error) {
+	verbose, opts := c.options(opts)
+	systems, err := c.GroupSystems(group)
+	if err != nil {
+		return SystemClassification{Group: group}, err
+	}
+	return sumaClassifySystems(c.SessionCookie(), c.URL, group, systems, verbose, opts...)
+}
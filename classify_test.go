@@ -0,0 +1,37 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSumaClassifyGroupSystems(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystemsMinimal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "name": "bm1"}, {"id": 2, "name": "vm1"}, {"id": 3, "name": "vm2"}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/system/listPhysicalSystems", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "name": "bm1"}, {"id": 9, "name": "other"}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	got, err := SumaClassifyGroupSystems("cookie", server.URL, "clab", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := SystemClassification{
+		Group:    "clab",
+		Physical: []SystemInfo{{ID: 1, Name: "bm1"}},
+		Virtual:  []SystemInfo{{ID: 2, Name: "vm1"}, {ID: 3, Name: "vm2"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if !got.IsVirtual(2) || got.IsVirtual(1) {
+		t.Errorf("unexpected IsVirtual results for %+v", got)
+	}
+}
@@ -0,0 +1,406 @@
+package appapi
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// SumaClient is a logged in session to a SUSE Manager. It wraps the Suma* functions, so the
+// caller does not have to pass the server and the session cookie on every call.
+type SumaClient struct {
+	URL      string
+	Networks []string
+	Verbose  bool
+
+	// Hostnames normalizes the hostnames passed to the client, e.g. to append the domain
+	Hostnames HostnameNormalization
+
+	// Version is the product version of the server detected by the client, see Supports
+	Version ServerVersion
+
+	// Notifier is sent the summary of the bulk operations of the client, if set
+	Notifier Notifier
+
+	opts          []Option
+	sessions      *SessionManager
+	ownsSessions  bool
+	mu            sync.RWMutex
+	sessioncookie string
+	cache         *ReadCache
+	// api makes the SUMA calls of the composite operations, sumaHTTP if nil
+	api sumaAPI
+}
+
+// NewSumaClient login to the SUSE Manager with the credentials of the provider. The options apply
+// to every call of the client.
+func NewSumaClient(susemgr string, creds CredentialProvider, opts ...Option) (*SumaClient, error) {
+	if err := validateURL(susemgr, newCallOptions(false, opts).insecure); err != nil {
+		return nil, err
+	}
+	c, err := NewSumaClientWithSessions(NewSessionManager(susemgr, creds, opts...), opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.ownsSessions = true
+	return c, nil
+}
+
+// NewSumaClientWithSessions creates a client using the sessions of a session manager, so several
+// clients, e.g. of parallel workers, share one SUMA session. Close does not end the shared session,
+// the owner of the session manager calls its Logout.
+func NewSumaClientWithSessions(sessions *SessionManager, opts ...Option) (*SumaClient, error) {
+	c := &SumaClient{URL: sessions.susemgr, opts: opts, sessions: sessions}
+	c.Verbose, c.opts = c.options(nil)
+	sessioncookie, err := sessions.Session()
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionCookie(sessioncookie)
+
+	if c.Version, err = SumaGetVersion(sessioncookie, c.URL, c.Verbose, c.opts...); err != nil {
+		logWarnf("could not detect the version of %s, assuming all features are supported: %v", c.URL, err)
+	}
+	return c, nil
+}
+
+// Close ends the session of the client on the server, see SumaLogout. A session shared with other
+// clients is kept, see NewSumaClientWithSessions. The client must not be used after Close.
+func (c *SumaClient) Close() error {
+	if c.sessions != nil {
+		c.setSessionCookie("")
+		if !c.ownsSessions {
+			return nil
+		}
+		return c.sessions.Logout()
+	}
+
+	sessioncookie := c.lastSessionCookie()
+	if sessioncookie == "" {
+		return nil
+	}
+	c.setSessionCookie("")
+	verbose, opts := c.options(nil)
+	return SumaLogout(sessioncookie, c.URL, verbose, opts...)
+}
+
+// Supports returns an error matching ErrUnsupportedByServer if the server version does not support
+// the feature
+func (c *SumaClient) Supports(feature Feature) error {
+	return c.Version.Supports(feature)
+}
+
+// Login creates a new session, e.g. after the session expired
+func (c *SumaClient) Login() error {
+	if c.sessions == nil {
+		return fmt.Errorf("login to %s failed: client has no credentials", c.URL)
+	}
+
+	c.sessions.Invalidate(c.lastSessionCookie())
+	sessioncookie, err := c.sessions.Session()
+	if err != nil {
+		return err
+	}
+	c.setSessionCookie(sessioncookie)
+	return nil
+}
+
+// options returns the verbose setting and the options of a call, the options of the client are
+// applied first
+func (c *SumaClient) options(opts []Option) (bool, []Option) {
+	return withOptions(c.Verbose, c.opts, opts)
+}
+
+// get calls a read-only API method with the options of the client, see sumaGet
+func (c *SumaClient) get(method string, query url.Values, result any) error {
+	verbose, opts := c.options(nil)
+	return sumaGet(c.SessionCookie(), c.URL, method, query, result, verbose, opts...)
+}
+
+// post calls an API method with the options of the client, see sumaPost
+func (c *SumaClient) post(method string, payload, result any) error {
+	verbose, opts := c.options(nil)
+	return sumaPost(c.SessionCookie(), c.URL, method, payload, result, verbose, opts...)
+}
+
+// suma returns the implementation of the SUMA calls
+func (c *SumaClient) suma() sumaAPI {
+	if c.api == nil {
+		return sumaHTTP{}
+	}
+	return c.api
+}
+
+// SessionCookie returns the session cookie of the client. A session which is about to expire is
+// renewed by the session manager.
+func (c *SumaClient) SessionCookie() string {
+	if c.sessions != nil {
+		sessioncookie, err := c.sessions.Session()
+		if err == nil {
+			c.setSessionCookie(sessioncookie)
+			return sessioncookie
+		}
+		logWarnf("could not renew session, use the last session: %v", err)
+	}
+	return c.lastSessionCookie()
+}
+
+// Sessions returns the session manager of the client, nil if the client has none
+func (c *SumaClient) Sessions() *SessionManager {
+	return c.sessions
+}
+
+func (c *SumaClient) lastSessionCookie() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sessioncookie
+}
+
+func (c *SumaClient) setSessionCookie(sessioncookie string) {
+	c.mu.Lock()
+	c.sessioncookie = sessioncookie
+	c.mu.Unlock()
+}
+
+// EnableReadCache caches group and user listings for ttl, see ReadCache
+func (c *SumaClient) EnableReadCache(ttl time.Duration) {
+	c.cache = NewReadCache(ttl)
+}
+
+// ReadCache returns the read cache of the client, nil if caching is disabled
+func (c *SumaClient) ReadCache() *ReadCache {
+	return c.cache
+}
+
+// SystemGroups returns the names of all system groups
+func (c *SumaClient) SystemGroups(opts ...Option) ([]string, error) {
+	verbose, opts := c.options(opts)
+	return cachedRead(c.cache, cacheSumaGroups, func() ([]string, error) {
+		return c.suma().ListSystemGroups(c.SessionCookie(), c.URL, verbose, opts...)
+	})
+}
+
+// Users returns the logins of all users
+func (c *SumaClient) Users(opts ...Option) ([]string, error) {
+	verbose, opts := c.options(opts)
+	return cachedRead(c.cache, cacheSumaUsers, func() ([]string, error) {
+		return c.suma().ListUsers(c.SessionCookie(), c.URL, verbose, opts...)
+	})
+}
+
+// GroupSystems returns the members of a system group
+func (c *SumaClient) GroupSystems(group string, opts ...Option) ([]SystemInfo, error) {
+	verbose, opts := c.options(opts)
+	return cachedRead(c.cache, cacheSumaGroupSystems+group, func() ([]SystemInfo, error) {
+		return sumaListGroupSystems(c.SessionCookie(), c.URL, group, verbose, opts...)
+	})
+}
+
+// AddSystem adds a system to a system group, see SumaAddSystemResult
+func (c *SumaClient) AddSystem(hostname, group, network string, opts ...Option) (SystemResult, error) {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheSumaGroupSystems + group)
+	return sumaAddSystemResult(c.suma(), c.SessionCookie(), c.URL, c.Hostnames.Normalize(hostname), group, network, verbose, opts...)
+}
+
+// DeleteSystem deletes a system, see SumaDeleteSystemResult
+func (c *SumaClient) DeleteSystem(hostname, network string, opts ...Option) (SystemResult, error) {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheSumaGroupSystems)
+	return sumaDeleteSystemResult(c.suma(), c.SessionCookie(), c.URL, c.Hostnames.Normalize(hostname), network, verbose, opts...)
+}
+
+// AddUser adds a user, see SumaAddUserResult
+func (c *SumaClient) AddUser(group, grouppassword string, opts ...Option) (UserResult, error) {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheSumaUsers)
+	return sumaAddUserResult(c.suma(), c.SessionCookie(), group, grouppassword, c.URL, verbose, opts...)
+}
+
+// RemoveUser removes a user and its system group, see SumaRemoveUser
+func (c *SumaClient) RemoveUser(group string, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheSumaUsers, cacheSumaGroups, cacheSumaGroupSystems+group)
+	return sumaRemoveUser(c.suma(), c.SessionCookie(), group, c.URL, verbose, opts...)
+}
+
+// MsClient is a logged in session to the Meshstack API. It wraps the Ms* functions.
+type MsClient struct {
+	URL     string
+	Verbose bool
+
+	// Health is the health of Meshstack probed by NewMsClient
+	Health MsHealth
+
+	opts   []Option
+	creds  CredentialProvider
+	tokens MsTokenSource
+	mu     sync.RWMutex
+	token  string
+	cache  *ReadCache
+	etags  *ETagCache
+}
+
+// NewMsClient login to Meshstack with the credentials of the provider. The options apply to every
+// call of the client.
+func NewMsClient(apiurl string, creds CredentialProvider, opts ...Option) (*MsClient, error) {
+	if err := validateURL(apiurl, newCallOptions(false, opts).insecure); err != nil {
+		return nil, err
+	}
+	return newMsClient(&MsClient{URL: apiurl, opts: opts, creds: creds})
+}
+
+// NewMsClientWithTokenSource creates a client getting its access tokens from the token source
+// instead of the /api/login exchange, e.g. an OIDCTokenSource
+func NewMsClientWithTokenSource(apiurl string, tokens MsTokenSource, opts ...Option) (*MsClient, error) {
+	if err := validateURL(apiurl, newCallOptions(false, opts).insecure); err != nil {
+		return nil, err
+	}
+	return newMsClient(&MsClient{URL: apiurl, opts: opts, tokens: tokens})
+}
+
+func newMsClient(c *MsClient) (*MsClient, error) {
+	c.Verbose, c.opts = c.options(nil)
+	if err := c.Login(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if c.Health, err = MsGetHealth(c.URL, c.Verbose, c.opts...); err != nil {
+		logWarnf("could not get the health of %s: %v", c.URL, err)
+	}
+	return c, nil
+}
+
+// Login gets a new access token, e.g. after the token expired
+func (c *MsClient) Login() error {
+	var (
+		token string
+		err   error
+	)
+	if c.tokens != nil {
+		c.mu.RLock()
+		renew := c.token != ""
+		c.mu.RUnlock()
+		// a source caching its token, e.g. OIDCTokenSource, must not return the expired token again
+		if s, ok := c.tokens.(interface{ Invalidate() }); ok && renew {
+			s.Invalidate()
+		}
+		token, err = c.tokens.Token()
+	} else {
+		verbose, opts := c.options(nil)
+		token, err = MsLoginWithProvider(c.creds, c.URL, verbose, opts...)
+	}
+	if err != nil {
+		return fmt.Errorf("login to %s failed: %v", c.URL, err)
+	}
+
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+	return nil
+}
+
+// options returns the verbose setting and the options of a call, the options of the client are
+// applied first
+func (c *MsClient) options(opts []Option) (bool, []Option) {
+	if c.etags != nil {
+		opts = append([]Option{WithETagCache(c.etags)}, opts...)
+	}
+	return withOptions(c.Verbose, c.opts, opts)
+}
+
+// Token returns the access token of the client. A token of a token source is renewed by the source
+// when it expires.
+func (c *MsClient) Token() string {
+	if c.tokens != nil {
+		token, err := c.tokens.Token()
+		if err == nil {
+			c.mu.Lock()
+			c.token = token
+			c.mu.Unlock()
+			return token
+		}
+		logWarnf("could not renew token, use the last token: %v", err)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// EnableReadCache caches building block listings and details for ttl, see ReadCache
+func (c *MsClient) EnableReadCache(ttl time.Duration) {
+	c.cache = NewReadCache(ttl)
+}
+
+// ReadCache returns the read cache of the client, nil if caching is disabled
+func (c *MsClient) ReadCache() *ReadCache {
+	return c.cache
+}
+
+// EnableETagCache sends conditional requests for the last size responses with an ETag, see
+// ETagCache. WithETagCache(nil) disables it for a call.
+func (c *MsClient) EnableETagCache(size int) {
+	c.etags = NewETagCache(size)
+}
+
+// ETagCache returns the ETag cache of the client, nil if it is disabled
+func (c *MsClient) ETagCache() *ETagCache {
+	return c.etags
+}
+
+// ListBuildingBlocks lists the building blocks of a project, see MsListBuildingBlocks
+func (c *MsClient) ListBuildingBlocks(projectid string, opts ...Option) ([]BuildingBlockType, error) {
+	verbose, opts := c.options(opts)
+	return cachedRead(c.cache, cacheMsBuildingBlocks+projectid, func() ([]BuildingBlockType, error) {
+		return MsListBuildingBlocks(c.URL, projectid, c.Token(), verbose, opts...)
+	})
+}
+
+// EachBuildingBlock calls fn for every building block of a project, see MsEachBuildingBlock
+func (c *MsClient) EachBuildingBlock(projectid string, fn func(BuildingBlockType) error, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return MsEachBuildingBlock(c.URL, projectid, c.Token(), fn, verbose, opts...)
+}
+
+// GetBuildingBlockUUIDByName resolves a building block name, see MsGetBuildingBlockUUIDByName
+func (c *MsClient) GetBuildingBlockUUIDByName(projectid, name string, opts ...Option) (string, error) {
+	verbose, opts := c.options(opts)
+	return MsGetBuildingBlockUUIDByName(c.URL, projectid, c.Token(), name, verbose, opts...)
+}
+
+// CreateBuildingBlock creates a building block, see MsCreateBuildingBlock
+func (c *MsClient) CreateBuildingBlock(payload []byte, opts ...Option) (string, error) {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheMsBuildingBlocks)
+	return MsCreateBuildingBlock(c.URL, c.Token(), payload, verbose, opts...)
+}
+
+// GetBuildingBlock returns the status of a building block, see MsGetBuildingBlock
+func (c *MsClient) GetBuildingBlock(UUID string, opts ...Option) (string, error) {
+	verbose, opts := c.options(opts)
+	return MsGetBuildingBlock(c.URL, c.Token(), UUID, verbose, opts...)
+}
+
+// DeleteBuildingBlock deletes a building block, see MsDeleteBuildingBlock
+func (c *MsClient) DeleteBuildingBlock(UUID string, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheMsBuildingBlocks, cacheMsBuildingBlock+UUID)
+	return MsDeleteBuildingBlock(c.URL, c.Token(), UUID, verbose, opts...)
+}
+
+// ApplyMeshObject imports meshObjects, see MsApplyMeshObject
+func (c *MsClient) ApplyMeshObject(payload []byte, opts ...Option) ([]MeshObjectImportResult, error) {
+	verbose, opts := c.options(opts)
+	defer c.cache.Clear()
+	return MsApplyMeshObject(c.URL, c.Token(), payload, verbose, opts...)
+}
+
+// GetBuildingBlockDetails returns a building block with inputs and outputs, see MsGetBuildingBlockDetails
+func (c *MsClient) GetBuildingBlockDetails(UUID string, opts ...Option) (BuildingBlockDetails, error) {
+	verbose, opts := c.options(opts)
+	return cachedRead(c.cache, cacheMsBuildingBlock+UUID, func() (BuildingBlockDetails, error) {
+		return MsGetBuildingBlockDetails(c.URL, c.Token(), UUID, verbose, opts...)
+	})
+}
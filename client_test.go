@@ -0,0 +1,142 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConfigEnvironments(t *testing.T) {
+	env := map[string]string{
+		EnvSumaURL:                 "https://suma.example.com",
+		EnvSumaUsername:            "admin",
+		EnvNetworks:                "192.168.1.0/24",
+		EnvEnvironments:            "dev, prod",
+		"APPAPI_PROD_SUMA_URL":     "https://suma-prod.example.com",
+		"APPAPI_PROD_NETWORKS":     "10.0.0.0/8",
+		"APPAPI_PROD_MS_URL":       "https://meshstack-prod.example.com",
+		"APPAPI_DEV_SUMA_USERNAME": "dev-admin",
+	}
+	cfg, err := loadConfig(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+
+	prod, err := cfg.Environment("prod")
+	if err != nil {
+		t.Fatalf("Environment(prod) returned error: %v", err)
+	}
+	if prod.SumaURL != "https://suma-prod.example.com" || prod.MsURL != "https://meshstack-prod.example.com" || prod.Networks[0] != "10.0.0.0/8" {
+		t.Errorf("unexpected prod environment: %+v", prod)
+	}
+	if prod.Credentials.SumaUsername != "admin" {
+		t.Errorf("expected default username for prod, got %q", prod.Credentials.SumaUsername)
+	}
+
+	dev, _ := cfg.Environment("dev")
+	if dev.SumaURL != "https://suma.example.com" || dev.Credentials.SumaUsername != "dev-admin" {
+		t.Errorf("unexpected dev environment: %+v", dev)
+	}
+
+	if _, err := cfg.Environment("staging"); err == nil {
+		t.Error("expected error for unknown environment, got nil")
+	}
+}
+
+func TestConfigNewClients(t *testing.T) {
+	suma := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "pxt-session-cookie", Value: "cookie", MaxAge: 3600})
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer suma.Close()
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token": "token"}`)
+	}))
+	defer ms.Close()
+
+	cfg := Config{
+		Environments: map[string]Environment{
+			"test": {Name: "test", SumaURL: suma.URL, MsURL: ms.URL, Networks: []string{"192.168.1.0/24"}},
+			"suma": {Name: "suma", SumaURL: suma.URL},
+		},
+		AllowInsecure: true,
+	}
+
+	sumaClient, msClient, err := cfg.NewClients("test", nil)
+	if err != nil {
+		t.Fatalf("NewClients returned error: %v", err)
+	}
+	if sumaClient.SessionCookie() != "cookie" || sumaClient.Networks[0] != "192.168.1.0/24" {
+		t.Errorf("unexpected suma client: %+v", sumaClient)
+	}
+	if msClient.Token() != "token" {
+		t.Errorf("unexpected meshstack token %q", msClient.Token())
+	}
+
+	_, msClient, err = cfg.NewClients("suma", nil)
+	if err != nil {
+		t.Fatalf("NewClients returned error: %v", err)
+	}
+	if msClient != nil {
+		t.Error("expected no meshstack client without meshstack url")
+	}
+
+	cfg.AllowInsecure = false
+	if _, _, err = cfg.NewClients("suma", nil); err == nil {
+		t.Error("expected error for plain http url without AllowInsecure, got nil")
+	}
+}
+
+func TestConfigNewClientsLogsOutOnError(t *testing.T) {
+	var logins, logouts atomic.Int32
+	suma := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/auth/login"):
+			logins.Add(1)
+			http.SetCookie(w, &http.Cookie{Name: "pxt-session-cookie", Value: "cookie", MaxAge: 3600})
+		case strings.HasSuffix(r.URL.Path, "/auth/logout"):
+			logouts.Add(1)
+		}
+		fmt.Fprint(w, `{"success": true}`)
+	}))
+	defer suma.Close()
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ms.Close()
+
+	cfg := Config{
+		Environments: map[string]Environment{
+			"test": {Name: "test", SumaURL: suma.URL, MsURL: ms.URL},
+		},
+		AllowInsecure: true,
+	}
+
+	if _, _, err := cfg.NewClients("test", nil); err == nil {
+		t.Fatal("expected error of the meshstack login, got nil")
+	}
+	if logins.Load() != 1 || logouts.Load() != 1 {
+		t.Errorf("got %d SUMA logins and %d logouts, want the session logged out", logins.Load(), logouts.Load())
+	}
+
+	// the single clients do not log in to the other backend
+	sumaClient, err := cfg.NewSumaClient("test", nil)
+	if err != nil || sumaClient == nil {
+		t.Fatalf("NewSumaClient() = %v, %v", sumaClient, err)
+	}
+	sumaClient.Close()
+	if _, err := cfg.NewMsClient("test", nil); err == nil {
+		t.Error("expected error of the meshstack login, got nil")
+	}
+	if logins.Load() != 2 {
+		t.Errorf("got %d SUMA logins, want 2", logins.Load())
+	}
+}
@@ -0,0 +1,192 @@
+package appapi
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the time source of the retries, the session expiry and the polling of the watchers, so
+// tests can advance the time with a FakeClock instead of sleeping.
+type Clock interface {
+	Now() time.Time
+	// After sends the time on the channel after d
+	After(d time.Duration) <-chan time.Time
+	// NewTicker sends the time on the channel of the ticker every d
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is a time.Ticker of a Clock
+type Ticker interface {
+	Chan() <-chan time.Time
+	Stop()
+}
+
+// DefaultClock is the clock of appapi unless a call or client sets its own with WithClock. Session
+// managers and token sources keep the clock they are created with, the watchers read it when they
+// need the time, so set it before the calls start.
+var DefaultClock Clock = systemClock{}
+
+// systemClock is the real time
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) NewTicker(d time.Duration) Ticker       { return systemTicker{time.NewTicker(d)} }
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) Chan() <-chan time.Time { return t.C }
+
+// sleep waits for d on the clock or until the context is canceled
+func sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clock.After(d):
+		return nil
+	}
+}
+
+// FakeClock is a Clock which only moves when it is advanced. The timers and tickers due are fired
+// by Advance.
+//
+//	clock := NewFakeClock(time.Now())
+//	go appapi.MsWaitForBuildingBlock(ctx, url, key, uuid, policy, false, appapi.WithClock(clock))
+//	clock.BlockUntil(1)
+//	clock.Advance(time.Minute)
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{}
+}
+
+// fakeWaiter is a timer of After or a ticker, a ticker has a period
+type fakeWaiter struct {
+	when   time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+// NewFakeClock creates a fake clock starting at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, changed: make(chan struct{})}
+}
+
+// Now returns the time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel receiving the time once the clock is advanced by d
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.add(&fakeWaiter{when: c.now.Add(d), c: ch})
+	return ch
+}
+
+// NewTicker returns a ticker firing every d the clock is advanced
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	w := &fakeWaiter{period: d, c: make(chan time.Time, 1)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.when = c.now.Add(d)
+	c.add(w)
+	return &fakeTicker{clock: c, w: w}
+}
+
+// Advance moves the clock forward by d and fires the timers and tickers which are due. A ticker
+// drops the ticks its receiver is not ready for, like a time.Ticker.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+
+	var waiting []*fakeWaiter
+	for _, w := range c.waiters {
+		if w.when.After(c.now) {
+			waiting = append(waiting, w)
+			continue
+		}
+		select {
+		case w.c <- c.now:
+		default:
+		}
+		if w.period > 0 {
+			for !w.when.After(c.now) {
+				w.when = w.when.Add(w.period)
+			}
+			waiting = append(waiting, w)
+		}
+	}
+	c.waiters = waiting
+	c.notify()
+}
+
+// Waiters returns the number of pending timers and tickers
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until there are at least n pending timers and tickers, e.g. until a goroutine
+// under test waits for the next poll
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		if len(c.waiters) >= n {
+			c.mu.Unlock()
+			return
+		}
+		changed := c.changed
+		c.mu.Unlock()
+		<-changed
+	}
+}
+
+// add registers a waiter, the waiters are kept in the order they are due
+func (c *FakeClock) add(w *fakeWaiter) {
+	c.waiters = append(c.waiters, w)
+	sort.SliceStable(c.waiters, func(i, j int) bool { return c.waiters[i].when.Before(c.waiters[j].when) })
+	c.notify()
+}
+
+// notify wakes up BlockUntil, the lock must be held
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *FakeClock) remove(w *fakeWaiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, waiter := range c.waiters {
+		if waiter == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.notify()
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock *FakeClock
+	w     *fakeWaiter
+}
+
+func (t *fakeTicker) Chan() <-chan time.Time { return t.w.c }
+func (t *fakeTicker) Stop()                  { t.clock.remove(t.w) }
@@ -0,0 +1,124 @@
+package appapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	after := clock.After(time.Minute)
+	ticker := clock.NewTicker(20 * time.Second)
+	if n := clock.Waiters(); n != 2 {
+		t.Fatalf("waiters = %d, want 2", n)
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-after:
+		t.Fatal("the timer fired early")
+	default:
+	}
+	if tick := <-ticker.Chan(); !tick.Equal(start.Add(30 * time.Second)) {
+		t.Errorf("tick = %v", tick)
+	}
+
+	// the tick of 40s is dropped, the receiver did not take it before 60s
+	clock.Advance(30 * time.Second)
+	if now := <-after; !now.Equal(start.Add(time.Minute)) || !clock.Now().Equal(now) {
+		t.Errorf("timer fired at %v, now = %v", now, clock.Now())
+	}
+	<-ticker.Chan()
+	select {
+	case <-ticker.Chan():
+		t.Error("a missed tick was delivered")
+	default:
+	}
+
+	ticker.Stop()
+	if n := clock.Waiters(); n != 0 {
+		t.Errorf("waiters = %d after Stop, want 0", n)
+	}
+	select {
+	case <-clock.After(0):
+	default:
+		t.Error("a timer without duration must fire immediately")
+	}
+}
+
+func TestRetryWithFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	policy := RetryPolicy{Retries: 1, Backoff: time.Second, MaxWait: time.Hour}
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"success": true, "result": "5.0.3"}`))
+	}))
+	defer server.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := SumaGetVersion("cookie", server.URL, false, WithRetry(policy), WithClock(clock))
+		done <- err
+	}()
+
+	// the ten minutes of Retry-After pass without sleeping
+	clock.BlockUntil(1)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("calls = %d before the wait, want 1", n)
+	}
+	clock.Advance(10 * time.Minute)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
+
+func TestMsStreamRunLogsWithFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := RunInProgress
+		if polls.Add(1) == 3 {
+			status = RunSucceeded
+		}
+		w.Write([]byte(`{"metadata": {"uuid": "run-1"}, "status": {"status": "` + status + `"}}`))
+	}))
+	defer server.Close()
+
+	done := make(chan string, 1)
+	go func() {
+		status, _ := MsStreamRunLogs(context.Background(), server.URL, "token", "run-1", io.Discard, time.Hour, false, WithClock(clock))
+		done <- status
+	}()
+
+	// the ticker is advanced after each poll, so the tick before was received
+	for poll := range int32(2) {
+		for polls.Load() <= poll {
+			runtime.Gosched()
+		}
+		clock.Advance(time.Hour)
+	}
+	if status := <-done; status != RunSucceeded {
+		t.Errorf("status = %q, want %q", status, RunSucceeded)
+	}
+	if n := polls.Load(); n != 3 {
+		t.Errorf("polls = %d, want 3", n)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/arzieg/appapi/grpcapi"
+	"google.golang.org/grpc"
+	grpccreds "google.golang.org/grpc/credentials"
+)
+
+func runGrpcServe(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("grpc serve", flag.ContinueOnError)
+	listen := fs.String("listen", ":9090", "address of the gRPC server")
+	tokenFile := fs.String("token-file", "", "file with the bearer token the callers must send")
+	certFile := fs.String("cert", "", "TLS certificate file")
+	keyFile := fs.String("key", "", "TLS key file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*certFile == "") != (*keyFile == "") {
+		return errors.New("-cert and -key must be set together")
+	}
+
+	var token string
+	if *tokenFile != "" {
+		var err error
+		if token, err = readSecretFile(*tokenFile); err != nil {
+			return err
+		}
+	}
+	var opts []grpc.ServerOption
+	if *certFile != "" {
+		creds, err := grpccreds.NewServerTLSFromFile(*certFile, *keyFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	suma, ms, err := clients(g)
+	if err != nil {
+		return err
+	}
+	if suma != nil {
+		defer suma.Close()
+	}
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return err
+	}
+	server := grpcapi.NewServer(suma, ms, token, opts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	fmt.Fprintf(out, "serving gRPC on %s\n", lis.Addr())
+	return server.Serve(lis)
+}
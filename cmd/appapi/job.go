@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+
+	"github.com/arzieg/appapi"
+)
+
+// jobFlags are the flags of the job commands which run the provision workflow
+type jobFlags struct {
+	dir          *string
+	passwordFile *string
+	network      *string
+}
+
+func addJobFlags(fs *flag.FlagSet) jobFlags {
+	defaultDir, _ := appapi.DefaultJobDir()
+	return jobFlags{
+		dir:          fs.String("dir", defaultDir, "directory of the jobs"),
+		passwordFile: fs.String("password-file", "", "file with the password of the SUMA user, no user is created if empty"),
+		network:      fs.String("network", "", "permitted network of the systems (default: first configured network)"),
+	}
+}
+
+// jobRunner returns the runner of the provision workflow and the SUMA client to close
+func jobRunner(g globalFlags, f jobFlags) (*appapi.JobRunner, *appapi.SumaClient, error) {
+	if *f.dir == "" {
+		return nil, nil, errors.New("-dir is required")
+	}
+	cfg := appapi.ProvisionConfig{Network: *f.network}
+	if *f.passwordFile != "" {
+		password, err := readSecretFile(*f.passwordFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.GroupPassword = password
+	}
+
+	suma, ms, err := clients(g)
+	if err != nil {
+		return nil, nil, err
+	}
+	if suma == nil || ms == nil {
+		if suma != nil {
+			suma.Close()
+		}
+		return nil, nil, errors.New("jobs need a SUMA and a Meshstack url")
+	}
+	runner := appapi.NewJobRunner(appapi.NewFileJobStore(*f.dir), appapi.ProvisionWorkflow(suma, ms, cfg))
+	return runner, suma, nil
+}
+
+func runJobProvision(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("job provision", flag.ContinueOnError)
+	payloadFile := fs.String("payload", "", "file with the JSON payload of the building block")
+	group := fs.String("group", "", "SUMA system group and user")
+	f := addJobFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *payloadFile == "" || *group == "" {
+		return errors.New("-payload and -group are required")
+	}
+	payload, err := os.ReadFile(*payloadFile)
+	if err != nil {
+		return err
+	}
+
+	runner, suma, err := jobRunner(g, f)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+
+	job, err := runner.Submit(appapi.ProvisionWorkflowName, map[string]string{"payload": string(payload), "group": *group})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	job, err = runner.Run(ctx, job.ID)
+	if job != nil {
+		if perr := printResult(g, job); perr != nil {
+			return perr
+		}
+	}
+	return err
+}
+
+func runJobList(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("job list", flag.ContinueOnError)
+	defaultDir, _ := appapi.DefaultJobDir()
+	dir := fs.String("dir", defaultDir, "directory of the jobs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	jobs, err := appapi.NewFileJobStore(*dir).List()
+	if err != nil {
+		return err
+	}
+	return printResult(g, jobs)
+}
+
+func runJobResume(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("job resume", flag.ContinueOnError)
+	id := fs.String("id", "", "job to run, also retries a failed job (default: all unfinished jobs)")
+	f := addJobFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	runner, suma, err := jobRunner(g, f)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var jobs []*appapi.Job
+	if *id != "" {
+		var job *appapi.Job
+		job, err = runner.Run(ctx, *id)
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	} else {
+		jobs, err = runner.Resume(ctx)
+	}
+	if perr := printResult(g, jobs); perr != nil {
+		return perr
+	}
+	return err
+}
@@ -0,0 +1,240 @@
+// Command appapi exposes the appapi library on the command line.
+//
+// Usage:
+//
+//	appapi [global flags] suma add-system -host HOST -group GROUP [-network CIDR]
+//	appapi [global flags] suma delete-system -host HOST [-network CIDR]
+//	appapi [global flags] suma add-user -user USER -password-file FILE
+//	appapi [global flags] suma ensure-group -group GROUP -hosts HOST,... [-network CIDR] [-plan]
+//	appapi [global flags] -o csv suma list-systems > systems.csv
+//	appapi [global flags] ms list-bb -project PROJECT
+//	appapi [global flags] ms create-bb -file PAYLOAD
+//	appapi [global flags] sync project -project PROJECT [-group GROUP] [-password-file FILE] [-plan]
+//
+// The endpoints and credentials are read from the APPAPI_* environment variables,
+// see the appapi package documentation.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/arzieg/appapi"
+	"github.com/arzieg/appapi/output"
+)
+
+// globalFlags are the flags valid for all commands
+type globalFlags struct {
+	profile string
+	verbose bool
+	output  string
+	match   appapi.SystemMatchPolicy
+}
+
+// command is a sub command like "suma add-system"
+type command struct {
+	name  string
+	usage string
+	run   func(g globalFlags, args []string) error
+}
+
+var commands = []command{
+	{name: "suma add-system", usage: "add a system to a system group", run: runSumaAddSystem},
+	{name: "suma delete-system", usage: "delete a system from SUSE Manager", run: runSumaDeleteSystem},
+	{name: "suma add-user", usage: "add a user to SUSE Manager", run: runSumaAddUser},
+	{name: "suma ensure-group", usage: "make the members of a system group match a list of hosts", run: runSumaEnsureGroup},
+	{name: "suma list-systems", usage: "list all systems, streamed with -o csv or ndjson", run: runSumaListSystems},
+	{name: "suma list-errata", usage: "list the errata relevant for a system, streamed with -o csv or ndjson", run: runSumaListErrata},
+	{name: "suma patch-report", usage: "count the relevant errata of the members of a system group", run: runSumaPatchReport},
+	{name: "suma exporter", usage: "serve the patch compliance of system groups as Prometheus metrics", run: runSumaExporter},
+	{name: "suma export-state", usage: "write the groups, users and activation keys to a YAML or JSON file", run: runSumaExportState},
+	{name: "suma import-state", usage: "re-create the groups, users and activation keys of an exported state", run: runSumaImportState},
+	{name: "ms list-bb", usage: "list the building blocks of a project", run: runMsListBuildingBlocks},
+	{name: "ms create-bb", usage: "create a building block from a payload file", run: runMsCreateBuildingBlock},
+	{name: "ms tenants", usage: "list the tenants of a workspace, optionally of one platform", run: runMsTenants},
+	{name: "ms apply", usage: "apply a directory of meshObject YAML manifests", run: runMsApply},
+	{name: "ms export", usage: "export the meshObjects of a project to a YAML manifest", run: runMsExport},
+	{name: "ms run-logs", usage: "follow the logs of a building block run", run: runMsRunLogs},
+	{name: "sync project", usage: "sync the VMs of a Meshstack project to a SUMA system group", run: runSync},
+	{name: "grpc serve", usage: "serve the operations over gRPC for other tooling", run: runGrpcServe},
+	{name: "rest serve", usage: "serve the operations as HTTP API with an OpenAPI document", run: runRestServe},
+	{name: "job provision", usage: "create a VM building block and add it to SUMA as a resumable job", run: runJobProvision},
+	{name: "job list", usage: "list the jobs and their status", run: runJobList},
+	{name: "job resume", usage: "resume the unfinished jobs or retry a failed job", run: runJobResume},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	var g globalFlags
+
+	fs := flag.NewFlagSet("appapi", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&g.profile, "profile", "", "environment of APPAPI_ENVIRONMENTS to use (default: top level settings)")
+	fs.BoolVar(&g.verbose, "v", false, "verbose output")
+	fs.StringVar(&g.output, "o", "table", "output format: table, json, yaml, csv or ndjson")
+	match := fs.String("match", string(appapi.MatchError), "system used if a hostname matches several systems: error, latest-checkin or network")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: appapi [global flags] <backend> <command> [flags]")
+		fmt.Fprintln(stderr, "\nCommands:")
+		for _, c := range commands {
+			fmt.Fprintf(stderr, "  %-20s %s\n", c.name, c.usage)
+		}
+		fmt.Fprintln(stderr, "\nGlobal flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	switch g.match = appapi.SystemMatchPolicy(*match); g.match {
+	case appapi.MatchError, appapi.MatchLatestCheckin, appapi.MatchNetwork:
+	default:
+		fmt.Fprintf(stderr, "appapi: unknown -match policy %q\n", *match)
+		return 2
+	}
+
+	rest := fs.Args()
+	if len(rest) < 2 {
+		fs.Usage()
+		return 2
+	}
+
+	name := strings.Join(rest[:2], " ")
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		out = stdout
+		if err := c.run(g, rest[2:]); err != nil {
+			// errors may quote URLs or response bodies with credentials
+			fmt.Fprintf(stderr, "appapi %s: %s\n", name, appapi.Redact(err.Error()))
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "appapi: unknown command %q\n", name)
+	fs.Usage()
+	return 2
+}
+
+// out is the writer for command results
+var out io.Writer = os.Stdout
+
+// printResult writes a result in the selected output format
+func printResult(g globalFlags, v any) error {
+	format, err := output.ParseFormat(g.output)
+	if err != nil {
+		return err
+	}
+	return output.Render(out, format, v)
+}
+
+// streamResult writes the items listed by each in the selected output format. CSV and NDJSON are
+// written item by item, the other formats need the complete list.
+func streamResult[T any](g globalFlags, each func(fn func(T) error) error) error {
+	format, err := output.ParseFormat(g.output)
+	if err != nil {
+		return err
+	}
+	if format != output.CSV && format != output.NDJSON {
+		items := []T{}
+		if err := each(func(item T) error {
+			items = append(items, item)
+			return nil
+		}); err != nil {
+			return err
+		}
+		return output.Render(out, format, items)
+	}
+
+	sw, err := output.NewStreamWriter(out, format)
+	if err != nil {
+		return err
+	}
+	err = each(func(item T) error { return sw.Write(item) })
+	if flushErr := sw.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// printPlan prints the planned changes and applies them unless planOnly is set
+func printPlan(g globalFlags, plan *appapi.Plan, planOnly bool) error {
+	if g.output == "text" || g.output == "table" {
+		fmt.Fprint(out, plan.String())
+	} else if err := printResult(g, plan.Changes); err != nil {
+		return err
+	}
+	if planOnly || plan.Empty() {
+		return nil
+	}
+	return plan.Apply()
+}
+
+// loadConfig reads the configuration and applies the global flags
+func loadConfig(g globalFlags) (appapi.Config, error) {
+	cfg, err := appapi.LoadConfig()
+	if err != nil {
+		return cfg, err
+	}
+	if g.verbose {
+		cfg.Verbose = true
+	}
+	cfg.SystemMatch = g.match
+	return cfg, nil
+}
+
+// credentials returns the Vault provider if Vault paths are configured, nil otherwise
+// which uses the credentials of the environment.
+func credentials(cfg appapi.Config) (appapi.CredentialProvider, error) {
+	if cfg.VaultSumaPath == "" && cfg.VaultMeshstackPath == "" {
+		return nil, nil
+	}
+	session, err := appapi.VaultLoginFromConfig(cfg, cfg.Verbose)
+	if err != nil {
+		return nil, err
+	}
+	return appapi.NewVaultCredentialProvider(session, cfg.VaultSumaPath, cfg.VaultMeshstackPath), nil
+}
+
+// clients creates the clients of the selected profile
+func clients(g globalFlags) (*appapi.SumaClient, *appapi.MsClient, error) {
+	cfg, creds, err := configCredentials(g)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg.NewClients(g.profile, creds)
+}
+
+// configCredentials reads the configuration and the credential provider of the clients
+func configCredentials(g globalFlags) (appapi.Config, appapi.CredentialProvider, error) {
+	cfg, err := loadConfig(g)
+	if err != nil {
+		return cfg, nil, err
+	}
+	creds, err := credentials(cfg)
+	return cfg, creds, err
+}
+
+// readSecretFile reads a password from a file, "-" reads from stdin
+func readSecretFile(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
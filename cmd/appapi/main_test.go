@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arzieg/appapi"
+	"github.com/arzieg/appapi/meshtest"
+	"github.com/arzieg/appapi/sumatest"
+)
+
+// setEnv points the configuration to the fake servers, an empty URL is not set
+func setEnv(t *testing.T, sumaURL string, sumaCreds appapi.Credentials, msURL string, msCreds appapi.Credentials) {
+	t.Helper()
+	t.Setenv(appapi.EnvAllowInsecure, "true")
+	t.Setenv(appapi.EnvNetworks, "192.168.1.0/24")
+	if sumaURL != "" {
+		t.Setenv(appapi.EnvSumaURL, sumaURL)
+		t.Setenv(appapi.EnvSumaUsername, sumaCreds.SumaUsername)
+		t.Setenv(appapi.EnvSumaPassword, sumaCreds.SumaPassword)
+	}
+	if msURL != "" {
+		t.Setenv(appapi.EnvMsURL, msURL)
+		t.Setenv(appapi.EnvMsClientID, msCreds.MsClientID)
+		t.Setenv(appapi.EnvMsClientSecret, msCreds.MsClientSecret)
+	}
+}
+
+// unusedServer fails the test if the command calls it
+func unusedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(func() {
+		server.Close()
+		if n := calls.Load(); n > 0 {
+			t.Errorf("the command called the other backend %d times", n)
+		}
+	})
+	return server
+}
+
+func TestRunFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStderr string
+	}{
+		{name: "no command", args: nil, wantCode: 2, wantStderr: "Usage: appapi"},
+		{name: "unknown flag", args: []string{"-unknown", "suma", "list-systems"}, wantCode: 2, wantStderr: "flag provided but not defined: -unknown"},
+		{name: "unknown command", args: []string{"suma", "reboot"}, wantCode: 2, wantStderr: `unknown command "suma reboot"`},
+		{name: "unknown match policy", args: []string{"-match", "first", "suma", "list-systems"}, wantCode: 2, wantStderr: `unknown -match policy "first"`},
+		{name: "missing command flag", args: []string{"suma", "add-system", "-host", "vm1"}, wantCode: 1, wantStderr: "appapi suma add-system: -host and -group are required"},
+		{name: "unknown command flag", args: []string{"ms", "list-bb", "-tenant", "t1"}, wantCode: 1, wantStderr: "flag provided but not defined: -tenant"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d, stderr %q", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunSuma(t *testing.T) {
+	suma := sumatest.NewServer(sumatest.Dataset{
+		Groups:  []appapi.SystemGroup{{Name: "project-a"}},
+		Systems: []sumatest.System{{ID: 1, Name: "vm1.example.com", IP: "192.168.1.10"}, {ID: 2, Name: "vm2.example.com", IP: "192.168.1.20"}},
+	})
+	defer suma.Close()
+	setEnv(t, suma.URL, suma.Credentials(), unusedServer(t).URL, appapi.Credentials{MsClientID: "id", MsClientSecret: "secret"})
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-o", "json", "suma", "add-system", "-host", "vm1.example.com", "-group", "project-a"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, stderr.String())
+	}
+	var result appapi.SystemResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("could not decode output %q: %v", stdout.String(), err)
+	}
+	if result.SystemID != 1 || result.Action != appapi.ResultAdded {
+		t.Errorf("result = %+v, want system 1 added", result)
+	}
+	if got := suma.Members("project-a"); len(got) != 1 || got[0] != 1 {
+		t.Errorf("members = %v, want [1]", got)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"-o", "ndjson", "suma", "list-systems"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, stderr.String())
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[1], "vm2.example.com") {
+		t.Errorf("list-systems output = %q, want 2 systems", stdout.String())
+	}
+
+	// errors of the API are reported with exit code 1
+	stderr.Reset()
+	if code := run([]string{"suma", "delete-system", "-host", "unknown.example.com"}, &stdout, &stderr); code != 1 {
+		t.Errorf("run() = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "appapi suma delete-system: ") {
+		t.Errorf("stderr = %q, want the error of delete-system", stderr.String())
+	}
+}
+
+func TestRunMs(t *testing.T) {
+	ms := meshtest.NewServer(meshtest.Dataset{
+		BuildingBlocks: []meshtest.BuildingBlock{
+			{ProjectID: "project-a", BuildingBlockDetails: appapi.BuildingBlockDetails{UUID: "bb-1", Name: "vm1", Status: appapi.RunSucceeded}},
+			{ProjectID: "project-b", BuildingBlockDetails: appapi.BuildingBlockDetails{UUID: "bb-2", Name: "vm2", Status: appapi.RunSucceeded}},
+		},
+	})
+	defer ms.Close()
+	setEnv(t, unusedServer(t).URL, appapi.Credentials{SumaUsername: "admin", SumaPassword: "secret"}, ms.URL, ms.Credentials())
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-o", "json", "ms", "list-bb", "-project", "project-a"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, stderr.String())
+	}
+	var bbs []appapi.BuildingBlockType
+	if err := json.Unmarshal(stdout.Bytes(), &bbs); err != nil {
+		t.Fatalf("could not decode output %q: %v", stdout.String(), err)
+	}
+	if len(bbs) != 1 || bbs[0].UUID != "bb-1" {
+		t.Errorf("building blocks = %+v, want bb-1", bbs)
+	}
+}
+
+func TestRunMatch(t *testing.T) {
+	suma := sumatest.NewServer(sumatest.Dataset{
+		Groups: []appapi.SystemGroup{{Name: "project-a"}},
+		Systems: []sumatest.System{
+			{ID: 1, Name: "vm1.example.com", IP: "192.168.1.10", LastCheckin: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{ID: 2, Name: "vm1.example.com", IP: "192.168.1.11", LastCheckin: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+	defer suma.Close()
+	setEnv(t, suma.URL, suma.Credentials(), "", appapi.Credentials{})
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"suma", "add-system", "-host", "vm1.example.com", "-group", "project-a"}
+	if code := run(args, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "matches 2 systems") {
+		t.Errorf("run() = %d, stderr %q, want the ambiguous hostname reported", code, stderr.String())
+	}
+
+	if code := run(append([]string{"-match", "latest-checkin"}, args...), &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, stderr.String())
+	}
+	if got := suma.Members("project-a"); len(got) != 1 || got[0] != 2 {
+		t.Errorf("members = %v, want the latest checked in system [2]", got)
+	}
+}
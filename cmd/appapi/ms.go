@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/arzieg/appapi"
+)
+
+// msClient creates the Meshstack client of the selected profile without logging in to SUMA
+func msClient(g globalFlags) (*appapi.MsClient, error) {
+	cfg, creds, err := configCredentials(g)
+	if err != nil {
+		return nil, err
+	}
+	ms, err := cfg.NewMsClient(g.profile, creds)
+	if err != nil {
+		return nil, err
+	}
+	if ms == nil {
+		return nil, errors.New("no Meshstack url configured")
+	}
+	return ms, nil
+}
+
+func runMsListBuildingBlocks(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("ms list-bb", flag.ContinueOnError)
+	project := fs.String("project", "", "project identifier")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *project == "" {
+		return errors.New("-project is required")
+	}
+
+	ms, err := msClient(g)
+	if err != nil {
+		return err
+	}
+
+	bbs, err := ms.ListBuildingBlocks(*project)
+	if err != nil {
+		return err
+	}
+	return printResult(g, bbs)
+}
+
+func runMsCreateBuildingBlock(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("ms create-bb", flag.ContinueOnError)
+	file := fs.String("file", "", "file with the building block payload (JSON)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	payload, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	ms, err := msClient(g)
+	if err != nil {
+		return err
+	}
+
+	uuid, err := ms.CreateBuildingBlock(payload)
+	if err != nil {
+		return err
+	}
+	return printResult(g, map[string]any{"uuid": uuid})
+}
+
+func runMsTenants(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("ms tenants", flag.ContinueOnError)
+	workspace := fs.String("workspace", "", "workspace identifier")
+	platform := fs.String("platform", "", "platform identifier, e.g. openstack.eu-de (default: all platforms)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *workspace == "" {
+		return errors.New("-workspace is required")
+	}
+
+	ms, err := msClient(g)
+	if err != nil {
+		return err
+	}
+
+	var tenants []appapi.Tenant
+	if *platform != "" {
+		tenants, err = ms.PlatformTenants(*workspace, *platform)
+	} else {
+		tenants, err = ms.Tenants(*workspace, nil)
+	}
+	if err != nil {
+		return err
+	}
+	return printResult(g, tenants)
+}
+
+func runMsApply(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("ms apply", flag.ContinueOnError)
+	dir := fs.String("dir", "", "directory with the meshObject manifests (*.yaml, *.yml)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errors.New("-dir is required")
+	}
+
+	ms, err := msClient(g)
+	if err != nil {
+		return err
+	}
+
+	results, err := ms.ApplyManifests(*dir)
+	if results != nil {
+		if perr := printResult(g, results); perr != nil {
+			return perr
+		}
+	}
+	return err
+}
+
+func runMsExport(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("ms export", flag.ContinueOnError)
+	workspace := fs.String("workspace", "", "workspace identifier")
+	project := fs.String("project", "", "project identifier")
+	dir := fs.String("dir", ".", "directory of the manifest")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *workspace == "" || *project == "" {
+		return errors.New("-workspace and -project are required")
+	}
+
+	ms, err := msClient(g)
+	if err != nil {
+		return err
+	}
+
+	file, err := ms.ExportProject(*workspace, *project, *dir)
+	if err != nil {
+		return err
+	}
+	return printResult(g, map[string]any{"file": file})
+}
+
+func runSync(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("sync project", flag.ContinueOnError)
+	project := fs.String("project", "", "Meshstack project identifier")
+	group := fs.String("group", "", "SUMA system group and user (default: project)")
+	passwordFile := fs.String("password-file", "", "file with the password of the SUMA user, no user is created if empty")
+	network := fs.String("network", "", "permitted network of the systems (default: first configured network)")
+	planOnly := fs.Bool("plan", false, "only print the planned changes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *project == "" {
+		return errors.New("-project is required")
+	}
+
+	opts := appapi.SyncOptions{ProjectID: *project, Group: *group, Network: *network}
+	if *passwordFile != "" {
+		password, err := readSecretFile(*passwordFile)
+		if err != nil {
+			return err
+		}
+		opts.GroupPassword = password
+	}
+
+	suma, ms, err := clients(g)
+	if err != nil {
+		return err
+	}
+	if suma != nil {
+		defer suma.Close()
+	}
+	if suma == nil || ms == nil {
+		return errors.New("sync needs a SUMA and a Meshstack url")
+	}
+
+	plan, _, err := appapi.PlanSync(suma, ms, opts)
+	if err != nil {
+		return err
+	}
+	return printPlan(g, plan, *planOnly)
+}
+
+func runMsRunLogs(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("ms run-logs", flag.ContinueOnError)
+	run := fs.String("run", "", "UUID of the building block run")
+	interval := fs.Duration("interval", 5*time.Second, "poll interval while the run is in progress")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *run == "" {
+		return errors.New("-run is required")
+	}
+
+	ms, err := msClient(g)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	status, err := ms.StreamRunLogs(ctx, *run, out, *interval)
+	if err != nil {
+		return err
+	}
+	if status != appapi.RunSucceeded {
+		return fmt.Errorf("run %s finished with status %s", *run, status)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/arzieg/appapi/restapi"
+)
+
+func runRestServe(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("rest serve", flag.ContinueOnError)
+	listen := fs.String("listen", ":8080", "address of the HTTP server")
+	tokenFile := fs.String("token-file", "", "file with the bearer token the callers must send")
+	certFile := fs.String("cert", "", "TLS certificate file")
+	keyFile := fs.String("key", "", "TLS key file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*certFile == "") != (*keyFile == "") {
+		return errors.New("-cert and -key must be set together")
+	}
+
+	var token string
+	if *tokenFile != "" {
+		var err error
+		if token, err = readSecretFile(*tokenFile); err != nil {
+			return err
+		}
+	}
+
+	suma, ms, err := clients(g)
+	if err != nil {
+		return err
+	}
+	if suma != nil {
+		defer suma.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	server := &http.Server{Addr: *listen, Handler: restapi.NewServer(suma, ms, token)}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	fmt.Fprintf(out, "serving the API on %s, OpenAPI document at /openapi.json\n", *listen)
+	if *certFile != "" {
+		err = server.ListenAndServeTLS(*certFile, *keyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
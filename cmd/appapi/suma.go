@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/arzieg/appapi"
+)
+
+// sumaClient creates the SUMA client of the selected profile without logging in to Meshstack
+func sumaClient(g globalFlags) (*appapi.SumaClient, error) {
+	cfg, creds, err := configCredentials(g)
+	if err != nil {
+		return nil, err
+	}
+	suma, err := cfg.NewSumaClient(g.profile, creds)
+	if err != nil {
+		return nil, err
+	}
+	if suma == nil {
+		return nil, errors.New("no SUMA url configured")
+	}
+	return suma, nil
+}
+
+// defaultNetwork returns the first permitted network of the client
+func defaultNetwork(suma *appapi.SumaClient, network string) (string, error) {
+	if network != "" {
+		return network, nil
+	}
+	if len(suma.Networks) == 0 {
+		return "", errors.New("-network is required, no permitted network configured")
+	}
+	return suma.Networks[0], nil
+}
+
+func runSumaAddSystem(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma add-system", flag.ContinueOnError)
+	host := fs.String("host", "", "hostname of the system")
+	group := fs.String("group", "", "system group")
+	network := fs.String("network", "", "permitted network of the system (default: first configured network)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *host == "" || *group == "" {
+		return errors.New("-host and -group are required")
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+	net, err := defaultNetwork(suma, *network)
+	if err != nil {
+		return err
+	}
+
+	result, err := suma.AddSystem(*host, *group, net)
+	if err != nil {
+		return err
+	}
+	return printResult(g, result)
+}
+
+func runSumaDeleteSystem(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma delete-system", flag.ContinueOnError)
+	host := fs.String("host", "", "hostname of the system")
+	network := fs.String("network", "", "permitted network of the system (default: first configured network)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *host == "" {
+		return errors.New("-host is required")
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+	net, err := defaultNetwork(suma, *network)
+	if err != nil {
+		return err
+	}
+
+	result, err := suma.DeleteSystem(*host, net)
+	if err != nil {
+		return err
+	}
+	return printResult(g, result)
+}
+
+func runSumaAddUser(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma add-user", flag.ContinueOnError)
+	user := fs.String("user", "", "login of the user")
+	passwordFile := fs.String("password-file", "", "file with the password of the user, - reads from stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == "" || *passwordFile == "" {
+		return errors.New("-user and -password-file are required")
+	}
+
+	password, err := readSecretFile(*passwordFile)
+	if err != nil {
+		return err
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+
+	result, err := suma.AddUser(*user, password)
+	if err != nil {
+		return err
+	}
+	return printResult(g, result)
+}
+
+func runSumaEnsureGroup(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma ensure-group", flag.ContinueOnError)
+	group := fs.String("group", "", "system group")
+	hosts := fs.String("hosts", "", "comma separated hostnames of the members")
+	network := fs.String("network", "", "permitted network of the systems (default: first configured network)")
+	planOnly := fs.Bool("plan", false, "only print the planned changes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *group == "" {
+		return errors.New("-group is required")
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+	net, err := defaultNetwork(suma, *network)
+	if err != nil {
+		return err
+	}
+
+	var members []string
+	for _, host := range strings.Split(*hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			members = append(members, host)
+		}
+	}
+
+	plan, _, err := suma.PlanGroupMembers(*group, members, net)
+	if err != nil {
+		return err
+	}
+	return printPlan(g, plan, *planOnly)
+}
+
+func runSumaListSystems(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma list-systems", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+
+	return streamResult(g, func(fn func(appapi.SystemInfo) error) error {
+		return suma.EachSystem(fn)
+	})
+}
+
+func runSumaListErrata(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma list-errata", flag.ContinueOnError)
+	system := fs.Int("system", 0, "system ID")
+	advisoryType := fs.String("type", appapi.AdvisorySecurity, "advisory type: "+strings.Join([]string{appapi.AdvisorySecurity, appapi.AdvisoryBugfix, appapi.AdvisoryEnhancement}, ", "))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *system == 0 {
+		return errors.New("-system is required")
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+
+	return streamResult(g, func(fn func(appapi.Erratum) error) error {
+		return suma.EachRelevantErrata(*system, *advisoryType, fn)
+	})
+}
+
+func runSumaPatchReport(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma patch-report", flag.ContinueOnError)
+	group := fs.String("group", "", "system group")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *group == "" {
+		return errors.New("-group is required")
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+
+	report, err := suma.GroupPatchReport(*group)
+	if err != nil {
+		return err
+	}
+	return printResult(g, report.Systems)
+}
+
+func runSumaExporter(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma exporter", flag.ContinueOnError)
+	groups := fs.String("groups", "", "comma separated system groups")
+	listen := fs.String("listen", ":9101", "address of the metrics endpoint")
+	interval := fs.Duration("interval", 5*time.Minute, "interval of the collection of the metrics")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *groups == "" {
+		return errors.New("-groups is required")
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	exporter := appapi.NewPatchExporter(suma, strings.Split(*groups, ","), *interval)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	server := &http.Server{Addr: *listen, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go exporter.Run(ctx)
+
+	fmt.Fprintf(out, "serving metrics on %s/metrics\n", *listen)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func runSumaExportState(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma export-state", flag.ContinueOnError)
+	file := fs.String("file", "-", "file to write the state to, - for stdout")
+	format := fs.String("format", "yaml", "format of the state: yaml or json")
+	groups := fs.String("groups", "", "comma separated system groups to export (default: all)")
+	allUsers := fs.Bool("all-users", false, "export all users, not only the users named like an exported group")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+
+	opts := appapi.ExportOptions{AllUsers: *allUsers}
+	for _, group := range strings.Split(*groups, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			opts.Groups = append(opts.Groups, group)
+		}
+	}
+	state, err := suma.Export(opts)
+	if err != nil {
+		return err
+	}
+
+	if *file == "-" {
+		return appapi.WriteSumaState(out, state, *format)
+	}
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	if err := appapi.WriteSumaState(f, state, *format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runSumaImportState(g globalFlags, args []string) error {
+	fs := flag.NewFlagSet("suma import-state", flag.ContinueOnError)
+	file := fs.String("file", "", "file with the state written by export-state, - for stdin")
+	network := fs.String("network", "", "permitted network of the group members (default: first configured network)")
+	passwordFile := fs.String("password-file", "", "file with the initial password of the users which do not exist, - for stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	var state *appapi.SumaState
+	if *file == "-" {
+		s, err := appapi.ReadSumaState(os.Stdin)
+		if err != nil {
+			return err
+		}
+		state = s
+	} else {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		state, err = appapi.ReadSumaState(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	opts := appapi.ImportOptions{}
+	if *passwordFile != "" {
+		password, err := readSecretFile(*passwordFile)
+		if err != nil {
+			return err
+		}
+		opts.UserPassword = func(string) (string, error) { return password, nil }
+	}
+
+	suma, err := sumaClient(g)
+	if err != nil {
+		return err
+	}
+	defer suma.Close()
+	if opts.Network, err = defaultNetwork(suma, *network); err != nil {
+		return err
+	}
+
+	results, err := suma.Import(state, opts)
+	if perr := printResult(g, results); perr != nil {
+		return perr
+	}
+	return err
+}
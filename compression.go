@@ -0,0 +1,111 @@
+package appapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RequestCompressionMinSize is the size in bytes from which request bodies, e.g. large serverIds
+// arrays or uploaded config files, are sent gzip compressed, 0 disables the compression. A server
+// which cannot decode them answers 415 Unsupported Media Type, the call is then repeated without
+// compression, which is kept for the server. See APPAPI_REQUEST_COMPRESSION_MIN_SIZE.
+var RequestCompressionMinSize = Envs.CompressionMinSize
+
+// WithRequestCompression sets the size from which the request bodies are compressed instead of
+// RequestCompressionMinSize, 0 disables the compression
+func WithRequestCompression(minSize int64) Option {
+	return func(o *callOptions) {
+		o.compressMin = minSize
+	}
+}
+
+// uncompressedHosts are the servers which rejected a compressed request body
+var uncompressedHosts sync.Map
+
+// compressible reports if the body of the request should be compressed
+func compressible(req *http.Request, minSize int64) bool {
+	if minSize <= 0 || req.GetBody == nil || req.ContentLength < minSize || req.Header.Get("Content-Encoding") != "" {
+		return false
+	}
+	_, rejected := uncompressedHosts.Load(req.URL.Host)
+	return !rejected
+}
+
+// compressRequest returns a copy of the request with a gzip compressed body, or the request if the
+// body does not get smaller
+func compressRequest(req *http.Request) (*http.Request, error) {
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := io.Copy(gz, body); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if int64(buf.Len()) >= req.ContentLength {
+		return req, nil
+	}
+
+	compressed := buf.Bytes()
+	creq := req.Clone(req.Context())
+	creq.Header.Set("Content-Encoding", "gzip")
+	creq.ContentLength = int64(len(compressed))
+	creq.Body = io.NopCloser(bytes.NewReader(compressed))
+	creq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	return creq, nil
+}
+
+// rejectsCompression reports if the server could not decode the compressed body. A server may
+// list the codings it accepts in the Accept-Encoding header of the response (RFC 7694).
+func rejectsCompression(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		return false
+	}
+	accepted := resp.Header.Get("Accept-Encoding")
+	return accepted == "" || !strings.Contains(strings.ToLower(accepted), "gzip")
+}
+
+// send sends the request with a compressed body if it is large enough and repeats it uncompressed if
+// the server rejects the compression
+func (t *apiTransport) send(req *http.Request) (*http.Response, error) {
+	if !compressible(req, t.compressMin) {
+		return t.roundTripWithRetry(req)
+	}
+	creq, err := compressRequest(req)
+	if err != nil || creq == req {
+		return t.roundTripWithRetry(req)
+	}
+
+	resp, err := t.roundTripWithRetry(creq)
+	if err != nil || !rejectsCompression(resp) {
+		return resp, err
+	}
+	Logger().Warn("compressed request body rejected, sending uncompressed",
+		"method", req.Method,
+		"host", req.URL.Host,
+		"endpoint", req.URL.Path,
+		"request_id", req.Header.Get(requestIDHeader))
+	uncompressedHosts.Store(req.URL.Host, true)
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = body
+	return t.roundTripWithRetry(req)
+}
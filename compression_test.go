@@ -0,0 +1,116 @@
+package appapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newDecodingServer echoes the request bodies and records their Content-Encoding. It answers gzip
+// bodies with 415 Unsupported Media Type unless acceptGzip is set.
+func newDecodingServer(t *testing.T, acceptGzip bool) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.Header.Get("Content-Encoding")
+		mu.Lock()
+		encodings = append(encodings, encoding)
+		mu.Unlock()
+
+		var body io.Reader = r.Body
+		if encoding == "gzip" {
+			if !acceptGzip {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("invalid gzip body: %v", err)
+				return
+			}
+			body = gz
+		}
+		// the request body cannot be read once the response is sent
+		data, err := io.ReadAll(body)
+		if err != nil {
+			t.Errorf("reading the body: %v", err)
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, encodings...)
+	}
+}
+
+// post sends the payload and returns the echoed body
+func post(t *testing.T, url, payload string, opts ...Option) string {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(payload))
+	resp, err := newCallOptions(false, opts).do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestRequestCompression(t *testing.T) {
+	server, encodings := newDecodingServer(t, true)
+	large := `{"serverIds": [` + strings.Repeat("1000010001, ", 1000) + `1]}`
+
+	if got := post(t, server.URL, large, WithRequestCompression(1024)); got != large {
+		t.Errorf("the server got %d bytes, want the %d bytes of the payload", len(got), len(large))
+	}
+	post(t, server.URL, `{"small": true}`, WithRequestCompression(1024))
+	post(t, server.URL, large)
+
+	want := []string{"gzip", "", ""}
+	if got := encodings(); !slices.Equal(got, want) {
+		t.Errorf("encodings = %q, want %q", got, want)
+	}
+}
+
+func TestRequestCompressionRejected(t *testing.T) {
+	server, encodings := newDecodingServer(t, false)
+	large := strings.Repeat("x", 4096)
+
+	// the rejected call is repeated uncompressed, the next call is not compressed any more
+	for range 2 {
+		if got := post(t, server.URL, large, WithRequestCompression(1024)); got != large {
+			t.Errorf("the server got %d bytes, want %d", len(got), len(large))
+		}
+	}
+	want := []string{"gzip", "", ""}
+	if got := encodings(); !slices.Equal(got, want) {
+		t.Errorf("encodings = %q, want %q", got, want)
+	}
+}
+
+func TestCompressRequestIncompressible(t *testing.T) {
+	// random data does not get smaller, it is sent as it is
+	payload := make([]byte, 2048)
+	rand.Read(payload)
+	req, _ := http.NewRequest(http.MethodPost, "http://suma", bytes.NewReader(payload))
+	creq, err := compressRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creq != req {
+		t.Errorf("compressed %d random bytes to %d bytes, want the request unchanged", req.ContentLength, creq.ContentLength)
+	}
+}
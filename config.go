@@ -1,22 +1,630 @@
 package appapi
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Environment variables of appapi. Every credential setting (marked with *) could also be
+// read from a file by setting the variable with the suffix _FILE, e.g. APPAPI_SUMA_PASSWORD_FILE.
+//
+//	APPAPI_SUMA_URL             URL of the SUSE Manager, e.g. https://suma.example.com
+//	APPAPI_SUMA_USERNAME *      SUSE Manager login
+//	APPAPI_SUMA_PASSWORD *      SUSE Manager password
+//	APPAPI_SUMA_TOKEN *         SUSE Manager API token, used instead of username and password
+//	APPAPI_MS_URL               URL of the Meshstack API, e.g. https://federation.example.com
+//	APPAPI_MS_CLIENT_ID *       Meshstack API client id
+//	APPAPI_MS_CLIENT_SECRET *   Meshstack API client secret
+//	APPAPI_MS_OIDC_TOKEN_URL    token endpoint of the identity provider of Meshstack, the client id
+//	                            and secret get the tokens with the OIDC client credentials grant
+//	                            instead of /api/login, see OIDCTokenSource (default none)
+//	APPAPI_MS_OIDC_ISSUER       issuer of the identity provider, whose token endpoint is discovered
+//	                            if APPAPI_MS_OIDC_TOKEN_URL is not set (default none)
+//	APPAPI_MS_OIDC_SCOPES       comma separated list of scopes requested for the tokens
+//	APPAPI_MS_OIDC_AUDIENCE     audience requested for the tokens
+//	APPAPI_VAULT_ADDR           URL of Hashicorp Vault
+//	APPAPI_VAULT_ROLE_ID *      Vault AppRole role id
+//	APPAPI_VAULT_SECRET_ID *    Vault AppRole secret id
+//	APPAPI_VAULT_SUMA_PATH      KV v2 path with the SUMA credentials, e.g. kv-clab-group/data/suma
+//	APPAPI_VAULT_MS_PATH        KV v2 path with the Meshstack credentials
+//	APPAPI_NETWORKS             comma separated list of permitted networks in CIDR notation
+//	APPAPI_SESSION_CACHE        file of the encrypted session cache, disabled if empty
+//	APPAPI_SESSION_CACHE_KEY *  passphrase of the session cache
+//	APPAPI_ENVIRONMENTS         comma separated list of additional environments, e.g. dev,prod
+//	APPAPI_<ENV>_*              settings of an environment, e.g. APPAPI_PROD_SUMA_URL. Supported are
+//	                            SUMA_URL, MS_URL, NETWORKS and the credential settings. Settings
+//	                            which are not set for the environment are taken from the defaults.
+//	APPAPI_TIMEOUT              timeout of an API call as duration (default 30s)
+//	APPAPI_RETRIES              number of retries of a throttled API call (HTTP 429 or 503), see
+//	                            RetryPolicy (default 3)
+//	APPAPI_READ_CACHE_TTL       cache group, user and building block listings for a duration,
+//	                            disabled if 0 (default 0)
+//	APPAPI_ETAG_CACHE_SIZE      number of Meshstack responses kept for conditional requests with
+//	                            their ETag, disabled if 0 (default 0)
+//	APPAPI_HOSTNAME_DOMAIN      domain appended to short hostnames, e.g. example.com (default none)
+//	APPAPI_ALLOW_INSECURE       allow http:// URLs and skip the verification of TLS certificates,
+//	                            only for tests and labs (default false)
+//	APPAPI_MAX_RESPONSE_SIZE    maximum size of a decompressed API response in bytes, unlimited if 0
+//	                            (default 67108864, 64 MiB)
+//	APPAPI_REQUEST_COMPRESSION_MIN_SIZE
+//	                            size in bytes from which request bodies are sent gzip compressed to
+//	                            servers accepting it, disabled if 0 (default 0)
+//	APPAPI_MAX_IDLE_CONNS_PER_HOST
+//	                            idle connections kept per server for the next calls, bulk operations
+//	                            with a higher concurrency open new connections (default 16)
+//	APPAPI_MAX_CONNS_PER_HOST   maximum number of connections per server, further calls wait for a
+//	                            free connection; unlimited if 0 (default 0)
+//	APPAPI_SUMA_MAX_CONCURRENCY maximum number of calls in flight to the SUMA server of a client,
+//	                            see WithHostConcurrency; unlimited if 0 (default 0)
+//	APPAPI_MS_MAX_CONCURRENCY   maximum number of calls in flight to the Meshstack API of a client;
+//	                            unlimited if 0 (default 0)
+//	APPAPI_VERBOSE              enable debug output (default false)
+//	APPAPI_LOG_LEVEL            minimum level of the log output, debug, info, warn or error; debug
+//	                            messages are only written with APPAPI_VERBOSE (default debug)
+//	APPAPI_LOG_FORMAT           format of the log output, text or json (default text)
+const (
+	EnvSumaURL             = "APPAPI_SUMA_URL"
+	EnvSumaUsername        = "APPAPI_SUMA_USERNAME"
+	EnvSumaPassword        = "APPAPI_SUMA_PASSWORD"
+	EnvSumaToken           = "APPAPI_SUMA_TOKEN"
+	EnvMsURL               = "APPAPI_MS_URL"
+	EnvMsClientID          = "APPAPI_MS_CLIENT_ID"
+	EnvMsClientSecret      = "APPAPI_MS_CLIENT_SECRET"
+	EnvMsOIDCTokenURL      = "APPAPI_MS_OIDC_TOKEN_URL"
+	EnvMsOIDCIssuer        = "APPAPI_MS_OIDC_ISSUER"
+	EnvMsOIDCScopes        = "APPAPI_MS_OIDC_SCOPES"
+	EnvMsOIDCAudience      = "APPAPI_MS_OIDC_AUDIENCE"
+	EnvVaultAddr           = "APPAPI_VAULT_ADDR"
+	EnvVaultRoleID         = "APPAPI_VAULT_ROLE_ID"
+	EnvVaultSecretID       = "APPAPI_VAULT_SECRET_ID"
+	EnvVaultSumaPath       = "APPAPI_VAULT_SUMA_PATH"
+	EnvVaultMsPath         = "APPAPI_VAULT_MS_PATH"
+	EnvNetworks            = "APPAPI_NETWORKS"
+	EnvSessionCache        = "APPAPI_SESSION_CACHE"
+	EnvSessionCacheKey     = "APPAPI_SESSION_CACHE_KEY"
+	EnvEnvironments        = "APPAPI_ENVIRONMENTS"
+	EnvTimeout             = "APPAPI_TIMEOUT"
+	EnvRetries             = "APPAPI_RETRIES"
+	EnvReadCacheTTL        = "APPAPI_READ_CACHE_TTL"
+	EnvETagCacheSize       = "APPAPI_ETAG_CACHE_SIZE"
+	EnvMaxResponseSize     = "APPAPI_MAX_RESPONSE_SIZE"
+	EnvRequestCompression  = "APPAPI_REQUEST_COMPRESSION_MIN_SIZE"
+	EnvAllowInsecure       = "APPAPI_ALLOW_INSECURE"
+	EnvMaxIdleConnsPerHost = "APPAPI_MAX_IDLE_CONNS_PER_HOST"
+	EnvMaxConnsPerHost     = "APPAPI_MAX_CONNS_PER_HOST"
+	EnvSumaMaxConcurrency  = "APPAPI_SUMA_MAX_CONCURRENCY"
+	EnvMsMaxConcurrency    = "APPAPI_MS_MAX_CONCURRENCY"
+	EnvHostnameDomain      = "APPAPI_HOSTNAME_DOMAIN"
+	EnvVerbose             = "APPAPI_VERBOSE"
+	EnvLogLevel            = "APPAPI_LOG_LEVEL"
+	EnvLogFormat           = "APPAPI_LOG_FORMAT"
+)
+
+const (
+	defaultTimeout             = 30 * time.Second
+	defaultRetries             = 3
+	defaultMaxResponseSize     = 64 << 20
+	defaultMaxIdleConnsPerHost = 16
+	configFileSuffix           = "_FILE"
+)
+
+// Envs is the configuration read from the environment at startup
 var Envs = initConfig()
 
 func initConfig() Config {
-	return Config{
-		AnsibleHashiVaultRoleID:   getEnv("ansible_hashi_vault_role_id", ""),
-		AnsibleHashiVaultSecretID: getEnv("ansible_hashi_vault_secret_id", ""),
+	cfg, err := LoadConfig()
+	if err != nil {
+		slog.Warn("invalid appapi configuration, using defaults", "error", err)
 	}
+	return cfg
 }
 
-func getEnv(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+// LoadConfig reads the configuration from the APPAPI_* environment variables.
+// Values which could not be parsed are reported in the error and replaced by their default.
+func LoadConfig() (Config, error) {
+	return loadConfig(os.LookupEnv)
+}
+
+func loadConfig(lookup func(string) (string, bool)) (Config, error) {
+	l := configLoader{lookup: lookup}
+	cfg := l.config()
+	return cfg, errors.Join(l.errs...)
+}
+
+// LoadConfigFile reads the configuration from a file with lines KEY=VALUE, using the same
+// APPAPI_* names as the environment variables. Empty lines and lines starting with # are ignored.
+// Values in the file take precedence over the environment.
+func LoadConfigFile(path string) (Config, error) {
+	l, err := newFileConfigLoader(path)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := l.config()
+	return cfg, errors.Join(l.errs...)
+}
+
+// newFileConfigLoader returns a loader which looks up the keys in the config file first
+func newFileConfigLoader(path string) (*configLoader, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %v", err)
+	}
+
+	values := map[string]string{}
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n+1)
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+
+	return &configLoader{
+		lookup: func(key string) (string, bool) {
+			if value, ok := values[key]; ok {
+				return value, true
+			}
+			return os.LookupEnv(key)
+		},
+		files: []string{path},
+	}, nil
+}
+
+// configLoader reads typed values and collects the parse errors and the files read
+type configLoader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+	files  []string
+}
+
+func (l *configLoader) config() Config {
+	cfg := Config{
+		VaultAddr:           l.str(EnvVaultAddr, ""),
+		VaultRoleID:         l.secret(EnvVaultRoleID, ""),
+		VaultSecretID:       l.secret(EnvVaultSecretID, ""),
+		VaultSumaPath:       l.str(EnvVaultSumaPath, ""),
+		VaultMeshstackPath:  l.str(EnvVaultMsPath, ""),
+		SumaURL:             l.str(EnvSumaURL, ""),
+		SumaUsername:        l.secret(EnvSumaUsername, ""),
+		SumaPassword:        l.secret(EnvSumaPassword, ""),
+		SumaToken:           l.secret(EnvSumaToken, ""),
+		MsURL:               l.str(EnvMsURL, ""),
+		MsClientID:          l.secret(EnvMsClientID, ""),
+		MsClientSecret:      l.secret(EnvMsClientSecret, ""),
+		MsOIDCTokenURL:      l.str(EnvMsOIDCTokenURL, ""),
+		MsOIDCIssuer:        l.str(EnvMsOIDCIssuer, ""),
+		MsOIDCScopes:        splitList(l.str(EnvMsOIDCScopes, "")),
+		MsOIDCAudience:      l.str(EnvMsOIDCAudience, ""),
+		Networks:            splitList(l.str(EnvNetworks, "")),
+		SessionCachePath:    l.str(EnvSessionCache, ""),
+		SessionCacheKey:     l.secret(EnvSessionCacheKey, ""),
+		Timeout:             l.duration(EnvTimeout, defaultTimeout),
+		Retries:             l.integer(EnvRetries, defaultRetries),
+		ReadCacheTTL:        l.duration(EnvReadCacheTTL, 0),
+		ETagCacheSize:       l.integer(EnvETagCacheSize, 0),
+		MaxResponseSize:     int64(l.integer(EnvMaxResponseSize, defaultMaxResponseSize)),
+		CompressionMinSize:  int64(l.integer(EnvRequestCompression, 0)),
+		AllowInsecure:       l.boolean(EnvAllowInsecure, false),
+		MaxIdleConnsPerHost: l.integer(EnvMaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:     l.integer(EnvMaxConnsPerHost, 0),
+		SumaMaxConcurrency:  l.integer(EnvSumaMaxConcurrency, 0),
+		MsMaxConcurrency:    l.integer(EnvMsMaxConcurrency, 0),
+		HostnameDomain:      l.str(EnvHostnameDomain, ""),
+		Verbose:             l.boolean(EnvVerbose, false),
+		LogLevel:            l.logLevel(EnvLogLevel, slog.LevelDebug),
+		LogFormat:           l.oneOf(EnvLogFormat, "text", "text", "json"),
+	}
+
+	for _, name := range splitList(l.str(EnvEnvironments, "")) {
+		if cfg.Environments == nil {
+			cfg.Environments = map[string]Environment{}
+		}
+		cfg.Environments[name] = l.environment(name, cfg)
+	}
+
+	return cfg
+}
+
+// environment reads the settings of a named environment, APPAPI_<NAME>_*, with the
+// default settings as fallback
+func (l *configLoader) environment(name string, defaults Config) Environment {
+	key := func(env string) string {
+		return strings.Replace(env, "APPAPI_", "APPAPI_"+strings.ToUpper(name)+"_", 1)
+	}
+
+	networks := defaults.Networks
+	if value, ok := l.lookup(key(EnvNetworks)); ok {
+		networks = splitList(value)
+	}
+
+	return Environment{
+		Name:     name,
+		SumaURL:  l.str(key(EnvSumaURL), defaults.SumaURL),
+		MsURL:    l.str(key(EnvMsURL), defaults.MsURL),
+		Networks: networks,
+		Credentials: Credentials{
+			SumaUsername:   l.secret(key(EnvSumaUsername), defaults.SumaUsername),
+			SumaPassword:   l.secret(key(EnvSumaPassword), defaults.SumaPassword),
+			SumaToken:      l.secret(key(EnvSumaToken), defaults.SumaToken),
+			MsClientID:     l.secret(key(EnvMsClientID), defaults.MsClientID),
+			MsClientSecret: l.secret(key(EnvMsClientSecret), defaults.MsClientSecret),
+		},
+	}
+}
+
+// Environment returns the endpoints of a named environment. The empty name returns the default
+// environment of the top level settings.
+func (c Config) Environment(name string) (Environment, error) {
+	if name == "" {
+		return Environment{
+			SumaURL:  c.SumaURL,
+			MsURL:    c.MsURL,
+			Networks: c.Networks,
+			Credentials: Credentials{
+				SumaUsername:   c.SumaUsername,
+				SumaPassword:   c.SumaPassword,
+				SumaToken:      c.SumaToken,
+				MsClientID:     c.MsClientID,
+				MsClientSecret: c.MsClientSecret,
+			},
+		}, nil
+	}
+
+	env, ok := c.Environments[name]
+	if !ok {
+		return Environment{}, fmt.Errorf("environment %s is not configured, add it to %s", name, EnvEnvironments)
+	}
+	return env, nil
+}
+
+// OIDC returns the identity provider of Meshstack, ok is false if the tokens are got with /api/login
+func (c Config) OIDC() (oidc OIDCConfig, ok bool) {
+	if c.MsOIDCTokenURL == "" && c.MsOIDCIssuer == "" {
+		return oidc, false
+	}
+	return OIDCConfig{TokenURL: c.MsOIDCTokenURL, Issuer: c.MsOIDCIssuer, Scopes: c.MsOIDCScopes, Audience: c.MsOIDCAudience}, true
+}
+
+// NewClients creates the SUMA and Meshstack clients of an environment, see Config.Environment.
+// If creds is nil, the credentials of the environment are used. A client is nil if the
+// environment has no URL for the backend.
+func (c Config) NewClients(name string, creds CredentialProvider) (suma *SumaClient, ms *MsClient, err error) {
+	env, creds, err := c.environmentCredentials(name, creds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if suma, err = c.newSumaClient(name, env, creds); err != nil {
+		return nil, nil, err
+	}
+	if ms, err = c.newMsClient(name, env, creds); err != nil {
+		if suma != nil {
+			suma.Close()
+		}
+		return nil, nil, err
+	}
+	return suma, ms, nil
+}
+
+// NewSumaClient creates only the SUMA client of an environment, see NewClients. The client is nil
+// if the environment has no SUMA URL.
+func (c Config) NewSumaClient(name string, creds CredentialProvider) (*SumaClient, error) {
+	env, creds, err := c.environmentCredentials(name, creds)
+	if err != nil {
+		return nil, err
+	}
+	return c.newSumaClient(name, env, creds)
+}
+
+// NewMsClient creates only the Meshstack client of an environment, see NewClients. The client is
+// nil if the environment has no Meshstack URL.
+func (c Config) NewMsClient(name string, creds CredentialProvider) (*MsClient, error) {
+	env, creds, err := c.environmentCredentials(name, creds)
+	if err != nil {
+		return nil, err
+	}
+	return c.newMsClient(name, env, creds)
+}
+
+// environmentCredentials returns the environment and creds, or the credentials of the environment
+// if creds is nil
+func (c Config) environmentCredentials(name string, creds CredentialProvider) (Environment, CredentialProvider, error) {
+	env, err := c.Environment(name)
+	if err != nil {
+		return env, nil, err
+	}
+	if creds == nil {
+		creds = NewStaticCredentialProvider(env.Credentials)
+	}
+	return env, creds, nil
+}
+
+func (c Config) newSumaClient(name string, env Environment, creds CredentialProvider) (*SumaClient, error) {
+	if env.SumaURL == "" {
+		return nil, nil
+	}
+	opts := append(c.clientOptions(), WithHostConcurrency(c.SumaMaxConcurrency))
+	if c.SystemMatch != "" {
+		opts = append(opts, WithSystemMatch(c.SystemMatch))
+	}
+	suma, err := NewSumaClient(env.SumaURL, creds, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("environment %s: %v", name, err)
+	}
+	suma.Networks = env.Networks
+	suma.Hostnames = HostnameNormalization{Domain: c.HostnameDomain}
+	if c.ReadCacheTTL > 0 {
+		suma.EnableReadCache(c.ReadCacheTTL)
+	}
+	return suma, nil
+}
+
+func (c Config) newMsClient(name string, env Environment, creds CredentialProvider) (ms *MsClient, err error) {
+	if env.MsURL == "" {
+		return nil, nil
+	}
+	opts := append(c.clientOptions(), WithHostConcurrency(c.MsMaxConcurrency))
+	if oidc, ok := c.OIDC(); ok {
+		ms, err = NewMsClientWithTokenSource(env.MsURL, NewOIDCTokenSource(oidc, creds, opts...), opts...)
+	} else {
+		ms, err = NewMsClient(env.MsURL, creds, opts...)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("environment %s: %v", name, err)
+	}
+	if c.ReadCacheTTL > 0 {
+		ms.EnableReadCache(c.ReadCacheTTL)
+	}
+	if c.ETagCacheSize > 0 {
+		ms.EnableETagCache(c.ETagCacheSize)
+	}
+	return ms, nil
+}
+
+// vaultAppRole returns the Vault address and the AppRole credentials, the deprecated AnsibleHashiVault
+// fields are used for the empty ones
+func (c Config) vaultAppRole() (addr, roleID, secretID string) {
+	return cmp.Or(c.VaultAddr, c.AnsibleHashiVaultAddr), cmp.Or(c.VaultRoleID, c.AnsibleHashiVaultRoleID), cmp.Or(c.VaultSecretID, c.AnsibleHashiVaultSecretID)
+}
+
+// clientOptions returns the options of the clients, a zero timeout keeps the default and AllowInsecure
+// applies unless the configuration allows insecure URLs
+func (c Config) clientOptions() []Option {
+	opts := []Option{WithVerbose(c.Verbose)}
+	if c.Timeout > 0 {
+		opts = append(opts, WithTimeout(c.Timeout))
+	}
+	if c.AllowInsecure {
+		opts = append(opts, WithInsecure(true))
+	}
+	return opts
+}
 
+func (l *configLoader) str(key, fallback string) string {
+	if value, ok := l.lookup(key); ok {
+		return value
+	}
 	return fallback
 }
+
+// secret returns the value of a credential setting. If the variable key_FILE is set
+// (e.g. APPAPI_SUMA_PASSWORD_FILE=/run/secrets/suma_password), the value is read from that file
+// instead, as provided by Docker and Kubernetes secret mounts. A trailing newline is removed.
+func (l *configLoader) secret(key, fallback string) string {
+	if path, ok := l.lookup(key + configFileSuffix); ok {
+		l.files = append(l.files, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("%s%s: %v", key, configFileSuffix, err))
+			return fallback
+		}
+		return strings.TrimRight(string(data), "\r\n")
+	}
+	return l.str(key, fallback)
+}
+
+func (l *configLoader) duration(key string, fallback time.Duration) time.Duration {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: %q is not a duration (e.g. 30s, 2m)", key, value))
+		return fallback
+	}
+	return d
+}
+
+func (l *configLoader) integer(key string, fallback int) int {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: %q is not a number", key, value))
+		return fallback
+	}
+	return i
+}
+
+func (l *configLoader) boolean(key string, fallback bool) bool {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: %q is not a boolean (true/false)", key, value))
+		return fallback
+	}
+	return b
+}
+
+// getSecret returns the value of a credential environment variable, see configLoader.secret
+func getSecret(key, fallback string) string {
+	l := configLoader{lookup: os.LookupEnv}
+	value := l.secret(key, fallback)
+	for _, err := range l.errs {
+		logErrorf("could not read secret: %v", err)
+	}
+	return value
+}
+
+// splitList splits a comma separated list and drops empty entries
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Validate checks the configuration and returns an error listing every problem found.
+// A backend (SUMA, Meshstack) is enabled when its URL is set, it then needs either
+// credentials or a Vault path to read them from.
+func (c Config) Validate() error {
+	var errs []error
+
+	vaultUsed := c.VaultSumaPath != "" || c.VaultMeshstackPath != ""
+	c.VaultAddr, c.VaultRoleID, c.VaultSecretID = c.vaultAppRole()
+
+	if c.SumaURL != "" {
+		if err := validateURL(c.SumaURL, c.AllowInsecure); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", EnvSumaURL, err))
+		}
+		if c.VaultSumaPath == "" && c.SumaToken == "" {
+			if c.SumaUsername == "" {
+				errs = append(errs, fmt.Errorf("suma username is missing, set %s or %s", EnvSumaUsername, EnvVaultSumaPath))
+			}
+			if c.SumaPassword == "" {
+				errs = append(errs, fmt.Errorf("suma password is missing, set %s or %s", EnvSumaPassword, EnvVaultSumaPath))
+			}
+		}
+	}
+
+	if c.MsURL != "" {
+		if err := validateURL(c.MsURL, c.AllowInsecure); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", EnvMsURL, err))
+		}
+		if c.VaultMeshstackPath == "" {
+			if c.MsClientID == "" {
+				errs = append(errs, fmt.Errorf("meshstack client id is missing, set %s or %s", EnvMsClientID, EnvVaultMsPath))
+			}
+			if c.MsClientSecret == "" {
+				errs = append(errs, fmt.Errorf("meshstack client secret is missing, set %s or %s", EnvMsClientSecret, EnvVaultMsPath))
+			}
+		}
+		for _, setting := range [][2]string{{EnvMsOIDCTokenURL, c.MsOIDCTokenURL}, {EnvMsOIDCIssuer, c.MsOIDCIssuer}} {
+			if setting[1] == "" {
+				continue
+			}
+			if err := validateURL(setting[1], c.AllowInsecure); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", setting[0], err))
+			}
+		}
+	}
+
+	if c.SumaURL == "" && c.MsURL == "" {
+		errs = append(errs, fmt.Errorf("neither suma url nor meshstack url is configured, set %s or %s", EnvSumaURL, EnvMsURL))
+	}
+
+	if vaultUsed {
+		if c.VaultAddr == "" {
+			errs = append(errs, fmt.Errorf("vault address is missing, set %s to use the vault paths", EnvVaultAddr))
+		} else if err := validateURL(c.VaultAddr, c.AllowInsecure); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", EnvVaultAddr, err))
+		}
+		if c.VaultRoleID == "" {
+			errs = append(errs, fmt.Errorf("vault role id is missing, set %s to use the vault paths", EnvVaultRoleID))
+		}
+		if c.VaultSecretID == "" {
+			errs = append(errs, fmt.Errorf("vault secret id is missing, set %s to use the vault paths", EnvVaultSecretID))
+		}
+	}
+
+	for name, env := range c.Environments {
+		if env.SumaURL != "" {
+			if err := validateURL(env.SumaURL, c.AllowInsecure); err != nil {
+				errs = append(errs, fmt.Errorf("environment %s suma url: %v", name, err))
+			}
+		}
+		if env.MsURL != "" {
+			if err := validateURL(env.MsURL, c.AllowInsecure); err != nil {
+				errs = append(errs, fmt.Errorf("environment %s meshstack url: %v", name, err))
+			}
+		}
+	}
+
+	if c.SessionCachePath != "" && c.SessionCacheKey == "" {
+		errs = append(errs, fmt.Errorf("session cache key is missing, set %s to use the session cache", EnvSessionCacheKey))
+	}
+
+	for _, network := range c.Networks {
+		if _, _, err := net.ParseCIDR(network); err != nil {
+			errs = append(errs, fmt.Errorf("%s: network %q is not in CIDR notation (e.g. 192.168.1.0/24)", EnvNetworks, network))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateURL checks that a URL is absolute with https scheme and a host. http is only accepted
+// with allowInsecure.
+func validateURL(rawURL string, allowInsecure bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid URL: %v", rawURL, err)
+	}
+	switch {
+	case u.Scheme == "http" && !allowInsecure:
+		return fmt.Errorf("%q uses plain http, use https:// or set %s", rawURL, EnvAllowInsecure)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Errorf("%q must start with https://", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", rawURL)
+	}
+	return nil
+}
+
+func (l *configLoader) logLevel(key string, fallback slog.Level) slog.Level {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: %q is not a log level (debug, info, warn or error)", key, value))
+		return fallback
+	}
+	return level
+}
+
+func (l *configLoader) oneOf(key, fallback string, values ...string) string {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	if !slices.Contains(values, value) {
+		l.errs = append(l.errs, fmt.Errorf("%s: %q is not one of %s", key, value, strings.Join(values, ", ")))
+		return fallback
+	}
+	return value
+}
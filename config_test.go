@@ -0,0 +1,196 @@
+package appapi
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		wantErrs []string
+	}{
+		{
+			name: "valid suma and meshstack",
+			cfg: Config{
+				SumaURL: "https://suma.example.com", SumaUsername: "admin", SumaPassword: "secret",
+				MsURL: "https://meshstack.example.com", MsClientID: "client", MsClientSecret: "secret",
+				Networks: []string{"192.168.1.0/24", "2001:db8::/64"},
+			},
+		},
+		{
+			name: "credentials from vault",
+			cfg: Config{
+				SumaURL: "https://suma.example.com", VaultSumaPath: "kv-clab-x/data/suma",
+				VaultAddr: "https://vault.example.com", VaultRoleID: "role", VaultSecretID: "secret",
+			},
+		},
+		{
+			name: "credentials from vault with deprecated fields",
+			cfg: Config{
+				SumaURL: "https://suma.example.com", VaultSumaPath: "kv-clab-x/data/suma",
+				AnsibleHashiVaultAddr: "https://vault.example.com", AnsibleHashiVaultRoleID: "role",
+				AnsibleHashiVaultSecretID: "secret",
+			},
+		},
+		{
+			name: "suma api token",
+			cfg:  Config{SumaURL: "https://suma.example.com", SumaToken: "token"},
+		},
+		{
+			name: "meshstack oidc issuer over plain http",
+			cfg: Config{
+				MsURL: "https://meshstack.example.com", MsClientID: "client", MsClientSecret: "secret",
+				MsOIDCIssuer: "http://sso.example.com/realms/mesh",
+			},
+			wantErrs: []string{EnvMsOIDCIssuer, "uses plain http"},
+		},
+		{
+			name: "plain http",
+			cfg: Config{
+				SumaURL: "http://suma.example.com", SumaUsername: "admin", SumaPassword: "secret",
+			},
+			wantErrs: []string{"uses plain http", EnvAllowInsecure},
+		},
+		{
+			name: "plain http allowed",
+			cfg: Config{
+				SumaURL: "http://suma.example.com", SumaUsername: "admin", SumaPassword: "secret",
+				AllowInsecure: true,
+			},
+		},
+		{
+			name:     "nothing configured",
+			cfg:      Config{},
+			wantErrs: []string{"neither suma url nor meshstack url"},
+		},
+		{
+			name: "every problem is listed",
+			cfg: Config{
+				SumaURL:       "suma.example.com",
+				MsURL:         "https://meshstack.example.com",
+				VaultSumaPath: "kv/data/suma",
+				Networks:      []string{"192.168.1.0"},
+			},
+			wantErrs: []string{
+				EnvSumaURL,
+				"meshstack client id is missing",
+				"meshstack client secret is missing",
+				"vault address is missing",
+				"vault role id is missing",
+				"vault secret id is missing",
+				`network "192.168.1.0"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() returned error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() returned nil, want errors %v", tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suma_password")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("test_secret", "from-env")
+	if got := getSecret("test_secret", ""); got != "from-env" {
+		t.Errorf("getSecret() = %q, want from-env", got)
+	}
+
+	t.Setenv("test_secret_FILE", path)
+	if got := getSecret("test_secret", ""); got != "from-file" {
+		t.Errorf("getSecret() = %q, want from-file", got)
+	}
+
+	t.Setenv("test_secret_FILE", filepath.Join(t.TempDir(), "missing"))
+	if got := getSecret("test_secret", "fallback"); got != "fallback" {
+		t.Errorf("getSecret() = %q, want fallback", got)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	env := map[string]string{
+		EnvSumaURL:            "https://suma.example.com",
+		EnvNetworks:           "192.168.1.0/24, 10.0.0.0/8",
+		EnvTimeout:            "1m",
+		EnvRetries:            "5",
+		EnvVerbose:            "true",
+		EnvLogLevel:           "warn",
+		EnvMaxConnsPerHost:    "20",
+		EnvETagCacheSize:      "100",
+		EnvMsMaxConcurrency:   "4",
+		EnvRequestCompression: "8192",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	cfg, err := loadConfig(lookup)
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if cfg.SumaURL != "https://suma.example.com" || cfg.Timeout != time.Minute || cfg.Retries != 5 || !cfg.Verbose {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if len(cfg.Networks) != 2 || cfg.Networks[1] != "10.0.0.0/8" {
+		t.Errorf("unexpected networks: %v", cfg.Networks)
+	}
+	if cfg.LogLevel != slog.LevelWarn || cfg.LogFormat != "text" {
+		t.Errorf("unexpected log settings: %v %s", cfg.LogLevel, cfg.LogFormat)
+	}
+	if cfg.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost || cfg.MaxConnsPerHost != 20 {
+		t.Errorf("unexpected connection limits: %d idle, %d per host", cfg.MaxIdleConnsPerHost, cfg.MaxConnsPerHost)
+	}
+	if cfg.SumaMaxConcurrency != 0 || cfg.MsMaxConcurrency != 4 {
+		t.Errorf("unexpected concurrency limits: %d SUMA, %d Meshstack", cfg.SumaMaxConcurrency, cfg.MsMaxConcurrency)
+	}
+	if cfg.CompressionMinSize != 8192 {
+		t.Errorf("unexpected compression size: %d", cfg.CompressionMinSize)
+	}
+	if cfg.ETagCacheSize != 100 || cfg.ReadCacheTTL != 0 {
+		t.Errorf("unexpected caches: %d ETags, read cache %v", cfg.ETagCacheSize, cfg.ReadCacheTTL)
+	}
+
+	env[EnvTimeout] = "soon"
+	env[EnvRetries] = "many"
+	env[EnvVerbose] = "yes please"
+	env[EnvLogLevel] = "loud"
+	env[EnvLogFormat] = "xml"
+	cfg, err = loadConfig(lookup)
+	if err == nil {
+		t.Fatal("expected parse errors, got nil")
+	}
+	for _, key := range []string{EnvTimeout, EnvRetries, EnvVerbose, EnvLogLevel, EnvLogFormat} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error %q does not mention %s", err, key)
+		}
+	}
+	if cfg.Timeout != defaultTimeout || cfg.Retries != defaultRetries || cfg.Verbose || cfg.LogLevel != slog.LevelDebug || cfg.LogFormat != "text" {
+		t.Errorf("expected defaults for invalid values, got %+v", cfg)
+	}
+}
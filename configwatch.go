@@ -0,0 +1,162 @@
+package appapi
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ConfigWatcher holds the current configuration and reloads it on SIGHUP or when the config file
+// or one of the secret files (APPAPI_*_FILE) changes. The configuration is swapped atomically, so
+// running clients using the watcher's CredentialProvider pick up rotated passwords without a restart.
+type ConfigWatcher struct {
+	path    string
+	verbose bool
+
+	current atomic.Pointer[Config]
+
+	mu        sync.Mutex
+	files     map[string]time.Time
+	listeners []func(Config)
+}
+
+// NewConfigWatcher loads the configuration from the config file. If path is empty, the
+// configuration is read from the environment only.
+func NewConfigWatcher(path string, verbose bool) (*ConfigWatcher, error) {
+	w := &ConfigWatcher{path: path, verbose: verbose}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Config returns the current configuration
+func (w *ConfigWatcher) Config() Config {
+	return *w.current.Load()
+}
+
+// OnReload registers a function which is called with the new configuration after every reload
+func (w *ConfigWatcher) OnReload(fn func(Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, fn)
+}
+
+// Reload reads the configuration again. If it could not be read, the current configuration is kept.
+func (w *ConfigWatcher) Reload() error {
+	var l *configLoader
+	if w.path != "" {
+		var err error
+		if l, err = newFileConfigLoader(w.path); err != nil {
+			return err
+		}
+	} else {
+		l = &configLoader{lookup: os.LookupEnv}
+	}
+
+	cfg := l.config()
+	if err := errors.Join(l.errs...); err != nil {
+		return err
+	}
+
+	w.current.Store(&cfg)
+
+	w.mu.Lock()
+	w.files = make(map[string]time.Time, len(l.files))
+	for _, file := range l.files {
+		w.files[file] = modTime(file)
+	}
+	listeners := append([]func(Config){}, w.listeners...)
+	w.mu.Unlock()
+
+	if w.verbose {
+		logDebugf("CONFIG ConfigWatcher: configuration loaded, watching %d file(s)", len(l.files))
+	}
+
+	for _, fn := range listeners {
+		fn(cfg)
+	}
+	return nil
+}
+
+// Run reloads the configuration on SIGHUP and checks the files for changes every interval
+// until the context is canceled.
+func (w *ConfigWatcher) Run(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := DefaultClock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if w.verbose {
+				logDebugf("CONFIG ConfigWatcher: got SIGHUP, reload configuration")
+			}
+		case <-ticker.Chan():
+			if !w.changed() {
+				continue
+			}
+			if w.verbose {
+				logDebugf("CONFIG ConfigWatcher: file changed, reload configuration")
+			}
+		}
+
+		if err := w.Reload(); err != nil {
+			logWarnf("could not reload configuration, keep current configuration: %v", err)
+		}
+	}
+}
+
+// changed checks if one of the watched files has a different modification time
+func (w *ConfigWatcher) changed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for file, mtime := range w.files {
+		if !modTime(file).Equal(mtime) {
+			return true
+		}
+	}
+	return false
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// CredentialProvider returns a provider which always returns the credentials of the current configuration
+func (w *ConfigWatcher) CredentialProvider() CredentialProvider {
+	return watcherCredentialProvider{w: w}
+}
+
+type watcherCredentialProvider struct {
+	w *ConfigWatcher
+}
+
+func (p watcherCredentialProvider) Get() (Credentials, error) {
+	cfg := p.w.Config()
+	return Credentials{
+		SumaUsername:   cfg.SumaUsername,
+		SumaPassword:   cfg.SumaPassword,
+		SumaToken:      cfg.SumaToken,
+		MsClientID:     cfg.MsClientID,
+		MsClientSecret: cfg.MsClientSecret,
+	}, nil
+}
+
+func (p watcherCredentialProvider) Refresh() error {
+	return p.w.Reload()
+}
@@ -0,0 +1,75 @@
+package appapi
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcher(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "suma_password")
+	configFile := filepath.Join(dir, "appapi.env")
+
+	if err := os.WriteFile(secretFile, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := "# test config\nAPPAPI_SUMA_URL=https://suma.example.com\nAPPAPI_SUMA_USERNAME=admin\nAPPAPI_SUMA_PASSWORD_FILE=" + secretFile + "\n"
+	if err := os.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewConfigWatcher(configFile, false)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher returned error: %v", err)
+	}
+	provider := w.CredentialProvider()
+
+	creds, _ := provider.Get()
+	if creds.SumaUsername != "admin" || creds.SumaPassword != "old" {
+		t.Fatalf("unexpected credentials: %+v", creds)
+	}
+
+	reloaded := make(chan Config, 1)
+	w.OnReload(func(cfg Config) { reloaded <- cfg })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx, 10*time.Millisecond)
+
+	// rotate the password, make sure the modification time changes
+	if err := os.WriteFile(secretFile, []byte("new\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(secretFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case cfg := <-reloaded:
+		if cfg.SumaPassword != "new" {
+			t.Errorf("expected new password after reload, got %q", cfg.SumaPassword)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+
+	creds, _ = provider.Get()
+	if creds.SumaPassword != "new" {
+		t.Errorf("provider returned %q, want new", creds.SumaPassword)
+	}
+
+	// an invalid config keeps the current configuration
+	if err := os.WriteFile(configFile, []byte("APPAPI_RETRIES=many\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); err == nil {
+		t.Error("expected error for invalid config, got nil")
+	}
+	if w.Config().SumaUsername != "admin" {
+		t.Errorf("expected previous configuration to be kept, got %+v", w.Config())
+	}
+}
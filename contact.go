@@ -0,0 +1,63 @@
+package appapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ContactMethod is how SUSE Manager contacts a system
+type ContactMethod string
+
+// The contact methods of a system
+const (
+	// ContactDefault lets the system connect to SUSE Manager
+	ContactDefault ContactMethod = "default"
+	// ContactSSHPush lets SUSE Manager connect to the system with SSH, e.g. for systems in a DMZ
+	ContactSSHPush ContactMethod = "ssh-push"
+	// ContactSSHPushTunnel is ContactSSHPush with the connections of the system tunneled through SSH
+	ContactSSHPushTunnel ContactMethod = "ssh-push-tunnel"
+)
+
+// Valid reports if the contact method is known
+func (m ContactMethod) Valid() bool {
+	switch m {
+	case ContactDefault, ContactSSHPush, ContactSSHPushTunnel:
+		return true
+	}
+	return false
+}
+
+// SumaSetContactMethod changes how SUSE Manager contacts a system
+func SumaSetContactMethod(sessioncookie, susemgr string, systemID int, method ContactMethod, verbose bool, opts ...Option) (err error) {
+	if !method.Valid() {
+		return &ValidationError{Payload: "system details", Field: "contact_method", Reason: fmt.Sprintf("%q is not one of default, ssh-push and ssh-push-tunnel", method)}
+	}
+	if verbose {
+		Logger().Debug("SUMAAPI SumaSetContactMethod: set contact method", "system_id", systemID, "contact_method", method)
+	}
+	payload := map[string]any{"sid": systemID, "details": map[string]any{"contact_method": method}}
+	return sumaPost(sessioncookie, susemgr, "system/setDetails", payload, nil, verbose, opts...)
+}
+
+// SumaGetContactMethod returns how SUSE Manager contacts a system
+func SumaGetContactMethod(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (method ContactMethod, err error) {
+	var details struct {
+		ContactMethod ContactMethod `json:"contact_method"`
+	}
+	query := url.Values{"sid": {strconv.Itoa(systemID)}}
+	err = sumaGet(sessioncookie, susemgr, "system/getDetails", query, &details, verbose, opts...)
+	return details.ContactMethod, err
+}
+
+// SetContactMethod changes how SUSE Manager contacts a system, see SumaSetContactMethod
+func (c *SumaClient) SetContactMethod(systemID int, method ContactMethod, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return SumaSetContactMethod(c.SessionCookie(), c.URL, systemID, method, verbose, opts...)
+}
+
+// ContactMethod returns how SUSE Manager contacts a system, see SumaGetContactMethod
+func (c *SumaClient) ContactMethod(systemID int, opts ...Option) (ContactMethod, error) {
+	verbose, opts := c.options(opts)
+	return SumaGetContactMethod(c.SessionCookie(), c.URL, systemID, verbose, opts...)
+}
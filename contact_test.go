@@ -0,0 +1,39 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSumaSetContactMethod(t *testing.T) {
+	var payload struct {
+		ID      int               `json:"sid"`
+		Details map[string]string `json:"details"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/system/setDetails" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		fmt.Fprint(w, `{"success": true, "result": 1}`)
+	}))
+	defer server.Close()
+
+	if err := SumaSetContactMethod("cookie", server.URL, 42, ContactSSHPush, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.ID != 42 || payload.Details["contact_method"] != "ssh-push" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	var verr *ValidationError
+	if err := SumaSetContactMethod("cookie", server.URL, 42, "ssh", false); !errors.As(err, &verr) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// CredentialProvider is the source of the SUSE Manager and Meshstack login data.
+// Get returns the current credentials, Refresh reloads them from the source,
+// e.g. after a password was rotated.
+type CredentialProvider interface {
+	Get() (Credentials, error)
+	Refresh() error
+}
+
+// cachedCredentials is the common part of the providers, it holds the last loaded
+// credentials and loads them on first use.
+type cachedCredentials struct {
+	mu     sync.RWMutex
+	creds  Credentials
+	loaded bool
+	load   func() (Credentials, error)
+}
+
+func (c *cachedCredentials) Get() (Credentials, error) {
+	c.mu.RLock()
+	if c.loaded {
+		defer c.mu.RUnlock()
+		return c.creds, nil
+	}
+	c.mu.RUnlock()
+
+	if err := c.Refresh(); err != nil {
+		return Credentials{}, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.creds, nil
+}
+
+func (c *cachedCredentials) Refresh() error {
+	creds, err := c.load()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.creds = creds
+	c.loaded = true
+	c.mu.Unlock()
+
+	return nil
+}
+
+// StaticCredentialProvider returns always the same credentials
+type StaticCredentialProvider struct {
+	creds Credentials
+}
+
+// NewStaticCredentialProvider creates a provider for fixed credentials
+func NewStaticCredentialProvider(creds Credentials) *StaticCredentialProvider {
+	return &StaticCredentialProvider{creds: creds}
+}
+
+// Get returns the static credentials
+func (p *StaticCredentialProvider) Get() (Credentials, error) {
+	return p.creds, nil
+}
+
+// Refresh does nothing for static credentials
+func (p *StaticCredentialProvider) Refresh() error {
+	return nil
+}
+
+// EnvCredentialProvider reads the credentials from the environment variables APPAPI_SUMA_USERNAME,
+// APPAPI_SUMA_PASSWORD, APPAPI_SUMA_TOKEN, APPAPI_MS_CLIENT_ID and APPAPI_MS_CLIENT_SECRET.
+// Every variable could also be given as file with the _FILE suffix, e.g. APPAPI_SUMA_PASSWORD_FILE.
+type EnvCredentialProvider struct {
+	cachedCredentials
+}
+
+// NewEnvCredentialProvider creates a provider for credentials in environment variables
+func NewEnvCredentialProvider() *EnvCredentialProvider {
+	p := &EnvCredentialProvider{}
+	p.load = func() (Credentials, error) {
+		return Credentials{
+			SumaUsername:   getSecret(EnvSumaUsername, ""),
+			SumaPassword:   getSecret(EnvSumaPassword, ""),
+			SumaToken:      getSecret(EnvSumaToken, ""),
+			MsClientID:     getSecret(EnvMsClientID, ""),
+			MsClientSecret: getSecret(EnvMsClientSecret, ""),
+		}, nil
+	}
+	return p
+}
+
+// FileCredentialProvider reads the credentials from a JSON file like
+//
+//	{
+//	  "suma_username": "admin",
+//	  "suma_password": "secret",
+//	  "suma_token": "token",
+//	  "meshstack_client_id": "client",
+//	  "meshstack_client_secret": "secret"
+//	}
+type FileCredentialProvider struct {
+	cachedCredentials
+	path string
+}
+
+// NewFileCredentialProvider creates a provider for credentials in a JSON file
+func NewFileCredentialProvider(path string) *FileCredentialProvider {
+	p := &FileCredentialProvider{path: path}
+	p.load = func() (Credentials, error) {
+		type credentialFile struct {
+			SumaUsername   string `json:"suma_username"`
+			SumaPassword   string `json:"suma_password"`
+			SumaToken      string `json:"suma_token"`
+			MsClientID     string `json:"meshstack_client_id"`
+			MsClientSecret string `json:"meshstack_client_secret"`
+		}
+
+		data, err := os.ReadFile(p.path)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("failed to read credential file: %v", err)
+		}
+
+		var f credentialFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			return Credentials{}, fmt.Errorf("failed to parse credential file %s: %v", p.path, err)
+		}
+
+		return Credentials{
+			SumaUsername:   f.SumaUsername,
+			SumaPassword:   f.SumaPassword,
+			SumaToken:      f.SumaToken,
+			MsClientID:     f.MsClientID,
+			MsClientSecret: f.MsClientSecret,
+		}, nil
+	}
+	return p
+}
+
+// VaultCredentialProvider reads the credentials from Hashicorp Vault, see VaultGetCredentials.
+type VaultCredentialProvider struct {
+	cachedCredentials
+}
+
+// NewVaultCredentialProvider creates a provider for credentials in Vault KV paths
+func NewVaultCredentialProvider(session *VaultSession, sumaPath, meshstackPath string) *VaultCredentialProvider {
+	p := &VaultCredentialProvider{}
+	p.load = func() (Credentials, error) {
+		return session.Credentials(sumaPath, meshstackPath)
+	}
+	return p
+}
+
+// SumaLoginWithProvider login to SUSE Manager with the credentials of a provider, with the API token
+// if the credentials have one, see SumaLoginWithToken. If the login fails, the credentials are
+// refreshed once and the login is retried, so rotated passwords and tokens are picked up.
+func SumaLoginWithProvider(provider CredentialProvider, susemgr string, verbose bool, opts ...Option) (sessioncookie string, err error) {
+	sessioncookie, _, err = sumaLoginWithProvider(provider, susemgr, verbose, opts...)
+	return sessioncookie, err
+}
+
+// sumaLoginWithProvider returns the session cookie and its Max-Age in seconds
+func sumaLoginWithProvider(provider CredentialProvider, susemgr string, verbose bool, opts ...Option) (sessioncookie string, maxAge int, err error) {
+
+	var creds Credentials
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			if verbose {
+				logDebugf("SUMAAPI SumaLoginWithProvider: login failed, refresh credentials")
+			}
+			if err := provider.Refresh(); err != nil {
+				return "", 0, fmt.Errorf("failed to refresh credentials: %v", err)
+			}
+		}
+
+		creds, err = provider.Get()
+		if err != nil {
+			return "", 0, err
+		}
+
+		if creds.SumaToken != "" {
+			sessioncookie, maxAge, err = sumaLoginWithToken(creds.SumaToken, susemgr, verbose, opts...)
+			if errors.Is(err, ErrUnsupportedByServer) {
+				return "", 0, err
+			}
+		} else {
+			sessioncookie, maxAge, err = sumaLogin(creds.SumaUsername, creds.SumaPassword, susemgr, verbose, opts...)
+		}
+		if err == nil && sessioncookie != "" {
+			return sessioncookie, maxAge, nil
+		}
+	}
+
+	if err == nil {
+		err = fmt.Errorf("login to %s failed, got no session cookie", susemgr)
+	}
+	return "", 0, err
+}
+
+// MsLoginWithProvider login to Meshstack with the credentials of a provider. If the login fails,
+// the credentials are refreshed once and the login is retried.
+func MsLoginWithProvider(provider CredentialProvider, apiurl string, verbose bool, opts ...Option) (accesstoken string, err error) {
+
+	var creds Credentials
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			if verbose {
+				logDebugf("MSAPI MsLoginWithProvider: login failed, refresh credentials")
+			}
+			if err := provider.Refresh(); err != nil {
+				return "", fmt.Errorf("failed to refresh credentials: %v", err)
+			}
+		}
+
+		creds, err = provider.Get()
+		if err != nil {
+			return "", err
+		}
+
+		accesstoken, err = MsLogin(creds.MsClientID, creds.MsClientSecret, apiurl, verbose, opts...)
+		if err == nil && accesstoken != "" {
+			return accesstoken, nil
+		}
+	}
+
+	if err == nil {
+		err = fmt.Errorf("login to %s failed, got no access token", apiurl)
+	}
+	return "", err
+}
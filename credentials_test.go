@@ -0,0 +1,107 @@
+package appapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvCredentialProvider(t *testing.T) {
+	t.Setenv(EnvSumaUsername, "admin")
+	t.Setenv(EnvSumaPassword, "secret")
+	t.Setenv(EnvMsClientID, "client")
+	t.Setenv(EnvMsClientSecret, "client-secret")
+
+	p := NewEnvCredentialProvider()
+	creds, err := p.Get()
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	want := Credentials{SumaUsername: "admin", SumaPassword: "secret", MsClientID: "client", MsClientSecret: "client-secret"}
+	if creds != want {
+		t.Errorf("Get() = %+v, want %+v", creds, want)
+	}
+
+	// rotated password is only visible after Refresh
+	t.Setenv(EnvSumaPassword, "rotated")
+	creds, _ = p.Get()
+	if creds.SumaPassword != "secret" {
+		t.Errorf("expected cached password, got %q", creds.SumaPassword)
+	}
+	if err := p.Refresh(); err != nil {
+		t.Fatalf("Refresh() returned error: %v", err)
+	}
+	creds, _ = p.Get()
+	if creds.SumaPassword != "rotated" {
+		t.Errorf("expected rotated password, got %q", creds.SumaPassword)
+	}
+}
+
+func TestFileCredentialProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "creds.json")
+	content := `{"suma_username": "admin", "suma_password": "secret", "meshstack_client_id": "client", "meshstack_client_secret": "client-secret"}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := NewFileCredentialProvider(path).Get()
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	if creds.SumaUsername != "admin" || creds.MsClientSecret != "client-secret" {
+		t.Errorf("unexpected credentials: %+v", creds)
+	}
+
+	_, err = NewFileCredentialProvider(filepath.Join(t.TempDir(), "missing.json")).Get()
+	if err == nil {
+		t.Error("expected error for missing file, got nil")
+	}
+}
+
+// rotatingProvider returns an outdated password until Refresh is called
+type rotatingProvider struct {
+	refreshed bool
+}
+
+func (p *rotatingProvider) Get() (Credentials, error) {
+	if p.refreshed {
+		return Credentials{SumaUsername: "admin", SumaPassword: "new"}, nil
+	}
+	return Credentials{SumaUsername: "admin", SumaPassword: "old"}, nil
+}
+
+func (p *rotatingProvider) Refresh() error {
+	p.refreshed = true
+	return nil
+}
+
+func TestSumaLoginWithProvider(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Password string `json:"password"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if payload.Password != "new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "pxt-session-cookie", Value: "cookie", MaxAge: 3600})
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mockServer.Close()
+
+	p := &rotatingProvider{}
+	cookie, err := SumaLoginWithProvider(p, mockServer.URL, false)
+	if err != nil {
+		t.Fatalf("SumaLoginWithProvider returned error: %v", err)
+	}
+	if cookie != "cookie" {
+		t.Errorf("expected cookie, got %q", cookie)
+	}
+	if !p.refreshed {
+		t.Error("expected credentials to be refreshed")
+	}
+}
@@ -0,0 +1,56 @@
+package appapi
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// maxPooledPayload is the capacity up to which a payload buffer is put back into the pool, larger
+// buffers of exceptional calls are left to the garbage collector
+const maxPooledPayload = 1 << 20
+
+// payloadBuffers are the buffers of the frequently sent payloads, e.g. systemgroup/addOrRemoveSystems
+// of a reconciliation of thousands of systems, and the scratch space of their validation
+var payloadBuffers = sync.Pool{New: func() any { return new([]byte) }}
+
+// getPayloadBuffer returns an empty buffer with a capacity of at least size bytes
+func getPayloadBuffer(size int) *[]byte {
+	buf := payloadBuffers.Get().(*[]byte)
+	if cap(*buf) < size {
+		*buf = make([]byte, 0, size)
+	}
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// putPayloadBuffer puts a buffer back into the pool. A request body must not be put back before the
+// response body is closed, the transport may read it until then.
+func putPayloadBuffer(buf *[]byte) {
+	if cap(*buf) <= maxPooledPayload {
+		payloadBuffers.Put(buf)
+	}
+}
+
+// idBuffers are the scratch space of the validation of the system IDs
+var idBuffers = sync.Pool{New: func() any { return new([]int) }}
+
+// putIDBuffer puts a scratch buffer of system IDs back into the pool
+func putIDBuffer(ids *[]int) {
+	if cap(*ids) <= maxPooledPayload/8 {
+		idBuffers.Put(ids)
+	}
+}
+
+// appendJSONString appends s as JSON string like encoding/json, which escapes HTML characters too
+func appendJSONString(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c > 0x7e || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			// the rare names with special characters are escaped by encoding/json
+			quoted, _ := json.Marshal(s)
+			return append(b, quoted...)
+		}
+	}
+	b = append(b, '"')
+	b = append(b, s...)
+	return append(b, '"')
+}
@@ -0,0 +1,187 @@
+package appapi
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// DefaultETagCacheSize is the number of responses kept by an ETagCache created with size 0
+const DefaultETagCacheSize = 256
+
+// ETagCache keeps the responses of GET calls which carry an ETag. The next call of the same URL
+// sends the ETag in If-None-Match and a 304 Not Modified is answered from the cache, so unchanged
+// building block definitions and project lists are not transferred again in a reconciliation loop.
+// Unlike the ReadCache the result is always current, it only saves the transfer.
+//
+// The responses are kept per URL and Accept header, a cache must not be shared by clients with
+// different credentials. The least recently used response is dropped when the cache is full.
+type ETagCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	hits    int
+	misses  int
+}
+
+type etagEntry struct {
+	key    string
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// NewETagCache creates a cache of at most size responses, DefaultETagCacheSize if 0
+func NewETagCache(size int) *ETagCache {
+	if size <= 0 {
+		size = DefaultETagCacheSize
+	}
+	return &ETagCache{size: size, entries: map[string]*list.Element{}, lru: list.New()}
+}
+
+// WithETagCache sends conditional GET requests for the responses kept in the cache, nil disables it
+func WithETagCache(cache *ETagCache) Option {
+	return func(o *callOptions) {
+		o.etags = cache
+	}
+}
+
+// Len returns the number of cached responses
+func (c *ETagCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns how many conditional calls were answered from the cache with 304 Not Modified and
+// how many returned a new response
+func (c *ETagCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Clear removes all responses
+func (c *ETagCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+func etagKey(req *http.Request) string {
+	return req.URL.String() + "\x00" + req.Header.Get("Accept")
+}
+
+func (c *ETagCache) get(key string) (etagEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return etagEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return *elem.Value.(*etagEntry), true
+}
+
+func (c *ETagCache) put(entry etagEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[entry.key]; ok {
+		elem.Value = &entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(&entry)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*etagEntry).key)
+	}
+}
+
+func (c *ETagCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+func (c *ETagCache) count(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+}
+
+// conditional adds If-None-Match to a GET request of a cached response and returns the entry
+func (c *ETagCache) conditional(req *http.Request) (*http.Request, *etagEntry) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return req, nil
+	}
+	entry, ok := c.get(etagKey(req))
+	if !ok {
+		return req, nil
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("If-None-Match", entry.etag)
+	return req, &entry
+}
+
+// response answers a 304 Not Modified from the cached entry and keeps a successful response with an
+// ETag. The body of a kept response is read completely.
+func (c *ETagCache) response(req *http.Request, resp *http.Response, cached *etagEntry) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return resp, nil
+	}
+	key := etagKey(req)
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		c.count(true)
+		header := cached.header.Clone()
+		header.Set("Content-Length", strconv.Itoa(len(cached.body)))
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.body)),
+			ContentLength: int64(len(cached.body)),
+			Request:       req,
+		}, nil
+	}
+
+	if cached != nil {
+		c.count(false)
+	}
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		if cached != nil {
+			c.remove(key)
+		}
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	c.put(etagEntry{key: key, etag: etag, header: resp.Header.Clone(), body: body})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,127 @@
+package appapi
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newETagServer serves a project list with the ETag of its version, gzip compressed if requested
+func newETagServer(t *testing.T, version *atomic.Int32) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var transfers atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, version.Load())
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		transfers.Add(1)
+		body := fmt.Sprintf(`{"_embedded": {"meshProjects": [{"metadata": {"name": "web-%d", "ownedByWorkspace": "clab"}}]}, "page": {"totalPages": 1}}`, version.Load())
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			fmt.Fprint(gz, body)
+			gz.Close()
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &transfers
+}
+
+func TestETagCache(t *testing.T) {
+	var version atomic.Int32
+	server, transfers := newETagServer(t, &version)
+	cache := NewETagCache(0)
+
+	list := func(opts ...Option) string {
+		t.Helper()
+		projects, err := MsListProjects(server.URL, "apikey", "clab", false, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(projects) != 1 {
+			t.Fatalf("got %d projects, want 1", len(projects))
+		}
+		return projects[0].Identifier
+	}
+
+	for i := 0; i < 3; i++ {
+		if name := list(WithETagCache(cache)); name != "web-0" {
+			t.Errorf("project = %s, want web-0", name)
+		}
+	}
+	if n := transfers.Load(); n != 1 {
+		t.Errorf("the list was transferred %d times, want once", n)
+	}
+	if hits, misses := cache.Stats(); hits != 2 || misses != 0 || cache.Len() != 1 {
+		t.Errorf("%d hits, %d misses, %d entries, want 2, 0 and 1", hits, misses, cache.Len())
+	}
+
+	// a changed list is transferred again
+	version.Store(1)
+	if name := list(WithETagCache(cache)); name != "web-1" {
+		t.Errorf("project = %s after the change, want web-1", name)
+	}
+	if hits, misses := cache.Stats(); hits != 2 || misses != 1 {
+		t.Errorf("%d hits, %d misses after the change, want 2 and 1", hits, misses)
+	}
+
+	// without the cache every call transfers the list
+	list()
+	if n := transfers.Load(); n != 3 {
+		t.Errorf("the list was transferred %d times, want 3", n)
+	}
+}
+
+func TestETagCacheEviction(t *testing.T) {
+	var version atomic.Int32
+	server, transfers := newETagServer(t, &version)
+	cache := NewETagCache(2)
+
+	for _, workspace := range []string{"a", "b", "a", "c", "a", "b"} {
+		if _, err := MsListProjects(server.URL, "apikey", workspace, false, WithETagCache(cache)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a stays cached as the most recently used list, b is dropped for c
+	if n := transfers.Load(); n != 4 || cache.Len() != 2 {
+		t.Errorf("%d transfers, %d entries, want 4 and 2", n, cache.Len())
+	}
+
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Errorf("%d entries after Clear, want 0", cache.Len())
+	}
+}
+
+func TestMsClientETagCache(t *testing.T) {
+	var version atomic.Int32
+	server, transfers := newETagServer(t, &version)
+	c := &MsClient{URL: server.URL, token: "token"}
+	c.EnableETagCache(10)
+
+	for i := 0; i < 2; i++ {
+		if err := c.Call(http.MethodGet, "api/meshobjects/meshprojects", nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := transfers.Load(); n != 1 {
+		t.Errorf("the list was transferred %d times, want once", n)
+	}
+
+	// a call can disable the cache
+	if err := c.Call(http.MethodGet, "api/meshobjects/meshprojects", nil, nil, nil, WithETagCache(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if n := transfers.Load(); n != 2 {
+		t.Errorf("the list was transferred %d times, want 2", n)
+	}
+}
@@ -0,0 +1,200 @@
+package appapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sumaListGroupCheckins returns the last check-in of the members of a system group by system ID
+func sumaListGroupCheckins(sessioncookie, susemgr, group string, verbose bool, opts ...Option) (checkins map[int]time.Time, err error) {
+	var systems []struct {
+		ID          int    `json:"id"`
+		LastCheckin string `json:"last_checkin"`
+	}
+	query := url.Values{"systemGroupName": {group}}
+	if err := sumaGet(sessioncookie, susemgr, "systemgroup/listSystems", query, &systems, verbose, opts...); err != nil {
+		return nil, err
+	}
+	checkins = make(map[int]time.Time, len(systems))
+	for _, s := range systems {
+		checkins[s.ID] = parseSumaTime(s.LastCheckin)
+	}
+	return checkins, nil
+}
+
+// PatchExporter serves the patch compliance of system groups as Prometheus metrics: the members per
+// group, the relevant errata per system and advisory type and the days since the last check-in of
+// the systems. The metrics are collected every Interval by Run, not on every scrape, so a scrape does
+// not load SUSE Manager.
+type PatchExporter struct {
+	Client   *SumaClient
+	Groups   []string
+	Interval time.Duration
+	// Clock is the clock of the Interval and the check-in age, DefaultClock if nil
+	Clock Clock
+
+	mu      sync.RWMutex
+	metrics []byte
+}
+
+// NewPatchExporter returns an exporter of the system groups, collected every interval
+func NewPatchExporter(client *SumaClient, groups []string, interval time.Duration) *PatchExporter {
+	return &PatchExporter{Client: client, Groups: groups, Interval: interval}
+}
+
+// clock returns the Clock of the exporter
+func (e *PatchExporter) clock() Clock {
+	if e.Clock == nil {
+		return DefaultClock
+	}
+	return e.Clock
+}
+
+// Run collects the metrics at once and then every Interval until ctx is done
+func (e *PatchExporter) Run(ctx context.Context) error {
+	ticker := e.clock().NewTicker(e.Interval)
+	defer ticker.Stop()
+	for {
+		e.Collect()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.Chan():
+		}
+	}
+}
+
+// Collect queries SUMA for the metrics of the groups. A group which cannot be queried is reported by
+// appapi_suma_group_up 0, the other groups are still exported.
+func (e *PatchExporter) Collect(opts ...Option) {
+	var b bytes.Buffer
+	w := &metricWriter{w: &b}
+
+	type groupMetrics struct {
+		report   GroupPatchReport
+		checkins map[int]time.Time
+		err      error
+	}
+	collected := make([]groupMetrics, len(e.Groups))
+	for i, group := range e.Groups {
+		report, err := e.Client.GroupPatchReport(group, opts...)
+		var checkins map[int]time.Time
+		if err == nil {
+			verbose, opts := e.Client.options(opts)
+			checkins, err = sumaListGroupCheckins(e.Client.SessionCookie(), e.Client.URL, group, verbose, opts...)
+		}
+		if err != nil {
+			logWarnf("could not collect the patch metrics of group %s: %v", group, err)
+		}
+		collected[i] = groupMetrics{report: report, checkins: checkins, err: err}
+	}
+
+	w.header("appapi_suma_group_up", "gauge", "Whether the metrics of the system group could be collected")
+	for i, group := range e.Groups {
+		up := 1.0
+		if collected[i].err != nil {
+			up = 0
+		}
+		w.sample("appapi_suma_group_up", up, "group", group)
+	}
+
+	w.header("appapi_suma_group_systems", "gauge", "Number of systems in the system group")
+	for _, m := range collected {
+		if m.err == nil {
+			w.sample("appapi_suma_group_systems", float64(len(m.report.Systems)), "group", m.report.Group)
+		}
+	}
+
+	w.header("appapi_suma_system_relevant_errata", "gauge", "Number of errata relevant for the system by advisory type")
+	for _, m := range collected {
+		if m.err != nil {
+			continue
+		}
+		for _, s := range m.report.Systems {
+			w.sample("appapi_suma_system_relevant_errata", float64(s.Security), "group", m.report.Group, "system", s.Name, "type", "security")
+			w.sample("appapi_suma_system_relevant_errata", float64(s.Bugfix), "group", m.report.Group, "system", s.Name, "type", "bugfix")
+			w.sample("appapi_suma_system_relevant_errata", float64(s.Enhancement), "group", m.report.Group, "system", s.Name, "type", "enhancement")
+		}
+	}
+
+	w.header("appapi_suma_system_checkin_age_days", "gauge", "Days since the last check-in of the system")
+	now := e.clock().Now()
+	for _, m := range collected {
+		if m.err != nil {
+			continue
+		}
+		for _, s := range m.report.Systems {
+			if checkin := m.checkins[s.SystemID]; !checkin.IsZero() {
+				w.sample("appapi_suma_system_checkin_age_days", now.Sub(checkin).Hours()/24, "group", m.report.Group, "system", s.Name)
+			}
+		}
+	}
+
+	stats := ReadTransportStats()
+	w.header("appapi_http_connections_total", "counter", "Number of connections of the API calls by new or reused connection")
+	w.sample("appapi_http_connections_total", float64(stats.NewConns), "state", "new")
+	w.sample("appapi_http_connections_total", float64(stats.ReusedConns), "state", "reused")
+	w.header("appapi_http_dns_seconds_total", "counter", "Time spent in DNS lookups of the API calls")
+	w.sample("appapi_http_dns_seconds_total", stats.DNSTime.Seconds())
+	w.header("appapi_http_tls_handshakes_total", "counter", "Number of TLS handshakes of the API calls")
+	w.sample("appapi_http_tls_handshakes_total", float64(stats.TLSHandshakes))
+	w.header("appapi_http_tls_handshake_seconds_total", "counter", "Time spent in TLS handshakes of the API calls")
+	w.sample("appapi_http_tls_handshake_seconds_total", stats.TLSTime.Seconds())
+	w.header("appapi_http_shared_calls_total", "counter", "Number of GET calls answered with the response of an identical call in flight")
+	w.sample("appapi_http_shared_calls_total", float64(stats.SharedCalls))
+
+	w.header("appapi_suma_last_collect_timestamp_seconds", "gauge", "Time of the last collection of the metrics")
+	w.sample("appapi_suma_last_collect_timestamp_seconds", float64(now.Unix()))
+
+	e.mu.Lock()
+	e.metrics = b.Bytes()
+	e.mu.Unlock()
+}
+
+// ServeHTTP writes the collected metrics in the Prometheus text format
+func (e *PatchExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	metrics := e.metrics
+	e.mu.RUnlock()
+
+	if metrics == nil {
+		http.Error(w, "metrics not collected yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(metrics)
+}
+
+// metricWriter writes metrics in the Prometheus text format
+type metricWriter struct {
+	w *bytes.Buffer
+}
+
+func (m *metricWriter) header(name, metricType, help string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// sample writes a sample with the labels given as name and value pairs
+func (m *metricWriter) sample(name string, value float64, labels ...string) {
+	m.w.WriteString(name)
+	if len(labels) > 0 {
+		m.w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.w.WriteByte(',')
+			}
+			fmt.Fprintf(m.w, "%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		m.w.WriteByte('}')
+	}
+	fmt.Fprintf(m.w, " %s\n", strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
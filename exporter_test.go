@@ -0,0 +1,70 @@
+package appapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPatchExporter(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+
+	checkin := clock.Now().Add(-72 * time.Hour).Format(time.RFC3339)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystemsMinimal", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("systemGroupName") != "clab" {
+			fmt.Fprint(w, `{"success": false, "message": "no such group"}`)
+			return
+		}
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "name": "host1"}, {"id": 2, "name": "host\"2"}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystems", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success": true, "result": [{"id": 1, "last_checkin": %q}, {"id": 2}]}`, checkin)
+	})
+	mux.HandleFunc("/rhn/manager/api/system/getRelevantErrata", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sid") == "1" {
+			fmt.Fprint(w, `{"success": true, "result": [{"advisory_type": "Security Advisory"}, {"advisory_type": "Bug Fix Advisory"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"success": true, "result": []}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	exporter := NewPatchExporter(&SumaClient{URL: server.URL, sessioncookie: "cookie"}, []string{"clab", "missing"}, time.Minute)
+	exporter.Clock = clock
+
+	rec := httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 before the first collection, got %d", rec.Code)
+	}
+
+	exporter.Collect()
+	rec = httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	metrics := string(body)
+
+	for _, want := range []string{
+		"# TYPE appapi_suma_group_systems gauge\n",
+		`appapi_suma_group_up{group="clab"} 1` + "\n",
+		`appapi_suma_group_up{group="missing"} 0` + "\n",
+		`appapi_suma_group_systems{group="clab"} 2` + "\n",
+		`appapi_suma_system_relevant_errata{group="clab",system="host1",type="security"} 1` + "\n",
+		`appapi_suma_system_relevant_errata{group="clab",system="host\"2",type="bugfix"} 0` + "\n",
+		`appapi_suma_system_checkin_age_days{group="clab",system="host1"} 3` + "\n",
+		"# TYPE appapi_http_connections_total counter\n",
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, metrics)
+		}
+	}
+	if strings.Contains(metrics, `appapi_suma_system_checkin_age_days{group="clab",system="host\"2"}`) {
+		t.Errorf("system without check-in must not have a check-in age:\n%s", metrics)
+	}
+}
@@ -0,0 +1,119 @@
+package appapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The fixture tests decode real, sanitized responses of SUMA and Meshstack into the exported types
+// and compare the result with a golden file. To cover a new endpoint, save its response as
+// testdata/fixtures/suma/<method>.json or testdata/fixtures/meshstack/<path>.json, add the call to
+// fixtureTests and write its golden file with
+//
+//	go test -run TestFixtures -update .
+//
+// Review the golden file before committing it: a field which is not decoded shows up as zero value.
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of TestFixtures")
+
+var fixtureTests = []struct {
+	name string
+	call func(url string) (any, error)
+}{
+	{"SumaListNotifications", func(url string) (any, error) {
+		return SumaListNotifications("cookie", url, false, false)
+	}},
+	{"SumaListSystemEvents", func(url string) (any, error) {
+		return SumaListSystemEvents("cookie", url, 1000010001, false)
+	}},
+	{"SumaGetOrg", func(url string) (any, error) {
+		return SumaGetOrg("cookie", url, "clab", false)
+	}},
+	{"MsListProjects", func(url string) (any, error) {
+		return MsListProjects(url, "apikey", "clab", false)
+	}},
+	{"MsListBuildingBlockRuns", func(url string) (any, error) {
+		return MsListBuildingBlockRuns(url, "apikey", "5b0e2f3c-7d41-4a8e-b1c6-9f2a4e7d3c10", "", false)
+	}},
+	{"MsGetRunLog", func(url string) (any, error) {
+		return MsGetRunLog(url, "apikey", "8d1f6a52-0c3b-4f0e-9a57-2b61c9e0d4a7", false)
+	}},
+}
+
+func TestFixtures(t *testing.T) {
+	server := newFixtureServer(t)
+	for _, tt := range fixtureTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.call(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reflect.ValueOf(got).IsZero() {
+				t.Fatalf("%s decoded nothing", tt.name)
+			}
+			checkGolden(t, tt.name, got)
+		})
+	}
+}
+
+// newFixtureServer answers the SUMA calls with testdata/fixtures/suma/<method>.json and the other
+// calls with testdata/fixtures/meshstack/<path>.json, the query is ignored. A call without a fixture
+// fails the test.
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := filepath.Join("meshstack", r.URL.Path)
+		if method, ok := strings.CutPrefix(r.URL.Path, "/rhn/manager/api/"); ok {
+			path = filepath.Join("suma", method)
+		}
+		data, err := loadFixture(path + ".json")
+		if err != nil {
+			t.Errorf("no fixture for %s %s: %v", r.Method, r.URL.Path, err)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// loadFixture reads a file of testdata/fixtures
+func loadFixture(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join("testdata", "fixtures", name))
+}
+
+// checkGolden compares the JSON of got with testdata/fixtures/golden/<name>.json, which is rewritten
+// with -update
+func checkGolden(t *testing.T, name string, got any) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+
+	golden := filepath.Join("golden", name+".json")
+	if *updateGolden {
+		if err := os.WriteFile(filepath.Join("testdata", "fixtures", golden), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := loadFixture(golden)
+	if err != nil {
+		t.Fatalf("%v, write it with -update", err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("%s differs from %s:\n%s", name, golden, data)
+	}
+}
@@ -0,0 +1,1365 @@
+// The gRPC facade of appapi. The services call the SUMA and Meshstack clients of the server, so
+// tooling in other languages gets the same behaviour as the appapi command.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: appapi.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AddSystemRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Hostname string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Group    string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// network is the permitted network of the system, empty for the first network of the server
+	Network       string `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddSystemRequest) Reset() {
+	*x = AddSystemRequest{}
+	mi := &file_appapi_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSystemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSystemRequest) ProtoMessage() {}
+
+func (x *AddSystemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSystemRequest.ProtoReflect.Descriptor instead.
+func (*AddSystemRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AddSystemRequest) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *AddSystemRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *AddSystemRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+type DeleteSystemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Network       string                 `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSystemRequest) Reset() {
+	*x = DeleteSystemRequest{}
+	mi := &file_appapi_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSystemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSystemRequest) ProtoMessage() {}
+
+func (x *DeleteSystemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSystemRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSystemRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DeleteSystemRequest) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *DeleteSystemRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+type SystemResult struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	SystemId int64                  `protobuf:"varint,1,opt,name=system_id,json=systemId,proto3" json:"system_id,omitempty"`
+	Hostname string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Ip       string                 `protobuf:"bytes,3,opt,name=ip,proto3" json:"ip,omitempty"`
+	Group    string                 `protobuf:"bytes,4,opt,name=group,proto3" json:"group,omitempty"`
+	// action is added, deleted or unchanged
+	Action        string `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SystemResult) Reset() {
+	*x = SystemResult{}
+	mi := &file_appapi_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SystemResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemResult) ProtoMessage() {}
+
+func (x *SystemResult) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemResult.ProtoReflect.Descriptor instead.
+func (*SystemResult) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SystemResult) GetSystemId() int64 {
+	if x != nil {
+		return x.SystemId
+	}
+	return 0
+}
+
+func (x *SystemResult) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *SystemResult) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *SystemResult) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *SystemResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type ListGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsRequest) Reset() {
+	*x = ListGroupsRequest{}
+	mi := &file_appapi_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsRequest) ProtoMessage() {}
+
+func (x *ListGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{3}
+}
+
+type ListGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []string               `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsResponse) Reset() {
+	*x = ListGroupsResponse{}
+	mi := &file_appapi_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsResponse) ProtoMessage() {}
+
+func (x *ListGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListGroupsResponse) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type ListGroupSystemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupSystemsRequest) Reset() {
+	*x = ListGroupSystemsRequest{}
+	mi := &file_appapi_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupSystemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupSystemsRequest) ProtoMessage() {}
+
+func (x *ListGroupSystemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupSystemsRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupSystemsRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListGroupSystemsRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type System struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *System) Reset() {
+	*x = System{}
+	mi := &file_appapi_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *System) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*System) ProtoMessage() {}
+
+func (x *System) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use System.ProtoReflect.Descriptor instead.
+func (*System) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *System) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *System) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListGroupSystemsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Systems       []*System              `protobuf:"bytes,1,rep,name=systems,proto3" json:"systems,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupSystemsResponse) Reset() {
+	*x = ListGroupSystemsResponse{}
+	mi := &file_appapi_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupSystemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupSystemsResponse) ProtoMessage() {}
+
+func (x *ListGroupSystemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupSystemsResponse.ProtoReflect.Descriptor instead.
+func (*ListGroupSystemsResponse) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListGroupSystemsResponse) GetSystems() []*System {
+	if x != nil {
+		return x.Systems
+	}
+	return nil
+}
+
+type EnsureGroupMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Hostnames     []string               `protobuf:"bytes,2,rep,name=hostnames,proto3" json:"hostnames,omitempty"`
+	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnsureGroupMembersRequest) Reset() {
+	*x = EnsureGroupMembersRequest{}
+	mi := &file_appapi_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnsureGroupMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnsureGroupMembersRequest) ProtoMessage() {}
+
+func (x *EnsureGroupMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnsureGroupMembersRequest.ProtoReflect.Descriptor instead.
+func (*EnsureGroupMembersRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *EnsureGroupMembersRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *EnsureGroupMembersRequest) GetHostnames() []string {
+	if x != nil {
+		return x.Hostnames
+	}
+	return nil
+}
+
+func (x *EnsureGroupMembersRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+type GroupChangeReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Added         []string               `protobuf:"bytes,2,rep,name=added,proto3" json:"added,omitempty"`
+	Removed       []string               `protobuf:"bytes,3,rep,name=removed,proto3" json:"removed,omitempty"`
+	Unchanged     []string               `protobuf:"bytes,4,rep,name=unchanged,proto3" json:"unchanged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupChangeReport) Reset() {
+	*x = GroupChangeReport{}
+	mi := &file_appapi_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupChangeReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupChangeReport) ProtoMessage() {}
+
+func (x *GroupChangeReport) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupChangeReport.ProtoReflect.Descriptor instead.
+func (*GroupChangeReport) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GroupChangeReport) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *GroupChangeReport) GetAdded() []string {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *GroupChangeReport) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *GroupChangeReport) GetUnchanged() []string {
+	if x != nil {
+		return x.Unchanged
+	}
+	return nil
+}
+
+type ListUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersRequest) Reset() {
+	*x = ListUsersRequest{}
+	mi := &file_appapi_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersRequest) ProtoMessage() {}
+
+func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersRequest.ProtoReflect.Descriptor instead.
+func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{10}
+}
+
+type ListUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []string               `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersResponse) Reset() {
+	*x = ListUsersResponse{}
+	mi := &file_appapi_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersResponse) ProtoMessage() {}
+
+func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersResponse.ProtoReflect.Descriptor instead.
+func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListUsersResponse) GetUsers() []string {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type AddUserRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// group is the system group and the login of the user
+	Group         string `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Password      string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddUserRequest) Reset() {
+	*x = AddUserRequest{}
+	mi := &file_appapi_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddUserRequest) ProtoMessage() {}
+
+func (x *AddUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddUserRequest.ProtoReflect.Descriptor instead.
+func (*AddUserRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AddUserRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *AddUserRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type UserResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Login string                 `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
+	// action is created or unchanged
+	Action        string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserResult) Reset() {
+	*x = UserResult{}
+	mi := &file_appapi_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserResult) ProtoMessage() {}
+
+func (x *UserResult) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserResult.ProtoReflect.Descriptor instead.
+func (*UserResult) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UserResult) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *UserResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type RemoveUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveUserRequest) Reset() {
+	*x = RemoveUserRequest{}
+	mi := &file_appapi_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveUserRequest) ProtoMessage() {}
+
+func (x *RemoveUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveUserRequest.ProtoReflect.Descriptor instead.
+func (*RemoveUserRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RemoveUserRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type RemoveUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveUserResponse) Reset() {
+	*x = RemoveUserResponse{}
+	mi := &file_appapi_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveUserResponse) ProtoMessage() {}
+
+func (x *RemoveUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveUserResponse.ProtoReflect.Descriptor instead.
+func (*RemoveUserResponse) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{15}
+}
+
+type BuildingBlock struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Uuid           string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DefinitionUuid string                 `protobuf:"bytes,3,opt,name=definition_uuid,json=definitionUuid,proto3" json:"definition_uuid,omitempty"`
+	Status         string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Inputs         map[string]string      `protobuf:"bytes,5,rep,name=inputs,proto3" json:"inputs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Outputs        map[string]string      `protobuf:"bytes,6,rep,name=outputs,proto3" json:"outputs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BuildingBlock) Reset() {
+	*x = BuildingBlock{}
+	mi := &file_appapi_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildingBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildingBlock) ProtoMessage() {}
+
+func (x *BuildingBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildingBlock.ProtoReflect.Descriptor instead.
+func (*BuildingBlock) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BuildingBlock) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *BuildingBlock) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BuildingBlock) GetDefinitionUuid() string {
+	if x != nil {
+		return x.DefinitionUuid
+	}
+	return ""
+}
+
+func (x *BuildingBlock) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BuildingBlock) GetInputs() map[string]string {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *BuildingBlock) GetOutputs() map[string]string {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+type ListBuildingBlocksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBuildingBlocksRequest) Reset() {
+	*x = ListBuildingBlocksRequest{}
+	mi := &file_appapi_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBuildingBlocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBuildingBlocksRequest) ProtoMessage() {}
+
+func (x *ListBuildingBlocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBuildingBlocksRequest.ProtoReflect.Descriptor instead.
+func (*ListBuildingBlocksRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListBuildingBlocksRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+type ListBuildingBlocksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// building_blocks only have the uuid and name, get a building block for the details
+	BuildingBlocks []*BuildingBlock `protobuf:"bytes,1,rep,name=building_blocks,json=buildingBlocks,proto3" json:"building_blocks,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListBuildingBlocksResponse) Reset() {
+	*x = ListBuildingBlocksResponse{}
+	mi := &file_appapi_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBuildingBlocksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBuildingBlocksResponse) ProtoMessage() {}
+
+func (x *ListBuildingBlocksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBuildingBlocksResponse.ProtoReflect.Descriptor instead.
+func (*ListBuildingBlocksResponse) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListBuildingBlocksResponse) GetBuildingBlocks() []*BuildingBlock {
+	if x != nil {
+		return x.BuildingBlocks
+	}
+	return nil
+}
+
+type GetBuildingBlockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBuildingBlockRequest) Reset() {
+	*x = GetBuildingBlockRequest{}
+	mi := &file_appapi_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBuildingBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBuildingBlockRequest) ProtoMessage() {}
+
+func (x *GetBuildingBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBuildingBlockRequest.ProtoReflect.Descriptor instead.
+func (*GetBuildingBlockRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetBuildingBlockRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+type CreateBuildingBlockRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// payload is the JSON payload of the building block as accepted by the Meshstack API
+	Payload       []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBuildingBlockRequest) Reset() {
+	*x = CreateBuildingBlockRequest{}
+	mi := &file_appapi_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBuildingBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBuildingBlockRequest) ProtoMessage() {}
+
+func (x *CreateBuildingBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBuildingBlockRequest.ProtoReflect.Descriptor instead.
+func (*CreateBuildingBlockRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CreateBuildingBlockRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type DeleteBuildingBlockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBuildingBlockRequest) Reset() {
+	*x = DeleteBuildingBlockRequest{}
+	mi := &file_appapi_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBuildingBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBuildingBlockRequest) ProtoMessage() {}
+
+func (x *DeleteBuildingBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBuildingBlockRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBuildingBlockRequest) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteBuildingBlockRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+type DeleteBuildingBlockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBuildingBlockResponse) Reset() {
+	*x = DeleteBuildingBlockResponse{}
+	mi := &file_appapi_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBuildingBlockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBuildingBlockResponse) ProtoMessage() {}
+
+func (x *DeleteBuildingBlockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appapi_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBuildingBlockResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBuildingBlockResponse) Descriptor() ([]byte, []int) {
+	return file_appapi_proto_rawDescGZIP(), []int{22}
+}
+
+var File_appapi_proto protoreflect.FileDescriptor
+
+const file_appapi_proto_rawDesc = "" +
+	"\n" +
+	"\fappapi.proto\x12\tappapi.v1\"^\n" +
+	"\x10AddSystemRequest\x12\x1a\n" +
+	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\"K\n" +
+	"\x13DeleteSystemRequest\x12\x1a\n" +
+	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x18\n" +
+	"\anetwork\x18\x02 \x01(\tR\anetwork\"\x85\x01\n" +
+	"\fSystemResult\x12\x1b\n" +
+	"\tsystem_id\x18\x01 \x01(\x03R\bsystemId\x12\x1a\n" +
+	"\bhostname\x18\x02 \x01(\tR\bhostname\x12\x0e\n" +
+	"\x02ip\x18\x03 \x01(\tR\x02ip\x12\x14\n" +
+	"\x05group\x18\x04 \x01(\tR\x05group\x12\x16\n" +
+	"\x06action\x18\x05 \x01(\tR\x06action\"\x13\n" +
+	"\x11ListGroupsRequest\",\n" +
+	"\x12ListGroupsResponse\x12\x16\n" +
+	"\x06groups\x18\x01 \x03(\tR\x06groups\"/\n" +
+	"\x17ListGroupSystemsRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\",\n" +
+	"\x06System\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"G\n" +
+	"\x18ListGroupSystemsResponse\x12+\n" +
+	"\asystems\x18\x01 \x03(\v2\x11.appapi.v1.SystemR\asystems\"i\n" +
+	"\x19EnsureGroupMembersRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x1c\n" +
+	"\thostnames\x18\x02 \x03(\tR\thostnames\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\"w\n" +
+	"\x11GroupChangeReport\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x14\n" +
+	"\x05added\x18\x02 \x03(\tR\x05added\x12\x18\n" +
+	"\aremoved\x18\x03 \x03(\tR\aremoved\x12\x1c\n" +
+	"\tunchanged\x18\x04 \x03(\tR\tunchanged\"\x12\n" +
+	"\x10ListUsersRequest\")\n" +
+	"\x11ListUsersResponse\x12\x14\n" +
+	"\x05users\x18\x01 \x03(\tR\x05users\"B\n" +
+	"\x0eAddUserRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\":\n" +
+	"\n" +
+	"UserResult\x12\x14\n" +
+	"\x05login\x18\x01 \x01(\tR\x05login\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\")\n" +
+	"\x11RemoveUserRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\"\x14\n" +
+	"\x12RemoveUserResponse\"\xee\x02\n" +
+	"\rBuildingBlock\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12'\n" +
+	"\x0fdefinition_uuid\x18\x03 \x01(\tR\x0edefinitionUuid\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12<\n" +
+	"\x06inputs\x18\x05 \x03(\v2$.appapi.v1.BuildingBlock.InputsEntryR\x06inputs\x12?\n" +
+	"\aoutputs\x18\x06 \x03(\v2%.appapi.v1.BuildingBlock.OutputsEntryR\aoutputs\x1a9\n" +
+	"\vInputsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a:\n" +
+	"\fOutputsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\":\n" +
+	"\x19ListBuildingBlocksRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\"_\n" +
+	"\x1aListBuildingBlocksResponse\x12A\n" +
+	"\x0fbuilding_blocks\x18\x01 \x03(\v2\x18.appapi.v1.BuildingBlockR\x0ebuildingBlocks\"-\n" +
+	"\x17GetBuildingBlockRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\"6\n" +
+	"\x1aCreateBuildingBlockRequest\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\fR\apayload\"0\n" +
+	"\x1aDeleteBuildingBlockRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\"\x1d\n" +
+	"\x1bDeleteBuildingBlockResponse2\x9b\x01\n" +
+	"\rSystemService\x12A\n" +
+	"\tAddSystem\x12\x1b.appapi.v1.AddSystemRequest\x1a\x17.appapi.v1.SystemResult\x12G\n" +
+	"\fDeleteSystem\x12\x1e.appapi.v1.DeleteSystemRequest\x1a\x17.appapi.v1.SystemResult2\x90\x02\n" +
+	"\fGroupService\x12I\n" +
+	"\n" +
+	"ListGroups\x12\x1c.appapi.v1.ListGroupsRequest\x1a\x1d.appapi.v1.ListGroupsResponse\x12[\n" +
+	"\x10ListGroupSystems\x12\".appapi.v1.ListGroupSystemsRequest\x1a#.appapi.v1.ListGroupSystemsResponse\x12X\n" +
+	"\x12EnsureGroupMembers\x12$.appapi.v1.EnsureGroupMembersRequest\x1a\x1c.appapi.v1.GroupChangeReport2\xdd\x01\n" +
+	"\vUserService\x12F\n" +
+	"\tListUsers\x12\x1b.appapi.v1.ListUsersRequest\x1a\x1c.appapi.v1.ListUsersResponse\x12;\n" +
+	"\aAddUser\x12\x19.appapi.v1.AddUserRequest\x1a\x15.appapi.v1.UserResult\x12I\n" +
+	"\n" +
+	"RemoveUser\x12\x1c.appapi.v1.RemoveUserRequest\x1a\x1d.appapi.v1.RemoveUserResponse2\x89\x03\n" +
+	"\x14BuildingBlockService\x12a\n" +
+	"\x12ListBuildingBlocks\x12$.appapi.v1.ListBuildingBlocksRequest\x1a%.appapi.v1.ListBuildingBlocksResponse\x12P\n" +
+	"\x10GetBuildingBlock\x12\".appapi.v1.GetBuildingBlockRequest\x1a\x18.appapi.v1.BuildingBlock\x12V\n" +
+	"\x13CreateBuildingBlock\x12%.appapi.v1.CreateBuildingBlockRequest\x1a\x18.appapi.v1.BuildingBlock\x12d\n" +
+	"\x13DeleteBuildingBlock\x12%.appapi.v1.DeleteBuildingBlockRequest\x1a&.appapi.v1.DeleteBuildingBlockResponseB\"Z github.com/arzieg/appapi/grpcapib\x06proto3"
+
+var (
+	file_appapi_proto_rawDescOnce sync.Once
+	file_appapi_proto_rawDescData []byte
+)
+
+func file_appapi_proto_rawDescGZIP() []byte {
+	file_appapi_proto_rawDescOnce.Do(func() {
+		file_appapi_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_appapi_proto_rawDesc), len(file_appapi_proto_rawDesc)))
+	})
+	return file_appapi_proto_rawDescData
+}
+
+var file_appapi_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_appapi_proto_goTypes = []any{
+	(*AddSystemRequest)(nil),            // 0: appapi.v1.AddSystemRequest
+	(*DeleteSystemRequest)(nil),         // 1: appapi.v1.DeleteSystemRequest
+	(*SystemResult)(nil),                // 2: appapi.v1.SystemResult
+	(*ListGroupsRequest)(nil),           // 3: appapi.v1.ListGroupsRequest
+	(*ListGroupsResponse)(nil),          // 4: appapi.v1.ListGroupsResponse
+	(*ListGroupSystemsRequest)(nil),     // 5: appapi.v1.ListGroupSystemsRequest
+	(*System)(nil),                      // 6: appapi.v1.System
+	(*ListGroupSystemsResponse)(nil),    // 7: appapi.v1.ListGroupSystemsResponse
+	(*EnsureGroupMembersRequest)(nil),   // 8: appapi.v1.EnsureGroupMembersRequest
+	(*GroupChangeReport)(nil),           // 9: appapi.v1.GroupChangeReport
+	(*ListUsersRequest)(nil),            // 10: appapi.v1.ListUsersRequest
+	(*ListUsersResponse)(nil),           // 11: appapi.v1.ListUsersResponse
+	(*AddUserRequest)(nil),              // 12: appapi.v1.AddUserRequest
+	(*UserResult)(nil),                  // 13: appapi.v1.UserResult
+	(*RemoveUserRequest)(nil),           // 14: appapi.v1.RemoveUserRequest
+	(*RemoveUserResponse)(nil),          // 15: appapi.v1.RemoveUserResponse
+	(*BuildingBlock)(nil),               // 16: appapi.v1.BuildingBlock
+	(*ListBuildingBlocksRequest)(nil),   // 17: appapi.v1.ListBuildingBlocksRequest
+	(*ListBuildingBlocksResponse)(nil),  // 18: appapi.v1.ListBuildingBlocksResponse
+	(*GetBuildingBlockRequest)(nil),     // 19: appapi.v1.GetBuildingBlockRequest
+	(*CreateBuildingBlockRequest)(nil),  // 20: appapi.v1.CreateBuildingBlockRequest
+	(*DeleteBuildingBlockRequest)(nil),  // 21: appapi.v1.DeleteBuildingBlockRequest
+	(*DeleteBuildingBlockResponse)(nil), // 22: appapi.v1.DeleteBuildingBlockResponse
+	nil,                                 // 23: appapi.v1.BuildingBlock.InputsEntry
+	nil,                                 // 24: appapi.v1.BuildingBlock.OutputsEntry
+}
+var file_appapi_proto_depIdxs = []int32{
+	6,  // 0: appapi.v1.ListGroupSystemsResponse.systems:type_name -> appapi.v1.System
+	23, // 1: appapi.v1.BuildingBlock.inputs:type_name -> appapi.v1.BuildingBlock.InputsEntry
+	24, // 2: appapi.v1.BuildingBlock.outputs:type_name -> appapi.v1.BuildingBlock.OutputsEntry
+	16, // 3: appapi.v1.ListBuildingBlocksResponse.building_blocks:type_name -> appapi.v1.BuildingBlock
+	0,  // 4: appapi.v1.SystemService.AddSystem:input_type -> appapi.v1.AddSystemRequest
+	1,  // 5: appapi.v1.SystemService.DeleteSystem:input_type -> appapi.v1.DeleteSystemRequest
+	3,  // 6: appapi.v1.GroupService.ListGroups:input_type -> appapi.v1.ListGroupsRequest
+	5,  // 7: appapi.v1.GroupService.ListGroupSystems:input_type -> appapi.v1.ListGroupSystemsRequest
+	8,  // 8: appapi.v1.GroupService.EnsureGroupMembers:input_type -> appapi.v1.EnsureGroupMembersRequest
+	10, // 9: appapi.v1.UserService.ListUsers:input_type -> appapi.v1.ListUsersRequest
+	12, // 10: appapi.v1.UserService.AddUser:input_type -> appapi.v1.AddUserRequest
+	14, // 11: appapi.v1.UserService.RemoveUser:input_type -> appapi.v1.RemoveUserRequest
+	17, // 12: appapi.v1.BuildingBlockService.ListBuildingBlocks:input_type -> appapi.v1.ListBuildingBlocksRequest
+	19, // 13: appapi.v1.BuildingBlockService.GetBuildingBlock:input_type -> appapi.v1.GetBuildingBlockRequest
+	20, // 14: appapi.v1.BuildingBlockService.CreateBuildingBlock:input_type -> appapi.v1.CreateBuildingBlockRequest
+	21, // 15: appapi.v1.BuildingBlockService.DeleteBuildingBlock:input_type -> appapi.v1.DeleteBuildingBlockRequest
+	2,  // 16: appapi.v1.SystemService.AddSystem:output_type -> appapi.v1.SystemResult
+	2,  // 17: appapi.v1.SystemService.DeleteSystem:output_type -> appapi.v1.SystemResult
+	4,  // 18: appapi.v1.GroupService.ListGroups:output_type -> appapi.v1.ListGroupsResponse
+	7,  // 19: appapi.v1.GroupService.ListGroupSystems:output_type -> appapi.v1.ListGroupSystemsResponse
+	9,  // 20: appapi.v1.GroupService.EnsureGroupMembers:output_type -> appapi.v1.GroupChangeReport
+	11, // 21: appapi.v1.UserService.ListUsers:output_type -> appapi.v1.ListUsersResponse
+	13, // 22: appapi.v1.UserService.AddUser:output_type -> appapi.v1.UserResult
+	15, // 23: appapi.v1.UserService.RemoveUser:output_type -> appapi.v1.RemoveUserResponse
+	18, // 24: appapi.v1.BuildingBlockService.ListBuildingBlocks:output_type -> appapi.v1.ListBuildingBlocksResponse
+	16, // 25: appapi.v1.BuildingBlockService.GetBuildingBlock:output_type -> appapi.v1.BuildingBlock
+	16, // 26: appapi.v1.BuildingBlockService.CreateBuildingBlock:output_type -> appapi.v1.BuildingBlock
+	22, // 27: appapi.v1.BuildingBlockService.DeleteBuildingBlock:output_type -> appapi.v1.DeleteBuildingBlockResponse
+	16, // [16:28] is the sub-list for method output_type
+	4,  // [4:16] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_appapi_proto_init() }
+func file_appapi_proto_init() {
+	if File_appapi_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_appapi_proto_rawDesc), len(file_appapi_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   25,
+			NumExtensions: 0,
+			NumServices:   4,
+		},
+		GoTypes:           file_appapi_proto_goTypes,
+		DependencyIndexes: file_appapi_proto_depIdxs,
+		MessageInfos:      file_appapi_proto_msgTypes,
+	}.Build()
+	File_appapi_proto = out.File
+	file_appapi_proto_goTypes = nil
+	file_appapi_proto_depIdxs = nil
+}
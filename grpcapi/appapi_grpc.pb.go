@@ -0,0 +1,750 @@
+// The gRPC facade of appapi. The services call the SUMA and Meshstack clients of the server, so
+// tooling in other languages gets the same behaviour as the appapi command.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: appapi.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SystemService_AddSystem_FullMethodName    = "/appapi.v1.SystemService/AddSystem"
+	SystemService_DeleteSystem_FullMethodName = "/appapi.v1.SystemService/DeleteSystem"
+)
+
+// SystemServiceClient is the client API for SystemService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SystemService adds systems to system groups and deletes them from SUSE Manager.
+type SystemServiceClient interface {
+	AddSystem(ctx context.Context, in *AddSystemRequest, opts ...grpc.CallOption) (*SystemResult, error)
+	DeleteSystem(ctx context.Context, in *DeleteSystemRequest, opts ...grpc.CallOption) (*SystemResult, error)
+}
+
+type systemServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSystemServiceClient(cc grpc.ClientConnInterface) SystemServiceClient {
+	return &systemServiceClient{cc}
+}
+
+func (c *systemServiceClient) AddSystem(ctx context.Context, in *AddSystemRequest, opts ...grpc.CallOption) (*SystemResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SystemResult)
+	err := c.cc.Invoke(ctx, SystemService_AddSystem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *systemServiceClient) DeleteSystem(ctx context.Context, in *DeleteSystemRequest, opts ...grpc.CallOption) (*SystemResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SystemResult)
+	err := c.cc.Invoke(ctx, SystemService_DeleteSystem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SystemServiceServer is the server API for SystemService service.
+// All implementations must embed UnimplementedSystemServiceServer
+// for forward compatibility.
+//
+// SystemService adds systems to system groups and deletes them from SUSE Manager.
+type SystemServiceServer interface {
+	AddSystem(context.Context, *AddSystemRequest) (*SystemResult, error)
+	DeleteSystem(context.Context, *DeleteSystemRequest) (*SystemResult, error)
+	mustEmbedUnimplementedSystemServiceServer()
+}
+
+// UnimplementedSystemServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSystemServiceServer struct{}
+
+func (UnimplementedSystemServiceServer) AddSystem(context.Context, *AddSystemRequest) (*SystemResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSystem not implemented")
+}
+func (UnimplementedSystemServiceServer) DeleteSystem(context.Context, *DeleteSystemRequest) (*SystemResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSystem not implemented")
+}
+func (UnimplementedSystemServiceServer) mustEmbedUnimplementedSystemServiceServer() {}
+func (UnimplementedSystemServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeSystemServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SystemServiceServer will
+// result in compilation errors.
+type UnsafeSystemServiceServer interface {
+	mustEmbedUnimplementedSystemServiceServer()
+}
+
+func RegisterSystemServiceServer(s grpc.ServiceRegistrar, srv SystemServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSystemServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SystemService_ServiceDesc, srv)
+}
+
+func _SystemService_AddSystem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSystemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemServiceServer).AddSystem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SystemService_AddSystem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemServiceServer).AddSystem(ctx, req.(*AddSystemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SystemService_DeleteSystem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSystemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemServiceServer).DeleteSystem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SystemService_DeleteSystem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemServiceServer).DeleteSystem(ctx, req.(*DeleteSystemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SystemService_ServiceDesc is the grpc.ServiceDesc for SystemService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SystemService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "appapi.v1.SystemService",
+	HandlerType: (*SystemServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddSystem",
+			Handler:    _SystemService_AddSystem_Handler,
+		},
+		{
+			MethodName: "DeleteSystem",
+			Handler:    _SystemService_DeleteSystem_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "appapi.proto",
+}
+
+const (
+	GroupService_ListGroups_FullMethodName         = "/appapi.v1.GroupService/ListGroups"
+	GroupService_ListGroupSystems_FullMethodName   = "/appapi.v1.GroupService/ListGroupSystems"
+	GroupService_EnsureGroupMembers_FullMethodName = "/appapi.v1.GroupService/EnsureGroupMembers"
+)
+
+// GroupServiceClient is the client API for GroupService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GroupService lists system groups and their members and reconciles the members.
+type GroupServiceClient interface {
+	ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error)
+	ListGroupSystems(ctx context.Context, in *ListGroupSystemsRequest, opts ...grpc.CallOption) (*ListGroupSystemsResponse, error)
+	EnsureGroupMembers(ctx context.Context, in *EnsureGroupMembersRequest, opts ...grpc.CallOption) (*GroupChangeReport, error)
+}
+
+type groupServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGroupServiceClient(cc grpc.ClientConnInterface) GroupServiceClient {
+	return &groupServiceClient{cc}
+}
+
+func (c *groupServiceClient) ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListGroupsResponse)
+	err := c.cc.Invoke(ctx, GroupService_ListGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *groupServiceClient) ListGroupSystems(ctx context.Context, in *ListGroupSystemsRequest, opts ...grpc.CallOption) (*ListGroupSystemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListGroupSystemsResponse)
+	err := c.cc.Invoke(ctx, GroupService_ListGroupSystems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *groupServiceClient) EnsureGroupMembers(ctx context.Context, in *EnsureGroupMembersRequest, opts ...grpc.CallOption) (*GroupChangeReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GroupChangeReport)
+	err := c.cc.Invoke(ctx, GroupService_EnsureGroupMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GroupServiceServer is the server API for GroupService service.
+// All implementations must embed UnimplementedGroupServiceServer
+// for forward compatibility.
+//
+// GroupService lists system groups and their members and reconciles the members.
+type GroupServiceServer interface {
+	ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error)
+	ListGroupSystems(context.Context, *ListGroupSystemsRequest) (*ListGroupSystemsResponse, error)
+	EnsureGroupMembers(context.Context, *EnsureGroupMembersRequest) (*GroupChangeReport, error)
+	mustEmbedUnimplementedGroupServiceServer()
+}
+
+// UnimplementedGroupServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGroupServiceServer struct{}
+
+func (UnimplementedGroupServiceServer) ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroups not implemented")
+}
+func (UnimplementedGroupServiceServer) ListGroupSystems(context.Context, *ListGroupSystemsRequest) (*ListGroupSystemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroupSystems not implemented")
+}
+func (UnimplementedGroupServiceServer) EnsureGroupMembers(context.Context, *EnsureGroupMembersRequest) (*GroupChangeReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnsureGroupMembers not implemented")
+}
+func (UnimplementedGroupServiceServer) mustEmbedUnimplementedGroupServiceServer() {}
+func (UnimplementedGroupServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeGroupServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GroupServiceServer will
+// result in compilation errors.
+type UnsafeGroupServiceServer interface {
+	mustEmbedUnimplementedGroupServiceServer()
+}
+
+func RegisterGroupServiceServer(s grpc.ServiceRegistrar, srv GroupServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGroupServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GroupService_ServiceDesc, srv)
+}
+
+func _GroupService_ListGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GroupServiceServer).ListGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GroupService_ListGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GroupServiceServer).ListGroups(ctx, req.(*ListGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GroupService_ListGroupSystems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupSystemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GroupServiceServer).ListGroupSystems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GroupService_ListGroupSystems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GroupServiceServer).ListGroupSystems(ctx, req.(*ListGroupSystemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GroupService_EnsureGroupMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnsureGroupMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GroupServiceServer).EnsureGroupMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GroupService_EnsureGroupMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GroupServiceServer).EnsureGroupMembers(ctx, req.(*EnsureGroupMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GroupService_ServiceDesc is the grpc.ServiceDesc for GroupService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GroupService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "appapi.v1.GroupService",
+	HandlerType: (*GroupServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListGroups",
+			Handler:    _GroupService_ListGroups_Handler,
+		},
+		{
+			MethodName: "ListGroupSystems",
+			Handler:    _GroupService_ListGroupSystems_Handler,
+		},
+		{
+			MethodName: "EnsureGroupMembers",
+			Handler:    _GroupService_EnsureGroupMembers_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "appapi.proto",
+}
+
+const (
+	UserService_ListUsers_FullMethodName  = "/appapi.v1.UserService/ListUsers"
+	UserService_AddUser_FullMethodName    = "/appapi.v1.UserService/AddUser"
+	UserService_RemoveUser_FullMethodName = "/appapi.v1.UserService/RemoveUser"
+)
+
+// UserServiceClient is the client API for UserService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// UserService manages the SUMA users of system groups.
+type UserServiceClient interface {
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	AddUser(ctx context.Context, in *AddUserRequest, opts ...grpc.CallOption) (*UserResult, error)
+	RemoveUser(ctx context.Context, in *RemoveUserRequest, opts ...grpc.CallOption) (*RemoveUserResponse, error)
+}
+
+type userServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUserServiceClient(cc grpc.ClientConnInterface) UserServiceClient {
+	return &userServiceClient{cc}
+}
+
+func (c *userServiceClient) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUsersResponse)
+	err := c.cc.Invoke(ctx, UserService_ListUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AddUser(ctx context.Context, in *AddUserRequest, opts ...grpc.CallOption) (*UserResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserResult)
+	err := c.cc.Invoke(ctx, UserService_AddUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RemoveUser(ctx context.Context, in *RemoveUserRequest, opts ...grpc.CallOption) (*RemoveUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveUserResponse)
+	err := c.cc.Invoke(ctx, UserService_RemoveUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UserServiceServer is the server API for UserService service.
+// All implementations must embed UnimplementedUserServiceServer
+// for forward compatibility.
+//
+// UserService manages the SUMA users of system groups.
+type UserServiceServer interface {
+	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	AddUser(context.Context, *AddUserRequest) (*UserResult, error)
+	RemoveUser(context.Context, *RemoveUserRequest) (*RemoveUserResponse, error)
+	mustEmbedUnimplementedUserServiceServer()
+}
+
+// UnimplementedUserServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUserServiceServer struct{}
+
+func (UnimplementedUserServiceServer) ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedUserServiceServer) AddUser(context.Context, *AddUserRequest) (*UserResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddUser not implemented")
+}
+func (UnimplementedUserServiceServer) RemoveUser(context.Context, *RemoveUserRequest) (*RemoveUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveUser not implemented")
+}
+func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
+func (UnimplementedUserServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeUserServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UserServiceServer will
+// result in compilation errors.
+type UnsafeUserServiceServer interface {
+	mustEmbedUnimplementedUserServiceServer()
+}
+
+func RegisterUserServiceServer(s grpc.ServiceRegistrar, srv UserServiceServer) {
+	// If the following call pancis, it indicates UnimplementedUserServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UserService_ServiceDesc, srv)
+}
+
+func _UserService_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ListUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListUsers(ctx, req.(*ListUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AddUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AddUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AddUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AddUser(ctx, req.(*AddUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RemoveUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RemoveUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_RemoveUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RemoveUser(ctx, req.(*RemoveUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UserService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "appapi.v1.UserService",
+	HandlerType: (*UserServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListUsers",
+			Handler:    _UserService_ListUsers_Handler,
+		},
+		{
+			MethodName: "AddUser",
+			Handler:    _UserService_AddUser_Handler,
+		},
+		{
+			MethodName: "RemoveUser",
+			Handler:    _UserService_RemoveUser_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "appapi.proto",
+}
+
+const (
+	BuildingBlockService_ListBuildingBlocks_FullMethodName  = "/appapi.v1.BuildingBlockService/ListBuildingBlocks"
+	BuildingBlockService_GetBuildingBlock_FullMethodName    = "/appapi.v1.BuildingBlockService/GetBuildingBlock"
+	BuildingBlockService_CreateBuildingBlock_FullMethodName = "/appapi.v1.BuildingBlockService/CreateBuildingBlock"
+	BuildingBlockService_DeleteBuildingBlock_FullMethodName = "/appapi.v1.BuildingBlockService/DeleteBuildingBlock"
+)
+
+// BuildingBlockServiceClient is the client API for BuildingBlockService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BuildingBlockService manages Meshstack building blocks.
+type BuildingBlockServiceClient interface {
+	ListBuildingBlocks(ctx context.Context, in *ListBuildingBlocksRequest, opts ...grpc.CallOption) (*ListBuildingBlocksResponse, error)
+	GetBuildingBlock(ctx context.Context, in *GetBuildingBlockRequest, opts ...grpc.CallOption) (*BuildingBlock, error)
+	CreateBuildingBlock(ctx context.Context, in *CreateBuildingBlockRequest, opts ...grpc.CallOption) (*BuildingBlock, error)
+	DeleteBuildingBlock(ctx context.Context, in *DeleteBuildingBlockRequest, opts ...grpc.CallOption) (*DeleteBuildingBlockResponse, error)
+}
+
+type buildingBlockServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBuildingBlockServiceClient(cc grpc.ClientConnInterface) BuildingBlockServiceClient {
+	return &buildingBlockServiceClient{cc}
+}
+
+func (c *buildingBlockServiceClient) ListBuildingBlocks(ctx context.Context, in *ListBuildingBlocksRequest, opts ...grpc.CallOption) (*ListBuildingBlocksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBuildingBlocksResponse)
+	err := c.cc.Invoke(ctx, BuildingBlockService_ListBuildingBlocks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *buildingBlockServiceClient) GetBuildingBlock(ctx context.Context, in *GetBuildingBlockRequest, opts ...grpc.CallOption) (*BuildingBlock, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BuildingBlock)
+	err := c.cc.Invoke(ctx, BuildingBlockService_GetBuildingBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *buildingBlockServiceClient) CreateBuildingBlock(ctx context.Context, in *CreateBuildingBlockRequest, opts ...grpc.CallOption) (*BuildingBlock, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BuildingBlock)
+	err := c.cc.Invoke(ctx, BuildingBlockService_CreateBuildingBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *buildingBlockServiceClient) DeleteBuildingBlock(ctx context.Context, in *DeleteBuildingBlockRequest, opts ...grpc.CallOption) (*DeleteBuildingBlockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBuildingBlockResponse)
+	err := c.cc.Invoke(ctx, BuildingBlockService_DeleteBuildingBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BuildingBlockServiceServer is the server API for BuildingBlockService service.
+// All implementations must embed UnimplementedBuildingBlockServiceServer
+// for forward compatibility.
+//
+// BuildingBlockService manages Meshstack building blocks.
+type BuildingBlockServiceServer interface {
+	ListBuildingBlocks(context.Context, *ListBuildingBlocksRequest) (*ListBuildingBlocksResponse, error)
+	GetBuildingBlock(context.Context, *GetBuildingBlockRequest) (*BuildingBlock, error)
+	CreateBuildingBlock(context.Context, *CreateBuildingBlockRequest) (*BuildingBlock, error)
+	DeleteBuildingBlock(context.Context, *DeleteBuildingBlockRequest) (*DeleteBuildingBlockResponse, error)
+	mustEmbedUnimplementedBuildingBlockServiceServer()
+}
+
+// UnimplementedBuildingBlockServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBuildingBlockServiceServer struct{}
+
+func (UnimplementedBuildingBlockServiceServer) ListBuildingBlocks(context.Context, *ListBuildingBlocksRequest) (*ListBuildingBlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBuildingBlocks not implemented")
+}
+func (UnimplementedBuildingBlockServiceServer) GetBuildingBlock(context.Context, *GetBuildingBlockRequest) (*BuildingBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBuildingBlock not implemented")
+}
+func (UnimplementedBuildingBlockServiceServer) CreateBuildingBlock(context.Context, *CreateBuildingBlockRequest) (*BuildingBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBuildingBlock not implemented")
+}
+func (UnimplementedBuildingBlockServiceServer) DeleteBuildingBlock(context.Context, *DeleteBuildingBlockRequest) (*DeleteBuildingBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBuildingBlock not implemented")
+}
+func (UnimplementedBuildingBlockServiceServer) mustEmbedUnimplementedBuildingBlockServiceServer() {}
+func (UnimplementedBuildingBlockServiceServer) testEmbeddedByValue()                              {}
+
+// UnsafeBuildingBlockServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BuildingBlockServiceServer will
+// result in compilation errors.
+type UnsafeBuildingBlockServiceServer interface {
+	mustEmbedUnimplementedBuildingBlockServiceServer()
+}
+
+func RegisterBuildingBlockServiceServer(s grpc.ServiceRegistrar, srv BuildingBlockServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBuildingBlockServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BuildingBlockService_ServiceDesc, srv)
+}
+
+func _BuildingBlockService_ListBuildingBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBuildingBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BuildingBlockServiceServer).ListBuildingBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BuildingBlockService_ListBuildingBlocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BuildingBlockServiceServer).ListBuildingBlocks(ctx, req.(*ListBuildingBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BuildingBlockService_GetBuildingBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBuildingBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BuildingBlockServiceServer).GetBuildingBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BuildingBlockService_GetBuildingBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BuildingBlockServiceServer).GetBuildingBlock(ctx, req.(*GetBuildingBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BuildingBlockService_CreateBuildingBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBuildingBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BuildingBlockServiceServer).CreateBuildingBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BuildingBlockService_CreateBuildingBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BuildingBlockServiceServer).CreateBuildingBlock(ctx, req.(*CreateBuildingBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BuildingBlockService_DeleteBuildingBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBuildingBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BuildingBlockServiceServer).DeleteBuildingBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BuildingBlockService_DeleteBuildingBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BuildingBlockServiceServer).DeleteBuildingBlock(ctx, req.(*DeleteBuildingBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BuildingBlockService_ServiceDesc is the grpc.ServiceDesc for BuildingBlockService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BuildingBlockService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "appapi.v1.BuildingBlockService",
+	HandlerType: (*BuildingBlockServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListBuildingBlocks",
+			Handler:    _BuildingBlockService_ListBuildingBlocks_Handler,
+		},
+		{
+			MethodName: "GetBuildingBlock",
+			Handler:    _BuildingBlockService_GetBuildingBlock_Handler,
+		},
+		{
+			MethodName: "CreateBuildingBlock",
+			Handler:    _BuildingBlockService_CreateBuildingBlock_Handler,
+		},
+		{
+			MethodName: "DeleteBuildingBlock",
+			Handler:    _BuildingBlockService_DeleteBuildingBlock_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "appapi.proto",
+}
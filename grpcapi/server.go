@@ -0,0 +1,337 @@
+// Package grpcapi serves the operations of appapi over gRPC, see appapi.proto for the services.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative appapi.proto
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arzieg/appapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the services with a SUMA and a Meshstack client. The services of a backend without
+// client return codes.FailedPrecondition.
+type Server struct {
+	UnimplementedSystemServiceServer
+	UnimplementedGroupServiceServer
+	UnimplementedUserServiceServer
+	UnimplementedBuildingBlockServiceServer
+
+	Suma *appapi.SumaClient
+	Ms   *appapi.MsClient
+}
+
+// NewServer returns a gRPC server with the services registered. If token is set, the calls must send
+// it as "authorization: Bearer <token>" metadata.
+func NewServer(suma *appapi.SumaClient, ms *appapi.MsClient, token string, opts ...grpc.ServerOption) *grpc.Server {
+	if token != "" {
+		opts = append(opts, grpc.UnaryInterceptor(TokenAuth(token)))
+	}
+	s := grpc.NewServer(opts...)
+	Register(s, &Server{Suma: suma, Ms: ms})
+	return s
+}
+
+// Register registers the services of srv
+func Register(s grpc.ServiceRegistrar, srv *Server) {
+	RegisterSystemServiceServer(s, srv)
+	RegisterGroupServiceServer(s, srv)
+	RegisterUserServiceServer(s, srv)
+	RegisterBuildingBlockServiceServer(s, srv)
+}
+
+// TokenAuth returns an interceptor which rejects calls without the bearer token
+func TokenAuth(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, auth := range md.Get("authorization") {
+			got, ok := strings.CutPrefix(auth, "Bearer ")
+			if ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+}
+
+func (s *Server) suma() (*appapi.SumaClient, error) {
+	if s.Suma == nil {
+		return nil, status.Error(codes.FailedPrecondition, "SUMA is not configured")
+	}
+	return s.Suma, nil
+}
+
+func (s *Server) ms() (*appapi.MsClient, error) {
+	if s.Ms == nil {
+		return nil, status.Error(codes.FailedPrecondition, "Meshstack is not configured")
+	}
+	return s.Ms, nil
+}
+
+// network returns the network or the first network of the SUMA client
+func network(suma *appapi.SumaClient, network string) string {
+	if network == "" && len(suma.Networks) > 0 {
+		return suma.Networks[0]
+	}
+	return network
+}
+
+// toStatus maps the errors of appapi to gRPC status codes. The passwords, session cookies and tokens
+// in the messages are redacted, see appapi.Redact.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := appapi.Redact(err.Error())
+	var validation *appapi.ValidationError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, msg)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, msg)
+	case errors.Is(err, appapi.ErrNotFound):
+		return status.Error(codes.NotFound, msg)
+	case errors.As(err, &validation):
+		return status.Error(codes.InvalidArgument, msg)
+	case errors.Is(err, appapi.ErrSystemLocked):
+		return status.Error(codes.FailedPrecondition, msg)
+	case errors.Is(err, appapi.ErrUnsupportedByServer):
+		return status.Error(codes.Unimplemented, msg)
+	}
+	return status.Error(codes.Unknown, msg)
+}
+
+// callStatus maps the error of a call to a gRPC status. An error after the caller canceled the call
+// or its deadline passed is reported as such, even if a wrapping call lost the context error.
+func callStatus(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil && !errors.Is(err, ctxErr) {
+		err = fmt.Errorf("%w: %v", ctxErr, err)
+	}
+	return toStatus(err)
+}
+
+// required returns codes.InvalidArgument if a field is empty, the fields are given as name and value
+// pairs
+func required(fields ...string) error {
+	var missing []string
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i+1] == "" {
+			missing = append(missing, fields[i])
+		}
+	}
+	if len(missing) > 0 {
+		return status.Errorf(codes.InvalidArgument, "%s is required", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func systemResult(r appapi.SystemResult) *SystemResult {
+	return &SystemResult{SystemId: int64(r.SystemID), Hostname: r.Hostname, Ip: r.IP, Group: r.Group, Action: string(r.Action)}
+}
+
+// AddSystem adds a system to a system group
+func (s *Server) AddSystem(ctx context.Context, req *AddSystemRequest) (*SystemResult, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return nil, err
+	}
+	if err := required("hostname", req.Hostname, "group", req.Group); err != nil {
+		return nil, err
+	}
+	result, err := suma.AddSystem(req.Hostname, req.Group, network(suma, req.Network), appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return systemResult(result), nil
+}
+
+// DeleteSystem deletes a system from SUSE Manager
+func (s *Server) DeleteSystem(ctx context.Context, req *DeleteSystemRequest) (*SystemResult, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return nil, err
+	}
+	if err := required("hostname", req.Hostname); err != nil {
+		return nil, err
+	}
+	result, err := suma.DeleteSystem(req.Hostname, network(suma, req.Network), appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return systemResult(result), nil
+}
+
+// ListGroups returns the system groups
+func (s *Server) ListGroups(ctx context.Context, req *ListGroupsRequest) (*ListGroupsResponse, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := suma.SystemGroups(appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return &ListGroupsResponse{Groups: groups}, nil
+}
+
+// ListGroupSystems returns the members of a system group
+func (s *Server) ListGroupSystems(ctx context.Context, req *ListGroupSystemsRequest) (*ListGroupSystemsResponse, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return nil, err
+	}
+	if err := required("group", req.Group); err != nil {
+		return nil, err
+	}
+	systems, err := suma.GroupSystems(req.Group, appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	resp := &ListGroupSystemsResponse{}
+	for _, system := range systems {
+		resp.Systems = append(resp.Systems, &System{Id: int64(system.ID), Name: system.Name})
+	}
+	return resp, nil
+}
+
+// EnsureGroupMembers makes the members of a system group match the hostnames
+func (s *Server) EnsureGroupMembers(ctx context.Context, req *EnsureGroupMembersRequest) (*GroupChangeReport, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return nil, err
+	}
+	if err := required("group", req.Group); err != nil {
+		return nil, err
+	}
+	report, err := suma.EnsureGroupMembers(req.Group, req.Hostnames, network(suma, req.Network), appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return &GroupChangeReport{Group: report.Group, Added: report.Added, Removed: report.Removed, Unchanged: report.Unchanged}, nil
+}
+
+// ListUsers returns the SUMA users
+func (s *Server) ListUsers(ctx context.Context, req *ListUsersRequest) (*ListUsersResponse, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return nil, err
+	}
+	users, err := suma.Users(appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return &ListUsersResponse{Users: users}, nil
+}
+
+// AddUser creates the user of a system group
+func (s *Server) AddUser(ctx context.Context, req *AddUserRequest) (*UserResult, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return nil, err
+	}
+	if err := required("group", req.Group, "password", req.Password); err != nil {
+		return nil, err
+	}
+	result, err := suma.AddUser(req.Group, req.Password, appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return &UserResult{Login: result.Login, Action: string(result.Action)}, nil
+}
+
+// RemoveUser removes the user and the system group
+func (s *Server) RemoveUser(ctx context.Context, req *RemoveUserRequest) (*RemoveUserResponse, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return nil, err
+	}
+	if err := required("group", req.Group); err != nil {
+		return nil, err
+	}
+	if err := suma.RemoveUser(req.Group, appapi.WithContext(ctx)); err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return &RemoveUserResponse{}, nil
+}
+
+// ListBuildingBlocks returns the building blocks of a project
+func (s *Server) ListBuildingBlocks(ctx context.Context, req *ListBuildingBlocksRequest) (*ListBuildingBlocksResponse, error) {
+	ms, err := s.ms()
+	if err != nil {
+		return nil, err
+	}
+	if err := required("project_id", req.ProjectId); err != nil {
+		return nil, err
+	}
+	bbs, err := ms.ListBuildingBlocks(req.ProjectId, appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	resp := &ListBuildingBlocksResponse{}
+	for _, bb := range bbs {
+		resp.BuildingBlocks = append(resp.BuildingBlocks, &BuildingBlock{Uuid: bb.UUID, Name: bb.Name})
+	}
+	return resp, nil
+}
+
+// GetBuildingBlock returns a building block with its inputs and outputs
+func (s *Server) GetBuildingBlock(ctx context.Context, req *GetBuildingBlockRequest) (*BuildingBlock, error) {
+	ms, err := s.ms()
+	if err != nil {
+		return nil, err
+	}
+	if err := required("uuid", req.Uuid); err != nil {
+		return nil, err
+	}
+	details, err := ms.GetBuildingBlockDetails(req.Uuid, appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return &BuildingBlock{
+		Uuid:           details.UUID,
+		Name:           details.Name,
+		DefinitionUuid: details.DefinitionUUID,
+		Status:         details.Status,
+		Inputs:         details.Inputs,
+		Outputs:        details.Outputs,
+	}, nil
+}
+
+// CreateBuildingBlock creates a building block from its JSON payload
+func (s *Server) CreateBuildingBlock(ctx context.Context, req *CreateBuildingBlockRequest) (*BuildingBlock, error) {
+	ms, err := s.ms()
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Payload) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "payload is required")
+	}
+	uuid, err := ms.CreateBuildingBlock(req.Payload, appapi.WithContext(ctx))
+	if err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return &BuildingBlock{Uuid: uuid}, nil
+}
+
+// DeleteBuildingBlock deletes a building block
+func (s *Server) DeleteBuildingBlock(ctx context.Context, req *DeleteBuildingBlockRequest) (*DeleteBuildingBlockResponse, error) {
+	ms, err := s.ms()
+	if err != nil {
+		return nil, err
+	}
+	if err := required("uuid", req.Uuid); err != nil {
+		return nil, err
+	}
+	if err := ms.DeleteBuildingBlock(req.Uuid, appapi.WithContext(ctx)); err != nil {
+		return nil, callStatus(ctx, err)
+	}
+	return &DeleteBuildingBlockResponse{}, nil
+}
@@ -0,0 +1,154 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arzieg/appapi"
+	"github.com/arzieg/appapi/sumatest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves the server in memory and returns a connection to it
+func dial(t *testing.T, s *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGetBuildingBlock(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblocks/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("uuid") != "bb-1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{
+			"metadata": {"uuid": "bb-1", "definitionUuid": "def-1"},
+			"spec": {"displayName": "vm-1", "inputs": [{"key": "size", "value": "small"}]},
+			"status": {"status": "SUCCEEDED", "outputs": [{"key": "hostname", "value": "vm1.example.com"}]}
+		}`))
+	})
+	msserver := httptest.NewServer(mux)
+	defer msserver.Close()
+
+	ms, err := appapi.NewMsClient(msserver.URL, appapi.NewStaticCredentialProvider(appapi.Credentials{MsClientID: "id", MsClientSecret: "secret"}), appapi.WithInsecure(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := dial(t, NewServer(nil, ms, "secret-token"))
+	client := NewBuildingBlockServiceClient(conn)
+
+	_, err = client.GetBuildingBlock(context.Background(), &GetBuildingBlockRequest{Uuid: "bb-1"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("err = %v, want Unauthenticated without token", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret-token")
+	bb, err := client.GetBuildingBlock(ctx, &GetBuildingBlockRequest{Uuid: "bb-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bb.Name != "vm-1" || bb.Status != "SUCCEEDED" || bb.Outputs["hostname"] != "vm1.example.com" || bb.Inputs["size"] != "small" {
+		t.Errorf("building block = %v", bb)
+	}
+
+	if _, err := client.GetBuildingBlock(ctx, &GetBuildingBlockRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("err = %v, want InvalidArgument without uuid", err)
+	}
+
+	// the server has no SUMA client
+	if _, err := NewGroupServiceClient(conn).ListGroups(ctx, &ListGroupsRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("err = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{appapi.ErrNotFound, codes.NotFound},
+		{&appapi.ValidationError{Payload: "system", Field: "hostname", Reason: "is required"}, codes.InvalidArgument},
+		{appapi.ErrUnsupportedByServer, codes.Unimplemented},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		if got := status.Code(toStatus(tt.err)); got != tt.want {
+			t.Errorf("toStatus(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	err := toStatus(errors.New("login failed: client_secret=s3cr3t&grant_type=client_credentials"))
+	if msg := status.Convert(err).Message(); strings.Contains(msg, "s3cr3t") {
+		t.Errorf("toStatus() message %q contains the secret", msg)
+	}
+}
+
+func TestSumaFaultsReturnStatus(t *testing.T) {
+	srv := sumatest.NewServer(sumatest.Dataset{
+		Systems: []sumatest.System{{ID: 1000010001, Name: "vm1.example.com", IP: "192.168.1.10"}},
+	})
+	defer srv.Close()
+	suma, err := srv.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := dial(t, NewServer(suma, nil, ""))
+
+	srv.Fail("user/listUsers", "database unavailable")
+	if _, err := NewUserServiceClient(conn).RemoveUser(context.Background(), &RemoveUserRequest{Group: "project-a"}); status.Code(err) != codes.Unknown {
+		t.Errorf("RemoveUser() err = %v, want Unknown", err)
+	}
+	srv.Fail("user/listUsers", "")
+
+	if _, err := NewSystemServiceClient(conn).DeleteSystem(context.Background(), &DeleteSystemRequest{Hostname: "unknown.example.com"}); status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteSystem() of an unknown hostname err = %v, want NotFound", err)
+	}
+
+	// the calls with the expired session get 401 Unauthorized
+	srv.ExpireSessions()
+	if _, err := NewSystemServiceClient(conn).DeleteSystem(context.Background(), &DeleteSystemRequest{Hostname: "vm1.example.com"}); status.Code(err) != codes.Unknown {
+		t.Errorf("DeleteSystem() err = %v, want Unknown", err)
+	}
+}
+
+func TestCanceledCall(t *testing.T) {
+	srv := sumatest.NewServer(sumatest.Dataset{})
+	defer srv.Close()
+	suma, err := srv.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = (&Server{Suma: suma}).ListGroups(ctx, &ListGroupsRequest{})
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("ListGroups() err = %v, want Canceled", err)
+	}
+}
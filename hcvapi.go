@@ -2,7 +2,8 @@ package appapi
 
 import (
 	"fmt"
-	"log"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 )
@@ -13,9 +14,15 @@ func VaultGetSecrets(client *api.Client, vaultAddress, group, path string, verbo
 	// Path to the secret
 	secretPath := fmt.Sprintf("kv-clab-%s/data/%s", group, path)
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultGetSecrets: secretPath = %s\n", secretPath)
+		logDebugf("HCVAPI VaultGetSecrets: secretPath = %s", secretPath)
 	}
 
+	return vaultReadKV(client, secretPath, verbose)
+}
+
+// vaultReadKV reads the data of a KV version 2 secret path, e.g. kv-clab-group/data/suma
+func vaultReadKV(client *api.Client, secretPath string, verbose bool) (map[string]interface{}, error) {
+
 	// Retrieve the secret
 	secret, err := client.Logical().Read(secretPath)
 	if err != nil {
@@ -24,21 +31,21 @@ func VaultGetSecrets(client *api.Client, vaultAddress, group, path string, verbo
 
 	// Extract and print the secret data
 	if secret == nil || secret.Data == nil {
-		log.Printf("no secret found at path: %s", secretPath)
+		logErrorf("no secret found at path: %s", secretPath)
 		return nil, fmt.Errorf("no secret found at path: %s", secretPath)
 	}
 
 	// For KV version 2, secret data is in the "data" field
 	secretData, ok := secret.Data["data"].(map[string]interface{})
 	if !ok {
-		log.Printf("invalid secret data format")
+		logErrorf("invalid secret data format")
 		return nil, fmt.Errorf("invalid secret data format")
 	}
 
 	if verbose {
-		log.Println("DEBUG HCVAPI VaultGetSecrets: Retrieved secret:")
+		logDebugf("HCVAPI vaultReadKV: Retrieved secret:")
 		for key := range secretData {
-			log.Printf("DEBUG HCVAPI VaultGetSecrets: %s: *******\n", key)
+			logDebugf("HCVAPI vaultReadKV: %s: *******", key)
 		}
 	}
 	return secretData, nil
@@ -76,7 +83,7 @@ func VaultLogin(roleID, secretID, vaultAddr string, verbose bool) (*api.Client,
 	// Set the token received from authentication
 	client.SetToken(secret.Auth.ClientToken)
 	if verbose {
-		log.Println("DEBUG HCVAPI VaultLogin: Successful authenticate to Vault!")
+		logDebugf("HCVAPI VaultLogin: Successful authenticate to Vault!")
 	}
 	return client, nil
 }
@@ -96,7 +103,7 @@ func VaultLogout(client *api.Client, verbose bool) error {
 	}
 
 	if verbose {
-		log.Println("DEBUG HCVAPI VaultLogout: Successful logged out from Vault!")
+		logDebugf("HCVAPI VaultLogout: Successful logged out from Vault!")
 	}
 	return nil
 }
@@ -125,8 +132,8 @@ func VaultCreatePolicy(client *api.Client, group, policyContent string, verbose
 	*/
 
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultCreatePolicy: policyName: %s\n", policyName)
-		log.Printf("DEBUG HCVAPI VaultCreatePolicy: policyContent:%s\n", policyContent)
+		logDebugf("HCVAPI VaultCreatePolicy: policyName: %s", policyName)
+		logDebugf("HCVAPI VaultCreatePolicy: policyContent:%s", policyContent)
 	}
 
 	_, err = client.Logical().Write(fmt.Sprintf("sys/policies/acl/%s", policyName), map[string]interface{}{
@@ -137,7 +144,7 @@ func VaultCreatePolicy(client *api.Client, group, policyContent string, verbose
 	}
 
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultCreatePolicy: Policy created successfully: %s", policyName)
+		logDebugf("HCVAPI VaultCreatePolicy: Policy created successfully: %s", policyName)
 	}
 
 	return policyName, nil
@@ -154,7 +161,7 @@ func VaultDeletePolicy(client *api.Client, group string, verbose bool) (err erro
 	}
 
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultDeletePolicy: Policy deleted successfully: %s", policyName)
+		logDebugf("HCVAPI VaultDeletePolicy: Policy deleted successfully: %s", policyName)
 	}
 
 	return nil
@@ -177,7 +184,7 @@ func VaultCreateRole(client *api.Client, group, policyName string, verbose bool)
 	}
 
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultCreateRole: AppRole created successfully: %s", group)
+		logDebugf("HCVAPI VaultCreateRole: AppRole created successfully: %s", group)
 	}
 
 	// Retrieve role ID for authentication
@@ -195,7 +202,7 @@ func VaultCreateRole(client *api.Client, group, policyName string, verbose bool)
 	}
 
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultCreateRole: Got roleID: %s\n", roleID)
+		logDebugf("HCVAPI VaultCreateRole: Got roleID: %s", roleID)
 	}
 
 	// get secretID
@@ -213,7 +220,7 @@ func VaultCreateRole(client *api.Client, group, policyName string, verbose bool)
 	}
 
 	if verbose {
-		log.Println("DEBUG HCVAPI VaultCreateRole: Got secretID: #########")
+		logDebugf("HCVAPI VaultCreateRole: Got secretID: #########")
 	}
 
 	return roleID, secretID, nil
@@ -230,7 +237,7 @@ func VaultRemoveRole(client *api.Client, group string, verbose bool) (err error)
 	}
 
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultRemoveRole: AppRole successfully deleted: %s", group)
+		logDebugf("HCVAPI VaultRemoveRole: AppRole successfully deleted: %s", group)
 	}
 
 	return nil
@@ -260,7 +267,7 @@ func VaultEnableKVv2(client *api.Client, path string, verbose bool) (err error)
 
 	if _, exists := mounts[mountPath]; exists {
 		if verbose {
-			log.Printf("DEBUG HCVAPI VaultEnableKVv2: KV v2 is already enabled at: %s\n", path)
+			logDebugf("HCVAPI VaultEnableKVv2: KV v2 is already enabled at: %s", path)
 		}
 		return nil
 	}
@@ -272,7 +279,7 @@ func VaultEnableKVv2(client *api.Client, path string, verbose bool) (err error)
 	}
 
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultEnableKVv2: KV v2 successfully enabled at:%s\n", path)
+		logDebugf("HCVAPI VaultEnableKVv2: KV v2 successfully enabled at:%s", path)
 	}
 	return nil
 }
@@ -294,7 +301,7 @@ func VaultDisableKVv2(client *api.Client, path string, verbose bool) (err error)
 
 	if _, exists := mounts[mountPath]; !exists {
 		if verbose {
-			log.Printf("DEBUG HCVAPI VaultDisableKVv2: KV v2 is already disabled: %s\n", path)
+			logDebugf("HCVAPI VaultDisableKVv2: KV v2 is already disabled: %s", path)
 		}
 		return nil
 	}
@@ -306,7 +313,7 @@ func VaultDisableKVv2(client *api.Client, path string, verbose bool) (err error)
 	}
 
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultDisableKVv2: KV v2 successful disable path:%s\n", path)
+		logDebugf("HCVAPI VaultDisableKVv2: KV v2 successful disable path:%s", path)
 	}
 	return nil
 }
@@ -341,8 +348,198 @@ func VaultUpdateSecret(client *api.Client, path, key, value string, verbose bool
 	}
 
 	if verbose {
-		log.Printf("DEBUG HCVAPI VaultUpdateSecret: Successful update secret on %s", path)
+		logDebugf("HCVAPI VaultUpdateSecret: Successful update secret on %s", path)
 	}
 
 	return nil
 }
+
+// VaultGetCredentials reads the SUSE Manager and Meshstack login data from two KV version 2 paths.
+// The SUMA secret must contain the keys username and password or the key token with an API token,
+// see SumaLoginWithToken, the Meshstack secret the keys
+// client_id and client_secret. An empty path skips the corresponding backend.
+func VaultGetCredentials(client *api.Client, sumaPath, meshstackPath string, verbose bool) (creds Credentials, err error) {
+
+	if sumaPath != "" {
+		sumaData, err := vaultReadKV(client, sumaPath, verbose)
+		if err != nil {
+			return creds, fmt.Errorf("failed to read SUMA credentials: %v", err)
+		}
+		if token, ok := sumaData["token"].(string); ok && token != "" {
+			creds.SumaToken = token
+			creds.SumaUsername, _ = sumaData["username"].(string)
+		} else {
+			if creds.SumaUsername, err = vaultGetString(sumaData, sumaPath, "username"); err != nil {
+				return creds, err
+			}
+			if creds.SumaPassword, err = vaultGetString(sumaData, sumaPath, "password"); err != nil {
+				return creds, err
+			}
+		}
+	}
+
+	if meshstackPath != "" {
+		msData, err := vaultReadKV(client, meshstackPath, verbose)
+		if err != nil {
+			return creds, fmt.Errorf("failed to read Meshstack credentials: %v", err)
+		}
+		if creds.MsClientID, err = vaultGetString(msData, meshstackPath, "client_id"); err != nil {
+			return creds, err
+		}
+		if creds.MsClientSecret, err = vaultGetString(msData, meshstackPath, "client_secret"); err != nil {
+			return creds, err
+		}
+	}
+
+	if verbose {
+		logDebugf("HCVAPI VaultGetCredentials: Got credentials for SUMA user %s and Meshstack client %s", creds.SumaUsername, creds.MsClientID)
+	}
+
+	return creds, nil
+}
+
+// vaultGetString returns the string value of a key in secret data
+func vaultGetString(data map[string]interface{}, path, key string) (string, error) {
+	value, ok := data[key].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("key %s not found in secret %s", key, path)
+	}
+	return value, nil
+}
+
+// VaultSession is an AppRole login to Vault which renews its token in the background.
+// If the token could not be renewed anymore (e.g. token_max_ttl reached), the session logs in again.
+type VaultSession struct {
+	roleID    string
+	secretID  string
+	vaultAddr string
+	verbose   bool
+	clock     Clock
+
+	mu     sync.RWMutex
+	client *api.Client
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewVaultSession login to Vault with AppRole and start the token renewal. The renewal waits on the
+// clock of WithClock, the DefaultClock by default.
+func NewVaultSession(roleID, secretID, vaultAddr string, verbose bool, opts ...Option) (*VaultSession, error) {
+
+	client, err := VaultLogin(roleID, secretID, vaultAddr, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &VaultSession{
+		roleID:    roleID,
+		secretID:  secretID,
+		vaultAddr: vaultAddr,
+		verbose:   verbose,
+		clock:     newCallOptions(verbose, opts).clock,
+		client:    client,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	go s.renew()
+
+	return s, nil
+}
+
+// VaultLoginFromConfig creates a VaultSession with the AppRole settings of the configuration.
+func VaultLoginFromConfig(cfg Config, verbose bool) (*VaultSession, error) {
+	addr, roleID, secretID := cfg.vaultAppRole()
+	if addr == "" || roleID == "" || secretID == "" {
+		return nil, fmt.Errorf("vault address, role id and secret id must be configured")
+	}
+	return NewVaultSession(roleID, secretID, addr, verbose)
+}
+
+// Client returns the current logged in Vault client
+func (s *VaultSession) Client() *api.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+// Credentials reads the SUMA and Meshstack credentials from the configured KV paths
+func (s *VaultSession) Credentials(sumaPath, meshstackPath string) (Credentials, error) {
+	return VaultGetCredentials(s.Client(), sumaPath, meshstackPath, s.verbose)
+}
+
+// Close stops the token renewal and revokes the token. Further calls return the result of the
+// first one.
+func (s *VaultSession) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		s.closeErr = VaultLogout(s.Client(), s.verbose)
+	})
+	return s.closeErr
+}
+
+// renew keeps the token alive. It renews the token after 2/3 of its TTL.
+func (s *VaultSession) renew() {
+	defer close(s.done)
+
+	for {
+		ttl, err := s.tokenTTL()
+		if err != nil {
+			logErrorf("failed to get vault token ttl: %v", err)
+			ttl = 3 * time.Minute
+		}
+
+		// a TTL of 0 is a token without expiry, nothing to renew
+		if ttl == 0 {
+			<-s.stop
+			return
+		}
+
+		wait := ttl * 2 / 3
+		if wait < time.Second {
+			wait = time.Second
+		}
+
+		if s.verbose {
+			logDebugf("HCVAPI VaultSession: next token renewal in %s", wait)
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-s.clock.After(wait):
+		}
+
+		_, err = s.Client().Auth().Token().RenewSelf(0)
+		if err == nil {
+			if s.verbose {
+				logDebugf("HCVAPI VaultSession: token renewed")
+			}
+			continue
+		}
+
+		logWarnf("failed to renew vault token, login again: %v", err)
+		client, err := VaultLogin(s.roleID, s.secretID, s.vaultAddr, s.verbose)
+		if err != nil {
+			logErrorf("failed to login to vault: %v", err)
+			continue
+		}
+
+		s.mu.Lock()
+		s.client = client
+		s.mu.Unlock()
+	}
+}
+
+// tokenTTL returns the remaining lifetime of the token
+func (s *VaultSession) tokenTTL() (time.Duration, error) {
+	secret, err := s.Client().Auth().Token().LookupSelf()
+	if err != nil {
+		return 0, err
+	}
+	return secret.TokenTTL()
+}
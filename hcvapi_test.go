@@ -0,0 +1,96 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeVault serves the AppRole login and the token endpoints of Vault. The renewal of the tokens
+// fails after the given number of renewals, so the session logs in again.
+type fakeVault struct {
+	mu       sync.Mutex
+	logins   int
+	renewals int
+	revoked  []string
+}
+
+func newFakeVault(t *testing.T, renewals int) (*fakeVault, *httptest.Server) {
+	t.Helper()
+	v := &fakeVault{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		v.logins++
+		fmt.Fprintf(w, `{"auth": {"client_token": "token-%d", "lease_duration": 30, "renewable": true}}`, v.logins)
+	})
+	mux.HandleFunc("GET /v1/auth/token/lookup-self", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": {"ttl": 30}}`)
+	})
+	mux.HandleFunc("PUT /v1/auth/token/renew-self", func(w http.ResponseWriter, r *http.Request) {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if v.renewals == renewals {
+			http.Error(w, `{"errors": ["token not renewable"]}`, http.StatusForbidden)
+			return
+		}
+		v.renewals++
+		fmt.Fprintf(w, `{"auth": {"client_token": %q, "lease_duration": 30, "renewable": true}}`, r.Header.Get("X-Vault-Token"))
+	})
+	mux.HandleFunc("PUT /v1/auth/token/revoke-self", func(w http.ResponseWriter, r *http.Request) {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		v.revoked = append(v.revoked, r.Header.Get("X-Vault-Token"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return v, server
+}
+
+func (v *fakeVault) counts() (logins, renewals int, revoked []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.logins, v.renewals, append([]string(nil), v.revoked...)
+}
+
+func TestVaultSessionRenewAndClose(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	vault, server := newFakeVault(t, 1)
+	session, err := NewVaultSession("role", "secret", server.URL, false, WithClock(clock))
+	if err != nil {
+		t.Fatalf("NewVaultSession() error = %v", err)
+	}
+
+	// the token is renewed after 2/3 of its TTL
+	clock.BlockUntil(1)
+	clock.Advance(20 * time.Second)
+	clock.BlockUntil(1)
+	if logins, renewals, _ := vault.counts(); logins != 1 || renewals != 1 {
+		t.Errorf("got %d logins and %d renewals, want the token renewed", logins, renewals)
+	}
+
+	// a token which cannot be renewed anymore is replaced by a new login
+	clock.Advance(20 * time.Second)
+	clock.BlockUntil(1)
+	if logins, _, _ := vault.counts(); logins != 2 {
+		t.Errorf("got %d logins, want a new login after the failed renewal", logins)
+	}
+	if token := session.Client().Token(); token != "token-2" {
+		t.Errorf("token = %q, want token-2", token)
+	}
+
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := session.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if _, _, revoked := vault.counts(); len(revoked) != 1 || revoked[0] != "token-2" {
+		t.Errorf("revoked tokens = %v, want token-2 revoked once", revoked)
+	}
+}
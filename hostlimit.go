@@ -0,0 +1,79 @@
+package appapi
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// WithHostConcurrency limits the calls in flight to the server of a call, e.g. the SUMA server, to
+// limit. The limit is shared by all calls to the server with this option, also of other clients, so
+// a bulk run with a high concurrency can be aggressive against one backend and gentle with the
+// other. Further calls wait for a free slot, the wait counts into the timeout. A call holds its slot
+// until its response is read, the retries included. If the server is called with different limits,
+// the smallest one applies to all its calls from the first call with it on. 0 is unlimited, see
+// APPAPI_SUMA_MAX_CONCURRENCY and APPAPI_MS_MAX_CONCURRENCY.
+//
+//	suma, err := NewSumaClient(url, creds, WithHostConcurrency(20))
+//	ms, err := NewMsClient(msurl, creds, WithHostConcurrency(4))
+func WithHostConcurrency(limit int) Option {
+	return func(o *callOptions) {
+		o.hostLimit = limit
+	}
+}
+
+// hostSlots are the calls in flight to a server and the smallest limit the server was called with
+type hostSlots struct {
+	limit int
+	used  int
+	// freed is closed and replaced when a slot is freed
+	freed chan struct{}
+}
+
+// hostLimits are the slots of the servers called with a concurrency limit
+var hostLimits = struct {
+	mu    sync.Mutex
+	hosts map[string]*hostSlots
+}{hosts: map[string]*hostSlots{}}
+
+// acquireHost waits for a free slot of the server of the request and returns the function freeing it
+func acquireHost(req *http.Request, limit int) (release func(), err error) {
+	hostLimits.mu.Lock()
+	slots, ok := hostLimits.hosts[req.URL.Host]
+	if !ok {
+		slots = &hostSlots{limit: limit, freed: make(chan struct{})}
+		hostLimits.hosts[req.URL.Host] = slots
+	}
+	slots.limit = min(slots.limit, limit)
+	for slots.used >= slots.limit {
+		freed := slots.freed
+		hostLimits.mu.Unlock()
+		select {
+		case <-freed:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		hostLimits.mu.Lock()
+	}
+	slots.used++
+	hostLimits.mu.Unlock()
+
+	return sync.OnceFunc(func() {
+		hostLimits.mu.Lock()
+		defer hostLimits.mu.Unlock()
+		slots.used--
+		close(slots.freed)
+		slots.freed = make(chan struct{})
+	}), nil
+}
+
+// releaseBody frees the slot of a call when the response body is closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,130 @@
+package appapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newConcurrencyServer returns a server and the maximum number of requests it served at the same time
+func newConcurrencyServer(t *testing.T) (*httptest.Server, func() int) {
+	t.Helper()
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprint(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return maxInFlight
+	}
+}
+
+func TestWithHostConcurrency(t *testing.T) {
+	suma, sumaMax := newConcurrencyServer(t)
+	ms, msMax := newConcurrencyServer(t)
+
+	var wg sync.WaitGroup
+	for i := range 12 {
+		for _, target := range []struct {
+			url   string
+			limit int
+		}{{suma.URL, 2}, {ms.URL, 5}} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/call/%d", target.url, i), nil)
+				resp, err := newCallOptions(false, []Option{WithHostConcurrency(target.limit)}).do(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}()
+		}
+	}
+	wg.Wait()
+
+	if n := sumaMax(); n > 2 {
+		t.Errorf("%d calls to SUMA at the same time, want at most 2", n)
+	}
+	if n := msMax(); n > 5 || n <= 2 {
+		t.Errorf("%d calls to Meshstack at the same time, want 3 to 5", n)
+	}
+}
+
+func TestWithHostConcurrencyWaitTimeout(t *testing.T) {
+	server, _ := newConcurrencyServer(t)
+
+	// the slot is held until the body is closed, a coalesced call reads it at once
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/first", nil)
+	resp, err := newCallOptions(false, []Option{WithHostConcurrency(1), WithCoalescing(false)}).do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/second", nil)
+	if _, err := newCallOptions(false, []Option{WithHostConcurrency(1)}).do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v while the slot is taken, want %v", err, context.DeadlineExceeded)
+	}
+
+	resp.Body.Close()
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/third", nil)
+	resp, err = newCallOptions(false, []Option{WithHostConcurrency(1)}).do(req)
+	if err != nil {
+		t.Fatalf("error = %v after the slot was freed", err)
+	}
+	resp.Body.Close()
+}
+
+func TestWithHostConcurrencyMixedLimits(t *testing.T) {
+	server, maxInFlight := newConcurrencyServer(t)
+	call := func(limit, i int) {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/call/%d/%d", server.URL, limit, i), nil)
+		resp, err := newCallOptions(false, []Option{WithHostConcurrency(limit)}).do(req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	// two clients of the same server with different limits share the smallest limit once it is known
+	call(3, 0)
+	call(1, 0)
+	var wg sync.WaitGroup
+	for i := range 12 {
+		for _, limit := range []int{1, 3} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				call(limit, i)
+			}()
+		}
+	}
+	wg.Wait()
+
+	if n := maxInFlight(); n != 1 {
+		t.Errorf("%d calls at the same time, want 1 of the smaller limit", n)
+	}
+}
@@ -0,0 +1,54 @@
+package appapi
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// HostnameNormalization configures how hostnames are normalized before they are looked up or
+// compared. Hostnames are always lowercased and trailing dots are removed, e.g. WEB01.example.com.
+// becomes web01.example.com.
+type HostnameNormalization struct {
+	// Domain is appended to short names without a dot, e.g. example.com turns web01 into
+	// web01.example.com. Empty keeps short names.
+	Domain string
+}
+
+// Normalize returns the normalized hostname. IP addresses are not changed.
+func (n HostnameNormalization) Normalize(hostname string) string {
+	name := strings.ToLower(strings.TrimRight(strings.TrimSpace(hostname), "."))
+	if name == "" {
+		return name
+	}
+	if _, err := netip.ParseAddr(name); err == nil {
+		return name
+	}
+
+	domain := strings.ToLower(strings.Trim(strings.TrimSpace(n.Domain), "."))
+	if domain != "" && !strings.Contains(name, ".") {
+		name += "." + domain
+	}
+	return name
+}
+
+// NormalizeAll returns the normalized hostnames
+func (n HostnameNormalization) NormalizeAll(hostnames []string) []string {
+	normalized := make([]string, len(hostnames))
+	for i, hostname := range hostnames {
+		normalized[i] = n.Normalize(hostname)
+	}
+	return normalized
+}
+
+// NormalizeHostname lowercases the hostname and removes trailing dots
+func NormalizeHostname(hostname string) string {
+	return HostnameNormalization{}.Normalize(hostname)
+}
+
+// containsFold reports if list contains s ignoring the case, SUMA logins are not case sensitive
+func containsFold(list []string, s string) bool {
+	return slices.ContainsFunc(list, func(item string) bool {
+		return strings.EqualFold(item, s)
+	})
+}
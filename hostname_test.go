@@ -0,0 +1,97 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostnameNormalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		hostname string
+		want     string
+	}{
+		{name: "lowercase", hostname: "WEB01.Example.com", want: "web01.example.com"},
+		{name: "trailing dot", hostname: "web01.example.com.", want: "web01.example.com"},
+		{name: "whitespace", hostname: " web01 ", want: "web01"},
+		{name: "short name without domain", hostname: "Web01", want: "web01"},
+		{name: "short name with domain", domain: "Example.com.", hostname: "Web01", want: "web01.example.com"},
+		{name: "fqdn keeps its domain", domain: "example.com", hostname: "web01.other.net", want: "web01.other.net"},
+		{name: "ip address", domain: "example.com", hostname: "192.168.1.10", want: "192.168.1.10"},
+		{name: "empty", domain: "example.com", hostname: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HostnameNormalization{Domain: tt.domain}.Normalize(tt.hostname)
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.hostname, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSumaGetSystemIDNormalized(t *testing.T) {
+	var queried []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		queried = append(queried, name)
+		switch name {
+		case "web01.example.com":
+			fmt.Fprint(w, `{"success": true, "result": [{"id": 42, "name": "web01.example.com"}]}`)
+		case "LEGACY01":
+			fmt.Fprint(w, `{"success": true, "result": [{"id": 7, "name": "LEGACY01"}]}`)
+		default:
+			fmt.Fprint(w, `{"success": true, "result": []}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		hostname    string
+		wantID      int
+		wantQueries []string
+	}{
+		{hostname: "WEB01.example.com.", wantID: 42, wantQueries: []string{"web01.example.com"}},
+		{hostname: "LEGACY01", wantID: 7, wantQueries: []string{"legacy01", "LEGACY01"}},
+		{hostname: "unknown", wantID: -1, wantQueries: []string{"unknown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hostname, func(t *testing.T) {
+			queried = nil
+			id, _ := sumaGetSystemID("dummy", server.URL, tt.hostname, false)
+			if id != tt.wantID {
+				t.Errorf("sumaGetSystemID(%q) = %d, want %d", tt.hostname, id, tt.wantID)
+			}
+			if fmt.Sprint(queried) != fmt.Sprint(tt.wantQueries) {
+				t.Errorf("queried %v, want %v", queried, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestSumaCheckUserIgnoresCase(t *testing.T) {
+	api := fakeSumaAPI{users: fakeList("Project-A")}
+	if ok, err := sumaCheckUser(api, "dummy", "project-a", "http://suma", false); err != nil || !ok {
+		t.Error("expected user project-a to match Project-A")
+	}
+}
+
+func TestSumaCheckReturnsListErrors(t *testing.T) {
+	failed := errors.New("HTTP Request failed: HTTP/500")
+	api := fakeSumaAPI{
+		systemGroups: func() ([]string, error) { return nil, failed },
+		users:        func() ([]string, error) { return nil, failed },
+	}
+	if _, err := sumaCheckSystemGroup(api, "dummy", "project-a", "http://suma", false); !errors.Is(err, failed) {
+		t.Errorf("sumaCheckSystemGroup() error = %v, want %v", err, failed)
+	}
+	if _, err := sumaCheckUser(api, "dummy", "project-a", "http://suma", false); !errors.Is(err, failed) {
+		t.Errorf("sumaCheckUser() error = %v, want %v", err, failed)
+	}
+}
@@ -0,0 +1,186 @@
+package appapi
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrResponseTooLarge is returned while reading a response body which exceeds MaxResponseSize
+var ErrResponseTooLarge = errors.New("response exceeds the maximum size")
+
+// MaxResponseSize is the maximum size of a (decompressed) response body in bytes, 0 is unlimited.
+// It protects long running reconcilers from pathological responses, see APPAPI_MAX_RESPONSE_SIZE.
+var MaxResponseSize = Envs.MaxResponseSize
+
+// Timeout is the default timeout of an API call including reading the response, see WithTimeout
+var Timeout = Envs.Timeout
+
+// AllowInsecure accepts http:// URLs for the clients and disables the verification of TLS
+// certificates. It is meant for tests and labs only, a warning is logged when it is used. A client
+// can accept them on its own with WithInsecure.
+var AllowInsecure = Envs.AllowInsecure
+
+// MaxIdleConnsPerHost is the number of idle connections kept per server for the next calls. Calls
+// above it, e.g. of a bulk operation with a higher concurrency, open a new TLS connection each, see
+// TransportStats. It must be set before the first call.
+var MaxIdleConnsPerHost = Envs.MaxIdleConnsPerHost
+
+// MaxConnsPerHost limits the connections per server, further calls wait for a free connection. 0 is
+// unlimited. It must be set before the first call.
+var MaxConnsPerHost = Envs.MaxConnsPerHost
+
+var (
+	secureTransport = sync.OnceValue(func() *http.Transport {
+		return newTransport(false)
+	})
+	insecureTransport = sync.OnceValue(func() *http.Transport {
+		logWarnf("AllowInsecure is set, TLS certificates are NOT verified and plain http is accepted")
+		return newTransport(true)
+	})
+)
+
+// newTransport returns a transport with the proxy and timeout settings of http.DefaultTransport and
+// the connection limits, which requires TLS 1.2 and verifies the certificates unless insecure is set
+func newTransport(insecure bool) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = MaxIdleConnsPerHost
+	t.MaxIdleConns = max(t.MaxIdleConns, MaxIdleConnsPerHost)
+	t.MaxConnsPerHost = MaxConnsPerHost
+	t.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecure, //nolint:gosec // explicit opt-out with AllowInsecure
+	}
+	return t
+}
+
+// newHTTPClient returns the client for the API calls. It requests gzip compressed responses, which
+// are decompressed transparently, limits the response bodies to MaxResponseSize and retries
+// throttled calls, see RetryPolicy.
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: &apiTransport{base: defaultTransport(AllowInsecure)}}
+}
+
+// defaultTransport returns the transport sending the API calls, see AllowInsecure
+func defaultTransport(insecure bool) http.RoundTripper {
+	if insecure {
+		return insecureTransport()
+	}
+	return secureTransport()
+}
+
+// apiTransport handles the compression, the size limit, the retries, the conditional requests and
+// the coalescing of the calls
+type apiTransport struct {
+	base        http.RoundTripper
+	retry       *RetryPolicy
+	etags       *ETagCache
+	coalesce    bool
+	compressMin int64
+	// clock is the clock of the retries, DefaultClock if nil
+	clock Clock
+
+	// hostLimit is the concurrency limit of the server, see WithHostConcurrency
+	hostLimit int
+}
+
+func (t *apiTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.coalesce && req.Method == http.MethodGet {
+		return readFlights.do(req, t.roundTrip)
+	}
+	return t.roundTrip(req)
+}
+
+func (t *apiTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	var cached *etagEntry
+	if t.etags != nil {
+		req, cached = t.etags.conditional(req)
+	}
+
+	release := func() {}
+	if t.hostLimit > 0 {
+		var err error
+		if release, err = acquireHost(req, t.hostLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.send(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if t.hostLimit > 0 {
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	}
+
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") && resp.ContentLength != 0 && req.Method != http.MethodHead {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("error decompressing response: %v", err)
+		}
+		resp.Body = &gzipBody{Reader: gz, body: resp.Body}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+
+	if limit := MaxResponseSize; limit > 0 {
+		if resp.ContentLength > limit {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: %d bytes, limit %d bytes", ErrResponseTooLarge, resp.ContentLength, limit)
+		}
+		resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: limit}
+	}
+
+	if t.etags != nil {
+		return t.etags.response(req, resp, cached)
+	}
+	return resp, nil
+}
+
+// gzipBody decompresses a response body and closes the compressed body
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// limitedBody fails with ErrResponseTooLarge instead of returning more than remaining bytes
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// the limit is reached, the body is only too large if there is more
+		var one [1]byte
+		n, err := b.ReadCloser.Read(one[:])
+		if n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,118 @@
+package appapi
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newGzipServer(t *testing.T, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("expected Accept-Encoding gzip, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		io.WriteString(gz, body)
+		gz.Close()
+	}))
+}
+
+func TestHTTPClientGzip(t *testing.T) {
+	body := `{"success": true, "result": [{"id": 42, "name": "testhost"}]}`
+	server := newGzipServer(t, body)
+	defer server.Close()
+
+	resp, err := newHTTPClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != body {
+		t.Errorf("got body %q, want %q", got, body)
+	}
+
+	id, err := sumaGetSystemID("dummy", server.URL, "testhost", false)
+	if err != nil || id != 42 {
+		t.Errorf("sumaGetSystemID() = %d, %v, want 42", id, err)
+	}
+}
+
+func TestHTTPClientMaxResponseSize(t *testing.T) {
+	defer func(size int64) { MaxResponseSize = size }(MaxResponseSize)
+
+	body := strings.Repeat("x", 100)
+	tests := []struct {
+		name    string
+		limit   int64
+		gzip    bool
+		wantErr bool
+	}{
+		{name: "below limit", limit: 200},
+		{name: "exactly the limit", limit: 100},
+		{name: "above limit", limit: 50, wantErr: true},
+		{name: "compressed above limit", limit: 50, gzip: true, wantErr: true},
+		{name: "unlimited", limit: 0, gzip: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			MaxResponseSize = tt.limit
+
+			var server *httptest.Server
+			if tt.gzip {
+				server = newGzipServer(t, body)
+			} else {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					io.WriteString(w, body)
+				}))
+			}
+			defer server.Close()
+
+			resp, err := newHTTPClient().Get(server.URL)
+			if err == nil {
+				defer resp.Body.Close()
+				var got []byte
+				got, err = io.ReadAll(resp.Body)
+				if err == nil && string(got) != body {
+					t.Errorf("got %d bytes, want %d", len(got), len(body))
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrResponseTooLarge) {
+				t.Errorf("expected ErrResponseTooLarge, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPClientTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if resp, err := newCallOptions(false, []Option{WithInsecure(false)}).do(req); err == nil {
+		resp.Body.Close()
+		t.Error("expected certificate error for self-signed certificate, got nil")
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := newCallOptions(false, []Option{WithInsecure(true)}).do(req)
+	if err != nil {
+		t.Fatalf("unexpected error with WithInsecure: %v", err)
+	}
+	resp.Body.Close()
+}
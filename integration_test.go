@@ -0,0 +1,215 @@
+//go:build integration
+
+package appapi
+
+// The integration tests run the login, group and user flows against a real Uyuni server, catching
+// API changes which the fake servers cannot. They are only built with the integration tag:
+//
+//	APPAPI_UYUNI_URL=https://uyuni.example.com APPAPI_UYUNI_PASSWORD=... go test -tags integration -run Integration .
+//
+// Without APPAPI_UYUNI_URL a container is started from APPAPI_UYUNI_IMAGE and removed afterwards.
+// The image must be set up with the administrator of APPAPI_UYUNI_USER and APPAPI_UYUNI_PASSWORD.
+// Everything created by the tests is named with the prefix appapi-it- and deleted again.
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	EnvUyuniURL      = "APPAPI_UYUNI_URL"
+	EnvUyuniUser     = "APPAPI_UYUNI_USER"
+	EnvUyuniPassword = "APPAPI_UYUNI_PASSWORD"
+	EnvUyuniImage    = "APPAPI_UYUNI_IMAGE"
+	EnvUyuniTimeout  = "APPAPI_UYUNI_TIMEOUT"
+
+	integrationPrefix = "appapi-it-"
+)
+
+// uyuniURL is the server of the tests, empty if the integration tests are skipped
+var uyuniURL string
+
+// TestMain starts the Uyuni container shared by the tests if no server is configured
+func TestMain(m *testing.M) {
+	uyuniURL = os.Getenv(EnvUyuniURL)
+	image := os.Getenv(EnvUyuniImage)
+	if uyuniURL != "" || image == "" {
+		os.Exit(m.Run())
+	}
+
+	// Uyuni runs systemd in the container
+	id, err := docker("run", "--detach", "--privileged", "--publish", "127.0.0.1::443", image)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	uyuniURL, err = waitForUyuni(id)
+	code := 1
+	if err == nil {
+		code = m.Run()
+	} else {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if _, err := docker("rm", "--force", "--volumes", id); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	os.Exit(code)
+}
+
+// waitForUyuni returns the URL of the container once the API answers
+func waitForUyuni(id string) (string, error) {
+	timeout := 15 * time.Minute
+	if s := os.Getenv(EnvUyuniTimeout); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", EnvUyuniTimeout, err)
+		}
+		timeout = d
+	}
+
+	ports, err := docker("port", id, "443/tcp")
+	if err != nil {
+		return "", err
+	}
+	// the first address, docker lists the IPv4 and IPv6 mappings
+	port, _, _ := strings.Cut(ports, "\n")
+	url := "https://" + port
+
+	deadline := time.Now().Add(timeout)
+	for {
+		// the server answers 503 while it starts, the retries of the calls would only wait longer
+		_, err := SumaGetVersion("", url, false, WithRetry(RetryPolicy{}), WithInsecure(true))
+		if err == nil {
+			return url, nil
+		}
+		if time.Now().After(deadline) {
+			logs, _ := docker("logs", "--tail", "50", id)
+			return "", fmt.Errorf("uyuni container %s is not ready after %v: %v\n%s", id, timeout, err, logs)
+		}
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// docker runs a docker command and returns its trimmed output
+func docker(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %v: %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// newIntegrationClient logs in to the Uyuni server and logs out at the end of the test
+func newIntegrationClient(t *testing.T) *SumaClient {
+	t.Helper()
+	if uyuniURL == "" {
+		t.Skipf("neither %s nor %s is set", EnvUyuniURL, EnvUyuniImage)
+	}
+	user := os.Getenv(EnvUyuniUser)
+	if user == "" {
+		user = "admin"
+	}
+	creds := NewStaticCredentialProvider(Credentials{SumaUsername: user, SumaPassword: os.Getenv(EnvUyuniPassword)})
+	c, err := NewSumaClient(uyuniURL, creds, WithInsecure(true))
+	if err != nil {
+		t.Fatalf("login to %s: %v", uyuniURL, err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func integrationName(t *testing.T) string {
+	return fmt.Sprintf("%s%s-%d", integrationPrefix, strings.ToLower(t.Name()), time.Now().Unix()%100000)
+}
+
+func TestIntegrationLogin(t *testing.T) {
+	c := newIntegrationClient(t)
+	if c.Version.Raw == "" {
+		t.Error("the server version was not detected")
+	}
+	if _, err := c.SystemGroups(); err != nil {
+		t.Fatal(err)
+	}
+
+	creds := NewStaticCredentialProvider(Credentials{SumaUsername: "admin", SumaPassword: "appapi-wrong-password"})
+	if _, err := NewSumaClient(c.URL, creds); err == nil {
+		t.Error("login with a wrong password must fail")
+	}
+}
+
+func TestIntegrationSystemGroup(t *testing.T) {
+	c := newIntegrationClient(t)
+	name := integrationName(t)
+	t.Cleanup(func() {
+		if err := DeleteSystemGroup(c, name); err != nil {
+			t.Errorf("cleanup of group %s: %v", name, err)
+		}
+	})
+
+	status, err := EnsureSystemGroup(c, SystemGroup{Name: name, Description: "created by the appapi integration tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Action != ResultCreated || status.GroupID == 0 {
+		t.Errorf("status = %+v, want a created group", status)
+	}
+
+	status, err = EnsureSystemGroup(c, SystemGroup{Name: name, Description: "updated by the appapi integration tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Action != ResultUpdated {
+		t.Errorf("action = %q, want %q", status.Action, ResultUpdated)
+	}
+
+	if err := DeleteSystemGroup(c, name); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := ObserveSystemGroup(c, name); err != nil || status.Phase != PhaseAbsent {
+		t.Errorf("status = %+v, err = %v after delete", status, err)
+	}
+}
+
+func TestIntegrationUser(t *testing.T) {
+	c := newIntegrationClient(t)
+	login := integrationName(t)
+	t.Cleanup(func() {
+		if err := DeleteSumaUser(c, login); err != nil {
+			t.Errorf("cleanup of user %s: %v", login, err)
+		}
+	})
+
+	status, err := EnsureSumaUser(c, SumaUser{Login: login, Password: "appapi-It-p4ssword", Email: login + "@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Action != ResultCreated {
+		t.Errorf("action = %q, want %q", status.Action, ResultCreated)
+	}
+
+	if err := c.AddRole(login, RoleSystemGroupAdmin); err != nil {
+		t.Fatal(err)
+	}
+	roles, err := c.Roles(login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(roles, RoleSystemGroupAdmin) {
+		t.Errorf("roles = %v, want %s", roles, RoleSystemGroupAdmin)
+	}
+
+	if err := DeleteSumaUser(c, login); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := ObserveSumaUser(c, login); err != nil || status.Phase != PhaseAbsent {
+		t.Errorf("status = %+v, err = %v after delete", status, err)
+	}
+}
@@ -0,0 +1,489 @@
+package appapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The status of jobs and their steps
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// ErrUnknownWorkflow is returned if a job is submitted for a workflow which is not registered
+var ErrUnknownWorkflow = errors.New("unknown workflow")
+
+// ErrJobLocked is returned by Run if the job is run by another runner, also of another process
+var ErrJobLocked = errors.New("job is run by another runner")
+
+// Job is a run of a workflow. It is saved after every step, so a job interrupted by a restart of the
+// process is resumed at the step which did not finish.
+type Job struct {
+	ID       string `json:"id"`
+	Workflow string `json:"workflow"`
+	Status   string `json:"status"`
+	// Params are the inputs of the workflow, e.g. the building block payload
+	Params map[string]string `json:"params,omitempty" output:"-"`
+	// State are the results of the finished steps, e.g. the UUID of the created building block
+	State   map[string]string `json:"state,omitempty" output:"-"`
+	Steps   []JobStep         `json:"steps" output:"-"`
+	Error   string            `json:"error,omitempty"`
+	Created time.Time         `json:"created"`
+	Updated time.Time         `json:"updated"`
+}
+
+// JobStep is the progress of a step of a job
+type JobStep struct {
+	Name     string    `json:"name"`
+	Status   string    `json:"status"`
+	Error    string    `json:"error,omitempty"`
+	Finished time.Time `json:"finished,omitzero"`
+}
+
+// Current returns the name of the first step which is not finished, empty if all steps succeeded
+func (j *Job) Current() string {
+	for _, s := range j.Steps {
+		if s.Status != JobSucceeded {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// Done reports if the job succeeded or failed
+func (j *Job) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// WorkflowStep is a step of a workflow. Run reads the params and the results of the previous steps
+// from the job and records its own results in job.State. A step interrupted by a restart is run again,
+// so it should skip work whose result is already recorded.
+type WorkflowStep struct {
+	Name string
+	Run  func(ctx context.Context, job *Job) error
+}
+
+// Workflow is a named sequence of steps
+type Workflow struct {
+	Name  string
+	Steps []WorkflowStep
+}
+
+// JobStore persists jobs
+type JobStore interface {
+	Save(job *Job) error
+	// Load returns ErrNotFound if the job does not exist
+	Load(id string) (*Job, error)
+	List() ([]*Job, error)
+	// Lock claims a job for a run and returns the function freeing it. It returns ErrJobLocked while
+	// another run holds the job.
+	Lock(id string) (unlock func(), err error)
+}
+
+// FileJobStore stores every job as JSON file <id>.json in a directory. A file is replaced atomically,
+// so a crash while saving leaves the previous state of the job. A run claims its job with an
+// exclusive lock of the file <id>.lock, which the operating system frees if the process dies.
+//
+// The jobs are plain files instead of a bolt or sqlite database: a CLI and a server have a few
+// jobs of a few steps each, the files need no further dependency or cgo and can be read and repaired
+// with an editor.
+type FileJobStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// DefaultJobDir returns the default job directory in the user config directory
+func DefaultJobDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "appapi", "jobs"), nil
+}
+
+// NewFileJobStore returns a store of the jobs in dir, the directory is created on the first save
+func NewFileJobStore(dir string) *FileJobStore {
+	return &FileJobStore{dir: dir}
+}
+
+// Save writes the job
+func (s *FileJobStore) Save(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("could not create job directory: %v", err)
+	}
+	path := s.path(job.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("could not write job %s: %v", job.ID, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load reads a job
+func (s *FileJobStore) Load(id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := validJobID(id); err != nil {
+		return nil, err
+	}
+	return s.load(s.path(id))
+}
+
+// Lock claims the job with the lock file of the job, see FileJobStore
+func (s *FileJobStore) Lock(id string) (unlock func(), err error) {
+	if err := validJobID(id); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create job directory: %v", err)
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, id+".lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the lock of job %s: %v", id, err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, ErrJobLocked) {
+			return nil, fmt.Errorf("job %s: %w", id, err)
+		}
+		return nil, fmt.Errorf("could not lock job %s: %v", id, err)
+	}
+	return sync.OnceFunc(func() {
+		unlockFile(f)
+		f.Close()
+	}), nil
+}
+
+func validJobID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid job id %q", id)
+	}
+	return nil
+}
+
+// List returns the jobs, oldest first
+func (s *FileJobStore) List() ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(files))
+	for _, file := range files {
+		job, err := s.load(file)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].Created.Before(jobs[j].Created) })
+	return jobs, nil
+}
+
+func (s *FileJobStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *FileJobStore) load(path string) (*Job, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("job %s: %w", strings.TrimSuffix(filepath.Base(path), ".json"), ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read job: %v", err)
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("job %s is corrupt: %v", filepath.Base(path), err)
+	}
+	return &job, nil
+}
+
+// JobRunner submits, runs and resumes the jobs of registered workflows
+type JobRunner struct {
+	Store JobStore
+
+	workflows map[string]Workflow
+}
+
+// NewJobRunner returns a runner of the workflows with the jobs in store
+func NewJobRunner(store JobStore, workflows ...Workflow) *JobRunner {
+	r := &JobRunner{Store: store, workflows: map[string]Workflow{}}
+	for _, w := range workflows {
+		r.Register(w)
+	}
+	return r
+}
+
+// Register adds a workflow, a workflow with the same name is replaced
+func (r *JobRunner) Register(w Workflow) {
+	r.workflows[w.Name] = w
+}
+
+// Submit saves a new pending job of the workflow, it is started by Run or Resume
+func (r *JobRunner) Submit(workflow string, params map[string]string) (*Job, error) {
+	w, ok := r.workflows[workflow]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownWorkflow, workflow)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	now := DefaultClock.Now()
+	job := &Job{
+		ID:       hex.EncodeToString(id),
+		Workflow: w.Name,
+		Status:   JobPending,
+		Params:   params,
+		State:    map[string]string{},
+		Created:  now,
+		Updated:  now,
+	}
+	for _, step := range w.Steps {
+		job.Steps = append(job.Steps, JobStep{Name: step.Name, Status: JobPending})
+	}
+	if err := r.Store.Save(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Get returns a job
+func (r *JobRunner) Get(id string) (*Job, error) {
+	return r.Store.Load(id)
+}
+
+// Jobs returns all jobs, oldest first
+func (r *JobRunner) Jobs() ([]*Job, error) {
+	return r.Store.List()
+}
+
+// Run runs a job from its first unfinished step. A failed job is retried from the failed step. If ctx
+// is done the job is saved as pending and can be resumed later. The job is claimed for the run, a job
+// run by another runner returns ErrJobLocked.
+func (r *JobRunner) Run(ctx context.Context, id string) (*Job, error) {
+	if _, err := r.Store.Load(id); err != nil {
+		return nil, err
+	}
+	unlock, err := r.Store.Lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// the job is read again, another runner may have run it before the lock
+	job, err := r.Store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == JobSucceeded {
+		return job, nil
+	}
+	w, ok := r.workflows[job.Workflow]
+	if !ok {
+		return job, fmt.Errorf("job %s: %w %q", job.ID, ErrUnknownWorkflow, job.Workflow)
+	}
+	if len(job.Steps) != len(w.Steps) {
+		return job, fmt.Errorf("job %s has %d steps, workflow %s has %d", job.ID, len(job.Steps), w.Name, len(w.Steps))
+	}
+	if job.State == nil {
+		job.State = map[string]string{}
+	}
+
+	job.Status, job.Error = JobRunning, ""
+	if err := r.save(job); err != nil {
+		return job, err
+	}
+
+	for i, step := range w.Steps {
+		if job.Steps[i].Status == JobSucceeded {
+			continue
+		}
+		job.Steps[i].Status, job.Steps[i].Error = JobRunning, ""
+		if err := r.save(job); err != nil {
+			return job, err
+		}
+
+		logInfof("job %s: running step %s", job.ID, step.Name)
+		err := step.Run(ctx, job)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			// interrupted, not failed
+			job.Steps[i].Status, job.Status = JobPending, JobPending
+			if serr := r.save(job); serr != nil {
+				return job, serr
+			}
+			return job, ctx.Err()
+		case err != nil:
+			job.Steps[i].Status, job.Steps[i].Error = JobFailed, err.Error()
+			job.Status, job.Error = JobFailed, fmt.Sprintf("step %s: %v", step.Name, err)
+			if serr := r.save(job); serr != nil {
+				return job, serr
+			}
+			return job, fmt.Errorf("job %s step %s: %w", job.ID, step.Name, err)
+		}
+
+		job.Steps[i].Status, job.Steps[i].Finished = JobSucceeded, DefaultClock.Now()
+		if err := r.save(job); err != nil {
+			return job, err
+		}
+	}
+
+	job.Status = JobSucceeded
+	return job, r.save(job)
+}
+
+// Resume runs the pending jobs and the jobs which were running when the process stopped, oldest
+// first. Failed jobs are not retried, run them with Run, and jobs run by another runner are skipped.
+// The errors of the jobs are returned joined.
+func (r *JobRunner) Resume(ctx context.Context) ([]*Job, error) {
+	jobs, err := r.Store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var resumed []*Job
+	var errs []error
+	for _, job := range jobs {
+		if job.Done() {
+			continue
+		}
+		job, err := r.Run(ctx, job.ID)
+		if errors.Is(err, ErrJobLocked) {
+			logInfof("%v, skipped", err)
+			continue
+		}
+		if job != nil {
+			resumed = append(resumed, job)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return resumed, errors.Join(errs...)
+}
+
+func (r *JobRunner) save(job *Job) error {
+	job.Updated = DefaultClock.Now()
+	return r.Store.Save(job)
+}
+
+// ProvisionWorkflowName is the name of the workflow returned by ProvisionWorkflow
+const ProvisionWorkflowName = "provision"
+
+// ProvisionConfig configures the provision workflow. It is not saved with the jobs, so the group
+// password is not written to disk.
+type ProvisionConfig struct {
+	// HostnameOutput is the output of the building block with the hostname, default "hostname"
+	HostnameOutput string
+	// Network is the permitted network of the system, defaults to the first network of the SumaClient
+	Network string
+	// GroupPassword is the password of a new SUMA user, if empty no user is created
+	GroupPassword string
+	// PollInterval polls the building block at a fixed interval instead of the Poll policy
+	PollInterval time.Duration
+	// Poll is the adaptive interval in which the building block is polled, DefaultPollPolicy without
+	// intervals, see PollPolicy
+	Poll PollPolicy
+}
+
+// ProvisionWorkflow returns the workflow which creates a VM building block, waits until it is
+// deployed, adds the VM to a SUMA system group and creates the user of the group. The job params are
+// "payload", the JSON payload of the building block, and "group", the SUMA system group and user.
+func ProvisionWorkflow(suma *SumaClient, ms *MsClient, cfg ProvisionConfig) Workflow {
+	if cfg.HostnameOutput == "" {
+		cfg.HostnameOutput = defaultHostnameOutput
+	}
+	if cfg.Network == "" && len(suma.Networks) > 0 {
+		cfg.Network = suma.Networks[0]
+	}
+	switch {
+	case cfg.PollInterval > 0:
+		cfg.Poll = FixedPollPolicy(cfg.PollInterval)
+	case cfg.Poll.Pending <= 0 && cfg.Poll.InProgress <= 0 && cfg.Poll.Max <= 0:
+		cfg.Poll = DefaultPollPolicy
+	}
+
+	return Workflow{Name: ProvisionWorkflowName, Steps: []WorkflowStep{
+		{Name: "create-building-block", Run: func(ctx context.Context, job *Job) error {
+			if job.State["buildingBlock"] != "" {
+				return nil
+			}
+			payload := job.Params["payload"]
+			if payload == "" {
+				return &ValidationError{Payload: "job", Field: "payload", Reason: "is required"}
+			}
+			uuid, err := ms.CreateBuildingBlock([]byte(payload))
+			if err != nil {
+				return err
+			}
+			job.State["buildingBlock"] = uuid
+			return nil
+		}},
+		{Name: "wait-building-block", Run: func(ctx context.Context, job *Job) error {
+			details, err := ms.WaitForBuildingBlock(ctx, job.State["buildingBlock"], cfg.Poll)
+			if err != nil {
+				return err
+			}
+			switch details.Status {
+			case buildingBlockSucceded:
+				hostname := strings.TrimSpace(details.Outputs[cfg.HostnameOutput])
+				if hostname == "" {
+					return fmt.Errorf("building block %s has no output %s", details.Name, cfg.HostnameOutput)
+				}
+				job.State["hostname"] = hostname
+				return nil
+			case RunAborted:
+				return fmt.Errorf("building block %s was aborted", details.Name)
+			}
+			return fmt.Errorf("building block %s failed", details.Name)
+		}},
+		{Name: "add-system", Run: func(ctx context.Context, job *Job) error {
+			_, err := suma.AddSystem(job.State["hostname"], job.Params["group"], cfg.Network)
+			return err
+		}},
+		{Name: "add-user", Run: func(ctx context.Context, job *Job) error {
+			group := job.Params["group"]
+			if cfg.GroupPassword == "" {
+				return nil
+			}
+			users, err := suma.Users()
+			if err != nil {
+				return err
+			}
+			if containsFold(users, group) {
+				return nil
+			}
+			_, err = suma.AddUser(group, cfg.GroupPassword)
+			return err
+		}},
+	}}
+}
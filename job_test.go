@@ -0,0 +1,145 @@
+package appapi
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestJobRunnerResume(t *testing.T) {
+	store := NewFileJobStore(t.TempDir())
+
+	var calls []string
+	fail := true
+	workflow := Workflow{Name: "test", Steps: []WorkflowStep{
+		{Name: "first", Run: func(ctx context.Context, job *Job) error {
+			calls = append(calls, "first")
+			job.State["first"] = job.Params["in"]
+			return nil
+		}},
+		{Name: "second", Run: func(ctx context.Context, job *Job) error {
+			calls = append(calls, "second")
+			if fail {
+				return errors.New("boom")
+			}
+			job.State["second"] = job.State["first"] + "!"
+			return nil
+		}},
+	}}
+
+	runner := NewJobRunner(store, workflow)
+	job, err := runner.Submit("test", map[string]string{"in": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job, err = runner.Run(context.Background(), job.ID)
+	if err == nil || job.Status != JobFailed || job.Current() != "second" {
+		t.Fatalf("job = %+v, err = %v, want failed at second", job, err)
+	}
+
+	// a failed job is not resumed
+	if resumed, err := runner.Resume(context.Background()); err != nil || len(resumed) != 0 {
+		t.Fatalf("Resume = %v, %v, want no jobs", resumed, err)
+	}
+
+	// a new runner with the same store retries the failed step only
+	fail = false
+	job, err = NewJobRunner(NewFileJobStore(store.dir), workflow).Run(context.Background(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != JobSucceeded || job.State["second"] != "x!" {
+		t.Errorf("job = %+v, want succeeded with second = x!", job)
+	}
+	if want := []string{"first", "second", "second"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	jobs, err := runner.Jobs()
+	if err != nil || len(jobs) != 1 || jobs[0].Status != JobSucceeded {
+		t.Errorf("Jobs = %v, %v", jobs, err)
+	}
+}
+
+func TestJobRunnerInterrupted(t *testing.T) {
+	store := NewFileJobStore(t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	workflow := Workflow{Name: "wait", Steps: []WorkflowStep{
+		{Name: "wait", Run: func(ctx context.Context, job *Job) error {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	}}
+	runner := NewJobRunner(store, workflow)
+	job, err := runner.Submit("wait", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runner.Run(ctx, job.ID); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	job, err = runner.Get(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != JobPending || job.Steps[0].Status != JobPending {
+		t.Errorf("job = %+v, want pending to be resumed", job)
+	}
+
+	workflow.Steps[0].Run = func(ctx context.Context, job *Job) error { return nil }
+	runner.Register(workflow)
+	resumed, err := runner.Resume(context.Background())
+	if err != nil || len(resumed) != 1 || resumed[0].Status != JobSucceeded {
+		t.Errorf("Resume = %v, %v, want the job succeeded", resumed, err)
+	}
+
+	if _, err := runner.Submit("unknown", nil); !errors.Is(err, ErrUnknownWorkflow) {
+		t.Errorf("err = %v, want ErrUnknownWorkflow", err)
+	}
+	if _, err := runner.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestJobRunnerLocked(t *testing.T) {
+	store := NewFileJobStore(t.TempDir())
+	runs := 0
+	workflow := Workflow{Name: "test", Steps: []WorkflowStep{
+		{Name: "step", Run: func(ctx context.Context, job *Job) error {
+			runs++
+			return nil
+		}},
+	}}
+	runner := NewJobRunner(store, workflow)
+	job, err := runner.Submit("test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the lock of another runner, the lock file is locked by its own open file like in another process
+	unlock, err := NewFileJobStore(store.dir).Lock(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Lock(job.ID); !errors.Is(err, ErrJobLocked) {
+		t.Errorf("Lock() of a locked job error = %v, want ErrJobLocked", err)
+	}
+	if _, err := runner.Run(context.Background(), job.ID); !errors.Is(err, ErrJobLocked) {
+		t.Errorf("Run() of a locked job error = %v, want ErrJobLocked", err)
+	}
+	if resumed, err := runner.Resume(context.Background()); err != nil || len(resumed) != 0 {
+		t.Errorf("Resume() = %v, %v, want the locked job skipped", resumed, err)
+	}
+	if runs != 0 {
+		t.Errorf("the locked job ran %d times", runs)
+	}
+
+	unlock()
+	if job, err := runner.Run(context.Background(), job.ID); err != nil || job.Status != JobSucceeded || runs != 1 {
+		t.Errorf("Run() after the unlock = %+v, %v, want the job run once", job, err)
+	}
+}
@@ -0,0 +1,158 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringService is the service name under which appapi stores its secrets in the OS keyring
+// (Secret Service on Linux, Keychain on macOS, Credential Manager on Windows).
+const KeyringService = "appapi"
+
+// KeyringSessionService is the service name of the cached sessions. It is separate from KeyringService,
+// so that a session name can not collide with the entries of a profile.
+const KeyringSessionService = "appapi-session"
+
+// keyring entries of a profile
+const (
+	keyringSumaUsername   = "suma_username"
+	keyringSumaPassword   = "suma_password"
+	keyringSumaToken      = "suma_token"
+	keyringMsClientID     = "meshstack_client_id"
+	keyringMsClientSecret = "meshstack_client_secret"
+)
+
+func keyringKey(profile, name string) string {
+	if profile == "" {
+		profile = "default"
+	}
+	return fmt.Sprintf("%s/%s", profile, name)
+}
+
+// KeyringStoreCredentials saves the credentials of a profile in the OS keyring. Empty values are not stored.
+func KeyringStoreCredentials(profile string, creds Credentials) error {
+	values := map[string]string{
+		keyringSumaUsername:   creds.SumaUsername,
+		keyringSumaPassword:   creds.SumaPassword,
+		keyringSumaToken:      creds.SumaToken,
+		keyringMsClientID:     creds.MsClientID,
+		keyringMsClientSecret: creds.MsClientSecret,
+	}
+
+	for name, value := range values {
+		if value == "" {
+			continue
+		}
+		if err := keyring.Set(KeyringService, keyringKey(profile, name), value); err != nil {
+			return fmt.Errorf("failed to store %s in keyring: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// KeyringDeleteCredentials removes the credentials of a profile from the OS keyring.
+func KeyringDeleteCredentials(profile string) error {
+	for _, name := range []string{keyringSumaUsername, keyringSumaPassword, keyringSumaToken, keyringMsClientID, keyringMsClientSecret} {
+		err := keyring.Delete(KeyringService, keyringKey(profile, name))
+		if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return fmt.Errorf("failed to delete %s from keyring: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// keyringGet returns a keyring value, a missing entry is returned as empty string
+func keyringGet(profile, name string) (string, error) {
+	value, err := keyring.Get(KeyringService, keyringKey(profile, name))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from keyring: %v", name, err)
+	}
+	return value, nil
+}
+
+// KeyringCredentialProvider reads the credentials of a profile from the OS keyring.
+type KeyringCredentialProvider struct {
+	cachedCredentials
+}
+
+// NewKeyringCredentialProvider creates a provider for credentials in the OS keyring
+func NewKeyringCredentialProvider(profile string) *KeyringCredentialProvider {
+	p := &KeyringCredentialProvider{}
+	p.load = func() (creds Credentials, err error) {
+		if creds.SumaUsername, err = keyringGet(profile, keyringSumaUsername); err != nil {
+			return creds, err
+		}
+		if creds.SumaPassword, err = keyringGet(profile, keyringSumaPassword); err != nil {
+			return creds, err
+		}
+		if creds.SumaToken, err = keyringGet(profile, keyringSumaToken); err != nil {
+			return creds, err
+		}
+		if creds.MsClientID, err = keyringGet(profile, keyringMsClientID); err != nil {
+			return creds, err
+		}
+		if creds.MsClientSecret, err = keyringGet(profile, keyringMsClientSecret); err != nil {
+			return creds, err
+		}
+		return creds, nil
+	}
+	return p
+}
+
+// keyringSession is the stored form of a cached session cookie or token
+type keyringSession struct {
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// KeyringSaveSession stores a session cookie or access token with its expiry in the OS keyring,
+// e.g. KeyringSaveSession("suma@https://suma.example.com", cookie, time.Now().Add(time.Hour)).
+func KeyringSaveSession(name, value string, expires time.Time) error {
+	data, err := json.Marshal(keyringSession{Value: value, Expires: expires})
+	if err != nil {
+		return err
+	}
+	if err := keyring.Set(KeyringSessionService, name, string(data)); err != nil {
+		return fmt.Errorf("failed to store session %s in keyring: %v", name, err)
+	}
+	return nil
+}
+
+// KeyringLoadSession returns a session stored with KeyringSaveSession. ok is false if no session
+// is stored or the session is expired. Expired sessions are removed from the keyring.
+func KeyringLoadSession(name string) (value string, ok bool, err error) {
+	data, err := keyring.Get(KeyringSessionService, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read session %s from keyring: %v", name, err)
+	}
+
+	var s keyringSession
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return "", false, fmt.Errorf("invalid session %s in keyring: %v", name, err)
+	}
+
+	if !DefaultClock.Now().Before(s.Expires) {
+		return "", false, KeyringDeleteSession(name)
+	}
+
+	return s.Value, true, nil
+}
+
+// KeyringDeleteSession removes a cached session from the OS keyring.
+func KeyringDeleteSession(name string) error {
+	err := keyring.Delete(KeyringSessionService, name)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete session %s from keyring: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package appapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyringCredentialProvider(t *testing.T) {
+	keyring.MockInit()
+
+	want := Credentials{SumaUsername: "admin", SumaPassword: "secret", MsClientID: "client", MsClientSecret: "client-secret"}
+	if err := KeyringStoreCredentials("prod", want); err != nil {
+		t.Fatalf("KeyringStoreCredentials returned error: %v", err)
+	}
+
+	got, err := NewKeyringCredentialProvider("prod").Get()
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+
+	if err := KeyringDeleteCredentials("prod"); err != nil {
+		t.Fatalf("KeyringDeleteCredentials returned error: %v", err)
+	}
+	got, _ = NewKeyringCredentialProvider("prod").Get()
+	if got != (Credentials{}) {
+		t.Errorf("expected empty credentials after delete, got %+v", got)
+	}
+}
+
+func TestKeyringSession(t *testing.T) {
+	keyring.MockInit()
+
+	if err := KeyringSaveSession("suma", "cookie", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("KeyringSaveSession returned error: %v", err)
+	}
+	value, ok, err := KeyringLoadSession("suma")
+	if err != nil || !ok || value != "cookie" {
+		t.Errorf("KeyringLoadSession() = %q, %v, %v; want cookie, true, nil", value, ok, err)
+	}
+
+	if err := KeyringSaveSession("expired", "cookie", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("KeyringSaveSession returned error: %v", err)
+	}
+	_, ok, err = KeyringLoadSession("expired")
+	if err != nil || ok {
+		t.Errorf("expected expired session to be ignored, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestKeyringSessionProfileCollision(t *testing.T) {
+	keyring.MockInit()
+
+	want := Credentials{SumaUsername: "admin"}
+	if err := KeyringStoreCredentials("session", want); err != nil {
+		t.Fatalf("KeyringStoreCredentials returned error: %v", err)
+	}
+	if err := KeyringSaveSession(keyringSumaUsername, "cookie", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("KeyringSaveSession returned error: %v", err)
+	}
+
+	got, err := NewKeyringCredentialProvider("session").Get()
+	if err != nil || got != want {
+		t.Errorf("Get() = %+v, %v; want %+v, nil", got, err, want)
+	}
+
+	if err := KeyringDeleteCredentials("session"); err != nil {
+		t.Fatalf("KeyringDeleteCredentials returned error: %v", err)
+	}
+	value, ok, err := KeyringLoadSession(keyringSumaUsername)
+	if err != nil || !ok || value != "cookie" {
+		t.Errorf("KeyringLoadSession() = %q, %v, %v; want cookie, true, nil", value, ok, err)
+	}
+}
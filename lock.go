@@ -0,0 +1,76 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ErrSystemLocked is returned when a system is locked in SUSE Manager and must not be changed
+var ErrSystemLocked = errors.New("system is locked")
+
+// SystemLockedError is returned by the delete and patch flows for a locked system, it matches
+// ErrSystemLocked
+type SystemLockedError struct {
+	SystemID int
+	Hostname string
+}
+
+func (e *SystemLockedError) Error() string {
+	return fmt.Sprintf("%s (system ID %d) is locked in SUSE Manager, unlock it first", e.Hostname, e.SystemID)
+}
+
+func (e *SystemLockedError) Is(target error) bool {
+	return target == ErrSystemLocked
+}
+
+// SumaSetLockStatus locks or unlocks a system. A locked system does not run scheduled actions and is
+// not deleted or patched by appapi.
+func SumaSetLockStatus(sessioncookie, susemgr string, systemID int, locked, verbose bool, opts ...Option) (err error) {
+	if verbose {
+		Logger().Debug("SUMAAPI SumaSetLockStatus: set lock status", "system_id", systemID, "locked", locked)
+	}
+	payload := map[string]any{"sid": systemID, "lockStatus": locked}
+	return sumaPost(sessioncookie, susemgr, "system/setLockStatus", payload, nil, verbose, opts...)
+}
+
+// SumaGetLockStatus reports if a system is locked
+func SumaGetLockStatus(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (locked bool, err error) {
+	return sumaGetLockStatus(sessioncookie, susemgr, systemID, verbose, opts...)
+}
+
+func sumaGetLockStatus(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (locked bool, err error) {
+	var details struct {
+		LockStatus bool `json:"lock_status"`
+	}
+	query := url.Values{"sid": {strconv.Itoa(systemID)}}
+	if err := sumaGet(sessioncookie, susemgr, "system/getDetails", query, &details, verbose, opts...); err != nil {
+		return false, err
+	}
+	return details.LockStatus, nil
+}
+
+// sumaRequireUnlocked returns a SystemLockedError if the system is locked
+func sumaRequireUnlocked(api sumaAPI, sessioncookie, susemgr string, systemID int, hostname string, verbose bool, opts ...Option) error {
+	locked, err := api.GetLockStatus(sessioncookie, susemgr, systemID, verbose, opts...)
+	if err != nil {
+		return fmt.Errorf("could not get the lock status of %s: %w", hostname, err)
+	}
+	if locked {
+		return &SystemLockedError{SystemID: systemID, Hostname: hostname}
+	}
+	return nil
+}
+
+// SetLockStatus locks or unlocks a system, see SumaSetLockStatus
+func (c *SumaClient) SetLockStatus(systemID int, locked bool, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return SumaSetLockStatus(c.SessionCookie(), c.URL, systemID, locked, verbose, opts...)
+}
+
+// LockStatus reports if a system is locked, see SumaGetLockStatus
+func (c *SumaClient) LockStatus(systemID int, opts ...Option) (bool, error) {
+	verbose, opts := c.options(opts)
+	return c.suma().GetLockStatus(c.SessionCookie(), c.URL, systemID, verbose, opts...)
+}
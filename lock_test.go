@@ -0,0 +1,54 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSumaSetLockStatus(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/system/setLockStatus" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		fmt.Fprint(w, `{"success": true, "result": 1}`)
+	}))
+	defer server.Close()
+
+	if err := SumaSetLockStatus("cookie", server.URL, 42, true, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]any{"sid": 42.0, "lockStatus": true}; !reflect.DeepEqual(payload, want) {
+		t.Errorf("got payload %v, want %v", payload, want)
+	}
+}
+
+func TestSumaDeleteSystem_Locked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/system/getDetails" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("sid") != "42" {
+			t.Errorf("sid = %s, want 42", r.URL.Query().Get("sid"))
+		}
+		fmt.Fprint(w, `{"success": true, "result": {"id": 42, "lock_status": true}}`)
+	}))
+	defer server.Close()
+
+	// the lock status is read from the server
+	api := fakeSystem("192.168.1.10")
+	api.lockStatus = nil
+	_, err := sumaDeleteSystemResult(api, "cookie", server.URL, "host", "192.168.1.0/24", false)
+	var lerr *SystemLockedError
+	if !errors.Is(err, ErrSystemLocked) || !errors.As(err, &lerr) || lerr.SystemID != 42 {
+		t.Errorf("expected SystemLockedError, got %v", err)
+	}
+}
@@ -0,0 +1,480 @@
+// Package meshtest provides a fake Meshstack API for integration tests of applications using appapi,
+// like net/http/httptest does for HTTP handlers. The server keeps a dataset of projects, tenants and
+// building blocks in memory and implements the endpoints appapi calls with the HAL responses of
+// Meshstack, so the tests need no hand-written handlers:
+//
+//	srv := meshtest.NewServer(meshtest.Dataset{
+//		CreatedOutputs: func(b meshtest.BuildingBlock) map[string]string {
+//			return map[string]string{"hostname": b.Name + ".example.com"}
+//		},
+//	})
+//	defer srv.Close()
+//
+//	ms, err := srv.NewClient()
+//	uuid, err := ms.CreateBuildingBlock(payload)
+//	details, err := ms.GetBuildingBlockDetails(uuid) // Status SUCCEEDED, the hostname as output
+package meshtest
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/arzieg/appapi"
+)
+
+// The defaults of a Dataset
+const (
+	// DefaultClientID and DefaultClientSecret are the API key of a dataset without one
+	DefaultClientID     = "appapi-test"
+	DefaultClientSecret = "secret"
+	// DefaultVersion is the version reported by api/health
+	DefaultVersion = "2025.10.0"
+	// DefaultCreatedStatus is the status of created building blocks
+	DefaultCreatedStatus = appapi.RunSucceeded
+)
+
+// BuildingBlock is a building block of a project
+type BuildingBlock struct {
+	appapi.BuildingBlockDetails
+	// ProjectID is the project identifier the building block is listed with
+	ProjectID string
+	// TenantIdentifier is the tenant of the building block, workspace.project.platform
+	TenantIdentifier string
+}
+
+// Dataset is the initial content of a Server
+type Dataset struct {
+	// ClientID and ClientSecret are the API key accepted by api/login, DefaultClientID and
+	// DefaultClientSecret if empty
+	ClientID     string
+	ClientSecret string
+	// Version is the version reported by api/health, DefaultVersion if empty
+	Version        string
+	Projects       []appapi.Project
+	Tenants        []appapi.Tenant
+	BuildingBlocks []BuildingBlock
+	// CreatedStatus is the status of created building blocks, DefaultCreatedStatus if empty. Use
+	// SetStatus to finish a building block created IN_PROGRESS.
+	CreatedStatus string
+	// CreatedOutputs returns the outputs of a created building block, e.g. the hostname of a VM
+	CreatedOutputs func(BuildingBlock) map[string]string
+	// PlainStatus returns the status of building blocks as plain string like older Meshstack
+	// versions, without the outputs
+	PlainStatus bool
+}
+
+// Server is a fake Meshstack serving the API under /api. Every endpoint except api/login and
+// api/health requires the bearer token of a login.
+type Server struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	data           Dataset
+	projects       []appapi.Project
+	tenants        []appapi.Tenant
+	buildingBlocks map[string]*BuildingBlock
+	tokens         map[string]bool
+	failures       map[string]int
+	requests       []string
+}
+
+// NewServer starts a fake Meshstack with a copy of the dataset. The caller closes it with Close.
+func NewServer(data Dataset) *Server {
+	if data.ClientID == "" {
+		data.ClientID, data.ClientSecret = DefaultClientID, DefaultClientSecret
+	}
+	if data.Version == "" {
+		data.Version = DefaultVersion
+	}
+	if data.CreatedStatus == "" {
+		data.CreatedStatus = DefaultCreatedStatus
+	}
+	s := &Server{
+		data:           data,
+		projects:       slices.Clone(data.Projects),
+		tenants:        slices.Clone(data.Tenants),
+		buildingBlocks: map[string]*BuildingBlock{},
+		tokens:         map[string]bool{},
+		failures:       map[string]int{},
+	}
+	for _, b := range data.BuildingBlocks {
+		s.addBuildingBlock(b)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/login", s.login)
+	mux.HandleFunc("GET /api/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, appapi.MsHealth{Status: "UP", Version: data.Version})
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblocks", s.auth(s.listBuildingBlocks))
+	mux.HandleFunc("POST /api/meshobjects/meshbuildingblocks", s.auth(s.createBuildingBlock))
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblocks/{uuid}", s.auth(s.getBuildingBlock))
+	mux.HandleFunc("DELETE /api/meshobjects/meshbuildingblocks/{uuid}", s.auth(s.deleteBuildingBlock))
+	mux.HandleFunc("GET /api/meshobjects/meshprojects", s.auth(s.listProjects))
+	mux.HandleFunc("GET /api/meshobjects/meshprojects/{id}", s.auth(s.getProject))
+	mux.HandleFunc("DELETE /api/meshobjects/meshprojects/{id}", s.auth(s.deleteProject))
+	mux.HandleFunc("GET /api/meshobjects/meshtenants", s.auth(s.listTenants))
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Credentials returns the API key of the dataset
+func (s *Server) Credentials() appapi.Credentials {
+	return appapi.Credentials{MsClientID: s.data.ClientID, MsClientSecret: s.data.ClientSecret}
+}
+
+// NewClient logs in with Credentials. The server serves plain HTTP, so the client is created with
+// appapi.WithInsecure.
+func (s *Server) NewClient(opts ...appapi.Option) (*appapi.MsClient, error) {
+	opts = append([]appapi.Option{appapi.WithInsecure(true)}, opts...)
+	return appapi.NewMsClient(s.URL, appapi.NewStaticCredentialProvider(s.Credentials()), opts...)
+}
+
+// AddBuildingBlock adds a building block, an empty UUID is generated. It returns the UUID.
+func (s *Server) AddBuildingBlock(b BuildingBlock) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addBuildingBlock(b)
+}
+
+func (s *Server) addBuildingBlock(b BuildingBlock) string {
+	if b.UUID == "" {
+		b.UUID = newUUID()
+	}
+	b.Inputs, b.Outputs = cloneMap(b.Inputs), cloneMap(b.Outputs)
+	s.buildingBlocks[b.UUID] = &b
+	return b.UUID
+}
+
+// BuildingBlocks returns the building blocks sorted by name
+func (s *Server) BuildingBlocks() []BuildingBlock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var bbs []BuildingBlock
+	for _, b := range s.buildingBlocks {
+		c := *b
+		c.Inputs, c.Outputs = cloneMap(b.Inputs), cloneMap(b.Outputs)
+		bbs = append(bbs, c)
+	}
+	sort.Slice(bbs, func(i, j int) bool { return bbs[i].Name < bbs[j].Name })
+	return bbs
+}
+
+// SetStatus sets the status and, if not nil, the outputs of a building block, e.g. to finish a
+// deployment. It reports if the building block exists.
+func (s *Server) SetStatus(uuid, status string, outputs map[string]string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.buildingBlocks[uuid]
+	if b == nil {
+		return false
+	}
+	b.Status = status
+	if outputs != nil {
+		b.Outputs = cloneMap(outputs)
+	}
+	return true
+}
+
+// Projects returns the projects
+func (s *Server) Projects() []appapi.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.projects)
+}
+
+// Requests returns the requests in order as method and path, e.g. "POST /api/meshobjects/meshbuildingblocks"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Fail makes every request of the method and path pattern, e.g. "GET /api/meshobjects/meshprojects",
+// fail with the HTTP status code. A zero status code removes the failure.
+func (s *Server) Fail(pattern string, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if statusCode == 0 {
+		delete(s.failures, pattern)
+		return
+	}
+	s.failures[pattern] = statusCode
+}
+
+// handlerFunc handles an authenticated request, it is called with the lock held
+type handlerFunc func(w http.ResponseWriter, r *http.Request)
+
+// auth checks the bearer token, records the request and injects the failures of Fail
+func (s *Server) auth(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !s.tokens[token] {
+			writeError(w, http.StatusUnauthorized, "invalid access token")
+			return
+		}
+		if code := s.failures[r.Pattern]; code != 0 {
+			writeError(w, code, http.StatusText(code))
+			return
+		}
+		h(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]any{"status": code, "message": message})
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return maps.Clone(m)
+}
+
+// writePage writes a page of a HAL collection, the page and size are taken from the query. Without
+// a size all items are written, MsListBuildingBlocks does not page.
+func writePage[T any](w http.ResponseWriter, r *http.Request, collection string, items []T) {
+	size, err := strconv.Atoi(r.URL.Query().Get("size"))
+	if err != nil || size <= 0 {
+		size = max(len(items), 1)
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	totalPages := (len(items) + size - 1) / size
+	start := min(page*size, len(items))
+	end := min(start+size, len(items))
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"_embedded": map[string]any{collection: items[start:end]},
+		"page":      map[string]int{"size": size, "totalElements": len(items), "totalPages": totalPages, "number": page},
+	})
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if r.PostForm.Get("client_id") != s.data.ClientID || r.PostForm.Get("client_secret") != s.data.ClientSecret {
+		writeError(w, http.StatusUnauthorized, "invalid client credentials")
+		return
+	}
+
+	token := newUUID()
+	s.mu.Lock()
+	s.tokens[token] = true
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"access_token": token, "expires_in": 3600, "token_type": "bearer"})
+}
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+func keyValues(m map[string]string) []keyValue {
+	kvs := []keyValue{}
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		kvs = append(kvs, keyValue{Key: k, Value: m[k]})
+	}
+	return kvs
+}
+
+func (s *Server) buildingBlockJSON(b *BuildingBlock) map[string]any {
+	var status any = map[string]any{"status": b.Status, "outputs": keyValues(b.Outputs)}
+	if s.data.PlainStatus {
+		status = b.Status
+	}
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "meshBuildingBlock",
+		"metadata": map[string]any{
+			"uuid":             b.UUID,
+			"definitionUuid":   b.DefinitionUUID,
+			"tenantIdentifier": b.TenantIdentifier,
+		},
+		"spec":   map[string]any{"displayName": b.Name, "inputs": keyValues(b.Inputs)},
+		"status": status,
+	}
+}
+
+func (s *Server) listBuildingBlocks(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("projectIdentifier")
+	var items []map[string]any
+	for _, b := range s.sortedBuildingBlocks() {
+		if project == "" || b.ProjectID == project {
+			items = append(items, s.buildingBlockJSON(b))
+		}
+	}
+	if items == nil {
+		items = []map[string]any{}
+	}
+	writePage(w, r, "meshBuildingBlocks", items)
+}
+
+func (s *Server) sortedBuildingBlocks() []*BuildingBlock {
+	bbs := make([]*BuildingBlock, 0, len(s.buildingBlocks))
+	for _, b := range s.buildingBlocks {
+		bbs = append(bbs, b)
+	}
+	sort.Slice(bbs, func(i, j int) bool {
+		return bbs[i].Name < bbs[j].Name || bbs[i].Name == bbs[j].Name && bbs[i].UUID < bbs[j].UUID
+	})
+	return bbs
+}
+
+func (s *Server) createBuildingBlock(w http.ResponseWriter, r *http.Request) {
+	var payload appapi.MsApiCreateBuildingBlock
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if payload.Spec.DisplayName == "" || payload.Metadata.DefinitionUUID == "" {
+		writeError(w, http.StatusBadRequest, "displayName and definitionUuid are required")
+		return
+	}
+
+	b := BuildingBlock{
+		BuildingBlockDetails: appapi.BuildingBlockDetails{
+			UUID:           newUUID(),
+			Name:           payload.Spec.DisplayName,
+			DefinitionUUID: payload.Metadata.DefinitionUUID,
+			Status:         s.data.CreatedStatus,
+			Inputs:         map[string]string{},
+		},
+		TenantIdentifier: payload.Metadata.TenantIdentifier,
+	}
+	// the tenant identifier is workspace.project.platform
+	if parts := strings.Split(payload.Metadata.TenantIdentifier, "."); len(parts) > 1 {
+		b.ProjectID = parts[1]
+	}
+	for _, input := range payload.Spec.Inputs {
+		b.Inputs[input.Key] = fmt.Sprint(input.Value)
+	}
+	if s.data.CreatedOutputs != nil {
+		b.Outputs = s.data.CreatedOutputs(b)
+	}
+	s.addBuildingBlock(b)
+	writeJSON(w, http.StatusCreated, s.buildingBlockJSON(s.buildingBlocks[b.UUID]))
+}
+
+func (s *Server) getBuildingBlock(w http.ResponseWriter, r *http.Request) {
+	b := s.buildingBlocks[r.PathValue("uuid")]
+	if b == nil {
+		writeError(w, http.StatusNotFound, "building block not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.buildingBlockJSON(b))
+}
+
+func (s *Server) deleteBuildingBlock(w http.ResponseWriter, r *http.Request) {
+	if s.buildingBlocks[r.PathValue("uuid")] == nil {
+		writeError(w, http.StatusNotFound, "building block not found")
+		return
+	}
+	delete(s.buildingBlocks, r.PathValue("uuid"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func projectJSON(p appapi.Project) map[string]any {
+	tags := p.Tags
+	if tags == nil {
+		tags = map[string][]string{}
+	}
+	return map[string]any{
+		"apiVersion": "v2",
+		"kind":       "meshProject",
+		"metadata":   map[string]any{"name": p.Identifier, "ownedByWorkspace": p.Workspace, "tags": tags},
+		"spec":       map[string]any{"displayName": p.DisplayName},
+	}
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	workspace := r.URL.Query().Get("workspaceIdentifier")
+	items := []map[string]any{}
+	for _, p := range s.projects {
+		if workspace == "" || p.Workspace == workspace {
+			items = append(items, projectJSON(p))
+		}
+	}
+	writePage(w, r, "meshProjects", items)
+}
+
+// project returns the index of the project with the identifier workspace.project, -1 if there is none
+func (s *Server) project(id string) int {
+	return slices.IndexFunc(s.projects, func(p appapi.Project) bool { return p.Workspace+"."+p.Identifier == id })
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	i := s.project(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, projectJSON(s.projects[i]))
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request) {
+	i := s.project(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+	p := s.projects[i]
+	if slices.ContainsFunc(s.tenants, func(t appapi.Tenant) bool { return t.Workspace == p.Workspace && t.Project == p.Identifier }) {
+		writeError(w, http.StatusConflict, "the project still has tenants")
+		return
+	}
+	s.projects = slices.Delete(s.projects, i, i+1)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) listTenants(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	items := []map[string]any{}
+	for _, t := range s.tenants {
+		if v := q.Get("workspaceIdentifier"); v != "" && t.Workspace != v {
+			continue
+		}
+		if v := q.Get("projectIdentifier"); v != "" && t.Project != v {
+			continue
+		}
+		if v := q.Get("platformIdentifier"); v != "" && t.Platform != v {
+			continue
+		}
+		items = append(items, map[string]any{
+			"apiVersion": "v4",
+			"kind":       "meshTenant",
+			"metadata":   map[string]any{"ownedByWorkspace": t.Workspace, "ownedByProject": t.Project, "platformIdentifier": t.Platform},
+			"spec":       map[string]any{"localId": t.LocalID},
+		})
+	}
+	writePage(w, r, "meshTenants", items)
+}
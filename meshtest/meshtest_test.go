@@ -0,0 +1,167 @@
+package meshtest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/arzieg/appapi"
+)
+
+func newClient(t *testing.T, s *Server) *appapi.MsClient {
+	t.Helper()
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestServerBuildingBlocks(t *testing.T) {
+	s := NewServer(Dataset{
+		CreatedStatus: appapi.RunInProgress,
+		CreatedOutputs: func(b BuildingBlock) map[string]string {
+			return map[string]string{"hostname": b.Name + ".example.com"}
+		},
+	})
+	defer s.Close()
+	c := newClient(t, s)
+
+	if c.Health.Version != DefaultVersion {
+		t.Errorf("version = %q, want %q", c.Health.Version, DefaultVersion)
+	}
+
+	payload, err := appapi.NewMsApiCreateBuildingBlock("0b5e8c1a-4f2d-4c3e-9a7b-1d2e3f4a5b6c", 1, "ws.proj.openstack", "vm1", map[string]any{"flavor": "small", "disk": 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := payload.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uuid, err := c.CreateBuildingBlock(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bbs, err := c.ListBuildingBlocks("proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bbs) != 1 || bbs[0].UUID != uuid || bbs[0].Name != "vm1" {
+		t.Errorf("building blocks = %+v", bbs)
+	}
+
+	details, err := c.GetBuildingBlockDetails(uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.Status != appapi.RunInProgress || details.Inputs["disk"] != "20" || details.Outputs["hostname"] != "vm1.example.com" {
+		t.Errorf("details = %+v", details)
+	}
+
+	if !s.SetStatus(uuid, appapi.RunSucceeded, nil) {
+		t.Fatal("the building block does not exist")
+	}
+	if details, err = c.GetBuildingBlockDetails(uuid); err != nil || details.Status != appapi.RunSucceeded {
+		t.Errorf("status = %q, err = %v after SetStatus", details.Status, err)
+	}
+
+	if err := c.DeleteBuildingBlock(uuid); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.BuildingBlocks()) != 0 {
+		t.Errorf("building blocks after delete = %+v", s.BuildingBlocks())
+	}
+	if _, err := c.GetBuildingBlockDetails(uuid); err == nil {
+		t.Error("a deleted building block must not be found")
+	}
+}
+
+func TestServerPlainStatus(t *testing.T) {
+	s := NewServer(Dataset{
+		PlainStatus:    true,
+		BuildingBlocks: []BuildingBlock{{BuildingBlockDetails: appapi.BuildingBlockDetails{UUID: "bb-1", Name: "vm1", Status: appapi.RunFailed}}},
+	})
+	defer s.Close()
+	c := newClient(t, s)
+
+	status, err := c.GetBuildingBlock("bb-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != appapi.RunFailed {
+		t.Errorf("status = %q, want %q", status, appapi.RunFailed)
+	}
+}
+
+func TestServerProjects(t *testing.T) {
+	projects := make([]appapi.Project, 150)
+	for i := range projects {
+		projects[i] = appapi.Project{Workspace: "ws", Identifier: "p" + string(rune('a'+i%26)) + string(rune('a'+i/26))}
+	}
+	s := NewServer(Dataset{
+		Projects: append(projects, appapi.Project{Workspace: "other", Identifier: "x"}),
+		Tenants:  []appapi.Tenant{{Workspace: "ws", Project: "paa", Platform: "openstack", LocalID: "42"}},
+	})
+	defer s.Close()
+	c := newClient(t, s)
+
+	// more projects than fit on a page of msEachPage
+	list, err := c.Projects("ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 150 {
+		t.Errorf("got %d projects, want 150", len(list))
+	}
+
+	tenants, err := c.Tenants("ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tenants) != 1 || tenants[0].LocalID != "42" {
+		t.Errorf("tenants = %+v", tenants)
+	}
+
+	if err := c.DeleteProject("ws", "paa"); err == nil {
+		t.Error("a project with tenants must not be deleted")
+	}
+	if err := c.DeleteProject("ws", "pba"); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Projects()) != 150 {
+		t.Errorf("got %d projects after delete, want 150", len(s.Projects()))
+	}
+	if err := c.DeleteProject("ws", "pba"); !errors.Is(err, appapi.ErrNotFound) {
+		t.Errorf("deleting a missing project: err = %v, want ErrNotFound", err)
+	}
+
+	s.Fail("GET /api/meshobjects/meshprojects", http.StatusBadGateway)
+	if _, err := c.Projects("ws"); err == nil {
+		t.Error("the injected failure was not returned")
+	}
+	s.Fail("GET /api/meshobjects/meshprojects", 0)
+	if _, err := c.Projects("ws"); err != nil {
+		t.Errorf("the failure was not removed: %v", err)
+	}
+}
+
+func TestServerLogin(t *testing.T) {
+	s := NewServer(Dataset{ClientID: "id", ClientSecret: "right"})
+	defer s.Close()
+
+	creds := appapi.NewStaticCredentialProvider(appapi.Credentials{MsClientID: "id", MsClientSecret: "wrong"})
+	if _, err := appapi.NewMsClient(s.URL, creds, appapi.WithInsecure(true)); err == nil {
+		t.Error("login with a wrong secret must fail")
+	}
+
+	resp, err := http.Get(s.URL + "/api/meshobjects/meshprojects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", resp.StatusCode)
+	}
+}
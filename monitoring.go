@@ -0,0 +1,70 @@
+package appapi
+
+import "sort"
+
+// MonitoringStatus maps the Prometheus exporters of SUSE Manager, e.g. tomcat or postgres, to their
+// state
+type MonitoringStatus map[string]bool
+
+// Enabled reports if all exporters are enabled
+func (s MonitoringStatus) Enabled() bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, enabled := range s {
+		if !enabled {
+			return false
+		}
+	}
+	return true
+}
+
+// Disabled returns the sorted names of the disabled exporters
+func (s MonitoringStatus) Disabled() []string {
+	var disabled []string
+	for name, enabled := range s {
+		if !enabled {
+			disabled = append(disabled, name)
+		}
+	}
+	sort.Strings(disabled)
+	return disabled
+}
+
+// SumaEnableMonitoring enables the Prometheus exporters of the SUSE Manager server and returns their
+// state. The exporters are started after a restart of the services.
+func SumaEnableMonitoring(sessioncookie, susemgr string, verbose bool, opts ...Option) (status MonitoringStatus, err error) {
+	err = sumaPost(sessioncookie, susemgr, "admin/monitoring/enable", nil, &status, verbose, opts...)
+	return status, err
+}
+
+// SumaDisableMonitoring disables the Prometheus exporters of the SUSE Manager server and returns their
+// state
+func SumaDisableMonitoring(sessioncookie, susemgr string, verbose bool, opts ...Option) (status MonitoringStatus, err error) {
+	err = sumaPost(sessioncookie, susemgr, "admin/monitoring/disable", nil, &status, verbose, opts...)
+	return status, err
+}
+
+// SumaGetMonitoringStatus returns the state of the Prometheus exporters of the SUSE Manager server
+func SumaGetMonitoringStatus(sessioncookie, susemgr string, verbose bool, opts ...Option) (status MonitoringStatus, err error) {
+	err = sumaGet(sessioncookie, susemgr, "admin/monitoring/getStatus", nil, &status, verbose, opts...)
+	return status, err
+}
+
+// EnableMonitoring enables the Prometheus exporters, see SumaEnableMonitoring
+func (c *SumaClient) EnableMonitoring(opts ...Option) (MonitoringStatus, error) {
+	verbose, opts := c.options(opts)
+	return SumaEnableMonitoring(c.SessionCookie(), c.URL, verbose, opts...)
+}
+
+// DisableMonitoring disables the Prometheus exporters, see SumaDisableMonitoring
+func (c *SumaClient) DisableMonitoring(opts ...Option) (MonitoringStatus, error) {
+	verbose, opts := c.options(opts)
+	return SumaDisableMonitoring(c.SessionCookie(), c.URL, verbose, opts...)
+}
+
+// MonitoringStatus returns the state of the Prometheus exporters, see SumaGetMonitoringStatus
+func (c *SumaClient) MonitoringStatus(opts ...Option) (MonitoringStatus, error) {
+	verbose, opts := c.options(opts)
+	return SumaGetMonitoringStatus(c.SessionCookie(), c.URL, verbose, opts...)
+}
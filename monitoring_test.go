@@ -0,0 +1,40 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSumaMonitoring(t *testing.T) {
+	enabled := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rhn/manager/api/admin/monitoring/enable", func(w http.ResponseWriter, r *http.Request) {
+		enabled = true
+		fmt.Fprint(w, `{"success": true, "result": {"node": true, "tomcat": true, "taskomatic": true, "postgres": true}}`)
+	})
+	mux.HandleFunc("GET /rhn/manager/api/admin/monitoring/getStatus", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success": true, "result": {"node": true, "tomcat": %t, "taskomatic": %t, "postgres": true}}`, enabled, enabled)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	status, err := c.MonitoringStatus()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Enabled() || !reflect.DeepEqual(status.Disabled(), []string{"taskomatic", "tomcat"}) {
+		t.Errorf("unexpected status %v", status)
+	}
+
+	if status, err = c.EnableMonitoring(); err != nil || !status.Enabled() {
+		t.Errorf("EnableMonitoring() = %v, %v", status, err)
+	}
+	if status, err = c.MonitoringStatus(); err != nil || !status.Enabled() {
+		t.Errorf("MonitoringStatus() = %v, %v", status, err)
+	}
+}
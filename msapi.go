@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"strings"
 )
 
 // BuildingBlockType hold the structure for a BuildingBlock
@@ -16,138 +16,146 @@ type BuildingBlockType struct {
 }
 
 // MsLogin login to Meshstack with a api key and get a bearer token back
-func MsLogin(clientid, clientsecret, apiurl string, verbose bool) (accesstoken string, err error) {
+func MsLogin(clientid, clientsecret, apiurl string, verbose bool, opts ...Option) (accesstoken string, err error) {
+	accesstoken, _, err = msLogin(clientid, clientsecret, apiurl, verbose, opts...)
+	return accesstoken, err
+}
+
+// msLogin login to Meshstack and returns the bearer token with its lifetime in seconds (0 if unknown)
+func msLogin(clientid, clientsecret, apiurl string, verbose bool, opts ...Option) (accesstoken string, expiresIn int, err error) {
 
 	var grantType string = "client_credentials"
 
 	if verbose {
-		log.Println("DEBUG MSAPI MsLogin: ====================")
-		log.Println("DEBUG MSAPI MsLogin: Enter function Login")
+		logDebugf("MSAPI MsLogin: ====================")
+		logDebugf("MSAPI MsLogin: Enter function Login")
 
-		defer log.Println("DEBUG MSAPI MSLogin: Leave function Login")
+		defer logDebugf("MSAPI MSLogin: Leave function Login")
 	}
 
 	//  Define the API Method
 	apiMethod := fmt.Sprintf("%s%s", apiurl, "/api/login")
 	if verbose {
-		log.Printf("DEBUG MSAPI MSLogin: apiMethod = %s", apiMethod)
+		logDebugf("MSAPI MSLogin: apiMethod = %s", apiMethod)
 	}
 
 	// Create the authentication request payload
 	payloadString := fmt.Sprintf("client_id=%s&client_secret=%s&grantType=%s", clientid, clientsecret, grantType)
 	payloadStringWithoutPassword := fmt.Sprintf("client_id=%s&client_secret=XXXXXXX&grantType=%s", clientid, grantType)
 	if verbose {
-		log.Printf("DEBUG MSAPI MSLogin: payloadString = %s", payloadStringWithoutPassword)
+		logDebugf("MSAPI MSLogin: payloadString = %s", payloadStringWithoutPassword)
 	}
 
 	// Create an HTTP POST request
 	req, err := http.NewRequest("POST", apiMethod, bytes.NewBufferString(payloadString))
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
-		return "", err
+		logErrorf("error creating request: %v", err)
+		return "", 0, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	//req.Header.Set("Content-Type", "application/json")
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("HTTP(S) Reqeust failed. Got: %v\n", err)
-		return "", err
+		logErrorf("HTTP(S) Reqeust failed. Got: %v", err)
+		return "", 0, err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	// Read respone Body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("error reading http response: %v", err)
-		return "", err
+		logErrorf("error reading http response: %v", err)
+		return "", 0, err
 	}
 
 	if verbose {
-		log.Printf("DEBUG MSAPI msLogin: Got resp.Body = %s\n", string(bodyBytes))
+		logDebugf("MSAPI msLogin: Got resp.Body = %s", string(bodyBytes))
 	}
 
 	// extract the authentication token
 	type ResultMsLogin struct {
 		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
 	}
 
 	// Unmarshal the JSON response into the struct
 	var myaccesstoken ResultMsLogin
 	err = json.Unmarshal(bodyBytes, &myaccesstoken)
 	if err != nil {
-		log.Printf("error unmarshaling JSON: %s\n", err)
-		return "", err
+		logErrorf("error unmarshaling JSON: %s", err)
+		return "", 0, err
 	}
 
 	if verbose {
-		log.Printf("DEBUG MSAPI MsLogin: Access_Token = %s\n", myaccesstoken.AccessToken)
-		log.Printf("DEBUG MSAPI MsLogin: Response status = %s\n", resp.Status)
+		logDebugf("MSAPI MsLogin: Access_Token = %s", myaccesstoken.AccessToken)
+		logDebugf("MSAPI MsLogin: Response status = %s", resp.Status)
 	}
 
-	return myaccesstoken.AccessToken, nil
+	return myaccesstoken.AccessToken, myaccesstoken.ExpiresIn, nil
 }
 
 // MsListBuildingBlocks list all deployed building blocks in a project
-func MsListBuildingBlocks(apiurl, projectid, apikey string, verbose bool) (bb []BuildingBlockType, err error) {
+func MsListBuildingBlocks(apiurl, projectid, apikey string, verbose bool, opts ...Option) (bb []BuildingBlockType, err error) {
+	err = MsEachBuildingBlock(apiurl, projectid, apikey, func(b BuildingBlockType) error {
+		bb = append(bb, b)
+		return nil
+	}, verbose, opts...)
+	return bb, err
+}
+
+// MsEachBuildingBlock calls fn for every deployed building block in a project. The list is decoded while
+// it is read, so large projects are not buffered in memory. An error of fn stops the listing.
+func MsEachBuildingBlock(apiurl, projectid, apikey string, fn func(BuildingBlockType) error, verbose bool, opts ...Option) (err error) {
 
-	var functionname string = "MsListBuildingBlocks"
+	var functionname string = "MsEachBuildingBlock"
 
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: ===================================\n", functionname)
-		log.Printf("DEBUG MSAPI %s: Enter function %s\n", functionname, functionname)
+		logDebugf("MSAPI %s: ===================================", functionname)
+		logDebugf("MSAPI %s: Enter function %s", functionname, functionname)
 
-		defer log.Printf("DEBUG MSAPI %s: Leave function %s\n", functionname, functionname)
+		defer logDebugf("MSAPI %s: Leave function %s", functionname, functionname)
 	}
 	//  Define the API Method
 	apiMethod := fmt.Sprintf("%s/api/meshobjects/meshbuildingblocks?projectIdentifier=%s", apiurl, projectid)
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: apiMethod = %s", functionname, apiMethod)
+		logDebugf("MSAPI %s: apiMethod = %s", functionname, apiMethod)
 	}
 
 	// Create an HTTP GET request
 	req, err := http.NewRequest(http.MethodGet, apiMethod, nil)
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
-		return bb, err
+		logErrorf("error creating request: %v", err)
+		return err
 	}
 	bearerApikey := fmt.Sprintf("Bearer %s", apikey)
 	req.Header.Set("Accept", "application/vnd.meshcloud.api.meshbuildingblock.v1.hal+json")
 	req.Header.Set("Authorization", bearerApikey)
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("HTTP(S) Reqeust failed. Error: %v\n", err)
-		return bb, err
+		logErrorf("HTTP(S) Reqeust failed. Error: %v", err)
+		return err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
-	// Read respone Body
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("error reading http response: %v", err)
-		return bb, err
-	}
-
-	if verbose {
-		log.Printf("DEBUG MSAPI %s: Got resp.Body = %s\n", functionname, string(bodyBytes))
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
 	}
 
-	// Unmarshal and extract UUID and DisplayName
+	// Decode the building blocks one by one and extract UUID and DisplayName
 	/*
 		jsonData := `{
 		  "_embedded": {
@@ -177,56 +185,47 @@ func MsListBuildingBlocks(apiurl, projectid, apikey string, verbose bool) (bb []
 		Metadata Metadata `json:"metadata"`
 		Spec     Spec     `json:"spec"`
 	}
-	type Embedded struct {
-		MeshBuildingBlockType []MeshBuildingBlockType `json:"meshBuildingBlocks"`
-	}
-	type Response struct {
-		Embedded Embedded `json:"_embedded"`
-	}
-
-	var myvalues Response
-	err = json.Unmarshal([]byte(bodyBytes), &myvalues)
-	if err != nil {
-		log.Printf("error unmarshal http response: %v", err)
-		return bb, err
-	}
 
-	for _, item := range myvalues.Embedded.MeshBuildingBlockType {
+	dec := json.NewDecoder(resp.Body)
+	err = streamJSONArray(dec, []string{"_embedded", "meshBuildingBlocks"}, func(item MeshBuildingBlockType) error {
 		if verbose {
-			log.Printf("UUID: %s, DisplayName: %s\n", item.Metadata.UUID, item.Spec.DisplayName)
+			logDebugf("UUID: %s, DisplayName: %s", item.Metadata.UUID, item.Spec.DisplayName)
 		}
-		newb := BuildingBlockType{Name: item.Spec.DisplayName, UUID: item.Metadata.UUID}
-		bb = append(bb, newb)
+		return fn(BuildingBlockType{Name: item.Spec.DisplayName, UUID: item.Metadata.UUID})
+	})
+	if err != nil {
+		logErrorf("error unmarshal http response: %v", err)
+		return err
 	}
 
-	return bb, nil
+	return nil
 }
 
 // MsCreateBuildingBlock create a new Building Block based on a template
-func MsCreateBuildingBlock(apiurl, apikey string, payload []byte, verbose bool) (UUID string, err error) {
+func MsCreateBuildingBlock(apiurl, apikey string, payload []byte, verbose bool, opts ...Option) (UUID string, err error) {
 
 	var functionname string = "MsCreateBuildingBlock"
 
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: ===================================\n", functionname)
-		log.Printf("DEBUG MSAPI %s: Enter function %s\n", functionname, functionname)
+		logDebugf("MSAPI %s: ===================================", functionname)
+		logDebugf("MSAPI %s: Enter function %s", functionname, functionname)
 
-		defer log.Printf("DEBUG MSAPI %s: Leave function %s\n", functionname, functionname)
+		defer logDebugf("MSAPI %s: Leave function %s", functionname, functionname)
 	}
 	//  Define the API Method
 	apiMethod := fmt.Sprintf("%s/api/meshobjects/meshbuildingblocks", apiurl)
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: apiMethod = %s", functionname, apiMethod)
+		logDebugf("MSAPI %s: apiMethod = %s", functionname, apiMethod)
 	}
 
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: payload = %s", functionname, payload)
+		logDebugf("MSAPI %s: payload = %s", functionname, payload)
 	}
 
 	// Create an HTTP POST request
 	req, err := http.NewRequest(http.MethodPost, apiMethod, bytes.NewBuffer(payload))
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
+		logErrorf("error creating request: %v", err)
 		return "", err
 	}
 	bearerApikey := fmt.Sprintf("Bearer %s", apikey)
@@ -235,28 +234,27 @@ func MsCreateBuildingBlock(apiurl, apikey string, payload []byte, verbose bool)
 	req.Header.Set("Content-Type", "application/vnd.meshcloud.api.meshbuildingblock.v1.hal+json;charset=UTF-8")
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("HTTP(S) Reqeust failed. Got: %v\n", err)
+		logErrorf("HTTP(S) Reqeust failed. Got: %v", err)
 		return "", err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	// Read respone Body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("error reading http response: %v", err)
+		logErrorf("error reading http response: %v", err)
 		return "", err
 	}
 
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: Got resp.Body = %s\n", functionname, string(bodyBytes))
+		logDebugf("MSAPI %s: Got resp.Body = %s", functionname, string(bodyBytes))
 	}
 
 	// get UUID
@@ -271,60 +269,59 @@ func MsCreateBuildingBlock(apiurl, apikey string, payload []byte, verbose bool)
 	var myUUID Response
 	err = json.Unmarshal([]byte(bodyBytes), &myUUID)
 	if err != nil {
-		log.Printf("error unmarshal http response: %v", err)
+		logErrorf("error unmarshal http response: %v", err)
 		return "", err
 	}
 
 	UUID = myUUID.Metadata.UUID
 
 	if verbose {
-		log.Printf("UUID: %s\n", myUUID.Metadata.UUID)
+		logDebugf("UUID: %s", myUUID.Metadata.UUID)
 	}
 
 	return UUID, nil
 }
 
 // MsDeleteBuildingBlock deletes a Building Block
-func MsDeleteBuildingBlock(apiurl, apikey, UUID string, verbose bool) (err error) {
+func MsDeleteBuildingBlock(apiurl, apikey, UUID string, verbose bool, opts ...Option) (err error) {
 
 	var functionname string = "MsDeleteBuildingBlock"
 
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: ===================================\n", functionname)
-		log.Printf("DEBUG MSAPI %s: Enter function %s\n", functionname, functionname)
+		logDebugf("MSAPI %s: ===================================", functionname)
+		logDebugf("MSAPI %s: Enter function %s", functionname, functionname)
 
-		defer log.Printf("DEBUG MSAPI %s: Leave function %s\n", functionname, functionname)
+		defer logDebugf("MSAPI %s: Leave function %s", functionname, functionname)
 	}
 	//  Define the API Method
 	apiMethod := fmt.Sprintf("%s/api/meshobjects/meshbuildingblocks/%s", apiurl, UUID)
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: apiMethod = %s", functionname, apiMethod)
+		logDebugf("MSAPI %s: apiMethod = %s", functionname, apiMethod)
 	}
 
 	// Create an HTTP DELETE request
 	req, err := http.NewRequest(http.MethodDelete, apiMethod, nil)
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
+		logErrorf("error creating request: %v", err)
 		return err
 	}
 	bearerApikey := fmt.Sprintf("Bearer %s", apikey)
 	req.Header.Set("Authorization", bearerApikey)
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("HTTP(S) Reqeust failed. Got: %v\n", err)
+		logErrorf("HTTP(S) Reqeust failed. Got: %v", err)
 		return err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("error http/%d", resp.StatusCode)
+		return statusError(resp)
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
@@ -332,26 +329,26 @@ func MsDeleteBuildingBlock(apiurl, apikey, UUID string, verbose bool) (err error
 }
 
 // MsGetBuildingBlock get the actual deployment status of a Building Block
-func MsGetBuildingBlock(apiurl, apikey, UUID string, verbose bool) (status string, err error) {
+func MsGetBuildingBlock(apiurl, apikey, UUID string, verbose bool, opts ...Option) (status string, err error) {
 
 	var functionname string = "MsGetBuildingBlock"
 
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: ===================================\n", functionname)
-		log.Printf("DEBUG MSAPI %s: Enter function %s\n", functionname, functionname)
+		logDebugf("MSAPI %s: ===================================", functionname)
+		logDebugf("MSAPI %s: Enter function %s", functionname, functionname)
 
-		defer log.Printf("DEBUG MSAPI %s: Leave function %s\n", functionname, functionname)
+		defer logDebugf("MSAPI %s: Leave function %s", functionname, functionname)
 	}
 	//  Define the API Method
 	apiMethod := fmt.Sprintf("%s/api/meshobjects/meshbuildingblocks/%s", apiurl, UUID)
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: apiMethod = %s", functionname, apiMethod)
+		logDebugf("MSAPI %s: apiMethod = %s", functionname, apiMethod)
 	}
 
 	// Create an HTTP GET request
 	req, err := http.NewRequest(http.MethodGet, apiMethod, nil)
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
+		logErrorf("error creating request: %v", err)
 		return "", err
 	}
 	bearerApikey := fmt.Sprintf("Bearer %s", apikey)
@@ -359,28 +356,27 @@ func MsGetBuildingBlock(apiurl, apikey, UUID string, verbose bool) (status strin
 	req.Header.Set("Authorization", bearerApikey)
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("HTTP(S) Reqeust failed. Got: %v\n", err)
+		logErrorf("HTTP(S) Reqeust failed. Got: %v", err)
 		return "", err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	// Read respone Body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("error reading http response: %v", err)
+		logErrorf("error reading http response: %v", err)
 		return "", err
 	}
 
 	if verbose {
-		log.Printf("DEBUG MSAPI %s: Got resp.Body = %s\n", functionname, string(bodyBytes))
+		logDebugf("MSAPI %s: Got resp.Body = %s", functionname, string(bodyBytes))
 	}
 
 	// get status
@@ -407,15 +403,194 @@ func MsGetBuildingBlock(apiurl, apikey, UUID string, verbose bool) (status strin
 	var mystatus Response
 	err = json.Unmarshal([]byte(bodyBytes), &mystatus)
 	if err != nil {
-		log.Printf("error unmarshal http response: %v", err)
+		logErrorf("error unmarshal http response: %v", err)
 		return "", err
 	}
 
 	status = string(mystatus.Status)
 
 	if verbose {
-		log.Printf("STATUS: %s\n", mystatus.Status)
+		logDebugf("STATUS: %s", mystatus.Status)
 	}
 
 	return status, nil
 }
+
+// MsGetBuildingBlockUUIDByName resolve the display name of a building block in a project to its UUID.
+// It returns an error if no building block or more than one building block with that name exists.
+func MsGetBuildingBlockUUIDByName(apiurl, projectid, apikey, name string, verbose bool, opts ...Option) (UUID string, err error) {
+
+	var functionname string = "MsGetBuildingBlockUUIDByName"
+
+	if verbose {
+		logDebugf("MSAPI %s: ===================================", functionname)
+		logDebugf("MSAPI %s: Enter function %s", functionname, functionname)
+
+		defer logDebugf("MSAPI %s: Leave function %s", functionname, functionname)
+	}
+
+	bbs, err := MsListBuildingBlocks(apiurl, projectid, apikey, verbose, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, bb := range bbs {
+		if bb.Name == name {
+			matches = append(matches, bb.UUID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no building block named %q found in project %s", name, projectid)
+	case 1:
+		if verbose {
+			logDebugf("MSAPI %s: %s has UUID %s", functionname, name, matches[0])
+		}
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("building block name %q is ambiguous in project %s, found %d matches: %s", name, projectid, len(matches), strings.Join(matches, ", "))
+	}
+}
+
+// BuildingBlockDetails hold the inputs, outputs and status of a building block
+type BuildingBlockDetails struct {
+	UUID           string
+	Name           string
+	DefinitionUUID string
+	Status         string
+	Inputs         map[string]string
+	Outputs        map[string]string
+}
+
+// MsGetBuildingBlockDetails get a building block with its inputs and outputs
+func MsGetBuildingBlockDetails(apiurl, apikey, UUID string, verbose bool, opts ...Option) (details BuildingBlockDetails, err error) {
+
+	var functionname string = "MsGetBuildingBlockDetails"
+
+	if verbose {
+		logDebugf("MSAPI %s: ===================================", functionname)
+		logDebugf("MSAPI %s: Enter function %s", functionname, functionname)
+
+		defer logDebugf("MSAPI %s: Leave function %s", functionname, functionname)
+	}
+	//  Define the API Method
+	apiMethod := fmt.Sprintf("%s/api/meshobjects/meshbuildingblocks/%s", apiurl, UUID)
+	if verbose {
+		logDebugf("MSAPI %s: apiMethod = %s", functionname, apiMethod)
+	}
+
+	// Create an HTTP GET request
+	req, err := http.NewRequest(http.MethodGet, apiMethod, nil)
+	if err != nil {
+		logErrorf("error creating request: %v", err)
+		return details, err
+	}
+	bearerApikey := fmt.Sprintf("Bearer %s", apikey)
+	req.Header.Set("Accept", "application/vnd.meshcloud.api.meshbuildingblock.v1.hal+json")
+	req.Header.Set("Authorization", bearerApikey)
+
+	// Send the request using the HTTP client
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		logErrorf("HTTP(S) Reqeust failed. Got: %v", err)
+		return details, err
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	// Read respone Body
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logErrorf("error reading http response: %v", err)
+		return details, err
+	}
+
+	if verbose {
+		logDebugf("MSAPI %s: Got resp.Body = %s", functionname, string(bodyBytes))
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return details, fmt.Errorf("building block %s: %w", UUID, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return details, statusError(resp)
+	}
+
+	/*
+		{
+		  "metadata": {"uuid": "xyz", "definitionUuid": "abc"},
+		  "spec": {
+		    "displayName": "my vm",
+		    "inputs": [{"key": "size", "value": "small", "valueType": "STRING"}]
+		  },
+		  "status": {
+		    "status": "SUCCEEDED",
+		    "outputs": [{"key": "hostname", "value": "vm1", "valueType": "STRING"}]
+		  }
+		}
+	*/
+
+	type KeyValue struct {
+		Key   string `json:"key"`
+		Value any    `json:"value"`
+	}
+	type Metadata struct {
+		UUID           string `json:"uuid"`
+		DefinitionUUID string `json:"definitionUuid"`
+	}
+	type Spec struct {
+		DisplayName string     `json:"displayName"`
+		Inputs      []KeyValue `json:"inputs"`
+	}
+	type Status struct {
+		Status  string     `json:"status"`
+		Outputs []KeyValue `json:"outputs"`
+	}
+	type Response struct {
+		Metadata Metadata        `json:"metadata"`
+		Spec     Spec            `json:"spec"`
+		Status   json.RawMessage `json:"status"`
+	}
+
+	var myvalues Response
+	err = json.Unmarshal(bodyBytes, &myvalues)
+	if err != nil {
+		logErrorf("error unmarshal http response: %v", err)
+		return details, err
+	}
+
+	// status is either the plain status or an object with status and outputs
+	var status Status
+	if len(myvalues.Status) > 0 && myvalues.Status[0] == '"' {
+		err = json.Unmarshal(myvalues.Status, &status.Status)
+	} else if len(myvalues.Status) > 0 {
+		err = json.Unmarshal(myvalues.Status, &status)
+	}
+	if err != nil {
+		logErrorf("error unmarshal building block status: %v", err)
+		return details, err
+	}
+
+	details = BuildingBlockDetails{
+		UUID:           myvalues.Metadata.UUID,
+		Name:           myvalues.Spec.DisplayName,
+		DefinitionUUID: myvalues.Metadata.DefinitionUUID,
+		Status:         status.Status,
+		Inputs:         make(map[string]string, len(myvalues.Spec.Inputs)),
+		Outputs:        make(map[string]string, len(status.Outputs)),
+	}
+	for _, input := range myvalues.Spec.Inputs {
+		details.Inputs[input.Key] = fmt.Sprint(input.Value)
+	}
+	for _, output := range status.Outputs {
+		details.Outputs[output.Key] = fmt.Sprint(output.Value)
+	}
+
+	return details, nil
+}
@@ -269,3 +269,111 @@ func TestMsDeleteBuildingBlock(t *testing.T) {
 		t.Errorf("Expected error for 404 Not Found response, got nil")
 	}
 }
+
+func TestMsGetBuildingBlockUUIDByName(t *testing.T) {
+	mockResponse := `{
+		"_embedded": {
+			"meshBuildingBlocks": [
+				{"metadata": {"uuid": "uuid-123"}, "spec": {"displayName": "Block One"}},
+				{"metadata": {"uuid": "uuid-456"}, "spec": {"displayName": "Block Two"}},
+				{"metadata": {"uuid": "uuid-789"}, "spec": {"displayName": "Block Two"}}
+			]
+		}
+	}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, mockResponse)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		bbName   string
+		wantUUID string
+		wantErr  bool
+	}{
+		{name: "single match", bbName: "Block One", wantUUID: "uuid-123", wantErr: false},
+		{name: "no match", bbName: "Block Three", wantUUID: "", wantErr: true},
+		{name: "ambiguous", bbName: "Block Two", wantUUID: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uuid, err := MsGetBuildingBlockUUIDByName(server.URL, "test-project", "test-api-key", tt.bbName, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MsGetBuildingBlockUUIDByName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if uuid != tt.wantUUID {
+				t.Errorf("MsGetBuildingBlockUUIDByName() = %q, want %q", uuid, tt.wantUUID)
+			}
+			if tt.name == "ambiguous" && !strings.Contains(err.Error(), "uuid-456") {
+				t.Errorf("expected error to list all candidates, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMsApplyMeshObject(t *testing.T) {
+	tests := []struct {
+		name            string
+		payload         string
+		response        string
+		wantContentType string
+		wantResults     int
+		wantErr         bool
+	}{
+		{
+			name:            "json payload",
+			payload:         `{"kind": "meshProject", "apiVersion": "v2", "metadata": {"name": "my-project"}}`,
+			response:        `[{"meshObject": {"kind": "meshProject", "name": "my-project"}, "status": "SUCCESS", "resultCode": "SUCCESS"}]`,
+			wantContentType: "application/vnd.meshcloud.api.meshobjects.v1+json",
+			wantResults:     1,
+			wantErr:         false,
+		},
+		{
+			name:            "yaml payload",
+			payload:         "kind: meshProject\napiVersion: v2\nmetadata:\n  name: my-project\n",
+			response:        `[{"meshObject": {"kind": "meshProject", "name": "my-project"}, "status": "SUCCESS", "resultCode": "SUCCESS"}]`,
+			wantContentType: "application/vnd.meshcloud.api.meshobjects.v1+yaml",
+			wantResults:     1,
+			wantErr:         false,
+		},
+		{
+			name:            "failed object",
+			payload:         `{"kind": "meshTenant"}`,
+			response:        `[{"meshObject": {"kind": "meshTenant", "name": "t1"}, "status": "FAILED", "resultCode": "INVALID", "message": "platform missing"}]`,
+			wantContentType: "application/vnd.meshcloud.api.meshobjects.v1+json",
+			wantResults:     1,
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					t.Errorf("Expected PUT request, got %s", r.Method)
+				}
+				if r.URL.Path != "/api/meshobjects" {
+					t.Errorf("Expected path /api/meshobjects, got %s", r.URL.Path)
+				}
+				ct := r.Header.Get("Content-Type")
+				if !strings.HasPrefix(ct, tt.wantContentType) {
+					t.Errorf("Expected Content-Type %s, got %s", tt.wantContentType, ct)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, tt.response)
+			}))
+			defer server.Close()
+
+			results, err := MsApplyMeshObject(server.URL, "test-api-key", []byte(tt.payload), false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MsApplyMeshObject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(results) != tt.wantResults {
+				t.Errorf("Expected %d results, got %d", tt.wantResults, len(results))
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package appapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// msMediaType returns the versioned HAL media type of a meshObject kind, e.g. meshworkspace
+func msMediaType(kind string) string {
+	return fmt.Sprintf("application/vnd.meshcloud.api.%s.v1.hal+json", kind)
+}
+
+// msGet calls a read-only Meshstack endpoint, e.g. "api/meshobjects/meshworkspaces", and unmarshals
+// the response into result. A 404 Not Found returns ErrNotFound.
+func msGet(apiurl, apikey, path, mediaType string, query url.Values, result any, verbose bool, opts ...Option) (err error) {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return msCall(apiurl, apikey, http.MethodGet, path, mediaType, nil, result, verbose, opts...)
+}
+
+// msCall calls a Meshstack endpoint with the payload as JSON body of the media type and unmarshals the
+// response into result
+func msCall(apiurl, apikey, httpMethod, path, mediaType string, payload, result any, verbose bool, opts ...Option) (err error) {
+
+	apiMethod := fmt.Sprintf("%s/%s", apiurl, path)
+	if verbose {
+		logDebugf("MSAPI msCall: %s apiMethod = %s", httpMethod, apiMethod)
+	}
+
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error marshalling payload: %v", err)
+		}
+		if verbose {
+			logDebugf("MSAPI msCall: Payload = %s", string(payloadBytes))
+		}
+		body = bytes.NewBuffer(payloadBytes)
+	}
+
+	req, err := http.NewRequest(httpMethod, apiMethod, body)
+	if err != nil {
+		return fmt.Errorf("error creating request for %s: %v", path, err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apikey))
+	if mediaType != "" {
+		req.Header.Set("Accept", mediaType)
+		if payload != nil {
+			req.Header.Set("Content-Type", mediaType)
+		}
+	}
+
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %v", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading http response: %v", err)
+	}
+
+	if verbose {
+		logDebugf("MSAPI msCall: Got resp.Body = %s", string(bodyBytes))
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return withRequestID(resp, fmt.Errorf("%s: %w", path, ErrNotFound))
+	case resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented:
+		// older Meshstack versions lack some operations, e.g. canceling a run
+		return withRequestID(resp, fmt.Errorf("%s %s: %w", httpMethod, path, ErrUnsupportedByServer))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("%w: %s", statusError(resp), string(bodyBytes))
+	}
+
+	if result == nil || len(bytes.TrimSpace(bodyBytes)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(bodyBytes, result); err != nil {
+		return withRequestID(resp, fmt.Errorf("%s: error unmarshaling JSON: %v", path, err))
+	}
+	return nil
+}
+
+// msPage is a page of a HAL collection of Meshstack, the items are embedded under their collection name
+type msPage[T any] struct {
+	Embedded map[string][]T `json:"_embedded"`
+	Page     struct {
+		Size          int `json:"size"`
+		TotalElements int `json:"totalElements"`
+		TotalPages    int `json:"totalPages"`
+		Number        int `json:"number"`
+	} `json:"page"`
+}
+
+// msPageSize is the number of items requested per page
+const msPageSize = 100
+
+// MsPageConcurrency is the number of pages of a Meshstack collection which are requested at the same
+// time, once the first page tells how many there are. 1 requests the pages one by one.
+var MsPageConcurrency = 4
+
+// msEachPage calls fn for every item of a paginated HAL collection in the order of the pages. After
+// the first page the others are requested concurrently, at most MsPageConcurrency pages ahead of fn.
+// An error of fn stops the listing.
+func msEachPage[T any](apiurl, apikey, path, mediaType, collection string, query url.Values, fn func(T) error, verbose bool, opts ...Option) error {
+	getPage := func(page int) (p msPage[T], err error) {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("size", strconv.Itoa(msPageSize))
+		q.Set("page", strconv.Itoa(page))
+		err = msGet(apiurl, apikey, path, mediaType, q, &p, verbose, opts...)
+		return p, err
+	}
+	eachItem := func(p msPage[T]) error {
+		for _, item := range p.Embedded[collection] {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	first, err := getPage(0)
+	if err != nil {
+		return err
+	}
+	if err := eachItem(first); err != nil {
+		return err
+	}
+	pages := first.Page.TotalPages
+	if pages <= 1 {
+		return nil
+	}
+
+	type result struct {
+		page msPage[T]
+		err  error
+	}
+	results := make([]chan result, pages)
+	for page := 1; page < pages; page++ {
+		results[page] = make(chan result, 1)
+	}
+	// a slot is taken before a page is requested and freed when fn got its items
+	slots := make(chan struct{}, max(MsPageConcurrency, 1))
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for page := 1; page < pages; page++ {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func() {
+				p, err := getPage(page)
+				results[page] <- result{p, err}
+			}()
+		}
+	}()
+
+	for page := 1; page < pages; page++ {
+		r := <-results[page]
+		if r.err != nil {
+			return r.err
+		}
+		if err := eachItem(r.page); err != nil {
+			return err
+		}
+		<-slots
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newPagedServer serves a collection of items with the numbers of the pages, two items per page
+func newPagedServer(t *testing.T, pages int, failPage int) (*httptest.Server, func() int) {
+	t.Helper()
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == failPage {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// the later pages answer first
+		time.Sleep(time.Duration(pages-page) * time.Millisecond)
+		fmt.Fprintf(w, `{"_embedded": {"items": [{"n": %d}, {"n": %d}]}, "page": {"size": 2, "totalElements": %d, "totalPages": %d, "number": %d}}`,
+			2*page, 2*page+1, 2*pages, pages, page)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return maxInFlight
+	}
+}
+
+type pagedItem struct {
+	N int `json:"n"`
+}
+
+func TestMsEachPage(t *testing.T) {
+	orig := MsPageConcurrency
+	defer func() { MsPageConcurrency = orig }()
+
+	for _, concurrency := range []int{1, 3} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			MsPageConcurrency = concurrency
+			server, maxInFlight := newPagedServer(t, 12, -1)
+
+			var got []int
+			err := msEachPage(server.URL, "token", "items", "", "items", nil, func(item pagedItem) error {
+				got = append(got, item.N)
+				return nil
+			}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]int, 24)
+			for i := range want {
+				want[i] = i
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("items = %v, want them in the order of the pages", got)
+			}
+			if n := maxInFlight(); n > concurrency {
+				t.Errorf("%d pages requested at the same time, want at most %d", n, concurrency)
+			}
+		})
+	}
+}
+
+func TestMsEachPageErrors(t *testing.T) {
+	server, _ := newPagedServer(t, 12, 5)
+
+	var got []int
+	err := msEachPage(server.URL, "token", "items", "", "items", nil, func(item pagedItem) error {
+		got = append(got, item.N)
+		return nil
+	}, false)
+	if err == nil || len(got) != 10 {
+		t.Errorf("got %d items, err = %v, want the items before the failed page and an error", len(got), err)
+	}
+
+	// an error of fn stops the listing
+	errStop := errors.New("stop")
+	got = nil
+	err = msEachPage(server.URL, "token", "items", "", "items", nil, func(item pagedItem) error {
+		got = append(got, item.N)
+		if item.N == 3 {
+			return errStop
+		}
+		return nil
+	}, false)
+	if !errors.Is(err, errStop) || len(got) != 4 {
+		t.Errorf("got %d items, err = %v, want 4 and %v", len(got), err, errStop)
+	}
+}
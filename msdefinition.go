@@ -0,0 +1,320 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The states of a building block definition version
+const (
+	DefinitionVersionDraft    = "DRAFT"
+	DefinitionVersionReleased = "RELEASED"
+)
+
+// DefinitionVersion is a version of a building block definition
+type DefinitionVersion struct {
+	UUID           string `json:"uuid" output:"-"`
+	DefinitionUUID string `json:"definitionUuid" output:"Definition"`
+	Version        int    `json:"version"`
+	State          string `json:"state"`
+	Deprecated     bool   `json:"deprecated"`
+	// Inputs is the input schema of the version
+	Inputs []DefinitionInput `json:"inputs,omitempty" output:"-"`
+}
+
+// The types of the inputs of a building block definition
+const (
+	InputString       = "STRING"
+	InputInteger      = "INTEGER"
+	InputBoolean      = "BOOLEAN"
+	InputSingleSelect = "SINGLE_SELECT"
+	InputMultiSelect  = "MULTI_SELECT"
+)
+
+// InputUserAssigned is the assignment type of the inputs the creator of a building block sets, the
+// other inputs are set by the definition, e.g. STATIC or PLATFORM_TENANT_ID
+const InputUserAssigned = "USER_INPUT"
+
+// DefinitionInput is an input of a building block definition version
+type DefinitionInput struct {
+	Key              string   `json:"key"`
+	Type             string   `json:"type"`
+	AssignmentType   string   `json:"assignmentType"`
+	SelectableValues []string `json:"selectableValues,omitempty"`
+	DefaultValue     any      `json:"defaultValue,omitempty"`
+}
+
+// Required reports if the creator of a building block has to set the input
+func (i DefinitionInput) Required() bool {
+	return i.AssignmentType == InputUserAssigned && i.DefaultValue == nil
+}
+
+// Released reports if the version can be used for new building blocks
+func (v DefinitionVersion) Released() bool {
+	return v.State == DefinitionVersionReleased && !v.Deprecated
+}
+
+// ErrDeprecatedDefinitionVersion is returned by MsCheckDefinitionVersion for a deprecated version
+var ErrDeprecatedDefinitionVersion = errors.New("building block definition version is deprecated")
+
+// DeprecatedVersionError is returned for a pinned version which is deprecated, it matches
+// ErrDeprecatedDefinitionVersion
+type DeprecatedVersionError struct {
+	DefinitionUUID string
+	Pinned         int
+	// Latest is the latest released version, 0 if there is none
+	Latest int
+}
+
+func (e *DeprecatedVersionError) Error() string {
+	if e.Latest == 0 {
+		return fmt.Sprintf("version %d of building block definition %s is deprecated, no version is released", e.Pinned, e.DefinitionUUID)
+	}
+	return fmt.Sprintf("version %d of building block definition %s is deprecated, the latest released version is %d", e.Pinned, e.DefinitionUUID, e.Latest)
+}
+
+func (e *DeprecatedVersionError) Is(target error) bool {
+	return target == ErrDeprecatedDefinitionVersion
+}
+
+// msDefinitionVersion is a meshBuildingBlockDefinitionVersion of the Meshstack API
+type msDefinitionVersion struct {
+	Metadata struct {
+		UUID string `json:"uuid"`
+	} `json:"metadata"`
+	Spec struct {
+		BuildingBlockDefinitionRef struct {
+			UUID string `json:"uuid"`
+		} `json:"buildingBlockDefinitionRef"`
+		VersionNumber int    `json:"versionNumber"`
+		State         string `json:"state"`
+		Deprecated    bool   `json:"deprecated"`
+		Inputs        map[string]struct {
+			Type             string   `json:"type"`
+			AssignmentType   string   `json:"assignmentType"`
+			SelectableValues []string `json:"selectableValues"`
+			DefaultValue     any      `json:"defaultValue"`
+		} `json:"inputs"`
+	} `json:"spec"`
+}
+
+func (v msDefinitionVersion) version() DefinitionVersion {
+	version := DefinitionVersion{
+		UUID:           v.Metadata.UUID,
+		DefinitionUUID: v.Spec.BuildingBlockDefinitionRef.UUID,
+		Version:        v.Spec.VersionNumber,
+		State:          v.Spec.State,
+		Deprecated:     v.Spec.Deprecated,
+	}
+	for key, input := range v.Spec.Inputs {
+		version.Inputs = append(version.Inputs, DefinitionInput{
+			Key:              key,
+			Type:             input.Type,
+			AssignmentType:   input.AssignmentType,
+			SelectableValues: input.SelectableValues,
+			DefaultValue:     input.DefaultValue,
+		})
+	}
+	sort.Slice(version.Inputs, func(i, j int) bool { return version.Inputs[i].Key < version.Inputs[j].Key })
+	return version
+}
+
+// ValidateInputs checks the inputs of a building block against the input schema of the version: every
+// required input is set, only user inputs are set, the values have the type of the input and select
+// inputs only use the selectable values. All problems are returned as joined ValidationErrors.
+func (v DefinitionVersion) ValidateInputs(inputs []MsApiBuildingBlockInput) error {
+	invalid := func(key, reason string) error {
+		return &ValidationError{Payload: "building block", Field: "input " + key, Reason: reason}
+	}
+
+	set := make(map[string]any, len(inputs))
+	for _, input := range inputs {
+		set[input.Key] = input.Value
+	}
+
+	var errs []error
+	schema := make(map[string]DefinitionInput, len(v.Inputs))
+	for _, input := range v.Inputs {
+		schema[input.Key] = input
+		if _, ok := set[input.Key]; !ok && input.Required() {
+			errs = append(errs, invalid(input.Key, "is required"))
+		}
+	}
+
+	for _, input := range inputs {
+		def, ok := schema[input.Key]
+		switch {
+		case !ok:
+			errs = append(errs, invalid(input.Key, fmt.Sprintf("is not defined by version %d of the definition", v.Version)))
+		case def.AssignmentType != InputUserAssigned:
+			errs = append(errs, invalid(input.Key, fmt.Sprintf("is assigned by the definition (%s)", def.AssignmentType)))
+		default:
+			if reason := checkInputValue(def, input.Value); reason != "" {
+				errs = append(errs, invalid(input.Key, reason))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkInputValue returns why the value does not match the input, or an empty string
+func checkInputValue(def DefinitionInput, value any) string {
+	switch def.Type {
+	case InputString:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("must be a string, got %T", value)
+		}
+	case InputInteger:
+		switch n := value.(type) {
+		case int, int32, int64:
+		case float64:
+			if n != float64(int64(n)) {
+				return fmt.Sprintf("must be an integer, got %v", n)
+			}
+		default:
+			return fmt.Sprintf("must be an integer, got %T", value)
+		}
+	case InputBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("must be a boolean, got %T", value)
+		}
+	case InputSingleSelect:
+		s, ok := value.(string)
+		if !ok || !slices.Contains(def.SelectableValues, s) {
+			return fmt.Sprintf("must be one of %s, got %v", strings.Join(def.SelectableValues, ", "), value)
+		}
+	case InputMultiSelect:
+		values, ok := value.([]string)
+		if !ok {
+			return fmt.Sprintf("must be a list of strings, got %T", value)
+		}
+		for _, s := range values {
+			if !slices.Contains(def.SelectableValues, s) {
+				return fmt.Sprintf("must only contain %s, got %s", strings.Join(def.SelectableValues, ", "), s)
+			}
+		}
+	}
+	return ""
+}
+
+// MsListBuildingBlockDefinitionVersions returns the versions of a building block definition, ordered
+// by the version number
+func MsListBuildingBlockDefinitionVersions(apiurl, apikey, defUUID string, verbose bool, opts ...Option) (versions []DefinitionVersion, err error) {
+	query := url.Values{"buildingBlockDefinitionUuid": {defUUID}}
+	err = msEachPage(apiurl, apikey, "api/meshobjects/meshbuildingblockdefinitionversions", msMediaType("meshbuildingblockdefinitionversion"), "meshBuildingBlockDefinitionVersions", query, func(v msDefinitionVersion) error {
+		versions = append(versions, v.version())
+		return nil
+	}, verbose, opts...)
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, err
+}
+
+// LatestReleasedVersion returns the released version with the highest number which is not deprecated
+func LatestReleasedVersion(versions []DefinitionVersion) (latest DefinitionVersion, ok bool) {
+	for _, v := range versions {
+		if v.Released() && v.Version > latest.Version {
+			latest, ok = v, true
+		}
+	}
+	return latest, ok
+}
+
+// MsLatestDefinitionVersion returns the latest released version of a building block definition, e.g.
+// to create building blocks without pinning a version. It returns ErrNotFound if no version is released.
+func MsLatestDefinitionVersion(apiurl, apikey, defUUID string, verbose bool, opts ...Option) (version DefinitionVersion, err error) {
+	versions, err := MsListBuildingBlockDefinitionVersions(apiurl, apikey, defUUID, verbose, opts...)
+	if err != nil {
+		return version, err
+	}
+	version, ok := LatestReleasedVersion(versions)
+	if !ok {
+		return version, fmt.Errorf("released version of building block definition %s: %w", defUUID, ErrNotFound)
+	}
+	return version, nil
+}
+
+// MsCheckDefinitionVersion checks a pinned version of a building block definition. It returns a
+// DeprecatedVersionError with the latest released version if the pinned version is deprecated and
+// ErrNotFound if the version does not exist.
+func MsCheckDefinitionVersion(apiurl, apikey, defUUID string, pinned int, verbose bool, opts ...Option) (err error) {
+	versions, err := MsListBuildingBlockDefinitionVersions(apiurl, apikey, defUUID, verbose, opts...)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if v.Version != pinned {
+			continue
+		}
+		if !v.Deprecated {
+			return nil
+		}
+		latest, _ := LatestReleasedVersion(versions)
+		return &DeprecatedVersionError{DefinitionUUID: defUUID, Pinned: pinned, Latest: latest.Version}
+	}
+	return fmt.Errorf("version %d of building block definition %s: %w", pinned, defUUID, ErrNotFound)
+}
+
+// MsGetDefinitionVersion returns a version of a building block definition with its input schema. It
+// returns ErrNotFound if the version does not exist.
+func MsGetDefinitionVersion(apiurl, apikey, defUUID string, version int, verbose bool, opts ...Option) (DefinitionVersion, error) {
+	versions, err := MsListBuildingBlockDefinitionVersions(apiurl, apikey, defUUID, verbose, opts...)
+	if err != nil {
+		return DefinitionVersion{}, err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return DefinitionVersion{}, fmt.Errorf("version %d of building block definition %s: %w", version, defUUID, ErrNotFound)
+}
+
+// MsCreateValidatedBuildingBlock validates the inputs of the payload against the input schema of its
+// definition version before the building block is created, see MsCreateBuildingBlock
+func MsCreateValidatedBuildingBlock(apiurl, apikey string, p MsApiCreateBuildingBlock, verbose bool, opts ...Option) (UUID string, err error) {
+	payload, err := p.Marshal()
+	if err != nil {
+		return "", err
+	}
+	version, err := MsGetDefinitionVersion(apiurl, apikey, p.Metadata.DefinitionUUID, p.Metadata.DefinitionVersion, verbose, opts...)
+	if err != nil {
+		return "", err
+	}
+	if err := version.ValidateInputs(p.Spec.Inputs); err != nil {
+		return "", err
+	}
+	return MsCreateBuildingBlock(apiurl, apikey, payload, verbose, opts...)
+}
+
+// DefinitionVersions returns the versions of a building block definition, see
+// MsListBuildingBlockDefinitionVersions
+func (c *MsClient) DefinitionVersions(defUUID string, opts ...Option) ([]DefinitionVersion, error) {
+	verbose, opts := c.options(opts)
+	return MsListBuildingBlockDefinitionVersions(c.URL, c.Token(), defUUID, verbose, opts...)
+}
+
+// LatestDefinitionVersion returns the latest released version of a building block definition, see
+// MsLatestDefinitionVersion
+func (c *MsClient) LatestDefinitionVersion(defUUID string, opts ...Option) (DefinitionVersion, error) {
+	verbose, opts := c.options(opts)
+	return MsLatestDefinitionVersion(c.URL, c.Token(), defUUID, verbose, opts...)
+}
+
+// CheckDefinitionVersion checks a pinned version of a building block definition, see
+// MsCheckDefinitionVersion
+func (c *MsClient) CheckDefinitionVersion(defUUID string, pinned int, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return MsCheckDefinitionVersion(c.URL, c.Token(), defUUID, pinned, verbose, opts...)
+}
+
+// CreateValidatedBuildingBlock validates the inputs and creates a building block, see
+// MsCreateValidatedBuildingBlock
+func (c *MsClient) CreateValidatedBuildingBlock(p MsApiCreateBuildingBlock, opts ...Option) (string, error) {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheMsBuildingBlocks)
+	return MsCreateValidatedBuildingBlock(c.URL, c.Token(), p, verbose, opts...)
+}
@@ -0,0 +1,107 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newDefinitionVersionServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshbuildingblockdefinitionversions" || r.URL.Query().Get("buildingBlockDefinitionUuid") != "def-1" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		version := func(n int, state string, deprecated bool) string {
+			return fmt.Sprintf(`{"metadata": {"uuid": "v%d"}, "spec": {"buildingBlockDefinitionRef": {"uuid": "def-1"}, "versionNumber": %d, "state": %q, "deprecated": %t}}`, n, n, state, deprecated)
+		}
+		fmt.Fprintf(w, `{"_embedded": {"meshBuildingBlockDefinitionVersions": [%s, %s, %s, %s]}, "page": {"size": 100, "totalElements": 4, "totalPages": 1, "number": 0}}`,
+			version(4, DefinitionVersionDraft, false), version(1, DefinitionVersionReleased, true), version(3, DefinitionVersionReleased, false), version(2, DefinitionVersionReleased, false))
+	}))
+}
+
+func TestMsListBuildingBlockDefinitionVersions(t *testing.T) {
+	server := newDefinitionVersionServer(t)
+	defer server.Close()
+
+	versions, err := MsListBuildingBlockDefinitionVersions(server.URL, "token", "def-1", false)
+	if err != nil || len(versions) != 4 {
+		t.Fatalf("MsListBuildingBlockDefinitionVersions() = %+v, %v", versions, err)
+	}
+	for i, v := range versions {
+		if v.Version != i+1 || v.DefinitionUUID != "def-1" {
+			t.Errorf("unexpected version %+v at %d", v, i)
+		}
+	}
+
+	latest, err := MsLatestDefinitionVersion(server.URL, "token", "def-1", false)
+	if err != nil || latest.Version != 3 {
+		t.Errorf("MsLatestDefinitionVersion() = %+v, %v, want version 3", latest, err)
+	}
+}
+
+func TestMsCheckDefinitionVersion(t *testing.T) {
+	server := newDefinitionVersionServer(t)
+	defer server.Close()
+
+	if err := MsCheckDefinitionVersion(server.URL, "token", "def-1", 2, false); err != nil {
+		t.Errorf("unexpected error for version 2: %v", err)
+	}
+
+	var derr *DeprecatedVersionError
+	err := MsCheckDefinitionVersion(server.URL, "token", "def-1", 1, false)
+	if !errors.Is(err, ErrDeprecatedDefinitionVersion) || !errors.As(err, &derr) || derr.Latest != 3 {
+		t.Errorf("expected DeprecatedVersionError with latest 3, got %v", err)
+	}
+
+	if err := MsCheckDefinitionVersion(server.URL, "token", "def-1", 7, false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for version 7, got %v", err)
+	}
+}
+
+func TestMsCreateValidatedBuildingBlock(t *testing.T) {
+	const defUUID = "0b7a3a40-2a64-4c7c-9f5a-0c9a3e1d2f10"
+	created := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblockdefinitionversions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"_embedded": {"meshBuildingBlockDefinitionVersions": [{"metadata": {"uuid": "v1"}, "spec": {
+			"buildingBlockDefinitionRef": {"uuid": %q}, "versionNumber": 1, "state": "RELEASED", "inputs": {
+			"flavor": {"type": "SINGLE_SELECT", "assignmentType": "USER_INPUT", "selectableValues": ["small", "large"]},
+			"disk": {"type": "INTEGER", "assignmentType": "USER_INPUT", "defaultValue": 20},
+			"hostname": {"type": "STRING", "assignmentType": "USER_INPUT"},
+			"tenant": {"type": "STRING", "assignmentType": "PLATFORM_TENANT_ID"}}}}]},
+			"page": {"size": 100, "totalElements": 1, "totalPages": 1, "number": 0}}`, defUUID)
+	})
+	mux.HandleFunc("POST /api/meshobjects/meshbuildingblocks", func(w http.ResponseWriter, r *http.Request) {
+		created++
+		fmt.Fprint(w, `{"metadata": {"uuid": "bb-1"}}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p, err := NewMsApiCreateBuildingBlock(defUUID, 1, "tenant-1", "vm", map[string]any{"flavor": "huge", "disk": "20", "tenant": "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = MsCreateValidatedBuildingBlock(server.URL, "token", p, false)
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var verr *ValidationError
+		if errors.As(e, &verr) {
+			fields = append(fields, verr.Field)
+		}
+	}
+	want := "[input hostname input disk input flavor input tenant]"
+	if fmt.Sprint(fields) != want {
+		t.Errorf("got validation errors for %v, want %s: %v", fields, want, err)
+	}
+	if created != 0 {
+		t.Errorf("the building block must not be created with invalid inputs")
+	}
+
+	p, _ = NewMsApiCreateBuildingBlock(defUUID, 1, "tenant-1", "vm", map[string]any{"flavor": "small", "hostname": "vm1"})
+	if uuid, err := MsCreateValidatedBuildingBlock(server.URL, "token", p, false); err != nil || uuid != "bb-1" || created != 1 {
+		t.Errorf("MsCreateValidatedBuildingBlock() = %q, %v", uuid, err)
+	}
+}
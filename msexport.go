@@ -0,0 +1,109 @@
+package appapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// msServerFields are the metadata fields set by Meshstack, they are not part of a manifest
+var msServerFields = []string{"uuid", "createdOn", "deletedOn", "markedForDeletionOn", "markedForDeletionBy"}
+
+// exportManifest turns a meshObject read from Meshstack into a manifest without the fields set by
+// Meshstack, e.g. the links, the status and the creation date
+func exportManifest(object map[string]any) MeshObjectManifest {
+	object = withoutKeys(object, "_links", "status")
+	if metadata, ok := object["metadata"].(map[string]any); ok {
+		keep := metadata["uuid"]
+		metadata = withoutKeys(metadata, msServerFields...)
+		// building blocks have no name which identifies them in the project
+		if object["kind"] == "meshBuildingBlock" && keep != nil {
+			metadata["uuid"] = keep
+		}
+		object["metadata"] = metadata
+	}
+	if _, ok := object["apiVersion"]; !ok {
+		object["apiVersion"] = "v1"
+	}
+	m := MeshObjectManifest{Object: object}
+	m.Kind, _ = object["kind"].(string)
+	m.Name = manifestName(object)
+	return m
+}
+
+// MsExportProject reads the meshObjects of a project, the project itself, its tenants, its user and
+// group bindings and its building blocks, as manifests for MsApplyManifests
+func MsExportProject(apiurl, apikey, workspace, project string, verbose bool, opts ...Option) (manifests []MeshObjectManifest, err error) {
+
+	var object map[string]any
+	if err := msGet(apiurl, apikey, "api/meshobjects/meshprojects/"+url.PathEscape(workspace+"."+project), msMediaType("meshproject"), nil, &object, verbose, opts...); err != nil {
+		return nil, fmt.Errorf("could not read project %s of workspace %s: %w", project, workspace, err)
+	}
+	object["kind"] = "meshProject"
+	manifests = append(manifests, exportManifest(object))
+
+	query := url.Values{"workspaceIdentifier": {workspace}, "projectIdentifier": {project}}
+	collections := []struct {
+		kind, path, mediaType, collection string
+		query                             url.Values
+	}{
+		{"meshTenant", "api/meshobjects/meshtenants", "meshtenant", "meshTenants", query},
+		{"meshProjectUserBinding", "api/meshobjects/meshprojectbindings/userbindings", "meshprojectuserbinding", "meshProjectUserBindings", query},
+		{"meshProjectGroupBinding", "api/meshobjects/meshprojectbindings/groupbindings", "meshprojectgroupbinding", "meshProjectGroupBindings", query},
+		{"meshBuildingBlock", "api/meshobjects/meshbuildingblocks", "meshbuildingblock", "meshBuildingBlocks", url.Values{"projectIdentifier": {project}}},
+	}
+	for _, c := range collections {
+		err := msEachPage(apiurl, apikey, c.path, msMediaType(c.mediaType), c.collection, c.query, func(object map[string]any) error {
+			object["kind"] = c.kind
+			manifests = append(manifests, exportManifest(object))
+			return nil
+		}, verbose, opts...)
+		if err != nil {
+			return manifests, fmt.Errorf("could not read the %s objects of project %s: %w", c.kind, project, err)
+		}
+	}
+
+	if verbose {
+		logDebugf("MSAPI MsExportProject: exported %d meshObjects of project %s", len(manifests), project)
+	}
+	return manifests, nil
+}
+
+// WriteManifests writes the manifests as YAML documents separated by "---"
+func WriteManifests(w io.Writer, manifests []MeshObjectManifest) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	for _, m := range manifests {
+		if err := enc.Encode(m.Object); err != nil {
+			return fmt.Errorf("could not write %s %s: %v", m.Kind, m.Name, err)
+		}
+	}
+	return enc.Close()
+}
+
+// MsExportProjectToDir writes the meshObjects of a project to the manifest <workspace>.<project>.yaml
+// in dir and returns the path of the file, see MsExportProject
+func MsExportProjectToDir(apiurl, apikey, workspace, project, dir string, verbose bool, opts ...Option) (file string, err error) {
+	manifests, err := MsExportProject(apiurl, apikey, workspace, project, verbose, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := WriteManifests(&b, manifests); err != nil {
+		return "", err
+	}
+	file = filepath.Join(dir, workspace+"."+project+".yaml")
+	return file, os.WriteFile(file, b.Bytes(), 0o644)
+}
+
+// ExportProject writes the meshObjects of a project to a manifest in dir, see MsExportProjectToDir
+func (c *MsClient) ExportProject(workspace, project, dir string, opts ...Option) (string, error) {
+	verbose, opts := c.options(opts)
+	return MsExportProjectToDir(c.URL, c.Token(), workspace, project, dir, verbose, opts...)
+}
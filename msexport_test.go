@@ -0,0 +1,65 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMsExportProject(t *testing.T) {
+	page := func(collection, items string) string {
+		return fmt.Sprintf(`{"_embedded": {%q: [%s]}, "page": {"size": 100, "totalElements": 1, "totalPages": 1, "number": 0}}`, collection, items)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/meshobjects/meshprojects/retail.shop", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"apiVersion": "v1", "kind": "meshProject", "metadata": {"name": "shop", "ownedByWorkspace": "retail", "createdOn": "2024-05-01T10:00:00Z"},
+			"spec": {"displayName": "Shop", "tags": {"env": ["prod"]}}, "_links": {"self": {"href": "x"}}}`)
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshtenants", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("projectIdentifier") != "shop" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, page("meshTenants", `{"metadata": {"ownedByWorkspace": "retail", "ownedByProject": "shop", "platformIdentifier": "openstack.eu"}, "spec": {"localId": "abc"}}`))
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshprojectbindings/userbindings", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, page("meshProjectUserBindings", `{"metadata": {"name": "shop-admin-jdoe"}, "roleRef": {"name": "Project Admin"}, "subjects": [{"name": "jdoe"}]}`))
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshprojectbindings/groupbindings", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"page": {"size": 100, "totalElements": 0, "totalPages": 0, "number": 0}}`)
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblocks", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, page("meshBuildingBlocks", `{"metadata": {"uuid": "bb-1", "createdOn": "2024-05-02T10:00:00Z"}, "spec": {"displayName": "suma-proxy"}, "status": {"status": "SUCCEEDED"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	file, err := MsExportProjectToDir(server.URL, "token", "retail", "shop", dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(file)
+	for _, unwanted := range []string{"createdOn", "_links", "SUCCEEDED"} {
+		if strings.Contains(string(content), unwanted) {
+			t.Errorf("manifest contains %s:\n%s", unwanted, content)
+		}
+	}
+
+	// the export can be applied again
+	manifests, err := ReadManifestDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, m := range manifests {
+		got = append(got, m.Kind+"/"+m.Name)
+	}
+	want := []string{"meshProject/shop", "meshTenant/shop.openstack.eu", "meshProjectUserBinding/shop-admin-jdoe", "meshBuildingBlock/bb-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
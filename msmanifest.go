@@ -0,0 +1,291 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The states of a meshObject applied by MsApplyManifests
+const (
+	ManifestCreated   = "created"
+	ManifestUpdated   = "updated"
+	ManifestUnchanged = "unchanged"
+	// ManifestApplied is an imported meshObject whose previous state cannot be read, e.g. a binding
+	ManifestApplied = "applied"
+	ManifestFailed  = "failed"
+)
+
+// MeshObjectManifest is one meshObject of a YAML manifest, e.g. a meshProject
+type MeshObjectManifest struct {
+	File   string
+	Kind   string
+	Name   string
+	Object map[string]any
+}
+
+// ManifestResult is the result of one meshObject applied by MsApplyManifests
+type ManifestResult struct {
+	File    string
+	Kind    string
+	Name    string
+	Status  string
+	Message string `output:"-"`
+}
+
+// ReadManifestDir reads the meshObjects of the YAML files (*.yaml, *.yml) of a directory and its
+// subdirectories, in the order of the file names. A file can hold several documents separated by
+// "---".
+func ReadManifestDir(dir string) (manifests []MeshObjectManifest, err error) {
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := filepath.Ext(path); !d.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		m, err := readManifests(f, file)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m...)
+	}
+	return manifests, nil
+}
+
+// readManifests decodes the YAML documents of a manifest file
+func readManifests(r io.Reader, file string) (manifests []MeshObjectManifest, err error) {
+	dec := yaml.NewDecoder(r)
+	for i := 1; ; i++ {
+		var doc map[string]any
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			return manifests, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: document %d: %v", file, i, err)
+		}
+		if doc == nil {
+			// empty document, e.g. a trailing ---
+			continue
+		}
+
+		// round trip through JSON so the objects compare with the objects read from Meshstack
+		object, err := normalizeJSON(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: document %d: %v", file, i, err)
+		}
+		m := MeshObjectManifest{File: file, Object: object}
+		m.Kind, _ = object["kind"].(string)
+		m.Name = manifestName(object)
+		if m.Kind == "" || m.Name == "" {
+			return nil, fmt.Errorf("%s: document %d: kind and metadata.name are required", file, i)
+		}
+		if _, ok := object["apiVersion"]; !ok {
+			object["apiVersion"] = "v1"
+		}
+		manifests = append(manifests, m)
+	}
+}
+
+func normalizeJSON(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	return object, json.Unmarshal(b, &object)
+}
+
+// manifestField returns the value of a nested field, e.g. metadata.name
+func manifestField(object map[string]any, path ...string) any {
+	var v any = object
+	for _, key := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// manifestName returns the name of a meshObject. Tenants are named by their project and platform,
+// building blocks by their UUID.
+func manifestName(object map[string]any) string {
+	if name, _ := manifestField(object, "metadata", "name").(string); name != "" {
+		return name
+	}
+	switch object["kind"] {
+	case "meshTenant":
+		project, _ := manifestField(object, "metadata", "ownedByProject").(string)
+		platform, _ := manifestField(object, "metadata", "platformIdentifier").(string)
+		if project != "" && platform != "" {
+			return project + "." + platform
+		}
+	case "meshBuildingBlock":
+		uuid, _ := manifestField(object, "metadata", "uuid").(string)
+		return uuid
+	}
+	return ""
+}
+
+// msManifestPath returns the path of the meshObject of a manifest in the Meshstack API and its media
+// type. Kinds without a readable path, e.g. bindings, return an empty path.
+func msManifestPath(m MeshObjectManifest) (path, mediaType string) {
+	workspace, _ := manifestField(m.Object, "metadata", "ownedByWorkspace").(string)
+	project, _ := manifestField(m.Object, "metadata", "ownedByProject").(string)
+	platform, _ := manifestField(m.Object, "metadata", "platformIdentifier").(string)
+
+	var id string
+	switch m.Kind {
+	case "meshWorkspace":
+		id = m.Name
+	case "meshProject":
+		id = workspace + "." + m.Name
+	case "meshTenant":
+		id = workspace + "." + project + "." + platform
+	default:
+		return "", ""
+	}
+	kind := strings.ToLower(m.Kind)
+	return "api/meshobjects/" + kind + "s/" + id, msMediaType(kind)
+}
+
+// manifestContains reports if every field of desired has the same value in current. Fields only set
+// by Meshstack, e.g. the creation date, are ignored.
+func manifestContains(current, desired any) bool {
+	switch d := desired.(type) {
+	case map[string]any:
+		c, ok := current.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range d {
+			if !manifestContains(c[k], v) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		c, ok := current.([]any)
+		if !ok || len(c) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !manifestContains(c[i], d[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(current, desired)
+	}
+}
+
+// MsApplyManifests applies the meshObjects of the YAML manifests in dir with the meshObject import,
+// see ReadManifestDir and MsApplyMeshObject. Workspaces, projects and tenants are compared with their
+// current state first, unchanged objects are not imported again. The result reports every object as
+// created, updated, unchanged, applied (for kinds whose state cannot be read) or failed.
+func MsApplyManifests(apiurl, apikey, dir string, verbose bool, opts ...Option) (results []ManifestResult, err error) {
+	manifests, err := ReadManifestDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return msApplyManifests(apiurl, apikey, manifests, verbose, opts...)
+}
+
+func msApplyManifests(apiurl, apikey string, manifests []MeshObjectManifest, verbose bool, opts ...Option) (results []ManifestResult, err error) {
+
+	var payload []map[string]any
+	pending := map[string]int{}
+	for _, m := range manifests {
+		result := ManifestResult{File: m.File, Kind: m.Kind, Name: m.Name, Status: ManifestApplied}
+
+		if path, mediaType := msManifestPath(m); path != "" {
+			var current map[string]any
+			err := msGet(apiurl, apikey, path, mediaType, nil, &current, verbose, opts...)
+			switch {
+			case errors.Is(err, ErrNotFound):
+				result.Status = ManifestCreated
+			case err != nil:
+				return results, fmt.Errorf("could not read %s %s: %w", m.Kind, m.Name, err)
+			case manifestContains(current, withoutKeys(m.Object, "apiVersion", "kind")):
+				result.Status = ManifestUnchanged
+			default:
+				result.Status = ManifestUpdated
+			}
+		}
+		if verbose {
+			logDebugf("MSAPI MsApplyManifests: %s %s from %s is %s", m.Kind, m.Name, m.File, result.Status)
+		}
+
+		results = append(results, result)
+		if result.Status != ManifestUnchanged {
+			pending[m.Kind+"/"+m.Name] = len(results) - 1
+			payload = append(payload, m.Object)
+		}
+	}
+
+	if len(payload) == 0 {
+		return results, nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return results, fmt.Errorf("error marshalling payload: %v", err)
+	}
+	imported, err := MsApplyMeshObject(apiurl, apikey, body, verbose, opts...)
+	if err != nil && len(imported) == 0 {
+		// the import was rejected as a whole
+		for _, i := range pending {
+			results[i].Status = ManifestFailed
+			results[i].Message = err.Error()
+		}
+	}
+	for _, r := range imported {
+		if i, ok := pending[r.Kind+"/"+r.Name]; ok && r.Status != "SUCCESS" {
+			results[i].Status = ManifestFailed
+			results[i].Message = r.Message
+		}
+	}
+	return results, err
+}
+
+// withoutKeys returns a copy of the object without the top level keys
+func withoutKeys(object map[string]any, keys ...string) map[string]any {
+	c := make(map[string]any, len(object))
+	for k, v := range object {
+		c[k] = v
+	}
+	for _, k := range keys {
+		delete(c, k)
+	}
+	return c
+}
+
+// ApplyManifests applies the YAML manifests of a directory, see MsApplyManifests
+func (c *MsClient) ApplyManifests(dir string, opts ...Option) ([]ManifestResult, error) {
+	verbose, opts := c.options(opts)
+	return MsApplyManifests(c.URL, c.Token(), dir, verbose, opts...)
+}
@@ -0,0 +1,105 @@
+package appapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadManifestDir(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "projects"), 0o755)
+	os.WriteFile(filepath.Join(dir, "workspace.yaml"), []byte("kind: meshWorkspace\nmetadata:\n  name: retail\nspec:\n  displayName: Retail\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "projects", "shop.yml"), []byte("---\nkind: meshProject\nmetadata:\n  name: shop\n  ownedByWorkspace: retail\n---\napiVersion: v1\nkind: meshProject\nmetadata:\n  name: web\n  ownedByWorkspace: retail\n---\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0o644)
+
+	manifests, err := ReadManifestDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, m := range manifests {
+		got = append(got, m.Kind+"/"+m.Name)
+		if m.Object["apiVersion"] != "v1" {
+			t.Errorf("%s/%s: apiVersion not defaulted: %v", m.Kind, m.Name, m.Object)
+		}
+	}
+	want := []string{"meshProject/shop", "meshProject/web", "meshWorkspace/retail"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("kind: meshProject\n"), 0o644)
+	if _, err := ReadManifestDir(dir); err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Errorf("expected an error for the manifest without name, got %v", err)
+	}
+}
+
+func TestMsApplyManifests(t *testing.T) {
+	var imported []map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/meshobjects/meshworkspaces/retail", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"apiVersion": "v1", "kind": "meshWorkspace", "metadata": {"name": "retail", "createdOn": "2024-05-01T10:00:00Z"}, "spec": {"displayName": "Retail"}}`)
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshprojects/retail.shop", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"metadata": {"name": "shop", "ownedByWorkspace": "retail"}, "spec": {"displayName": "Old Shop"}}`)
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshprojects/retail.web", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("PUT /api/meshobjects", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &imported); err != nil {
+			t.Errorf("invalid payload %s: %v", b, err)
+		}
+		fmt.Fprint(w, `[{"meshObject": {"kind": "meshProject", "name": "shop"}, "status": "SUCCESS", "resultCode": "SUCCESS"},
+			{"meshObject": {"kind": "meshProject", "name": "web"}, "status": "SUCCESS", "resultCode": "SUCCESS"},
+			{"meshObject": {"kind": "meshProjectUserBinding", "name": "shop-admin"}, "status": "FAILED", "resultCode": "FAILED", "message": "unknown user"}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	manifests, err := readManifests(strings.NewReader(`
+kind: meshWorkspace
+metadata: {name: retail}
+spec: {displayName: Retail}
+---
+kind: meshProject
+metadata: {name: shop, ownedByWorkspace: retail}
+spec: {displayName: Shop}
+---
+kind: meshProject
+metadata: {name: web, ownedByWorkspace: retail}
+spec: {displayName: Web}
+---
+kind: meshProjectUserBinding
+metadata: {name: shop-admin}
+roleRef: {name: Project Admin}
+`), "retail.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, err := msApplyManifests(server.URL, "token", manifests, false)
+	if err == nil {
+		t.Errorf("expected an error for the failed binding")
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Name+":"+r.Status)
+	}
+	want := []string{"retail:unchanged", "shop:updated", "web:created", "shop-admin:failed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if len(imported) != 3 {
+		t.Errorf("the unchanged workspace must not be imported, got %v", imported)
+	}
+}
@@ -0,0 +1,145 @@
+package appapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MeshObjectImportResult hold the result of one meshObject of a declarative import
+type MeshObjectImportResult struct {
+	Kind       string
+	Name       string
+	Status     string
+	ResultCode string
+	Message    string
+}
+
+// isJSONPayload checks if a meshObject definition is JSON. Everything else is handled as YAML.
+func isJSONPayload(payload []byte) bool {
+	trimmed := bytes.TrimSpace(payload)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// MsApplyMeshObject create or update meshObjects (projects, tenants, role bindings, ...) with the meshObject
+// import endpoint. The payload could be a JSON or YAML definition, the import is idempotent.
+func MsApplyMeshObject(apiurl, apikey string, payload []byte, verbose bool, opts ...Option) (results []MeshObjectImportResult, err error) {
+
+	var functionname string = "MsApplyMeshObject"
+
+	if verbose {
+		logDebugf("MSAPI %s: ===================================", functionname)
+		logDebugf("MSAPI %s: Enter function %s", functionname, functionname)
+
+		defer logDebugf("MSAPI %s: Leave function %s", functionname, functionname)
+	}
+	//  Define the API Method
+	apiMethod := fmt.Sprintf("%s/api/meshobjects", apiurl)
+	if verbose {
+		logDebugf("MSAPI %s: apiMethod = %s", functionname, apiMethod)
+	}
+
+	contentType := "application/vnd.meshcloud.api.meshobjects.v1+yaml;charset=UTF-8"
+	if isJSONPayload(payload) {
+		contentType = "application/vnd.meshcloud.api.meshobjects.v1+json;charset=UTF-8"
+	}
+
+	if verbose {
+		logDebugf("MSAPI %s: Content-Type = %s", functionname, contentType)
+		logDebugf("MSAPI %s: payload = %s", functionname, payload)
+	}
+
+	// Create an HTTP PUT request
+	req, err := http.NewRequest(http.MethodPut, apiMethod, bytes.NewBuffer(payload))
+	if err != nil {
+		logErrorf("error creating request: %v", err)
+		return nil, err
+	}
+	bearerApikey := fmt.Sprintf("Bearer %s", apikey)
+	req.Header.Set("Accept", "application/vnd.meshcloud.api.meshobjects.v1+json")
+	req.Header.Set("Authorization", bearerApikey)
+	req.Header.Set("Content-Type", contentType)
+
+	// Send the request using the HTTP client
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		logErrorf("HTTP(S) Reqeust failed. Got: %v", err)
+		return nil, err
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	// Read respone Body
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logErrorf("error reading http response: %v", err)
+		return nil, err
+	}
+
+	if verbose {
+		logDebugf("MSAPI %s: Got resp.Body = %s", functionname, string(bodyBytes))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", statusError(resp), string(bodyBytes))
+	}
+
+	/*
+		[
+		  {
+		    "meshObject": {"kind": "meshProject", "name": "my-project"},
+		    "status": "SUCCESS",
+		    "resultCode": "SUCCESS",
+		    "message": null
+		  }
+		]
+	*/
+
+	type MeshObject struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	}
+	type Response struct {
+		MeshObject MeshObject `json:"meshObject"`
+		Status     string     `json:"status"`
+		ResultCode string     `json:"resultCode"`
+		Message    string     `json:"message"`
+	}
+
+	var myresults []Response
+	err = json.Unmarshal(bodyBytes, &myresults)
+	if err != nil {
+		logErrorf("error unmarshal http response: %v", err)
+		return nil, err
+	}
+
+	var failed []string
+	for _, item := range myresults {
+		if verbose {
+			logDebugf("MSAPI %s: %s %s: %s (%s)", functionname, item.MeshObject.Kind, item.MeshObject.Name, item.Status, item.ResultCode)
+		}
+		results = append(results, MeshObjectImportResult{
+			Kind:       item.MeshObject.Kind,
+			Name:       item.MeshObject.Name,
+			Status:     item.Status,
+			ResultCode: item.ResultCode,
+			Message:    item.Message,
+		})
+		if item.Status != "SUCCESS" {
+			failed = append(failed, fmt.Sprintf("%s %s: %s", item.MeshObject.Kind, item.MeshObject.Name, item.Message))
+		}
+	}
+
+	if len(failed) > 0 {
+		return results, fmt.Errorf("import of %d meshObject(s) failed: %s", len(failed), strings.Join(failed, "; "))
+	}
+
+	return results, nil
+}
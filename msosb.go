@@ -0,0 +1,165 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// msOSBPath is the Open Service Broker endpoint of the Meshstack marketplace, the services
+// published in the marketplace are provisioned through it
+const msOSBPath = "api/marketplace/osb/v2"
+
+// osbAPIVersion is the version of the Open Service Broker API sent with every call
+const osbAPIVersion = "2.17"
+
+// OSBPlan is a plan of a marketplace service
+type OSBPlan struct {
+	ID          string `json:"id" output:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Free        bool   `json:"free"`
+}
+
+// OSBService is a service of the marketplace catalog
+type OSBService struct {
+	ID          string    `json:"id" output:"-"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Bindable    bool      `json:"bindable"`
+	Plans       []OSBPlan `json:"plans" output:"-"`
+}
+
+// Plan returns the plan of the service with the name
+func (s OSBService) Plan(name string) (OSBPlan, bool) {
+	for _, p := range s.Plans {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return OSBPlan{}, false
+}
+
+// OSBInstance is a service instance of a Meshstack project, the workspace and project are the
+// organization and space of the Open Service Broker API
+type OSBInstance struct {
+	ID         string
+	ServiceID  string
+	PlanID     string
+	Workspace  string
+	Project    string
+	Parameters map[string]any
+}
+
+// OSBOperation is the result of a provisioning. Operation is set if the broker provisions the
+// instance asynchronously.
+type OSBOperation struct {
+	DashboardURL string `json:"dashboard_url,omitempty"`
+	Operation    string `json:"operation,omitempty"`
+}
+
+// msOSBCall calls the Open Service Broker endpoint of the marketplace
+func msOSBCall(apiurl, apikey, httpMethod, path string, query url.Values, payload, result any, verbose bool, opts ...Option) (err error) {
+	path = msOSBPath + "/" + path
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	opts = append(opts[:len(opts):len(opts)], WithHeader("X-Broker-API-Version", osbAPIVersion))
+	return msCall(apiurl, apikey, httpMethod, path, "application/json", payload, result, verbose, opts...)
+}
+
+// MsOSBCatalog returns the services of the marketplace with their plans
+func MsOSBCatalog(apiurl, apikey string, verbose bool, opts ...Option) (services []OSBService, err error) {
+	var catalog struct {
+		Services []OSBService `json:"services"`
+	}
+	if err := msOSBCall(apiurl, apikey, http.MethodGet, "catalog", nil, nil, &catalog, verbose, opts...); err != nil {
+		return nil, err
+	}
+	return catalog.Services, nil
+}
+
+// MsOSBProvision provisions a service instance in a project. Brokers which provision asynchronously
+// return the operation to poll.
+func MsOSBProvision(apiurl, apikey string, instance OSBInstance, verbose bool, opts ...Option) (operation OSBOperation, err error) {
+	if instance.ID == "" || instance.ServiceID == "" || instance.PlanID == "" {
+		return operation, &ValidationError{Payload: "service instance", Field: "id, service_id and plan_id", Reason: "are required"}
+	}
+
+	payload := map[string]any{
+		"service_id":        instance.ServiceID,
+		"plan_id":           instance.PlanID,
+		"organization_guid": instance.Workspace,
+		"space_guid":        instance.Project,
+		"context":           map[string]any{"platform": "meshmarketplace", "workspace": instance.Workspace, "project": instance.Project},
+	}
+	if len(instance.Parameters) > 0 {
+		payload["parameters"] = instance.Parameters
+	}
+	query := url.Values{"accepts_incomplete": {"true"}}
+	err = msOSBCall(apiurl, apikey, http.MethodPut, "service_instances/"+url.PathEscape(instance.ID), query, payload, &operation, verbose, opts...)
+	return operation, err
+}
+
+// MsOSBDeprovision deletes a service instance
+func MsOSBDeprovision(apiurl, apikey string, instance OSBInstance, verbose bool, opts ...Option) (err error) {
+	query := url.Values{"service_id": {instance.ServiceID}, "plan_id": {instance.PlanID}, "accepts_incomplete": {"true"}}
+	return msOSBCall(apiurl, apikey, http.MethodDelete, "service_instances/"+url.PathEscape(instance.ID), query, nil, nil, verbose, opts...)
+}
+
+// MsOSBBind creates a binding of a service instance and returns its credentials
+func MsOSBBind(apiurl, apikey string, instance OSBInstance, bindingID string, parameters map[string]any, verbose bool, opts ...Option) (credentials map[string]any, err error) {
+	if bindingID == "" {
+		return nil, &ValidationError{Payload: "service binding", Field: "binding_id", Reason: "is required"}
+	}
+
+	payload := map[string]any{"service_id": instance.ServiceID, "plan_id": instance.PlanID}
+	if len(parameters) > 0 {
+		payload["parameters"] = parameters
+	}
+	var binding struct {
+		Credentials map[string]any `json:"credentials"`
+	}
+	path := fmt.Sprintf("service_instances/%s/service_bindings/%s", url.PathEscape(instance.ID), url.PathEscape(bindingID))
+	if err := msOSBCall(apiurl, apikey, http.MethodPut, path, nil, payload, &binding, verbose, opts...); err != nil {
+		return nil, err
+	}
+	return binding.Credentials, nil
+}
+
+// MsOSBUnbind deletes a binding of a service instance
+func MsOSBUnbind(apiurl, apikey string, instance OSBInstance, bindingID string, verbose bool, opts ...Option) (err error) {
+	query := url.Values{"service_id": {instance.ServiceID}, "plan_id": {instance.PlanID}}
+	path := fmt.Sprintf("service_instances/%s/service_bindings/%s", url.PathEscape(instance.ID), url.PathEscape(bindingID))
+	return msOSBCall(apiurl, apikey, http.MethodDelete, path, query, nil, nil, verbose, opts...)
+}
+
+// OSBCatalog returns the services of the marketplace, see MsOSBCatalog
+func (c *MsClient) OSBCatalog(opts ...Option) ([]OSBService, error) {
+	verbose, opts := c.options(opts)
+	return MsOSBCatalog(c.URL, c.Token(), verbose, opts...)
+}
+
+// OSBProvision provisions a service instance, see MsOSBProvision
+func (c *MsClient) OSBProvision(instance OSBInstance, opts ...Option) (OSBOperation, error) {
+	verbose, opts := c.options(opts)
+	return MsOSBProvision(c.URL, c.Token(), instance, verbose, opts...)
+}
+
+// OSBDeprovision deletes a service instance, see MsOSBDeprovision
+func (c *MsClient) OSBDeprovision(instance OSBInstance, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return MsOSBDeprovision(c.URL, c.Token(), instance, verbose, opts...)
+}
+
+// OSBBind creates a binding of a service instance, see MsOSBBind
+func (c *MsClient) OSBBind(instance OSBInstance, bindingID string, parameters map[string]any, opts ...Option) (map[string]any, error) {
+	verbose, opts := c.options(opts)
+	return MsOSBBind(c.URL, c.Token(), instance, bindingID, parameters, verbose, opts...)
+}
+
+// OSBUnbind deletes a binding of a service instance, see MsOSBUnbind
+func (c *MsClient) OSBUnbind(instance OSBInstance, bindingID string, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return MsOSBUnbind(c.URL, c.Token(), instance, bindingID, verbose, opts...)
+}
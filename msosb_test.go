@@ -0,0 +1,84 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMsOSB(t *testing.T) {
+	var calls []string
+	var provisioned map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Broker-API-Version") != osbAPIVersion || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("missing headers in %s %s: %v", r.Method, r.URL, r.Header)
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/marketplace/osb/v2/catalog":
+			fmt.Fprint(w, `{"services": [{"id": "svc-1", "name": "postgres", "bindable": true, "plans": [{"id": "plan-s", "name": "small", "free": true}]}]}`)
+		case "PUT /api/marketplace/osb/v2/service_instances/db-1":
+			json.NewDecoder(r.Body).Decode(&provisioned)
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"operation": "op-1"}`)
+		case "PUT /api/marketplace/osb/v2/service_instances/db-1/service_bindings/app-1":
+			fmt.Fprint(w, `{"credentials": {"uri": "postgres://db-1"}}`)
+		case "DELETE /api/marketplace/osb/v2/service_instances/db-1/service_bindings/app-1", "DELETE /api/marketplace/osb/v2/service_instances/db-1":
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	services, err := MsOSBCatalog(server.URL, "token", false)
+	if err != nil || len(services) != 1 {
+		t.Fatalf("MsOSBCatalog() = %+v, %v", services, err)
+	}
+	plan, ok := services[0].Plan("small")
+	if !ok {
+		t.Fatalf("plan small not found in %+v", services[0])
+	}
+
+	instance := OSBInstance{ID: "db-1", ServiceID: services[0].ID, PlanID: plan.ID, Workspace: "retail", Project: "shop", Parameters: map[string]any{"version": "16"}}
+	operation, err := MsOSBProvision(server.URL, "token", instance, false)
+	if err != nil || operation.Operation != "op-1" {
+		t.Errorf("MsOSBProvision() = %+v, %v", operation, err)
+	}
+	if provisioned["organization_guid"] != "retail" || provisioned["space_guid"] != "shop" || provisioned["plan_id"] != "plan-s" {
+		t.Errorf("unexpected provision payload %v", provisioned)
+	}
+
+	credentials, err := MsOSBBind(server.URL, "token", instance, "app-1", nil, false)
+	if err != nil || !reflect.DeepEqual(credentials, map[string]any{"uri": "postgres://db-1"}) {
+		t.Errorf("MsOSBBind() = %v, %v", credentials, err)
+	}
+	if err := MsOSBUnbind(server.URL, "token", instance, "app-1", false); err != nil {
+		t.Errorf("MsOSBUnbind() = %v", err)
+	}
+	if err := MsOSBDeprovision(server.URL, "token", instance, false); err != nil {
+		t.Errorf("MsOSBDeprovision() = %v", err)
+	}
+
+	want := []string{
+		"GET /api/marketplace/osb/v2/catalog?",
+		"PUT /api/marketplace/osb/v2/service_instances/db-1?accepts_incomplete=true",
+		"PUT /api/marketplace/osb/v2/service_instances/db-1/service_bindings/app-1?",
+		"DELETE /api/marketplace/osb/v2/service_instances/db-1/service_bindings/app-1?plan_id=plan-s&service_id=svc-1",
+		"DELETE /api/marketplace/osb/v2/service_instances/db-1?accepts_incomplete=true&plan_id=plan-s&service_id=svc-1",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls\n%v\nwant\n%v", calls, want)
+	}
+
+	var verr *ValidationError
+	if _, err := MsOSBProvision(server.URL, "token", OSBInstance{ID: "db-2"}, false); !errors.As(err, &verr) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
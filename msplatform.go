@@ -0,0 +1,61 @@
+package appapi
+
+import (
+	"slices"
+	"sort"
+)
+
+// The capabilities of a platform type
+const (
+	CapabilityLandingZones = "landingZones"
+	CapabilityQuotas       = "quotas"
+	CapabilityMetering     = "metering"
+	CapabilityReplication  = "replication"
+)
+
+// PlatformType is a type of platforms Meshstack provisions tenants on, e.g. OpenStack or AWS
+type PlatformType struct {
+	Identifier   string   `json:"identifier"`
+	DisplayName  string   `json:"displayName"`
+	Category     string   `json:"category"`
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// Supports reports if platforms of the type have the capability, e.g. CapabilityQuotas
+func (p PlatformType) Supports(capability string) bool {
+	return slices.Contains(p.Capabilities, capability)
+}
+
+// MsListPlatformTypes returns the platform types with their capabilities, so provisioning can depend
+// on what a platform supports instead of its name
+func MsListPlatformTypes(apiurl, apikey string, verbose bool, opts ...Option) (types []PlatformType, err error) {
+	type msPlatformType struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			DisplayName  string          `json:"displayName"`
+			Category     string          `json:"category"`
+			Capabilities map[string]bool `json:"capabilities"`
+		} `json:"spec"`
+	}
+
+	err = msEachPage(apiurl, apikey, "api/meshobjects/meshplatformtypes", msMediaType("meshplatformtype"), "meshPlatformTypes", nil, func(t msPlatformType) error {
+		platformType := PlatformType{Identifier: t.Metadata.Name, DisplayName: t.Spec.DisplayName, Category: t.Spec.Category}
+		for capability, supported := range t.Spec.Capabilities {
+			if supported {
+				platformType.Capabilities = append(platformType.Capabilities, capability)
+			}
+		}
+		sort.Strings(platformType.Capabilities)
+		types = append(types, platformType)
+		return nil
+	}, verbose, opts...)
+	return types, err
+}
+
+// PlatformTypes returns the platform types, see MsListPlatformTypes
+func (c *MsClient) PlatformTypes(opts ...Option) ([]PlatformType, error) {
+	verbose, opts := c.options(opts)
+	return MsListPlatformTypes(c.URL, c.Token(), verbose, opts...)
+}
@@ -0,0 +1,33 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMsListPlatformTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshplatformtypes" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"_embedded": {"meshPlatformTypes": [
+			{"metadata": {"name": "OPENSTACK"}, "spec": {"displayName": "OpenStack", "category": "IAAS", "capabilities": {"quotas": true, "landingZones": true, "metering": false}}},
+			{"metadata": {"name": "CUSTOM"}, "spec": {"displayName": "Custom", "category": "CUSTOM"}}]},
+			"page": {"size": 100, "totalElements": 2, "totalPages": 1, "number": 0}}`)
+	}))
+	defer server.Close()
+
+	types, err := MsListPlatformTypes(server.URL, "token", false)
+	if err != nil || len(types) != 2 {
+		t.Fatalf("MsListPlatformTypes() = %+v, %v", types, err)
+	}
+	if want := []string{CapabilityLandingZones, CapabilityQuotas}; !reflect.DeepEqual(types[0].Capabilities, want) {
+		t.Errorf("got capabilities %v, want %v", types[0].Capabilities, want)
+	}
+	if !types[0].Supports(CapabilityQuotas) || types[0].Supports(CapabilityMetering) || types[1].Supports(CapabilityQuotas) {
+		t.Errorf("unexpected Supports for %+v", types)
+	}
+}
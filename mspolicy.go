@@ -0,0 +1,76 @@
+package appapi
+
+import (
+	"net/url"
+	"slices"
+	"sort"
+)
+
+// PolicyViolation is a violation of a Meshstack policy by a project, e.g. a missing tag or a landing
+// zone which is not permitted for the workspace
+type PolicyViolation struct {
+	Policy    string `json:"policy"`
+	Workspace string `json:"workspace"`
+	Project   string `json:"project"`
+	// Subject is the violating object of the project, e.g. a tenant
+	Subject string `json:"subject,omitempty"`
+	Message string `json:"message" output:"-"`
+}
+
+// MsListPolicyViolations returns the policy violations of the projects of a workspace, only those of
+// one project if project is not empty
+func MsListPolicyViolations(apiurl, apikey, workspace, project string, verbose bool, opts ...Option) (violations []PolicyViolation, err error) {
+	type msViolation struct {
+		Spec struct {
+			PolicyRef struct {
+				Name string `json:"name"`
+			} `json:"policyRef"`
+			WorkspaceIdentifier string `json:"workspaceIdentifier"`
+			ProjectIdentifier   string `json:"projectIdentifier"`
+			SubjectRef          struct {
+				Kind string `json:"kind"`
+				Name string `json:"name"`
+			} `json:"subjectRef"`
+			Message string `json:"message"`
+		} `json:"spec"`
+	}
+
+	query := url.Values{"workspaceIdentifier": {workspace}}
+	if project != "" {
+		query.Set("projectIdentifier", project)
+	}
+	err = msEachPage(apiurl, apikey, "api/meshobjects/meshpolicyviolations", msMediaType("meshpolicyviolation"), "meshPolicyViolations", query, func(v msViolation) error {
+		violation := PolicyViolation{
+			Policy:    v.Spec.PolicyRef.Name,
+			Workspace: v.Spec.WorkspaceIdentifier,
+			Project:   v.Spec.ProjectIdentifier,
+			Message:   v.Spec.Message,
+		}
+		if v.Spec.SubjectRef.Name != "" {
+			violation.Subject = v.Spec.SubjectRef.Kind + " " + v.Spec.SubjectRef.Name
+		}
+		violations = append(violations, violation)
+		return nil
+	}, verbose, opts...)
+	return violations, err
+}
+
+// ViolatingProjects returns the projects with policy violations and their sorted violated policies
+func ViolatingProjects(violations []PolicyViolation) map[string][]string {
+	projects := map[string][]string{}
+	for _, v := range violations {
+		if !slices.Contains(projects[v.Project], v.Policy) {
+			projects[v.Project] = append(projects[v.Project], v.Policy)
+		}
+	}
+	for _, policies := range projects {
+		sort.Strings(policies)
+	}
+	return projects
+}
+
+// PolicyViolations returns the policy violations of a workspace or project, see MsListPolicyViolations
+func (c *MsClient) PolicyViolations(workspace, project string, opts ...Option) ([]PolicyViolation, error) {
+	verbose, opts := c.options(opts)
+	return MsListPolicyViolations(c.URL, c.Token(), workspace, project, verbose, opts...)
+}
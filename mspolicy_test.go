@@ -0,0 +1,39 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMsListPolicyViolations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshpolicyviolations" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if q := r.URL.Query(); q.Get("workspaceIdentifier") != "retail" || q.Has("projectIdentifier") {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"_embedded": {"meshPolicyViolations": [
+			{"spec": {"policyRef": {"name": "require-cost-center"}, "workspaceIdentifier": "retail", "projectIdentifier": "shop", "message": "tag costCenter is missing"}},
+			{"spec": {"policyRef": {"name": "eu-landing-zones"}, "workspaceIdentifier": "retail", "projectIdentifier": "shop", "subjectRef": {"kind": "meshTenant", "name": "shop.aws.us"}}},
+			{"spec": {"policyRef": {"name": "require-cost-center"}, "workspaceIdentifier": "retail", "projectIdentifier": "web"}}]},
+			"page": {"size": 100, "totalElements": 3, "totalPages": 1, "number": 0}}`)
+	}))
+	defer server.Close()
+
+	violations, err := MsListPolicyViolations(server.URL, "token", "retail", "", false)
+	if err != nil || len(violations) != 3 {
+		t.Fatalf("MsListPolicyViolations() = %+v, %v", violations, err)
+	}
+	if violations[1].Subject != "meshTenant shop.aws.us" || violations[0].Message != "tag costCenter is missing" {
+		t.Errorf("unexpected violations %+v", violations)
+	}
+
+	want := map[string][]string{"shop": {"eu-landing-zones", "require-cost-center"}, "web": {"require-cost-center"}}
+	if got := ViolatingProjects(violations); !reflect.DeepEqual(got, want) {
+		t.Errorf("ViolatingProjects() = %v, want %v", got, want)
+	}
+}
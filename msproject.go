@@ -0,0 +1,161 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Project is a meshProject of a workspace
+type Project struct {
+	Workspace   string              `json:"workspace"`
+	Identifier  string              `json:"identifier"`
+	DisplayName string              `json:"displayName"`
+	Tags        map[string][]string `json:"tags,omitempty" output:"-"`
+}
+
+// Tenant is a meshTenant, the account of a project on a platform, e.g. an OpenStack project
+type Tenant struct {
+	Workspace string `json:"workspace"`
+	Project   string `json:"project"`
+	Platform  string `json:"platform"`
+	// LocalID is the ID of the tenant on the platform, empty until the tenant is replicated
+	LocalID string `json:"localId,omitempty"`
+}
+
+// ID returns the identifier of the tenant, workspace.project.platform
+func (t Tenant) ID() string {
+	return t.Workspace + "." + t.Project + "." + t.Platform
+}
+
+// ErrIdentifierTaken is returned by MsCheckProjectIdentifier if the project already exists
+var ErrIdentifierTaken = errors.New("identifier is already taken")
+
+// MsListProjects returns the projects of a workspace
+func MsListProjects(apiurl, apikey, workspace string, verbose bool, opts ...Option) (projects []Project, err error) {
+	type msProject struct {
+		Metadata struct {
+			Name             string              `json:"name"`
+			OwnedByWorkspace string              `json:"ownedByWorkspace"`
+			Tags             map[string][]string `json:"tags"`
+		} `json:"metadata"`
+		Spec struct {
+			DisplayName string `json:"displayName"`
+		} `json:"spec"`
+	}
+
+	query := url.Values{"workspaceIdentifier": {workspace}}
+	err = msEachPage(apiurl, apikey, "api/meshobjects/meshprojects", msMediaType("meshproject"), "meshProjects", query, func(p msProject) error {
+		projects = append(projects, Project{
+			Workspace:   p.Metadata.OwnedByWorkspace,
+			Identifier:  p.Metadata.Name,
+			DisplayName: p.Spec.DisplayName,
+			Tags:        p.Metadata.Tags,
+		})
+		return nil
+	}, verbose, opts...)
+	return projects, err
+}
+
+// MsListTenants returns the tenants of a workspace. The query can filter the tenants, e.g.
+// projectIdentifier or platformIdentifier.
+func MsListTenants(apiurl, apikey, workspace string, query url.Values, verbose bool, opts ...Option) (tenants []Tenant, err error) {
+	type msTenant struct {
+		Metadata struct {
+			OwnedByWorkspace   string `json:"ownedByWorkspace"`
+			OwnedByProject     string `json:"ownedByProject"`
+			PlatformIdentifier string `json:"platformIdentifier"`
+		} `json:"metadata"`
+		Spec struct {
+			LocalID string `json:"localId"`
+		} `json:"spec"`
+	}
+
+	q := url.Values{"workspaceIdentifier": {workspace}}
+	for k, v := range query {
+		q[k] = v
+	}
+	err = msEachPage(apiurl, apikey, "api/meshobjects/meshtenants", msMediaType("meshtenant"), "meshTenants", q, func(t msTenant) error {
+		tenants = append(tenants, Tenant{
+			Workspace: t.Metadata.OwnedByWorkspace,
+			Project:   t.Metadata.OwnedByProject,
+			Platform:  t.Metadata.PlatformIdentifier,
+			LocalID:   t.Spec.LocalID,
+		})
+		return nil
+	}, verbose, opts...)
+	return tenants, err
+}
+
+// MsListPlatformTenants returns the tenants of all projects of a workspace on a platform, e.g. all
+// OpenStack tenants whose systems need a SUMA proxy
+func MsListPlatformTenants(apiurl, apikey, workspace, platform string, verbose bool, opts ...Option) (tenants []Tenant, err error) {
+	all, err := MsListTenants(apiurl, apikey, workspace, url.Values{"platformIdentifier": {platform}}, verbose, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// older Meshstack versions ignore the filter
+	for _, t := range all {
+		if t.Platform == platform {
+			tenants = append(tenants, t)
+		}
+	}
+	return tenants, nil
+}
+
+// MsCheckProjectIdentifier checks a project identifier before the project is created: the workspace
+// and project identifiers must follow the rules of Meshstack, see ValidateMsIdentifier, and the project
+// must not exist yet, otherwise ErrIdentifierTaken is returned
+func MsCheckProjectIdentifier(apiurl, apikey, workspace, project string, verbose bool, opts ...Option) (err error) {
+	if err := ValidateMsIdentifier("workspace", workspace); err != nil {
+		return err
+	}
+	if err := ValidateMsIdentifier("project", project); err != nil {
+		return err
+	}
+
+	err = msGet(apiurl, apikey, "api/meshobjects/meshprojects/"+url.PathEscape(workspace+"."+project), msMediaType("meshproject"), nil, nil, verbose, opts...)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+	return fmt.Errorf("project %s of workspace %s: %w", project, workspace, ErrIdentifierTaken)
+}
+
+// MsDeleteProject deletes a project of a workspace, Meshstack refuses it while the project has tenants
+func MsDeleteProject(apiurl, apikey, workspace, project string, verbose bool, opts ...Option) (err error) {
+	return msCall(apiurl, apikey, http.MethodDelete, "api/meshobjects/meshprojects/"+url.PathEscape(workspace+"."+project), msMediaType("meshproject"), nil, nil, verbose, opts...)
+}
+
+// Projects returns the projects of a workspace, see MsListProjects
+func (c *MsClient) Projects(workspace string, opts ...Option) ([]Project, error) {
+	verbose, opts := c.options(opts)
+	return MsListProjects(c.URL, c.Token(), workspace, verbose, opts...)
+}
+
+// Tenants returns the tenants of a workspace, see MsListTenants
+func (c *MsClient) Tenants(workspace string, query url.Values, opts ...Option) ([]Tenant, error) {
+	verbose, opts := c.options(opts)
+	return MsListTenants(c.URL, c.Token(), workspace, query, verbose, opts...)
+}
+
+// PlatformTenants returns the tenants of a workspace on a platform, see MsListPlatformTenants
+func (c *MsClient) PlatformTenants(workspace, platform string, opts ...Option) ([]Tenant, error) {
+	verbose, opts := c.options(opts)
+	return MsListPlatformTenants(c.URL, c.Token(), workspace, platform, verbose, opts...)
+}
+
+// CheckProjectIdentifier checks if a project can be created, see MsCheckProjectIdentifier
+func (c *MsClient) CheckProjectIdentifier(workspace, project string, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return MsCheckProjectIdentifier(c.URL, c.Token(), workspace, project, verbose, opts...)
+}
+
+// DeleteProject deletes a project, see MsDeleteProject
+func (c *MsClient) DeleteProject(workspace, project string, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return MsDeleteProject(c.URL, c.Token(), workspace, project, verbose, opts...)
+}
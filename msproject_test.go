@@ -0,0 +1,67 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMsListPlatformTenants(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshtenants" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("workspaceIdentifier") != "retail" || q.Get("platformIdentifier") != "openstack.eu" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		// the filter is ignored, as by older Meshstack versions
+		fmt.Fprint(w, `{"_embedded": {"meshTenants": [
+			{"metadata": {"ownedByWorkspace": "retail", "ownedByProject": "shop", "platformIdentifier": "openstack.eu"}, "spec": {"localId": "abc"}},
+			{"metadata": {"ownedByWorkspace": "retail", "ownedByProject": "shop", "platformIdentifier": "aws.global"}, "spec": {"localId": "123"}},
+			{"metadata": {"ownedByWorkspace": "retail", "ownedByProject": "web", "platformIdentifier": "openstack.eu"}, "spec": {}}]},
+			"page": {"size": 100, "totalElements": 3, "totalPages": 1, "number": 0}}`)
+	}))
+	defer server.Close()
+
+	tenants, err := MsListPlatformTenants(server.URL, "token", "retail", "openstack.eu", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Tenant{
+		{Workspace: "retail", Project: "shop", Platform: "openstack.eu", LocalID: "abc"},
+		{Workspace: "retail", Project: "web", Platform: "openstack.eu"},
+	}
+	if !reflect.DeepEqual(tenants, want) {
+		t.Errorf("got %+v, want %+v", tenants, want)
+	}
+}
+
+func TestMsCheckProjectIdentifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshprojects/retail.shop" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"metadata": {"name": "shop", "ownedByWorkspace": "retail"}}`)
+	}))
+	defer server.Close()
+
+	if err := MsCheckProjectIdentifier(server.URL, "token", "retail", "web", false); err != nil {
+		t.Errorf("unexpected error for a free identifier: %v", err)
+	}
+	if err := MsCheckProjectIdentifier(server.URL, "token", "retail", "shop", false); !errors.Is(err, ErrIdentifierTaken) {
+		t.Errorf("expected ErrIdentifierTaken, got %v", err)
+	}
+
+	for _, project := range []string{"Shop", "-shop", "shop_1", "", strings.Repeat("a", 33)} {
+		var verr *ValidationError
+		if err := MsCheckProjectIdentifier(server.URL, "token", "retail", project, false); !errors.As(err, &verr) || verr.Payload != "project" {
+			t.Errorf("expected a validation error for %q, got %v", project, err)
+		}
+	}
+}
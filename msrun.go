@@ -0,0 +1,160 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// The states of a building block run
+const (
+	RunInProgress = "IN_PROGRESS"
+	RunSucceeded  = "SUCCEEDED"
+	RunFailed     = "FAILED"
+	RunAborted    = "ABORTED"
+)
+
+// ErrRunNotInProgress is returned for an operation on a run which has already finished
+var ErrRunNotInProgress = errors.New("building block run is not in progress")
+
+// ErrBuildingBlockNotFailed is returned by MsRetryBuildingBlock for a building block which did not fail
+var ErrBuildingBlockNotFailed = errors.New("building block has not failed")
+
+// BuildingBlockRun is one run of a building block, e.g. the deployment after an input changed
+type BuildingBlockRun struct {
+	UUID              string    `json:"uuid"`
+	BuildingBlockUUID string    `json:"buildingBlockUuid" output:"Block"`
+	RunNumber         int       `json:"runNumber" output:"Run"`
+	Status            string    `json:"status"`
+	CreatedOn         time.Time `json:"createdOn" output:"Created"`
+}
+
+// msRun is a meshBuildingBlockRun of the Meshstack API
+type msRun struct {
+	Metadata struct {
+		UUID      string `json:"uuid"`
+		CreatedOn string `json:"createdOn"`
+	} `json:"metadata"`
+	Spec struct {
+		RunNumber     int `json:"runNumber"`
+		BuildingBlock struct {
+			UUID string `json:"uuid"`
+		} `json:"buildingBlock"`
+	} `json:"spec"`
+	Status struct {
+		Status string `json:"status"`
+	} `json:"status"`
+}
+
+func (r msRun) run() BuildingBlockRun {
+	return BuildingBlockRun{
+		UUID:              r.Metadata.UUID,
+		BuildingBlockUUID: r.Spec.BuildingBlock.UUID,
+		RunNumber:         r.Spec.RunNumber,
+		Status:            r.Status.Status,
+		CreatedOn:         parseSumaTime(r.Metadata.CreatedOn),
+	}
+}
+
+// MsListBuildingBlockRuns lists the runs of a building block, newest first. A statusFilter, e.g.
+// RunFailed, only returns the runs with the status, an empty filter returns all runs. The pages of the
+// list are requested one by one.
+func MsListBuildingBlockRuns(apiurl, apikey, blockUUID, statusFilter string, verbose bool, opts ...Option) (runs []BuildingBlockRun, err error) {
+	query := url.Values{"buildingBlockUuid": {blockUUID}, "sort": {"runNumber,desc"}}
+	if statusFilter != "" {
+		query.Set("status", statusFilter)
+	}
+
+	err = msEachPage(apiurl, apikey, "api/meshobjects/meshbuildingblockruns", msMediaType("meshbuildingblockrun"), "meshBuildingBlockRuns", query, func(r msRun) error {
+		// the filter is checked again, older Meshstack versions ignore it
+		if run := r.run(); statusFilter == "" || run.Status == statusFilter {
+			runs = append(runs, run)
+		}
+		return nil
+	}, verbose, opts...)
+	return runs, err
+}
+
+// MsLastFailedRun returns the latest failed run of a building block, e.g. to link it in an incident
+// ticket. It returns ErrNotFound if no run failed.
+func MsLastFailedRun(apiurl, apikey, blockUUID string, verbose bool, opts ...Option) (run BuildingBlockRun, err error) {
+	runs, err := MsListBuildingBlockRuns(apiurl, apikey, blockUUID, RunFailed, verbose, opts...)
+	if err != nil {
+		return run, err
+	}
+	for _, r := range runs {
+		if r.RunNumber > run.RunNumber {
+			run = r
+		}
+	}
+	if run.UUID == "" {
+		return run, fmt.Errorf("failed run of building block %s: %w", blockUUID, ErrNotFound)
+	}
+	return run, nil
+}
+
+// MsCancelBuildingBlockRun aborts a run which is in progress, e.g. a stuck provisioning before it is
+// retried. It returns ErrRunNotInProgress if the run has finished and ErrUnsupportedByServer if the
+// Meshstack cannot cancel runs.
+func MsCancelBuildingBlockRun(apiurl, apikey, runUUID string, verbose bool, opts ...Option) (err error) {
+	log, err := MsGetRunLog(apiurl, apikey, runUUID, verbose, opts...)
+	if err != nil {
+		return err
+	}
+	if log.Done() {
+		return fmt.Errorf("run %s is %s: %w", runUUID, log.Status, ErrRunNotInProgress)
+	}
+	return msCall(apiurl, apikey, http.MethodPost, "api/meshobjects/meshbuildingblockruns/"+runUUID+"/cancel", msMediaType("meshbuildingblockrun"), nil, nil, verbose, opts...)
+}
+
+// MsRetryBuildingBlock starts a new run of a failed building block and returns the UUID of the run,
+// e.g. to heal transient platform failures. It returns ErrBuildingBlockNotFailed if the building block
+// did not fail.
+func MsRetryBuildingBlock(apiurl, apikey, blockUUID string, verbose bool, opts ...Option) (runUUID string, err error) {
+	status, err := MsGetBuildingBlock(apiurl, apikey, blockUUID, verbose, opts...)
+	if err != nil {
+		return "", err
+	}
+	if status != RunFailed {
+		return "", fmt.Errorf("building block %s is %s: %w", blockUUID, status, ErrBuildingBlockNotFailed)
+	}
+
+	var run msRun
+	if err := msCall(apiurl, apikey, http.MethodPost, "api/meshobjects/meshbuildingblocks/"+blockUUID+"/runs", msMediaType("meshbuildingblockrun"), nil, &run, verbose, opts...); err != nil {
+		return "", err
+	}
+	if run.Metadata.UUID == "" {
+		return "", fmt.Errorf("retry of building block %s returned no run", blockUUID)
+	}
+	if verbose {
+		logDebugf("MSAPI MsRetryBuildingBlock: started run %d (%s) of building block %s", run.Spec.RunNumber, run.Metadata.UUID, blockUUID)
+	}
+	return run.Metadata.UUID, nil
+}
+
+// ListBuildingBlockRuns lists the runs of a building block, see MsListBuildingBlockRuns
+func (c *MsClient) ListBuildingBlockRuns(blockUUID, statusFilter string, opts ...Option) ([]BuildingBlockRun, error) {
+	verbose, opts := c.options(opts)
+	return MsListBuildingBlockRuns(c.URL, c.Token(), blockUUID, statusFilter, verbose, opts...)
+}
+
+// LastFailedRun returns the latest failed run of a building block, see MsLastFailedRun
+func (c *MsClient) LastFailedRun(blockUUID string, opts ...Option) (BuildingBlockRun, error) {
+	verbose, opts := c.options(opts)
+	return MsLastFailedRun(c.URL, c.Token(), blockUUID, verbose, opts...)
+}
+
+// CancelBuildingBlockRun aborts a run which is in progress, see MsCancelBuildingBlockRun
+func (c *MsClient) CancelBuildingBlockRun(runUUID string, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return MsCancelBuildingBlockRun(c.URL, c.Token(), runUUID, verbose, opts...)
+}
+
+// RetryBuildingBlock starts a new run of a failed building block, see MsRetryBuildingBlock
+func (c *MsClient) RetryBuildingBlock(blockUUID string, opts ...Option) (string, error) {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheMsBuildingBlock + blockUUID)
+	return MsRetryBuildingBlock(c.URL, c.Token(), blockUUID, verbose, opts...)
+}
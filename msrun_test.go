@@ -0,0 +1,120 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMsListBuildingBlockRuns(t *testing.T) {
+	const block = "0b4a0e1c-3b6e-4f2a-9d7e-5c8f1a2b3c4d"
+	pages := []string{
+		`{"_embedded": {"meshBuildingBlockRuns": [
+			{"metadata": {"uuid": "run-4", "createdOn": "2024-06-04T08:00:00Z"}, "spec": {"runNumber": 4, "buildingBlock": {"uuid": "` + block + `"}}, "status": {"status": "SUCCEEDED"}},
+			{"metadata": {"uuid": "run-3", "createdOn": "2024-06-03T08:00:00Z"}, "spec": {"runNumber": 3, "buildingBlock": {"uuid": "` + block + `"}}, "status": {"status": "FAILED"}}
+		]}, "page": {"size": 2, "totalElements": 3, "totalPages": 2, "number": 0}}`,
+		`{"_embedded": {"meshBuildingBlockRuns": [
+			{"metadata": {"uuid": "run-1", "createdOn": "2024-06-01T08:00:00Z"}, "spec": {"runNumber": 1, "buildingBlock": {"uuid": "` + block + `"}}, "status": {"status": "FAILED"}}
+		]}, "page": {"size": 2, "totalElements": 3, "totalPages": 2, "number": 1}}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshbuildingblockruns" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("buildingBlockUuid") != block {
+			t.Errorf("buildingBlockUuid = %s", r.URL.Query().Get("buildingBlockUuid"))
+		}
+		var page int
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		fmt.Fprint(w, pages[page])
+	}))
+	defer server.Close()
+
+	runs, err := MsListBuildingBlockRuns(server.URL, "token", block, "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 3 || runs[0].UUID != "run-4" || runs[2].CreatedOn.Day() != 1 || runs[1].BuildingBlockUUID != block {
+		t.Errorf("unexpected runs %+v", runs)
+	}
+
+	// the server ignores the filter like older Meshstack versions
+	failed, err := MsListBuildingBlockRuns(server.URL, "token", block, RunFailed, false)
+	if err != nil || len(failed) != 2 {
+		t.Errorf("expected 2 failed runs, got %+v, %v", failed, err)
+	}
+
+	last, err := MsLastFailedRun(server.URL, "token", block, false)
+	if err != nil || last.UUID != "run-3" {
+		t.Errorf("MsLastFailedRun() = %+v, %v, want run-3", last, err)
+	}
+}
+
+func TestMsGetNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	var result map[string]any
+	err := msGet(server.URL, "token", "api/meshobjects/meshworkspaces/missing", msMediaType("meshworkspace"), nil, &result, false)
+	if !errors.Is(err, ErrNotFound) || RequestIDOf(err) == "" {
+		t.Errorf("expected ErrNotFound with a request ID, got %v", err)
+	}
+}
+
+func TestMsCancelBuildingBlockRun(t *testing.T) {
+	status := map[string]string{"run-1": RunInProgress, "run-2": RunSucceeded, "run-3": RunInProgress}
+	var canceled []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblockruns/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"metadata": {"uuid": %q}, "status": {"status": %q}}`, r.PathValue("uuid"), status[r.PathValue("uuid")])
+	})
+	mux.HandleFunc("POST /api/meshobjects/meshbuildingblockruns/{uuid}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("uuid") == "run-3" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		canceled = append(canceled, r.PathValue("uuid"))
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	if err := MsCancelBuildingBlockRun(server.URL, "token", "run-1", false); err != nil || len(canceled) != 1 {
+		t.Errorf("MsCancelBuildingBlockRun() = %v, canceled %v", err, canceled)
+	}
+	if err := MsCancelBuildingBlockRun(server.URL, "token", "run-2", false); !errors.Is(err, ErrRunNotInProgress) {
+		t.Errorf("expected ErrRunNotInProgress for a finished run, got %v", err)
+	}
+	if err := MsCancelBuildingBlockRun(server.URL, "token", "run-3", false); !errors.Is(err, ErrUnsupportedByServer) {
+		t.Errorf("expected ErrUnsupportedByServer, got %v", err)
+	}
+}
+
+func TestMsRetryBuildingBlock(t *testing.T) {
+	status := map[string]string{"bb-1": RunFailed, "bb-2": RunSucceeded}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblocks/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"metadata": {"uuid": %q}, "status": %q}`, r.PathValue("uuid"), status[r.PathValue("uuid")])
+	})
+	mux.HandleFunc("POST /api/meshobjects/meshbuildingblocks/{uuid}/runs", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("uuid") != "bb-1" {
+			t.Errorf("unexpected retry of %s", r.PathValue("uuid"))
+		}
+		fmt.Fprint(w, `{"metadata": {"uuid": "run-5"}, "spec": {"runNumber": 5, "buildingBlock": {"uuid": "bb-1"}}, "status": {"status": "IN_PROGRESS"}}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	if run, err := MsRetryBuildingBlock(server.URL, "token", "bb-1", false); err != nil || run != "run-5" {
+		t.Errorf("MsRetryBuildingBlock() = %q, %v", run, err)
+	}
+	if _, err := MsRetryBuildingBlock(server.URL, "token", "bb-2", false); !errors.Is(err, ErrBuildingBlockNotFailed) {
+		t.Errorf("expected ErrBuildingBlockNotFailed, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package appapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// RunStep is a step of a building block run with its log messages
+type RunStep struct {
+	Name          string `json:"displayName"`
+	Status        string `json:"status"`
+	UserMessage   string `json:"userMessage"`
+	SystemMessage string `json:"systemMessage"`
+}
+
+// RunLog is the state of a building block run with the logs of its steps
+type RunLog struct {
+	UUID   string
+	Status string
+	Steps  []RunStep
+}
+
+// Done reports if the run is finished
+func (l RunLog) Done() bool {
+	return l.Status != "" && l.Status != RunInProgress
+}
+
+// MsGetRunLog returns the steps and their log messages of a building block run
+func MsGetRunLog(apiurl, apikey, runUUID string, verbose bool, opts ...Option) (log RunLog, err error) {
+	var run struct {
+		Metadata struct {
+			UUID string `json:"uuid"`
+		} `json:"metadata"`
+		Status struct {
+			Status string    `json:"status"`
+			Steps  []RunStep `json:"steps"`
+		} `json:"status"`
+	}
+	if err := msGet(apiurl, apikey, "api/meshobjects/meshbuildingblockruns/"+runUUID, msMediaType("meshbuildingblockrun"), nil, &run, verbose, opts...); err != nil {
+		return log, err
+	}
+	return RunLog{UUID: run.Metadata.UUID, Status: run.Status.Status, Steps: run.Status.Steps}, nil
+}
+
+// runLogWriter writes the parts of the step logs which were not written yet
+type runLogWriter struct {
+	w       io.Writer
+	status  []string
+	written []int
+	// partial is set if the last message did not end with a newline
+	partial bool
+	// continued is set if a newline was added after a partial message, the newline at the start of
+	// its continuation is dropped then
+	continued bool
+}
+
+func (lw *runLogWriter) write(log RunLog) error {
+	for i, step := range log.Steps {
+		if i == len(lw.status) {
+			lw.status = append(lw.status, "")
+			lw.written = append(lw.written, 0)
+		}
+		if step.Status != lw.status[i] {
+			header := fmt.Sprintf("==> %s: %s\n", step.Name, step.Status)
+			if lw.partial {
+				header = "\n" + header
+				lw.partial, lw.continued = false, true
+			}
+			if _, err := io.WriteString(lw.w, header); err != nil {
+				return err
+			}
+			lw.status[i] = step.Status
+		}
+
+		// the messages grow while the step runs, only the new part is written
+		message := step.UserMessage
+		if step.SystemMessage != "" {
+			message = strings.TrimPrefix(strings.TrimSuffix(message, "\n")+"\n"+step.SystemMessage, "\n")
+		}
+		if len(message) < lw.written[i] {
+			lw.written[i] = 0
+		}
+		rest := message[lw.written[i]:]
+		lw.written[i] = len(message)
+		if lw.continued && rest != "" {
+			rest = strings.TrimPrefix(rest, "\n")
+			lw.continued = false
+		}
+		if rest == "" {
+			continue
+		}
+		if !strings.HasSuffix(rest, "\n") && log.Done() {
+			rest += "\n"
+		}
+		if _, err := io.WriteString(lw.w, rest); err != nil {
+			return err
+		}
+		lw.partial = !strings.HasSuffix(rest, "\n")
+	}
+	return nil
+}
+
+// MsStreamRunLogs writes the step logs of a building block run to w and follows the run every
+// interval until it is finished or the context is canceled, e.g. to show the Meshstack errors in the
+// output of a CI job. It returns the final status of the run.
+func MsStreamRunLogs(ctx context.Context, apiurl, apikey, runUUID string, w io.Writer, interval time.Duration, verbose bool, opts ...Option) (status string, err error) {
+	lw := &runLogWriter{w: w}
+
+	ticker := newCallOptions(verbose, opts).clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		log, err := MsGetRunLog(apiurl, apikey, runUUID, verbose, opts...)
+		if err != nil {
+			return status, err
+		}
+		status = log.Status
+		if err := lw.write(log); err != nil {
+			return status, err
+		}
+		if log.Done() {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.Chan():
+		}
+	}
+}
+
+// StreamRunLogs writes the step logs of a building block run to w until it is finished, see
+// MsStreamRunLogs
+func (c *MsClient) StreamRunLogs(ctx context.Context, runUUID string, w io.Writer, interval time.Duration, opts ...Option) (string, error) {
+	verbose, opts := c.options(opts)
+	return MsStreamRunLogs(ctx, c.URL, c.Token(), runUUID, w, interval, verbose, opts...)
+}
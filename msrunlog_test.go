@@ -0,0 +1,53 @@
+package appapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMsStreamRunLogs(t *testing.T) {
+	polls := []string{
+		`{"metadata": {"uuid": "run-1"}, "status": {"status": "IN_PROGRESS", "steps": [
+			{"displayName": "terraform init", "status": "IN_PROGRESS", "userMessage": "Initializing\n"}
+		]}}`,
+		`{"metadata": {"uuid": "run-1"}, "status": {"status": "IN_PROGRESS", "steps": [
+			{"displayName": "terraform init", "status": "SUCCEEDED", "userMessage": "Initializing\nDone\n"},
+			{"displayName": "terraform apply", "status": "IN_PROGRESS", "userMessage": "Applying"}
+		]}}`,
+		`{"metadata": {"uuid": "run-1"}, "status": {"status": "FAILED", "steps": [
+			{"displayName": "terraform init", "status": "SUCCEEDED", "userMessage": "Initializing\nDone\n"},
+			{"displayName": "terraform apply", "status": "FAILED", "userMessage": "Applying", "systemMessage": "Error: quota exceeded"}
+		]}}`,
+	}
+	poll := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshbuildingblockruns/run-1" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		fmt.Fprint(w, polls[min(poll, len(polls)-1)])
+		poll++
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	status, err := MsStreamRunLogs(context.Background(), server.URL, "token", "run-1", &out, time.Millisecond, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != RunFailed {
+		t.Errorf("got status %s, want FAILED", status)
+	}
+
+	want := "==> terraform init: IN_PROGRESS\nInitializing\n" +
+		"==> terraform init: SUCCEEDED\nDone\n" +
+		"==> terraform apply: IN_PROGRESS\nApplying\n" +
+		"==> terraform apply: FAILED\nError: quota exceeded\n"
+	if out.String() != want {
+		t.Errorf("got log\n%q\nwant\n%q", out.String(), want)
+	}
+}
@@ -0,0 +1,157 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The kinds of meshObjects with tags
+const (
+	TagTargetWorkspace = "meshWorkspace"
+	TagTargetProject   = "meshProject"
+)
+
+// The value types of tags
+const (
+	TagString       = "string"
+	TagEmail        = "email"
+	TagInteger      = "integer"
+	TagSingleSelect = "singleSelect"
+	TagMultiSelect  = "multiSelect"
+)
+
+// TagDefinition defines a tag of workspaces or projects
+type TagDefinition struct {
+	Key        string   `json:"key"`
+	TargetKind string   `json:"targetKind" output:"Target"`
+	Type       string   `json:"type"`
+	Mandatory  bool     `json:"mandatory"`
+	Options    []string `json:"options,omitempty" output:"-"`
+	// Pattern is the regular expression string values must match, empty for any value
+	Pattern string `json:"pattern,omitempty" output:"-"`
+}
+
+// TagSchema are the tag definitions of a kind of meshObjects
+type TagSchema []TagDefinition
+
+// MsGetTagSchema returns the tag definitions of a kind of meshObjects, e.g. TagTargetProject
+func MsGetTagSchema(apiurl, apikey, targetKind string, verbose bool, opts ...Option) (schema TagSchema, err error) {
+	type msTagDefinition struct {
+		Spec struct {
+			TargetKind string `json:"targetKind"`
+			Key        string `json:"key"`
+			Mandatory  bool   `json:"mandatory"`
+			// the value type is an object with one key, the type, e.g. {"singleSelect": {"options": [...]}}
+			ValueType map[string]struct {
+				Options []string `json:"options"`
+				Regex   string   `json:"regex"`
+			} `json:"valueType"`
+		} `json:"spec"`
+	}
+
+	err = msEachPage(apiurl, apikey, "api/meshobjects/meshtagdefinitions", msMediaType("meshtagdefinition"), "meshTagDefinitions", nil, func(d msTagDefinition) error {
+		if d.Spec.TargetKind != targetKind {
+			return nil
+		}
+		def := TagDefinition{Key: d.Spec.Key, TargetKind: d.Spec.TargetKind, Mandatory: d.Spec.Mandatory}
+		for valueType, v := range d.Spec.ValueType {
+			def.Type, def.Options, def.Pattern = valueType, v.Options, v.Regex
+		}
+		schema = append(schema, def)
+		return nil
+	}, verbose, opts...)
+	sort.Slice(schema, func(i, j int) bool { return schema[i].Key < schema[j].Key })
+	return schema, err
+}
+
+// Validate checks tags against the schema: mandatory tags are set, only defined tags are used and the
+// values match the type, options and pattern of their definition. All problems are returned as
+// joined ValidationErrors.
+func (s TagSchema) Validate(tags map[string][]string) error {
+	var errs []error
+	invalid := func(key, reason string) {
+		errs = append(errs, &ValidationError{Payload: "tags", Field: key, Reason: reason})
+	}
+
+	defined := make(map[string]TagDefinition, len(s))
+	for _, def := range s {
+		defined[def.Key] = def
+		if def.Mandatory && len(tags[def.Key]) == 0 {
+			invalid(def.Key, "is mandatory")
+		}
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		values := tags[key]
+		def, ok := defined[key]
+		if !ok {
+			invalid(key, "is not defined")
+			continue
+		}
+		if def.Type != TagMultiSelect && len(values) > 1 {
+			invalid(key, fmt.Sprintf("must have one value, got %d", len(values)))
+			continue
+		}
+		for _, value := range values {
+			if reason := checkTagValue(def, value); reason != "" {
+				invalid(key, reason)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkTagValue returns why the value does not match the definition, or an empty string
+func checkTagValue(def TagDefinition, value string) string {
+	switch def.Type {
+	case TagSingleSelect, TagMultiSelect:
+		if !slices.Contains(def.Options, value) {
+			return fmt.Sprintf("%q is not one of %s", value, strings.Join(def.Options, ", "))
+		}
+	case TagInteger:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Sprintf("%q is not an integer", value)
+		}
+	case TagEmail:
+		if at := strings.Index(value, "@"); at < 1 || at == len(value)-1 {
+			return fmt.Sprintf("%q is not an email address", value)
+		}
+	}
+	if def.Pattern != "" {
+		re, err := regexp.Compile(def.Pattern)
+		if err != nil {
+			return fmt.Sprintf("has the invalid pattern %q", def.Pattern)
+		}
+		if !re.MatchString(value) {
+			return fmt.Sprintf("%q does not match %s", value, def.Pattern)
+		}
+	}
+	return ""
+}
+
+// TagSchema returns the tag definitions of a kind of meshObjects, see MsGetTagSchema
+func (c *MsClient) TagSchema(targetKind string, opts ...Option) (TagSchema, error) {
+	verbose, opts := c.options(opts)
+	return MsGetTagSchema(c.URL, c.Token(), targetKind, verbose, opts...)
+}
+
+// ValidateTags checks the tags of a workspace or project before it is created or updated, see
+// TagSchema.Validate
+func (c *MsClient) ValidateTags(targetKind string, tags map[string][]string, opts ...Option) error {
+	schema, err := c.TagSchema(targetKind, opts...)
+	if err != nil {
+		return err
+	}
+	return schema.Validate(tags)
+}
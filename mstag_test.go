@@ -0,0 +1,48 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMsGetTagSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshtagdefinitions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"_embedded": {"meshTagDefinitions": [
+			{"spec": {"targetKind": "meshProject", "key": "environment", "mandatory": true, "valueType": {"singleSelect": {"options": ["dev", "prod"]}}}},
+			{"spec": {"targetKind": "meshProject", "key": "costCenter", "mandatory": true, "valueType": {"string": {"regex": "^[0-9]{4}$"}}}},
+			{"spec": {"targetKind": "meshProject", "key": "owners", "valueType": {"email": {}}}},
+			{"spec": {"targetKind": "meshWorkspace", "key": "department", "mandatory": true, "valueType": {"string": {}}}}]},
+			"page": {"size": 100, "totalElements": 4, "totalPages": 1, "number": 0}}`)
+	}))
+	defer server.Close()
+
+	schema, err := MsGetTagSchema(server.URL, "token", TagTargetProject, false)
+	if err != nil || len(schema) != 3 {
+		t.Fatalf("MsGetTagSchema() = %+v, %v", schema, err)
+	}
+	if schema[1].Key != "environment" || schema[1].Type != TagSingleSelect || len(schema[1].Options) != 2 {
+		t.Errorf("unexpected definition %+v", schema[1])
+	}
+
+	if err := schema.Validate(map[string][]string{"environment": {"prod"}, "costCenter": {"4711"}, "owners": {"jdoe@example.com"}}); err != nil {
+		t.Errorf("unexpected error for valid tags: %v", err)
+	}
+
+	err = schema.Validate(map[string][]string{"environment": {"staging"}, "owners": {"jdoe"}, "team": {"a"}})
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var verr *ValidationError
+		if errors.As(e, &verr) {
+			fields = append(fields, verr.Field)
+		}
+	}
+	if want := "[costCenter environment owners team]"; fmt.Sprint(fields) != want {
+		t.Errorf("got validation errors for %v, want %s: %v", fields, want, err)
+	}
+}
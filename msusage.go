@@ -0,0 +1,107 @@
+package appapi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// usagePeriodPattern matches the month of a usage report, e.g. 2024-05
+var usagePeriodPattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
+
+// UsageLineItem is one metered resource of a tenant usage report, e.g. the vCPU hours of a flavor
+type UsageLineItem struct {
+	Description string  `json:"description"`
+	Usage       float64 `json:"usage"`
+	Unit        string  `json:"unit"`
+	NetAmount   float64 `json:"netAmount" output:"Amount"`
+}
+
+// TenantUsageReport is the resource usage of a tenant in a month as metered by Meshstack
+type TenantUsageReport struct {
+	UUID      string          `json:"uuid" output:"-"`
+	Workspace string          `json:"workspace"`
+	Project   string          `json:"project"`
+	Platform  string          `json:"platform"`
+	LocalID   string          `json:"localId,omitempty" output:"Tenant"`
+	Period    string          `json:"period"`
+	NetAmount float64         `json:"netAmount" output:"Amount"`
+	Currency  string          `json:"currency"`
+	LineItems []UsageLineItem `json:"lineItems,omitempty" output:"-"`
+}
+
+// Tenant returns the tenant of the report
+func (r TenantUsageReport) Tenant() Tenant {
+	return Tenant{Workspace: r.Workspace, Project: r.Project, Platform: r.Platform, LocalID: r.LocalID}
+}
+
+// OverBudget reports if the net amount of the report exceeds the budget
+func (r TenantUsageReport) OverBudget(budget float64) bool {
+	return r.NetAmount > budget
+}
+
+// MsListTenantUsageReports returns the usage reports of the tenants of a workspace for a period, e.g.
+// 2024-05. An empty period returns the reports of all periods.
+func MsListTenantUsageReports(apiurl, apikey, workspace, period string, verbose bool, opts ...Option) (reports []TenantUsageReport, err error) {
+	type msAmount struct {
+		Amount   float64 `json:"amount"`
+		Currency string  `json:"currency"`
+	}
+	type msUsageReport struct {
+		Metadata struct {
+			UUID string `json:"uuid"`
+		} `json:"metadata"`
+		Spec struct {
+			Period              string   `json:"period"`
+			WorkspaceIdentifier string   `json:"workspaceIdentifier"`
+			ProjectIdentifier   string   `json:"projectIdentifier"`
+			PlatformIdentifier  string   `json:"platformIdentifier"`
+			LocalTenantID       string   `json:"localTenantId"`
+			NetAmount           msAmount `json:"netAmount"`
+			LineItems           []struct {
+				Description string   `json:"description"`
+				Usage       float64  `json:"usageQuantity"`
+				Unit        string   `json:"usageUnit"`
+				NetAmount   msAmount `json:"netAmount"`
+			} `json:"lineItems"`
+		} `json:"spec"`
+	}
+
+	query := url.Values{"workspaceIdentifier": {workspace}}
+	if period != "" {
+		if !usagePeriodPattern.MatchString(period) {
+			return nil, &ValidationError{Payload: "tenant usage report", Field: "period", Reason: fmt.Sprintf("%q must be a month like 2024-05", period)}
+		}
+		query.Set("period", period)
+	}
+
+	err = msEachPage(apiurl, apikey, "api/meshobjects/meshtenantusagereports", msMediaType("meshtenantusagereport"), "meshTenantUsageReports", query, func(r msUsageReport) error {
+		report := TenantUsageReport{
+			UUID:      r.Metadata.UUID,
+			Workspace: r.Spec.WorkspaceIdentifier,
+			Project:   r.Spec.ProjectIdentifier,
+			Platform:  r.Spec.PlatformIdentifier,
+			LocalID:   r.Spec.LocalTenantID,
+			Period:    r.Spec.Period,
+			NetAmount: r.Spec.NetAmount.Amount,
+			Currency:  r.Spec.NetAmount.Currency,
+		}
+		for _, item := range r.Spec.LineItems {
+			report.LineItems = append(report.LineItems, UsageLineItem{
+				Description: item.Description,
+				Usage:       item.Usage,
+				Unit:        item.Unit,
+				NetAmount:   item.NetAmount.Amount,
+			})
+		}
+		reports = append(reports, report)
+		return nil
+	}, verbose, opts...)
+	return reports, err
+}
+
+// TenantUsage returns the usage reports of the tenants of a workspace, see MsListTenantUsageReports
+func (c *MsClient) TenantUsage(workspace, period string, opts ...Option) ([]TenantUsageReport, error) {
+	verbose, opts := c.options(opts)
+	return MsListTenantUsageReports(c.URL, c.Token(), workspace, period, verbose, opts...)
+}
@@ -0,0 +1,48 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMsListTenantUsageReports(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshtenantusagereports" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if q := r.URL.Query(); q.Get("workspaceIdentifier") != "retail" || q.Get("period") != "2024-05" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"_embedded": {"meshTenantUsageReports": [{"metadata": {"uuid": "r1"}, "spec": {"period": "2024-05",
+			"workspaceIdentifier": "retail", "projectIdentifier": "shop", "platformIdentifier": "openstack.eu", "localTenantId": "abc",
+			"netAmount": {"amount": 120.5, "currency": "EUR"},
+			"lineItems": [{"description": "m1.large", "usageQuantity": 720, "usageUnit": "h", "netAmount": {"amount": 120.5, "currency": "EUR"}}]}}]},
+			"page": {"size": 100, "totalElements": 1, "totalPages": 1, "number": 0}}`)
+	}))
+	defer server.Close()
+
+	reports, err := MsListTenantUsageReports(server.URL, "token", "retail", "2024-05", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []TenantUsageReport{{
+		UUID: "r1", Workspace: "retail", Project: "shop", Platform: "openstack.eu", LocalID: "abc",
+		Period: "2024-05", NetAmount: 120.5, Currency: "EUR",
+		LineItems: []UsageLineItem{{Description: "m1.large", Usage: 720, Unit: "h", NetAmount: 120.5}},
+	}}
+	if !reflect.DeepEqual(reports, want) {
+		t.Errorf("got %+v, want %+v", reports, want)
+	}
+	if !reports[0].OverBudget(100) || reports[0].OverBudget(200) {
+		t.Errorf("unexpected OverBudget for %v", reports[0].NetAmount)
+	}
+
+	var verr *ValidationError
+	if _, err := MsListTenantUsageReports(server.URL, "token", "retail", "2024-13", false); !errors.As(err, &verr) {
+		t.Errorf("expected validation error for the period, got %v", err)
+	}
+}
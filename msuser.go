@@ -0,0 +1,62 @@
+package appapi
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MsUser is a user of Meshstack
+type MsUser struct {
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+// MsFindUserByEmail returns the user with the email address, e.g. to bind a corporate address to a
+// workspace role. The address is compared case-insensitively. It returns ErrNotFound if no user has
+// the address and an error if several users have it.
+func MsFindUserByEmail(apiurl, apikey, email string, verbose bool, opts ...Option) (user MsUser, err error) {
+	type msUser struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Email     string `json:"email"`
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"spec"`
+	}
+
+	email = strings.TrimSpace(email)
+	var found []MsUser
+	err = msEachPage(apiurl, apikey, "api/meshobjects/meshusers", msMediaType("meshuser"), "meshUsers", url.Values{"email": {email}}, func(u msUser) error {
+		// the filter is checked again, it matches substrings on some Meshstack versions
+		if strings.EqualFold(u.Spec.Email, email) {
+			found = append(found, MsUser{Username: u.Metadata.Name, Email: u.Spec.Email, FirstName: u.Spec.FirstName, LastName: u.Spec.LastName})
+		}
+		return nil
+	}, verbose, opts...)
+	if err != nil {
+		return user, err
+	}
+
+	switch len(found) {
+	case 0:
+		return user, fmt.Errorf("user with email %s: %w", email, ErrNotFound)
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, len(found))
+	for i, u := range found {
+		names[i] = u.Username
+	}
+	return user, fmt.Errorf("email %s is ambiguous, it belongs to the users %s", email, strings.Join(names, ", "))
+}
+
+// FindUserByEmail returns the user with the email address, see MsFindUserByEmail
+func (c *MsClient) FindUserByEmail(email string, opts ...Option) (MsUser, error) {
+	verbose, opts := c.options(opts)
+	return MsFindUserByEmail(c.URL, c.Token(), email, verbose, opts...)
+}
@@ -0,0 +1,35 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMsFindUserByEmail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshusers" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"_embedded": {"meshUsers": [
+			{"metadata": {"name": "jdoe"}, "spec": {"email": "John.Doe@example.com", "firstName": "John", "lastName": "Doe"}},
+			{"metadata": {"name": "jdoe2"}, "spec": {"email": "john.doe@example.com.invalid"}},
+			{"metadata": {"name": "shared1"}, "spec": {"email": "ops@example.com"}},
+			{"metadata": {"name": "shared2"}, "spec": {"email": "ops@example.com"}}]},
+			"page": {"size": 100, "totalElements": 4, "totalPages": 1, "number": 0}}`)
+	}))
+	defer server.Close()
+
+	user, err := MsFindUserByEmail(server.URL, "token", " john.doe@example.com", false)
+	if err != nil || user.Username != "jdoe" || user.LastName != "Doe" {
+		t.Errorf("MsFindUserByEmail() = %+v, %v", user, err)
+	}
+	if _, err := MsFindUserByEmail(server.URL, "token", "nobody@example.com", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := MsFindUserByEmail(server.URL, "token", "ops@example.com", false); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an error for an ambiguous address, got %v", err)
+	}
+}
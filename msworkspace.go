@@ -0,0 +1,112 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// MsCreateWorkspace creates a workspace and binds its owners with the meshObject import, see
+// MsApplyMeshObject. The import is idempotent, an existing workspace is updated.
+func MsCreateWorkspace(apiurl, apikey string, workspace MsApiCreateWorkspace, verbose bool, opts ...Option) (results []MeshObjectImportResult, err error) {
+	payload, err := workspace.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return MsApplyMeshObject(apiurl, apikey, payload, verbose, opts...)
+}
+
+// CreateWorkspace creates a workspace with its bindings, see MsCreateWorkspace
+func (c *MsClient) CreateWorkspace(workspace MsApiCreateWorkspace, opts ...Option) ([]MeshObjectImportResult, error) {
+	verbose, opts := c.options(opts)
+	return MsCreateWorkspace(c.URL, c.Token(), workspace, verbose, opts...)
+}
+
+// ErrWorkspaceNotEmpty is returned by MsDeleteWorkspace if projects or tenants remain in the workspace
+var ErrWorkspaceNotEmpty = errors.New("workspace is not empty")
+
+// WorkspaceDeletionReport lists the resources which block the deletion of a workspace, see
+// MsDeleteWorkspace
+type WorkspaceDeletionReport struct {
+	Workspace string
+	// Projects are the remaining projects, with cascade only those which could not be deleted
+	Projects []string
+	// Tenants are the tenants of the projects, they are never deleted by MsDeleteWorkspace
+	Tenants []string
+	// DeletedProjects are the projects deleted with cascade
+	DeletedProjects []string
+	Deleted         bool
+}
+
+// Blocked reports if resources block the deletion
+func (r WorkspaceDeletionReport) Blocked() bool {
+	return len(r.Projects) > 0 || len(r.Tenants) > 0
+}
+
+// WorkspaceNotEmptyError is returned with the report of the blocking resources, it matches
+// ErrWorkspaceNotEmpty
+type WorkspaceNotEmptyError struct {
+	Report WorkspaceDeletionReport
+}
+
+func (e *WorkspaceNotEmptyError) Error() string {
+	return fmt.Sprintf("workspace %s cannot be deleted, %d project(s) and %d tenant(s) remain: %s",
+		e.Report.Workspace, len(e.Report.Projects), len(e.Report.Tenants), strings.Join(append(slices.Clone(e.Report.Projects), e.Report.Tenants...), ", "))
+}
+
+func (e *WorkspaceNotEmptyError) Is(target error) bool {
+	return target == ErrWorkspaceNotEmpty
+}
+
+// MsDeleteWorkspace deletes a workspace if no projects and tenants remain. With cascade the projects
+// without tenants are deleted first, tenants are never deleted since that destroys the resources on
+// their platforms. If resources remain, a WorkspaceNotEmptyError with the report is returned.
+func MsDeleteWorkspace(apiurl, apikey, workspace string, cascade, verbose bool, opts ...Option) (report WorkspaceDeletionReport, err error) {
+	report.Workspace = workspace
+
+	tenants, err := MsListTenants(apiurl, apikey, workspace, nil, verbose, opts...)
+	if err != nil {
+		return report, err
+	}
+	withTenants := map[string]bool{}
+	for _, t := range tenants {
+		report.Tenants = append(report.Tenants, t.ID())
+		withTenants[t.Project] = true
+	}
+
+	projects, err := MsListProjects(apiurl, apikey, workspace, verbose, opts...)
+	if err != nil {
+		return report, err
+	}
+	for _, p := range projects {
+		if !cascade || withTenants[p.Identifier] {
+			report.Projects = append(report.Projects, p.Identifier)
+			continue
+		}
+		if err := MsDeleteProject(apiurl, apikey, workspace, p.Identifier, verbose, opts...); err != nil {
+			logWarnf("could not delete project %s of workspace %s: %v", p.Identifier, workspace, err)
+			report.Projects = append(report.Projects, p.Identifier)
+			continue
+		}
+		report.DeletedProjects = append(report.DeletedProjects, p.Identifier)
+	}
+
+	if report.Blocked() {
+		return report, &WorkspaceNotEmptyError{Report: report}
+	}
+
+	if err := msCall(apiurl, apikey, http.MethodDelete, "api/meshobjects/meshworkspaces/"+url.PathEscape(workspace), msMediaType("meshworkspace"), nil, nil, verbose, opts...); err != nil {
+		return report, err
+	}
+	report.Deleted = true
+	return report, nil
+}
+
+// DeleteWorkspace deletes a workspace if it is empty, see MsDeleteWorkspace
+func (c *MsClient) DeleteWorkspace(workspace string, cascade bool, opts ...Option) (WorkspaceDeletionReport, error) {
+	verbose, opts := c.options(opts)
+	return MsDeleteWorkspace(c.URL, c.Token(), workspace, cascade, verbose, opts...)
+}
@@ -0,0 +1,116 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMsCreateWorkspace(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/meshobjects" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		fmt.Fprint(w, `[{"meshObject": {"kind": "meshWorkspace", "name": "retail"}, "status": "SUCCESS", "resultCode": "SUCCESS"},
+			{"meshObject": {"kind": "meshWorkspaceUserBinding", "name": "retail-workspace-owner-user-jdoe"}, "status": "SUCCESS", "resultCode": "SUCCESS"}]`)
+	}))
+	defer server.Close()
+
+	workspace, err := NewMsApiCreateWorkspace("retail", "Retail", map[string][]string{"costCenter": {"4711"}}, "jdoe")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workspace.Bindings = append(workspace.Bindings, MsApiWorkspaceBinding{Subject: "retail-ops", Group: true, Role: MsWorkspaceMember})
+
+	results, err := MsCreateWorkspace(server.URL, "token", workspace, false)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("MsCreateWorkspace() = %+v, %v", results, err)
+	}
+
+	want := `[{"apiVersion":"v1","kind":"meshWorkspace","metadata":{"name":"retail","tags":{"costCenter":["4711"]}},"spec":{"displayName":"Retail"}},` +
+		`{"apiVersion":"v1","kind":"meshWorkspaceUserBinding","metadata":{"name":"retail-workspace-owner-user-jdoe"},"roleRef":{"name":"Workspace Owner"},"subjects":[{"name":"jdoe"}],"targetRef":{"name":"retail"}},` +
+		`{"apiVersion":"v1","kind":"meshWorkspaceGroupBinding","metadata":{"name":"retail-workspace-member-group-retail-ops"},"roleRef":{"name":"Workspace Member"},"subjects":[{"name":"retail-ops"}],"targetRef":{"name":"retail"}}]`
+	if body != want {
+		t.Errorf("got payload\n%s\nwant\n%s", body, want)
+	}
+
+	var verr *ValidationError
+	if _, err := NewMsApiCreateWorkspace("Retail_1", "Retail", nil); !errors.As(err, &verr) || verr.Field != "identifier" {
+		t.Errorf("expected validation error for the identifier, got %v", err)
+	}
+}
+
+func TestMsDeleteWorkspace(t *testing.T) {
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/meshobjects/meshtenants", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("workspaceIdentifier") != "retail" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"_embedded": {"meshTenants": [{"metadata": {"ownedByWorkspace": "retail", "ownedByProject": "shop", "platformIdentifier": "openstack.eu"}, "spec": {"localId": "abc"}}]},
+			"page": {"size": 100, "totalElements": 1, "totalPages": 1, "number": 0}}`)
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshprojects", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_embedded": {"meshProjects": [{"metadata": {"name": "shop", "ownedByWorkspace": "retail"}, "spec": {"displayName": "Shop"}},
+			{"metadata": {"name": "old", "ownedByWorkspace": "retail"}, "spec": {"displayName": "Old"}}]},
+			"page": {"size": 100, "totalElements": 2, "totalPages": 1, "number": 0}}`)
+	})
+	mux.HandleFunc("DELETE /api/meshobjects/{kind}/{name}", func(w http.ResponseWriter, r *http.Request) {
+		deleted = append(deleted, r.PathValue("kind")+"/"+r.PathValue("name"))
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	report, err := MsDeleteWorkspace(server.URL, "token", "retail", false, false)
+	var nerr *WorkspaceNotEmptyError
+	if !errors.Is(err, ErrWorkspaceNotEmpty) || !errors.As(err, &nerr) {
+		t.Fatalf("expected WorkspaceNotEmptyError, got %v", err)
+	}
+	if len(report.Projects) != 2 || len(report.Tenants) != 1 || report.Tenants[0] != "retail.shop.openstack.eu" || report.Deleted {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("nothing must be deleted without cascade, deleted %v", deleted)
+	}
+
+	// the project with a tenant still blocks the deletion
+	report, err = MsDeleteWorkspace(server.URL, "token", "retail", true, false)
+	if !errors.Is(err, ErrWorkspaceNotEmpty) {
+		t.Fatalf("expected ErrWorkspaceNotEmpty, got %v", err)
+	}
+	if len(report.DeletedProjects) != 1 || report.DeletedProjects[0] != "old" || len(report.Projects) != 1 || report.Projects[0] != "shop" {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(deleted) != 1 || deleted[0] != "meshprojects/retail.old" {
+		t.Errorf("unexpected deletions %v", deleted)
+	}
+}
+
+func TestMsDeleteEmptyWorkspace(t *testing.T) {
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/meshobjects/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_embedded": {}, "page": {"size": 100, "totalElements": 0, "totalPages": 0, "number": 0}}`)
+	})
+	mux.HandleFunc("DELETE /api/meshobjects/{kind}/{name}", func(w http.ResponseWriter, r *http.Request) {
+		deleted = append(deleted, r.PathValue("kind")+"/"+r.PathValue("name"))
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	report, err := MsDeleteWorkspace(server.URL, "token", "retail", false, false)
+	if err != nil || !report.Deleted {
+		t.Fatalf("MsDeleteWorkspace() = %+v, %v", report, err)
+	}
+	if len(deleted) != 1 || deleted[0] != "meshworkspaces/retail" {
+		t.Errorf("unexpected deletions %v", deleted)
+	}
+}
@@ -0,0 +1,119 @@
+package appapi
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// NotificationOnboardingFailed is the type of the notifications SUSE Manager creates when the
+// onboarding of a system failed, e.g. the bootstrap script could not be run
+const NotificationOnboardingFailed = "OnboardingFailed"
+
+// Notification is a notification of the SUSE Manager user, e.g. a failed onboarding of a system
+type Notification struct {
+	ID      int       `json:"id"`
+	Type    string    `json:"type"`
+	Summary string    `json:"summary"`
+	Details string    `json:"details,omitempty" output:"-"`
+	Created time.Time `json:"created"`
+	Read    bool      `json:"read"`
+}
+
+// SumaListNotifications returns the notifications of the user of the session, only the unread ones
+// if unreadOnly is set
+func SumaListNotifications(sessioncookie, susemgr string, unreadOnly, verbose bool, opts ...Option) (notifications []Notification, err error) {
+
+	type ResultNotification struct {
+		ID      int    `json:"id"`
+		Type    string `json:"type"`
+		Summary string `json:"summary"`
+		Details string `json:"details"`
+		Created string `json:"created"`
+		Read    bool   `json:"read"`
+	}
+
+	var result []ResultNotification
+	query := url.Values{"unreadOnly": {strconv.FormatBool(unreadOnly)}}
+	if err := sumaGet(sessioncookie, susemgr, "user/notifications/getNotifications", query, &result, verbose, opts...); err != nil {
+		return nil, err
+	}
+
+	notifications = make([]Notification, 0, len(result))
+	for _, r := range result {
+		notifications = append(notifications, Notification{
+			ID:      r.ID,
+			Type:    r.Type,
+			Summary: r.Summary,
+			Details: r.Details,
+			Created: parseSumaTime(r.Created),
+			Read:    r.Read,
+		})
+	}
+	return notifications, nil
+}
+
+// SumaMarkNotificationsRead marks the notifications as read, or as unread if read is false
+func SumaMarkNotificationsRead(sessioncookie, susemgr string, ids []int, read, verbose bool, opts ...Option) (err error) {
+	if len(ids) == 0 {
+		return nil
+	}
+	payload := map[string]any{"notifications": ids, "read": read}
+	return sumaPost(sessioncookie, susemgr, "user/notifications/setNotificationsRead", payload, nil, verbose, opts...)
+}
+
+// SumaDeleteNotifications deletes the notifications of the user of the session
+func SumaDeleteNotifications(sessioncookie, susemgr string, ids []int, verbose bool, opts ...Option) (err error) {
+	if len(ids) == 0 {
+		return nil
+	}
+	payload := map[string]any{"notifications": ids}
+	return sumaPost(sessioncookie, susemgr, "user/notifications/deleteNotifications", payload, nil, verbose, opts...)
+}
+
+// FilterNotifications returns the notifications of a type, e.g. NotificationOnboardingFailed
+func FilterNotifications(notifications []Notification, notificationType string) []Notification {
+	var filtered []Notification
+	for _, n := range notifications {
+		if n.Type == notificationType {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+// NotificationIDs returns the IDs of the notifications, e.g. to mark them as read
+func NotificationIDs(notifications []Notification) []int {
+	ids := make([]int, len(notifications))
+	for i, n := range notifications {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+// Notifications returns the notifications of the user of the client, see SumaListNotifications
+func (c *SumaClient) Notifications(unreadOnly bool, opts ...Option) ([]Notification, error) {
+	verbose, opts := c.options(opts)
+	return SumaListNotifications(c.SessionCookie(), c.URL, unreadOnly, verbose, opts...)
+}
+
+// OnboardingFailures returns the unread notifications about systems whose onboarding failed
+func (c *SumaClient) OnboardingFailures(opts ...Option) ([]Notification, error) {
+	notifications, err := c.Notifications(true, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return FilterNotifications(notifications, NotificationOnboardingFailed), nil
+}
+
+// MarkNotificationsRead marks notifications as read, see SumaMarkNotificationsRead
+func (c *SumaClient) MarkNotificationsRead(ids []int, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return SumaMarkNotificationsRead(c.SessionCookie(), c.URL, ids, true, verbose, opts...)
+}
+
+// DeleteNotifications deletes notifications, see SumaDeleteNotifications
+func (c *SumaClient) DeleteNotifications(ids []int, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return SumaDeleteNotifications(c.SessionCookie(), c.URL, ids, verbose, opts...)
+}
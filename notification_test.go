@@ -0,0 +1,64 @@
+package appapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSumaNotifications(t *testing.T) {
+	var posted map[string]map[string]any
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/user/notifications/getNotifications", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("unreadOnly") != "true" {
+			t.Errorf("unreadOnly = %s, want true", r.URL.Query().Get("unreadOnly"))
+		}
+		io.WriteString(w, `{"success": true, "result": [
+			{"id": 3, "type": "OnboardingFailed", "summary": "Onboarding of host1 failed", "created": "2024-06-01T08:30:00Z", "read": false},
+			{"id": 4, "type": "ChannelSyncFinished", "summary": "Channel sync finished", "created": "2024-06-01T09:00:00Z", "read": false}
+		]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/notifications/{method}", func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("%s: invalid payload: %v", r.PathValue("method"), err)
+		}
+		posted[r.PathValue("method")] = payload
+		io.WriteString(w, `{"success": true, "result": 1}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	failures, err := c.OnboardingFailures()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(failures) != 1 || failures[0].ID != 3 || failures[0].Created.IsZero() {
+		t.Fatalf("unexpected onboarding failures: %+v", failures)
+	}
+
+	posted = map[string]map[string]any{}
+	if err := c.MarkNotificationsRead(NotificationIDs(failures)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.DeleteNotifications([]int{3, 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.DeleteNotifications(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]map[string]any{
+		"setNotificationsRead": {"notifications": []any{3.0}, "read": true},
+		"deleteNotifications":  {"notifications": []any{3.0, 4.0}},
+	}
+	if !reflect.DeepEqual(posted, want) {
+		t.Errorf("got payloads %v, want %v", posted, want)
+	}
+}
@@ -0,0 +1,212 @@
+package appapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// notifyTimeout limits the delivery of a notification, a slow chat service must not block an operation
+const notifyTimeout = 10 * time.Second
+
+// Summary is the outcome of an operation which is sent to the operators, e.g. of a bulk delete
+type Summary struct {
+	Operation string    `json:"operation"`
+	Changes   []string  `json:"changes,omitempty"`
+	Failures  []string  `json:"failures,omitempty"`
+	Finished  time.Time `json:"finished"`
+}
+
+// Failed reports if a part of the operation failed
+func (s Summary) Failed() bool {
+	return len(s.Failures) > 0
+}
+
+// Title returns a one line summary, e.g. "sync project web failed: 3 change(s), 1 failure(s)"
+func (s Summary) Title() string {
+	status := "succeeded"
+	if s.Failed() {
+		status = "failed"
+	}
+	return fmt.Sprintf("%s %s: %d change(s), %d failure(s)", s.Operation, status, len(s.Changes), len(s.Failures))
+}
+
+// Text returns the title and the changes and failures as lines
+func (s Summary) Text() string {
+	var b strings.Builder
+	b.WriteString(s.Title())
+	for _, c := range s.Changes {
+		b.WriteString("\n+ " + c)
+	}
+	for _, f := range s.Failures {
+		b.WriteString("\n! " + f)
+	}
+	return b.String()
+}
+
+// Notifier sends the summary of an operation to the operators
+type Notifier interface {
+	Notify(ctx context.Context, s Summary) error
+}
+
+// Notifiers sends a summary with every notifier, e.g. to Slack and by email
+type Notifiers []Notifier
+
+// Notify sends the summary with every notifier and returns their errors joined
+func (n Notifiers) Notify(ctx context.Context, s Summary) error {
+	var errs []error
+	for _, notifier := range n {
+		if err := notifier.Notify(ctx, s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WebhookNotifier posts the summary as JSON to a URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// Notify posts the summary
+func (n WebhookNotifier) Notify(ctx context.Context, s Summary) error {
+	return postJSON(ctx, n.Client, n.URL, s)
+}
+
+// SlackNotifier posts the summary to a Slack incoming webhook
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// Notify posts the summary as Slack message
+func (n SlackNotifier) Notify(ctx context.Context, s Summary) error {
+	return postJSON(ctx, n.Client, n.WebhookURL, map[string]string{"text": s.Text()})
+}
+
+// TeamsNotifier posts the summary to a Microsoft Teams incoming webhook
+type TeamsNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// Notify posts the summary as Teams message card, red if the operation failed
+func (n TeamsNotifier) Notify(ctx context.Context, s Summary) error {
+	color := "2EB886"
+	if s.Failed() {
+		color = "D00000"
+	}
+	var text strings.Builder
+	for _, c := range s.Changes {
+		text.WriteString("- " + c + "\n")
+	}
+	for _, f := range s.Failures {
+		text.WriteString("- **failed:** " + f + "\n")
+	}
+	card := map[string]string{
+		"@type":      "MessageCard",
+		"@context":   "http://schema.org/extensions",
+		"summary":    s.Title(),
+		"title":      s.Title(),
+		"themeColor": color,
+		"text":       text.String(),
+	}
+	return postJSON(ctx, n.Client, n.WebhookURL, card)
+}
+
+// smtpSendMail sends an email, replaced in tests
+var smtpSendMail = smtp.SendMail
+
+// EmailNotifier sends the summary by email through an SMTP server
+type EmailNotifier struct {
+	// Addr is the host:port of the SMTP server
+	Addr string
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+// Notify sends the summary as plain text email
+func (n EmailNotifier) Notify(ctx context.Context, s Summary) error {
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		n.From, strings.Join(n.To, ", "), s.Title(), strings.ReplaceAll(s.Text(), "\n", "\r\n"))
+	if err := smtpSendMail(n.Addr, n.Auth, n.From, n.To, []byte(msg)); err != nil {
+		return fmt.Errorf("could not send the notification to %s: %w", strings.Join(n.To, ", "), err)
+	}
+	return nil
+}
+
+// postJSON posts the payload as JSON and expects a 2xx status
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling payload: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not send the notification: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("could not send the notification: %w", statusError(resp))
+	}
+	return nil
+}
+
+// notify sends the summary if a notifier is set. A failed notification is only logged, it does not
+// fail the operation.
+func notify(n Notifier, s Summary) {
+	if n == nil {
+		return
+	}
+	if s.Finished.IsZero() {
+		s.Finished = time.Now()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := n.Notify(ctx, s); err != nil {
+		logWarnf("could not notify about %s: %v", s.Operation, err)
+	}
+}
+
+// BulkSummary returns the summary of a bulk operation, the succeeded targets are the changes
+func BulkSummary[T any](operation string, report BulkReport[T]) Summary {
+	s := Summary{Operation: operation, Changes: report.Succeeded()}
+	for _, r := range report.Failed() {
+		s.Failures = append(s.Failures, fmt.Sprintf("%s: %v", r.Target, r.Err))
+	}
+	return s
+}
+
+// SyncSummary returns the summary of a sync, the error is the error of the sync
+func SyncSummary(report SyncReport, err error) Summary {
+	s := Summary{Operation: "sync project " + report.ProjectID}
+	if report.UserCreated {
+		s.Changes = append(s.Changes, "created user "+report.Group.Group)
+	}
+	for _, host := range report.Group.Added {
+		s.Changes = append(s.Changes, fmt.Sprintf("added %s to group %s", host, report.Group.Group))
+	}
+	for _, host := range report.Group.Removed {
+		s.Changes = append(s.Changes, fmt.Sprintf("removed %s from group %s", host, report.Group.Group))
+	}
+	if err != nil {
+		s.Failures = append(s.Failures, err.Error())
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+package appapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+func TestNotifiers(t *testing.T) {
+	bodies := map[string]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid body for %s: %v", r.URL.Path, err)
+		}
+		bodies[r.URL.Path] = body
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	var mail string
+	sendMail := smtpSendMail
+	smtpSendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mail = string(msg)
+		return nil
+	}
+	defer func() { smtpSendMail = sendMail }()
+
+	summary := Summary{Operation: "delete systems", Changes: []string{"host1"}, Failures: []string{"host2: system is locked"}}
+	notifiers := Notifiers{
+		WebhookNotifier{URL: server.URL + "/webhook"},
+		SlackNotifier{WebhookURL: server.URL + "/slack"},
+		TeamsNotifier{WebhookURL: server.URL + "/teams"},
+		EmailNotifier{Addr: "mail:25", From: "appapi@example.com", To: []string{"ops@example.com"}},
+	}
+	if err := notifiers.Notify(context.Background(), summary); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if bodies["/webhook"]["operation"] != "delete systems" {
+		t.Errorf("unexpected webhook payload %v", bodies["/webhook"])
+	}
+	if want := "delete systems failed: 1 change(s), 1 failure(s)\n+ host1\n! host2: system is locked"; bodies["/slack"]["text"] != want {
+		t.Errorf("got Slack text %q, want %q", bodies["/slack"]["text"], want)
+	}
+	if bodies["/teams"]["themeColor"] != "D00000" || !strings.Contains(bodies["/teams"]["text"].(string), "**failed:** host2") {
+		t.Errorf("unexpected Teams card %v", bodies["/teams"])
+	}
+	if !strings.Contains(mail, "Subject: delete systems failed") || !strings.Contains(mail, "To: ops@example.com") {
+		t.Errorf("unexpected mail %q", mail)
+	}
+
+	if err := (WebhookNotifier{URL: server.URL + "/broken"}).Notify(context.Background(), summary); err == nil {
+		t.Errorf("expected an error for a failed delivery")
+	}
+}
+
+func TestBulkSummary(t *testing.T) {
+	report := BulkReport[int]{Results: []BulkResult[int]{
+		{Target: "host1", Value: 1},
+		{Target: "host2", Err: errors.New("not found")},
+	}}
+	s := BulkSummary("delete systems", report)
+	if len(s.Changes) != 1 || s.Changes[0] != "host1" || len(s.Failures) != 1 || s.Failures[0] != "host2: not found" {
+		t.Errorf("unexpected summary %+v", s)
+	}
+}
@@ -0,0 +1,233 @@
+package appapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MsTokenSource returns the access tokens of the Meshstack calls instead of the /api/login exchange,
+// e.g. an OIDCTokenSource of a federated identity provider or a token of an SSO login. Token is
+// called before every call and returns a valid token, the source renews it when it expires.
+type MsTokenSource interface {
+	Token() (accesstoken string, err error)
+}
+
+// MsTokenSourceFunc adapts a function to an MsTokenSource, e.g. to plug in the SSO login of a CLI
+type MsTokenSourceFunc func() (string, error)
+
+// Token calls f
+func (f MsTokenSourceFunc) Token() (string, error) {
+	return f()
+}
+
+// StaticTokenSource returns always the same access token, e.g. one passed by a CI pipeline
+func StaticTokenSource(accesstoken string) MsTokenSource {
+	return MsTokenSourceFunc(func() (string, error) { return accesstoken, nil })
+}
+
+// OIDCConfig is the identity provider of an OIDCTokenSource
+type OIDCConfig struct {
+	// TokenURL is the token endpoint. If empty, it is discovered from the openid-configuration of
+	// the Issuer.
+	TokenURL string
+	Issuer   string
+	// Scopes and Audience are requested for the token, if set
+	Scopes   []string
+	Audience string
+	// BasicAuth sends the client credentials in the Authorization header (client_secret_basic)
+	// instead of the form (client_secret_post)
+	BasicAuth bool
+}
+
+// oidcExpiryMargin is the longest time before the expiry at which a token is renewed
+const oidcExpiryMargin = 30 * time.Second
+
+// OIDCTokenSource gets the Meshstack access tokens with the OIDC client credentials grant. The client
+// ID and secret are the Meshstack credentials of the provider. The token is reused until shortly
+// before it expires, a failed token request is retried once with refreshed credentials.
+type OIDCTokenSource struct {
+	cfg   OIDCConfig
+	creds CredentialProvider
+	opts  []Option
+	clock Clock
+
+	mu       sync.Mutex
+	tokenURL string
+	token    string
+	expires  time.Time
+}
+
+// NewOIDCTokenSource creates a token source for the identity provider. The first token is requested
+// by the first call of Token.
+func NewOIDCTokenSource(cfg OIDCConfig, creds CredentialProvider, opts ...Option) *OIDCTokenSource {
+	return &OIDCTokenSource{cfg: cfg, creds: creds, opts: opts, clock: newCallOptions(false, opts).clock, tokenURL: cfg.TokenURL}
+}
+
+// Token returns the current access token or requests a new one. Concurrent callers wait for one
+// token request.
+func (s *OIDCTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && s.clock.Now().Before(s.expires) {
+		return s.token, nil
+	}
+
+	verbose := newCallOptions(false, s.opts).verbose
+	if s.tokenURL == "" {
+		tokenURL, err := OIDCDiscoverTokenURL(s.cfg.Issuer, verbose, s.opts...)
+		if err != nil {
+			return "", err
+		}
+		s.tokenURL = tokenURL
+	}
+
+	var (
+		token     string
+		expiresIn int
+		err       error
+	)
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			if verbose {
+				logDebugf("MSAPI OIDCTokenSource: token request failed, refresh credentials")
+			}
+			if err := s.creds.Refresh(); err != nil {
+				return "", fmt.Errorf("failed to refresh credentials: %v", err)
+			}
+		}
+
+		var creds Credentials
+		if creds, err = s.creds.Get(); err != nil {
+			return "", err
+		}
+		if token, expiresIn, err = oidcClientCredentials(s.tokenURL, creds.MsClientID, creds.MsClientSecret, s.cfg, verbose, s.opts...); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	lifetime := time.Duration(expiresIn) * time.Second
+	s.expires = s.clock.Now().Add(lifetime - min(oidcExpiryMargin, lifetime/2))
+	if verbose {
+		logDebugf("MSAPI OIDCTokenSource: new token valid until %s", s.expires.Format(time.RFC3339))
+	}
+	return token, nil
+}
+
+// Invalidate drops the current token, e.g. after Meshstack rejected it, so the next Token requests
+// a new one
+func (s *OIDCTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+	s.expires = time.Time{}
+}
+
+// OIDCDiscoverTokenURL returns the token endpoint of an issuer from its
+// .well-known/openid-configuration
+func OIDCDiscoverTokenURL(issuer string, verbose bool, opts ...Option) (string, error) {
+	if issuer == "" {
+		return "", fmt.Errorf("neither token url nor issuer of the identity provider is configured")
+	}
+	apiMethod := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if verbose {
+		logDebugf("MSAPI OIDCDiscoverTokenURL: apiMethod = %s", apiMethod)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, apiMethod, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		return "", fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp)
+	}
+	var discovery struct {
+		TokenEndpoint string `json:"token_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return "", fmt.Errorf("error decoding openid-configuration of %s: %v", issuer, err)
+	}
+	if discovery.TokenEndpoint == "" {
+		return "", fmt.Errorf("openid-configuration of %s has no token_endpoint", issuer)
+	}
+	return discovery.TokenEndpoint, nil
+}
+
+// oidcClientCredentials requests a token with the client credentials grant and returns it with its
+// lifetime in seconds, 0 if unknown
+func oidcClientCredentials(tokenURL, clientid, clientsecret string, cfg OIDCConfig, verbose bool, opts ...Option) (accesstoken string, expiresIn int, err error) {
+	if verbose {
+		logDebugf("MSAPI oidcClientCredentials: tokenURL = %s", tokenURL)
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+	if cfg.Audience != "" {
+		form.Set("audience", cfg.Audience)
+	}
+	if !cfg.BasicAuth {
+		form.Set("client_id", clientid)
+		form.Set("client_secret", clientsecret)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if cfg.BasicAuth {
+		req.SetBasicAuth(url.QueryEscape(clientid), url.QueryEscape(clientsecret))
+	}
+
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("error reading http response: %v", err)
+	}
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(bodyBytes, &result); err != nil && resp.StatusCode == http.StatusOK {
+		return "", 0, fmt.Errorf("error unmarshaling token response: %v", err)
+	}
+	if result.Error != "" {
+		// RFC 6749 section 5.2, e.g. invalid_client or invalid_scope
+		return "", 0, withRequestID(resp, fmt.Errorf("token request failed: %s", strings.TrimSpace(result.Error+" "+result.ErrorDescription)))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, statusError(resp)
+	}
+	if result.AccessToken == "" {
+		return "", 0, fmt.Errorf("token response of %s has no access_token", tokenURL)
+	}
+	return result.AccessToken, result.ExpiresIn, nil
+}
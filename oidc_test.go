@@ -0,0 +1,137 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newOIDCServer starts an identity provider issuing the tokens token-1, token-2, ... valid for 300s
+func newOIDCServer(t *testing.T, check func(r *http.Request) string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var issued atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/realms/mesh/.well-known/openid-configuration":
+			fmt.Fprintf(w, `{"issuer": "%s/realms/mesh", "token_endpoint": "%s/realms/mesh/token"}`, server.URL, server.URL)
+		case "/realms/mesh/token":
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+			if problem := check(r); problem != "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprintf(w, `{"error": "invalid_client", "error_description": %q}`, problem)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 300}`, issued.Add(1))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func TestOIDCTokenSource(t *testing.T) {
+	server, issued := newOIDCServer(t, func(r *http.Request) string {
+		switch {
+		case r.PostForm.Get("grant_type") != "client_credentials":
+			return "wrong grant type"
+		case r.PostForm.Get("client_id") != "client" || r.PostForm.Get("client_secret") != "secret":
+			return "wrong client credentials"
+		case r.PostForm.Get("scope") != "openid meshstack" || r.PostForm.Get("audience") != "meshfed":
+			return "wrong scope or audience"
+		}
+		return ""
+	})
+
+	clock := NewFakeClock(time.Now())
+	s := NewOIDCTokenSource(OIDCConfig{Issuer: server.URL + "/realms/mesh/", Scopes: []string{"openid", "meshstack"}, Audience: "meshfed"},
+		NewStaticCredentialProvider(Credentials{MsClientID: "client", MsClientSecret: "secret"}))
+	s.clock = clock
+
+	for _, step := range []struct {
+		advance time.Duration
+		want    string
+	}{
+		{0, "token-1"},
+		{time.Minute, "token-1"},
+		// renewed 30s before the expiry
+		{4 * time.Minute, "token-2"},
+	} {
+		clock.Advance(step.advance)
+		if token, err := s.Token(); err != nil || token != step.want {
+			t.Errorf("Token() = %q, %v, want %q", token, err, step.want)
+		}
+	}
+
+	s.Invalidate()
+	if token, _ := s.Token(); token != "token-3" {
+		t.Errorf("Token() after Invalidate = %q, want token-3", token)
+	}
+	if n := issued.Load(); n != 3 {
+		t.Errorf("%d tokens issued, want 3", n)
+	}
+}
+
+func TestOIDCTokenSourceBasicAuth(t *testing.T) {
+	server, _ := newOIDCServer(t, func(r *http.Request) string {
+		// the credentials are form encoded (RFC 6749 section 2.3.1)
+		id, secret, ok := r.BasicAuth()
+		secret, _ = url.QueryUnescape(secret)
+		if !ok || id != "client" || secret != "s&cret" || r.PostForm.Has("client_secret") {
+			return "expected client_secret_basic"
+		}
+		return ""
+	})
+
+	s := NewOIDCTokenSource(OIDCConfig{TokenURL: server.URL + "/realms/mesh/token", BasicAuth: true},
+		NewStaticCredentialProvider(Credentials{MsClientID: "client", MsClientSecret: "s&cret"}))
+	if token, err := s.Token(); err != nil || token != "token-1" {
+		t.Errorf("Token() = %q, %v, want token-1", token, err)
+	}
+}
+
+func TestOIDCTokenSourceError(t *testing.T) {
+	server, _ := newOIDCServer(t, func(r *http.Request) string { return "client disabled" })
+
+	s := NewOIDCTokenSource(OIDCConfig{TokenURL: server.URL + "/realms/mesh/token"},
+		NewStaticCredentialProvider(Credentials{MsClientID: "client", MsClientSecret: "secret"}))
+	_, err := s.Token()
+	if err == nil || !strings.Contains(err.Error(), "invalid_client client disabled") {
+		t.Errorf("Token() error = %v, want the error of the identity provider", err)
+	}
+
+	s = NewOIDCTokenSource(OIDCConfig{Issuer: server.URL + "/unknown"}, NewStaticCredentialProvider(Credentials{}))
+	if _, err := s.Token(); err == nil {
+		t.Error("Token() with an unknown issuer returned no error")
+	}
+}
+
+func TestNewMsClientWithTokenSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/login" {
+			t.Error("unexpected call of /api/login")
+		}
+		fmt.Fprint(w, `{"status": "UP"}`)
+	}))
+	defer server.Close()
+
+	var calls atomic.Int32
+	tokens := MsTokenSourceFunc(func() (string, error) {
+		return fmt.Sprintf("sso-%d", calls.Add(1)), nil
+	})
+	c, err := NewMsClientWithTokenSource(server.URL, tokens, WithInsecure(true))
+	if err != nil {
+		t.Fatalf("NewMsClientWithTokenSource() returned error: %v", err)
+	}
+	if token := c.Token(); token != "sso-2" {
+		t.Errorf("Token() = %q, want the token of the source", token)
+	}
+}
@@ -0,0 +1,324 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The Ensure, Observe and Delete functions reconcile the resources SystemGroup, SumaUser and
+// BuildingBlock, so a Kubernetes operator can wrap them without own logic. They are idempotent:
+// Ensure creates a missing resource and updates a changed one, Observe reports a missing resource
+// in the status instead of failing and Delete succeeds if the resource is already gone. The status
+// structs are meant to be stored as status of a custom resource.
+
+// The phases of a ResourceStatus
+const (
+	PhaseReady   = "Ready"
+	PhasePending = "Pending"
+	PhaseFailed  = "Failed"
+	PhaseAbsent  = "Absent"
+)
+
+// ResourceStatus is the observed state of a resource
+type ResourceStatus struct {
+	// ID is the identifier of the resource, e.g. the UUID of a building block
+	ID    string `json:"id,omitempty"`
+	Phase string `json:"phase"`
+	// Action is the change made by Ensure, unchanged if the resource was in the desired state
+	Action     ResultAction `json:"action,omitempty"`
+	Message    string       `json:"message,omitempty"`
+	ObservedAt time.Time    `json:"observedAt"`
+}
+
+// Ready reports if the resource exists and is usable
+func (s ResourceStatus) Ready() bool {
+	return s.Phase == PhaseReady
+}
+
+func newResourceStatus(id, phase string) ResourceStatus {
+	return ResourceStatus{ID: id, Phase: phase, ObservedAt: time.Now().UTC()}
+}
+
+// SystemGroupStatus is the observed state of a system group
+type SystemGroupStatus struct {
+	ResourceStatus `json:",inline"`
+	GroupID        int `json:"groupId,omitempty"`
+	SystemCount    int `json:"systemCount"`
+}
+
+func systemGroupStatus(g *SystemGroup, action ResultAction) SystemGroupStatus {
+	s := SystemGroupStatus{ResourceStatus: newResourceStatus(g.Name, PhaseReady), GroupID: g.GroupID, SystemCount: g.SystemCount}
+	s.Action = action
+	return s
+}
+
+// EnsureSystemGroup creates the system group or updates its description
+func EnsureSystemGroup(c *SumaClient, desired SystemGroup) (SystemGroupStatus, error) {
+	current := &SystemGroup{Name: desired.Name}
+	err := current.Read(c)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		g := desired
+		if err := g.Create(c); err != nil {
+			return failedSystemGroup(desired.Name, err), err
+		}
+		return systemGroupStatus(&g, ResultCreated), nil
+	case err != nil:
+		return failedSystemGroup(desired.Name, err), err
+	}
+
+	if current.Description == desired.Description {
+		return systemGroupStatus(current, ResultUnchanged), nil
+	}
+	current.Description = desired.Description
+	if err := current.Update(c); err != nil {
+		return failedSystemGroup(desired.Name, err), err
+	}
+	return systemGroupStatus(current, ResultUpdated), nil
+}
+
+// ObserveSystemGroup returns the state of the system group, PhaseAbsent if it does not exist
+func ObserveSystemGroup(c *SumaClient, name string) (SystemGroupStatus, error) {
+	g := &SystemGroup{Name: name}
+	err := g.Read(c)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return SystemGroupStatus{ResourceStatus: newResourceStatus(name, PhaseAbsent)}, nil
+	case err != nil:
+		return failedSystemGroup(name, err), err
+	}
+	return systemGroupStatus(g, ""), nil
+}
+
+// DeleteSystemGroup deletes the system group if it exists
+func DeleteSystemGroup(c *SumaClient, name string) error {
+	g := &SystemGroup{Name: name}
+	err := g.Read(c)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+	return g.Delete(c)
+}
+
+func failedSystemGroup(name string, err error) SystemGroupStatus {
+	s := SystemGroupStatus{ResourceStatus: newResourceStatus(name, PhaseFailed)}
+	s.Message = err.Error()
+	return s
+}
+
+// SumaUserStatus is the observed state of a SUMA user
+type SumaUserStatus struct {
+	ResourceStatus `json:",inline"`
+	FirstName      string `json:"firstName,omitempty"`
+	LastName       string `json:"lastName,omitempty"`
+	Email          string `json:"email,omitempty"`
+}
+
+func sumaUserStatus(u *SumaUser, action ResultAction) SumaUserStatus {
+	s := SumaUserStatus{ResourceStatus: newResourceStatus(u.Login, PhaseReady), FirstName: u.FirstName, LastName: u.LastName, Email: u.Email}
+	s.Action = action
+	return s
+}
+
+// EnsureSumaUser creates the user or updates its names and email. The password is only set when the
+// user is created, SUMA does not return it to compare.
+func EnsureSumaUser(c *SumaClient, desired SumaUser) (SumaUserStatus, error) {
+	current := &SumaUser{Login: desired.Login}
+	err := current.Read(c)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		u := desired
+		if err := u.Create(c); err != nil {
+			return failedSumaUser(desired.Login, err), err
+		}
+		return sumaUserStatus(&u, ResultCreated), nil
+	case err != nil:
+		return failedSumaUser(desired.Login, err), err
+	}
+
+	// empty names and email are not managed
+	changed := false
+	for _, f := range []struct{ current, desired *string }{
+		{&current.FirstName, &desired.FirstName},
+		{&current.LastName, &desired.LastName},
+		{&current.Email, &desired.Email},
+	} {
+		if *f.desired != "" && *f.current != *f.desired {
+			*f.current = *f.desired
+			changed = true
+		}
+	}
+	if !changed {
+		return sumaUserStatus(current, ResultUnchanged), nil
+	}
+	if err := current.Update(c); err != nil {
+		return failedSumaUser(desired.Login, err), err
+	}
+	return sumaUserStatus(current, ResultUpdated), nil
+}
+
+// ObserveSumaUser returns the state of the user, PhaseAbsent if it does not exist
+func ObserveSumaUser(c *SumaClient, login string) (SumaUserStatus, error) {
+	u := &SumaUser{Login: login}
+	err := u.Read(c)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return SumaUserStatus{ResourceStatus: newResourceStatus(login, PhaseAbsent)}, nil
+	case err != nil:
+		return failedSumaUser(login, err), err
+	}
+	return sumaUserStatus(u, ""), nil
+}
+
+// DeleteSumaUser deletes the user if it exists
+func DeleteSumaUser(c *SumaClient, login string) error {
+	u := &SumaUser{Login: login}
+	err := u.Read(c)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+	return u.Delete(c)
+}
+
+func failedSumaUser(login string, err error) SumaUserStatus {
+	s := SumaUserStatus{ResourceStatus: newResourceStatus(login, PhaseFailed)}
+	s.Message = err.Error()
+	return s
+}
+
+// BuildingBlockSpec is the desired building block of EnsureBuildingBlock
+type BuildingBlockSpec struct {
+	// ProjectID is the project of the building block. If set, a building block with the display
+	// name of the payload is adopted instead of creating a second one, e.g. if the status with the
+	// UUID was lost.
+	ProjectID string `json:"projectId,omitempty"`
+	// Payload is the JSON payload to create the building block, see MsCreateBuildingBlock
+	Payload json.RawMessage `json:"payload"`
+}
+
+// BuildingBlockStatus is the observed state of a building block. The phase is PhasePending while
+// Meshstack deploys the building block, the caller polls until it is PhaseReady or PhaseFailed.
+type BuildingBlockStatus struct {
+	ResourceStatus `json:",inline"`
+	// Status is the status of the building block in Meshstack, e.g. SUCCEEDED
+	Status  string            `json:"status,omitempty"`
+	Outputs map[string]string `json:"outputs,omitempty"`
+}
+
+func buildingBlockStatus(b *BuildingBlock, action ResultAction) BuildingBlockStatus {
+	phase := PhasePending
+	switch b.Status {
+	case buildingBlockSucceded:
+		phase = PhaseReady
+	case RunFailed:
+		phase = PhaseFailed
+	}
+	s := BuildingBlockStatus{ResourceStatus: newResourceStatus(b.UUID, phase), Status: b.Status, Outputs: b.Outputs}
+	s.Action = action
+	if phase == PhaseFailed {
+		s.Message = fmt.Sprintf("building block %s failed", b.Name)
+	}
+	return s
+}
+
+// EnsureBuildingBlock creates the building block unless the building block with the UUID of the
+// previous status exists. Building blocks cannot be updated, a changed payload is not applied.
+func EnsureBuildingBlock(c *MsClient, spec BuildingBlockSpec, previous BuildingBlockStatus) (BuildingBlockStatus, error) {
+	uuid := previous.ID
+	if uuid == "" && spec.ProjectID != "" {
+		var err error
+		if uuid, err = findBuildingBlock(c, spec); err != nil {
+			return failedBuildingBlock(uuid, err), err
+		}
+	}
+
+	if uuid != "" {
+		b := &BuildingBlock{UUID: uuid}
+		err := b.Read(c)
+		switch {
+		case err == nil:
+			return buildingBlockStatus(b, ResultUnchanged), nil
+		case !errors.Is(err, ErrNotFound):
+			return failedBuildingBlock(uuid, err), err
+		}
+		// deleted outside of the operator, create it again
+	}
+
+	b := &BuildingBlock{Payload: spec.Payload}
+	if err := b.Create(c); err != nil {
+		return failedBuildingBlock(b.UUID, err), err
+	}
+	return buildingBlockStatus(b, ResultCreated), nil
+}
+
+// findBuildingBlock returns the UUID of the building block with the display name of the payload in
+// the project, empty if there is none
+func findBuildingBlock(c *MsClient, spec BuildingBlockSpec) (string, error) {
+	var payload MsApiCreateBuildingBlock
+	if err := json.Unmarshal(spec.Payload, &payload); err != nil {
+		return "", &ValidationError{Payload: "building block", Field: "payload", Reason: err.Error()}
+	}
+	if payload.Spec.DisplayName == "" {
+		return "", nil
+	}
+	bbs, err := c.ListBuildingBlocks(spec.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for _, bb := range bbs {
+		if bb.Name == payload.Spec.DisplayName {
+			matches = append(matches, bb.UUID)
+		}
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("building block name %q is ambiguous in project %s", payload.Spec.DisplayName, spec.ProjectID)
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return "", nil
+}
+
+// ObserveBuildingBlock returns the state of the building block, PhaseAbsent if it does not exist
+func ObserveBuildingBlock(c *MsClient, uuid string) (BuildingBlockStatus, error) {
+	b := &BuildingBlock{UUID: uuid}
+	err := b.Read(c)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return BuildingBlockStatus{ResourceStatus: newResourceStatus(uuid, PhaseAbsent)}, nil
+	case err != nil:
+		return failedBuildingBlock(uuid, err), err
+	}
+	return buildingBlockStatus(b, ""), nil
+}
+
+// DeleteBuildingBlock deletes the building block if it exists
+func DeleteBuildingBlock(c *MsClient, uuid string) error {
+	if uuid == "" {
+		return nil
+	}
+	b := &BuildingBlock{UUID: uuid}
+	err := b.Read(c)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+	return b.Delete(c)
+}
+
+func failedBuildingBlock(uuid string, err error) BuildingBlockStatus {
+	s := BuildingBlockStatus{ResourceStatus: newResourceStatus(uuid, PhaseFailed)}
+	s.Message = err.Error()
+	return s
+}
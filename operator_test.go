@@ -0,0 +1,135 @@
+package appapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnsureSystemGroup(t *testing.T) {
+	server := newFakeResourceServer(t)
+	defer server.Close()
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	status, err := ObserveSystemGroup(c, "web")
+	if err != nil || status.Phase != PhaseAbsent {
+		t.Fatalf("ObserveSystemGroup() = %+v, %v, want absent", status, err)
+	}
+
+	for _, want := range []ResultAction{ResultCreated, ResultUnchanged} {
+		status, err := EnsureSystemGroup(c, SystemGroup{Name: "web", Description: "web servers"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Action != want || !status.Ready() || status.ID != "web" || status.GroupID != 1 {
+			t.Errorf("EnsureSystemGroup() = %+v, want %s and ready", status, want)
+		}
+	}
+
+	status, err = EnsureSystemGroup(c, SystemGroup{Name: "web", Description: "all web servers"})
+	if err != nil || status.Action != ResultUpdated {
+		t.Errorf("EnsureSystemGroup() with new description = %+v, %v, want updated", status, err)
+	}
+
+	for range 2 {
+		if err := DeleteSystemGroup(c, "web"); err != nil {
+			t.Errorf("DeleteSystemGroup() error = %v", err)
+		}
+	}
+	if status, _ := ObserveSystemGroup(c, "web"); status.Phase != PhaseAbsent {
+		t.Errorf("ObserveSystemGroup() after delete = %+v", status)
+	}
+}
+
+func TestEnsureSumaUser(t *testing.T) {
+	server := newFakeResourceServer(t)
+	defer server.Close()
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	status, err := EnsureSumaUser(c, SumaUser{Login: "user1", Password: "secret"})
+	if err != nil || status.Action != ResultCreated || status.FirstName != "user1" {
+		t.Fatalf("EnsureSumaUser() = %+v, %v, want created", status, err)
+	}
+
+	// the password and empty fields are not compared
+	status, err = EnsureSumaUser(c, SumaUser{Login: "user1", Password: "other"})
+	if err != nil || status.Action != ResultUnchanged {
+		t.Errorf("EnsureSumaUser() = %+v, %v, want unchanged", status, err)
+	}
+
+	status, err = EnsureSumaUser(c, SumaUser{Login: "user1", Email: "user1@example.com"})
+	if err != nil || status.Action != ResultUpdated || status.Email != "user1@example.com" {
+		t.Errorf("EnsureSumaUser() with email = %+v, %v, want updated", status, err)
+	}
+
+	if err := DeleteSumaUser(c, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteSumaUser(c, "user1"); err != nil {
+		t.Errorf("DeleteSumaUser() of a deleted user error = %v", err)
+	}
+}
+
+func TestEnsureBuildingBlock(t *testing.T) {
+	blocks := map[string]string{}
+	created := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/meshobjects/meshbuildingblocks", func(w http.ResponseWriter, r *http.Request) {
+		var payload MsApiCreateBuildingBlock
+		json.NewDecoder(r.Body).Decode(&payload)
+		created++
+		uuid := fmt.Sprintf("bb%d", created)
+		blocks[uuid] = payload.Spec.DisplayName
+		fmt.Fprintf(w, `{"metadata": {"uuid": %q}}`, uuid)
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblocks/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		name, ok := blocks[r.PathValue("uuid")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"metadata": {"uuid": %q, "definitionUuid": "def"}, "spec": {"displayName": %q},
+			"status": {"status": "IN_PROGRESS", "outputs": []}}`, r.PathValue("uuid"), name)
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblocks", func(w http.ResponseWriter, r *http.Request) {
+		var list []map[string]any
+		for uuid, name := range blocks {
+			list = append(list, map[string]any{"metadata": map[string]any{"uuid": uuid}, "spec": map[string]any{"displayName": name}})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"_embedded": map[string]any{"meshBuildingBlocks": list}, "page": map[string]any{"totalPages": 1}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := &MsClient{URL: server.URL, token: "token"}
+
+	spec := BuildingBlockSpec{ProjectID: "web", Payload: json.RawMessage(`{"spec": {"displayName": "vm1"}}`)}
+	status, err := EnsureBuildingBlock(c, spec, BuildingBlockStatus{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Action != ResultCreated || status.ID != "bb1" || status.Phase != PhasePending {
+		t.Errorf("EnsureBuildingBlock() = %+v, want created and pending", status)
+	}
+
+	// the previous status is lost, the building block is found by its name
+	status, err = EnsureBuildingBlock(c, spec, BuildingBlockStatus{})
+	if err != nil || status.Action != ResultUnchanged || status.ID != "bb1" || created != 1 {
+		t.Errorf("EnsureBuildingBlock() without status = %+v, %v, want bb1 unchanged", status, err)
+	}
+
+	// deleted outside of the operator
+	delete(blocks, "bb1")
+	status, err = EnsureBuildingBlock(c, BuildingBlockSpec{Payload: spec.Payload}, status)
+	if err != nil || status.Action != ResultCreated || status.ID != "bb2" {
+		t.Errorf("EnsureBuildingBlock() of a deleted building block = %+v, %v, want bb2 created", status, err)
+	}
+
+	if status, err := ObserveBuildingBlock(c, "bb1"); err != nil || status.Phase != PhaseAbsent {
+		t.Errorf("ObserveBuildingBlock() = %+v, %v, want absent", status, err)
+	}
+	if err := DeleteBuildingBlock(c, "bb1"); err != nil {
+		t.Errorf("DeleteBuildingBlock() of a missing building block error = %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package appapi
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Option changes the settings of a client or of a single call. Options passed to NewSumaClient or
+// NewMsClient apply to every call of the client, options passed to a call are applied after them.
+//
+//	suma, err := NewSumaClient(url, creds, WithTimeout(time.Minute), WithHeader("X-Team", "clab"))
+//	report, err := suma.EnsureGroupMembers(group, hosts, network, WithVerbose(true))
+type Option func(*callOptions)
+
+// callOptions are the settings of a call, see Option
+type callOptions struct {
+	verbose   bool
+	timeout   time.Duration
+	retry     *RetryPolicy
+	header    http.Header
+	requestID string
+	ctx       context.Context
+	transport http.RoundTripper
+	etags     *ETagCache
+	coalesce  bool
+	hostLimit int
+	clock     Clock
+	insecure  bool
+
+	systemMatch SystemMatchPolicy
+
+	compressMin int64
+}
+
+// WithVerbose enables the debug output
+func WithVerbose(verbose bool) Option {
+	return func(o *callOptions) {
+		o.verbose = verbose
+	}
+}
+
+// WithTimeout sets the timeout of an API call including reading the response, 0 disables the
+// timeout. The default is set by APPAPI_TIMEOUT, see Timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetry sets the retry policy of throttled calls instead of Retry
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *callOptions) {
+		o.retry = &policy
+	}
+}
+
+// WithHeader sets an additional header on the requests, e.g. for a proxy
+func WithHeader(key, value string) Option {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = http.Header{}
+		} else {
+			o.header = o.header.Clone()
+		}
+		o.header.Set(key, value)
+	}
+}
+
+// WithRequestID sets the request ID sent in the X-Request-Id header instead of a random ID, e.g. to
+// correlate the calls of one operation
+func WithRequestID(id string) Option {
+	return func(o *callOptions) {
+		o.requestID = id
+	}
+}
+
+// WithContext sends the requests with the context, e.g. of a server request, so they are canceled
+// with it. The timeout of the call still applies.
+func WithContext(ctx context.Context) Option {
+	return func(o *callOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithClock sets the clock of the retries, the polls and the session expiry instead of DefaultClock,
+// e.g. a FakeClock
+func WithClock(clock Clock) Option {
+	return func(o *callOptions) {
+		o.clock = clock
+	}
+}
+
+// WithInsecure accepts a http:// URL of the client and disables the verification of the TLS
+// certificates instead of AllowInsecure, e.g. for a test server
+func WithInsecure(insecure bool) Option {
+	return func(o *callOptions) {
+		o.insecure = insecure
+	}
+}
+
+// WithTransport sends the requests with the round tripper instead of the default transport, e.g. a
+// Recorder. The compression, the size limit and the retries still apply.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *callOptions) {
+		o.transport = rt
+	}
+}
+
+// newCallOptions applies the options to the defaults, verbose is the parameter of the Suma* and
+// Ms* functions
+func newCallOptions(verbose bool, opts []Option) callOptions {
+	o := callOptions{verbose: verbose, timeout: Timeout, coalesce: true, compressMin: RequestCompressionMinSize, systemMatch: MatchError, clock: DefaultClock, insecure: AllowInsecure}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// do sends the request with the headers, timeout and retry policy of the options. Every request gets
+// a request ID, which is logged and returned with the errors of the call, see RequestError.
+func (o callOptions) do(req *http.Request) (*http.Response, error) {
+	if o.ctx != nil {
+		req = req.WithContext(o.ctx)
+	}
+	for key, values := range o.header {
+		req.Header[key] = values
+	}
+
+	id := o.requestID
+	if id == "" {
+		id = newRequestID()
+	}
+	req.Header.Set(requestIDHeader, id)
+
+	client := newHTTPClient()
+	client.Timeout = o.timeout
+	if o.retry != nil {
+		client.Transport.(*apiTransport).retry = o.retry
+	}
+	if o.transport != nil {
+		client.Transport.(*apiTransport).base = o.transport
+	} else {
+		client.Transport.(*apiTransport).base = defaultTransport(o.insecure)
+	}
+	client.Transport.(*apiTransport).etags = o.etags
+	client.Transport.(*apiTransport).coalesce = o.coalesce && CoalesceReads
+	client.Transport.(*apiTransport).hostLimit = o.hostLimit
+	client.Transport.(*apiTransport).compressMin = o.compressMin
+	client.Transport.(*apiTransport).clock = o.clock
+
+	req, trace := withConnTrace(req)
+	start := time.Now()
+	resp, err := client.Do(req)
+	if o.verbose {
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("host", req.URL.Host),
+			slog.String("endpoint", req.URL.Path),
+			slog.String("request_id", id),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			Logger().LogAttrs(req.Context(), slog.LevelDebug, "API call failed", append(attrs, slog.Any("error", err))...)
+		} else {
+			attrs = append(attrs, slog.Int("status", resp.StatusCode), slog.Bool("conn_reused", trace.reused.Load()))
+			Logger().LogAttrs(req.Context(), slog.LevelDebug, "API call", attrs...)
+		}
+	}
+	if err != nil {
+		return nil, &RequestError{RequestID: id, Err: err}
+	}
+	return resp, nil
+}
+
+// withOptions returns the options of a client followed by the options of a call and the resulting
+// verbose setting, which the Suma* and Ms* functions take as parameter
+func withOptions(verbose bool, clientOpts, callOpts []Option) (bool, []Option) {
+	opts := append(append([]Option{}, clientOpts...), callOpts...)
+	return newCallOptions(verbose, opts).verbose, opts
+}
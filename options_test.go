@@ -0,0 +1,114 @@
+package appapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCallOptions(t *testing.T) {
+	var throttled atomic.Int32
+	slowDone := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			defer close(slowDone)
+			time.Sleep(200 * time.Millisecond)
+		case "/throttled":
+			if throttled.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		io.WriteString(w, r.Header.Get("X-Team"))
+	}))
+	defer server.Close()
+
+	get := func(path string, opts ...Option) (*http.Response, error) {
+		t.Helper()
+		throttled.Store(0)
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		return newCallOptions(false, opts).do(req)
+	}
+
+	resp, err := get("/header", WithHeader("X-Team", "clab"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "clab" {
+		t.Errorf("expected header X-Team clab, got %q", body)
+	}
+
+	if _, err := get("/slow", WithTimeout(50*time.Millisecond)); err == nil {
+		t.Error("expected timeout error, got nil")
+	}
+	<-slowDone
+
+	resp, err = get("/throttled", WithRetry(RetryPolicy{}))
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected HTTP/503 without retries, got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	resp, err = get("/throttled", WithRetry(RetryPolicy{Retries: 1, Backoff: time.Millisecond}))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected HTTP/200 after a retry, got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+}
+
+func TestWithOptions(t *testing.T) {
+	clientOpts := []Option{WithVerbose(true), WithHeader("X-Team", "clab")}
+
+	verbose, opts := withOptions(false, clientOpts, []Option{WithHeader("X-Request", "1")})
+	o := newCallOptions(false, opts)
+	if !verbose || o.header.Get("X-Team") != "clab" || o.header.Get("X-Request") != "1" {
+		t.Errorf("expected client and call options, got verbose %v, header %v", verbose, o.header)
+	}
+
+	// options of a call must not change the options of the client
+	if o := newCallOptions(false, clientOpts); o.header.Get("X-Request") != "" {
+		t.Errorf("call option leaked into the client options: %v", o.header)
+	}
+
+	verbose, _ = withOptions(false, clientOpts, []Option{WithVerbose(false)})
+	if verbose {
+		t.Error("expected the call option to override verbose of the client")
+	}
+}
+
+func TestSumaClientOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Team") != "clab" {
+			t.Errorf("%s: expected header X-Team of the client, got %q", r.URL.Path, r.Header.Get("X-Team"))
+		}
+		switch r.URL.Path {
+		case "/rhn/manager/api/auth/login":
+			http.SetCookie(w, &http.Cookie{Name: "pxt-session-cookie", Value: "cookie", MaxAge: 3600})
+		case "/rhn/manager/api/api/systemVersion":
+			io.WriteString(w, `{"success": true, "result": "5.0.2"}`)
+		case "/rhn/manager/api/user/listUsers":
+			if r.Header.Get("X-Request") != "1" {
+				t.Errorf("expected header X-Request of the call, got %q", r.Header.Get("X-Request"))
+			}
+			io.WriteString(w, `{"success": true, "result": [{"login": "admin"}]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewSumaClient(server.URL, NewStaticCredentialProvider(Credentials{SumaUsername: "admin", SumaPassword: "secret"}), WithHeader("X-Team", "clab"), WithInsecure(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	users, err := c.Users(WithHeader("X-Request", "1"))
+	if err != nil || len(users) != 1 || users[0] != "admin" {
+		t.Errorf("Users() = %v, %v, want [admin]", users, err)
+	}
+}
@@ -0,0 +1,74 @@
+package appapi
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// Org is an organization of SUSE Manager
+type Org struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// SumaGetOrg returns the organization with the name, it needs the SUSE Manager administrator role
+func SumaGetOrg(sessioncookie, susemgr, name string, verbose bool, opts ...Option) (org Org, err error) {
+	query := url.Values{"name": {name}}
+	err = sumaGet(sessioncookie, susemgr, "org/getDetails", query, &org, verbose, opts...)
+	return org, err
+}
+
+// SumaMigrateSystems moves systems to another organization and returns the IDs of the migrated
+// systems. The systems lose their system groups and channels of the old organization. It needs the
+// SUSE Manager administrator role.
+func SumaMigrateSystems(sessioncookie, susemgr string, toOrgID int, systemIDs []int, verbose bool, opts ...Option) (migrated []int, err error) {
+	if len(systemIDs) == 0 {
+		return nil, nil
+	}
+	if verbose {
+		Logger().Debug("SUMAAPI SumaMigrateSystems: migrate systems", "org_id", toOrgID, "systems", systemIDs)
+	}
+	payload := map[string]any{"toOrgId": toOrgID, "sids": systemIDs}
+	err = sumaPost(sessioncookie, susemgr, "org/migrateSystems", payload, &migrated, verbose, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(migrated) != len(systemIDs) {
+		var missing []int
+		for _, id := range systemIDs {
+			if !slices.Contains(migrated, id) {
+				missing = append(missing, id)
+			}
+		}
+		return migrated, fmt.Errorf("systems %v were not migrated to organization %d", missing, toOrgID)
+	}
+	return migrated, nil
+}
+
+// MigrateSystems moves systems to the organization with the name, see SumaMigrateSystems
+func (c *SumaClient) MigrateSystems(toOrg string, systemIDs []int, opts ...Option) ([]int, error) {
+	verbose, opts := c.options(opts)
+	defer c.cache.Invalidate(cacheSumaGroupSystems)
+
+	org, err := SumaGetOrg(c.SessionCookie(), c.URL, toOrg, verbose, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not find organization %s: %w", toOrg, err)
+	}
+	return SumaMigrateSystems(c.SessionCookie(), c.URL, org.ID, systemIDs, verbose, opts...)
+}
+
+// MigrateGroup moves the members of a system group to the organization with the name, e.g. when a
+// project moves to another business unit
+func (c *SumaClient) MigrateGroup(group, toOrg string, opts ...Option) ([]int, error) {
+	systems, err := c.GroupSystems(group)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(systems))
+	for i, system := range systems {
+		ids[i] = system.ID
+	}
+	return c.MigrateSystems(toOrg, ids, opts...)
+}
@@ -0,0 +1,49 @@
+package appapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateGroup(t *testing.T) {
+	var payload struct {
+		ToOrgID int   `json:"toOrgId"`
+		IDs     []int `json:"sids"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystemsMinimal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "name": "host1"}, {"id": 2, "name": "host2"}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/org/getDetails", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") != "bu-retail" {
+			t.Errorf("name = %s, want bu-retail", r.URL.Query().Get("name"))
+		}
+		fmt.Fprint(w, `{"success": true, "result": {"id": 7, "name": "bu-retail"}}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/org/migrateSystems", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		fmt.Fprint(w, `{"success": true, "result": [1]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	migrated, err := c.MigrateGroup("clab", "bu-retail")
+	if err == nil {
+		t.Error("expected an error for the system which was not migrated")
+	}
+	if !reflect.DeepEqual(migrated, []int{1}) {
+		t.Errorf("got migrated systems %v, want [1]", migrated)
+	}
+	if payload.ToOrgID != 7 || !reflect.DeepEqual(payload.IDs, []int{1, 2}) {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
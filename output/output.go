@@ -0,0 +1,271 @@
+// Package output renders the results of the appapi functions as JSON, YAML, CSV, NDJSON or aligned
+// tables.
+//
+// Slices of structs are rendered as one row per element with a column per exported field, a single
+// struct or a map as one row. The column name is the field name, it can be changed with an
+// `output:"name"` tag, `output:"-"` hides the field.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format
+type Format string
+
+// The supported output formats
+const (
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	CSV   Format = "csv"
+	Table Format = "table"
+	// NDJSON writes one JSON object per line and element
+	NDJSON Format = "ndjson"
+)
+
+// Formats lists the supported output formats
+var Formats = []Format{Table, JSON, YAML, CSV, NDJSON}
+
+// ParseFormat returns the format with the name, "text" is an alias for table
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case JSON, YAML, CSV, Table, NDJSON:
+		return f, nil
+	case "text":
+		return Table, nil
+	}
+	return "", fmt.Errorf("unknown output format %q", name)
+}
+
+// Render writes v to w in the format
+func Render(w io.Writer, format Format, v any) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	case CSV:
+		header, rows := Rows(v)
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	case Table:
+		header, rows := Rows(v)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		rv := indirect(reflect.ValueOf(v))
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return enc.Encode(v)
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// Rows converts v into a header and rows of cells for the tabular formats
+func Rows(v any) (header []string, rows [][]string) {
+	rv := indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		elem := rv.Type().Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		switch elem.Kind() {
+		case reflect.Struct:
+			header = structHeader(elem)
+			for i := 0; i < rv.Len(); i++ {
+				rows = append(rows, structRow(indirect(rv.Index(i)), elem))
+			}
+		case reflect.Map:
+			header = mapHeader(rv)
+			for i := 0; i < rv.Len(); i++ {
+				rows = append(rows, mapRow(indirect(rv.Index(i)), header))
+			}
+		default:
+			header = []string{"value"}
+			for i := 0; i < rv.Len(); i++ {
+				rows = append(rows, []string{cell(rv.Index(i))})
+			}
+		}
+	case reflect.Struct:
+		header = structHeader(rv.Type())
+		rows = [][]string{structRow(rv, rv.Type())}
+	case reflect.Map:
+		header = mapKeys(rv)
+		rows = [][]string{mapRow(rv, header)}
+	default:
+		header = []string{"value"}
+		rows = [][]string{{cell(rv)}}
+	}
+
+	return header, rows
+}
+
+type column struct {
+	name  string
+	index int
+}
+
+func columns(t reflect.Type) []column {
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("output"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		cols = append(cols, column{name: name, index: i})
+	}
+	return cols
+}
+
+func structHeader(t reflect.Type) []string {
+	var header []string
+	for _, c := range columns(t) {
+		header = append(header, c.name)
+	}
+	return header
+}
+
+func structRow(v reflect.Value, t reflect.Type) []string {
+	var row []string
+	for _, c := range columns(t) {
+		if !v.IsValid() {
+			row = append(row, "")
+			continue
+		}
+		row = append(row, cell(v.Field(c.index)))
+	}
+	return row
+}
+
+// mapHeader is the sorted union of the keys of a slice of maps
+func mapHeader(rv reflect.Value) []string {
+	keys := map[string]bool{}
+	for i := 0; i < rv.Len(); i++ {
+		for _, k := range mapKeys(indirect(rv.Index(i))) {
+			keys[k] = true
+		}
+	}
+	header := make([]string, 0, len(keys))
+	for k := range keys {
+		header = append(header, k)
+	}
+	sort.Strings(header)
+	return header
+}
+
+func mapKeys(v reflect.Value) []string {
+	if !v.IsValid() || v.Kind() != reflect.Map {
+		return nil
+	}
+	var keys []string
+	for _, k := range v.MapKeys() {
+		keys = append(keys, fmt.Sprint(k.Interface()))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func mapRow(v reflect.Value, header []string) []string {
+	values := map[string]string{}
+	if v.IsValid() && v.Kind() == reflect.Map {
+		iter := v.MapRange()
+		for iter.Next() {
+			values[fmt.Sprint(iter.Key().Interface())] = cell(iter.Value())
+		}
+	}
+	row := make([]string, len(header))
+	for i, k := range header {
+		row[i] = values[k]
+	}
+	return row
+}
+
+// cell formats a single value, lists are joined with a comma
+func cell(v reflect.Value) string {
+	if !indirect(v).IsValid() {
+		return ""
+	}
+	switch i := v.Interface().(type) {
+	case error:
+		return i.Error()
+	case fmt.Stringer:
+		return i.String()
+	}
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = cell(v.Index(i))
+		}
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		keys := mapKeys(v)
+		values := mapRow(v, keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + "=" + values[i]
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// indirect follows pointers and interfaces, nil pointers are an invalid value
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
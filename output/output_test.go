@@ -0,0 +1,117 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type system struct {
+	Name   string
+	ID     int `output:"id"`
+	Groups []string
+	secret string
+	Hidden string `output:"-"`
+	Err    error
+}
+
+func TestRender(t *testing.T) {
+	systems := []system{
+		{Name: "host1", ID: 1, Groups: []string{"a", "b"}, secret: "x", Hidden: "y"},
+		{Name: "host2", ID: 2, Err: errors.New("not found")},
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		v      any
+		want   string
+	}{
+		{
+			name:   "table",
+			format: Table,
+			v:      systems,
+			want:   "NAME   ID  GROUPS  ERR\nhost1  1   a,b     \nhost2  2           not found\n",
+		},
+		{
+			name:   "csv",
+			format: CSV,
+			v:      systems,
+			want:   "Name,id,Groups,Err\nhost1,1,\"a,b\",\nhost2,2,,not found\n",
+		},
+		{
+			name:   "csv of pointer to struct",
+			format: CSV,
+			v:      &systems[0],
+			want:   "Name,id,Groups,Err\nhost1,1,\"a,b\",\n",
+		},
+		{
+			name:   "table of map",
+			format: Table,
+			v:      map[string]any{"uuid": "xyz", "status": 200},
+			want:   "STATUS  UUID\n200     xyz\n",
+		},
+		{
+			name:   "csv of maps",
+			format: CSV,
+			v:      []map[string]string{{"a": "1"}, {"b": "2"}},
+			want:   "a,b\n1,\n,2\n",
+		},
+		{
+			name:   "csv of strings",
+			format: CSV,
+			v:      []string{"x", "y"},
+			want:   "value\nx\ny\n",
+		},
+		{
+			name:   "json",
+			format: JSON,
+			v:      map[string]any{"uuid": "xyz"},
+			want:   "{\n  \"uuid\": \"xyz\"\n}\n",
+		},
+		{
+			name:   "yaml",
+			format: YAML,
+			v:      map[string]any{"hosts": []string{"host1", "host2"}},
+			want:   "hosts:\n  - host1\n  - host2\n",
+		},
+		{
+			name:   "ndjson",
+			format: NDJSON,
+			v:      []map[string]any{{"id": 1}, {"id": 2}},
+			want:   "{\"id\":1}\n{\"id\":2}\n",
+		},
+		{
+			name:   "ndjson of a single value",
+			format: NDJSON,
+			v:      map[string]any{"uuid": "xyz"},
+			want:   "{\"uuid\":\"xyz\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, tt.format, tt.v); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"json": JSON, "YAML": YAML, "csv": CSV, "table": Table, "text": Table, "NDJSON": NDJSON} {
+		got, err := ParseFormat(name)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+
+	if _, err := ParseFormat("xml"); err == nil || !strings.Contains(err.Error(), "xml") {
+		t.Errorf("ParseFormat(xml) error = %v, want unknown format", err)
+	}
+}
@@ -0,0 +1,90 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// StreamWriter writes rows one by one as CSV or NDJSON, so a large listing does not have to be
+// collected in a slice before it is rendered.
+//
+//	sw, err := output.NewStreamWriter(os.Stdout, output.CSV)
+//	err = suma.EachSystem(func(s appapi.SystemInfo) error { return sw.Write(s) })
+//	err = sw.Flush()
+//
+// A row is a struct, a pointer to a struct or a map. The CSV header is taken from the first row,
+// the later rows must have the same type and the cells of map keys missing in the header are lost.
+type StreamWriter struct {
+	format Format
+	csv    *csv.Writer
+	json   *json.Encoder
+	header []string
+	typ    reflect.Type
+}
+
+// NewStreamWriter returns a writer of rows to w, the format is CSV or NDJSON. The other formats
+// need all rows at once, see Render.
+func NewStreamWriter(w io.Writer, format Format) (*StreamWriter, error) {
+	sw := &StreamWriter{format: format}
+	switch format {
+	case CSV:
+		sw.csv = csv.NewWriter(w)
+	case NDJSON:
+		sw.json = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("output format %q can not be streamed, use %s or %s", format, CSV, NDJSON)
+	}
+	return sw, nil
+}
+
+// Write writes a row
+func (sw *StreamWriter) Write(v any) error {
+	if sw.json != nil {
+		return sw.json.Encode(v)
+	}
+
+	rv := indirect(reflect.ValueOf(v))
+	if sw.header == nil {
+		switch rv.Kind() {
+		case reflect.Struct:
+			sw.typ = rv.Type()
+			sw.header = structHeader(sw.typ)
+		case reflect.Map:
+			sw.header = mapKeys(rv)
+		default:
+			sw.header = []string{"value"}
+		}
+		if err := sw.csv.Write(sw.header); err != nil {
+			return err
+		}
+	}
+
+	var row []string
+	switch {
+	case sw.typ != nil:
+		if rv.IsValid() && rv.Type() != sw.typ {
+			return fmt.Errorf("row of type %s does not match the header of %s", rv.Type(), sw.typ)
+		}
+		row = structRow(rv, sw.typ)
+	case rv.Kind() == reflect.Map:
+		row = mapRow(rv, sw.header)
+	default:
+		row = []string{cell(reflect.ValueOf(v))}
+	}
+	if err := sw.csv.Write(row); err != nil {
+		return err
+	}
+	return sw.csv.Error()
+}
+
+// Flush writes the buffered rows, it has to be called after the last row
+func (sw *StreamWriter) Flush() error {
+	if sw.csv == nil {
+		return nil
+	}
+	sw.csv.Flush()
+	return sw.csv.Error()
+}
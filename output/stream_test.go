@@ -0,0 +1,90 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStreamWriter(t *testing.T) {
+	systems := []system{
+		{Name: "host1", ID: 1, Groups: []string{"a", "b"}, secret: "x", Hidden: "y"},
+		{Name: "host2", ID: 2, Err: errors.New("not found")},
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		rows   []any
+		want   string
+	}{
+		{
+			name:   "csv",
+			format: CSV,
+			rows:   []any{systems[0], &systems[1]},
+			want:   "Name,id,Groups,Err\nhost1,1,\"a,b\",\nhost2,2,,not found\n",
+		},
+		{
+			name:   "csv of maps",
+			format: CSV,
+			rows:   []any{map[string]string{"a": "1", "b": "2"}, map[string]string{"b": "3", "c": "4"}},
+			want:   "a,b\n1,2\n,3\n",
+		},
+		{
+			name:   "csv of strings",
+			format: CSV,
+			rows:   []any{"x", "y"},
+			want:   "value\nx\ny\n",
+		},
+		{
+			name:   "csv without rows",
+			format: CSV,
+			want:   "",
+		},
+		{
+			name:   "ndjson",
+			format: NDJSON,
+			rows:   []any{map[string]any{"id": 1}, map[string]any{"id": 2}},
+			want:   "{\"id\":1}\n{\"id\":2}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			sw, err := NewStreamWriter(&buf, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, row := range tt.rows {
+				if err := sw.Write(row); err != nil {
+					t.Fatalf("Write(%v) error = %v", row, err)
+				}
+			}
+			if err := sw.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("stream = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamWriterErrors(t *testing.T) {
+	if _, err := NewStreamWriter(&bytes.Buffer{}, Table); err == nil || !strings.Contains(err.Error(), "table") {
+		t.Errorf("NewStreamWriter(table) error = %v, want an error", err)
+	}
+
+	sw, err := NewStreamWriter(&bytes.Buffer{}, CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.Write(system{Name: "host1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.Write(struct{ Name string }{"host2"}); err == nil {
+		t.Error("Write() of another type succeeded, want an error")
+	}
+}
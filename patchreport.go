@@ -0,0 +1,248 @@
+package appapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// The advisory types of errata
+const (
+	AdvisorySecurity    = "Security Advisory"
+	AdvisoryBugfix      = "Bug Fix Advisory"
+	AdvisoryEnhancement = "Product Enhancement Advisory"
+)
+
+// SystemPatchCounts are the numbers of relevant errata of a system by advisory type
+type SystemPatchCounts struct {
+	SystemID    int    `json:"systemId" output:"ID"`
+	Name        string `json:"name"`
+	Security    int    `json:"security"`
+	Bugfix      int    `json:"bugfix"`
+	Enhancement int    `json:"enhancement"`
+}
+
+// Total returns the number of relevant errata of the system
+func (c SystemPatchCounts) Total() int {
+	return c.Security + c.Bugfix + c.Enhancement
+}
+
+// GroupPatchReport is the patch compliance of the members of a system group, see SumaGroupPatchReport
+type GroupPatchReport struct {
+	Group       string              `json:"group"`
+	Systems     []SystemPatchCounts `json:"systems"`
+	Security    int                 `json:"security"`
+	Bugfix      int                 `json:"bugfix"`
+	Enhancement int                 `json:"enhancement"`
+}
+
+// Compliant returns the systems without relevant errata
+func (r GroupPatchReport) Compliant() []SystemPatchCounts {
+	var compliant []SystemPatchCounts
+	for _, s := range r.Systems {
+		if s.Total() == 0 {
+			compliant = append(compliant, s)
+		}
+	}
+	return compliant
+}
+
+// sumaListRelevantErrata returns the advisory types of the errata relevant for a system
+func sumaListRelevantErrata(sessioncookie, susemgr string, id int, verbose bool, opts ...Option) (advisoryTypes []string, err error) {
+	var errata []struct {
+		AdvisoryType string `json:"advisory_type"`
+	}
+	query := url.Values{"sid": {strconv.Itoa(id)}}
+	if err := sumaGet(sessioncookie, susemgr, "system/getRelevantErrata", query, &errata, verbose, opts...); err != nil {
+		return nil, err
+	}
+	for _, e := range errata {
+		advisoryTypes = append(advisoryTypes, e.AdvisoryType)
+	}
+	return advisoryTypes, nil
+}
+
+// SumaGroupPatchReport counts the relevant security, bug fix and enhancement errata of every member
+// of a system group
+func SumaGroupPatchReport(sessioncookie, susemgr, group string, verbose bool, opts ...Option) (report GroupPatchReport, err error) {
+
+	report.Group = group
+
+	systems, err := sumaListGroupSystems(sessioncookie, susemgr, group, verbose, opts...)
+	if err != nil {
+		return report, err
+	}
+	return sumaGroupPatchReport(sessioncookie, susemgr, report, systems, verbose, opts...)
+}
+
+func sumaGroupPatchReport(sessioncookie, susemgr string, report GroupPatchReport, systems []SystemInfo, verbose bool, opts ...Option) (GroupPatchReport, error) {
+	report.Systems = make([]SystemPatchCounts, 0, len(systems))
+	for _, system := range systems {
+		advisoryTypes, err := sumaListRelevantErrata(sessioncookie, susemgr, system.ID, verbose, opts...)
+		if err != nil {
+			return report, err
+		}
+
+		counts := SystemPatchCounts{SystemID: system.ID, Name: system.Name}
+		for _, advisoryType := range advisoryTypes {
+			switch advisoryType {
+			case AdvisorySecurity:
+				counts.Security++
+			case AdvisoryBugfix:
+				counts.Bugfix++
+			case AdvisoryEnhancement:
+				counts.Enhancement++
+			default:
+				logWarnf("system %s has an erratum of the unknown advisory type %q", system.Name, advisoryType)
+			}
+		}
+		if verbose {
+			Logger().Debug("SUMAAPI SumaGroupPatchReport: relevant errata", "group", report.Group, "system", system.Name,
+				"security", counts.Security, "bugfix", counts.Bugfix, "enhancement", counts.Enhancement)
+		}
+
+		report.Systems = append(report.Systems, counts)
+		report.Security += counts.Security
+		report.Bugfix += counts.Bugfix
+		report.Enhancement += counts.Enhancement
+	}
+	return report, nil
+}
+
+// GroupPatchReport counts the relevant errata of the members of a system group, see SumaGroupPatchReport
+func (c *SumaClient) GroupPatchReport(group string, opts ...Option) (GroupPatchReport, error) {
+	verbose, opts := c.options(opts)
+	systems, err := c.GroupSystems(group)
+	if err != nil {
+		return GroupPatchReport{Group: group}, err
+	}
+	return sumaGroupPatchReport(c.SessionCookie(), c.URL, GroupPatchReport{Group: group}, systems, verbose, opts...)
+}
+
+// Erratum is a patch relevant for a system
+type Erratum struct {
+	ID       int    `json:"id"`
+	Advisory string `json:"advisory"`
+	Synopsis string `json:"synopsis"`
+	Type     string `json:"type"`
+	Date     string `json:"date"`
+}
+
+// SumaEachRelevantErrataByType calls fn for every erratum of an advisory type, e.g. AdvisorySecurity,
+// which is relevant for a system. The list is decoded while it is read, an error of fn stops the
+// listing.
+func SumaEachRelevantErrataByType(sessioncookie, susemgr string, systemID int, advisoryType string, fn func(Erratum) error, verbose bool, opts ...Option) error {
+	switch advisoryType {
+	case AdvisorySecurity, AdvisoryBugfix, AdvisoryEnhancement:
+	default:
+		return &ValidationError{Payload: "errata query", Field: "advisoryType", Reason: fmt.Sprintf("%q is not one of %q, %q and %q", advisoryType, AdvisorySecurity, AdvisoryBugfix, AdvisoryEnhancement)}
+	}
+
+	query := url.Values{"sid": {strconv.Itoa(systemID)}, "advisoryType": {advisoryType}}
+	return sumaStream(sessioncookie, susemgr, "system/getRelevantErrataByType", query, func(r struct {
+		ID           int    `json:"id"`
+		Advisory     string `json:"advisory_name"`
+		Synopsis     string `json:"advisory_synopsis"`
+		AdvisoryType string `json:"advisory_type"`
+		Date         string `json:"date"`
+	}) error {
+		return fn(Erratum{ID: r.ID, Advisory: r.Advisory, Synopsis: r.Synopsis, Type: r.AdvisoryType, Date: r.Date})
+	}, verbose, opts...)
+}
+
+// SumaListRelevantErrataByType returns the errata of an advisory type, e.g. AdvisorySecurity, which
+// are relevant for a system, so a security-only patch run does not have to filter all errata
+func SumaListRelevantErrataByType(sessioncookie, susemgr string, systemID int, advisoryType string, verbose bool, opts ...Option) (errata []Erratum, err error) {
+	errata = []Erratum{}
+	err = SumaEachRelevantErrataByType(sessioncookie, susemgr, systemID, advisoryType, func(e Erratum) error {
+		errata = append(errata, e)
+		return nil
+	}, verbose, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return errata, nil
+}
+
+// RelevantErrata returns the errata of an advisory type relevant for a system, see
+// SumaListRelevantErrataByType
+func (c *SumaClient) RelevantErrata(systemID int, advisoryType string, opts ...Option) ([]Erratum, error) {
+	verbose, opts := c.options(opts)
+	return SumaListRelevantErrataByType(c.SessionCookie(), c.URL, systemID, advisoryType, verbose, opts...)
+}
+
+// EachRelevantErrata calls fn for every erratum of an advisory type relevant for a system, see
+// SumaEachRelevantErrataByType
+func (c *SumaClient) EachRelevantErrata(systemID int, advisoryType string, fn func(Erratum) error, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return SumaEachRelevantErrataByType(c.SessionCookie(), c.URL, systemID, advisoryType, fn, verbose, opts...)
+}
+
+// ErrataResult is the result of applying the relevant errata to a system, see SumaApplyRelevantErrata.
+// On an error the fields which are already known, e.g. the system ID, are set.
+type ErrataResult struct {
+	SystemID  int    `json:"systemId" output:"ID"`
+	Hostname  string `json:"hostname"`
+	Errata    int    `json:"errata"`
+	ActionIDs []int  `json:"actionIds" output:"Actions"`
+}
+
+// SumaScheduleApplyErrata schedules the installation of errata on a system and returns the IDs of
+// the scheduled actions
+func SumaScheduleApplyErrata(sessioncookie, susemgr string, systemID int, errataIDs []int, verbose bool, opts ...Option) (actionIDs []int, err error) {
+	if verbose {
+		Logger().Debug("SUMAAPI SumaScheduleApplyErrata: schedule errata", "system_id", systemID, "errata", len(errataIDs))
+	}
+	payload := map[string]any{"sid": systemID, "errataIds": errataIDs}
+	if err := sumaPost(sessioncookie, susemgr, "system/scheduleApplyErrata", payload, &actionIDs, verbose, opts...); err != nil {
+		return nil, err
+	}
+	return actionIDs, nil
+}
+
+// SumaApplyRelevantErrata schedules the installation of the errata of an advisory type, e.g.
+// AdvisorySecurity, which are relevant for a system. A locked system is not patched, nothing is
+// scheduled for a system without relevant errata.
+func SumaApplyRelevantErrata(sessioncookie, susemgr, hostname, advisoryType string, verbose bool, opts ...Option) (result ErrataResult, err error) {
+	return sumaApplyRelevantErrata(sumaHTTP{}, sessioncookie, susemgr, hostname, advisoryType, verbose, opts...)
+}
+
+func sumaApplyRelevantErrata(api sumaAPI, sessioncookie, susemgr, hostname, advisoryType string, verbose bool, opts ...Option) (result ErrataResult, err error) {
+	result.Hostname = hostname
+
+	id, err := api.GetSystemID(sessioncookie, susemgr, hostname, verbose, opts...)
+	if err != nil {
+		return result, err
+	}
+	result.SystemID = id
+
+	if err := sumaRequireUnlocked(api, sessioncookie, susemgr, id, hostname, verbose, opts...); err != nil {
+		return result, err
+	}
+
+	var errataIDs []int
+	err = SumaEachRelevantErrataByType(sessioncookie, susemgr, id, advisoryType, func(e Erratum) error {
+		errataIDs = append(errataIDs, e.ID)
+		return nil
+	}, verbose, opts...)
+	if err != nil {
+		return result, err
+	}
+	result.Errata = len(errataIDs)
+	if len(errataIDs) == 0 {
+		if verbose {
+			Logger().Debug("SUMAAPI SumaApplyRelevantErrata: no relevant errata", "system", hostname, "advisory_type", advisoryType)
+		}
+		return result, nil
+	}
+
+	result.ActionIDs, err = SumaScheduleApplyErrata(sessioncookie, susemgr, id, errataIDs, verbose, opts...)
+	return result, err
+}
+
+// ApplyRelevantErrata schedules the relevant errata of an advisory type on a system, see
+// SumaApplyRelevantErrata
+func (c *SumaClient) ApplyRelevantErrata(hostname, advisoryType string, opts ...Option) (ErrataResult, error) {
+	verbose, opts := c.options(opts)
+	return sumaApplyRelevantErrata(c.suma(), c.SessionCookie(), c.URL, c.Hostnames.Normalize(hostname), advisoryType, verbose, opts...)
+}
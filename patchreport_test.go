@@ -0,0 +1,104 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSumaGroupPatchReport(t *testing.T) {
+	errata := map[string]string{
+		"1": `[{"advisory_type": "Security Advisory"}, {"advisory_type": "Security Advisory"}, {"advisory_type": "Bug Fix Advisory"}]`,
+		"2": `[{"advisory_type": "Product Enhancement Advisory"}]`,
+		"3": `[]`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystemsMinimal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "name": "host1"}, {"id": 2, "name": "host2"}, {"id": 3, "name": "host3"}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/system/getRelevantErrata", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success": true, "result": %s}`, errata[r.URL.Query().Get("sid")])
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	report, err := SumaGroupPatchReport("cookie", server.URL, "clab", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := GroupPatchReport{
+		Group: "clab",
+		Systems: []SystemPatchCounts{
+			{SystemID: 1, Name: "host1", Security: 2, Bugfix: 1},
+			{SystemID: 2, Name: "host2", Enhancement: 1},
+			{SystemID: 3, Name: "host3"},
+		},
+		Security:    2,
+		Bugfix:      1,
+		Enhancement: 1,
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got report %+v, want %+v", report, want)
+	}
+	if compliant := report.Compliant(); len(compliant) != 1 || compliant[0].Name != "host3" {
+		t.Errorf("unexpected compliant systems %+v", compliant)
+	}
+}
+
+func TestSumaListRelevantErrataByType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/system/getRelevantErrataByType" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("advisoryType") != AdvisorySecurity || r.URL.Query().Get("sid") != "42" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 7, "advisory_name": "SUSE-SU-2024:1234-1", "advisory_synopsis": "Security update for openssl", "advisory_type": "Security Advisory", "date": "2024-06-01"}]}`)
+	}))
+	defer server.Close()
+
+	errata, err := SumaListRelevantErrataByType("cookie", server.URL, 42, AdvisorySecurity, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Erratum{{ID: 7, Advisory: "SUSE-SU-2024:1234-1", Synopsis: "Security update for openssl", Type: AdvisorySecurity, Date: "2024-06-01"}}
+	if !reflect.DeepEqual(errata, want) {
+		t.Errorf("got %+v, want %+v", errata, want)
+	}
+
+	if _, err := SumaListRelevantErrataByType("cookie", server.URL, 42, "security", false); err == nil {
+		t.Error("expected an error for an unknown advisory type")
+	}
+}
+
+func TestSumaEachRelevantErrataByType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "advisory_type": "Bug Fix Advisory"}, {"id": 2, "advisory_type": "Bug Fix Advisory"}, {"id": 3, "advisory_type": "Bug Fix Advisory"}]}`)
+	}))
+	defer server.Close()
+
+	// an error of the callback stops the listing
+	stop := errors.New("stop")
+	var ids []int
+	err := SumaEachRelevantErrataByType("cookie", server.URL, 42, AdvisoryBugfix, func(e Erratum) error {
+		ids = append(ids, e.ID)
+		if e.ID == 2 {
+			return stop
+		}
+		return nil
+	}, false)
+	if !errors.Is(err, stop) || !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("SumaEachRelevantErrataByType() = %v after %v, want stop after [1 2]", err, ids)
+	}
+
+	var validation *ValidationError
+	err = SumaEachRelevantErrataByType("cookie", server.URL, 42, "bugfix", func(Erratum) error { return nil }, false)
+	if !errors.As(err, &validation) {
+		t.Errorf("SumaEachRelevantErrataByType(bugfix) error = %v, want a ValidationError", err)
+	}
+}
@@ -0,0 +1,385 @@
+package appapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ValidationError is returned by the payload builders when a field is missing or invalid, so the
+// request is not sent and SUMA or Meshstack does not answer with a 400 Bad Request
+type ValidationError struct {
+	Payload string
+	Field   string
+	Reason  string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s %s", e.Payload, e.Field, e.Reason)
+}
+
+// PasswordPolicy are the rules a password of a new SUMA user has to follow
+type PasswordPolicy struct {
+	MinLength int
+	// MaxLength is the maximum length, 0 is unlimited
+	MaxLength      int
+	RequireDigit   bool
+	RequireUpper   bool
+	RequireLower   bool
+	RequireSpecial bool
+}
+
+// SumaPasswordPolicy is checked by NewSumaApiAddUser, the default are the length limits of SUMA
+var SumaPasswordPolicy = PasswordPolicy{MinLength: 5, MaxLength: 32}
+
+// Check returns the reason why the password does not follow the policy, empty if it does
+func (p PasswordPolicy) Check(password string) string {
+	length := len([]rune(password))
+	switch {
+	case length < p.MinLength:
+		return fmt.Sprintf("must have at least %d characters", p.MinLength)
+	case p.MaxLength > 0 && length > p.MaxLength:
+		return fmt.Sprintf("must have at most %d characters", p.MaxLength)
+	case p.RequireDigit && !strings.ContainsFunc(password, unicode.IsDigit):
+		return "must contain a digit"
+	case p.RequireUpper && !strings.ContainsFunc(password, unicode.IsUpper):
+		return "must contain an upper case letter"
+	case p.RequireLower && !strings.ContainsFunc(password, unicode.IsLower):
+		return "must contain a lower case letter"
+	case p.RequireSpecial && !strings.ContainsFunc(password, isSpecial):
+		return "must contain a special character"
+	}
+	return ""
+}
+
+func isSpecial(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+}
+
+// SumaApiAddUser is the payload of user/create
+type SumaApiAddUser struct {
+	Login     string `json:"login"`
+	Password  string `json:"password"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email"`
+}
+
+// NewSumaApiAddUser returns a validated user/create payload. First and last name default to the login
+// and the email to root@localhost, as SUMA requires them.
+func NewSumaApiAddUser(login, password, firstName, lastName, email string) (SumaApiAddUser, error) {
+	p := SumaApiAddUser{Login: login, Password: password, FirstName: firstName, LastName: lastName, Email: email}
+	if p.FirstName == "" {
+		p.FirstName = login
+	}
+	if p.LastName == "" {
+		p.LastName = login
+	}
+	if p.Email == "" {
+		p.Email = "root@localhost"
+	}
+	return p, p.Validate()
+}
+
+// Validate checks the login, the email and the password against SumaPasswordPolicy
+func (p SumaApiAddUser) Validate() error {
+	invalid := func(field, reason string) error {
+		return &ValidationError{Payload: "user", Field: field, Reason: reason}
+	}
+
+	switch {
+	case p.Login == "":
+		return invalid("login", "is required")
+	case len(p.Login) > 64:
+		return invalid("login", "must have at most 64 characters")
+	case strings.ContainsFunc(p.Login, unicode.IsSpace):
+		return invalid("login", "must not contain spaces")
+	case p.FirstName == "":
+		return invalid("firstName", "is required")
+	case p.LastName == "":
+		return invalid("lastName", "is required")
+	}
+	if addr, err := mail.ParseAddress(p.Email); err != nil || addr.Address != p.Email {
+		return invalid("email", fmt.Sprintf("%q is not an email address", p.Email))
+	}
+	if reason := SumaPasswordPolicy.Check(p.Password); reason != "" {
+		return invalid("password", reason)
+	}
+	return nil
+}
+
+// SumaApiAddRemoveSystem is the payload of systemgroup/addOrRemoveSystems
+type SumaApiAddRemoveSystem struct {
+	SystemGroupName string `json:"systemGroupName"`
+	ServerIds       []int  `json:"serverIds"`
+	Add             bool   `json:"add"`
+}
+
+// NewSumaApiAddRemoveSystem returns a validated systemgroup/addOrRemoveSystems payload
+func NewSumaApiAddRemoveSystem(group string, ids []int, add bool) (SumaApiAddRemoveSystem, error) {
+	p := SumaApiAddRemoveSystem{SystemGroupName: group, ServerIds: ids, Add: add}
+	return p, p.Validate()
+}
+
+// Validate checks that the group is set and the system IDs are valid and unique
+func (p SumaApiAddRemoveSystem) Validate() error {
+	invalid := func(field, reason string) error {
+		return &ValidationError{Payload: "system group change", Field: field, Reason: reason}
+	}
+
+	if p.SystemGroupName == "" {
+		return invalid("systemGroupName", "is required")
+	}
+	if len(p.ServerIds) == 0 {
+		return invalid("serverIds", "are required")
+	}
+	for _, id := range p.ServerIds {
+		if id <= 0 {
+			return invalid("serverIds", fmt.Sprintf("contain the invalid ID %d", id))
+		}
+	}
+
+	// duplicates are next to each other in a sorted copy, which unlike a set needs no allocation
+	sorted := idBuffers.Get().(*[]int)
+	defer putIDBuffer(sorted)
+	*sorted = append((*sorted)[:0], p.ServerIds...)
+	slices.Sort(*sorted)
+	for i := 1; i < len(*sorted); i++ {
+		if (*sorted)[i] == (*sorted)[i-1] {
+			return invalid("serverIds", fmt.Sprintf("contain %d twice", (*sorted)[i]))
+		}
+	}
+	return nil
+}
+
+// AppendJSON appends the JSON encoding of the payload to b. It equals json.Marshal without the
+// reflection and the intermediate buffers, see BenchmarkEncodeAddRemoveSystem.
+func (p SumaApiAddRemoveSystem) AppendJSON(b []byte) []byte {
+	b = append(b, `{"systemGroupName":`...)
+	b = appendJSONString(b, p.SystemGroupName)
+	b = append(b, `,"serverIds":`...)
+	if p.ServerIds == nil {
+		b = append(b, "null"...)
+	} else {
+		b = append(b, '[')
+		for i, id := range p.ServerIds {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = strconv.AppendInt(b, int64(id), 10)
+		}
+		b = append(b, ']')
+	}
+	b = append(b, `,"add":`...)
+	b = strconv.AppendBool(b, p.Add)
+	return append(b, '}')
+}
+
+// encodedSize estimates the size of the JSON encoding to size its buffer
+func (p SumaApiAddRemoveSystem) encodedSize() int {
+	return 48 + len(p.SystemGroupName) + 11*len(p.ServerIds)
+}
+
+// appendDeleteSystem appends the payload of system/deleteSystem, which deletes the system even if
+// the cleanup on the system fails
+func appendDeleteSystem(b []byte, systemID int) []byte {
+	b = append(b, `{"sid":`...)
+	b = strconv.AppendInt(b, int64(systemID), 10)
+	return append(b, `,"cleanupType":"FORCE_DELETE"}`...)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// MsApiBuildingBlockInput is an input of a building block, the value type is STRING, INTEGER or
+// BOOLEAN
+type MsApiBuildingBlockInput struct {
+	Key       string `json:"key"`
+	Value     any    `json:"value"`
+	ValueType string `json:"valueType"`
+}
+
+// MsApiCreateBuildingBlock is the payload to create a building block, see MsCreateBuildingBlock
+type MsApiCreateBuildingBlock struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		DefinitionUUID    string `json:"definitionUuid"`
+		DefinitionVersion int    `json:"definitionVersion"`
+		TenantIdentifier  string `json:"tenantIdentifier"`
+	} `json:"metadata"`
+	Spec struct {
+		DisplayName string                    `json:"displayName"`
+		Inputs      []MsApiBuildingBlockInput `json:"inputs"`
+	} `json:"spec"`
+}
+
+// NewMsApiCreateBuildingBlock returns a validated payload for a building block of the definition in
+// the tenant. The value type of the inputs is derived from the Go type, strings, integers and bools
+// are supported.
+func NewMsApiCreateBuildingBlock(definitionUUID string, definitionVersion int, tenantIdentifier, displayName string, inputs map[string]any) (MsApiCreateBuildingBlock, error) {
+	p := MsApiCreateBuildingBlock{APIVersion: "v1", Kind: "meshBuildingBlock"}
+	p.Metadata.DefinitionUUID = definitionUUID
+	p.Metadata.DefinitionVersion = definitionVersion
+	p.Metadata.TenantIdentifier = tenantIdentifier
+	p.Spec.DisplayName = displayName
+
+	keys := make([]string, 0, len(inputs))
+	for key := range inputs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		input := MsApiBuildingBlockInput{Key: key, Value: inputs[key]}
+		switch inputs[key].(type) {
+		case string:
+			input.ValueType = "STRING"
+		case int, int32, int64:
+			input.ValueType = "INTEGER"
+		case bool:
+			input.ValueType = "BOOLEAN"
+		}
+		p.Spec.Inputs = append(p.Spec.Inputs, input)
+	}
+	return p, p.Validate()
+}
+
+// Validate checks the definition, the tenant, the display name and the inputs
+func (p MsApiCreateBuildingBlock) Validate() error {
+	invalid := func(field, reason string) error {
+		return &ValidationError{Payload: "building block", Field: field, Reason: reason}
+	}
+
+	switch {
+	case !uuidPattern.MatchString(p.Metadata.DefinitionUUID):
+		return invalid("definitionUuid", fmt.Sprintf("%q is not a UUID", p.Metadata.DefinitionUUID))
+	case p.Metadata.DefinitionVersion < 1:
+		return invalid("definitionVersion", "must be at least 1")
+	case p.Metadata.TenantIdentifier == "":
+		return invalid("tenantIdentifier", "is required")
+	case strings.TrimSpace(p.Spec.DisplayName) == "":
+		return invalid("displayName", "is required")
+	}
+	for _, input := range p.Spec.Inputs {
+		if input.Key == "" {
+			return invalid("inputs", "contain an input without key")
+		}
+		if input.ValueType == "" {
+			return invalid("inputs", fmt.Sprintf("%s has the unsupported type %T", input.Key, input.Value))
+		}
+	}
+	return nil
+}
+
+// Marshal validates the payload and returns it as JSON for MsCreateBuildingBlock
+func (p MsApiCreateBuildingBlock) Marshal() ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(p)
+}
+
+var msIdentifierPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,30}[a-z0-9])?$`)
+
+// ValidateMsIdentifier checks the identifier of a meshObject, e.g. a workspace or project, against
+// the rules of Meshstack: up to 32 lower case letters, digits and dashes, starting and ending with a
+// letter or digit
+func ValidateMsIdentifier(kind, identifier string) error {
+	if !msIdentifierPattern.MatchString(identifier) {
+		return &ValidationError{Payload: kind, Field: "identifier", Reason: fmt.Sprintf("%q must have up to 32 lower case letters, digits and dashes", identifier)}
+	}
+	return nil
+}
+
+// The roles of workspace bindings
+const (
+	MsWorkspaceOwner   = "Workspace Owner"
+	MsWorkspaceManager = "Workspace Manager"
+	MsWorkspaceMember  = "Workspace Member"
+)
+
+// MsApiWorkspaceBinding binds a user or a group to a workspace role, e.g. MsWorkspaceOwner
+type MsApiWorkspaceBinding struct {
+	Subject string
+	Group   bool
+	Role    string
+}
+
+// MsApiCreateWorkspace is the payload to create a workspace with its bindings, see MsCreateWorkspace
+type MsApiCreateWorkspace struct {
+	Identifier  string
+	DisplayName string
+	Tags        map[string][]string
+	Bindings    []MsApiWorkspaceBinding
+}
+
+// NewMsApiCreateWorkspace returns a validated payload for a workspace, the owners are bound to the
+// role MsWorkspaceOwner
+func NewMsApiCreateWorkspace(identifier, displayName string, tags map[string][]string, owners ...string) (MsApiCreateWorkspace, error) {
+	p := MsApiCreateWorkspace{Identifier: identifier, DisplayName: displayName, Tags: tags}
+	for _, owner := range owners {
+		p.Bindings = append(p.Bindings, MsApiWorkspaceBinding{Subject: owner, Role: MsWorkspaceOwner})
+	}
+	return p, p.Validate()
+}
+
+// Validate checks the identifier, the display name and the bindings
+func (p MsApiCreateWorkspace) Validate() error {
+	invalid := func(field, reason string) error {
+		return &ValidationError{Payload: "workspace", Field: field, Reason: reason}
+	}
+
+	if err := ValidateMsIdentifier("workspace", p.Identifier); err != nil {
+		return err
+	}
+	if strings.TrimSpace(p.DisplayName) == "" {
+		return invalid("displayName", "is required")
+	}
+	for _, b := range p.Bindings {
+		if b.Subject == "" {
+			return invalid("bindings", "contain a binding without subject")
+		}
+		if !slices.Contains([]string{MsWorkspaceOwner, MsWorkspaceManager, MsWorkspaceMember}, b.Role) {
+			return invalid("bindings", fmt.Sprintf("contain the unknown role %q", b.Role))
+		}
+	}
+	return nil
+}
+
+// Marshal validates the payload and returns the meshObjects of the workspace and its bindings as
+// JSON for MsApplyMeshObject
+func (p MsApiCreateWorkspace) Marshal() ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	type ref struct {
+		Name string `json:"name"`
+	}
+	objects := []any{map[string]any{
+		"apiVersion": "v1",
+		"kind":       "meshWorkspace",
+		"metadata":   map[string]any{"name": p.Identifier, "tags": p.Tags},
+		"spec":       map[string]any{"displayName": p.DisplayName},
+	}}
+	for _, b := range p.Bindings {
+		kind, prefix := "meshWorkspaceUserBinding", "user"
+		if b.Group {
+			kind, prefix = "meshWorkspaceGroupBinding", "group"
+		}
+		role := strings.ToLower(strings.ReplaceAll(b.Role, " ", "-"))
+		objects = append(objects, map[string]any{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata":   map[string]any{"name": fmt.Sprintf("%s-%s-%s-%s", p.Identifier, role, prefix, b.Subject)},
+			"roleRef":    ref{Name: b.Role},
+			"targetRef":  ref{Name: p.Identifier},
+			"subjects":   []ref{{Name: b.Subject}},
+		})
+	}
+	return json.Marshal(objects)
+}
@@ -0,0 +1,164 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewSumaApiAddUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		login     string
+		password  string
+		email     string
+		wantField string
+	}{
+		{name: "valid", login: "clab01", password: "secret", email: "clab@example.com"},
+		{name: "default email", login: "clab01", password: "secret"},
+		{name: "missing login", password: "secret", wantField: "login"},
+		{name: "login with space", login: "clab 01", password: "secret", wantField: "login"},
+		{name: "invalid email", login: "clab01", password: "secret", email: "clab", wantField: "email"},
+		{name: "email with name", login: "clab01", password: "secret", email: "Clab <clab@example.com>", wantField: "email"},
+		{name: "short password", login: "clab01", password: "abc", wantField: "password"},
+		{name: "long password", login: "clab01", password: "0123456789012345678901234567890123", wantField: "password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewSumaApiAddUser(tt.login, tt.password, "", "", tt.email)
+			var verr *ValidationError
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if p.FirstName != tt.login || p.LastName != tt.login || p.Email == "" {
+					t.Errorf("expected defaults for names and email, got %+v", p)
+				}
+				return
+			}
+			if !errors.As(err, &verr) || verr.Field != tt.wantField {
+				t.Errorf("expected validation error for %s, got %v", tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, RequireDigit: true, RequireUpper: true, RequireLower: true, RequireSpecial: true}
+	tests := map[string]string{
+		"Secret-123": "",
+		"Sec-1":      "must have at least 8 characters",
+		"Secret-abc": "must contain a digit",
+		"secret-123": "must contain an upper case letter",
+		"SECRET-123": "must contain a lower case letter",
+		"Secret1234": "must contain a special character",
+	}
+	for password, want := range tests {
+		if got := policy.Check(password); got != want {
+			t.Errorf("Check(%q) = %q, want %q", password, got, want)
+		}
+	}
+}
+
+func TestNewSumaApiAddRemoveSystem(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   string
+		ids     []int
+		wantErr string
+	}{
+		{name: "valid", group: "clab", ids: []int{1, 2}},
+		{name: "missing group", ids: []int{1}, wantErr: "invalid system group change: systemGroupName is required"},
+		{name: "no systems", group: "clab", wantErr: "invalid system group change: serverIds are required"},
+		{name: "invalid ID", group: "clab", ids: []int{1, 0}, wantErr: "invalid system group change: serverIds contain the invalid ID 0"},
+		{name: "duplicate ID", group: "clab", ids: []int{1, 2, 1}, wantErr: "invalid system group change: serverIds contain 1 twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSumaApiAddRemoveSystem(tt.group, tt.ids, true)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSumaApiAddRemoveSystemAppendJSON(t *testing.T) {
+	for _, p := range []SumaApiAddRemoveSystem{
+		{SystemGroupName: "clab", ServerIds: []int{1000010001, 2, 3}, Add: true},
+		{SystemGroupName: "web & db <prod>", ServerIds: []int{7}},
+		{SystemGroupName: "grüne \"Gruppe\"\n"},
+		{ServerIds: []int{}},
+	} {
+		want, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.AppendJSON([]byte("prefix")); string(got) != "prefix"+string(want) {
+			t.Errorf("AppendJSON() = %s, want the json.Marshal result %s", got, want)
+		}
+	}
+
+	want, _ := json.Marshal(struct {
+		ServerID    int    `json:"sid"`
+		CleanupType string `json:"cleanupType"`
+	}{1000010001, "FORCE_DELETE"})
+	if got := appendDeleteSystem(nil, 1000010001); string(got) != string(want) {
+		t.Errorf("appendDeleteSystem() = %s, want %s", got, want)
+	}
+}
+
+func TestNewMsApiCreateBuildingBlock(t *testing.T) {
+	const definition = "0b4a0e1c-3b6e-4f2a-9d7e-5c8f1a2b3c4d"
+
+	p, err := NewMsApiCreateBuildingBlock(definition, 2, "ws.project.platform", "my vm", map[string]any{"size": "small", "count": 2, "backup": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload, err := p.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"apiVersion":"v1","kind":"meshBuildingBlock","metadata":{"definitionUuid":"` + definition + `","definitionVersion":2,"tenantIdentifier":"ws.project.platform"},` +
+		`"spec":{"displayName":"my vm","inputs":[{"key":"backup","value":true,"valueType":"BOOLEAN"},{"key":"count","value":2,"valueType":"INTEGER"},{"key":"size","value":"small","valueType":"STRING"}]}}`
+	if string(payload) != want {
+		t.Errorf("got payload\n%s\nwant\n%s", payload, want)
+	}
+
+	invalid := []struct {
+		name   string
+		uuid   string
+		inputs map[string]any
+		field  string
+	}{
+		{name: "invalid definition", uuid: "abc", field: "definitionUuid"},
+		{name: "unsupported input", uuid: definition, inputs: map[string]any{"size": 1.5}, field: "inputs"},
+	}
+	for _, tt := range invalid {
+		_, err := NewMsApiCreateBuildingBlock(tt.uuid, 1, "ws.project.platform", "my vm", tt.inputs)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != tt.field {
+			t.Errorf("%s: expected validation error for %s, got %v", tt.name, tt.field, err)
+		}
+	}
+}
+
+func TestSumaAddUserValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]any{"success": true, "result": []any{}})
+	}))
+	defer server.Close()
+
+	var verr *ValidationError
+	if _, err := SumaAddUserResult("cookie", "clab01", "abc", server.URL, false); !errors.As(err, &verr) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
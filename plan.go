@@ -0,0 +1,175 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PlanAction is the kind of a planned change
+type PlanAction string
+
+// The actions of a plan
+const (
+	PlanCreate PlanAction = "create"
+	PlanUpdate PlanAction = "update"
+	PlanDelete PlanAction = "delete"
+)
+
+// PlannedChange is a change to a SUMA or Meshstack resource, which is made when the plan is applied
+type PlannedChange struct {
+	Action   PlanAction `json:"action"`
+	Resource string     `json:"resource"`
+	Name     string     `json:"name"`
+	Detail   string     `json:"detail,omitempty"`
+}
+
+func (c PlannedChange) String() string {
+	symbol := map[PlanAction]string{PlanCreate: "+", PlanUpdate: "~", PlanDelete: "-"}[c.Action]
+	s := fmt.Sprintf("%s %s %s", symbol, c.Resource, c.Name)
+	if c.Detail != "" {
+		s += fmt.Sprintf(" (%s)", c.Detail)
+	}
+	return s
+}
+
+// planStep is an API call of a plan, one step can make several changes, e.g. add all systems to a
+// group. undo reverts the changes of the step, it is nil if they cannot be reverted.
+type planStep struct {
+	description string
+	run         func() error
+	undo        func() error
+}
+
+// ErrNotReversible is returned by Plan.ApplyAtomic for a plan with changes which cannot be rolled back
+var ErrNotReversible = errors.New("the plan has changes which cannot be rolled back")
+
+// RollbackError is returned by Plan.ApplyAtomic if a change failed. The changes applied before were
+// rolled back, unless RollbackErr is set: then the resources may be half-updated.
+type RollbackError struct {
+	Err error
+	// RolledBack is the number of API calls which were reverted
+	RolledBack  int
+	RollbackErr error
+}
+
+func (e *RollbackError) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("%v, the rollback failed: %v", e.Err, e.RollbackErr)
+	}
+	return fmt.Sprintf("%v, %d applied calls were rolled back", e.Err, e.RolledBack)
+}
+
+func (e *RollbackError) Unwrap() error {
+	return e.Err
+}
+
+// Plan is the list of changes an operation like SumaEnsureGroupMembers or Sync would make. It is
+// returned by the Plan* functions, so the changes can be reviewed before they are applied.
+type Plan struct {
+	Changes []PlannedChange `json:"changes"`
+
+	steps []planStep
+	after []func()
+}
+
+// Empty reports if the plan makes no changes
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes with the action
+func (p *Plan) Count(action PlanAction) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// String lists the changes and a summary
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "No changes.\n"
+	}
+	var b strings.Builder
+	for _, c := range p.Changes {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete.\n", p.Count(PlanCreate), p.Count(PlanUpdate), p.Count(PlanDelete))
+	return b.String()
+}
+
+// Apply makes the changes of the plan. It stops at the first failed API call.
+func (p *Plan) Apply() error {
+	defer func() {
+		for _, fn := range p.after {
+			fn()
+		}
+	}()
+	for _, step := range p.steps {
+		if err := step.run(); err != nil {
+			return fmt.Errorf("%s: %v", step.description, err)
+		}
+	}
+	return nil
+}
+
+// Reversible reports if all changes of the plan can be rolled back, see ApplyAtomic
+func (p *Plan) Reversible() bool {
+	for _, step := range p.steps {
+		if step.undo == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyAtomic makes the changes of the plan like Apply, but reverts the applied changes in reverse
+// order if a change fails, so the resources are either all changed or not at all. The error is then
+// a RollbackError. A plan which is not Reversible returns ErrNotReversible without changing anything.
+func (p *Plan) ApplyAtomic() error {
+	if !p.Reversible() {
+		return ErrNotReversible
+	}
+	defer func() {
+		for _, fn := range p.after {
+			fn()
+		}
+	}()
+	for i, step := range p.steps {
+		err := step.run()
+		if err == nil {
+			continue
+		}
+		rerr := &RollbackError{Err: fmt.Errorf("%s: %v", step.description, err)}
+		for j := i - 1; j >= 0; j-- {
+			if err := p.steps[j].undo(); err != nil {
+				rerr.RollbackErr = fmt.Errorf("rollback of %s: %v", p.steps[j].description, err)
+				break
+			}
+			rerr.RolledBack++
+		}
+		return rerr
+	}
+	return nil
+}
+
+// add adds changes and the step making them to the plan
+func (p *Plan) add(description string, run func() error, changes ...PlannedChange) {
+	p.addReversible(description, run, nil, changes...)
+}
+
+// addReversible adds changes and the step making them to the plan, undo reverts the step
+func (p *Plan) addReversible(description string, run, undo func() error, changes ...PlannedChange) {
+	p.Changes = append(p.Changes, changes...)
+	p.steps = append(p.steps, planStep{description: description, run: run, undo: undo})
+}
+
+// afterApply registers a function which is called after the plan was applied, also if it failed
+func (p *Plan) afterApply(fn func()) {
+	p.after = append(p.after, fn)
+}
@@ -0,0 +1,128 @@
+package appapi
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// The states of a building block besides the states of its runs
+const (
+	BuildingBlockPending                  = "PENDING"
+	BuildingBlockWaitingForDependentInput = "WAITING_FOR_DEPENDENT_INPUT"
+	BuildingBlockWaitingForOperatorInput  = "WAITING_FOR_OPERATOR_INPUT"
+)
+
+// PollPolicy decides how often the status of a building block is polled. A PENDING building block
+// is about to start and polled fast, a building block IN_PROGRESS is polled slower the longer it
+// runs and a building block waiting for an input, e.g. of an operator, is polled with the longest
+// interval. Polling stops at a terminal state, SUCCEEDED, FAILED or ABORTED. The jitter spreads the
+// calls of hundreds of watched building blocks.
+type PollPolicy struct {
+	// Pending is the interval while the building block is PENDING
+	Pending time.Duration
+	// InProgress is the first interval while the building block is IN_PROGRESS, it grows by Factor
+	// with every poll up to Max
+	InProgress time.Duration
+	Factor     float64
+	// Max is the longest interval and the interval of the waiting building blocks
+	Max time.Duration
+	// Jitter is the fraction by which an interval is randomly shortened or lengthened, e.g. 0.2
+	Jitter float64
+	// Rand returns the random numbers in [0, 1) of the jitter, rand.Float64 if nil
+	Rand func() float64
+}
+
+// DefaultPollPolicy is the poll policy of the building block waits
+var DefaultPollPolicy = PollPolicy{
+	Pending:    5 * time.Second,
+	InProgress: 15 * time.Second,
+	Factor:     1.5,
+	Max:        2 * time.Minute,
+	Jitter:     0.2,
+}
+
+// FixedPollPolicy polls every interval without jitter
+func FixedPollPolicy(interval time.Duration) PollPolicy {
+	return PollPolicy{Pending: interval, InProgress: interval, Factor: 1, Max: interval}
+}
+
+// terminalStatus reports if a building block does not change its status any more
+func terminalStatus(status string) bool {
+	switch status {
+	case buildingBlockSucceded, RunFailed, RunAborted:
+		return true
+	}
+	return false
+}
+
+// Next returns the wait before the next poll of a building block with the status, which was
+// IN_PROGRESS for the last inProgress polls. done is true for a terminal status.
+func (p PollPolicy) Next(status string, inProgress int) (d time.Duration, done bool) {
+	switch {
+	case terminalStatus(status):
+		return 0, true
+	case status == BuildingBlockPending:
+		d = p.Pending
+	case status == RunInProgress:
+		d = p.InProgress
+		for i := 1; i < inProgress && d < p.Max; i++ {
+			d = time.Duration(float64(d) * max(p.Factor, 1))
+		}
+	default:
+		// waiting for an input or an unknown status
+		d = p.Max
+	}
+	if p.Max > 0 {
+		d = min(d, p.Max)
+	}
+
+	if p.Jitter > 0 {
+		random := p.Rand
+		if random == nil {
+			random = rand.Float64
+		}
+		d = time.Duration(float64(d) * (1 + p.Jitter*(2*random()-1)))
+	}
+	return max(d, 0), false
+}
+
+// MsWaitForBuildingBlock polls the status of a building block with the policy until it reaches a
+// terminal state, SUCCEEDED, FAILED or ABORTED, and returns its details. The waits are on the clock
+// of WithClock, the DefaultClock by default. An error of a poll or the cancellation of the context
+// ends the wait, the polls are sent with the context.
+func MsWaitForBuildingBlock(ctx context.Context, apiurl, apikey, UUID string, policy PollPolicy, verbose bool, opts ...Option) (BuildingBlockDetails, error) {
+	clock := newCallOptions(verbose, opts).clock
+	opts = append(slices.Clip(opts), WithContext(ctx))
+	inProgress := 0
+	for {
+		details, err := MsGetBuildingBlockDetails(apiurl, apikey, UUID, verbose, opts...)
+		if err != nil {
+			return details, err
+		}
+		if details.Status == RunInProgress {
+			inProgress++
+		} else {
+			inProgress = 0
+		}
+
+		d, done := policy.Next(details.Status, inProgress)
+		if done {
+			return details, nil
+		}
+		if verbose {
+			logDebugf("MSAPI MsWaitForBuildingBlock: %s is %s, next poll in %v", UUID, details.Status, d)
+		}
+		if err := sleep(ctx, clock, d); err != nil {
+			return details, err
+		}
+	}
+}
+
+// WaitForBuildingBlock waits until the building block reaches a terminal state, see
+// MsWaitForBuildingBlock. The status is always read from Meshstack, not from the read cache.
+func (c *MsClient) WaitForBuildingBlock(ctx context.Context, UUID string, policy PollPolicy, opts ...Option) (BuildingBlockDetails, error) {
+	verbose, opts := c.options(opts)
+	return MsWaitForBuildingBlock(ctx, c.URL, c.Token(), UUID, policy, verbose, opts...)
+}
@@ -0,0 +1,143 @@
+package appapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPollPolicyNext(t *testing.T) {
+	policy := PollPolicy{Pending: 5 * time.Second, InProgress: 10 * time.Second, Factor: 2, Max: time.Minute}
+
+	tests := []struct {
+		status     string
+		inProgress int
+		want       time.Duration
+		wantDone   bool
+	}{
+		{status: BuildingBlockPending, want: 5 * time.Second},
+		{status: RunInProgress, inProgress: 1, want: 10 * time.Second},
+		{status: RunInProgress, inProgress: 2, want: 20 * time.Second},
+		{status: RunInProgress, inProgress: 3, want: 40 * time.Second},
+		{status: RunInProgress, inProgress: 10, want: time.Minute},
+		{status: BuildingBlockWaitingForOperatorInput, want: time.Minute},
+		{status: "SOMETHING_NEW", want: time.Minute},
+		{status: RunSucceeded, wantDone: true},
+		{status: RunFailed, wantDone: true},
+		{status: RunAborted, wantDone: true},
+	}
+	for _, tt := range tests {
+		d, done := policy.Next(tt.status, tt.inProgress)
+		if d != tt.want || done != tt.wantDone {
+			t.Errorf("Next(%s, %d) = %v, %v, want %v, %v", tt.status, tt.inProgress, d, done, tt.want, tt.wantDone)
+		}
+	}
+
+	if d, _ := FixedPollPolicy(time.Second).Next(RunInProgress, 5); d != time.Second {
+		t.Errorf("fixed policy waits %v, want 1s", d)
+	}
+}
+
+func TestPollPolicyJitter(t *testing.T) {
+	policy := PollPolicy{Pending: 10 * time.Second, Jitter: 0.2}
+	for r, want := range map[float64]time.Duration{0: 8 * time.Second, 0.5: 10 * time.Second, 0.75: 11 * time.Second} {
+		policy.Rand = func() float64 { return r }
+		if d, _ := policy.Next(BuildingBlockPending, 0); d != want {
+			t.Errorf("Next() with random %v = %v, want %v", r, d, want)
+		}
+	}
+
+	policy.Rand = nil
+	for range 100 {
+		if d, _ := policy.Next(BuildingBlockPending, 0); d < 8*time.Second || d > 12*time.Second {
+			t.Fatalf("Next() = %v, want 8s to 12s", d)
+		}
+	}
+}
+
+func TestMsWaitForBuildingBlock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	statuses := []string{BuildingBlockPending, RunInProgress, RunInProgress, RunSucceeded}
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[min(int(polls.Add(1))-1, len(statuses)-1)]
+		fmt.Fprintf(w, `{"metadata": {"uuid": "bb1"}, "spec": {"displayName": "vm1"}, "status": {"status": %q, "outputs": [{"key": "hostname", "value": "vm1.example.com"}]}}`, status)
+	}))
+	defer server.Close()
+
+	policy := PollPolicy{Pending: time.Second, InProgress: 10 * time.Second, Factor: 2, Max: time.Minute}
+	type result struct {
+		details BuildingBlockDetails
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		details, err := MsWaitForBuildingBlock(context.Background(), server.URL, "apikey", "bb1", policy, false, WithClock(clock))
+		done <- result{details, err}
+	}()
+
+	// PENDING, IN_PROGRESS and IN_PROGRESS again wait 1s, 10s and 20s
+	for _, d := range []time.Duration{time.Second, 10 * time.Second, 20 * time.Second} {
+		clock.BlockUntil(1)
+		clock.Advance(d)
+	}
+	r := <-done
+	if r.err != nil || r.details.Status != RunSucceeded || r.details.Outputs["hostname"] != "vm1.example.com" {
+		t.Errorf("MsWaitForBuildingBlock() = %+v, %v, want the succeeded building block", r.details, r.err)
+	}
+	if n := polls.Load(); n != 4 {
+		t.Errorf("%d polls, want 4", n)
+	}
+}
+
+func TestMsWaitForBuildingBlockCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"metadata": {"uuid": "bb1"}, "status": "WAITING_FOR_OPERATOR_INPUT"}`)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	details, err := MsWaitForBuildingBlock(ctx, server.URL, "apikey", "bb1", DefaultPollPolicy, false)
+	if !errors.Is(err, context.DeadlineExceeded) || details.Status != BuildingBlockWaitingForOperatorInput {
+		t.Errorf("MsWaitForBuildingBlock() = %s, %v, want the waiting status and the deadline", details.Status, err)
+	}
+}
+
+func TestMsWaitForBuildingBlockCanceledPoll(t *testing.T) {
+	polling, stop := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(polling)
+		select {
+		case <-r.Context().Done():
+		case <-stop:
+		}
+	}))
+	defer server.Close()
+	defer close(stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := MsWaitForBuildingBlock(ctx, server.URL, "apikey", "bb1", PollPolicy{}, false, WithTimeout(0), WithRetry(RetryPolicy{}))
+		done <- err
+	}()
+
+	// the cancellation ends the poll in flight, not only the wait between the polls
+	<-polling
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("MsWaitForBuildingBlock() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the poll was not canceled with the context")
+	}
+}
@@ -0,0 +1,115 @@
+package appapi
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoAutoinstallation is returned by SumaGetAutoinstallStatus if no autoinstallation was scheduled
+// for the system
+var ErrNoAutoinstallation = errors.New("no autoinstallation scheduled")
+
+// SystemEvent is an entry of the event history of a system, e.g. a scheduled action
+type SystemEvent struct {
+	ID        int       `json:"id"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	Summary   string    `json:"summary"`
+	Message   string    `json:"message,omitempty" output:"-"`
+	Created   time.Time `json:"created"`
+	Completed time.Time `json:"completed"`
+}
+
+// Done reports if the event is completed or failed
+func (e SystemEvent) Done() bool {
+	return e.Status == "Completed" || e.Status == "Failed"
+}
+
+// SumaObtainReactivationKey returns a new reactivation key of a system. A rebuilt system registered
+// with the key keeps its system ID, groups and channels. The previous key of the system is invalid then.
+func SumaObtainReactivationKey(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (key string, err error) {
+	payload := map[string]any{"sid": systemID}
+	err = sumaPost(sessioncookie, susemgr, "system/obtainReactivationKey", payload, &key, verbose, opts...)
+	return key, err
+}
+
+// SumaListSystemEvents returns the event history of a system
+func SumaListSystemEvents(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (events []SystemEvent, err error) {
+
+	type ResultEvent struct {
+		ID          int    `json:"id"`
+		HistoryType string `json:"history_type"`
+		Status      string `json:"status"`
+		Summary     string `json:"summary"`
+		ResultMsg   string `json:"result_msg"`
+		Created     string `json:"created"`
+		Completed   string `json:"completed"`
+	}
+
+	var result []ResultEvent
+	query := url.Values{"sid": {strconv.Itoa(systemID)}}
+	if err := sumaGet(sessioncookie, susemgr, "system/listSystemEvents", query, &result, verbose, opts...); err != nil {
+		return nil, err
+	}
+
+	events = make([]SystemEvent, 0, len(result))
+	for _, r := range result {
+		events = append(events, SystemEvent{
+			ID:        r.ID,
+			Type:      r.HistoryType,
+			Status:    r.Status,
+			Summary:   r.Summary,
+			Message:   r.ResultMsg,
+			Created:   parseSumaTime(r.Created),
+			Completed: parseSumaTime(r.Completed),
+		})
+	}
+	return events, nil
+}
+
+// isAutoinstallation reports if an event is an autoinstallation, which older versions call kickstart
+func isAutoinstallation(e SystemEvent) bool {
+	t := strings.ToLower(e.Type)
+	return strings.Contains(t, "auto installation") || strings.Contains(t, "autoinstallation") || strings.Contains(t, "kickstart")
+}
+
+// SumaGetAutoinstallStatus returns the latest autoinstallation event of a system, e.g. to track the
+// progress of a rebuild. It returns ErrNoAutoinstallation if there is none.
+func SumaGetAutoinstallStatus(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (event SystemEvent, err error) {
+	events, err := SumaListSystemEvents(sessioncookie, susemgr, systemID, verbose, opts...)
+	if err != nil {
+		return event, err
+	}
+
+	found := false
+	for _, e := range events {
+		if isAutoinstallation(e) && (!found || e.Created.After(event.Created)) {
+			event, found = e, true
+		}
+	}
+	if !found {
+		return event, ErrNoAutoinstallation
+	}
+	return event, nil
+}
+
+// ReactivationKey returns a new reactivation key of a system, see SumaObtainReactivationKey
+func (c *SumaClient) ReactivationKey(systemID int, opts ...Option) (string, error) {
+	verbose, opts := c.options(opts)
+	return SumaObtainReactivationKey(c.SessionCookie(), c.URL, systemID, verbose, opts...)
+}
+
+// SystemEvents returns the event history of a system, see SumaListSystemEvents
+func (c *SumaClient) SystemEvents(systemID int, opts ...Option) ([]SystemEvent, error) {
+	verbose, opts := c.options(opts)
+	return SumaListSystemEvents(c.SessionCookie(), c.URL, systemID, verbose, opts...)
+}
+
+// AutoinstallStatus returns the latest autoinstallation event of a system, see SumaGetAutoinstallStatus
+func (c *SumaClient) AutoinstallStatus(systemID int, opts ...Option) (SystemEvent, error) {
+	verbose, opts := c.options(opts)
+	return SumaGetAutoinstallStatus(c.SessionCookie(), c.URL, systemID, verbose, opts...)
+}
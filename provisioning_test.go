@@ -0,0 +1,54 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSumaReactivationKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/system/obtainReactivationKey" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"success": true, "result": "re-1-0123456789abcdef"}`)
+	}))
+	defer server.Close()
+
+	key, err := SumaObtainReactivationKey("cookie", server.URL, 42, false)
+	if err != nil || key != "re-1-0123456789abcdef" {
+		t.Errorf("SumaObtainReactivationKey() = %q, %v", key, err)
+	}
+}
+
+func TestSumaGetAutoinstallStatus(t *testing.T) {
+	events := map[string]string{
+		"42": `[
+			{"id": 1, "history_type": "Initiate an auto installation", "status": "Failed", "summary": "first try", "created": "2024-06-01T08:00:00Z"},
+			{"id": 3, "history_type": "Package Install", "status": "Completed", "created": "2024-06-03T08:00:00Z"},
+			{"id": 2, "history_type": "Initiate an auto installation", "status": "Picked Up", "summary": "rebuild", "created": "2024-06-02T08:00:00Z"}
+		]`,
+		"43": `[{"id": 4, "history_type": "Package Install", "status": "Completed", "created": "2024-06-03T08:00:00Z"}]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/system/listSystemEvents" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"success": true, "result": %s}`, events[r.URL.Query().Get("sid")])
+	}))
+	defer server.Close()
+
+	event, err := SumaGetAutoinstallStatus("cookie", server.URL, 42, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.ID != 2 || event.Status != "Picked Up" || event.Done() {
+		t.Errorf("expected the latest autoinstallation, got %+v", event)
+	}
+
+	if _, err := SumaGetAutoinstallStatus("cookie", server.URL, 43, false); !errors.Is(err, ErrNoAutoinstallation) {
+		t.Errorf("expected ErrNoAutoinstallation, got %v", err)
+	}
+}
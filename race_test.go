@@ -0,0 +1,6 @@
+//go:build race
+
+package appapi
+
+// raceEnabled reports if the tests run with the race detector, which adds allocations
+const raceEnabled = true
@@ -0,0 +1,123 @@
+package appapi
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Keys of the cached reads. Keys ending with ":" are prefixes followed by the group, project or UUID.
+const (
+	cacheSumaGroups       = "suma:groups"
+	cacheSumaUsers        = "suma:users"
+	cacheSumaRoles        = "suma:roles"
+	cacheSumaGroupSystems = "suma:group-systems:"
+	cacheMsBuildingBlocks = "ms:building-blocks:"
+	cacheMsBuildingBlock  = "ms:building-block:"
+)
+
+// ReadCache caches the results of idempotent GET calls of a client in memory for a TTL. Mutations
+// made through the client invalidate the related entries. A nil ReadCache caches nothing.
+type ReadCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]readCacheEntry
+}
+
+type readCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// NewReadCache creates a cache which keeps results for ttl
+func NewReadCache(ttl time.Duration) *ReadCache {
+	return &ReadCache{ttl: ttl, now: time.Now, entries: map[string]readCacheEntry{}}
+}
+
+// Invalidate removes the entries with the keys, a key ending with ":" removes all entries with the prefix
+func (c *ReadCache) Invalidate(keys ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ":") {
+			delete(c.entries, key)
+			continue
+		}
+		for k := range c.entries {
+			if strings.HasPrefix(k, key) {
+				delete(c.entries, k)
+			}
+		}
+	}
+}
+
+// Clear removes all entries
+func (c *ReadCache) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]readCacheEntry{}
+}
+
+func (c *ReadCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *ReadCache) put(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = readCacheEntry{value: value, expires: c.now().Add(c.ttl)}
+}
+
+// cachedRead returns the cached result of key or calls load and caches its result. Errors are not
+// cached. The results are copied when they are cached and when they are returned, so a caller
+// changing its result does not change the cache.
+func cachedRead[T any](c *ReadCache, key string, load func() (T, error)) (T, error) {
+	if c == nil {
+		return load()
+	}
+	if value, ok := c.get(key); ok {
+		return cloneRead(value).(T), nil
+	}
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	c.put(key, cloneRead(value))
+	return value, nil
+}
+
+// cloneRead returns a copy of a cached result, which does not share its slices and maps
+func cloneRead(value any) any {
+	switch v := value.(type) {
+	case []string:
+		return slices.Clone(v)
+	case []Role:
+		return slices.Clone(v)
+	case []SystemInfo:
+		return slices.Clone(v)
+	case []BuildingBlockType:
+		return slices.Clone(v)
+	case BuildingBlockDetails:
+		v.Inputs = maps.Clone(v.Inputs)
+		v.Outputs = maps.Clone(v.Outputs)
+		return v
+	}
+	return value
+}
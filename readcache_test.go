@@ -0,0 +1,141 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadCache(t *testing.T) {
+	now := time.Now()
+	cache := NewReadCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		return loads, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if v, err := cachedRead(cache, "a", load); err != nil || v != 1 {
+			t.Fatalf("cachedRead() = %d, %v, want 1", v, err)
+		}
+	}
+
+	// expired
+	now = now.Add(time.Minute)
+	if v, _ := cachedRead(cache, "a", load); v != 2 {
+		t.Errorf("cachedRead() after ttl = %d, want 2", v)
+	}
+
+	// invalidate by key and by prefix
+	cache.Invalidate("a")
+	if v, _ := cachedRead(cache, "a", load); v != 3 {
+		t.Errorf("cachedRead() after Invalidate = %d, want 3", v)
+	}
+	_, _ = cachedRead(cache, "group:x", load)
+	_, _ = cachedRead(cache, "group:y", load)
+	cache.Invalidate("group:")
+	if v, _ := cachedRead(cache, "group:y", load); v != 6 {
+		t.Errorf("cachedRead() after prefix Invalidate = %d, want 6", v)
+	}
+	if v, _ := cachedRead(cache, "a", load); v != 3 {
+		t.Errorf("Invalidate(group:) removed a, got %d", v)
+	}
+
+	// errors are not cached
+	if _, err := cachedRead(cache, "err", func() (int, error) { return 0, errors.New("failed") }); err == nil {
+		t.Errorf("cachedRead() error = nil, want error")
+	}
+	if v, err := cachedRead(cache, "err", load); err != nil || v != 7 {
+		t.Errorf("cachedRead() after error = %d, %v, want 7", v, err)
+	}
+
+	// a nil cache always loads
+	var none *ReadCache
+	none.Invalidate("a")
+	none.Clear()
+	if v, _ := cachedRead(none, "a", load); v != 8 {
+		t.Errorf("cachedRead(nil) = %d, want 8", v)
+	}
+}
+
+func TestSumaClientReadCache(t *testing.T) {
+	calls := map[string]int{}
+	users := `[]`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listAllGroups", func(w http.ResponseWriter, r *http.Request) {
+		calls["listAllGroups"]++
+		fmt.Fprint(w, `{"success": true, "result": [{"name": "group1"}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/listUsers", func(w http.ResponseWriter, r *http.Request) {
+		calls["listUsers"]++
+		fmt.Fprintf(w, `{"success": true, "result": %s}`, users)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/create", func(w http.ResponseWriter, r *http.Request) {
+		users = `[{"login": "user1"}]`
+		fmt.Fprint(w, `{"success": true, "result": 1}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+	c.EnableReadCache(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		groups, err := c.SystemGroups()
+		if err != nil || len(groups) != 1 || groups[0] != "group1" {
+			t.Fatalf("SystemGroups() = %v, %v", groups, err)
+		}
+	}
+	if calls["listAllGroups"] != 1 {
+		t.Errorf("listAllGroups called %d times, want 1", calls["listAllGroups"])
+	}
+
+	if got, _ := c.Users(); len(got) != 0 {
+		t.Fatalf("Users() = %v, want none", got)
+	}
+
+	// AddUser checks the user list itself and invalidates the cached list
+	if _, err := c.AddUser("user1", "secret"); err != nil {
+		t.Fatalf("AddUser() error = %v", err)
+	}
+	got, _ := c.Users()
+	if len(got) != 1 || got[0] != "user1" {
+		t.Errorf("Users() after AddUser = %v, want [user1]", got)
+	}
+	if _, _ = c.Users(); calls["listUsers"] != 3 {
+		t.Errorf("listUsers called %d times, want 3", calls["listUsers"])
+	}
+}
+
+func TestReadCacheReturnsCopies(t *testing.T) {
+	cache := NewReadCache(time.Minute)
+	groups := []string{"group1", "group2"}
+	loadGroups := func() ([]string, error) { return groups, nil }
+
+	got, _ := cachedRead(cache, cacheSumaGroups, loadGroups)
+	got[0] = "changed"
+	groups[1] = "changed"
+	if got, _ := cachedRead(cache, cacheSumaGroups, loadGroups); got[0] != "group1" || got[1] != "group2" {
+		t.Errorf("cachedRead() after changing the results = %v, want [group1 group2]", got)
+	}
+
+	details := BuildingBlockDetails{UUID: "bb-1", Inputs: map[string]string{"size": "small"}, Outputs: map[string]string{"hostname": "vm1"}}
+	loadDetails := func() (BuildingBlockDetails, error) { return details, nil }
+	first, _ := cachedRead(cache, cacheMsBuildingBlock+"bb-1", loadDetails)
+	first.Inputs["size"] = "large"
+	delete(first.Outputs, "hostname")
+	second, _ := cachedRead(cache, cacheMsBuildingBlock+"bb-1", loadDetails)
+	if second.Inputs["size"] != "small" || second.Outputs["hostname"] != "vm1" {
+		t.Errorf("cachedRead() after changing the details = %+v, want the cached inputs and outputs", second)
+	}
+	second.Outputs["hostname"] = "vm2"
+	if third, _ := cachedRead(cache, cacheMsBuildingBlock+"bb-1", loadDetails); third.Outputs["hostname"] != "vm1" {
+		t.Errorf("cachedRead() returned the outputs of an earlier result: %+v", third)
+	}
+}
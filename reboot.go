@@ -0,0 +1,92 @@
+package appapi
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// RebootStatus is the kernel and reboot state of a system
+type RebootStatus struct {
+	SystemID      int    `json:"systemId" output:"ID"`
+	Name          string `json:"name"`
+	RunningKernel string `json:"runningKernel"`
+	// LivePatch is the kernel live patch applied to the running kernel, empty if there is none
+	LivePatch string `json:"livePatch"`
+	// RebootSuggested is set if SUSE Manager suggests a reboot, e.g. after a kernel update which was
+	// not live patched
+	RebootSuggested bool `json:"rebootSuggested" output:"Reboot"`
+}
+
+// SumaListSuggestedReboot returns the systems which should be rebooted
+func SumaListSuggestedReboot(sessioncookie, susemgr string, verbose bool, opts ...Option) (systems []SystemInfo, err error) {
+	var result []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := sumaGet(sessioncookie, susemgr, "system/listSuggestedReboot", nil, &result, verbose, opts...); err != nil {
+		return nil, err
+	}
+	for _, r := range result {
+		systems = append(systems, SystemInfo{ID: r.ID, Name: r.Name})
+	}
+	return systems, nil
+}
+
+// SumaGetKernelLivePatch returns the kernel live patch of a system, empty if it has none
+func SumaGetKernelLivePatch(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (livePatch string, err error) {
+	query := url.Values{"sid": {strconv.Itoa(systemID)}}
+	err = sumaGet(sessioncookie, susemgr, "system/getKernelLivePatch", query, &livePatch, verbose, opts...)
+	return livePatch, err
+}
+
+// SumaGetRunningKernel returns the version of the running kernel of a system
+func SumaGetRunningKernel(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (kernel string, err error) {
+	query := url.Values{"sid": {strconv.Itoa(systemID)}}
+	err = sumaGet(sessioncookie, susemgr, "system/getRunningKernel", query, &kernel, verbose, opts...)
+	return kernel, err
+}
+
+// SumaGroupRebootStatus returns the kernel and reboot state of the members of a system group, e.g.
+// to plan which systems need a reboot window
+func SumaGroupRebootStatus(sessioncookie, susemgr, group string, verbose bool, opts ...Option) (status []RebootStatus, err error) {
+	systems, err := sumaListGroupSystems(sessioncookie, susemgr, group, verbose, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return sumaRebootStatus(sessioncookie, susemgr, systems, verbose, opts...)
+}
+
+func sumaRebootStatus(sessioncookie, susemgr string, systems []SystemInfo, verbose bool, opts ...Option) (status []RebootStatus, err error) {
+	suggested, err := SumaListSuggestedReboot(sessioncookie, susemgr, verbose, opts...)
+	if err != nil {
+		return nil, err
+	}
+	reboot := make(map[int]bool, len(suggested))
+	for _, system := range suggested {
+		reboot[system.ID] = true
+	}
+
+	status = make([]RebootStatus, 0, len(systems))
+	for _, system := range systems {
+		s := RebootStatus{SystemID: system.ID, Name: system.Name, RebootSuggested: reboot[system.ID]}
+		if s.RunningKernel, err = SumaGetRunningKernel(sessioncookie, susemgr, system.ID, verbose, opts...); err != nil {
+			return status, err
+		}
+		if s.LivePatch, err = SumaGetKernelLivePatch(sessioncookie, susemgr, system.ID, verbose, opts...); err != nil {
+			return status, err
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// GroupRebootStatus returns the kernel and reboot state of the members of a system group, see
+// SumaGroupRebootStatus
+func (c *SumaClient) GroupRebootStatus(group string, opts ...Option) ([]RebootStatus, error) {
+	verbose, opts := c.options(opts)
+	systems, err := c.GroupSystems(group)
+	if err != nil {
+		return nil, err
+	}
+	return sumaRebootStatus(c.SessionCookie(), c.URL, systems, verbose, opts...)
+}
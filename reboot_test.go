@@ -0,0 +1,41 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSumaGroupRebootStatus(t *testing.T) {
+	livePatches := map[string]string{"1": "kgraft_patch_5_14_21-150500_55_49-default", "2": ""}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystemsMinimal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "name": "host1"}, {"id": 2, "name": "host2"}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/system/listSuggestedReboot", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 2, "name": "host2"}, {"id": 9, "name": "other"}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/system/getRunningKernel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": "5.14.21-150500.55.49-default"}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/system/getKernelLivePatch", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success": true, "result": %q}`, livePatches[r.URL.Query().Get("sid")])
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	status, err := SumaGroupRebootStatus("cookie", server.URL, "clab", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []RebootStatus{
+		{SystemID: 1, Name: "host1", RunningKernel: "5.14.21-150500.55.49-default", LivePatch: "kgraft_patch_5_14_21-150500_55_49-default"},
+		{SystemID: 2, Name: "host2", RunningKernel: "5.14.21-150500.55.49-default", RebootSuggested: true},
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("got %+v, want %+v", status, want)
+	}
+}
@@ -0,0 +1,205 @@
+package appapi
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GroupChangeReport lists the changes made to a system group by SumaEnsureGroupMembers
+type GroupChangeReport struct {
+	Group     string
+	Added     []string
+	Removed   []string
+	Unchanged []string
+}
+
+// Changed reports if the membership of the group was changed
+func (r GroupChangeReport) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0
+}
+
+// SumaEnsureGroupMembers makes the members of a system group match desiredHosts. It compares the
+// desired systems with the current members and only adds the missing and removes the surplus systems.
+// Every system to add must be registered in SUSE Manager and belong to the permitted network,
+// otherwise nothing is changed and an error is returned.
+func SumaEnsureGroupMembers(sessioncookie, susemgr, group string, desiredHosts []string, network string, verbose bool, opts ...Option) (report GroupChangeReport, err error) {
+
+	plan, report, err := SumaPlanGroupMembers(sessioncookie, susemgr, group, desiredHosts, network, verbose, opts...)
+	if err != nil {
+		return report, err
+	}
+	return report, plan.Apply()
+}
+
+// SumaPlanGroupMembers returns the plan of SumaEnsureGroupMembers without changing anything
+func SumaPlanGroupMembers(sessioncookie, susemgr, group string, desiredHosts []string, network string, verbose bool, opts ...Option) (plan *Plan, report GroupChangeReport, err error) {
+
+	if verbose {
+		logDebugf("SUMAAPI SumaPlanGroupMembers: Enter function")
+		logDebugf("SUMAAPI SumaPlanGroupMembers: ==============")
+		defer logDebugf("SUMAAPI SumaPlanGroupMembers: Leave function")
+	}
+
+	current, err := sumaListGroupSystems(sessioncookie, susemgr, group, verbose, opts...)
+	if err != nil {
+		return nil, GroupChangeReport{Group: group}, err
+	}
+
+	plan = &Plan{}
+	report, err = sumaPlanGroupMembers(sumaHTTP{}, plan, sessioncookie, susemgr, group, current, desiredHosts, network, verbose, opts...)
+	return plan, report, err
+}
+
+// sumaPlanGroupMembers adds the membership changes of a group with the current members to the plan
+func sumaPlanGroupMembers(api sumaAPI, plan *Plan, sessioncookie, susemgr, group string, current []SystemInfo, desiredHosts []string, network string, verbose bool, opts ...Option) (report GroupChangeReport, err error) {
+
+	report.Group = group
+
+	currentIDs := make(map[int]string, len(current))
+	for _, system := range current {
+		currentIDs[system.ID] = system.Name
+	}
+
+	// resolve the desired hosts before changing anything
+	desiredIDs := make(map[int]string, len(desiredHosts))
+	var addIDs []int
+	for _, hostname := range desiredHosts {
+		id, err := sumaGetSystemIDInNetwork(api, sessioncookie, susemgr, hostname, network, verbose, opts...)
+		if err != nil {
+			return report, err
+		}
+		desiredIDs[id] = hostname
+
+		if _, ok := currentIDs[id]; ok {
+			report.Unchanged = append(report.Unchanged, hostname)
+			continue
+		}
+
+		ip, err := api.GetSystemIP(sessioncookie, susemgr, id, verbose, opts...)
+		if err != nil {
+			return report, err
+		}
+		if !sumaSystemInNetwork(api, sessioncookie, susemgr, id, ip, network, verbose, opts...) {
+			return report, fmt.Errorf("%s cannot be added, the system does not belong to the permitted network", hostname)
+		}
+
+		addIDs = append(addIDs, id)
+		report.Added = append(report.Added, hostname)
+	}
+
+	var removeIDs []int
+	for id, name := range currentIDs {
+		if _, ok := desiredIDs[id]; !ok {
+			removeIDs = append(removeIDs, id)
+			report.Removed = append(report.Removed, name)
+		}
+	}
+	sort.Ints(removeIDs)
+	sort.Strings(report.Removed)
+
+	detail := "group " + group
+
+	if len(addIDs) > 0 {
+		var changes []PlannedChange
+		for _, hostname := range report.Added {
+			changes = append(changes, PlannedChange{Action: PlanCreate, Resource: "suma system group member", Name: hostname, Detail: detail})
+		}
+		plan.addReversible(fmt.Sprintf("could not add systems to group %s", group), func() error {
+			if verbose {
+				Logger().Debug("SUMAAPI SumaEnsureGroupMembers: add systems", "group", group, "hosts", report.Added)
+			}
+			return sumaAddOrRemoveSystems(sessioncookie, susemgr, group, addIDs, true, verbose, opts...)
+		}, func() error {
+			return sumaAddOrRemoveSystems(sessioncookie, susemgr, group, addIDs, false, verbose, opts...)
+		}, changes...)
+	}
+
+	if len(removeIDs) > 0 {
+		var changes []PlannedChange
+		for _, hostname := range report.Removed {
+			changes = append(changes, PlannedChange{Action: PlanDelete, Resource: "suma system group member", Name: hostname, Detail: detail})
+		}
+		plan.addReversible(fmt.Sprintf("could not remove systems from group %s", group), func() error {
+			if verbose {
+				Logger().Debug("SUMAAPI SumaEnsureGroupMembers: remove systems", "group", group, "hosts", report.Removed)
+			}
+			return sumaAddOrRemoveSystems(sessioncookie, susemgr, group, removeIDs, false, verbose, opts...)
+		}, func() error {
+			return sumaAddOrRemoveSystems(sessioncookie, susemgr, group, removeIDs, true, verbose, opts...)
+		}, changes...)
+	}
+
+	return report, nil
+}
+
+// EnsureGroupMembers makes the members of a system group match desiredHosts, see SumaEnsureGroupMembers
+func (c *SumaClient) EnsureGroupMembers(group string, desiredHosts []string, network string, opts ...Option) (GroupChangeReport, error) {
+	plan, report, err := c.PlanGroupMembers(group, desiredHosts, network, opts...)
+	if err != nil {
+		return report, err
+	}
+	return report, plan.Apply()
+}
+
+// PlanGroupMembers returns the plan of EnsureGroupMembers, see SumaPlanGroupMembers
+func (c *SumaClient) PlanGroupMembers(group string, desiredHosts []string, network string, opts ...Option) (*Plan, GroupChangeReport, error) {
+	current, err := c.GroupSystems(group, opts...)
+	verbose, opts := c.options(opts)
+	if err != nil {
+		return nil, GroupChangeReport{Group: group}, err
+	}
+
+	plan := &Plan{}
+	plan.afterApply(func() { c.cache.Invalidate(cacheSumaGroupSystems + group) })
+	report, err := sumaPlanGroupMembers(c.suma(), plan, c.SessionCookie(), c.URL, group, current, c.Hostnames.NormalizeAll(desiredHosts), network, verbose, opts...)
+	return plan, report, err
+}
+
+// GroupMembers are the desired members of a system group in a batch, see SumaClient.ApplyGroupBatch
+type GroupMembers struct {
+	Group   string
+	Hosts   []string
+	Network string
+}
+
+// PlanGroupBatch returns one plan with the membership changes of several groups, see
+// ApplyGroupBatch. Every host to add is resolved and checked against the network of its group,
+// otherwise nothing is planned and an error is returned.
+func (c *SumaClient) PlanGroupBatch(batch []GroupMembers, opts ...Option) (*Plan, []GroupChangeReport, error) {
+	callOpts := opts
+	verbose, opts := c.options(opts)
+	plan := &Plan{}
+	reports := make([]GroupChangeReport, 0, len(batch))
+	seen := make(map[string]bool, len(batch))
+	for _, members := range batch {
+		group := members.Group
+		if seen[group] {
+			return nil, reports, fmt.Errorf("group %s is twice in the batch", group)
+		}
+		seen[group] = true
+
+		current, err := c.GroupSystems(group, callOpts...)
+		if err != nil {
+			return nil, reports, fmt.Errorf("group %s: %w", group, err)
+		}
+		plan.afterApply(func() { c.cache.Invalidate(cacheSumaGroupSystems + group) })
+		report, err := sumaPlanGroupMembers(c.suma(), plan, c.SessionCookie(), c.URL, group, current, c.Hostnames.NormalizeAll(members.Hosts), members.Network, verbose, opts...)
+		if err != nil {
+			return nil, reports, fmt.Errorf("group %s: %w", group, err)
+		}
+		reports = append(reports, report)
+	}
+	return plan, reports, nil
+}
+
+// ApplyGroupBatch makes the members of several groups match in one transaction: all hosts are
+// validated before any group is changed, and if a change fails, the changes applied before are
+// rolled back, see Plan.ApplyAtomic and RollbackError. The reports are returned in the order of the
+// batch.
+func (c *SumaClient) ApplyGroupBatch(batch []GroupMembers, opts ...Option) ([]GroupChangeReport, error) {
+	plan, reports, err := c.PlanGroupBatch(batch, opts...)
+	if err != nil {
+		return reports, err
+	}
+	return reports, plan.ApplyAtomic()
+}
@@ -0,0 +1,262 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// newFakeGroupServer simulates the SUMA endpoints used by SumaEnsureGroupMembers.
+// systems maps hostnames to ids, members are the ids in the group.
+func newFakeGroupServer(t *testing.T, systems map[string]int, members []int, changes *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(newFakeGroupMux(t, systems, members, changes))
+}
+
+func newFakeGroupMux(t *testing.T, systems map[string]int, members []int, changes *[]string) *http.ServeMux {
+	t.Helper()
+
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/system/getId", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := systems[r.URL.Query().Get("name")]
+		if !ok {
+			fmt.Fprint(w, `{"success": true, "result": []}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success": true, "result": [{"id": %d, "name": "%s"}]}`, id, r.URL.Query().Get("name"))
+	})
+	mux.HandleFunc("/rhn/manager/api/system/getNetwork", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success": true, "result": {"ip": "192.168.1.%s"}}`, r.URL.Query().Get("sid"))
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystemsMinimal", func(w http.ResponseWriter, r *http.Request) {
+		var result []map[string]any
+		for name, id := range systems {
+			for _, m := range members {
+				if m == id {
+					result = append(result, map[string]any{"id": id, "name": name})
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"success": true, "result": result})
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/addOrRemoveSystems", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			ServerIds []int `json:"serverIds"`
+			Add       bool  `json:"add"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		*changes = append(*changes, fmt.Sprintf("add=%v %v", payload.Add, payload.ServerIds))
+		fmt.Fprint(w, `{"success": true, "result": 1}`)
+	})
+
+	return mux
+}
+
+func TestSumaEnsureGroupMembers(t *testing.T) {
+	systems := map[string]int{"host1": 1, "host2": 2, "host3": 3}
+
+	tests := []struct {
+		name        string
+		members     []int
+		desired     []string
+		network     string
+		wantAdded   []string
+		wantRemoved []string
+		wantChanges []string
+		wantErr     bool
+	}{
+		{
+			name:        "add and remove",
+			members:     []int{1, 3},
+			desired:     []string{"host1", "host2"},
+			network:     "192.168.1.0/24",
+			wantAdded:   []string{"host2"},
+			wantRemoved: []string{"host3"},
+			wantChanges: []string{"add=true [2]", "add=false [3]"},
+		},
+		{
+			name:        "already in sync",
+			members:     []int{1, 2},
+			desired:     []string{"host2", "host1"},
+			network:     "192.168.1.0/24",
+			wantChanges: nil,
+		},
+		{
+			name:        "unknown host changes nothing",
+			members:     []int{1},
+			desired:     []string{"host1", "unknown"},
+			network:     "192.168.1.0/24",
+			wantChanges: nil,
+			wantErr:     true,
+		},
+		{
+			name:        "system outside network changes nothing",
+			members:     []int{},
+			desired:     []string{"host1"},
+			network:     "10.0.0.0/8",
+			wantChanges: nil,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changes []string
+			server := newFakeGroupServer(t, systems, tt.members, &changes)
+			defer server.Close()
+
+			report, err := SumaEnsureGroupMembers("cookie", server.URL, "group", tt.desired, tt.network, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SumaEnsureGroupMembers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("changes = %v, want %v", changes, tt.wantChanges)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(report.Added, tt.wantAdded) || !reflect.DeepEqual(report.Removed, tt.wantRemoved) {
+				t.Errorf("report = %+v, want added %v removed %v", report, tt.wantAdded, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+// newBatchServer simulates the system groups of SUMA, the members are changed by addOrRemoveSystems.
+// Adding systems to the group failAdd fails.
+func newBatchServer(t *testing.T, groups map[string][]int, failAdd string) (*httptest.Server, func() map[string][]int) {
+	t.Helper()
+	systems := map[string]int{"host1": 1, "host2": 2, "host3": 3, "host4": 4}
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+	// the systems are resolved by the fake group server
+	mux.Handle("/rhn/manager/api/system/", newFakeGroupMux(t, systems, nil, new([]string)))
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystemsMinimal", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		result := []map[string]any{}
+		for _, id := range groups[r.URL.Query().Get("systemGroupName")] {
+			result = append(result, map[string]any{"id": id, "name": fmt.Sprintf("host%d", id)})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"success": true, "result": result})
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/addOrRemoveSystems", func(w http.ResponseWriter, r *http.Request) {
+		var payload SumaApiAddRemoveSystem
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if payload.Add && payload.SystemGroupName == failAdd {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		members := groups[payload.SystemGroupName]
+		for _, id := range payload.ServerIds {
+			if payload.Add {
+				members = append(members, id)
+			} else {
+				members = slices.DeleteFunc(members, func(m int) bool { return m == id })
+			}
+		}
+		slices.Sort(members)
+		groups[payload.SystemGroupName] = members
+		fmt.Fprint(w, `{"success": true, "result": 1}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, func() map[string][]int {
+		mu.Lock()
+		defer mu.Unlock()
+		return maps.Clone(groups)
+	}
+}
+
+func TestApplyGroupBatch(t *testing.T) {
+	batch := []GroupMembers{
+		{Group: "web", Hosts: []string{"host1", "host3"}, Network: "192.168.1.0/24"},
+		{Group: "db", Hosts: []string{"host4"}, Network: "192.168.1.0/24"},
+	}
+	initial := func() map[string][]int { return map[string][]int{"web": {1, 2}, "db": {3}} }
+
+	t.Run("applied", func(t *testing.T) {
+		server, groups := newBatchServer(t, initial(), "")
+		c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+		reports, err := c.ApplyGroupBatch(batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reports) != 2 || !reflect.DeepEqual(reports[1].Added, []string{"host4"}) {
+			t.Errorf("reports = %+v", reports)
+		}
+		if want := map[string][]int{"web": {1, 3}, "db": {4}}; !reflect.DeepEqual(groups(), want) {
+			t.Errorf("groups = %v, want %v", groups(), want)
+		}
+	})
+
+	t.Run("rolled back", func(t *testing.T) {
+		server, groups := newBatchServer(t, initial(), "db")
+		c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+		_, err := c.ApplyGroupBatch(batch)
+		var rerr *RollbackError
+		if !errors.As(err, &rerr) || rerr.RolledBack != 2 || rerr.RollbackErr != nil {
+			t.Fatalf("expected a RollbackError of 2 calls, got %v", err)
+		}
+		if !reflect.DeepEqual(groups(), initial()) {
+			t.Errorf("groups = %v after the rollback, want %v", groups(), initial())
+		}
+	})
+
+	t.Run("invalid host", func(t *testing.T) {
+		server, groups := newBatchServer(t, initial(), "")
+		c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+		invalid := append(slices.Clone(batch), GroupMembers{Group: "app", Hosts: []string{"unknown"}})
+		if _, err := c.ApplyGroupBatch(invalid); err == nil {
+			t.Fatal("expected an error for an unknown host")
+		}
+		if !reflect.DeepEqual(groups(), initial()) {
+			t.Errorf("groups = %v, nothing must be changed", groups())
+		}
+	})
+}
+
+func TestPlanApplyAtomic(t *testing.T) {
+	var calls []string
+	step := func(name string, err error) func() error {
+		return func() error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	plan := &Plan{}
+	plan.addReversible("a", step("a", nil), step("undo a", nil))
+	plan.addReversible("b", step("b", nil), step("undo b", errors.New("gone")))
+	plan.addReversible("c", step("c", errors.New("failed")), step("undo c", nil))
+	err := plan.ApplyAtomic()
+	var rerr *RollbackError
+	if !errors.As(err, &rerr) || rerr.RolledBack != 0 || rerr.RollbackErr == nil {
+		t.Errorf("expected a failed rollback, got %v", err)
+	}
+	if want := []string{"a", "b", "c", "undo b"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	calls = nil
+	plan.add("d", step("d", nil))
+	if err := plan.ApplyAtomic(); !errors.Is(err, ErrNotReversible) || len(calls) != 0 {
+		t.Errorf("expected ErrNotReversible without calls, got %v, %v", err, calls)
+	}
+}
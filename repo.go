@@ -0,0 +1,105 @@
+package appapi
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// The types of custom repositories
+const (
+	RepoTypeYum = "yum"
+	RepoTypeULN = "uln"
+	RepoTypeDeb = "deb"
+)
+
+// Repo is a custom repository which can be associated with software channels
+type Repo struct {
+	ID    int    `json:"id"`
+	Label string `json:"label"`
+	URL   string `json:"sourceUrl"`
+	Type  string `json:"type"`
+}
+
+// validateRepo checks the label, the type and the URL of a new repository
+func validateRepo(label, repoType, repoURL string) error {
+	invalid := func(field, reason string) error {
+		return &ValidationError{Payload: "repository", Field: field, Reason: reason}
+	}
+	switch {
+	case label == "":
+		return invalid("label", "is required")
+	case !slices.Contains([]string{RepoTypeYum, RepoTypeULN, RepoTypeDeb}, repoType):
+		return invalid("type", fmt.Sprintf("%q is not one of yum, uln and deb", repoType))
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Scheme != "file") {
+		return invalid("url", fmt.Sprintf("%q is not an absolute URL", repoURL))
+	}
+	return nil
+}
+
+// SumaCreateRepo creates a custom repository
+func SumaCreateRepo(sessioncookie, susemgr, label, repoType, repoURL string, verbose bool, opts ...Option) (repo Repo, err error) {
+	if err := validateRepo(label, repoType, repoURL); err != nil {
+		return repo, err
+	}
+	payload := map[string]any{"label": label, "type": repoType, "url": repoURL}
+	err = sumaPost(sessioncookie, susemgr, "channel/software/createRepo", payload, &repo, verbose, opts...)
+	return repo, err
+}
+
+// SumaAssociateRepo associates a custom repository with a software channel, the channel syncs its
+// packages from the repository then
+func SumaAssociateRepo(sessioncookie, susemgr, channelLabel, repoLabel string, verbose bool, opts ...Option) (err error) {
+	payload := map[string]any{"channelLabel": channelLabel, "repoLabel": repoLabel}
+	return sumaPost(sessioncookie, susemgr, "channel/software/associateRepo", payload, nil, verbose, opts...)
+}
+
+// SumaListUserRepos returns the custom repositories of the organization of the user
+func SumaListUserRepos(sessioncookie, susemgr string, verbose bool, opts ...Option) (repos []Repo, err error) {
+	err = sumaGet(sessioncookie, susemgr, "channel/software/listUserRepos", nil, &repos, verbose, opts...)
+	return repos, err
+}
+
+// Repos returns the custom repositories, see SumaListUserRepos
+func (c *SumaClient) Repos(opts ...Option) ([]Repo, error) {
+	verbose, opts := c.options(opts)
+	return SumaListUserRepos(c.SessionCookie(), c.URL, verbose, opts...)
+}
+
+// CreateRepo creates a custom repository, see SumaCreateRepo
+func (c *SumaClient) CreateRepo(label, repoType, repoURL string, opts ...Option) (Repo, error) {
+	verbose, opts := c.options(opts)
+	return SumaCreateRepo(c.SessionCookie(), c.URL, label, repoType, repoURL, verbose, opts...)
+}
+
+// AssociateRepo associates a custom repository with a software channel, see SumaAssociateRepo
+func (c *SumaClient) AssociateRepo(channelLabel, repoLabel string, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return SumaAssociateRepo(c.SessionCookie(), c.URL, channelLabel, repoLabel, verbose, opts...)
+}
+
+// AttachRepo associates a third-party repository with a software channel. The repository is
+// created unless a repository with the label exists, which must have the same URL.
+func (c *SumaClient) AttachRepo(channelLabel, repoLabel, repoType, repoURL string, opts ...Option) (Repo, error) {
+	repos, err := c.Repos(opts...)
+	if err != nil {
+		return Repo{}, err
+	}
+
+	i := slices.IndexFunc(repos, func(r Repo) bool { return r.Label == repoLabel })
+	var repo Repo
+	if i < 0 {
+		if repo, err = c.CreateRepo(repoLabel, repoType, repoURL, opts...); err != nil {
+			return repo, err
+		}
+	} else {
+		repo = repos[i]
+		if repo.URL != repoURL {
+			return repo, fmt.Errorf("repository %s exists with the URL %s instead of %s", repoLabel, repo.URL, repoURL)
+		}
+	}
+
+	return repo, c.AssociateRepo(channelLabel, repoLabel, opts...)
+}
@@ -0,0 +1,68 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAttachRepo(t *testing.T) {
+	var calls []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/channel/software/listUserRepos", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "listUserRepos")
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "label": "docker-ce", "sourceUrl": "https://download.docker.com/linux/sles/15/x86_64/stable", "type": "yum"}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/channel/software/createRepo", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "createRepo")
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		fmt.Fprintf(w, `{"success": true, "result": {"id": 2, "label": %q, "sourceUrl": %q, "type": %q}}`, payload["label"], payload["url"], payload["type"])
+	})
+	mux.HandleFunc("/rhn/manager/api/channel/software/associateRepo", func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		calls = append(calls, "associateRepo "+payload["channelLabel"]+" "+payload["repoLabel"])
+		fmt.Fprint(w, `{"success": true, "result": {"id": 10, "label": "clab-sles15"}}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	repo, err := c.AttachRepo("clab-sles15", "grafana", RepoTypeYum, "https://rpm.grafana.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.ID != 2 || repo.URL != "https://rpm.grafana.com" {
+		t.Errorf("unexpected repo %+v", repo)
+	}
+
+	if _, err := c.AttachRepo("clab-sles15", "docker-ce", RepoTypeYum, "https://download.docker.com/linux/sles/15/x86_64/stable"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.AttachRepo("clab-sles15", "docker-ce", RepoTypeYum, "https://example.com/docker"); err == nil {
+		t.Error("expected an error for a repository with another URL")
+	}
+
+	want := "[listUserRepos createRepo associateRepo clab-sles15 grafana listUserRepos associateRepo clab-sles15 docker-ce listUserRepos]"
+	if got := fmt.Sprint(calls); got != want {
+		t.Errorf("got calls %s, want %s", got, want)
+	}
+
+	var verr *ValidationError
+	if _, err := c.CreateRepo("grafana", "rpm", "https://rpm.grafana.com"); !errors.As(err, &verr) || verr.Field != "type" {
+		t.Errorf("expected validation error for the type, got %v", err)
+	}
+	if _, err := c.CreateRepo("grafana", RepoTypeYum, "rpm.grafana.com"); !errors.As(err, &verr) || verr.Field != "url" {
+		t.Errorf("expected validation error for the url, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package appapi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// requestIDHeader carries the request ID of a call to SUMA and Meshstack, so a call can be found in
+// the logs of the client and of the server
+const requestIDHeader = "X-Request-Id"
+
+// newRequestID returns a random request ID. Without randomness the ID is derived from the time, so
+// the calls can still be correlated.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		logWarnf("could not create a random request ID: %v", err)
+		return fmt.Sprintf("%016x", uint64(time.Now().UnixNano()))
+	}
+	return hex.EncodeToString(b)
+}
+
+// RequestError is an error of an API call with the request ID of the call
+type RequestError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%v (request %s)", e.Err, e.RequestID)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// RequestIDOf returns the request ID of the first RequestError in the chain of err, empty if there is
+// none
+func RequestIDOf(err error) string {
+	var rerr *RequestError
+	if errors.As(err, &rerr) {
+		return rerr.RequestID
+	}
+	return ""
+}
+
+// requestID returns the request ID sent with the request of resp
+func requestID(resp *http.Response) string {
+	if resp == nil || resp.Request == nil {
+		return ""
+	}
+	return resp.Request.Header.Get(requestIDHeader)
+}
+
+// withRequestID adds the request ID of the call of resp to err
+func withRequestID(resp *http.Response, err error) error {
+	if id := requestID(resp); id != "" {
+		return &RequestError{RequestID: id, Err: err}
+	}
+	return err
+}
+
+// statusError returns the error of an unexpected HTTP status with the request ID of the call
+func statusError(resp *http.Response) error {
+	return withRequestID(resp, fmt.Errorf("HTTP Request failed: HTTP/%d", resp.StatusCode))
+}
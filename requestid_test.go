@@ -0,0 +1,48 @@
+package appapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-Id"))
+		if r.URL.Path == "/rhn/manager/api/user/listUsers" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"success": false, "message": "no such group"}`))
+	}))
+	defer server.Close()
+
+	_, err := sumaListUsers("cookie", server.URL, false)
+	if err == nil {
+		t.Fatal("expected error for HTTP/500, got nil")
+	}
+	if id := RequestIDOf(err); id == "" || id != ids[0] || !strings.Contains(err.Error(), "(request "+id+")") {
+		t.Errorf("expected the request ID %s in %v", ids[0], err)
+	}
+
+	err = sumaGet("cookie", server.URL, "systemgroup/getDetails", nil, nil, false, WithRequestID("bulk-1"))
+	if err == nil || RequestIDOf(err) != "bulk-1" || ids[1] != "bulk-1" {
+		t.Errorf("expected the request ID bulk-1, got %v, sent %q", err, ids[1])
+	}
+
+	if ids[0] == "" || ids[0] == ids[1] {
+		t.Errorf("expected unique request IDs, got %v", ids)
+	}
+}
+
+func TestRequestIDTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	err := sumaGet("cookie", server.URL, "user/listUsers", nil, nil, false, WithRequestID("failed-1"))
+	if err == nil || !strings.Contains(err.Error(), "request failed-1") {
+		t.Errorf("expected the request ID in %v", err)
+	}
+}
@@ -0,0 +1,331 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// ErrNotFound is returned by the Read and Import methods of the resources if the resource does not exist
+// (anymore), e.g. so a Terraform provider can remove it from the state.
+var ErrNotFound = errors.New("resource not found")
+
+// ErrUpdateNotSupported is returned by Update if the resource cannot be changed and has to be replaced
+var ErrUpdateNotSupported = errors.New("update not supported, the resource has to be replaced")
+
+// The resources SystemGroup, SumaUser, ActivationKey and BuildingBlock support the life cycle of a
+// Terraform resource. ID returns a stable identifier which is set after Create and accepted by the
+// Import* functions. Read refreshes the computed attributes from the server.
+
+// SystemGroup is a SUMA system group, the ID is the name
+type SystemGroup struct {
+	Name        string
+	Description string
+
+	// computed
+	GroupID     int
+	SystemCount int
+}
+
+// ID returns the name of the group
+func (g *SystemGroup) ID() string {
+	return g.Name
+}
+
+// Create creates the system group
+func (g *SystemGroup) Create(c *SumaClient) error {
+	payload := map[string]string{"name": g.Name, "description": g.Description}
+	err := c.post("systemgroup/create", payload, nil)
+	c.cache.Invalidate(cacheSumaGroups)
+	if err != nil {
+		return err
+	}
+	return g.Read(c)
+}
+
+// Read refreshes the group from SUMA
+func (g *SystemGroup) Read(c *SumaClient) error {
+	groups, err := c.SystemGroups()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(groups, g.Name) {
+		return fmt.Errorf("system group %s: %w", g.Name, ErrNotFound)
+	}
+
+	var details struct {
+		ID          int    `json:"id"`
+		Description string `json:"description"`
+		SystemCount int    `json:"system_count"`
+	}
+	query := url.Values{"systemGroupName": {g.Name}}
+	if err := c.get("systemgroup/getDetails", query, &details); err != nil {
+		return err
+	}
+	g.GroupID = details.ID
+	g.Description = details.Description
+	g.SystemCount = details.SystemCount
+	return nil
+}
+
+// Update sets the description of the group
+func (g *SystemGroup) Update(c *SumaClient) error {
+	payload := map[string]string{"systemGroupName": g.Name, "description": g.Description}
+	return c.post("systemgroup/update", payload, nil)
+}
+
+// Delete deletes the group
+func (g *SystemGroup) Delete(c *SumaClient) error {
+	defer c.cache.Invalidate(cacheSumaGroups, cacheSumaGroupSystems+g.Name)
+	payload := map[string]string{"systemGroupName": g.Name}
+	return c.post("systemgroup/delete", payload, nil)
+}
+
+// ImportSystemGroup reads an existing system group
+func ImportSystemGroup(c *SumaClient, id string) (*SystemGroup, error) {
+	g := &SystemGroup{Name: id}
+	if err := g.Read(c); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SumaUser is a SUMA user, the ID is the login. The password is only written.
+type SumaUser struct {
+	Login     string
+	Password  string `json:"-" output:"-"`
+	FirstName string
+	LastName  string
+	Email     string
+}
+
+// ID returns the login of the user
+func (u *SumaUser) ID() string {
+	return u.Login
+}
+
+// Create creates the user, first and last name default to the login as with SumaAddUser
+func (u *SumaUser) Create(c *SumaClient) error {
+	payload, err := NewSumaApiAddUser(u.Login, u.Password, u.FirstName, u.LastName, u.Email)
+	if err != nil {
+		return err
+	}
+	u.FirstName, u.LastName, u.Email = payload.FirstName, payload.LastName, payload.Email
+
+	defer c.cache.Invalidate(cacheSumaUsers)
+	return c.post("user/create", payload, nil)
+}
+
+// Read refreshes the user from SUMA
+func (u *SumaUser) Read(c *SumaClient) error {
+	users, err := c.Users()
+	if err != nil {
+		return err
+	}
+	if !containsFold(users, u.Login) {
+		return fmt.Errorf("user %s: %w", u.Login, ErrNotFound)
+	}
+
+	var details struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Email     string `json:"email"`
+	}
+	query := url.Values{"login": {u.Login}}
+	if err := c.get("user/getDetails", query, &details); err != nil {
+		return err
+	}
+	u.FirstName = details.FirstName
+	u.LastName = details.LastName
+	u.Email = details.Email
+	return nil
+}
+
+// Update sets the names, the email and, if not empty, the password of the user
+func (u *SumaUser) Update(c *SumaClient) error {
+	details := map[string]string{
+		"first_name": u.FirstName,
+		"last_name":  u.LastName,
+		"email":      u.Email,
+	}
+	if u.Password != "" {
+		details["password"] = u.Password
+	}
+	payload := map[string]any{"login": u.Login, "details": details}
+	return c.post("user/setDetails", payload, nil)
+}
+
+// Delete deletes the user
+func (u *SumaUser) Delete(c *SumaClient) error {
+	defer c.cache.Invalidate(cacheSumaUsers)
+	payload := map[string]string{"login": u.Login}
+	return c.post("user/delete", payload, nil)
+}
+
+// ImportSumaUser reads an existing user
+func ImportSumaUser(c *SumaClient, id string) (*SumaUser, error) {
+	u := &SumaUser{Login: id}
+	if err := u.Read(c); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// ActivationKey is a SUMA activation key, the ID is the key as returned by SUMA, e.g. 1-web
+type ActivationKey struct {
+	Key              string
+	Description      string
+	BaseChannelLabel string
+	// UsageLimit is the number of registrations with the key, 0 is unlimited
+	UsageLimit       int
+	UniversalDefault bool
+	Entitlements     []string
+}
+
+// ID returns the key
+func (k *ActivationKey) ID() string {
+	return k.Key
+}
+
+// Create creates the activation key. SUMA prefixes the key with the organization, Key is set to the new key.
+func (k *ActivationKey) Create(c *SumaClient) error {
+	payload := map[string]any{
+		"key":              k.Key,
+		"description":      k.Description,
+		"baseChannelLabel": k.BaseChannelLabel,
+		"entitlements":     append([]string{}, k.Entitlements...),
+		"universalDefault": k.UniversalDefault,
+	}
+	if k.UsageLimit > 0 {
+		payload["usageLimit"] = k.UsageLimit
+	}
+
+	var key string
+	if err := c.post("activationkey/create", payload, &key); err != nil {
+		return err
+	}
+	k.Key = key
+	return nil
+}
+
+// Read refreshes the activation key from SUMA
+func (k *ActivationKey) Read(c *SumaClient) error {
+	var keys []struct {
+		Key              string   `json:"key"`
+		Description      string   `json:"description"`
+		BaseChannelLabel string   `json:"base_channel_label"`
+		UsageLimit       int      `json:"usage_limit"`
+		UniversalDefault bool     `json:"universal_default"`
+		Entitlements     []string `json:"entitlements"`
+	}
+	if err := c.get("activationkey/listActivationKeys", nil, &keys); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key.Key != k.Key {
+			continue
+		}
+		k.Description = key.Description
+		k.BaseChannelLabel = key.BaseChannelLabel
+		k.UsageLimit = key.UsageLimit
+		k.UniversalDefault = key.UniversalDefault
+		k.Entitlements = key.Entitlements
+		return nil
+	}
+	return fmt.Errorf("activation key %s: %w", k.Key, ErrNotFound)
+}
+
+// Update sets the description, base channel, usage limit and universal default of the key
+func (k *ActivationKey) Update(c *SumaClient) error {
+	details := map[string]any{
+		"description":        k.Description,
+		"base_channel_label": k.BaseChannelLabel,
+		"universal_default":  k.UniversalDefault,
+	}
+	if k.UsageLimit > 0 {
+		details["usage_limit"] = k.UsageLimit
+	} else {
+		details["unlimited_usage_limit"] = true
+	}
+	payload := map[string]any{"key": k.Key, "details": details}
+	return c.post("activationkey/setDetails", payload, nil)
+}
+
+// Delete deletes the activation key
+func (k *ActivationKey) Delete(c *SumaClient) error {
+	payload := map[string]string{"key": k.Key}
+	return c.post("activationkey/delete", payload, nil)
+}
+
+// ImportActivationKey reads an existing activation key
+func ImportActivationKey(c *SumaClient, id string) (*ActivationKey, error) {
+	k := &ActivationKey{Key: id}
+	if err := k.Read(c); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// BuildingBlock is a Meshstack building block, the ID is the UUID. It is created from Payload, the other
+// attributes are computed. Building blocks cannot be updated, they have to be replaced.
+type BuildingBlock struct {
+	UUID    string
+	Payload []byte `json:"-" output:"-"`
+
+	// computed
+	Name           string
+	DefinitionUUID string
+	Status         string
+	Inputs         map[string]string
+	Outputs        map[string]string
+}
+
+// ID returns the UUID of the building block
+func (b *BuildingBlock) ID() string {
+	return b.UUID
+}
+
+// Create creates the building block from the payload
+func (b *BuildingBlock) Create(c *MsClient) error {
+	uuid, err := c.CreateBuildingBlock(b.Payload)
+	if err != nil {
+		return err
+	}
+	b.UUID = uuid
+	return b.Read(c)
+}
+
+// Read refreshes the building block from Meshstack
+func (b *BuildingBlock) Read(c *MsClient) error {
+	details, err := c.GetBuildingBlockDetails(b.UUID)
+	if err != nil {
+		return err
+	}
+	b.Name = details.Name
+	b.DefinitionUUID = details.DefinitionUUID
+	b.Status = details.Status
+	b.Inputs = details.Inputs
+	b.Outputs = details.Outputs
+	return nil
+}
+
+// Update returns ErrUpdateNotSupported, building blocks have to be replaced
+func (b *BuildingBlock) Update(c *MsClient) error {
+	return fmt.Errorf("building block %s: %w", b.UUID, ErrUpdateNotSupported)
+}
+
+// Delete deletes the building block
+func (b *BuildingBlock) Delete(c *MsClient) error {
+	return c.DeleteBuildingBlock(b.UUID)
+}
+
+// ImportBuildingBlock reads an existing building block
+func ImportBuildingBlock(c *MsClient, id string) (*BuildingBlock, error) {
+	b := &BuildingBlock{UUID: id}
+	if err := b.Read(c); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,283 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeResourceServer keeps system groups, users and activation keys in memory
+func newFakeResourceServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	groups := map[string]map[string]any{}
+	users := map[string]map[string]any{}
+	keys := map[string]map[string]any{}
+	roles := map[string][]string{}
+	assigned := map[string][]string{}
+
+	result := func(w http.ResponseWriter, v any) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"success": true, "result": v})
+	}
+	fail := func(w http.ResponseWriter, msg string) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"success": false, "message": msg})
+	}
+	decode := func(r *http.Request) map[string]any {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		return payload
+	}
+	names := func(m map[string]map[string]any, field string) []map[string]any {
+		var list []map[string]any
+		for name := range m {
+			list = append(list, map[string]any{field: name})
+		}
+		return list
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listAllGroups", func(w http.ResponseWriter, r *http.Request) {
+		result(w, names(groups, "name"))
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/create", func(w http.ResponseWriter, r *http.Request) {
+		p := decode(r)
+		groups[p["name"].(string)] = map[string]any{"id": len(groups) + 1, "description": p["description"], "system_count": 0}
+		result(w, groups[p["name"].(string)])
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/getDetails", func(w http.ResponseWriter, r *http.Request) {
+		result(w, groups[r.URL.Query().Get("systemGroupName")])
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/update", func(w http.ResponseWriter, r *http.Request) {
+		p := decode(r)
+		groups[p["systemGroupName"].(string)]["description"] = p["description"]
+		result(w, 1)
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/delete", func(w http.ResponseWriter, r *http.Request) {
+		delete(groups, decode(r)["systemGroupName"].(string))
+		result(w, 1)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/listUsers", func(w http.ResponseWriter, r *http.Request) {
+		result(w, names(users, "login"))
+	})
+	mux.HandleFunc("/rhn/manager/api/user/create", func(w http.ResponseWriter, r *http.Request) {
+		p := decode(r)
+		if p["password"] == "" {
+			fail(w, "password required")
+			return
+		}
+		users[p["login"].(string)] = map[string]any{"first_name": p["firstName"], "last_name": p["lastName"], "email": p["email"]}
+		result(w, 1)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/getDetails", func(w http.ResponseWriter, r *http.Request) {
+		// logins are case-insensitive
+		for login, details := range users {
+			if strings.EqualFold(login, r.URL.Query().Get("login")) {
+				result(w, details)
+				return
+			}
+		}
+		result(w, nil)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/setDetails", func(w http.ResponseWriter, r *http.Request) {
+		p := decode(r)
+		users[p["login"].(string)] = p["details"].(map[string]any)
+		result(w, 1)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/delete", func(w http.ResponseWriter, r *http.Request) {
+		delete(users, decode(r)["login"].(string))
+		result(w, 1)
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystemsMinimal", func(w http.ResponseWriter, r *http.Request) {
+		result(w, []any{})
+	})
+	mux.HandleFunc("/rhn/manager/api/user/listAssignableRoles", func(w http.ResponseWriter, r *http.Request) {
+		result(w, []string{"org_admin", "system_group_admin"})
+	})
+	mux.HandleFunc("/rhn/manager/api/user/listRoles", func(w http.ResponseWriter, r *http.Request) {
+		result(w, append([]string{}, roles[r.URL.Query().Get("login")]...))
+	})
+	mux.HandleFunc("/rhn/manager/api/user/addRole", func(w http.ResponseWriter, r *http.Request) {
+		p := decode(r)
+		roles[p["login"].(string)] = append(roles[p["login"].(string)], p["role"].(string))
+		result(w, 1)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/listAssignedSystemGroups", func(w http.ResponseWriter, r *http.Request) {
+		list := []map[string]any{}
+		for _, g := range assigned[r.URL.Query().Get("login")] {
+			list = append(list, map[string]any{"name": g})
+		}
+		result(w, list)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/addAssignedSystemGroups", func(w http.ResponseWriter, r *http.Request) {
+		p := decode(r)
+		for _, g := range p["serverGroupNames"].([]any) {
+			assigned[p["login"].(string)] = append(assigned[p["login"].(string)], g.(string))
+		}
+		result(w, 1)
+	})
+	mux.HandleFunc("/rhn/manager/api/activationkey/create", func(w http.ResponseWriter, r *http.Request) {
+		p := decode(r)
+		key := "1-" + p["key"].(string)
+		keys[key] = map[string]any{"key": key, "description": p["description"], "base_channel_label": p["baseChannelLabel"],
+			"usage_limit": p["usageLimit"], "universal_default": p["universalDefault"], "entitlements": p["entitlements"]}
+		result(w, key)
+	})
+	mux.HandleFunc("/rhn/manager/api/activationkey/listActivationKeys", func(w http.ResponseWriter, r *http.Request) {
+		var list []map[string]any
+		for _, k := range keys {
+			list = append(list, k)
+		}
+		result(w, list)
+	})
+	mux.HandleFunc("/rhn/manager/api/activationkey/delete", func(w http.ResponseWriter, r *http.Request) {
+		delete(keys, decode(r)["key"].(string))
+		result(w, 1)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestSystemGroupResource(t *testing.T) {
+	server := newFakeResourceServer(t)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+	c.EnableReadCache(time.Minute)
+
+	g := &SystemGroup{Name: "web", Description: "web servers"}
+	if err := g.Create(c); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if g.ID() != "web" || g.GroupID != 1 {
+		t.Errorf("after Create() = %+v, want ID web and GroupID 1", g)
+	}
+
+	g.Description = "all web servers"
+	if err := g.Update(c); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	imported, err := ImportSystemGroup(c, "web")
+	if err != nil {
+		t.Fatalf("ImportSystemGroup() error = %v", err)
+	}
+	if *imported != *g {
+		t.Errorf("ImportSystemGroup() = %+v, want %+v", imported, g)
+	}
+
+	if err := g.Delete(c); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := g.Read(c); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Read() after Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSumaUserResource(t *testing.T) {
+	server := newFakeResourceServer(t)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	if err := (&SumaUser{Login: "nopassword"}).Create(c); err == nil {
+		t.Errorf("Create() without password error = nil, want error")
+	}
+
+	u := &SumaUser{Login: "user1", Password: "secret"}
+	if err := u.Create(c); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	u.Email = "user1@example.com"
+	if err := u.Update(c); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	imported, err := ImportSumaUser(c, "user1")
+	if err != nil {
+		t.Fatalf("ImportSumaUser() error = %v", err)
+	}
+	want := SumaUser{Login: "user1", FirstName: "user1", LastName: "user1", Email: "user1@example.com"}
+	if *imported != want {
+		t.Errorf("ImportSumaUser() = %+v, want %+v", imported, want)
+	}
+	if _, err := ImportSumaUser(c, "User1"); err != nil {
+		t.Errorf("ImportSumaUser() with other case error = %v", err)
+	}
+
+	if err := u.Delete(c); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := ImportSumaUser(c, "user1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ImportSumaUser() after Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestActivationKeyResource(t *testing.T) {
+	server := newFakeResourceServer(t)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	k := &ActivationKey{Key: "web", Description: "web servers", BaseChannelLabel: "sles15", UsageLimit: 10, Entitlements: []string{"monitoring_entitled"}}
+	if err := k.Create(c); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if k.ID() != "1-web" {
+		t.Errorf("ID() = %s, want 1-web", k.ID())
+	}
+
+	imported, err := ImportActivationKey(c, "1-web")
+	if err != nil {
+		t.Fatalf("ImportActivationKey() error = %v", err)
+	}
+	sort.Strings(imported.Entitlements)
+	if got := fmt.Sprintf("%+v", *imported); got != fmt.Sprintf("%+v", *k) {
+		t.Errorf("ImportActivationKey() = %s, want %+v", got, *k)
+	}
+
+	if err := k.Delete(c); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := k.Read(c); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Read() after Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBuildingBlockResource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/meshobjects/meshbuildingblocks/bb1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"metadata": {"uuid": "bb1", "definitionUuid": "def"}, "spec": {"displayName": "vm1"},
+			"status": {"status": "SUCCEEDED", "outputs": [{"key": "hostname", "value": "host1"}]}}`)
+	}))
+	defer server.Close()
+
+	c := &MsClient{URL: server.URL, token: "token"}
+
+	b, err := ImportBuildingBlock(c, "bb1")
+	if err != nil {
+		t.Fatalf("ImportBuildingBlock() error = %v", err)
+	}
+	if b.Name != "vm1" || b.DefinitionUUID != "def" || b.Status != "SUCCEEDED" || b.Outputs["hostname"] != "host1" {
+		t.Errorf("ImportBuildingBlock() = %+v", b)
+	}
+
+	if err := b.Update(c); !errors.Is(err, ErrUpdateNotSupported) {
+		t.Errorf("Update() error = %v, want ErrUpdateNotSupported", err)
+	}
+
+	if _, err := ImportBuildingBlock(c, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ImportBuildingBlock(missing) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,141 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// openAPIVersion is the version of the OpenAPI specification of the generated document
+const openAPIVersion = "3.0.3"
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// openAPIDocument generates the OpenAPI document of the routes. The schemas of the request and
+// response bodies are derived from their Go types with the rules of encoding/json.
+func openAPIDocument(routes []route) map[string]any {
+	paths := map[string]map[string]any{}
+	for _, r := range routes {
+		op := map[string]any{
+			"summary":     r.summary,
+			"operationId": operationID(r),
+			"tags":        []string{r.tag},
+			"security":    []map[string][]string{{"bearer": {}}},
+		}
+
+		var params []map[string]any
+		for _, m := range pathParamPattern.FindAllStringSubmatch(r.pattern, -1) {
+			params = append(params, map[string]any{"name": m[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"}})
+		}
+		for _, q := range r.query {
+			params = append(params, map[string]any{"name": q, "in": "query", "schema": map[string]any{"type": "string"}})
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		if r.request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(r.request))}},
+			}
+		}
+
+		success := map[string]any{"description": http.StatusText(r.status)}
+		if r.response != nil {
+			success["content"] = map[string]any{"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(r.response))}}
+		}
+		errResponse := map[string]any{
+			"description": "Error",
+			"content":     map[string]any{"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(errorResponse{}))}},
+		}
+		op["responses"] = map[string]any{strconv.Itoa(r.status): success, "default": errResponse}
+
+		if paths[r.pattern] == nil {
+			paths[r.pattern] = map[string]any{}
+		}
+		paths[r.pattern][strings.ToLower(r.method)] = op
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       "appapi",
+			"description": "Automation API in front of SUSE Manager and Meshstack",
+			"version":     "1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{"bearer": map[string]any{"type": "http", "scheme": "bearer"}},
+		},
+	}
+}
+
+// operationID returns the method and path as identifier, e.g. postSystems
+func operationID(r route) string {
+	name := r.method + r.pattern
+	var b strings.Builder
+	upper := false
+	for i, c := range strings.ToLower(name) {
+		switch {
+		case c == '/' || c == '{' || c == '}':
+			upper = i > 0
+		case upper:
+			b.WriteString(strings.ToUpper(string(c)))
+			upper = false
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// schemaOf returns the JSON schema of a type as encoded by encoding/json
+func schemaOf(t reflect.Type) map[string]any {
+	if t == rawMessageType {
+		return map[string]any{"type": "object"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		props := map[string]any{}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = schemaOf(f.Type)
+		}
+		return map[string]any{"type": "object", "properties": props}
+	}
+	return map[string]any{}
+}
@@ -0,0 +1,223 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/arzieg/appapi"
+)
+
+// route is an operation of the API. The request and response are zero values of their types, the
+// OpenAPI document is generated from them.
+type route struct {
+	method   string
+	pattern  string
+	summary  string
+	tag      string
+	query    []string
+	request  any
+	response any
+	status   int
+	handler  handlerFunc
+}
+
+type addSystemRequest struct {
+	Hostname string `json:"hostname"`
+	Group    string `json:"group"`
+	// Network is the permitted network, empty for the first network of the server
+	Network string `json:"network,omitempty"`
+}
+
+type ensureGroupRequest struct {
+	Hostnames []string `json:"hostnames"`
+	Network   string   `json:"network,omitempty"`
+}
+
+type syncRequest struct {
+	ProjectID       string   `json:"projectId"`
+	Group           string   `json:"group,omitempty"`
+	GroupPassword   string   `json:"groupPassword,omitempty"`
+	Network         string   `json:"network,omitempty"`
+	DefinitionUUIDs []string `json:"definitionUuids,omitempty"`
+	HostnameOutput  string   `json:"hostnameOutput,omitempty"`
+	IPOutput        string   `json:"ipOutput,omitempty"`
+	// Plan only returns the planned changes
+	Plan bool `json:"plan,omitempty"`
+}
+
+type syncResponse struct {
+	// Changes are the planned changes, only returned for a plan
+	Changes []appapi.PlannedChange `json:"changes,omitempty"`
+	Report  appapi.SyncReport      `json:"report"`
+	Applied bool                   `json:"applied"`
+}
+
+type createdBuildingBlock struct {
+	UUID string `json:"uuid"`
+}
+
+func (s *Server) apiRoutes() []route {
+	return []route{
+		{method: "POST", pattern: "/systems", summary: "Add a system to a system group", tag: "systems",
+			request: addSystemRequest{}, response: appapi.SystemResult{}, status: http.StatusOK, handler: s.addSystem},
+		{method: "DELETE", pattern: "/systems/{hostname}", summary: "Delete a system from SUSE Manager", tag: "systems",
+			query: []string{"network"}, response: appapi.SystemResult{}, status: http.StatusOK, handler: s.deleteSystem},
+		{method: "GET", pattern: "/groups", summary: "List the system groups", tag: "groups",
+			response: []string{}, status: http.StatusOK, handler: s.listGroups},
+		{method: "GET", pattern: "/groups/{group}/systems", summary: "List the members of a system group", tag: "groups",
+			response: []appapi.SystemInfo{}, status: http.StatusOK, handler: s.listGroupSystems},
+		{method: "PUT", pattern: "/groups/{group}/systems", summary: "Make the members of a system group match the hostnames", tag: "groups",
+			request: ensureGroupRequest{}, response: appapi.GroupChangeReport{}, status: http.StatusOK, handler: s.ensureGroup},
+		{method: "POST", pattern: "/sync", summary: "Sync the VMs of a Meshstack project to a SUMA system group", tag: "sync",
+			request: syncRequest{}, response: syncResponse{}, status: http.StatusOK, handler: s.sync},
+		{method: "GET", pattern: "/buildingblocks", summary: "List the building blocks of a project", tag: "buildingblocks",
+			query: []string{"project"}, response: []appapi.BuildingBlockType{}, status: http.StatusOK, handler: s.listBuildingBlocks},
+		{method: "POST", pattern: "/buildingblocks", summary: "Create a building block from its Meshstack payload", tag: "buildingblocks",
+			request: json.RawMessage{}, response: createdBuildingBlock{}, status: http.StatusCreated, handler: s.createBuildingBlock},
+		{method: "GET", pattern: "/buildingblocks/{uuid}", summary: "Get a building block with its inputs and outputs", tag: "buildingblocks",
+			response: appapi.BuildingBlockDetails{}, status: http.StatusOK, handler: s.getBuildingBlock},
+		{method: "DELETE", pattern: "/buildingblocks/{uuid}", summary: "Delete a building block", tag: "buildingblocks",
+			status: http.StatusNoContent, handler: s.deleteBuildingBlock},
+	}
+}
+
+func (s *Server) addSystem(r *http.Request) (int, any, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return 0, nil, err
+	}
+	var req addSystemRequest
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.Hostname == "" || req.Group == "" {
+		return 0, nil, badRequest("hostname and group are required")
+	}
+	result, err := suma.AddSystem(req.Hostname, req.Group, network(suma, req.Network), appapi.WithContext(r.Context()))
+	return http.StatusOK, result, err
+}
+
+func (s *Server) deleteSystem(r *http.Request) (int, any, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return 0, nil, err
+	}
+	result, err := suma.DeleteSystem(r.PathValue("hostname"), network(suma, r.URL.Query().Get("network")), appapi.WithContext(r.Context()))
+	return http.StatusOK, result, err
+}
+
+func (s *Server) listGroups(r *http.Request) (int, any, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return 0, nil, err
+	}
+	groups, err := suma.SystemGroups(appapi.WithContext(r.Context()))
+	return http.StatusOK, groups, err
+}
+
+func (s *Server) listGroupSystems(r *http.Request) (int, any, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return 0, nil, err
+	}
+	systems, err := suma.GroupSystems(r.PathValue("group"), appapi.WithContext(r.Context()))
+	return http.StatusOK, systems, err
+}
+
+func (s *Server) ensureGroup(r *http.Request) (int, any, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return 0, nil, err
+	}
+	var req ensureGroupRequest
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	report, err := suma.EnsureGroupMembers(r.PathValue("group"), req.Hostnames, network(suma, req.Network), appapi.WithContext(r.Context()))
+	return http.StatusOK, report, err
+}
+
+func (s *Server) sync(r *http.Request) (int, any, error) {
+	suma, err := s.suma()
+	if err != nil {
+		return 0, nil, err
+	}
+	ms, err := s.ms()
+	if err != nil {
+		return 0, nil, err
+	}
+	var req syncRequest
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.ProjectID == "" {
+		return 0, nil, badRequest("projectId is required")
+	}
+
+	opts := appapi.SyncOptions{
+		ProjectID:       req.ProjectID,
+		Group:           req.Group,
+		GroupPassword:   req.GroupPassword,
+		Network:         req.Network,
+		DefinitionUUIDs: req.DefinitionUUIDs,
+		HostnameOutput:  req.HostnameOutput,
+		IPOutput:        req.IPOutput,
+		Notifier:        s.Notifier,
+		Context:         r.Context(),
+	}
+	if req.Plan {
+		plan, report, err := appapi.PlanSync(suma, ms, opts)
+		if err != nil {
+			return 0, nil, err
+		}
+		return http.StatusOK, syncResponse{Changes: plan.Changes, Report: report}, nil
+	}
+	report, err := appapi.Sync(suma, ms, opts)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, syncResponse{Report: report, Applied: true}, nil
+}
+
+func (s *Server) listBuildingBlocks(r *http.Request) (int, any, error) {
+	ms, err := s.ms()
+	if err != nil {
+		return 0, nil, err
+	}
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		return 0, nil, badRequest("project is required")
+	}
+	bbs, err := ms.ListBuildingBlocks(project, appapi.WithContext(r.Context()))
+	return http.StatusOK, bbs, err
+}
+
+func (s *Server) createBuildingBlock(r *http.Request) (int, any, error) {
+	ms, err := s.ms()
+	if err != nil {
+		return 0, nil, err
+	}
+	var payload json.RawMessage
+	if err := decode(r, &payload); err != nil {
+		return 0, nil, err
+	}
+	uuid, err := ms.CreateBuildingBlock(payload, appapi.WithContext(r.Context()))
+	return http.StatusCreated, createdBuildingBlock{UUID: uuid}, err
+}
+
+func (s *Server) getBuildingBlock(r *http.Request) (int, any, error) {
+	ms, err := s.ms()
+	if err != nil {
+		return 0, nil, err
+	}
+	details, err := ms.GetBuildingBlockDetails(r.PathValue("uuid"), appapi.WithContext(r.Context()))
+	return http.StatusOK, details, err
+}
+
+func (s *Server) deleteBuildingBlock(r *http.Request) (int, any, error) {
+	ms, err := s.ms()
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, ms.DeleteBuildingBlock(r.PathValue("uuid"), appapi.WithContext(r.Context()))
+}
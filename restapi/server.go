@@ -0,0 +1,161 @@
+// Package restapi serves high-level operations of appapi as a JSON HTTP API in front of SUSE
+// Manager and Meshstack. The OpenAPI document of the API is generated from the routes and served at
+// /openapi.json.
+package restapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/arzieg/appapi"
+)
+
+// maxBodySize limits the size of request bodies
+const maxBodySize = 1 << 20
+
+// Server is an http.Handler serving the API with a SUMA and a Meshstack client. The routes of a
+// backend without client return 503.
+type Server struct {
+	Suma *appapi.SumaClient
+	Ms   *appapi.MsClient
+	// Notifier is sent the summary of the syncs, if set
+	Notifier appapi.Notifier
+
+	token  string
+	routes []route
+	mux    *http.ServeMux
+}
+
+// NewServer returns the API server. If token is set, requests must send it as "Authorization: Bearer
+// <token>", except for the OpenAPI document.
+func NewServer(suma *appapi.SumaClient, ms *appapi.MsClient, token string) *Server {
+	s := &Server{Suma: suma, Ms: ms, token: token, mux: http.NewServeMux()}
+	s.routes = s.apiRoutes()
+	for _, r := range s.routes {
+		s.mux.Handle(r.method+" "+r.pattern, s.authenticated(r.handler))
+	}
+	s.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, openAPIDocument(s.routes))
+	})
+	return s
+}
+
+// ServeHTTP serves the API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authenticated(h handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+				writeError(w, &httpError{status: http.StatusUnauthorized, msg: "missing or invalid token"})
+				return
+			}
+		}
+		status, result, err := h(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if result == nil {
+			w.WriteHeader(status)
+			return
+		}
+		writeJSON(w, status, result)
+	})
+}
+
+// handlerFunc handles a request and returns the status and the result written as JSON, or an error
+type handlerFunc func(r *http.Request) (status int, result any, err error)
+
+// httpError is an error with the status of the response
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string { return e.msg }
+
+func badRequest(format string, args ...any) error {
+	return &httpError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+// errorResponse is the body of an error response
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// statusOf maps the errors of appapi to HTTP status codes
+func statusOf(err error) int {
+	var herr *httpError
+	var validation *appapi.ValidationError
+	switch {
+	case errors.As(err, &herr):
+		return herr.status
+	case errors.Is(err, appapi.ErrNotFound):
+		return http.StatusNotFound
+	case errors.As(err, &validation):
+		return http.StatusBadRequest
+	case errors.Is(err, appapi.ErrSystemLocked):
+		return http.StatusConflict
+	case errors.Is(err, appapi.ErrUnsupportedByServer):
+		return http.StatusNotImplemented
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// writeError sends the error with the passwords, session cookies and tokens redacted, see
+// appapi.Redact
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusOf(err), errorResponse{Error: appapi.Redact(err.Error())})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// decode reads the JSON body of a request, unknown fields are rejected
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func (s *Server) suma() (*appapi.SumaClient, error) {
+	if s.Suma == nil {
+		return nil, &httpError{status: http.StatusServiceUnavailable, msg: "SUMA is not configured"}
+	}
+	return s.Suma, nil
+}
+
+func (s *Server) ms() (*appapi.MsClient, error) {
+	if s.Ms == nil {
+		return nil, &httpError{status: http.StatusServiceUnavailable, msg: "Meshstack is not configured"}
+	}
+	return s.Ms, nil
+}
+
+// network returns the network or the first network of the SUMA client
+func network(suma *appapi.SumaClient, network string) string {
+	if network == "" && len(suma.Networks) > 0 {
+		return suma.Networks[0]
+	}
+	return network
+}
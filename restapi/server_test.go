@@ -0,0 +1,160 @@
+package restapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arzieg/appapi"
+	"github.com/arzieg/appapi/sumatest"
+)
+
+func msClient(t *testing.T) *appapi.MsClient {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+	})
+	mux.HandleFunc("GET /api/meshobjects/meshbuildingblocks/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("uuid") != "bb-1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{
+			"metadata": {"uuid": "bb-1", "definitionUuid": "def-1"},
+			"spec": {"displayName": "vm-1", "inputs": []},
+			"status": {"status": "SUCCEEDED", "outputs": [{"key": "hostname", "value": "vm1.example.com"}]}
+		}`))
+	})
+	msserver := httptest.NewServer(mux)
+	t.Cleanup(msserver.Close)
+
+	ms, err := appapi.NewMsClient(msserver.URL, appapi.NewStaticCredentialProvider(appapi.Credentials{MsClientID: "id", MsClientSecret: "secret"}), appapi.WithInsecure(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ms
+}
+
+func request(t *testing.T, h http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer(nil, msClient(t), "secret")
+
+	if rec := request(t, s, "GET", "/buildingblocks/bb-1", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", rec.Code)
+	}
+
+	rec := request(t, s, "GET", "/buildingblocks/bb-1", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var details appapi.BuildingBlockDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &details); err != nil {
+		t.Fatal(err)
+	}
+	if details.Name != "vm-1" || details.Outputs["hostname"] != "vm1.example.com" {
+		t.Errorf("details = %+v", details)
+	}
+
+	if rec := request(t, s, "GET", "/buildingblocks/missing", "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("status of a missing building block = %d, want 404", rec.Code)
+	}
+	if rec := request(t, s, "GET", "/buildingblocks", "secret"); rec.Code != http.StatusBadRequest {
+		t.Errorf("status without project = %d, want 400", rec.Code)
+	}
+	if rec := request(t, s, "GET", "/groups", "secret"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status without SUMA = %d, want 503", rec.Code)
+	}
+}
+
+func TestSumaErrors(t *testing.T) {
+	srv := sumatest.NewServer(sumatest.Dataset{
+		Groups:  []appapi.SystemGroup{{Name: "project-a"}},
+		Systems: []sumatest.System{{ID: 1000010001, Name: "vm1.example.com", IP: "192.168.1.10"}},
+	})
+	defer srv.Close()
+	suma, err := srv.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	suma.Networks = []string{"192.168.1.0/24"}
+	s := NewServer(suma, nil, "")
+
+	if rec := request(t, s, "DELETE", "/systems/unknown.example.com", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("status of an unknown hostname = %d, want 404: %s", rec.Code, rec.Body)
+	}
+
+	srv.Fail("systemgroup/listSystemsMinimal", "database unavailable")
+	srv.Fail("systemgroup/listSystems", "database unavailable")
+	if rec := request(t, s, "GET", "/groups/project-a/systems", ""); rec.Code != http.StatusBadGateway {
+		t.Errorf("status of a SUMA fault = %d, want 502: %s", rec.Code, rec.Body)
+	}
+
+	// the calls with the expired session get 401 Unauthorized
+	srv.ExpireSessions()
+	if rec := request(t, s, "DELETE", "/systems/vm1.example.com", ""); rec.Code != http.StatusBadGateway {
+		t.Errorf("status with an expired session = %d, want 502: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	s := NewServer(nil, nil, "secret")
+
+	// the document is served without token
+	rec := request(t, s, "GET", "/openapi.json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var doc struct {
+		OpenAPI string                               `json:"openapi"`
+		Paths   map[string]map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openAPIVersion {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+	for _, r := range s.routes {
+		op, ok := doc.Paths[r.pattern][strings.ToLower(r.method)]
+		if !ok {
+			t.Errorf("%s %s is not documented", r.method, r.pattern)
+			continue
+		}
+		if op["summary"] != r.summary {
+			t.Errorf("summary of %s %s = %v", r.method, r.pattern, op["summary"])
+		}
+	}
+
+	post := doc.Paths["/systems"]["post"]
+	body, _ := json.Marshal(post["requestBody"])
+	for _, field := range []string{`"hostname"`, `"group"`, `"network"`} {
+		if !strings.Contains(string(body), field) {
+			t.Errorf("request body of POST /systems has no %s: %s", field, body)
+		}
+	}
+	if id := operationID(route{method: "GET", pattern: "/groups/{group}/systems"}); id != "getGroupsGroupSystems" {
+		t.Errorf("operationID = %q", id)
+	}
+}
+
+func TestWriteErrorRedacts(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeError(rec, errors.New(`login failed: {"password": "s3cr3t"}`))
+	if rec.Code != http.StatusBadGateway || strings.Contains(rec.Body.String(), "s3cr3t") {
+		t.Errorf("writeError() = %d %s, want 502 without the password", rec.Code, rec.Body)
+	}
+}
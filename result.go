@@ -0,0 +1,41 @@
+package appapi
+
+// ResultAction is what a call changed in SUSE Manager
+type ResultAction string
+
+// The actions of a result
+const (
+	ResultAdded     ResultAction = "added"
+	ResultCreated   ResultAction = "created"
+	ResultDeleted   ResultAction = "deleted"
+	ResultUnchanged ResultAction = "unchanged"
+	ResultUpdated   ResultAction = "updated"
+)
+
+// SystemResult is the result of adding a system to a system group or deleting a system, see
+// SumaAddSystemResult and SumaDeleteSystemResult. On an error the fields which are already known,
+// e.g. the system ID, are set.
+type SystemResult struct {
+	SystemID   int          `json:"systemId" output:"ID"`
+	Hostname   string       `json:"hostname"`
+	IP         string       `json:"ip"`
+	Group      string       `json:"group,omitempty"`
+	Action     ResultAction `json:"action"`
+	StatusCode int          `json:"status" output:"Status"`
+}
+
+// UserResult is the result of adding a user, see SumaAddUserResult
+type UserResult struct {
+	Login      string       `json:"login"`
+	Action     ResultAction `json:"action"`
+	StatusCode int          `json:"status" output:"Status"`
+}
+
+// statusCode returns the HTTP status of a system result or -1 on an error, as returned by
+// SumaAddSystem and SumaDeleteSystem
+func statusCode(result SystemResult, err error) (int, error) {
+	if err != nil {
+		return -1, err
+	}
+	return result.StatusCode, nil
+}
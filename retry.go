@@ -0,0 +1,106 @@
+package appapi
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides how throttled API calls are retried. SUMA and Meshstack answer with 429 Too
+// Many Requests or 503 Service Unavailable when they are overloaded, the call is then repeated after
+// the time of the Retry-After header or, without the header, after an exponential backoff.
+type RetryPolicy struct {
+	// Retries is the maximum number of retries of a call, 0 disables retries
+	Retries int
+	// Backoff is the wait before the first retry without Retry-After, it doubles with every retry
+	Backoff time.Duration
+	// MaxWait is the longest wait accepted, a longer Retry-After returns the response to the caller
+	MaxWait time.Duration
+}
+
+// Retry is the retry policy of all API calls, the number of retries is set by APPAPI_RETRIES
+var Retry = RetryPolicy{Retries: Envs.Retries, Backoff: time.Second, MaxWait: 2 * time.Minute}
+
+// retryable reports if the response asks to repeat the call later
+func retryable(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// wait returns how long to wait before the retry, ok is false if the call should not be retried. A
+// Retry-After date is relative to now.
+func (p RetryPolicy) wait(resp *http.Response, attempt int, now time.Time) (d time.Duration, ok bool) {
+	if attempt >= p.Retries {
+		return 0, false
+	}
+
+	d = p.Backoff << attempt
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			d = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			d = date.Sub(now)
+		}
+	}
+	if d < 0 {
+		d = 0
+	}
+	if p.MaxWait > 0 && d > p.MaxWait {
+		return 0, false
+	}
+	return d, true
+}
+
+// roundTripWithRetry sends the request and repeats it while it is throttled, see RetryPolicy.
+// Requests with a body which cannot be replayed are not retried.
+func (t *apiTransport) roundTripWithRetry(req *http.Request) (*http.Response, error) {
+	policy := Retry
+	if t.retry != nil {
+		policy = *t.retry
+	}
+	clock := t.clock
+	if clock == nil {
+		clock = DefaultClock
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || !retryable(resp) {
+			return resp, err
+		}
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		d, ok := policy.wait(resp, attempt, clock.Now())
+		if !ok {
+			return resp, nil
+		}
+		Logger().Warn("API call throttled, retrying",
+			"method", req.Method,
+			"host", req.URL.Host,
+			"endpoint", req.URL.Path,
+			"request_id", req.Header.Get(requestIDHeader),
+			"status", resp.StatusCode,
+			"retry", attempt+1,
+			"retries", policy.Retries,
+			"wait", d)
+
+		// the connection is only reused if the body was read
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		if err := sleep(req.Context(), clock, d); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
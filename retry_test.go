@@ -0,0 +1,182 @@
+package appapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// nextWait returns the duration of the earliest pending timer of the clock
+func nextWait(clock *FakeClock) time.Duration {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	return clock.waiters[0].when.Sub(clock.now)
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := RetryPolicy{Retries: 3, Backoff: time.Second, MaxWait: time.Minute}
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		wantWait   time.Duration
+		wantOK     bool
+	}{
+		{name: "retry after seconds", retryAfter: "2", wantWait: 2 * time.Second, wantOK: true},
+		{name: "retry after date", retryAfter: now.Add(30 * time.Second).Format(http.TimeFormat), wantWait: 30 * time.Second, wantOK: true},
+		{name: "retry after date in the past", retryAfter: "Mon, 01 Jan 2024 12:00:00 GMT", wantWait: 0, wantOK: true},
+		{name: "retry after too long", retryAfter: "3600"},
+		{name: "backoff doubles", attempt: 2, wantWait: 4 * time.Second, wantOK: true},
+		{name: "retries exhausted", attempt: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			d, ok := policy.wait(resp, tt.attempt, now)
+			if d != tt.wantWait || ok != tt.wantOK {
+				t.Errorf("wait() = %v, %v, want %v, %v", d, ok, tt.wantWait, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		responses  []int
+		retryAfter string
+		policy     RetryPolicy
+		wantStatus int
+		wantWaits  []time.Duration
+	}{
+		{
+			name:       "retry after seconds",
+			responses:  []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK},
+			retryAfter: "2",
+			policy:     RetryPolicy{Retries: 3, Backoff: time.Second, MaxWait: time.Minute},
+			wantStatus: http.StatusOK,
+			wantWaits:  []time.Duration{2 * time.Second, 2 * time.Second},
+		},
+		{
+			name:       "exponential backoff without header",
+			responses:  []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			policy:     RetryPolicy{Retries: 3, Backoff: time.Second, MaxWait: time.Minute},
+			wantStatus: http.StatusOK,
+			wantWaits:  []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:       "retries exhausted",
+			responses:  []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests},
+			retryAfter: "1",
+			policy:     RetryPolicy{Retries: 2, Backoff: time.Second, MaxWait: time.Minute},
+			wantStatus: http.StatusTooManyRequests,
+			wantWaits:  []time.Duration{time.Second, time.Second},
+		},
+		{
+			name:       "retry after too long",
+			responses:  []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "3600",
+			policy:     RetryPolicy{Retries: 3, Backoff: time.Second, MaxWait: time.Minute},
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "retries disabled",
+			responses:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			policy:     RetryPolicy{},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "other errors are not retried",
+			responses:  []int{http.StatusInternalServerError, http.StatusOK},
+			policy:     RetryPolicy{Retries: 3, Backoff: time.Second},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := calls.Add(1) - 1
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != `{"login":"admin"}` {
+					t.Errorf("call %d got body %q", call, body)
+				}
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.responses[call])
+			}))
+			defer server.Close()
+
+			clock := NewFakeClock(time.Now())
+			done := make(chan *http.Response, 1)
+			go func() {
+				req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"login":"admin"}`))
+				resp, err := newCallOptions(false, []Option{WithRetry(tt.policy), WithClock(clock)}).do(req)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				done <- resp
+			}()
+
+			// every retry waits on the clock, which is advanced by the expected wait
+			for i, want := range tt.wantWaits {
+				clock.BlockUntil(1)
+				if got := nextWait(clock); got != want {
+					t.Errorf("wait %d = %v, want %v", i, got, want)
+				}
+				clock.Advance(want)
+			}
+
+			select {
+			case resp := <-done:
+				if resp == nil {
+					return
+				}
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("the call still waits for a retry, %d pending", clock.Waiters())
+			}
+			if n := int(calls.Load()); n != len(tt.wantWaits)+1 {
+				t.Errorf("got %d calls, want %d", n, len(tt.wantWaits)+1)
+			}
+		})
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clock := NewFakeClock(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		_, err := newCallOptions(false, []Option{WithRetry(RetryPolicy{Retries: 3, Backoff: time.Minute, MaxWait: time.Hour}), WithClock(clock)}).do(req)
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,115 @@
+package appapi
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Role is an administrative role of a SUSE Manager user
+type Role string
+
+// The roles of SUSE Manager users
+const (
+	RoleSatelliteAdmin     Role = "satellite_admin"
+	RoleOrgAdmin           Role = "org_admin"
+	RoleChannelAdmin       Role = "channel_admin"
+	RoleConfigAdmin        Role = "config_admin"
+	RoleSystemGroupAdmin   Role = "system_group_admin"
+	RoleActivationKeyAdmin Role = "activation_key_admin"
+	RoleImageAdmin         Role = "image_admin"
+)
+
+// SumaListAssignableRoles returns the roles the user of the session can assign
+func SumaListAssignableRoles(sessioncookie, susemgr string, verbose bool, opts ...Option) (roles []Role, err error) {
+	err = sumaGet(sessioncookie, susemgr, "user/listAssignableRoles", nil, &roles, verbose, opts...)
+	return roles, err
+}
+
+// SumaListRoles returns the roles of a user
+func SumaListRoles(sessioncookie, susemgr, login string, verbose bool, opts ...Option) (roles []Role, err error) {
+	query := url.Values{"login": {login}}
+	err = sumaGet(sessioncookie, susemgr, "user/listRoles", query, &roles, verbose, opts...)
+	return roles, err
+}
+
+// SumaAddRole adds a role to a user. The role is checked against the assignable roles first, SUMA
+// does not reject every unknown role.
+func SumaAddRole(sessioncookie, susemgr, login string, role Role, verbose bool, opts ...Option) (err error) {
+	return sumaChangeRole(sessioncookie, susemgr, "user/addRole", login, role, verbose, opts...)
+}
+
+// SumaRemoveRole removes a role from a user, the role is checked like by SumaAddRole
+func SumaRemoveRole(sessioncookie, susemgr, login string, role Role, verbose bool, opts ...Option) (err error) {
+	return sumaChangeRole(sessioncookie, susemgr, "user/removeRole", login, role, verbose, opts...)
+}
+
+func sumaChangeRole(sessioncookie, susemgr, method, login string, role Role, verbose bool, opts ...Option) error {
+	assignable, err := SumaListAssignableRoles(sessioncookie, susemgr, verbose, opts...)
+	if err != nil {
+		return err
+	}
+	if err := validateRoles(assignable, role); err != nil {
+		return err
+	}
+	payload := map[string]any{"login": login, "role": role}
+	return sumaPost(sessioncookie, susemgr, method, payload, nil, verbose, opts...)
+}
+
+// validateRoles returns a ValidationError for the first role which is not assignable
+func validateRoles(assignable []Role, roles ...Role) error {
+	for _, role := range roles {
+		if slices.Contains(assignable, role) {
+			continue
+		}
+		names := make([]string, len(assignable))
+		for i, r := range assignable {
+			names[i] = string(r)
+		}
+		return &ValidationError{Payload: "role", Field: "role", Reason: fmt.Sprintf("%q is not assignable, the server assigns %s", role, strings.Join(names, ", "))}
+	}
+	return nil
+}
+
+// AssignableRoles returns the roles the user of the client can assign, see SumaListAssignableRoles
+func (c *SumaClient) AssignableRoles(opts ...Option) ([]Role, error) {
+	verbose, opts := c.options(opts)
+	return cachedRead(c.cache, cacheSumaRoles, func() ([]Role, error) {
+		return SumaListAssignableRoles(c.SessionCookie(), c.URL, verbose, opts...)
+	})
+}
+
+// ValidateRoles returns a ValidationError if a role cannot be assigned on the server, e.g. for a role
+// from the configuration with a typo
+func (c *SumaClient) ValidateRoles(roles ...Role) error {
+	assignable, err := c.AssignableRoles()
+	if err != nil {
+		return err
+	}
+	return validateRoles(assignable, roles...)
+}
+
+// Roles returns the roles of a user, see SumaListRoles
+func (c *SumaClient) Roles(login string, opts ...Option) ([]Role, error) {
+	verbose, opts := c.options(opts)
+	return SumaListRoles(c.SessionCookie(), c.URL, login, verbose, opts...)
+}
+
+// AddRole adds a role to a user, see SumaAddRole
+func (c *SumaClient) AddRole(login string, role Role, opts ...Option) error {
+	if err := c.ValidateRoles(role); err != nil {
+		return err
+	}
+	verbose, opts := c.options(opts)
+	return sumaPost(c.SessionCookie(), c.URL, "user/addRole", map[string]any{"login": login, "role": role}, nil, verbose, opts...)
+}
+
+// RemoveRole removes a role from a user, see SumaRemoveRole
+func (c *SumaClient) RemoveRole(login string, role Role, opts ...Option) error {
+	if err := c.ValidateRoles(role); err != nil {
+		return err
+	}
+	verbose, opts := c.options(opts)
+	return sumaPost(c.SessionCookie(), c.URL, "user/removeRole", map[string]any{"login": login, "role": role}, nil, verbose, opts...)
+}
@@ -0,0 +1,56 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSumaRoles(t *testing.T) {
+	listed := 0
+	var added []map[string]string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/user/listAssignableRoles", func(w http.ResponseWriter, r *http.Request) {
+		listed++
+		fmt.Fprint(w, `{"success": true, "result": ["org_admin", "system_group_admin", "channel_admin"]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/user/addRole", func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		added = append(added, payload)
+		fmt.Fprint(w, `{"success": true, "result": 1}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	if err := SumaAddRole("cookie", server.URL, "clab01", RoleSystemGroupAdmin, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var verr *ValidationError
+	if err := SumaAddRole("cookie", server.URL, "clab01", "system_grp_admin", false); !errors.As(err, &verr) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+	if len(added) != 1 || added[0]["login"] != "clab01" || added[0]["role"] != "system_group_admin" {
+		t.Errorf("unexpected roles added %v", added)
+	}
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+	c.EnableReadCache(time.Minute)
+	listed = 0
+	if err := c.ValidateRoles(RoleOrgAdmin, RoleChannelAdmin); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := c.ValidateRoles(RoleSatelliteAdmin); !errors.As(err, &verr) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+	if listed != 1 {
+		t.Errorf("expected the assignable roles to be listed once, got %d", listed)
+	}
+}
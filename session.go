@@ -0,0 +1,118 @@
+package appapi
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SessionManager hands out SUMA sessions to parallel goroutines. Concurrent logins are merged
+// into one (singleflight), the session cookie is reused until shortly before its Max-Age expires.
+// Without a manager every worker logs in separately and SUMA collects dozens of sessions per run.
+type SessionManager struct {
+	susemgr  string
+	provider CredentialProvider
+	opts     []Option
+	clock    Clock
+
+	mu       sync.Mutex
+	cookie   string
+	expires  time.Time
+	inflight *loginCall
+}
+
+// loginCall is a login in progress, the goroutines waiting for it share its result
+type loginCall struct {
+	done   chan struct{}
+	cookie string
+	err    error
+}
+
+// NewSessionManager creates a session manager logging in to the SUSE Manager with the credentials
+// of the provider. The first login is done by the first call of Session.
+func NewSessionManager(susemgr string, provider CredentialProvider, opts ...Option) *SessionManager {
+	return &SessionManager{susemgr: susemgr, provider: provider, opts: opts, clock: newCallOptions(false, opts).clock}
+}
+
+// Session returns a valid session cookie. If there is none or it expires within a minute, one
+// login is made for all callers waiting at the same time.
+func (m *SessionManager) Session() (string, error) {
+	m.mu.Lock()
+	if m.cookie != "" && m.clock.Now().Before(m.expires) {
+		cookie := m.cookie
+		m.mu.Unlock()
+		return cookie, nil
+	}
+
+	if call := m.inflight; call != nil {
+		m.mu.Unlock()
+		if m.verbose() {
+			logDebugf("SUMAAPI SessionManager: wait for login in progress")
+		}
+		<-call.done
+		return call.cookie, call.err
+	}
+
+	call := &loginCall{done: make(chan struct{})}
+	m.inflight = call
+	m.mu.Unlock()
+
+	m.login(call)
+	return call.cookie, call.err
+}
+
+// Invalidate drops the session, e.g. after SUMA rejected it, so the next Session logs in again.
+// A newer session than cookie is kept, it was already renewed by another goroutine.
+func (m *SessionManager) Invalidate(cookie string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cookie == "" || cookie == m.cookie {
+		m.cookie = ""
+		m.expires = time.Time{}
+	}
+}
+
+// Logout ends the current session on the server, see SumaLogout. The next Session logs in again.
+func (m *SessionManager) Logout() error {
+	m.mu.Lock()
+	cookie := m.cookie
+	m.cookie = ""
+	m.expires = time.Time{}
+	m.mu.Unlock()
+
+	if cookie == "" {
+		return nil
+	}
+	return SumaLogout(cookie, m.susemgr, m.verbose(), m.opts...)
+}
+
+func (m *SessionManager) login(call *loginCall) {
+	verbose := m.verbose()
+	cookie, maxAge, err := sumaLoginWithProvider(m.provider, m.susemgr, verbose, m.opts...)
+	if err != nil {
+		call.err = fmt.Errorf("login to %s failed: %v", m.susemgr, err)
+	}
+	call.cookie = cookie
+
+	lifetime := time.Duration(maxAge) * time.Second
+	if lifetime <= 0 {
+		lifetime = sumaSessionLifetime
+	}
+
+	m.mu.Lock()
+	if err == nil {
+		m.cookie = cookie
+		m.expires = m.clock.Now().Add(lifetime - sessionExpiryMargin)
+		if verbose {
+			logDebugf("SUMAAPI SessionManager: new session valid until %s", m.expires.Format(time.RFC3339))
+		}
+	}
+	m.inflight = nil
+	m.mu.Unlock()
+
+	close(call.done)
+}
+
+func (m *SessionManager) verbose() bool {
+	return newCallOptions(false, m.opts).verbose
+}
@@ -0,0 +1,173 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFakeLoginServer(t *testing.T, logins *atomic.Int32, maxAge int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rhn/manager/api/api/systemVersion" {
+			fmt.Fprint(w, `{"success":true,"result":"4.3.12"}`)
+			return
+		}
+		if r.URL.Path != "/rhn/manager/api/auth/login" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		n := logins.Add(1)
+		// keep the login open, so the concurrent callers have to wait for it
+		time.Sleep(20 * time.Millisecond)
+		http.SetCookie(w, &http.Cookie{Name: "pxt-session-cookie", Value: fmt.Sprintf("cookie-%d", n), MaxAge: maxAge})
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestSessionManagerSingleflight(t *testing.T) {
+	var logins atomic.Int32
+	server := newFakeLoginServer(t, &logins, 3600)
+	defer server.Close()
+
+	m := NewSessionManager(server.URL, NewStaticCredentialProvider(Credentials{SumaUsername: "admin", SumaPassword: "secret"}))
+
+	var wg sync.WaitGroup
+	cookies := make([]string, 20)
+	errs := make([]error, 20)
+	for i := range cookies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cookies[i], errs[i] = m.Session()
+		}()
+	}
+	wg.Wait()
+
+	for i := range cookies {
+		if errs[i] != nil || cookies[i] != "cookie-1" {
+			t.Errorf("worker %d got %q, %v, want cookie-1", i, cookies[i], errs[i])
+		}
+	}
+	if n := logins.Load(); n != 1 {
+		t.Errorf("expected 1 login, got %d", n)
+	}
+}
+
+func TestSessionManagerExpiry(t *testing.T) {
+	var logins atomic.Int32
+	server := newFakeLoginServer(t, &logins, 600)
+	defer server.Close()
+
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	m := NewSessionManager(server.URL, NewStaticCredentialProvider(Credentials{SumaUsername: "admin", SumaPassword: "secret"}))
+	m.clock = clock
+
+	session := func(want string) {
+		t.Helper()
+		cookie, err := m.Session()
+		if err != nil || cookie != want {
+			t.Fatalf("Session() = %q, %v, want %q", cookie, err, want)
+		}
+	}
+
+	session("cookie-1")
+	clock.Advance(8 * time.Minute)
+	session("cookie-1")
+
+	// renewed a minute before the Max-Age of 10 minutes
+	clock.Advance(time.Minute)
+	session("cookie-2")
+
+	// an older session does not drop the current one
+	m.Invalidate("cookie-1")
+	session("cookie-2")
+
+	m.Invalidate("cookie-2")
+	session("cookie-3")
+}
+
+func TestSessionManagerLoginFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	m := NewSessionManager(server.URL, NewStaticCredentialProvider(Credentials{SumaUsername: "admin", SumaPassword: "wrong"}))
+	if _, err := m.Session(); err == nil {
+		t.Error("expected error for failed login, got nil")
+	}
+}
+
+func TestSumaClientSharedSessions(t *testing.T) {
+	var logins atomic.Int32
+	server := newFakeLoginServer(t, &logins, 3600)
+	defer server.Close()
+
+	m := NewSessionManager(server.URL, NewStaticCredentialProvider(Credentials{SumaUsername: "admin", SumaPassword: "secret"}))
+	c1, err := NewSumaClientWithSessions(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c2, err := NewSumaClientWithSessions(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c1.SessionCookie() != "cookie-1" || c2.SessionCookie() != "cookie-1" || logins.Load() != 1 {
+		t.Errorf("expected both clients to share one session, got %q and %q after %d logins", c1.SessionCookie(), c2.SessionCookie(), logins.Load())
+	}
+
+	if err := c1.Login(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c2.SessionCookie() != "cookie-2" {
+		t.Errorf("expected the new session to be shared, got %q", c2.SessionCookie())
+	}
+}
+
+func TestSumaClientClose(t *testing.T) {
+	var logins atomic.Int32
+	var logouts []string
+	mux := http.NewServeMux()
+	login := newFakeLoginServer(t, &logins, 3600)
+	defer login.Close()
+	mux.Handle("/rhn/manager/api/auth/login", login.Config.Handler)
+	mux.Handle("/rhn/manager/api/api/systemVersion", login.Config.Handler)
+	mux.HandleFunc("/rhn/manager/api/auth/logout", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("pxt-session-cookie")
+		if err != nil {
+			t.Errorf("logout without session cookie: %v", err)
+			return
+		}
+		logouts = append(logouts, cookie.Value)
+		fmt.Fprint(w, `{"success": true, "result": 1}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	creds := NewStaticCredentialProvider(Credentials{SumaUsername: "admin", SumaPassword: "secret"})
+
+	shared, err := NewSumaClientWithSessions(NewSessionManager(server.URL, creds))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shared.Close(); err != nil || len(logouts) != 0 {
+		t.Errorf("expected the shared session to be kept, got %v, logouts %v", err, logouts)
+	}
+	if err := shared.Sessions().Logout(); err != nil || len(logouts) != 1 || logouts[0] != "cookie-1" {
+		t.Errorf("expected the session manager to log out cookie-1, got %v, logouts %v", err, logouts)
+	}
+
+	c, err := NewSumaClient(server.URL, creds, WithInsecure(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Close(); err != nil || len(logouts) != 2 || logouts[1] != "cookie-2" {
+		t.Errorf("expected the client to log out cookie-2, got %v, logouts %v", err, logouts)
+	}
+	if err := c.Close(); err != nil || len(logouts) != 2 {
+		t.Errorf("expected a second Close to do nothing, got %v, logouts %v", err, logouts)
+	}
+}
@@ -0,0 +1,260 @@
+package appapi
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	sessionCacheSaltSize   = 16
+	sessionCacheIterations = 100000
+	sumaSessionLifetime    = 3600 * time.Second
+	// sessions are dropped from the cache shortly before they expire on the server
+	sessionExpiryMargin = time.Minute
+)
+
+// SessionCache stores SUMA session cookies and Meshstack tokens encrypted on disk, so short-lived
+// commands can reuse a session instead of logging in again. The file is encrypted with AES-GCM
+// with a key derived from the user supplied passphrase.
+type SessionCache struct {
+	path       string
+	passphrase string
+	mu         sync.Mutex
+}
+
+type sessionCacheEntry struct {
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// DefaultSessionCachePath returns the default location of the session cache in the user cache directory
+func DefaultSessionCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "appapi", "sessions"), nil
+}
+
+// NewSessionCache creates a session cache stored in path, encrypted with the passphrase
+func NewSessionCache(path, passphrase string) (*SessionCache, error) {
+	if passphrase == "" {
+		return nil, errors.New("session cache needs a passphrase")
+	}
+	return &SessionCache{path: path, passphrase: passphrase}, nil
+}
+
+// Get returns a cached session. ok is false if no valid session is cached.
+func (c *SessionCache) Get(name string) (value string, ok bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		return "", false, err
+	}
+
+	entry, ok := entries[name]
+	if !ok || !DefaultClock.Now().Before(entry.Expires) {
+		return "", false, nil
+	}
+	return entry.Value, true, nil
+}
+
+// Put stores a session until it expires
+func (c *SessionCache) Put(name, value string, expires time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+
+	entries[name] = sessionCacheEntry{Value: value, Expires: expires}
+	return c.save(entries)
+}
+
+// Delete removes a session from the cache
+func (c *SessionCache) Delete(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+
+	delete(entries, name)
+	return c.save(entries)
+}
+
+// load decrypts the cache file, a missing file is an empty cache
+func (c *SessionCache) load() (map[string]sessionCacheEntry, error) {
+	entries := map[string]sessionCacheEntry{}
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read session cache: %v", err)
+	}
+
+	if len(data) < sessionCacheSaltSize {
+		return nil, errors.New("session cache is corrupt")
+	}
+	salt, data := data[:sessionCacheSaltSize], data[sessionCacheSaltSize:]
+
+	gcm, err := c.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("session cache is corrupt")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("could not decrypt session cache, wrong passphrase?")
+	}
+
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, fmt.Errorf("session cache is corrupt: %v", err)
+	}
+
+	// drop expired sessions
+	for name, entry := range entries {
+		if !DefaultClock.Now().Before(entry.Expires) {
+			delete(entries, name)
+		}
+	}
+
+	return entries, nil
+}
+
+// save encrypts the entries with a new salt and nonce and replaces the cache file
+func (c *SessionCache) save(entries map[string]sessionCacheEntry) error {
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, sessionCacheSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	gcm, err := c.cipher(salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data := append(salt, nonce...)
+	data = gcm.Seal(data, nonce, plaintext, nil)
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return fmt.Errorf("could not create session cache directory: %v", err)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("could not write session cache: %v", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+func (c *SessionCache) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, c.passphrase, salt, sessionCacheIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SumaLoginCached returns a cached SUMA session cookie for the user and server or logs in and caches
+// the new session for its lifetime.
+func SumaLoginCached(cache *SessionCache, username, password, susemgr string, verbose bool, opts ...Option) (sessioncookie string, err error) {
+
+	name := fmt.Sprintf("suma|%s|%s", susemgr, username)
+
+	sessioncookie, ok, err := cache.Get(name)
+	if err != nil {
+		logWarnf("could not read session cache, login again: %v", err)
+	}
+	if ok {
+		if verbose {
+			logDebugf("SUMAAPI SumaLoginCached: use cached session")
+		}
+		return sessioncookie, nil
+	}
+
+	sessioncookie, maxAge, err := sumaLogin(username, password, susemgr, verbose, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	lifetime := time.Duration(maxAge) * time.Second
+	if lifetime <= 0 {
+		lifetime = sumaSessionLifetime
+	}
+	if sessioncookie != "" {
+		if err := cache.Put(name, sessioncookie, DefaultClock.Now().Add(lifetime-sessionExpiryMargin)); err != nil {
+			logErrorf("could not write session cache: %v", err)
+		}
+	}
+
+	return sessioncookie, nil
+}
+
+// MsLoginCached returns a cached Meshstack access token for the client or logs in and caches the new
+// token for its lifetime. Tokens without expires_in are not cached.
+func MsLoginCached(cache *SessionCache, clientid, clientsecret, apiurl string, verbose bool, opts ...Option) (accesstoken string, err error) {
+
+	name := fmt.Sprintf("meshstack|%s|%s", apiurl, clientid)
+
+	accesstoken, ok, err := cache.Get(name)
+	if err != nil {
+		logWarnf("could not read session cache, login again: %v", err)
+	}
+	if ok {
+		if verbose {
+			logDebugf("MSAPI MsLoginCached: use cached access token")
+		}
+		return accesstoken, nil
+	}
+
+	accesstoken, expiresIn, err := msLogin(clientid, clientsecret, apiurl, verbose, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	lifetime := time.Duration(expiresIn)*time.Second - sessionExpiryMargin
+	if accesstoken != "" && lifetime > 0 {
+		if err := cache.Put(name, accesstoken, DefaultClock.Now().Add(lifetime)); err != nil {
+			logErrorf("could not write session cache: %v", err)
+		}
+	}
+
+	return accesstoken, nil
+}
@@ -0,0 +1,74 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions")
+
+	cache, err := NewSessionCache(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cache.Put("suma", "cookie-value", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if err := cache.Put("expired", "old", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "cookie-value") {
+		t.Error("session cache is not encrypted")
+	}
+
+	value, ok, err := cache.Get("suma")
+	if err != nil || !ok || value != "cookie-value" {
+		t.Errorf("Get() = %q, %v, %v; want cookie-value, true, nil", value, ok, err)
+	}
+	if _, ok, _ := cache.Get("expired"); ok {
+		t.Error("expected expired session to be ignored")
+	}
+
+	wrongKey, _ := NewSessionCache(path, "other")
+	if _, _, err := wrongKey.Get("suma"); err == nil {
+		t.Error("expected error for wrong passphrase, got nil")
+	}
+}
+
+func TestSumaLoginCached(t *testing.T) {
+	logins := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logins++
+		http.SetCookie(w, &http.Cookie{Name: "pxt-session-cookie", Value: fmt.Sprintf("cookie-%d", logins), MaxAge: 3600})
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mockServer.Close()
+
+	cache, _ := NewSessionCache(filepath.Join(t.TempDir(), "sessions"), "passphrase")
+
+	for i := 0; i < 3; i++ {
+		cookie, err := SumaLoginCached(cache, "admin", "secret", mockServer.URL, false)
+		if err != nil {
+			t.Fatalf("SumaLoginCached returned error: %v", err)
+		}
+		if cookie != "cookie-1" {
+			t.Errorf("expected cached cookie-1, got %q", cookie)
+		}
+	}
+	if logins != 1 {
+		t.Errorf("expected 1 login, got %d", logins)
+	}
+}
@@ -0,0 +1,113 @@
+package appapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// CoalesceReads shares the response of a GET call with the identical calls started while it is in
+// flight, e.g. parallel workers listing the same system group. Calls are identical if they have the
+// same URL, Accept header and credentials. The shared call is logged with the request ID of the
+// first caller only.
+var CoalesceReads = true
+
+// WithCoalescing sends a GET call on its own if disabled, see CoalesceReads
+func WithCoalescing(enabled bool) Option {
+	return func(o *callOptions) {
+		o.coalesce = enabled
+	}
+}
+
+// flightGroup tracks the GET calls in flight by their key
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is a call in flight, the response is complete when done is closed
+type flight struct {
+	done    chan struct{}
+	waiters int
+	resp    *http.Response
+	body    []byte
+	bodyErr error
+	err     error
+}
+
+var readFlights = &flightGroup{calls: map[string]*flight{}}
+
+func flightKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String() + "\x00" + req.Header.Get("Accept") + "\x00" +
+		req.Header.Get("Authorization") + "\x00" + req.Header.Get("Cookie")
+}
+
+// do sends the request with fetch unless an identical request is in flight, whose response is
+// returned instead. The body of the response is read completely to share it.
+func (g *flightGroup) do(req *http.Request, fetch func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	key := flightKey(req)
+
+	g.mu.Lock()
+	if f, ok := g.calls[key]; ok {
+		f.waiters++
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		// the first caller gave up, e.g. on its timeout, which does not apply to this one
+		if errors.Is(f.err, context.Canceled) || errors.Is(f.err, context.DeadlineExceeded) {
+			return fetch(req)
+		}
+		transportStats.sharedCalls.Add(1)
+		return f.response(req)
+	}
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	f.resp, f.err = fetch(req)
+	if f.err == nil {
+		f.body, f.bodyErr = io.ReadAll(f.resp.Body)
+		f.resp.Body.Close()
+	}
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(f.done)
+
+	return f.response(req)
+}
+
+// response returns a copy of the response of the flight for the request. An error reading the body,
+// e.g. ErrResponseTooLarge, is returned by the body after the data read before.
+func (f *flight) response(req *http.Request) (*http.Response, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Request = req
+	var body io.Reader = bytes.NewReader(f.body)
+	if f.bodyErr != nil {
+		body = io.MultiReader(body, errorReader{f.bodyErr})
+	} else {
+		resp.ContentLength = int64(len(f.body))
+	}
+	resp.Body = io.NopCloser(body)
+	return &resp, nil
+}
+
+// errorReader fails every read with err
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
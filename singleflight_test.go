@@ -0,0 +1,190 @@
+package appapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBlockingServer answers with the body once release is closed and counts the requests. The body
+// is sent without a Content-Length.
+func newBlockingServer(t *testing.T, body string) (*httptest.Server, *atomic.Int32, chan struct{}) {
+	t.Helper()
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		fmt.Fprint(w, body)
+		w.(http.Flusher).Flush()
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests, release
+}
+
+// waitForWaiters waits until n calls wait for the call in flight
+func waitForWaiters(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		readFlights.mu.Lock()
+		waiters := 0
+		for _, f := range readFlights.calls {
+			waiters += f.waiters
+		}
+		readFlights.mu.Unlock()
+		if waiters >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d calls did not join the call in flight", n)
+}
+
+func TestCoalesceReads(t *testing.T) {
+	server, requests, release := newBlockingServer(t, `{"success": true, "result": [{"name": "web"}, {"name": "db"}]}`)
+	before := ReadTransportStats()
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make([][]string, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = sumaListSystemGroups("cookie", server.URL, false)
+		}()
+	}
+	waitForWaiters(t, callers-1)
+	close(release)
+	wg.Wait()
+
+	for i := range callers {
+		if errs[i] != nil || len(results[i]) != 2 {
+			t.Errorf("caller %d got %v, %v, want the 2 groups", i, results[i], errs[i])
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d requests, want 1 shared by all callers", n)
+	}
+	if shared := ReadTransportStats().Sub(before).SharedCalls; shared != callers-1 {
+		t.Errorf("%d shared calls, want %d", shared, callers-1)
+	}
+}
+
+func TestCoalesceReadsDistinctCalls(t *testing.T) {
+	server, requests, release := newBlockingServer(t, `{"success": true, "result": []}`)
+	close(release)
+
+	// different credentials or a disabled coalescing are never shared
+	var wg sync.WaitGroup
+	for _, call := range []func() error{
+		func() error { _, err := sumaListSystemGroups("cookie1", server.URL, false); return err },
+		func() error { _, err := sumaListSystemGroups("cookie2", server.URL, false); return err },
+		func() error {
+			_, err := sumaListSystemGroups("cookie1", server.URL, false, WithCoalescing(false))
+			return err
+		},
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := call(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := requests.Load(); n != 3 {
+		t.Errorf("%d requests, want 3", n)
+	}
+}
+
+func TestCoalesceReadsFirstCallerGivesUp(t *testing.T) {
+	server, requests, release := newBlockingServer(t, `{"success": true, "result": [{"name": "web"}]}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/rhn/manager/api/systemgroup/listAllGroups", nil)
+		resp, err := readFlights.do(req, (&apiTransport{base: defaultTransport(false)}).roundTrip)
+		if err == nil {
+			resp.Body.Close()
+		}
+		first <- err
+	}()
+	waitForFlight(t)
+
+	second := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/rhn/manager/api/systemgroup/listAllGroups", nil)
+		resp, err := readFlights.do(req, (&apiTransport{base: defaultTransport(false)}).roundTrip)
+		if err == nil {
+			resp.Body.Close()
+		}
+		second <- err
+	}()
+	waitForWaiters(t, 1)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("first call error = %v, want canceled", err)
+	}
+	close(release)
+	// the second caller sends the call itself instead of failing with the cancellation of the first
+	if err := <-second; err != nil {
+		t.Errorf("second call error = %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("%d requests, want 2", n)
+	}
+}
+
+// waitForFlight waits until a call is in flight
+func waitForFlight(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		readFlights.mu.Lock()
+		n := len(readFlights.calls)
+		readFlights.mu.Unlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("no call in flight")
+}
+
+func TestCoalesceReadsSharesBodyErrors(t *testing.T) {
+	defer func(size int64) { MaxResponseSize = size }(MaxResponseSize)
+	MaxResponseSize = 16
+
+	server, _, release := newBlockingServer(t, `{"success": true, "result": [{"name": "a group name longer than the limit"}]}`)
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = sumaListSystemGroups("cookie", server.URL, false)
+		}()
+	}
+	waitForWaiters(t, 1)
+	close(release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err == nil || !strings.Contains(err.Error(), ErrResponseTooLarge.Error()) {
+			t.Errorf("caller %d error = %v, want %v", i, err, ErrResponseTooLarge)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// snapshotTagPattern are the characters SUMA accepts in a snapshot tag
+var snapshotTagPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,256}$`)
+
+// SumaTagLatestSnapshot tags the latest snapshot of a system, e.g. with the change ticket before a
+// patch run, so the system can be rolled back to the snapshot by the tag
+func SumaTagLatestSnapshot(sessioncookie, susemgr string, systemID int, tag string, verbose bool, opts ...Option) (err error) {
+	if !snapshotTagPattern.MatchString(tag) {
+		return &ValidationError{Payload: "snapshot tag", Field: "tagName", Reason: fmt.Sprintf("%q may only contain letters, digits, '.', '_' and '-'", tag)}
+	}
+	if verbose {
+		Logger().Debug("SUMAAPI SumaTagLatestSnapshot: tag snapshot", "system_id", systemID, "tag", tag)
+	}
+	payload := map[string]any{"sid": systemID, "tagName": tag}
+	return sumaPost(sessioncookie, susemgr, "system/tagLatestSnapshot", payload, nil, verbose, opts...)
+}
+
+// TagLatestSnapshot tags the latest snapshot of a system, see SumaTagLatestSnapshot
+func (c *SumaClient) TagLatestSnapshot(systemID int, tag string, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return SumaTagLatestSnapshot(c.SessionCookie(), c.URL, systemID, tag, verbose, opts...)
+}
+
+// TagPreChangeSnapshots tags the latest snapshot of every system with the change ticket. It is
+// called before a patch run, so the snapshots are the state before the change. All systems are
+// tagged, the errors are joined.
+func (c *SumaClient) TagPreChangeSnapshots(ticket string, systemIDs []int, opts ...Option) error {
+	var errs []error
+	for _, id := range systemIDs {
+		if err := c.TagLatestSnapshot(id, ticket, opts...); err != nil {
+			errs = append(errs, fmt.Errorf("could not tag the snapshot of system %d: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,45 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTagPreChangeSnapshots(t *testing.T) {
+	tagged := map[int]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/system/tagLatestSnapshot" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var payload struct {
+			ID  int    `json:"sid"`
+			Tag string `json:"tagName"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		if payload.ID == 3 {
+			fmt.Fprint(w, `{"success": false, "message": "No snapshots found for system"}`)
+			return
+		}
+		tagged[payload.ID] = payload.Tag
+		fmt.Fprint(w, `{"success": true, "result": 1}`)
+	}))
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	err := c.TagPreChangeSnapshots("CHG0012345", []int{1, 2, 3})
+	if err == nil || tagged[1] != "CHG0012345" || tagged[2] != "CHG0012345" {
+		t.Errorf("expected systems 1 and 2 to be tagged and an error for system 3, got %v, %v", tagged, err)
+	}
+
+	var verr *ValidationError
+	if err := c.TagLatestSnapshot(1, "CHG 1"); !errors.As(err, &verr) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
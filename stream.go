@@ -0,0 +1,194 @@
+package appapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// streamJSONArray decodes the array at path, e.g. _embedded.meshBuildingBlocks, element by element and
+// calls fn for every element, so large lists are not buffered in memory. A missing path or null is an
+// empty list.
+func streamJSONArray[T any](dec *json.Decoder, path []string, fn func(T) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+
+	delim, ok := tok.(json.Delim)
+	if len(path) == 0 {
+		if !ok || delim != '[' {
+			return fmt.Errorf("expected a JSON array, got %v", tok)
+		}
+		for dec.More() {
+			var item T
+			if err := dec.Decode(&item); err != nil {
+				return err
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	}
+
+	if !ok || delim != '{' {
+		return fmt.Errorf("expected a JSON object at %s, got %v", path[0], tok)
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key == path[0] {
+			err = streamJSONArray(dec, path[1:], fn)
+		} else {
+			err = skipJSONValue(dec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// skipJSONValue reads the next value without keeping it
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// sumaStream calls a SUMA list method and calls fn for every element of the result
+func sumaStream[T any](sessioncookie, susemgr, method string, query url.Values, fn func(T) error, verbose bool, opts ...Option) (err error) {
+
+	apiMethod := fmt.Sprintf("%s%s/%s", susemgr, "/rhn/manager/api", method)
+	if len(query) > 0 {
+		apiMethod += "?" + query.Encode()
+	}
+	if verbose {
+		logDebugf("SUMAAPI sumaStream: apiMethod = %s", apiMethod)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, apiMethod, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for %s: %v", method, err)
+	}
+
+	// Add headers
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "pxt-session-cookie",
+		Value: sessioncookie,
+	})
+
+	// Send the HTTP request, a shared response would be buffered in memory
+	o := newCallOptions(verbose, opts)
+	o.coalesce = false
+	resp, err := o.do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %v", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	// Check HTTP status
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	var success bool
+	var message string
+	count := 0
+
+	dec := json.NewDecoder(resp.Body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("error unmarshaling JSON: expected an object, got %v %v", tok, err)
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("error unmarshaling JSON: %v", err)
+		}
+		switch key {
+		case "success":
+			err = dec.Decode(&success)
+		case "message":
+			err = dec.Decode(&message)
+		case "result":
+			err = streamJSONArray(dec, nil, func(item T) error {
+				count++
+				return fn(item)
+			})
+		default:
+			err = skipJSONValue(dec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if verbose {
+		logDebugf("SUMAAPI sumaStream: %s returned %d item(s)", method, count)
+	}
+
+	if !success {
+		return withRequestID(resp, fmt.Errorf("%s failed: %s", method, message))
+	}
+	return nil
+}
+
+// SumaEachSystem calls fn for every system registered in SUSE Manager. The list is decoded while it is
+// read, so also very large installations are not buffered in memory. An error of fn stops the listing.
+func SumaEachSystem(sessioncookie, susemgr string, fn func(SystemInfo) error, verbose bool, opts ...Option) (err error) {
+	return sumaStream(sessioncookie, susemgr, "system/listSystems", nil, func(s struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}) error {
+		return fn(SystemInfo{ID: s.ID, Name: s.Name})
+	}, verbose, opts...)
+}
+
+// SumaListSystems returns all systems registered in SUSE Manager, see SumaEachSystem
+func SumaListSystems(sessioncookie, susemgr string, verbose bool, opts ...Option) (systems []SystemInfo, err error) {
+	err = SumaEachSystem(sessioncookie, susemgr, func(s SystemInfo) error {
+		systems = append(systems, s)
+		return nil
+	}, verbose, opts...)
+	return systems, err
+}
+
+// EachSystem calls fn for every system, see SumaEachSystem
+func (c *SumaClient) EachSystem(fn func(SystemInfo) error, opts ...Option) error {
+	verbose, opts := c.options(opts)
+	return SumaEachSystem(c.SessionCookie(), c.URL, fn, verbose, opts...)
+}
+
+// ListSystems returns all systems, see SumaListSystems
+func (c *SumaClient) ListSystems(opts ...Option) ([]SystemInfo, error) {
+	verbose, opts := c.options(opts)
+	return SumaListSystems(c.SessionCookie(), c.URL, verbose, opts...)
+}
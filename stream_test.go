@@ -0,0 +1,92 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStreamJSONArray(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		path    []string
+		want    []int
+		wantErr bool
+	}{
+		{name: "top level array", doc: `[1, 2, 3]`, want: []int{1, 2, 3}},
+		{name: "nested", doc: `{"a": {"x": [9], "b": [1, 2]}, "c": {"b": [7]}}`, path: []string{"a", "b"}, want: []int{1, 2}},
+		{name: "skip values around", doc: `{"before": [{"x": [1]}], "list": [4, 5], "after": "s"}`, path: []string{"list"}, want: []int{4, 5}},
+		{name: "missing path", doc: `{"other": [1]}`, path: []string{"list"}, want: nil},
+		{name: "null", doc: `{"list": null}`, path: []string{"list"}, want: nil},
+		{name: "not an array", doc: `{"list": {"a": 1}}`, path: []string{"list"}, wantErr: true},
+		{name: "wrong element type", doc: `["a"]`, wantErr: true},
+		{name: "truncated", doc: `{"list": [1, 2`, path: []string{"list"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			err := streamJSONArray(json.NewDecoder(strings.NewReader(tt.doc)), tt.path, func(i int) error {
+				got = append(got, i)
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("streamJSONArray() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("streamJSONArray() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSumaEachSystem(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/system/listSystems" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "name": "host1", "last_checkin": "x"}, {"id": 2, "name": "host2"}, {"id": 3, "name": "host3"}]}`)
+	}))
+	defer server.Close()
+
+	systems, err := SumaListSystems("cookie", server.URL, false)
+	if err != nil {
+		t.Fatalf("SumaListSystems() error = %v", err)
+	}
+	want := []SystemInfo{{ID: 1, Name: "host1"}, {ID: 2, Name: "host2"}, {ID: 3, Name: "host3"}}
+	if !reflect.DeepEqual(systems, want) {
+		t.Errorf("SumaListSystems() = %v, want %v", systems, want)
+	}
+
+	// an error of the callback stops the listing
+	stop := errors.New("stop")
+	count := 0
+	err = SumaEachSystem("cookie", server.URL, func(s SystemInfo) error {
+		count++
+		if s.ID == 2 {
+			return stop
+		}
+		return nil
+	}, false)
+	if !errors.Is(err, stop) || count != 2 {
+		t.Errorf("SumaEachSystem() = %v after %d systems, want stop after 2", err, count)
+	}
+}
+
+func TestSumaEachSystemFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": false, "message": "permission denied"}`)
+	}))
+	defer server.Close()
+
+	_, err := SumaListSystems("cookie", server.URL, false)
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("SumaListSystems() error = %v, want permission denied", err)
+	}
+}
@@ -0,0 +1,154 @@
+package appapi
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PinnedSubscription pins a subscription to a system, so the subscription matcher prefers it
+type PinnedSubscription struct {
+	ID             int `json:"id"`
+	SubscriptionID int `json:"subscription_id"`
+	SystemID       int `json:"system_id"`
+}
+
+// SumaListPinnedSubscriptions returns the subscriptions pinned to systems
+func SumaListPinnedSubscriptions(sessioncookie, susemgr string, verbose bool, opts ...Option) (pins []PinnedSubscription, err error) {
+	err = sumaGet(sessioncookie, susemgr, "subscriptionmatching/pinnedsubscription/list", nil, &pins, verbose, opts...)
+	return pins, err
+}
+
+// SumaCreatePinnedSubscription pins a subscription to a system
+func SumaCreatePinnedSubscription(sessioncookie, susemgr string, subscriptionID, systemID int, verbose bool, opts ...Option) (pin PinnedSubscription, err error) {
+	payload := map[string]any{"subscriptionId": subscriptionID, "sid": systemID}
+	err = sumaPost(sessioncookie, susemgr, "subscriptionmatching/pinnedsubscription/create", payload, &pin, verbose, opts...)
+	return pin, err
+}
+
+// SumaDeletePinnedSubscription removes a pinned subscription
+func SumaDeletePinnedSubscription(sessioncookie, susemgr string, id int, verbose bool, opts ...Option) (err error) {
+	payload := map[string]any{"subscriptionId": id}
+	return sumaPost(sessioncookie, susemgr, "subscriptionmatching/pinnedsubscription/delete", payload, nil, verbose, opts...)
+}
+
+// SubscriptionUsage is a line of the subscription report of the subscription matcher
+type SubscriptionUsage struct {
+	PartNumber  string    `json:"partNumber"`
+	Description string    `json:"description"`
+	Policy      string    `json:"policy"`
+	Matched     int       `json:"matched"`
+	Total       int       `json:"total"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// Free returns the number of unused entitlements of the subscription
+func (u SubscriptionUsage) Free() int {
+	return u.Total - u.Matched
+}
+
+// ParseSubscriptionReport parses the subscription report of the subscription matcher,
+// subscription_report.csv, which lists the consumption of every subscription. The columns are found
+// by their headers, so reports of 4.3 and 5.x can be parsed.
+func ParseSubscriptionReport(r io.Reader) (usage []SubscriptionUsage, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the header of the subscription report: %v", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"part number", "matched", "total"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("subscription report has no column %q", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return usage, nil
+		}
+		if err != nil {
+			return usage, fmt.Errorf("subscription report line %d: %v", line, err)
+		}
+
+		u := SubscriptionUsage{
+			PartNumber:  field(record, "part number"),
+			Description: field(record, "description"),
+			Policy:      field(record, "policy"),
+			Start:       parseSumaTime(field(record, "start date")),
+			End:         parseSumaTime(field(record, "end date")),
+		}
+		if u.Matched, err = strconv.Atoi(field(record, "matched")); err != nil {
+			return usage, fmt.Errorf("subscription report line %d: invalid matched count: %v", line, err)
+		}
+		if u.Total, err = strconv.Atoi(field(record, "total")); err != nil {
+			return usage, fmt.Errorf("subscription report line %d: invalid total count: %v", line, err)
+		}
+		usage = append(usage, u)
+	}
+}
+
+// GroupSubscriptions counts the subscriptions pinned to the members of a system group
+type GroupSubscriptions struct {
+	Group string `json:"group"`
+	// Subscriptions maps the subscription IDs to the number of members pinned to them
+	Subscriptions map[int]int `json:"subscriptions"`
+	// Unpinned are the members without a pinned subscription
+	Unpinned []SystemInfo `json:"unpinned"`
+}
+
+// countGroupSubscriptions counts the pinned subscriptions of the systems
+func countGroupSubscriptions(group string, systems []SystemInfo, pins []PinnedSubscription) GroupSubscriptions {
+	report := GroupSubscriptions{Group: group, Subscriptions: map[int]int{}}
+	pinned := map[int][]int{}
+	for _, pin := range pins {
+		pinned[pin.SystemID] = append(pinned[pin.SystemID], pin.SubscriptionID)
+	}
+	for _, system := range systems {
+		if len(pinned[system.ID]) == 0 {
+			report.Unpinned = append(report.Unpinned, system)
+		}
+		for _, id := range pinned[system.ID] {
+			report.Subscriptions[id]++
+		}
+	}
+	return report
+}
+
+// PinnedSubscriptions returns the subscriptions pinned to systems, see SumaListPinnedSubscriptions
+func (c *SumaClient) PinnedSubscriptions(opts ...Option) ([]PinnedSubscription, error) {
+	verbose, opts := c.options(opts)
+	return SumaListPinnedSubscriptions(c.SessionCookie(), c.URL, verbose, opts...)
+}
+
+// GroupSubscriptions counts the subscriptions pinned to the members of a system group, e.g. to report
+// the license consumption of a project
+func (c *SumaClient) GroupSubscriptions(group string, opts ...Option) (GroupSubscriptions, error) {
+	systems, err := c.GroupSystems(group)
+	if err != nil {
+		return GroupSubscriptions{Group: group}, err
+	}
+	pins, err := c.PinnedSubscriptions(opts...)
+	if err != nil {
+		return GroupSubscriptions{Group: group}, err
+	}
+	return countGroupSubscriptions(group, systems, pins), nil
+}
@@ -0,0 +1,59 @@
+package appapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSubscriptionReport(t *testing.T) {
+	report := `"Part number","Description","Policy","Matched","Total","Start date","End date"
+"874-007013","SUSE Linux Enterprise Server, x86_64, 1-2 Sockets","Physical deployment only","12","20","2024-01-01T00:00:00Z","2025-01-01T00:00:00Z"
+"874-007015","SUSE Manager Lifecycle Management","Unlimited virtual machines","30","30","2024-01-01T00:00:00Z","2025-01-01T00:00:00Z"
+`
+	usage, err := ParseSubscriptionReport(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(usage) != 2 || usage[0].PartNumber != "874-007013" || usage[0].Free() != 8 || usage[1].Free() != 0 || usage[0].End.Year() != 2025 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	if _, err := ParseSubscriptionReport(strings.NewReader("Part number,Total\n")); err == nil {
+		t.Error("expected an error for a report without the matched column")
+	}
+	if _, err := ParseSubscriptionReport(strings.NewReader("Part number,Matched,Total\n874-007013,x,20\n")); err == nil {
+		t.Error("expected an error for an invalid count")
+	}
+}
+
+func TestGroupSubscriptions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listSystemsMinimal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"id": 1, "name": "host1"}, {"id": 2, "name": "host2"}, {"id": 3, "name": "host3"}]}`)
+	})
+	mux.HandleFunc("/rhn/manager/api/subscriptionmatching/pinnedsubscription/list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [
+			{"id": 1, "subscription_id": 100, "system_id": 1},
+			{"id": 2, "subscription_id": 100, "system_id": 2},
+			{"id": 3, "subscription_id": 200, "system_id": 2},
+			{"id": 4, "subscription_id": 100, "system_id": 9}
+		]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &SumaClient{URL: server.URL, sessioncookie: "cookie"}
+
+	got, err := c.GroupSubscriptions("clab")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := GroupSubscriptions{Group: "clab", Subscriptions: map[int]int{100: 2, 200: 1}, Unpinned: []SystemInfo{{ID: 3, Name: "host3"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -3,59 +3,236 @@ package appapi
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"net"
 	"net/http"
-	"os"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Patch osExit for testing
-var osExit = os.Exit
+// sumaAPI are the SUMA calls the composite operations are built on, e.g. SumaAddSystemResult resolves
+// the system with GetSystemID and GetSystemIP before it changes the group. The functions use sumaHTTP,
+// a SumaClient its own implementation, so tests inject a fake instead of replacing package variables.
+type sumaAPI interface {
+	GetSystemID(sessioncookie, susemgr, hostname string, verbose bool, opts ...Option) (int, error)
+	GetSystemIP(sessioncookie, susemgr string, id int, verbose bool, opts ...Option) (string, error)
+	GetSystemIP6(sessioncookie, susemgr string, id int, verbose bool, opts ...Option) (string, error)
+	GetLockStatus(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (bool, error)
+	ListSystemGroups(sessioncookie, susemgr string, verbose bool, opts ...Option) ([]string, error)
+	ListUsers(sessioncookie, susemgr string, verbose bool, opts ...Option) ([]string, error)
+}
+
+// sumaHTTP calls the SUMA API
+type sumaHTTP struct{}
+
+func (sumaHTTP) GetSystemID(sessioncookie, susemgr, hostname string, verbose bool, opts ...Option) (int, error) {
+	return sumaGetSystemID(sessioncookie, susemgr, hostname, verbose, opts...)
+}
+
+func (sumaHTTP) GetSystemIP(sessioncookie, susemgr string, id int, verbose bool, opts ...Option) (string, error) {
+	return sumaGetSystemIP(sessioncookie, susemgr, id, verbose, opts...)
+}
+
+func (sumaHTTP) GetSystemIP6(sessioncookie, susemgr string, id int, verbose bool, opts ...Option) (string, error) {
+	return sumaGetSystemIP6(sessioncookie, susemgr, id, verbose, opts...)
+}
+
+func (sumaHTTP) GetLockStatus(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (bool, error) {
+	return sumaGetLockStatus(sessioncookie, susemgr, systemID, verbose, opts...)
+}
+
+func (sumaHTTP) ListSystemGroups(sessioncookie, susemgr string, verbose bool, opts ...Option) ([]string, error) {
+	return sumaListSystemGroups(sessioncookie, susemgr, verbose, opts...)
+}
+
+func (sumaHTTP) ListUsers(sessioncookie, susemgr string, verbose bool, opts ...Option) ([]string, error) {
+	return sumaListUsers(sessioncookie, susemgr, verbose, opts...)
+}
 
-var isSystemInNetwork = func(pip, pnetwork string) bool {
-	// Define the IP address and the CIDR range
-	ip := net.ParseIP(pip)
-	pnet := fmt.Sprintf("%s/24", pnetwork)
-	_, network, err := net.ParseCIDR(pnet)
+// IsSystemInNetwork reports if the IP address belongs to the network. The network is given in CIDR
+// notation, e.g. 192.168.1.0/23 or 2001:db8::/64. A network without prefix length is treated as
+// /24 for IPv4 and /64 for IPv6, as in earlier versions.
+func IsSystemInNetwork(pip, pnetwork string) bool {
+	ip, err := netip.ParseAddr(pip)
+	if err != nil {
+		return false
+	}
+
+	if !strings.Contains(pnetwork, "/") {
+		if strings.Contains(pnetwork, ":") {
+			pnetwork += "/64"
+		} else {
+			pnetwork += "/24"
+		}
+	}
+	network, err := netip.ParsePrefix(pnetwork)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing CIDR: %v\n", err)
+		logErrorf("Error parsing CIDR: %v", err)
 		return false
 	}
-	return network.Contains(ip)
 
+	return network.Masked().Contains(ip.WithZone("").Unmap())
 }
 
-var sumaGetSystemID = func(sessioncookie, susemgr, hostname string, verbose bool) (id int, err error) {
+// sumaSystemInNetwork checks the IP of a system. SUMA reports dual stack systems with their IPv4
+// address, for an IPv6 network their IPv6 address is checked.
+func sumaSystemInNetwork(api sumaAPI, sessioncookie, susemgr string, id int, ip, network string, verbose bool, opts ...Option) bool {
+	if IsSystemInNetwork(ip, network) {
+		return true
+	}
+	if !strings.Contains(network, ":") || strings.Contains(ip, ":") {
+		return false
+	}
 
-	type ResultSystemGetID struct {
-		ID   int    `json:"id"`
-		Name string `json:"name"`
+	ip6, err := api.GetSystemIP6(sessioncookie, susemgr, id, verbose, opts...)
+	if err != nil {
+		logErrorf("could not get IPv6 address of system %d: %v", id, err)
+		return false
+	}
+	return ip6 != "" && IsSystemInNetwork(ip6, network)
+}
+
+// SystemMatchPolicy decides which system is used if several systems are registered with the same hostname
+type SystemMatchPolicy string
+
+// The policies for ambiguous hostnames
+const (
+	// MatchError fails with an AmbiguousSystemError listing all candidates
+	MatchError SystemMatchPolicy = "error"
+	// MatchLatestCheckin uses the system which checked in last
+	MatchLatestCheckin SystemMatchPolicy = "latest-checkin"
+	// MatchNetwork uses the only system in the permitted network of the call, else it fails
+	MatchNetwork SystemMatchPolicy = "network"
+)
+
+// WithSystemMatch sets the policy of the calls resolving a hostname to a system ID, the default is
+// MatchError
+func WithSystemMatch(policy SystemMatchPolicy) Option {
+	return func(o *callOptions) {
+		o.systemMatch = policy
+	}
+}
+
+// SystemCandidate is one of the systems registered with an ambiguous hostname
+type SystemCandidate struct {
+	ID          int
+	Name        string
+	LastCheckin time.Time
+}
+
+// AmbiguousSystemError is returned if a hostname matches several systems and the policy cannot choose one
+type AmbiguousSystemError struct {
+	Hostname   string
+	Network    string
+	Candidates []SystemCandidate
+}
+
+func (e *AmbiguousSystemError) Error() string {
+	ids := make([]string, 0, len(e.Candidates))
+	for _, c := range e.Candidates {
+		id := fmt.Sprintf("%d (%s", c.ID, c.Name)
+		if !c.LastCheckin.IsZero() {
+			id += ", last checkin " + c.LastCheckin.Format(time.RFC3339)
+		}
+		ids = append(ids, id+")")
+	}
+	where := ""
+	if e.Network != "" {
+		where = " in network " + e.Network
+	}
+	return fmt.Sprintf("hostname %s matches %d systems%s: %s", e.Hostname, len(e.Candidates), where, strings.Join(ids, ", "))
+}
+
+// parseSumaTime parses the date of a SUMA API result, a zero time if it cannot be parsed
+func parseSumaTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "Jan 2, 2006, 3:04:05 PM"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// sumaGetSystemIDInNetwork resolves the hostname like sumaGetSystemID. With the policy MatchNetwork an
+// ambiguous hostname is resolved to the only candidate in the network.
+func sumaGetSystemIDInNetwork(api sumaAPI, sessioncookie, susemgr, hostname, network string, verbose bool, opts ...Option) (id int, err error) {
+	id, err = api.GetSystemID(sessioncookie, susemgr, hostname, verbose, opts...)
+	var ambiguous *AmbiguousSystemError
+	if newCallOptions(verbose, opts).systemMatch != MatchNetwork || network == "" || !errors.As(err, &ambiguous) {
+		return id, err
+	}
+
+	var inNetwork []SystemCandidate
+	for _, c := range ambiguous.Candidates {
+		ip, err := api.GetSystemIP(sessioncookie, susemgr, c.ID, verbose, opts...)
+		if err != nil {
+			return -1, err
+		}
+		if sumaSystemInNetwork(api, sessioncookie, susemgr, c.ID, ip, network, verbose, opts...) {
+			inNetwork = append(inNetwork, c)
+		}
+	}
+	if verbose {
+		logDebugf("SUMAAPI sumaGetSystemIDInNetwork: %d of %d systems with hostname %s in network %s", len(inNetwork), len(ambiguous.Candidates), hostname, network)
 	}
 
-	type ResponseSystemGetID struct {
-		Success bool                `json:"success"`
-		Result  []ResultSystemGetID `json:"result"`
+	switch len(inNetwork) {
+	case 1:
+		return inNetwork[0].ID, nil
+	case 0:
+		return -1, fmt.Errorf("%w, none in network %s", ambiguous, network)
+	default:
+		return -1, &AmbiguousSystemError{Hostname: hostname, Network: network, Candidates: inNetwork}
+	}
+}
+
+// sumaGetSystemID resolves a hostname to a system ID. The hostname is normalized first, systems
+// registered with a different spelling are still found with the hostname as given.
+func sumaGetSystemID(sessioncookie, susemgr, hostname string, verbose bool, opts ...Option) (id int, err error) {
+	name := NormalizeHostname(hostname)
+	id, err = sumaLookupSystemID(sessioncookie, susemgr, name, verbose, opts...)
+
+	var ambiguous *AmbiguousSystemError
+	if err != nil && name != hostname && !errors.As(err, &ambiguous) {
+		if verbose {
+			logDebugf("SUMAAPI sumaGetSystemID: %s not found, trying %s", name, hostname)
+		}
+		return sumaLookupSystemID(sessioncookie, susemgr, hostname, verbose, opts...)
+	}
+	return id, err
+}
+
+func sumaLookupSystemID(sessioncookie, susemgr, hostname string, verbose bool, opts ...Option) (id int, err error) {
+
+	type ResultSystemGetID struct {
+		ID          int    `json:"id"`
+		Name        string `json:"name"`
+		LastCheckin string `json:"last_checkin"`
 	}
+
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgr, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaGetSystemID: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI sumaGetSystemID: apiURL =  %s", apiURL)
 	}
 
 	/*
 	 check if system is registered
 	*/
-	apiMethodgetSystemID := fmt.Sprintf("%s%s%s", apiURL, "/system/getId?name=", hostname)
+	apiMethodgetSystemID := fmt.Sprintf("%s%s%s", apiURL, "/system/getId?name=", url.QueryEscape(hostname))
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaGetSystemID: apiMethod = %s\n", apiMethodgetSystemID)
+		logDebugf("SUMAAPI sumaGetSystemID: apiMethod = %s", apiMethodgetSystemID)
 	}
 
 	// Create a new HTTP request
 	req, err := http.NewRequest(http.MethodGet, apiMethodgetSystemID, nil)
 	if err != nil {
-		log.Printf("error creating request to get hostname, error: %s\n", err)
+		logErrorf("error creating request to get hostname, error: %s", err)
 		return -1, err
 	}
 
@@ -67,75 +244,86 @@ var sumaGetSystemID = func(sessioncookie, susemgr, hostname string, verbose bool
 	})
 
 	// Send the HTTP request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("error sending request: %s\n", err)
+		logErrorf("error sending request: %s", err)
 		return -1, err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	// Check HTTP status
 	if resp.StatusCode != http.StatusOK {
-		fmt.Fprintf(os.Stderr, "HTTP Request failed: HTTP %d\n", resp.StatusCode)
-		osExit(1)
+		logErrorf("HTTP Request failed: HTTP %d", resp.StatusCode)
+		return -1, statusError(resp)
 	}
 
 	// Read response body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("error reading http response: %s\n", err)
+		logErrorf("error reading http response: %s", err)
 		return -1, err
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaGetSystemID: Got resp.Body = %s\n", string(bodyBytes))
+		logDebugf("SUMAAPI sumaGetSystemID: Got resp.Body = %s", string(bodyBytes))
 	}
 
-	// Unmarshal the JSON response into the struct
-	var rsp ResponseSystemGetID
-	err = json.Unmarshal(bodyBytes, &rsp)
+	result, err := decodeSumaResponse[[]ResultSystemGetID](resp, "system/getId", bodyBytes)
 	if err != nil {
-		log.Printf("error unmarshaling JSON: %s\n", err)
 		return -1, err
 	}
 
 	// Extract and print all fields
 	var foundID int
-	for _, r := range rsp.Result {
+	var candidates []SystemCandidate
+	for _, r := range result {
 		foundID = r.ID
+		candidates = append(candidates, SystemCandidate{ID: r.ID, Name: r.Name, LastCheckin: parseSumaTime(r.LastCheckin)})
+	}
+
+	if len(candidates) > 1 {
+		policy := newCallOptions(verbose, opts).systemMatch
+		if verbose {
+			logDebugf("SUMAAPI sumaGetSystemID: %d systems with hostname %s, policy %s", len(candidates), hostname, policy)
+		}
+		if policy != MatchLatestCheckin {
+			return -1, &AmbiguousSystemError{Hostname: hostname, Candidates: candidates}
+		}
+		latest := candidates[0]
+		for _, c := range candidates[1:] {
+			if c.LastCheckin.After(latest.LastCheckin) {
+				latest = c
+			}
+		}
+		foundID = latest.ID
 	}
 
 	if foundID == 0 {
-		log.Printf("%s not found in SUSE Manager on %s\n", hostname, susemgr)
-		return -1, fmt.Errorf("%s not found in SUSE Manager on %s", hostname, susemgr)
+		logInfof("%s not found in SUSE Manager on %s", hostname, susemgr)
+		return -1, fmt.Errorf("system %s in SUSE Manager on %s: %w", hostname, susemgr, ErrNotFound)
 	}
 
 	return foundID, nil
 
 }
 
-var sumaGetSystemIP = func(sessioncookie, susemgr string, id int, verbose bool) (foundIP string, err error) {
+func sumaGetSystemIP(sessioncookie, susemgr string, id int, verbose bool, opts ...Option) (foundIP string, err error) {
 
 	type ResultSystemGetIP struct {
 		IP   string `json:"ip"`
+		IP6  string `json:"ip6"`
 		Name string `json:"hostname"`
 	}
 
-	type ResponseSystemGetIP struct {
-		Success bool              `json:"success"`
-		Result  ResultSystemGetIP `json:"result"`
-	}
-
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgr, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaGetSystemIP: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI sumaGetSystemIP: apiURL =  %s", apiURL)
 	}
 
 	/*
@@ -143,13 +331,13 @@ var sumaGetSystemIP = func(sessioncookie, susemgr string, id int, verbose bool)
 	*/
 	apiMethodgetSystemIP := fmt.Sprintf("%s%s%d", apiURL, "/system/getNetwork?sid=", id)
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaGetSystemIP: apiMethod = %s\n", apiMethodgetSystemIP)
+		logDebugf("SUMAAPI sumaGetSystemIP: apiMethod = %s", apiMethodgetSystemIP)
 	}
 
 	// Create a new HTTP request
 	req, err := http.NewRequest(http.MethodGet, apiMethodgetSystemIP, nil)
 	if err != nil {
-		log.Printf("error creating request to get IP from system, error: %s\n", err)
+		logErrorf("error creating request to get IP from system, error: %s", err)
 		return "", err
 	}
 
@@ -161,60 +349,65 @@ var sumaGetSystemIP = func(sessioncookie, susemgr string, id int, verbose bool)
 	})
 
 	// Send the HTTP request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("error sending request: %s\n", err)
+		logErrorf("error sending request: %s", err)
 		return "", err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	// Check HTTP status
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("HTTP Request failed: HTTP %d\n", resp.StatusCode)
-		return "", fmt.Errorf("HTTP Request failed: HTTP/%d", resp.StatusCode)
+		logErrorf("HTTP Request failed: HTTP %d", resp.StatusCode)
+		return "", statusError(resp)
 	}
 
 	// Read response body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("error reading http response: %s\n", err)
+		logErrorf("error reading http response: %s", err)
 		return "", err
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaGetSystemIP: Got resp.Body = %s\n", string(bodyBytes))
+		logDebugf("SUMAAPI sumaGetSystemIP: Got resp.Body = %s", string(bodyBytes))
 	}
-	// Unmarshal the JSON response into the struct
-	var rsp ResponseSystemGetIP
-	err = json.Unmarshal(bodyBytes, &rsp)
+	result, err := decodeSumaResponse[ResultSystemGetIP](resp, "system/getNetwork", bodyBytes)
 	if err != nil {
-		log.Printf("error unmarshaling JSON: %s\n", err)
 		return "", err
 	}
 
-	// Extract and print all fields
-	foundIP = rsp.Result.IP
+	// Extract and print all fields, IPv6 only systems have no IPv4 address
+	foundIP = result.IP
+	if foundIP == "" {
+		foundIP = result.IP6
+	}
 
 	if foundIP == "" {
-		log.Printf("ID: %d not found in SUSE Manager on %s\n", id, susemgr)
+		logInfof("ID: %d not found in SUSE Manager on %s", id, susemgr)
 		return "", fmt.Errorf("ID: %d not found in SUSE Manager on %s", id, susemgr)
 	}
 
 	if verbose {
-		log.Printf("DEBUG: Found IP = %s\n", foundIP)
+		logDebugf("Found IP = %s", foundIP)
 	}
 	return foundIP, nil
 
 }
 
 // SumaLogin get the Username and Password from Hashicorp Vault.
-func SumaLogin(username, password, susemgr string, verbose bool) (sessioncookie string, err error) {
+func SumaLogin(username, password, susemgr string, verbose bool, opts ...Option) (sessioncookie string, err error) {
+	sessioncookie, _, err = sumaLogin(username, password, susemgr, verbose, opts...)
+	return sessioncookie, err
+}
+
+// sumaLogin returns the session cookie and its Max-Age in seconds
+func sumaLogin(username, password, susemgr string, verbose bool, opts ...Option) (sessioncookie string, maxAge int, err error) {
 
 	type AuthRequest struct {
 		Login    string `json:"login"`
@@ -222,20 +415,20 @@ func SumaLogin(username, password, susemgr string, verbose bool) (sessioncookie
 	}
 
 	if verbose {
-		log.Println("DEBUG SUMAAPI SumaLogin: Enter function Login")
-		log.Println("DEBUG SUMAAPI SumaLogin: ====================")
-		defer log.Println("DEBUG SUMAAPI SumaLogin: Leave function Login")
+		logDebugf("SUMAAPI SumaLogin: Enter function Login")
+		logDebugf("SUMAAPI SumaLogin: ====================")
+		defer logDebugf("SUMAAPI SumaLogin: Leave function Login")
 	}
 
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgr, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaLogin: apiURL = %s\n", apiURL)
+		logDebugf("SUMAAPI SumaLogin: apiURL = %s", apiURL)
 	}
 
 	apiMethod := fmt.Sprintf("%s%s", apiURL, "/auth/login")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaLogin: apiMethod = %s", apiMethod)
+		logDebugf("SUMAAPI SumaLogin: apiMethod = %s", apiMethod)
 	}
 
 	// Create the authentication request payload
@@ -247,33 +440,36 @@ func SumaLogin(username, password, susemgr string, verbose bool) (sessioncookie
 	// Marshal the payload to JSON
 	payloadBytes, err := json.Marshal(authPayload)
 	if err != nil {
-		log.Printf("error marshalling payload: %v\n", err)
-		return "", err
+		logErrorf("error marshalling payload: %v", err)
+		return "", 0, err
 	}
 
 	// Create an HTTP POST request
 	req, err := http.NewRequest("POST", apiMethod, bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
-		return "", err
+		logErrorf("error creating request: %v", err)
+		return "", 0, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		logErrorf("error sending request: %v", err)
+		return "", 0, err
+	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	if resp.StatusCode != http.StatusOK {
 		if verbose {
-			log.Printf("DEBUG SUMAAPI SumaLogin: HTTP Request failed: HTTP %d\n", resp.StatusCode)
+			logDebugf("SUMAAPI SumaLogin: HTTP Request failed: HTTP %d", resp.StatusCode)
 		}
-		return "", fmt.Errorf("HTTP Request failed: HTTP/%d", resp.StatusCode)
+		return "", 0, statusError(resp)
 	}
 
 	// Extract the session cookie from the response headers
@@ -281,107 +477,123 @@ func SumaLogin(username, password, susemgr string, verbose bool) (sessioncookie
 
 	for _, cookie := range cookies {
 		if verbose {
-			log.Printf("DEBUG SUMAAPI SumaLogin: Cookie Name: %s, Cookie Value: %s, Cookie MaxAge: %d\n", cookie.Name, cookie.Value, cookie.MaxAge)
+			logDebugf("SUMAAPI SumaLogin: Cookie Name: %s, Cookie Value: %s, Cookie MaxAge: %d", cookie.Name, cookie.Value, cookie.MaxAge)
 		}
-		if cookie.Name == "pxt-session-cookie" && cookie.MaxAge == 3600 {
+		if cookie.Name == "pxt-session-cookie" && cookie.MaxAge > 0 {
 			sessioncookie = cookie.Value
+			maxAge = cookie.MaxAge
 		}
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaLogin: Session Cookie = %s\n", sessioncookie)
-		log.Printf("DEBUG SUMAAPI SumaLogin: Response status = %s\n", resp.Status)
+		logDebugf("SUMAAPI SumaLogin: Session Cookie = %s", sessioncookie)
+		logDebugf("SUMAAPI SumaLogin: Response status = %s", resp.Status)
 	}
 
 	// Handle the response body if needed
 	var responseBody bytes.Buffer
 	_, err = responseBody.ReadFrom(resp.Body)
 	if err != nil {
-		log.Printf("got error to read from respone body.\n")
-		return "", err
+		logErrorf("got error to read from respone body.")
+		return "", 0, err
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaLogin: Response body =  %s\n", responseBody.String())
+		logDebugf("SUMAAPI SumaLogin: Response body =  %s", responseBody.String())
 	}
 
-	return sessioncookie, nil
+	return sessioncookie, maxAge, nil
 }
 
-// SumaAddSystem add's a System to a SUSE Manager SystemGroup.
-func SumaAddSystem(sessioncookie, susemgr, hostname, group, network string, verbose bool) (statuscode int, err error) {
-
-	type AddRemoveSystem struct {
-		SystemGroupName string `json:"systemGroupName"`
-		ServerIds       []int  `json:"serverIds"`
-		Add             bool   `json:"add"`
+// SumaLogout ends the session of the session cookie, so it does not stay open on the server until it
+// expires
+func SumaLogout(sessioncookie, susemgr string, verbose bool, opts ...Option) (err error) {
+	if verbose {
+		logDebugf("SUMAAPI SumaLogout: end session")
 	}
+	return sumaPost(sessioncookie, susemgr, "auth/logout", nil, nil, verbose, opts...)
+}
+
+// SumaAddSystem add's a System to a SUSE Manager SystemGroup. It returns the HTTP status or -1 on an
+// error, see SumaAddSystemResult for the details of the added system.
+func SumaAddSystem(sessioncookie, susemgr, hostname, group, network string, verbose bool, opts ...Option) (statuscode int, err error) {
+	return statusCode(SumaAddSystemResult(sessioncookie, susemgr, hostname, group, network, verbose, opts...))
+}
+
+// SumaAddSystemResult add's a System to a SUSE Manager SystemGroup and returns the ID and IP of the
+// system.
+func SumaAddSystemResult(sessioncookie, susemgr, hostname, group, network string, verbose bool, opts ...Option) (result SystemResult, err error) {
+	return sumaAddSystemResult(sumaHTTP{}, sessioncookie, susemgr, hostname, group, network, verbose, opts...)
+}
+
+func sumaAddSystemResult(api sumaAPI, sessioncookie, susemgr, hostname, group, network string, verbose bool, opts ...Option) (result SystemResult, err error) {
 
 	if verbose {
-		log.Println("DEBUG SUMAAPI SumaAddSystem: Enter function")
-		log.Println("DEBUG SUMAAPI SumaAddSystem: ==============")
-		defer log.Println("DEBUG SUMAAPI SumaAddSystem: Leave function")
+		logDebugf("SUMAAPI SumaAddSystem: Enter function")
+		logDebugf("SUMAAPI SumaAddSystem: ==============")
+		defer logDebugf("SUMAAPI SumaAddSystem: Leave function")
 	}
 
-	foundID, err := sumaGetSystemID(sessioncookie, susemgr, hostname, verbose)
+	foundID, err := sumaGetSystemIDInNetwork(api, sessioncookie, susemgr, hostname, network, verbose, opts...)
 	if err != nil {
-		return -1, err
+		return result, err
 	}
 
 	if foundID == 0 {
-		return -1, fmt.Errorf("did not found the system in SUSE Manager")
+		return result, fmt.Errorf("did not found the system in SUSE Manager")
 	}
+	result = SystemResult{SystemID: foundID, Hostname: hostname, Group: group}
 
-	foundIP, err := sumaGetSystemIP(sessioncookie, susemgr, foundID, verbose)
+	foundIP, err := api.GetSystemIP(sessioncookie, susemgr, foundID, verbose, opts...)
 	if err != nil {
-		log.Printf("could not get ip, errorcode: %v\n", err)
-		return -1, err
+		logErrorf("could not get ip, errorcode: %v", err)
+		return result, err
 	}
 
 	if foundIP == "" {
-		return -1, fmt.Errorf("did not found the system ID %d in SUSE Manager", foundID)
+		return result, fmt.Errorf("did not found the system ID %d in SUSE Manager", foundID)
 	}
+	result.IP = foundIP
 
-	isValid := isSystemInNetwork(foundIP, network)
+	isValid := sumaSystemInNetwork(api, sessioncookie, susemgr, foundID, foundIP, network, verbose, opts...)
 
 	if !isValid {
-		return -1, fmt.Errorf("system cannot be added, the system does not belong to the permitted network")
+		return result, fmt.Errorf("system cannot be added, the system does not belong to the permitted network")
 	}
 
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgr, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaAddSystem: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI SumaAddSystem: apiURL =  %s", apiURL)
 	}
 
 	apiMethodAddOrRemoveSystems := fmt.Sprintf("%s%s", apiURL, "/systemgroup/addOrRemoveSystems")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaAddSystem: apiMethod = %s\n", apiMethodAddOrRemoveSystems)
+		logDebugf("SUMAAPI SumaAddSystem: apiMethod = %s", apiMethodAddOrRemoveSystems)
 	}
 
-	// Create the authentication request payload
-	AddRemoveSystemPayload := AddRemoveSystem{
-		SystemGroupName: group,
-		ServerIds:       []int{foundID},
-		Add:             true,
+	// Create the request payload
+	AddRemoveSystemPayload, err := NewSumaApiAddRemoveSystem(group, []int{foundID}, true)
+	if err != nil {
+		return result, err
 	}
 
 	// Marshal the payload to JSON
 	payloadBytes, err := json.Marshal(AddRemoveSystemPayload)
 	if err != nil {
-		log.Printf("Error marshalling payload: %v\n", err)
-		return -1, err
+		logErrorf("Error marshalling payload: %v", err)
+		return result, err
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaAddSystem: Payload =  %v\n", string(payloadBytes))
+		logDebugf("SUMAAPI SumaAddSystem: Payload =  %v", string(payloadBytes))
 	}
 
 	// Create an HTTP POST request
 	req, err := http.NewRequest(http.MethodPost, apiMethodAddOrRemoveSystems, bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
-		return -1, err
+		logErrorf("error creating request: %v", err)
+		return result, err
 	}
 
 	// Add headers
@@ -392,106 +604,113 @@ func SumaAddSystem(sessioncookie, susemgr, hostname, group, network string, verb
 	})
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("error sending request: %v\n", err)
-		return -1, err
+		logErrorf("error sending request: %v", err)
+		return result, err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaAddSystem: Add Node: %v\n", resp)
+		logDebugf("SUMAAPI SumaAddSystem: Add Node: %v", resp)
 	}
 
+	result.StatusCode = resp.StatusCode
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("HTTP Request failed: HTTP %d\n", resp.StatusCode)
-		return -1, err
+		return result, statusError(resp)
 	}
 
-	return resp.StatusCode, nil
+	result.Action = ResultAdded
+	if verbose {
+		Logger().Debug("SUMAAPI SumaAddSystem: system added", "host", hostname, "group", group, "system_id", foundID, "ip", foundIP)
+	}
+	return result, nil
 
 }
 
 // SumaDeleteSystem delete a System from the SUSE Manager. This implies, that it is also deleted from the SUSE Manager SystemGroup.
 // To ensure, that DeleteSystem could not delete other Systems from o differen IP range, the procedure check if the IP belongs
-// to the IP range we get from hashicorp vault.
-func SumaDeleteSystem(sessioncookie, susemgr, hostname, network string, verbose bool) (statsucode int, err error) {
+// to the IP range we get from hashicorp vault. It returns the HTTP status or -1 on an error, see
+// SumaDeleteSystemResult for the details of the deleted system.
+func SumaDeleteSystem(sessioncookie, susemgr, hostname, network string, verbose bool, opts ...Option) (statsucode int, err error) {
+	return statusCode(SumaDeleteSystemResult(sessioncookie, susemgr, hostname, network, verbose, opts...))
+}
 
-	type DeleteSystemType struct {
-		ServerID    int    `json:"sid"`
-		CleanupType string `json:"cleanupType"`
-	}
+// SumaDeleteSystemResult delete a System from the SUSE Manager like SumaDeleteSystem and returns the
+// ID and IP of the deleted system.
+func SumaDeleteSystemResult(sessioncookie, susemgr, hostname, network string, verbose bool, opts ...Option) (result SystemResult, err error) {
+	return sumaDeleteSystemResult(sumaHTTP{}, sessioncookie, susemgr, hostname, network, verbose, opts...)
+}
+
+func sumaDeleteSystemResult(api sumaAPI, sessioncookie, susemgr, hostname, network string, verbose bool, opts ...Option) (result SystemResult, err error) {
 
 	if verbose {
-		log.Println("DEBUG SUMAAPI SumeDeleteSystem: Enter function")
-		log.Println("DEBUG SUMAAPI SumeDeleteSystem: ==============")
-		defer log.Println("DEBUG SUMAAPI SumeDeleteSystem: Leave function")
+		logDebugf("SUMAAPI SumeDeleteSystem: Enter function")
+		logDebugf("SUMAAPI SumeDeleteSystem: ==============")
+		defer logDebugf("SUMAAPI SumeDeleteSystem: Leave function")
 	}
 
-	foundID, err := sumaGetSystemID(sessioncookie, susemgr, hostname, verbose)
+	foundID, err := sumaGetSystemIDInNetwork(api, sessioncookie, susemgr, hostname, network, verbose, opts...)
 	if err != nil {
-		return -1, err
+		return result, err
 	}
 
 	if foundID == 0 {
-		return -1, fmt.Errorf("did not find the system in SUSE Manager")
+		return result, fmt.Errorf("did not find the system in SUSE Manager")
 	}
+	result = SystemResult{SystemID: foundID, Hostname: hostname}
 
-	foundIP, err := sumaGetSystemIP(sessioncookie, susemgr, foundID, verbose)
+	foundIP, err := api.GetSystemIP(sessioncookie, susemgr, foundID, verbose, opts...)
 	if err != nil {
-		log.Printf("Could not get IP, errorcode: %v", err)
-		return -1, err
+		logErrorf("Could not get IP, errorcode: %v", err)
+		return result, err
 	}
 
 	if foundIP == "" {
-		return -1, fmt.Errorf("did not find the system ID %d in SUSE Manager", foundID)
+		return result, fmt.Errorf("did not find the system ID %d in SUSE Manager", foundID)
 	}
+	result.IP = foundIP
 
-	isValid := isSystemInNetwork(foundIP, network)
+	isValid := sumaSystemInNetwork(api, sessioncookie, susemgr, foundID, foundIP, network, verbose, opts...)
 
 	if !isValid {
-		return -1, fmt.Errorf("%s cannot be deleted, the system does not belong to the permitted network of the group", hostname)
+		return result, fmt.Errorf("%s cannot be deleted, the system does not belong to the permitted network of the group", hostname)
+	}
+
+	if err := sumaRequireUnlocked(api, sessioncookie, susemgr, foundID, hostname, verbose, opts...); err != nil {
+		return result, err
 	}
 
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgr, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaDeleteSystem: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI SumaDeleteSystem: apiURL =  %s", apiURL)
 	}
 
 	apiDeleteSystems := fmt.Sprintf("%s%s", apiURL, "/system/deleteSystem")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaDeleteSystem: apiMethod = %s\n", apiDeleteSystems)
+		logDebugf("SUMAAPI SumaDeleteSystem: apiMethod = %s", apiDeleteSystems)
 	}
 
-	// Create the authentication request payload
-	DeleteSystemPayload := DeleteSystemType{
-		ServerID:    foundID,
-		CleanupType: "FORCE_DELETE",
-	}
-
-	// Marshal the payload to JSON
-	payloadBytes, err := json.Marshal(DeleteSystemPayload)
-	if err != nil {
-		log.Printf("error marshalling payload: %v\n", err)
-		return -1, err
-	}
+	// Encode the payload into a pooled buffer
+	buf := getPayloadBuffer(64)
+	payloadBytes := appendDeleteSystem(*buf, foundID)
+	*buf = payloadBytes
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaDeleteSystem: Paylod =  %v\n", string(payloadBytes))
+		logDebugf("SUMAAPI SumaDeleteSystem: Paylod =  %v", string(payloadBytes))
 	}
 
 	// Create an HTTP POST request
-	req, err := http.NewRequest(http.MethodPost, apiDeleteSystems, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequest(http.MethodPost, apiDeleteSystems, bytes.NewReader(payloadBytes))
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
-		return -1, err
+		logErrorf("error creating request: %v", err)
+		return result, err
 	}
 
 	// Add headers
@@ -501,60 +720,68 @@ func SumaDeleteSystem(sessioncookie, susemgr, hostname, network string, verbose
 		Value: sessioncookie,
 	})
 
-	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// Send the request using the HTTP client, after an error the buffer is not reused
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("error sending request: %v\n", err)
-		return -1, err
+		logErrorf("error sending request: %v", err)
+		return result, err
 	}
+	defer putPayloadBuffer(buf)
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaDeleteSystem: Delete Node: %v\n", resp)
+		logDebugf("SUMAAPI SumaDeleteSystem: Delete Node: %v", resp)
 	}
 
+	result.StatusCode = resp.StatusCode
 	if resp.StatusCode != http.StatusOK {
-		return -1, fmt.Errorf("HTTP Request failed: HTTP/%d", resp.StatusCode)
+		return result, statusError(resp)
 	}
 
-	return resp.StatusCode, nil
+	result.Action = ResultDeleted
+	if verbose {
+		Logger().Debug("SUMAAPI SumaDeleteSystem: system deleted", "host", hostname, "system_id", foundID, "ip", foundIP)
+	}
+	return result, nil
 
 }
 
-var sumaRemoveSystemGroup = func(sessioncookie, susemgrurl, group string, verbose bool) (statuscode int, err error) {
+func sumaRemoveSystemGroup(api sumaAPI, sessioncookie, susemgrurl, group string, verbose bool, opts ...Option) (statuscode int, err error) {
 
 	type RemoveSystemGroup struct {
 		SystemGroupName string `json:"systemGroupName"`
 	}
 
 	if verbose {
-		log.Println("DEBUG SUMAAPI SumeRemoveSystemGroup: Enter function")
-		log.Println("DEBUG SUMAAPI SumeRemoveSystemGroup: ==============")
-		defer log.Println("DEBUG SUMAAPI SumeRemoveSystemGroup: Leave function")
+		logDebugf("SUMAAPI SumeRemoveSystemGroup: Enter function")
+		logDebugf("SUMAAPI SumeRemoveSystemGroup: ==============")
+		defer logDebugf("SUMAAPI SumeRemoveSystemGroup: Leave function")
 	}
 
-	checkSystemgroup := sumaCheckSystemGroup(sessioncookie, group, susemgrurl, verbose)
+	checkSystemgroup, err := sumaCheckSystemGroup(api, sessioncookie, group, susemgrurl, verbose, opts...)
+	if err != nil {
+		return -1, err
+	}
 
 	if !checkSystemgroup {
-		log.Printf("no systemgroup %s found.", group)
+		logInfof("no systemgroup %s found.", group)
 		return http.StatusOK, nil
 	}
 
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgrurl, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaRemoveSystemGroup: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI SumaRemoveSystemGroup: apiURL =  %s", apiURL)
 	}
 
 	apiRemoveSystemGroup := fmt.Sprintf("%s%s", apiURL, "/systemgroup/delete")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaRemoveSystemGroup: apiMethod = %s\n", apiRemoveSystemGroup)
+		logDebugf("SUMAAPI SumaRemoveSystemGroup: apiMethod = %s", apiRemoveSystemGroup)
 	}
 
 	// Create the authentication request payload
@@ -565,18 +792,18 @@ var sumaRemoveSystemGroup = func(sessioncookie, susemgrurl, group string, verbos
 	// Marshal the payload to JSON
 	payloadBytes, err := json.Marshal(RemoveSystemGroupPayload)
 	if err != nil {
-		log.Printf("error marshalling payload: %v\n", err)
+		logErrorf("error marshalling payload: %v", err)
 		return -1, err
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaRemoveSystemGroup: Paylod =  %v\n", string(payloadBytes))
+		logDebugf("SUMAAPI SumaRemoveSystemGroup: Paylod =  %v", string(payloadBytes))
 	}
 
 	// Create an HTTP POST request
 	req, err := http.NewRequest(http.MethodPost, apiRemoveSystemGroup, bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
+		logErrorf("error creating request: %v", err)
 		return -1, err
 	}
 
@@ -588,64 +815,79 @@ var sumaRemoveSystemGroup = func(sessioncookie, susemgrurl, group string, verbos
 	})
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("error sending request: %v\n", err)
+		logErrorf("error sending request: %v", err)
 		return -1, err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaRemoveSystemGroup: Response: %v\n", resp)
+		logDebugf("SUMAAPI SumaRemoveSystemGroup: Response: %v", resp)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return -1, fmt.Errorf("HTTP Request failed: HTTP/%d", resp.StatusCode)
+		return -1, statusError(resp)
 	}
 
 	return resp.StatusCode, nil
 
 }
 
-// refactor:
-// sumaCheckSystemGroup should return bool, error to eliminate os.Exit
-// after them write a test
+// sumaCheckSystemGroup reports if the system group exists
+func sumaCheckSystemGroup(api sumaAPI, sessioncookie, group, susemgrurl string, verbose bool, opts ...Option) (exists bool, err error) {
 
-var sumaCheckSystemGroup = func(sessioncookie, group, susemgrurl string, verbose bool) (exists bool) {
+	groups, err := api.ListSystemGroups(sessioncookie, susemgrurl, verbose, opts...)
+	if err != nil {
+		return false, fmt.Errorf("could not get all systemgroups: %w", err)
+	}
+
+	return slices.Contains(groups, group), nil
+}
+
+// sumaCheckUser reports if the user exists, the login is compared case-insensitively
+func sumaCheckUser(api sumaAPI, sessioncookie, group, susemgrurl string, verbose bool, opts ...Option) (exists bool, err error) {
 
-	type responseListAllGroups struct {
-		Result []struct {
-			Name string `json:"name"`
-		} `json:"result"`
+	users, err := api.ListUsers(sessioncookie, susemgrurl, verbose, opts...)
+	if err != nil {
+		return false, fmt.Errorf("could not get user list: %w", err)
+	}
+
+	return containsFold(users, group), nil
+}
+
+// sumaListSystemGroups returns the names of all system groups
+func sumaListSystemGroups(sessioncookie, susemgrurl string, verbose bool, opts ...Option) (groups []string, err error) {
+
+	type resultListAllGroups struct {
+		Name string `json:"name"`
 	}
 
 	if verbose {
-		log.Println("DEBUG SUMAAPI sumaCheckSystemGroup: Enter function")
-		log.Println("DEBUG SUMAAPI sumaCheckSystemGroup:===============")
-		defer log.Println("DEBUG SUMAAPI sumaCheckSystemGroup: Leave function")
+		logDebugf("SUMAAPI sumaListSystemGroups: Enter function")
+		logDebugf("SUMAAPI sumaListSystemGroups:===============")
+		defer logDebugf("SUMAAPI sumaListSystemGroups: Leave function")
 	}
 
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgrurl, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaCheckSystemGroup: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI sumaListSystemGroups: apiURL =  %s", apiURL)
 	}
 
 	apiListAllGroups := fmt.Sprintf("%s%s", apiURL, "/systemgroup/listAllGroups")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaCheckSystemGroup: apiMethod = %s\n", apiListAllGroups)
+		logDebugf("SUMAAPI sumaListSystemGroups: apiMethod = %s", apiListAllGroups)
 	}
 
 	req, err := http.NewRequest(http.MethodGet, apiListAllGroups, nil)
 	if err != nil {
-		log.Printf("error creating request to get all systemgroups, error: %s\n", err)
-		osExit(1)
+		return nil, fmt.Errorf("error creating request to get all systemgroups: %v", err)
 	}
 
 	// Add headers
@@ -656,86 +898,71 @@ var sumaCheckSystemGroup = func(sessioncookie, group, susemgrurl string, verbose
 	})
 
 	// Send the HTTP request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("error sending request: %s\n", err)
-		osExit(1)
+		return nil, fmt.Errorf("error sending request: %v", err)
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	// Check HTTP status
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("http request failed: HTTP %d\n", resp.StatusCode)
-		osExit(1)
+		return nil, statusError(resp)
 	}
 
 	// Read response body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("error reading http, got response: %s\n", err)
-		osExit(1)
+		return nil, fmt.Errorf("error reading http response: %v", err)
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaCheckSystemGroup: Got resp.Body = %s\n", string(bodyBytes))
+		logDebugf("SUMAAPI sumaListSystemGroups: Got resp.Body = %s", string(bodyBytes))
 	}
 
-	// Unmarshal the JSON response into the struct
-	var rsp responseListAllGroups
-	err = json.Unmarshal(bodyBytes, &rsp)
+	result, err := decodeSumaResponse[[]resultListAllGroups](resp, "systemgroup/listAllGroups", bodyBytes)
 	if err != nil {
-		log.Printf("error unmarshaling JSON: %s\n", err)
-		osExit(1)
+		return nil, err
 	}
 
-	for _, sg := range rsp.Result {
-		if verbose {
-			log.Printf("DEBUG SUMAAPI sumaCheckSystemGroup: SG in SUMA: %s\n", sg.Name)
-		}
-		if sg.Name == group {
-			return true
-		}
+	for _, sg := range result {
+		groups = append(groups, sg.Name)
 	}
 
-	return false
+	return groups, nil
 }
 
-var sumaCheckUser = func(sessioncookie, group, susemgrurl string, verbose bool) (exists bool) {
+// sumaListUsers returns the logins of all users
+func sumaListUsers(sessioncookie, susemgrurl string, verbose bool, opts ...Option) (users []string, err error) {
 
-	type responseUserListUsers struct {
-		Success bool `json:"success"`
-		Result  []struct {
-			Login string `json:"login"`
-		} `json:"result"`
+	type resultUserListUsers struct {
+		Login string `json:"login"`
 	}
 
 	if verbose {
-		log.Println("DEBUG SUMAAPI sumaCheckUser: Enter function sumaCheckUser")
-		log.Println("DEBUG SUMAAPI sumaCheckUser: ============================")
-		defer log.Println("DEBUG SUMAAPI sumaCheckUser: Leave function sumaCheckUser")
+		logDebugf("SUMAAPI sumaListUsers: Enter function sumaListUsers")
+		logDebugf("SUMAAPI sumaListUsers: ============================")
+		defer logDebugf("SUMAAPI sumaListUsers: Leave function sumaListUsers")
 	}
 
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgrurl, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaCheckUser: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI sumaListUsers: apiURL =  %s", apiURL)
 	}
 
 	apiUserListUsers := fmt.Sprintf("%s%s", apiURL, "/user/listUsers")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaCheckUser: apiMethod = %s\n", apiUserListUsers)
+		logDebugf("SUMAAPI sumaListUsers: apiMethod = %s", apiUserListUsers)
 	}
 
 	req, err := http.NewRequest(http.MethodGet, apiUserListUsers, nil)
 	if err != nil {
-		log.Printf("error creating request to get user list, error: %s\n", err)
-		osExit(1)
+		return nil, fmt.Errorf("error creating request to get user list: %v", err)
 	}
 
 	// Add headers
@@ -746,118 +973,115 @@ var sumaCheckUser = func(sessioncookie, group, susemgrurl string, verbose bool)
 	})
 
 	// Send the HTTP request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("error sending request: %s\n", err)
-		osExit(1)
+		return nil, fmt.Errorf("error sending request: %v", err)
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	// Check HTTP status
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("http request failed: HTTP %d\n", resp.StatusCode)
-		osExit(1)
+		return nil, statusError(resp)
 	}
 
 	// Read response body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("error reading http, got response: %s\n", err)
-		osExit(1)
+		return nil, fmt.Errorf("error reading http response: %v", err)
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI sumaCheckUser: Got resp.Body = %s\n", string(bodyBytes))
+		logDebugf("SUMAAPI sumaListUsers: Got resp.Body = %s", string(bodyBytes))
 	}
 
-	// Unmarshal the JSON response into the struct
-	var rsp responseUserListUsers
-	err = json.Unmarshal(bodyBytes, &rsp)
+	result, err := decodeSumaResponse[[]resultUserListUsers](resp, "user/listUsers", bodyBytes)
 	if err != nil {
-		log.Printf("error unmarshaling JSON: %s\n", err)
-		osExit(1)
+		return nil, err
 	}
 
-	for _, user := range rsp.Result {
-		if verbose {
-			log.Printf("DEBUG SUMAAPI sumaCheckUser: User in SUMA: %s\n", user.Login)
-		}
-		if user.Login == group {
-			return true
-		}
+	for _, user := range result {
+		users = append(users, user.Login)
 	}
 
-	return false
+	return users, nil
 }
 
-// SumaAddUser add a user to the suse manager.
-var SumaAddUser = func(sessioncookie, group, grouppassword, susemgrurl string, verbose bool) (statuscode int, err error) {
-
-	type AddUser struct {
-		Login     string `json:"login"`
-		Password  string `json:"password"`
-		FirstName string `json:"firstName"`
-		LastName  string `json:"lastName"`
-		Email     string `json:"email"`
+// SumaAddUser add a user to the suse manager. It returns the HTTP status, see SumaAddUserResult.
+func SumaAddUser(sessioncookie, group, grouppassword, susemgrurl string, verbose bool, opts ...Option) (statuscode int, err error) {
+	result, err := SumaAddUserResult(sessioncookie, group, grouppassword, susemgrurl, verbose, opts...)
+	if err != nil && result.StatusCode == 0 {
+		return 1, err
 	}
+	return result.StatusCode, err
+}
+
+// SumaAddUserResult add a user to the suse manager, the action of the result is ResultUnchanged if
+// the user already exists.
+func SumaAddUserResult(sessioncookie, group, grouppassword, susemgrurl string, verbose bool, opts ...Option) (result UserResult, err error) {
+	return sumaAddUserResult(sumaHTTP{}, sessioncookie, group, grouppassword, susemgrurl, verbose, opts...)
+}
+
+func sumaAddUserResult(api sumaAPI, sessioncookie, group, grouppassword, susemgrurl string, verbose bool, opts ...Option) (result UserResult, err error) {
 
 	if verbose {
-		log.Println("DEBUG SUMAAPI SumaAddUser: Enter function")
-		log.Println("DEBUG SUMAAPI SumaAddUser: ==============")
-		defer log.Println("DEBUG SUMAAPI SumaAddUser: Leave function")
+		logDebugf("SUMAAPI SumaAddUser: Enter function")
+		logDebugf("SUMAAPI SumaAddUser: ==============")
+		defer logDebugf("SUMAAPI SumaAddUser: Leave function")
+	}
+
+	result.Login = group
+
+	// Create the request payload, it is validated before any request is sent
+	AddUserPayload, err := NewSumaApiAddUser(group, grouppassword, "", "", "")
+	if err != nil {
+		return result, err
 	}
 
 	//check if user exists
-	ok := sumaCheckUser(sessioncookie, group, susemgrurl, verbose)
+	ok, err := sumaCheckUser(api, sessioncookie, group, susemgrurl, verbose, opts...)
+	if err != nil {
+		return result, err
+	}
 
 	if ok {
-		log.Printf("user %s already exists in SUMA.\n", group)
-		return http.StatusOK, nil
+		logInfof("user %s already exists in SUMA.", group)
+		result.Action, result.StatusCode = ResultUnchanged, http.StatusOK
+		return result, nil
 	}
 
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgrurl, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaAddUser: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI SumaAddUser: apiURL =  %s", apiURL)
 	}
 
 	apiUserCreate := fmt.Sprintf("%s%s", apiURL, "/user/create")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaAddUser: apiMethod = %s\n", apiUserCreate)
-	}
-
-	// Create the authentication request payload
-	AddUserPayload := AddUser{
-		Login:     group,
-		Password:  grouppassword,
-		FirstName: group,
-		LastName:  group,
-		Email:     "root@localhost",
+		logDebugf("SUMAAPI SumaAddUser: apiMethod = %s", apiUserCreate)
 	}
 
 	// Marshal the payload to JSON
 	payloadBytes, err := json.Marshal(AddUserPayload)
 	if err != nil {
-		log.Printf("error marshalling payload: %v\n", err)
-		return 1, err
+		logErrorf("error marshalling payload: %v", err)
+		return result, err
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaAddUser: Payload =  %v\n", string(payloadBytes))
+		logDebugf("SUMAAPI SumaAddUser: Payload =  %v", string(payloadBytes))
 	}
 
 	// Create an HTTP POST request
 	req, err := http.NewRequest(http.MethodPost, apiUserCreate, bytes.NewBuffer(payloadBytes))
 
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
-		return 1, err
+		logErrorf("error creating request: %v", err)
+		return result, err
 	}
 
 	// Add headers
@@ -868,76 +1092,79 @@ var SumaAddUser = func(sessioncookie, group, grouppassword, susemgrurl string, v
 	})
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		log.Printf("error sending request: %v\n", err)
-		return 1, err
+		logErrorf("error sending request: %v", err)
+		return result, err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
+	result.StatusCode = resp.StatusCode
 	if resp.StatusCode != http.StatusOK {
 		if verbose {
-			log.Printf("DEBUG SUMAAPI SumaAddUser: http request failed: HTTP %d\n", resp.StatusCode)
+			logDebugf("SUMAAPI SumaAddUser: http request failed: HTTP %d", resp.StatusCode)
 		}
-		return resp.StatusCode, fmt.Errorf("HTTP Request failed: HTTP/%d", resp.StatusCode)
+		return result, statusError(resp)
 
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaAddUser: Add User: %v\n", resp)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("HTTP Request failed: HTTP %d\n", resp.StatusCode)
-		return 1, err
+		logDebugf("SUMAAPI SumaAddUser: Add User: %v", resp)
 	}
 
-	return resp.StatusCode, nil
+	result.Action = ResultCreated
+	return result, nil
 }
 
 // SumaRemoveUser delete a user from the suse manager
-func SumaRemoveUser(sessioncookie, group, susemgrurl string, verbose bool) (err error) {
+func SumaRemoveUser(sessioncookie, group, susemgrurl string, verbose bool, opts ...Option) (err error) {
+	return sumaRemoveUser(sumaHTTP{}, sessioncookie, group, susemgrurl, verbose, opts...)
+}
+
+func sumaRemoveUser(api sumaAPI, sessioncookie, group, susemgrurl string, verbose bool, opts ...Option) (err error) {
 
 	type RemoveUser struct {
 		Login string `json:"login"`
 	}
 
 	if verbose {
-		log.Println("DEBUG SUMAAPI SumaRemoveUser: Enter function")
-		log.Println("DEBUG SUMAAPI SumaRemoveUser: ==============")
-		defer log.Println("DEBUG SUMAAPI SumaRemoveUser: Leave function")
-		log.Printf("DEBUG SUMAAPI SumaRemoveUser: sessioncookie: %s\n", sessioncookie)
+		logDebugf("SUMAAPI SumaRemoveUser: Enter function")
+		logDebugf("SUMAAPI SumaRemoveUser: ==============")
+		defer logDebugf("SUMAAPI SumaRemoveUser: Leave function")
+		logDebugf("SUMAAPI SumaRemoveUser: sessioncookie: %s", sessioncookie)
 	}
 
-	_, err = sumaRemoveSystemGroup(sessioncookie, susemgrurl, group, verbose)
+	_, err = sumaRemoveSystemGroup(api, sessioncookie, susemgrurl, group, verbose, opts...)
 	if err != nil {
-		log.Printf("could not remove system group %s. Got %v\n", group, err)
+		logErrorf("could not remove system group %s. Got %v", group, err)
 		return err
 	}
 
 	//check if user exists
-	ok := sumaCheckUser(sessioncookie, group, susemgrurl, verbose)
+	ok, err := sumaCheckUser(api, sessioncookie, group, susemgrurl, verbose, opts...)
+	if err != nil {
+		return err
+	}
 
 	if !ok {
-		log.Printf("user %s already removed in SUMA.\n", group)
+		logInfof("user %s already removed in SUMA.", group)
 		return nil
 	}
 
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgrurl, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaRemoveUser: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI SumaRemoveUser: apiURL =  %s", apiURL)
 	}
 
 	apiUserRemove := fmt.Sprintf("%s%s", apiURL, "/user/delete")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaRemoveUser: apiMethod = %s\n", apiUserRemove)
+		logDebugf("SUMAAPI SumaRemoveUser: apiMethod = %s", apiUserRemove)
 	}
 
 	// Create the authentication request payload
@@ -948,19 +1175,19 @@ func SumaRemoveUser(sessioncookie, group, susemgrurl string, verbose bool) (err
 	// Marshal the payload to JSON
 	payloadBytes, err := json.Marshal(RemoveUserPayload)
 	if err != nil {
-		log.Printf("error marshalling payload: %v\n", err)
+		logErrorf("error marshalling payload: %v", err)
 		return err
 	}
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI SumaRemoveUser: Payload =  %v\n", string(payloadBytes))
+		logDebugf("SUMAAPI SumaRemoveUser: Payload =  %v", string(payloadBytes))
 	}
 
 	// Create an HTTP POST request
 	req, err := http.NewRequest(http.MethodPost, apiUserRemove, bytes.NewBuffer(payloadBytes))
 
 	if err != nil {
-		log.Printf("error creating request: %v\n", err)
+		logErrorf("error creating request: %v", err)
 		return err
 	}
 
@@ -972,21 +1199,20 @@ func SumaRemoveUser(sessioncookie, group, susemgrurl string, verbose bool) (err
 	})
 
 	// Send the request using the HTTP client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("error closing response body: %v\n", err)
+			logErrorf("error closing response body: %v", err)
 		}
 	}()
 
 	if verbose {
-		log.Printf("DEBUG SUMAAPI: SumaRemoveUser: %v\n", resp)
+		logDebugf("SUMAAPI: SumaRemoveUser: %v", resp)
 	}
 
 	if err != nil {
-		log.Printf("error sending request: %v\n", err)
+		logErrorf("error sending request: %v", err)
 		return err
 	}
 
@@ -997,8 +1223,8 @@ func SumaRemoveUser(sessioncookie, group, susemgrurl string, verbose bool) (err
 	return nil
 }
 
-// GetAPIList is a helper function to get the API List from SUMA API
-func GetAPIList(sessioncookie, susemgr string, verbose bool) {
+// GetAPIList is a helper function to print the API List of the SUMA API
+func GetAPIList(sessioncookie, susemgr string, verbose bool, opts ...Option) error {
 	type ResponseGetAPICallList struct {
 		Name        string `json:"name"`
 		Parameters  string `json:"parameters"`
@@ -1006,24 +1232,23 @@ func GetAPIList(sessioncookie, susemgr string, verbose bool) {
 		ReturnValue string `json:"return"`
 	}
 
-	log.Printf("DEBUG SUMAAPI GetApiList: sessioncookie =  %s\n", sessioncookie)
+	logDebugf("SUMAAPI GetApiList: sessioncookie =  %s", sessioncookie)
 
 	// Define the API endpoint
 	apiURL := fmt.Sprintf("%s%s", susemgr, "/rhn/manager/api")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI GetApiList: apiURL =  %s\n", apiURL)
+		logDebugf("SUMAAPI GetApiList: apiURL =  %s", apiURL)
 	}
 
 	apiAPICallList := fmt.Sprintf("%s%s", apiURL, "/api/getApiCallList")
 	if verbose {
-		log.Printf("DEBUG SUMAAPI GetApiList: apiMethod = %s\n", apiAPICallList)
+		logDebugf("SUMAAPI GetApiList: apiMethod = %s", apiAPICallList)
 	}
 
 	// Create a new HTTP request
 	req, err := http.NewRequest(http.MethodGet, apiAPICallList, nil)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating request, error: %s\n", err)
-		osExit(1)
+		return fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Add headers
@@ -1034,42 +1259,375 @@ func GetAPIList(sessioncookie, susemgr string, verbose bool) {
 	})
 
 	// Send the HTTP request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := newCallOptions(verbose, opts).do(req)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending request: %s\n", err)
-		osExit(1)
+		return fmt.Errorf("error sending request: %w", err)
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Fprintln(os.Stderr, "Error closing response body:", err)
+			logErrorf("Error closing response body: %v", err)
 		}
 	}()
 
 	// Check HTTP status
 	if resp.StatusCode != http.StatusOK {
-		fmt.Fprintf(os.Stderr, "HTTP Request failed: HTTP %d\n", resp.StatusCode)
-		osExit(1)
+		return statusError(resp)
 	}
 
 	// Read response body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading http response: %s\n", err)
-		osExit(1)
+		return withRequestID(resp, fmt.Errorf("error reading http response: %w", err))
 	}
 
 	if verbose {
-		fmt.Fprintf(os.Stderr, "DEBUG: Got resp.Body = %s\n", string(bodyBytes))
+		logDebugf("Got resp.Body = %s", string(bodyBytes))
 	}
 	// Unmarshal the JSON response into the struct
 	var rsp ResponseGetAPICallList
 	err = json.Unmarshal(bodyBytes, &rsp)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error unmarshaling JSON: %s\n", err)
-		osExit(1)
+		return withRequestID(resp, fmt.Errorf("error unmarshaling JSON: %w", err))
 	}
 
 	fmt.Printf("%v", rsp)
+	return nil
+}
+
+// SystemInfo hold the id and name of a system registered in SUSE Manager
+type SystemInfo struct {
+	ID   int
+	Name string
+}
+
+func sumaListGroupSystems(sessioncookie, susemgr, group string, verbose bool, opts ...Option) (systems []SystemInfo, err error) {
+
+	type ResultListSystems struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	if verbose {
+		logDebugf("SUMAAPI sumaListGroupSystems: Enter function")
+		logDebugf("SUMAAPI sumaListGroupSystems: ==============")
+		defer logDebugf("SUMAAPI sumaListGroupSystems: Leave function")
+	}
+
+	// Define the API endpoint
+	apiURL := fmt.Sprintf("%s%s", susemgr, "/rhn/manager/api")
+	if verbose {
+		logDebugf("SUMAAPI sumaListGroupSystems: apiURL =  %s", apiURL)
+	}
+
+	apiListSystemsMinimal := fmt.Sprintf("%s%s%s", apiURL, "/systemgroup/listSystemsMinimal?systemGroupName=", url.QueryEscape(group))
+	if verbose {
+		logDebugf("SUMAAPI sumaListGroupSystems: apiMethod = %s", apiListSystemsMinimal)
+	}
+
+	// Create a new HTTP request
+	req, err := http.NewRequest(http.MethodGet, apiListSystemsMinimal, nil)
+	if err != nil {
+		logErrorf("error creating request to list systems of group, error: %s", err)
+		return nil, err
+	}
+
+	// Add headers
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "pxt-session-cookie",
+		Value: sessioncookie,
+	})
+
+	// Send the HTTP request
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		logErrorf("error sending request: %s", err)
+		return nil, err
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	// Check HTTP status
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	// Read response body
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logErrorf("error reading http response: %s", err)
+		return nil, err
+	}
+
+	if verbose {
+		logDebugf("SUMAAPI sumaListGroupSystems: Got resp.Body = %s", string(bodyBytes))
+	}
+
+	result, err := decodeSumaResponse[[]ResultListSystems](resp, "systemgroup/listSystemsMinimal", bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("listing systems of group %s: %w", group, err)
+	}
+
+	for _, r := range result {
+		systems = append(systems, SystemInfo{ID: r.ID, Name: r.Name})
+	}
+
+	return systems, nil
+}
+
+func sumaAddOrRemoveSystems(sessioncookie, susemgr, group string, ids []int, add bool, verbose bool, opts ...Option) (err error) {
+
+	if verbose {
+		logDebugf("SUMAAPI sumaAddOrRemoveSystems: Enter function")
+		logDebugf("SUMAAPI sumaAddOrRemoveSystems: ==============")
+		defer logDebugf("SUMAAPI sumaAddOrRemoveSystems: Leave function")
+	}
+
+	// Define the API endpoint
+	apiURL := fmt.Sprintf("%s%s", susemgr, "/rhn/manager/api")
+	apiMethodAddOrRemoveSystems := fmt.Sprintf("%s%s", apiURL, "/systemgroup/addOrRemoveSystems")
+	if verbose {
+		logDebugf("SUMAAPI sumaAddOrRemoveSystems: apiMethod = %s", apiMethodAddOrRemoveSystems)
+	}
+
+	payload, err := NewSumaApiAddRemoveSystem(group, ids, add)
+	if err != nil {
+		return err
+	}
+
+	// Encode the payload into a pooled buffer, a reconciliation sends it for thousands of systems
+	buf := getPayloadBuffer(payload.encodedSize())
+	payloadBytes := payload.AppendJSON(*buf)
+	*buf = payloadBytes
+
+	if verbose {
+		logDebugf("SUMAAPI sumaAddOrRemoveSystems: Payload =  %v", string(payloadBytes))
+	}
+
+	// Create an HTTP POST request
+	req, err := http.NewRequest(http.MethodPost, apiMethodAddOrRemoveSystems, bytes.NewReader(payloadBytes))
+	if err != nil {
+		logErrorf("error creating request: %v", err)
+		return err
+	}
+
+	// Add headers
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "pxt-session-cookie",
+		Value: sessioncookie,
+	})
+
+	// Send the request using the HTTP client. After an error the buffer is not reused, the transport
+	// may still read it.
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		logErrorf("error sending request: %v", err)
+		return err
+	}
+	defer putPayloadBuffer(buf)
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	return nil
+}
+
+// sumaCreateSystemGroup creates a system group if it does not exist
+func sumaCreateSystemGroup(api sumaAPI, sessioncookie, susemgrurl, group, description string, verbose bool, opts ...Option) (err error) {
+
+	type CreateSystemGroup struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	if verbose {
+		logDebugf("SUMAAPI sumaCreateSystemGroup: Enter function")
+		logDebugf("SUMAAPI sumaCreateSystemGroup: ==============")
+		defer logDebugf("SUMAAPI sumaCreateSystemGroup: Leave function")
+	}
+
+	exists, err := sumaCheckSystemGroup(api, sessioncookie, group, susemgrurl, verbose, opts...)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if verbose {
+			logDebugf("SUMAAPI sumaCreateSystemGroup: systemgroup %s already exists", group)
+		}
+		return nil
+	}
+
+	// Define the API endpoint
+	apiURL := fmt.Sprintf("%s%s", susemgrurl, "/rhn/manager/api")
+	apiCreateSystemGroup := fmt.Sprintf("%s%s", apiURL, "/systemgroup/create")
+	if verbose {
+		logDebugf("SUMAAPI sumaCreateSystemGroup: apiMethod = %s", apiCreateSystemGroup)
+	}
+
+	payloadBytes, err := json.Marshal(CreateSystemGroup{Name: group, Description: description})
+	if err != nil {
+		logErrorf("error marshalling payload: %v", err)
+		return err
+	}
+
+	// Create an HTTP POST request
+	req, err := http.NewRequest(http.MethodPost, apiCreateSystemGroup, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		logErrorf("error creating request: %v", err)
+		return err
+	}
+
+	// Add headers
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "pxt-session-cookie",
+		Value: sessioncookie,
+	})
+
+	// Send the request using the HTTP client
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		logErrorf("error sending request: %v", err)
+		return err
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	return nil
+}
+
+// sumaResponse is the envelope of the SUMA API responses
+type sumaResponse[T any] struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Result  T      `json:"result"`
+}
+
+// decodeSumaResponse unmarshals the body of a SUMA response, checks its success and returns the
+// result. The errors name the API method and carry the request ID of resp.
+func decodeSumaResponse[T any](resp *http.Response, method string, body []byte) (result T, err error) {
+	var rsp sumaResponse[T]
+	if err := json.Unmarshal(body, &rsp); err != nil {
+		return result, withRequestID(resp, fmt.Errorf("%s: error unmarshaling JSON: %v", method, err))
+	}
+	if !rsp.Success {
+		return result, withRequestID(resp, fmt.Errorf("%s failed: %s", method, rsp.Message))
+	}
+	return rsp.Result, nil
+}
+
+// sumaGet calls a read-only API method, e.g. "schedule/listAllActions", and unmarshals the result
+// of the response into result
+func sumaGet(sessioncookie, susemgr, method string, query url.Values, result any, verbose bool, opts ...Option) (err error) {
+	if len(query) > 0 {
+		method += "?" + query.Encode()
+	}
+	return sumaCall(sessioncookie, susemgr, http.MethodGet, method, nil, result, verbose, opts...)
+}
+
+// sumaPost calls an API method with the payload as JSON body and unmarshals the result of the
+// response into result
+func sumaPost(sessioncookie, susemgr, method string, payload, result any, verbose bool, opts ...Option) (err error) {
+	return sumaCall(sessioncookie, susemgr, http.MethodPost, method, payload, result, verbose, opts...)
+}
+
+func sumaCall(sessioncookie, susemgr, httpMethod, method string, payload, result any, verbose bool, opts ...Option) (err error) {
+
+	// Define the API endpoint
+	apiMethod := fmt.Sprintf("%s%s/%s", susemgr, "/rhn/manager/api", method)
+	if verbose {
+		logDebugf("SUMAAPI sumaCall: %s apiMethod = %s", httpMethod, apiMethod)
+	}
+
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error marshalling payload: %v", err)
+		}
+		if verbose {
+			logDebugf("SUMAAPI sumaCall: Payload =  %s", string(payloadBytes))
+		}
+		body = bytes.NewBuffer(payloadBytes)
+	}
+
+	req, err := http.NewRequest(httpMethod, apiMethod, body)
+	if err != nil {
+		return fmt.Errorf("error creating request for %s: %v", method, err)
+	}
+
+	// Add headers
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "pxt-session-cookie",
+		Value: sessioncookie,
+	})
+
+	// Send the HTTP request
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %v", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	// Check HTTP status
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	// Read response body
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading http response: %v", err)
+	}
+
+	if verbose {
+		logDebugf("SUMAAPI sumaCall: Got resp.Body = %s", string(bodyBytes))
+	}
+
+	raw, err := decodeSumaResponse[json.RawMessage](resp, strings.SplitN(method, "?", 2)[0], bodyBytes)
+	if err != nil {
+		return err
+	}
+
+	if result == nil || len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, result)
+}
+
+// sumaGetSystemIP6 returns the IPv6 address of a system, empty if it has none
+func sumaGetSystemIP6(sessioncookie, susemgr string, id int, verbose bool, opts ...Option) (foundIP string, err error) {
+	var network struct {
+		IP6 string `json:"ip6"`
+	}
+	query := url.Values{"sid": {strconv.Itoa(id)}}
+	err = sumaGet(sessioncookie, susemgr, "system/getNetwork", query, &network, verbose, opts...)
+	return network.IP6, err
 }
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -65,13 +66,102 @@ func TestIsSystemInNetwork(t *testing.T) {
 			network: "2001:db8::",
 			want:    false,
 		},
+		{
+			name:    "IP in /23 network",
+			ip:      "192.168.1.10",
+			network: "192.168.0.0/23",
+			want:    true,
+		},
+		{
+			name:    "IP not in /23 network",
+			ip:      "192.168.2.10",
+			network: "192.168.0.0/23",
+			want:    false,
+		},
+		{
+			name:    "IP in /32 network",
+			ip:      "10.0.0.1",
+			network: "10.0.0.1/32",
+			want:    true,
+		},
+		{
+			name:    "network with host bits",
+			ip:      "192.168.1.10",
+			network: "192.168.1.5/24",
+			want:    true,
+		},
+		{
+			name:    "invalid prefix length",
+			ip:      "192.168.1.10",
+			network: "192.168.1.0/33",
+			want:    false,
+		},
+		{
+			name:    "IPv6 in IPv6 network",
+			ip:      "2001:db8::1",
+			network: "2001:db8::/64",
+			want:    true,
+		},
+		{
+			name:    "IPv6 not in IPv6 network",
+			ip:      "2001:db8:0:1::1",
+			network: "2001:db8::/64",
+			want:    false,
+		},
+		{
+			name:    "IPv6 in IPv6 network without prefix length",
+			ip:      "2001:db8::1",
+			network: "2001:db8::",
+			want:    true,
+		},
+		{
+			name:    "IPv6 with zone",
+			ip:      "fe80::1%eth0",
+			network: "fe80::/10",
+			want:    true,
+		},
+		{
+			name:    "IPv4-mapped IPv6 in IPv4 network",
+			ip:      "::ffff:192.168.1.10",
+			network: "192.168.1.0/24",
+			want:    true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isSystemInNetwork(tt.ip, tt.network)
+			got := IsSystemInNetwork(tt.ip, tt.network)
 			if got != tt.want {
-				t.Errorf("isSystemInNetwork(%q, %q) = %v; want %v", tt.ip, tt.network, got, tt.want)
+				t.Errorf("IsSystemInNetwork(%q, %q) = %v; want %v", tt.ip, tt.network, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSumaSystemInNetwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/system/getNetwork" || r.URL.Query().Get("sid") != "1" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		fmt.Fprint(w, `{"success": true, "result": {"ip": "192.168.1.10", "ip6": "2001:db8::10"}}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		ip      string
+		network string
+		want    bool
+	}{
+		{name: "IPv4 network", ip: "192.168.1.10", network: "192.168.1.0/24", want: true},
+		{name: "dual stack in IPv6 network", ip: "192.168.1.10", network: "2001:db8::/64", want: true},
+		{name: "dual stack outside IPv6 network", ip: "192.168.1.10", network: "2001:db8:1::/64", want: false},
+		{name: "IPv4 outside IPv4 network", ip: "192.168.1.10", network: "10.0.0.0/8", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sumaSystemInNetwork(sumaHTTP{}, "cookie", server.URL, 1, tt.ip, tt.network, false); got != tt.want {
+				t.Errorf("sumaSystemInNetwork(%q, %q) = %v, want %v", tt.ip, tt.network, got, tt.want)
 			}
 		})
 	}
@@ -93,15 +183,11 @@ func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 func TestSumaGetSystemID(t *testing.T) {
-	// Save and restore osExit to avoid exiting tests
-	origOsExit := osExit
-	defer func() { osExit = origOsExit }()
-	osExit = func(code int) {}
-
 	tests := []struct {
 		name           string
 		responseBody   string
 		responseStatus int
+		policy         SystemMatchPolicy
 		wantID         int
 		wantErr        bool
 	}{
@@ -125,6 +211,36 @@ func TestSumaGetSystemID(t *testing.T) {
 			wantID:         -1,
 			wantErr:        true,
 		},
+		{
+			name: "multiple systems - error",
+			responseBody: `{
+				"success": true,
+				"result": [
+					{"id": 41, "name": "testhost", "last_checkin": "2024-05-02T10:00:00Z"},
+					{"id": 42, "name": "testhost", "last_checkin": "2024-06-01T08:30:00Z"},
+					{"id": 43, "name": "testhost", "last_checkin": "2024-01-15T12:00:00Z"}
+				]
+			}`,
+			responseStatus: http.StatusOK,
+			policy:         MatchError,
+			wantID:         -1,
+			wantErr:        true,
+		},
+		{
+			name: "multiple systems - latest checkin",
+			responseBody: `{
+				"success": true,
+				"result": [
+					{"id": 41, "name": "testhost", "last_checkin": "2024-05-02T10:00:00Z"},
+					{"id": 42, "name": "testhost", "last_checkin": "2024-06-01T08:30:00Z"},
+					{"id": 43, "name": "testhost", "last_checkin": "2024-01-15T12:00:00Z"}
+				]
+			}`,
+			responseStatus: http.StatusOK,
+			policy:         MatchLatestCheckin,
+			wantID:         42,
+			wantErr:        false,
+		},
 		{
 			name:           "http error",
 			responseBody:   `error`,
@@ -157,7 +273,12 @@ func TestSumaGetSystemID(t *testing.T) {
 			hostname := "testhost"
 			verbose := false
 
-			id, err := sumaGetSystemID(sessioncookie, susemgr, hostname, verbose)
+			var opts []Option
+			if tt.policy != "" {
+				opts = append(opts, WithSystemMatch(tt.policy))
+			}
+
+			id, err := sumaGetSystemID(sessioncookie, susemgr, hostname, verbose, opts...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("sumaGetSystemID() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -168,13 +289,72 @@ func TestSumaGetSystemID(t *testing.T) {
 	}
 }
 
-// Additional test: error creating request
-func TestSumaGetSystemID_RequestError(t *testing.T) {
-	// Save and restore osExit to avoid exiting tests
-	origOsExit := osExit
-	defer func() { osExit = origOsExit }()
-	osExit = func(code int) {}
+func TestAmbiguousSystemError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"success": true, "result": [
+			{"id": 41, "name": "testhost", "last_checkin": "2024-05-02T10:00:00Z"},
+			{"id": 42, "name": "testhost"}
+		]}`)
+	}))
+	defer server.Close()
 
+	_, err := sumaGetSystemID("dummy", server.URL, "testhost", false)
+	var ambiguous *AmbiguousSystemError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected AmbiguousSystemError, got %v", err)
+	}
+	if len(ambiguous.Candidates) != 2 || ambiguous.Candidates[0].ID != 41 || ambiguous.Candidates[1].ID != 42 {
+		t.Errorf("unexpected candidates %+v", ambiguous.Candidates)
+	}
+	want := "hostname testhost matches 2 systems: 41 (testhost, last checkin 2024-05-02T10:00:00Z), 42 (testhost)"
+	if err.Error() != want {
+		t.Errorf("got error %q, want %q", err.Error(), want)
+	}
+}
+
+func TestSumaGetSystemIDInNetwork(t *testing.T) {
+	ips := map[int]string{41: "10.0.1.5", 42: "10.0.2.5", 43: "10.0.2.6"}
+	candidates := []SystemCandidate{{ID: 41, Name: "testhost"}, {ID: 42, Name: "testhost"}}
+	api := fakeSumaAPI{
+		systemID: func(hostname string) (int, error) {
+			return -1, &AmbiguousSystemError{Hostname: hostname, Candidates: candidates}
+		},
+		systemIP: func(id int) (string, error) { return ips[id], nil },
+	}
+
+	tests := []struct {
+		name       string
+		policy     SystemMatchPolicy
+		candidates []SystemCandidate
+		network    string
+		wantID     int
+		wantErr    bool
+	}{
+		{name: "one in network", policy: MatchNetwork, candidates: candidates, network: "10.0.2.0/24", wantID: 42},
+		{name: "policy error", policy: MatchError, candidates: candidates, network: "10.0.2.0/24", wantID: -1, wantErr: true},
+		{name: "none in network", policy: MatchNetwork, candidates: candidates, network: "10.0.3.0/24", wantID: -1, wantErr: true},
+		{name: "several in network", policy: MatchNetwork, candidates: append(candidates, SystemCandidate{ID: 43, Name: "testhost"}), network: "10.0.2.0/24", wantID: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates = tt.candidates
+			id, err := sumaGetSystemIDInNetwork(api, "dummy", "http://suma", "testhost", tt.network, false, WithSystemMatch(tt.policy))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sumaGetSystemIDInNetwork() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ambiguous *AmbiguousSystemError
+			if err != nil && !errors.As(err, &ambiguous) {
+				t.Errorf("expected AmbiguousSystemError, got %v", err)
+			}
+			if id != tt.wantID {
+				t.Errorf("sumaGetSystemIDInNetwork() id = %v, want %v", id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestSumaGetSystemID_RequestError(t *testing.T) {
 	// Intentionally pass an invalid URL to cause NewRequest to fail
 	sessioncookie := "dummy"
 	susemgr := "http://[::1]:namedport" // invalid URL
@@ -265,213 +445,186 @@ func TestSumaLogin_HTTPError(t *testing.T) {
 
 //------------------------------------------------------------
 
-// Save and restore original dependency functions
-func withMockedDeps(
-	mockGetSystemID func(string, string, string, bool) (int, error),
-	mockGetSystemIP func(string, string, int, bool) (string, error),
-	mockIsSystemInNetwork func(string, string) bool,
-	testFunc func(),
-) {
-	origGetSystemID := sumaGetSystemID
-	origGetSystemIP := sumaGetSystemIP
-	origIsSystemInNetwork := isSystemInNetwork
-	sumaGetSystemID = mockGetSystemID
-	sumaGetSystemIP = mockGetSystemIP
-	isSystemInNetwork = mockIsSystemInNetwork
-	defer func() {
-		sumaGetSystemID = origGetSystemID
-		sumaGetSystemIP = origGetSystemIP
-		isSystemInNetwork = origIsSystemInNetwork
-	}()
-	testFunc()
+// fakeSumaAPI answers the SUMA calls whose function is set, the other calls are sent to the server
+type fakeSumaAPI struct {
+	sumaHTTP
+	systemID     func(hostname string) (int, error)
+	systemIP     func(id int) (string, error)
+	lockStatus   func(id int) (bool, error)
+	systemGroups func() ([]string, error)
+	users        func() ([]string, error)
+}
+
+func (f fakeSumaAPI) GetSystemID(sessioncookie, susemgr, hostname string, verbose bool, opts ...Option) (int, error) {
+	if f.systemID == nil {
+		return f.sumaHTTP.GetSystemID(sessioncookie, susemgr, hostname, verbose, opts...)
+	}
+	return f.systemID(hostname)
+}
+
+func (f fakeSumaAPI) GetSystemIP(sessioncookie, susemgr string, id int, verbose bool, opts ...Option) (string, error) {
+	if f.systemIP == nil {
+		return f.sumaHTTP.GetSystemIP(sessioncookie, susemgr, id, verbose, opts...)
+	}
+	return f.systemIP(id)
+}
+
+func (f fakeSumaAPI) GetLockStatus(sessioncookie, susemgr string, systemID int, verbose bool, opts ...Option) (bool, error) {
+	if f.lockStatus == nil {
+		return f.sumaHTTP.GetLockStatus(sessioncookie, susemgr, systemID, verbose, opts...)
+	}
+	return f.lockStatus(systemID)
+}
+
+func (f fakeSumaAPI) ListSystemGroups(sessioncookie, susemgr string, verbose bool, opts ...Option) ([]string, error) {
+	if f.systemGroups == nil {
+		return f.sumaHTTP.ListSystemGroups(sessioncookie, susemgr, verbose, opts...)
+	}
+	return f.systemGroups()
+}
+
+func (f fakeSumaAPI) ListUsers(sessioncookie, susemgr string, verbose bool, opts ...Option) ([]string, error) {
+	if f.users == nil {
+		return f.sumaHTTP.ListUsers(sessioncookie, susemgr, verbose, opts...)
+	}
+	return f.users()
+}
+
+// fakeSystem resolves every hostname to the unlocked system 42 with the IP
+func fakeSystem(ip string) fakeSumaAPI {
+	return fakeSumaAPI{
+		systemID:   func(string) (int, error) { return 42, nil },
+		systemIP:   func(int) (string, error) { return ip, nil },
+		lockStatus: func(int) (bool, error) { return false, nil },
+	}
+}
+
+// fakeList returns a list call answering names
+func fakeList(names ...string) func() ([]string, error) {
+	return func() ([]string, error) { return names, nil }
 }
 
 func TestSumaAddSystem_Success(t *testing.T) {
-	withMockedDeps(
-		func(sessioncookie, susemgr, hostname string, verbose bool) (int, error) {
-			return 42, nil
-		},
-		func(sessioncookie, susemgr string, id int, verbose bool) (string, error) {
-			return "192.168.1.10", nil
-		},
-		func(ip, network string) bool {
-			return true
-		},
-		func() {
-			// Mock HTTP server for the final addOrRemoveSystems call
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if r.URL.Path != "/rhn/manager/api/systemgroup/addOrRemoveSystems" {
-					t.Errorf("unexpected path: %s", r.URL.Path)
-				}
-				var payload map[string]interface{}
-				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-					t.Errorf("could not decode payload: %v", err)
-				}
-				w.WriteHeader(http.StatusOK)
-			}))
-			defer server.Close()
+	// Mock HTTP server for the final addOrRemoveSystems call
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/systemgroup/addOrRemoveSystems" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
 
-			status, err := SumaAddSystem("cookie", server.URL, "host", "group", "192.168.1.0", false)
-			if err != nil {
-				t.Fatalf("expected no error, got %v", err)
-			}
-			if status != http.StatusOK {
-				t.Errorf("expected status %d, got %d", http.StatusOK, status)
-			}
-		},
-	)
+	result, err := sumaAddSystemResult(fakeSystem("192.168.1.10"), "cookie", server.URL, "host", "group", "192.168.1.0", false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, result.StatusCode)
+	}
+}
+
+func TestSumaAddSystemResult(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	api := fakeSystem("192.168.1.10")
+
+	result, err := sumaAddSystemResult(api, "cookie", server.URL, "host", "group", "192.168.1.0", false)
+	want := SystemResult{SystemID: 42, Hostname: "host", IP: "192.168.1.10", Group: "group", Action: ResultAdded, StatusCode: http.StatusOK}
+	if err != nil || result != want {
+		t.Errorf("sumaAddSystemResult() = %+v, %v, want %+v", result, err, want)
+	}
+
+	// a failed call returns an error and what is known about the system
+	status = http.StatusInternalServerError
+	result, err = sumaAddSystemResult(api, "cookie", server.URL, "host", "group", "192.168.1.0", false)
+	if err == nil || result.SystemID != 42 || result.Action != "" || result.StatusCode != http.StatusInternalServerError {
+		t.Errorf("sumaAddSystemResult() = %+v, %v, want an error for HTTP/500", result, err)
+	}
+	if code, err := statusCode(result, err); err == nil || code != -1 {
+		t.Errorf("statusCode() = %d, %v, want -1 and an error", code, err)
+	}
 }
 
 func TestSumaAddSystem_NotInNetwork(t *testing.T) {
-	withMockedDeps(
-		func(sessioncookie, susemgr, hostname string, verbose bool) (int, error) {
-			return 42, nil
-		},
-		func(sessioncookie, susemgr string, id int, verbose bool) (string, error) {
-			return "10.0.0.1", nil
-		},
-		func(ip, network string) bool {
-			return false
-		},
-		func() {
-			status, err := SumaAddSystem("cookie", "http://dummy", "host", "group", "192.168.1.0", false)
-			if err == nil || status != -1 {
-				t.Errorf("expected error for system not in network, got status=%d, err=%v", status, err)
-			}
-		},
-	)
+	result, err := sumaAddSystemResult(fakeSystem("10.0.0.1"), "cookie", "http://dummy", "host", "group", "192.168.1.0", false)
+	if code, _ := statusCode(result, err); err == nil || code != -1 {
+		t.Errorf("expected error for system not in network, got status=%d, err=%v", code, err)
+	}
 }
 
 func TestSumaAddSystem_GetSystemIDError(t *testing.T) {
-	withMockedDeps(
-		func(sessioncookie, susemgr, hostname string, verbose bool) (int, error) {
-			return -1, fmt.Errorf("system not found")
-		},
-		func(sessioncookie, susemgr string, id int, verbose bool) (string, error) {
-			return "", nil
-		},
-		func(ip, network string) bool {
-			return true
-		},
-		func() {
-			status, err := SumaAddSystem("cookie", "http://dummy", "host", "group", "192.168.1.0", false)
-			if err == nil || status != -1 {
-				t.Errorf("expected error for GetSystemID error, got status=%d, err=%v", status, err)
-			}
-		},
-	)
+	api := fakeSystem("")
+	api.systemID = func(string) (int, error) { return -1, fmt.Errorf("system not found") }
+
+	result, err := sumaAddSystemResult(api, "cookie", "http://dummy", "host", "group", "192.168.1.0", false)
+	if code, _ := statusCode(result, err); err == nil || code != -1 {
+		t.Errorf("expected error for GetSystemID error, got status=%d, err=%v", code, err)
+	}
 }
 
 func TestSumaAddSystem_GetSystemIPError(t *testing.T) {
-	withMockedDeps(
-		func(sessioncookie, susemgr, hostname string, verbose bool) (int, error) {
-			return 42, nil
-		},
-		func(sessioncookie, susemgr string, id int, verbose bool) (string, error) {
-			return "", fmt.Errorf("could not get IP")
-		},
-		func(ip, network string) bool {
-			return true
-		},
-		func() {
-			status, err := SumaAddSystem("cookie", "http://dummy", "host", "group", "192.168.1.0", false)
-			if err == nil || status != -1 {
-				t.Errorf("expected error for GetSystemIP error, got status=%d, err=%v", status, err)
-			}
-		},
-	)
+	api := fakeSystem("")
+	api.systemIP = func(int) (string, error) { return "", fmt.Errorf("could not get IP") }
+
+	result, err := sumaAddSystemResult(api, "cookie", "http://dummy", "host", "group", "192.168.1.0", false)
+	if code, _ := statusCode(result, err); err == nil || code != -1 {
+		t.Errorf("expected error for GetSystemIP error, got status=%d, err=%v", code, err)
+	}
 }
 
 // -----------------------------------------------------------
 
 func TestSumaDeleteSystem(t *testing.T) {
-	type args struct {
-		sessioncookie string
-		susemgr       string
-		hostname      string
-		network       string
-		verbose       bool
-	}
+	systemIDError := fakeSystem("")
+	systemIDError.systemID = func(string) (int, error) { return -1, fmt.Errorf("system not found") }
+	systemIPError := fakeSystem("")
+	systemIPError.systemIP = func(int) (string, error) { return "", fmt.Errorf("could not get IP") }
+
 	tests := []struct {
-		name              string
-		mockGetSystemID   func(string, string, string, bool) (int, error)
-		mockGetSystemIP   func(string, string, int, bool) (string, error)
-		mockIsSystemInNet func(string, string) bool
-		httpStatus        int
-		wantStatus        int
-		wantErr           bool
+		name       string
+		api        fakeSumaAPI
+		httpStatus int
+		wantStatus int
+		wantErr    bool
 	}{
 		{
-			name: "success",
-			mockGetSystemID: func(sessioncookie, susemgr, hostname string, verbose bool) (int, error) {
-				return 42, nil
-			},
-			mockGetSystemIP: func(sessioncookie, susemgr string, id int, verbose bool) (string, error) {
-				return "192.168.1.10", nil
-			},
-			mockIsSystemInNet: func(ip, network string) bool {
-				return true
-			},
+			name:       "success",
+			api:        fakeSystem("192.168.1.10"),
 			httpStatus: http.StatusOK,
 			wantStatus: http.StatusOK,
 			wantErr:    false,
 		},
 		{
-			name: "system not in network",
-			mockGetSystemID: func(sessioncookie, susemgr, hostname string, verbose bool) (int, error) {
-				return 42, nil
-			},
-			mockGetSystemIP: func(sessioncookie, susemgr string, id int, verbose bool) (string, error) {
-				return "10.0.0.1", nil
-			},
-			mockIsSystemInNet: func(ip, network string) bool {
-				return false
-			},
+			name:       "system not in network",
+			api:        fakeSystem("10.0.0.1"),
 			httpStatus: http.StatusOK,
 			wantStatus: -1,
 			wantErr:    true,
 		},
 		{
-			name: "get system id error",
-			mockGetSystemID: func(sessioncookie, susemgr, hostname string, verbose bool) (int, error) {
-				return -1, fmt.Errorf("system not found")
-			},
-			mockGetSystemIP: func(sessioncookie, susemgr string, id int, verbose bool) (string, error) {
-				return "", nil
-			},
-			mockIsSystemInNet: func(ip, network string) bool {
-				return true
-			},
+			name:       "get system id error",
+			api:        systemIDError,
 			httpStatus: http.StatusOK,
 			wantStatus: -1,
 			wantErr:    true,
 		},
 		{
-			name: "get system ip error",
-			mockGetSystemID: func(sessioncookie, susemgr, hostname string, verbose bool) (int, error) {
-				return 42, nil
-			},
-			mockGetSystemIP: func(sessioncookie, susemgr string, id int, verbose bool) (string, error) {
-				return "", fmt.Errorf("could not get IP")
-			},
-			mockIsSystemInNet: func(ip, network string) bool {
-				return true
-			},
+			name:       "get system ip error",
+			api:        systemIPError,
 			httpStatus: http.StatusOK,
 			wantStatus: -1,
 			wantErr:    true,
 		},
 		{
-			name: "http error on delete",
-			mockGetSystemID: func(sessioncookie, susemgr, hostname string, verbose bool) (int, error) {
-				return 42, nil
-			},
-			mockGetSystemIP: func(sessioncookie, susemgr string, id int, verbose bool) (string, error) {
-				return "192.168.1.10", nil
-			},
-			mockIsSystemInNet: func(ip, network string) bool {
-				return true
-			},
+			name:       "http error on delete",
+			api:        fakeSystem("192.168.1.10"),
 			httpStatus: http.StatusInternalServerError,
 			wantStatus: -1,
 			wantErr:    true,
@@ -479,80 +632,59 @@ func TestSumaDeleteSystem(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		tt := tt // capture range variable
 		t.Run(tt.name, func(t *testing.T) {
-			withMockedDeps(
-				tt.mockGetSystemID,
-				tt.mockGetSystemIP,
-				tt.mockIsSystemInNet,
-				func() {
-					// Mock HTTP server for the deleteSystem endpoint
-					server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-						if r.URL.Path != "/rhn/manager/api/system/deleteSystem" {
-							t.Errorf("unexpected path: %s", r.URL.Path)
-						}
-						w.WriteHeader(tt.httpStatus)
-						_ = json.NewEncoder(w).Encode(map[string]string{"result": "ok"})
-					}))
-					defer server.Close()
-
-					status, err := SumaDeleteSystem("cookie", server.URL, "host", "192.168.1.0", false)
-					if (err != nil) != tt.wantErr {
-						t.Errorf("SumaDeleteSystem() error = %v, wantErr %v", err, tt.wantErr)
-					}
-					if status != tt.wantStatus {
-						t.Errorf("SumaDeleteSystem() status = %v, want %v", status, tt.wantStatus)
-					}
-				},
-			)
+			t.Parallel()
+			// Mock HTTP server for the deleteSystem endpoint
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/rhn/manager/api/system/deleteSystem" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.httpStatus)
+				_ = json.NewEncoder(w).Encode(map[string]string{"result": "ok"})
+			}))
+			defer server.Close()
+
+			status, err := statusCode(sumaDeleteSystemResult(tt.api, "cookie", server.URL, "host", "192.168.1.0", false))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sumaDeleteSystemResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("sumaDeleteSystemResult() status = %v, want %v", status, tt.wantStatus)
+			}
 		})
 	}
 }
 
 // -----------------------------------------------------------------
 
-// Helper to mock sumaCheckSystemGroup for testing
-func withMockedCheckSystemGroup(mockFunc func(string, string, string, bool) bool, testFunc func()) {
-	orig := sumaCheckSystemGroup
-	sumaCheckSystemGroup = mockFunc
-	defer func() { sumaCheckSystemGroup = orig }()
-	testFunc()
-}
-
 func TestSumaRemoveSystemGroup(t *testing.T) {
 	tests := []struct {
-		name                 string
-		mockCheckSystemGroup func(string, string, string, bool) bool
-		expectHTTPCall       bool
-		httpStatus           int
-		wantStatus           int
-		wantErr              bool
+		name           string
+		groups         []string
+		expectHTTPCall bool
+		httpStatus     int
+		wantStatus     int
+		wantErr        bool
 	}{
 		{
-			name: "group exists, HTTP 200",
-			mockCheckSystemGroup: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return true
-			},
+			name:           "group exists, HTTP 200",
+			groups:         []string{"testgroup"},
 			expectHTTPCall: true,
 			httpStatus:     http.StatusOK,
 			wantStatus:     http.StatusOK,
 			wantErr:        false,
 		},
 		{
-			name: "group does not exist, no HTTP call",
-			mockCheckSystemGroup: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return false
-			},
+			name:           "group does not exist, no HTTP call",
+			groups:         []string{"other"},
 			expectHTTPCall: false,
 			httpStatus:     http.StatusOK, // not used
 			wantStatus:     http.StatusOK,
 			wantErr:        false,
 		},
 		{
-			name: "group exists, HTTP error",
-			mockCheckSystemGroup: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return true
-			},
+			name:           "group exists, HTTP error",
+			groups:         []string{"testgroup"},
 			expectHTTPCall: true,
 			httpStatus:     http.StatusInternalServerError,
 			wantStatus:     -1,
@@ -561,8 +693,8 @@ func TestSumaRemoveSystemGroup(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		tt := tt // capture range variable
 		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 			var called bool
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				called = true
@@ -574,69 +706,57 @@ func TestSumaRemoveSystemGroup(t *testing.T) {
 			}))
 			defer server.Close()
 
-			withMockedCheckSystemGroup(tt.mockCheckSystemGroup, func() {
-				status, err := sumaRemoveSystemGroup("cookie", server.URL, "testgroup", false)
-				if tt.expectHTTPCall && !called {
-					t.Errorf("expected HTTP call but it was not made")
-				}
-				if !tt.expectHTTPCall && called {
-					t.Errorf("did not expect HTTP call but it was made")
-				}
-				if (err != nil) != tt.wantErr {
-					t.Errorf("sumaRemoveSystemGroup() error = %v, wantErr %v", err, tt.wantErr)
-				}
-				if status != tt.wantStatus {
-					t.Errorf("sumaRemoveSystemGroup() status = %v, want %v", status, tt.wantStatus)
-				}
-			})
+			api := fakeSumaAPI{systemGroups: fakeList(tt.groups...)}
+			status, err := sumaRemoveSystemGroup(api, "cookie", server.URL, "testgroup", false)
+			if tt.expectHTTPCall && !called {
+				t.Errorf("expected HTTP call but it was not made")
+			}
+			if !tt.expectHTTPCall && called {
+				t.Errorf("did not expect HTTP call but it was made")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sumaRemoveSystemGroup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("sumaRemoveSystemGroup() status = %v, want %v", status, tt.wantStatus)
+			}
 		})
 	}
 }
 
 // ----------------------------------------------------------------------------------
 
-// Helper to mock sumaCheckUser for testing
-func withMockedCheckUser(mockFunc func(string, string, string, bool) bool, testFunc func()) {
-	orig := sumaCheckUser
-	sumaCheckUser = mockFunc
-	defer func() { sumaCheckUser = orig }()
-	testFunc()
-}
-
 func TestSumaAddUser(t *testing.T) {
 	tests := []struct {
 		name           string
-		mockCheckUser  func(string, string, string, bool) bool
+		users          []string
 		expectHTTPCall bool
 		httpStatus     int
 		wantStatus     int
+		wantAction     ResultAction
 		wantErr        bool
 	}{
 		{
-			name: "user does not exist, HTTP 200",
-			mockCheckUser: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return false
-			},
+			name:           "user does not exist, HTTP 200",
+			users:          []string{"admin"},
 			expectHTTPCall: true,
 			httpStatus:     http.StatusOK,
 			wantStatus:     http.StatusOK,
+			wantAction:     ResultCreated,
 			wantErr:        false,
 		},
 		{
-			name: "user already exists, no HTTP call",
-			mockCheckUser: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return true
-			},
+			name:           "user already exists, no HTTP call",
+			users:          []string{"admin", "testuser"},
 			expectHTTPCall: false,
 			httpStatus:     http.StatusOK, // not used
 			wantStatus:     http.StatusOK,
+			wantAction:     ResultUnchanged,
 			wantErr:        false,
 		},
 		{
-			name: "user does not exist, HTTP error",
-			mockCheckUser: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return false
-			},
+			name:           "user does not exist, HTTP error",
+			users:          []string{"admin"},
 			expectHTTPCall: true,
 			httpStatus:     http.StatusInternalServerError,
 			wantStatus:     500,
@@ -645,8 +765,8 @@ func TestSumaAddUser(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		tt := tt // capture range variable
 		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 			var called bool
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				called = true
@@ -658,97 +778,63 @@ func TestSumaAddUser(t *testing.T) {
 			}))
 			defer server.Close()
 
-			withMockedCheckUser(tt.mockCheckUser, func() {
-				status, err := SumaAddUser("cookie", "testuser", "testpass", server.URL, false)
-				if tt.expectHTTPCall && !called {
-					t.Errorf("expected HTTP call but it was not made")
-				}
-				if !tt.expectHTTPCall && called {
-					t.Errorf("did not expect HTTP call but it was made")
-				}
-				if (err != nil) != tt.wantErr {
-					t.Errorf("SumaAddUser() error = %v, wantErr %v", err, tt.wantErr)
-				}
-				if status != tt.wantStatus {
-					t.Errorf("SumaAddUser() status = %v, want %v", status, tt.wantStatus)
-				}
-			})
+			api := fakeSumaAPI{users: fakeList(tt.users...)}
+			result, err := sumaAddUserResult(api, "cookie", "testuser", "testpass", server.URL, false)
+			if tt.expectHTTPCall && !called {
+				t.Errorf("expected HTTP call but it was not made")
+			}
+			if !tt.expectHTTPCall && called {
+				t.Errorf("did not expect HTTP call but it was made")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sumaAddUserResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := (UserResult{Login: "testuser", Action: tt.wantAction, StatusCode: tt.wantStatus}); result != want {
+				t.Errorf("sumaAddUserResult() = %+v, want %+v", result, want)
+			}
 		})
 	}
 }
 
 // -----------------------------------------------------------------------
 
-// Helper to mock sumaRemoveSystemGroup and sumaCheckUser for testing
-func withMockedRemoveUserDeps(
-	mockRemoveSystemGroup func(string, string, string, bool) (int, error),
-	mockCheckUser func(string, string, string, bool) bool,
-	testFunc func(),
-) {
-	origRemoveSystemGroup := sumaRemoveSystemGroup
-	origCheckUser := sumaCheckUser
-	sumaRemoveSystemGroup = mockRemoveSystemGroup
-	sumaCheckUser = mockCheckUser
-	defer func() {
-		sumaRemoveSystemGroup = origRemoveSystemGroup
-		sumaCheckUser = origCheckUser
-	}()
-	testFunc()
-}
-
 func TestSumaRemoveUser(t *testing.T) {
 	tests := []struct {
-		name                  string
-		mockRemoveSystemGroup func(string, string, string, bool) (int, error)
-		mockCheckUser         func(string, string, string, bool) bool
-		expectHTTPCall        bool
-		httpStatus            int
-		wantErr               bool
+		name           string
+		groupStatus    int
+		users          []string
+		expectHTTPCall bool
+		httpStatus     int
+		wantErr        bool
 	}{
 		{
-			name: "user does not exist after group removal (no HTTP call)",
-			mockRemoveSystemGroup: func(sessioncookie, susemgrurl, group string, verbose bool) (int, error) {
-				return http.StatusOK, nil
-			},
-			mockCheckUser: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return false
-			},
+			name:           "user does not exist after group removal (no HTTP call)",
+			groupStatus:    http.StatusOK,
+			users:          []string{"admin"},
 			expectHTTPCall: false,
 			httpStatus:     http.StatusOK,
 			wantErr:        false,
 		},
 		{
-			name: "user exists, HTTP 200 (success)",
-			mockRemoveSystemGroup: func(sessioncookie, susemgrurl, group string, verbose bool) (int, error) {
-				return http.StatusOK, nil
-			},
-			mockCheckUser: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return true
-			},
+			name:           "user exists, HTTP 200 (success)",
+			groupStatus:    http.StatusOK,
+			users:          []string{"testuser"},
 			expectHTTPCall: true,
 			httpStatus:     http.StatusOK,
 			wantErr:        false,
 		},
 		{
-			name: "user exists, HTTP error (failure)",
-			mockRemoveSystemGroup: func(sessioncookie, susemgrurl, group string, verbose bool) (int, error) {
-				return http.StatusOK, nil
-			},
-			mockCheckUser: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return true
-			},
+			name:           "user exists, HTTP error (failure)",
+			groupStatus:    http.StatusOK,
+			users:          []string{"testuser"},
 			expectHTTPCall: true,
 			httpStatus:     http.StatusInternalServerError,
 			wantErr:        true,
 		},
 		{
-			name: "error from sumaRemoveSystemGroup",
-			mockRemoveSystemGroup: func(sessioncookie, susemgrurl, group string, verbose bool) (int, error) {
-				return -1, errors.New("remove group error")
-			},
-			mockCheckUser: func(sessioncookie, group, susemgrurl string, verbose bool) bool {
-				return true
-			},
+			name:           "error from sumaRemoveSystemGroup",
+			groupStatus:    http.StatusInternalServerError,
+			users:          []string{"testuser"},
 			expectHTTPCall: false,
 			httpStatus:     http.StatusOK,
 			wantErr:        true,
@@ -756,31 +842,76 @@ func TestSumaRemoveUser(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		tt := tt // capture range variable
 		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 			var called bool
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				called = true
-				if r.URL.Path != "/rhn/manager/api/user/delete" {
+				switch r.URL.Path {
+				case "/rhn/manager/api/systemgroup/delete":
+					w.WriteHeader(tt.groupStatus)
+				case "/rhn/manager/api/user/delete":
+					called = true
+					w.WriteHeader(tt.httpStatus)
+				default:
 					t.Errorf("unexpected path: %s", r.URL.Path)
 				}
-				w.WriteHeader(tt.httpStatus)
 				_ = json.NewEncoder(w).Encode(map[string]string{"result": "ok"})
 			}))
 			defer server.Close()
 
-			withMockedRemoveUserDeps(tt.mockRemoveSystemGroup, tt.mockCheckUser, func() {
-				err := SumaRemoveUser("cookie", "testuser", server.URL, false)
-				if tt.expectHTTPCall && !called {
-					t.Errorf("expected HTTP call but it was not made")
-				}
-				if !tt.expectHTTPCall && called {
-					t.Errorf("did not expect HTTP call but it was made")
-				}
-				if (err != nil) != tt.wantErr {
-					t.Errorf("SumaRemoveUser() error = %v, wantErr %v", err, tt.wantErr)
+			api := fakeSumaAPI{systemGroups: fakeList("testuser"), users: fakeList(tt.users...)}
+			err := sumaRemoveUser(api, "cookie", "testuser", server.URL, false)
+			if tt.expectHTTPCall && !called {
+				t.Errorf("expected HTTP call but it was not made")
+			}
+			if !tt.expectHTTPCall && called {
+				t.Errorf("did not expect HTTP call but it was made")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sumaRemoveUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeSumaResponse(t *testing.T) {
+	resp := &http.Response{Request: &http.Request{Header: http.Header{"X-Request-Id": {"req-1"}}}}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []int
+		wantErr string
+	}{
+		{name: "result", body: `{"success": true, "result": [1, 2]}`, want: []int{1, 2}},
+		{name: "empty result", body: `{"success": true}`},
+		{name: "failed", body: `{"success": false, "message": "No such group"}`, wantErr: "systemgroup/listSystems failed: No such group (request req-1)"},
+		{name: "invalid JSON", body: `<html>`, wantErr: "systemgroup/listSystems: error unmarshaling JSON: invalid character '<' looking for beginning of value (request req-1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeSumaResponse[[]int](resp, "systemgroup/listSystems", []byte(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("got error %v, want %q", err, tt.wantErr)
 				}
-			})
+				return
+			}
+			if err != nil || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
 		})
 	}
 }
+
+func TestGetAPIListError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if err := GetAPIList("cookie", server.URL, false); err == nil || !strings.Contains(err.Error(), "HTTP/500") {
+		t.Errorf("GetAPIList() error = %v, want HTTP/500", err)
+	}
+}
@@ -0,0 +1,354 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sumaStateVersion is the version of the SumaState document, it is increased on incompatible changes
+const sumaStateVersion = 1
+
+// SumaState is the exported state of a SUMA server: the system groups with their members, the users
+// and the activation keys. It is written as JSON or YAML document and imported on another server,
+// e.g. for a migration of the landscape or a disaster recovery rehearsal.
+type SumaState struct {
+	Version        int                  `json:"version" yaml:"version"`
+	Server         string               `json:"server,omitempty" yaml:"server,omitempty"`
+	Exported       time.Time            `json:"exported" yaml:"exported"`
+	Groups         []StateGroup         `json:"groups" yaml:"groups"`
+	Users          []StateUser          `json:"users" yaml:"users"`
+	ActivationKeys []StateActivationKey `json:"activationKeys" yaml:"activationKeys"`
+}
+
+// StateGroup is an exported system group, the members are the names of the systems
+type StateGroup struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Members     []string `json:"members,omitempty" yaml:"members,omitempty"`
+}
+
+// StateUser is an exported user with its roles and the system groups assigned to it. The password
+// cannot be exported.
+type StateUser struct {
+	Login     string   `json:"login" yaml:"login"`
+	FirstName string   `json:"firstName,omitempty" yaml:"firstName,omitempty"`
+	LastName  string   `json:"lastName,omitempty" yaml:"lastName,omitempty"`
+	Email     string   `json:"email,omitempty" yaml:"email,omitempty"`
+	Roles     []Role   `json:"roles,omitempty" yaml:"roles,omitempty"`
+	Groups    []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// StateActivationKey is an exported activation key. Key is the key without the organization prefix,
+// e.g. web for 1-web, so it can be imported into another organization.
+type StateActivationKey struct {
+	Key              string   `json:"key" yaml:"key"`
+	Description      string   `json:"description,omitempty" yaml:"description,omitempty"`
+	BaseChannelLabel string   `json:"baseChannelLabel,omitempty" yaml:"baseChannelLabel,omitempty"`
+	UsageLimit       int      `json:"usageLimit,omitempty" yaml:"usageLimit,omitempty"`
+	UniversalDefault bool     `json:"universalDefault,omitempty" yaml:"universalDefault,omitempty"`
+	Entitlements     []string `json:"entitlements,omitempty" yaml:"entitlements,omitempty"`
+}
+
+// ExportOptions select what Export exports
+type ExportOptions struct {
+	// Groups are the exported system groups, all groups if empty
+	Groups []string
+	// AllUsers exports every user. By default only the users managed by appapi are exported, the
+	// users named like an exported system group, see SumaAddUser.
+	AllUsers bool
+}
+
+// ImportOptions configure Import
+type ImportOptions struct {
+	// Network is the permitted network of the group members, defaults to the first network of the
+	// SumaClient
+	Network string
+	// UserPassword returns the initial password of a user which does not exist on the server
+	UserPassword func(login string) (string, error)
+}
+
+// StateImportResult is the result of importing one resource
+type StateImportResult struct {
+	Kind   string       `json:"kind"`
+	Name   string       `json:"name"`
+	Action ResultAction `json:"action,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// keyOrgPrefix returns the key without the organization prefix SUMA adds, e.g. web for 1-web
+func keyOrgPrefix(key string) string {
+	if org, rest, ok := strings.Cut(key, "-"); ok && org != "" && strings.Trim(org, "0123456789") == "" {
+		return rest
+	}
+	return key
+}
+
+// sumaListAssignedGroups returns the system groups assigned to a user
+func sumaListAssignedGroups(c *SumaClient, login string) ([]string, error) {
+	var groups []struct {
+		Name string `json:"name"`
+	}
+	if err := c.get("user/listAssignedSystemGroups", url.Values{"login": {login}}, &groups); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Export reads the system groups with their members, the users and the activation keys
+func (c *SumaClient) Export(opts ExportOptions) (*SumaState, error) {
+	state := &SumaState{Version: sumaStateVersion, Server: c.URL, Exported: time.Now().UTC()}
+
+	groups := opts.Groups
+	if len(groups) == 0 {
+		var err error
+		if groups, err = c.SystemGroups(); err != nil {
+			return nil, err
+		}
+	}
+	groups = slices.Sorted(slices.Values(groups))
+	for _, name := range groups {
+		g, err := ImportSystemGroup(c, name)
+		if err != nil {
+			return nil, err
+		}
+		systems, err := c.GroupSystems(name)
+		if err != nil {
+			return nil, err
+		}
+		sg := StateGroup{Name: g.Name, Description: g.Description}
+		for _, s := range systems {
+			sg.Members = append(sg.Members, s.Name)
+		}
+		sort.Strings(sg.Members)
+		state.Groups = append(state.Groups, sg)
+	}
+
+	users, err := c.Users()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(users)
+	for _, login := range users {
+		if !opts.AllUsers && !slices.Contains(groups, login) {
+			continue
+		}
+		u, err := ImportSumaUser(c, login)
+		if err != nil {
+			return nil, err
+		}
+		roles, err := c.Roles(login)
+		if err != nil {
+			return nil, err
+		}
+		assigned, err := sumaListAssignedGroups(c, login)
+		if err != nil {
+			return nil, err
+		}
+		state.Users = append(state.Users, StateUser{
+			Login:     u.Login,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Email:     u.Email,
+			Roles:     roles,
+			Groups:    assigned,
+		})
+	}
+
+	var keys []struct {
+		Key              string   `json:"key"`
+		Description      string   `json:"description"`
+		BaseChannelLabel string   `json:"base_channel_label"`
+		UsageLimit       int      `json:"usage_limit"`
+		UniversalDefault bool     `json:"universal_default"`
+		Entitlements     []string `json:"entitlements"`
+	}
+	if err := c.get("activationkey/listActivationKeys", nil, &keys); err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		state.ActivationKeys = append(state.ActivationKeys, StateActivationKey{
+			Key:              keyOrgPrefix(k.Key),
+			Description:      k.Description,
+			BaseChannelLabel: k.BaseChannelLabel,
+			UsageLimit:       k.UsageLimit,
+			UniversalDefault: k.UniversalDefault,
+			Entitlements:     k.Entitlements,
+		})
+	}
+	sort.Slice(state.ActivationKeys, func(i, j int) bool { return state.ActivationKeys[i].Key < state.ActivationKeys[j].Key })
+
+	return state, nil
+}
+
+// Import re-creates the state on the server. Existing resources are updated, nothing is deleted. The
+// members of the groups must already be registered with the server. Every resource is imported even if
+// another one fails, the failures are returned joined.
+func (c *SumaClient) Import(state *SumaState, opts ImportOptions) (results []StateImportResult, err error) {
+	if state.Version != sumaStateVersion {
+		return nil, fmt.Errorf("unsupported state version %d, want %d", state.Version, sumaStateVersion)
+	}
+	if opts.Network == "" && len(c.Networks) > 0 {
+		opts.Network = c.Networks[0]
+	}
+
+	var errs []error
+	record := func(kind, name string, action ResultAction, err error) {
+		r := StateImportResult{Kind: kind, Name: name, Action: action}
+		if err != nil {
+			r.Action, r.Error = "", err.Error()
+			errs = append(errs, fmt.Errorf("%s %s: %w", kind, name, err))
+		}
+		results = append(results, r)
+	}
+
+	for _, g := range state.Groups {
+		status, err := EnsureSystemGroup(c, SystemGroup{Name: g.Name, Description: g.Description})
+		record("system group", g.Name, status.Action, err)
+		if err != nil || len(g.Members) == 0 {
+			continue
+		}
+		report, err := c.EnsureGroupMembers(g.Name, g.Members, opts.Network)
+		action := ResultUnchanged
+		if report.Changed() {
+			action = ResultUpdated
+		}
+		record("system group members", g.Name, action, err)
+	}
+
+	existingKeys, err := c.activationKeys()
+	if err != nil {
+		return results, err
+	}
+	for _, k := range state.ActivationKeys {
+		if slices.Contains(existingKeys, k.Key) {
+			record("activation key", k.Key, ResultUnchanged, nil)
+			continue
+		}
+		key := &ActivationKey{
+			Key:              k.Key,
+			Description:      k.Description,
+			BaseChannelLabel: k.BaseChannelLabel,
+			UsageLimit:       k.UsageLimit,
+			UniversalDefault: k.UniversalDefault,
+			Entitlements:     k.Entitlements,
+		}
+		record("activation key", k.Key, ResultCreated, key.Create(c))
+	}
+
+	for _, u := range state.Users {
+		action, err := c.importUser(u, opts)
+		record("user", u.Login, action, err)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// activationKeys returns the activation keys of the server without the organization prefix
+func (c *SumaClient) activationKeys() ([]string, error) {
+	var keys []struct {
+		Key string `json:"key"`
+	}
+	if err := c.get("activationkey/listActivationKeys", nil, &keys); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, keyOrgPrefix(k.Key))
+	}
+	return names, nil
+}
+
+func (c *SumaClient) importUser(u StateUser, opts ImportOptions) (ResultAction, error) {
+	desired := SumaUser{Login: u.Login, FirstName: u.FirstName, LastName: u.LastName, Email: u.Email}
+	if _, err := ImportSumaUser(c, u.Login); errors.Is(err, ErrNotFound) {
+		if opts.UserPassword == nil {
+			return "", errors.New("the user does not exist and no password is configured")
+		}
+		if desired.Password, err = opts.UserPassword(u.Login); err != nil {
+			return "", err
+		}
+	} else if err != nil {
+		return "", err
+	}
+	status, err := EnsureSumaUser(c, desired)
+	if err != nil {
+		return "", err
+	}
+	action := status.Action
+
+	roles, err := c.Roles(u.Login)
+	if err != nil {
+		return "", err
+	}
+	for _, role := range u.Roles {
+		if slices.Contains(roles, role) {
+			continue
+		}
+		if err := c.AddRole(u.Login, role); err != nil {
+			return "", err
+		}
+		if action == ResultUnchanged {
+			action = ResultUpdated
+		}
+	}
+
+	assigned, err := sumaListAssignedGroups(c, u.Login)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	for _, g := range u.Groups {
+		if !slices.Contains(assigned, g) {
+			missing = append(missing, g)
+		}
+	}
+	if len(missing) > 0 {
+		payload := map[string]any{"login": u.Login, "serverGroupNames": missing, "setDefault": false}
+		if err := c.post("user/addAssignedSystemGroups", payload, nil); err != nil {
+			return "", err
+		}
+		if action == ResultUnchanged {
+			action = ResultUpdated
+		}
+	}
+	return action, nil
+}
+
+// WriteSumaState writes the state as YAML or, if format is "json", as JSON
+func WriteSumaState(w io.Writer, state *SumaState, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(state)
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(state); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ReadSumaState reads a state written by WriteSumaState, JSON or YAML
+func ReadSumaState(r io.Reader) (*SumaState, error) {
+	var state SumaState
+	// YAML is a superset of JSON, the same tags are used for both
+	if err := yaml.NewDecoder(r).Decode(&state); err != nil {
+		return nil, fmt.Errorf("could not read the SUMA state: %v", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,107 @@
+package appapi
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSumaStateExportImport(t *testing.T) {
+	source := newFakeResourceServer(t)
+	defer source.Close()
+	target := newFakeResourceServer(t)
+	defer target.Close()
+
+	src := &SumaClient{URL: source.URL, sessioncookie: "cookie"}
+	if err := (&SystemGroup{Name: "web", Description: "Web servers"}).Create(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&SystemGroup{Name: "db", Description: "Databases"}).Create(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&SumaUser{Login: "web", Password: "secret", FirstName: "Web", LastName: "Team", Email: "web@example.com"}).Create(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&SumaUser{Login: "admin", Password: "secret", FirstName: "Ad", LastName: "Min", Email: "admin@example.com"}).Create(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.AddRole("web", "system_group_admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.post("user/addAssignedSystemGroups", map[string]any{"login": "web", "serverGroupNames": []string{"web"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&ActivationKey{Key: "web", Description: "Web", BaseChannelLabel: "sles15-sp6", Entitlements: []string{"monitoring_entitled"}}).Create(src); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := src.Export(ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Groups) != 2 || state.Groups[0].Name != "db" || state.Groups[1].Name != "web" {
+		t.Errorf("groups = %+v", state.Groups)
+	}
+	if len(state.Users) != 1 || state.Users[0].Login != "web" {
+		t.Fatalf("only the managed user web must be exported, got %+v", state.Users)
+	}
+	if len(state.ActivationKeys) != 1 || state.ActivationKeys[0].Key != "web" {
+		t.Errorf("activation keys = %+v", state.ActivationKeys)
+	}
+
+	for _, format := range []string{"yaml", "json"} {
+		var buf bytes.Buffer
+		if err := WriteSumaState(&buf, state, format); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadSumaState(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !reflect.DeepEqual(read.Users, state.Users) || !read.Exported.Equal(state.Exported) {
+			t.Errorf("%s: read %+v, want %+v", format, read, state)
+		}
+	}
+
+	dst := &SumaClient{URL: target.URL, sessioncookie: "cookie"}
+	if _, err := dst.Import(state, ImportOptions{}); err == nil || !strings.Contains(err.Error(), "user web") {
+		t.Errorf("import without password: err = %v", err)
+	}
+	results, err := dst.Import(state, ImportOptions{UserPassword: func(string) (string, error) { return "initial", nil }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			t.Errorf("%s %s: %s", r.Kind, r.Name, r.Error)
+		}
+	}
+
+	imported, err := dst.Export(ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*SumaState{state, imported} {
+		s.Server, s.Exported = "", imported.Exported
+	}
+	if !reflect.DeepEqual(imported, state) {
+		t.Errorf("imported state = %+v, want %+v", imported, state)
+	}
+
+	// a second import changes nothing
+	results, err = dst.Import(state, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Action != ResultUnchanged {
+			t.Errorf("%s %s: action = %s, want unchanged", r.Kind, r.Name, r.Action)
+		}
+	}
+
+	state.Version = 2
+	if _, err := dst.Import(state, ImportOptions{}); err == nil {
+		t.Error("a state of another version must not be imported")
+	}
+}
@@ -0,0 +1,879 @@
+// Package sumatest provides a fake SUSE Manager API for integration tests of applications using appapi,
+// like net/http/httptest does for HTTP handlers. The server keeps a dataset of system groups, systems,
+// users and activation keys in memory and implements the API methods appapi calls with the responses
+// of SUSE Manager, so the tests need no hand-written handlers:
+//
+//	srv := sumatest.NewServer(sumatest.Dataset{
+//		Groups:  []appapi.SystemGroup{{Name: "web"}},
+//		Systems: []sumatest.System{{ID: 1000010000, Name: "web1.example.com", IP: "10.0.0.5"}},
+//	})
+//	defer srv.Close()
+//
+//	suma, err := srv.NewClient()
+//	result, err := suma.AddSystem("web1.example.com", "web", "10.0.0.0/24")
+//	members := srv.Members("web") // [1000010000]
+package sumatest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arzieg/appapi"
+)
+
+// The defaults of a Dataset
+const (
+	// DefaultLogin and DefaultPassword are the credentials of the admin user added to a dataset without users
+	DefaultLogin    = "admin"
+	DefaultPassword = "admin"
+	// DefaultVersion is the product version reported by api/systemVersion
+	DefaultVersion = "5.0.3"
+	// DefaultOrg is the organization ID, activation keys are prefixed with it like 1-web
+	DefaultOrg = 1
+)
+
+// DefaultAssignableRoles are the roles of user/listAssignableRoles if the dataset has none
+var DefaultAssignableRoles = []appapi.Role{
+	"org_admin", "system_group_admin", "activation_key_admin", "config_admin", "channel_admin", "image_admin",
+}
+
+// System is a registered system
+type System struct {
+	ID int
+	// Name is the profile name of the system, the hostname it is found with by system/getId
+	Name        string
+	IP          string
+	IP6         string
+	LastCheckin time.Time
+	Locked      bool
+}
+
+// User is a SUSE Manager user with its roles and the system groups it administers
+type User struct {
+	appapi.SumaUser
+	Roles  []appapi.Role
+	Groups []string
+}
+
+// Dataset is the initial content of a Server
+type Dataset struct {
+	// Version is the product version, DefaultVersion if empty
+	Version string
+	Groups  []appapi.SystemGroup
+	Systems []System
+	// Members are the IDs of the systems of a group by group name
+	Members map[string][]int
+	// Users are the users, the first one is returned by Credentials. A dataset without users gets
+	// the admin user DefaultLogin.
+	Users          []User
+	ActivationKeys []appapi.ActivationKey
+	// AssignableRoles are the roles users can get, DefaultAssignableRoles if empty
+	AssignableRoles []appapi.Role
+}
+
+type group struct {
+	id          int
+	name        string
+	description string
+}
+
+// Server is a fake SUSE Manager serving the API under /rhn/manager/api. Every API method except
+// auth/login requires the session cookie of a login.
+type Server struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	version         string
+	groups          map[string]*group
+	systems         map[int]*System
+	members         map[string][]int
+	users           map[string]*User
+	keys            map[string]*appapi.ActivationKey
+	assignableRoles []appapi.Role
+	sessions        map[string]string
+	failures        map[string]string
+	calls           []string
+	nextID          int
+	creds           appapi.Credentials
+}
+
+// NewServer starts a fake SUSE Manager with a copy of the dataset. The caller closes it with Close.
+func NewServer(data Dataset) *Server {
+	s := &Server{
+		version:         data.Version,
+		groups:          map[string]*group{},
+		systems:         map[int]*System{},
+		members:         map[string][]int{},
+		users:           map[string]*User{},
+		keys:            map[string]*appapi.ActivationKey{},
+		assignableRoles: slices.Clone(data.AssignableRoles),
+		sessions:        map[string]string{},
+		failures:        map[string]string{},
+		nextID:          1000010000,
+	}
+	if s.version == "" {
+		s.version = DefaultVersion
+	}
+	if len(s.assignableRoles) == 0 {
+		s.assignableRoles = slices.Clone(DefaultAssignableRoles)
+	}
+	for _, g := range data.Groups {
+		s.nextID++
+		s.groups[g.Name] = &group{id: s.nextID, name: g.Name, description: g.Description}
+	}
+	for _, sys := range data.Systems {
+		s.addSystem(sys)
+	}
+	for name, ids := range data.Members {
+		s.members[name] = slices.Clone(ids)
+	}
+	if len(data.Users) == 0 {
+		data.Users = []User{{SumaUser: appapi.SumaUser{Login: DefaultLogin, Password: DefaultPassword}, Roles: []appapi.Role{"org_admin"}}}
+	}
+	s.creds = appapi.Credentials{SumaUsername: data.Users[0].Login, SumaPassword: data.Users[0].Password}
+	for _, u := range data.Users {
+		u.Roles, u.Groups = slices.Clone(u.Roles), slices.Clone(u.Groups)
+		s.users[u.Login] = &u
+	}
+	for _, k := range data.ActivationKeys {
+		key := k
+		key.Entitlements = slices.Clone(k.Entitlements)
+		s.keys[k.Key] = &key
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Credentials returns the credentials of the first user of the dataset
+func (s *Server) Credentials() appapi.Credentials {
+	return s.creds
+}
+
+// NewClient logs in with Credentials. The server serves plain HTTP, so the client is created with
+// appapi.WithInsecure.
+func (s *Server) NewClient(opts ...appapi.Option) (*appapi.SumaClient, error) {
+	opts = append([]appapi.Option{appapi.WithInsecure(true)}, opts...)
+	return appapi.NewSumaClient(s.URL, appapi.NewStaticCredentialProvider(s.Credentials()), opts...)
+}
+
+// AddSystem registers a system, a zero ID is set to the next free ID. It returns the ID.
+func (s *Server) AddSystem(sys System) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addSystem(sys)
+}
+
+func (s *Server) addSystem(sys System) int {
+	if sys.ID == 0 {
+		s.nextID++
+		sys.ID = s.nextID
+	}
+	s.nextID = max(s.nextID, sys.ID)
+	s.systems[sys.ID] = &sys
+	return sys.ID
+}
+
+// Systems returns the registered systems sorted by ID
+func (s *Server) Systems() []System {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	systems := make([]System, 0, len(s.systems))
+	for _, sys := range s.systems {
+		systems = append(systems, *sys)
+	}
+	sort.Slice(systems, func(i, j int) bool { return systems[i].ID < systems[j].ID })
+	return systems
+}
+
+// Groups returns the system groups sorted by name
+func (s *Server) Groups() []appapi.SystemGroup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := make([]appapi.SystemGroup, 0, len(s.groups))
+	for _, g := range s.groups {
+		groups = append(groups, s.groupDetails(g))
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// Members returns the sorted IDs of the systems of a group
+func (s *Server) Members(group string) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(slices.Values(s.members[group]))
+}
+
+// Users returns the users sorted by login, without passwords
+func (s *Server) Users() []User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var users []User
+	for _, u := range s.users {
+		user := *u
+		user.Password = ""
+		user.Roles, user.Groups = slices.Clone(u.Roles), slices.Clone(u.Groups)
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Login < users[j].Login })
+	return users
+}
+
+// ActivationKeys returns the activation keys sorted by key
+func (s *Server) ActivationKeys() []appapi.ActivationKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]appapi.ActivationKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, *k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	return keys
+}
+
+// Calls returns the called API methods in order, e.g. systemgroup/addOrRemoveSystems
+func (s *Server) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.calls)
+}
+
+// Fail makes every call of the API method fail with the message, as SUSE Manager reports a fault.
+// An empty message removes the failure.
+func (s *Server) Fail(method, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if message == "" {
+		delete(s.failures, method)
+		return
+	}
+	s.failures[method] = message
+}
+
+// ExpireSessions drops every session, as SUSE Manager does after a timeout or a restart. The calls
+// with the old session cookies are answered with 401 Unauthorized.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.sessions)
+}
+
+// apiMethod is the implementation of an API method, it is called with the lock held
+type apiMethod func(r *http.Request) (any, error)
+
+func (s *Server) methods() map[string]apiMethod {
+	return map[string]apiMethod{
+		"auth/logout":       s.logout,
+		"api/systemVersion": func(*http.Request) (any, error) { return s.version, nil },
+
+		"systemgroup/listAllGroups":        s.listAllGroups,
+		"systemgroup/getDetails":           s.getGroupDetails,
+		"systemgroup/create":               s.createGroup,
+		"systemgroup/update":               s.updateGroup,
+		"systemgroup/delete":               s.deleteGroup,
+		"systemgroup/listSystems":          s.listGroupSystems,
+		"systemgroup/listSystemsMinimal":   s.listGroupSystems,
+		"systemgroup/addOrRemoveSystems":   s.addOrRemoveSystems,
+		"system/getId":                     s.getSystemID,
+		"system/getNetwork":                s.getNetwork,
+		"system/getDetails":                s.getSystemDetails,
+		"system/listSystems":               s.listSystems,
+		"system/setLockStatus":             s.setLockStatus,
+		"system/deleteSystem":              s.deleteSystem,
+		"user/listUsers":                   s.listUsers,
+		"user/getDetails":                  s.getUserDetails,
+		"user/create":                      s.createUser,
+		"user/setDetails":                  s.setUserDetails,
+		"user/delete":                      s.deleteUser,
+		"user/listRoles":                   s.listRoles,
+		"user/listAssignableRoles":         func(*http.Request) (any, error) { return s.assignableRoles, nil },
+		"user/addRole":                     s.changeRole(true),
+		"user/removeRole":                  s.changeRole(false),
+		"user/listAssignedSystemGroups":    s.listAssignedGroups,
+		"user/addAssignedSystemGroups":     s.addAssignedGroups,
+		"activationkey/listActivationKeys": s.listActivationKeys,
+		"activationkey/create":             s.createActivationKey,
+		"activationkey/setDetails":         s.setActivationKeyDetails,
+		"activationkey/delete":             s.deleteActivationKey,
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutPrefix(r.URL.Path, "/rhn/manager/api/")
+	method, known := s.methods()[name]
+	if name == "auth/login" {
+		// the login sets the session cookie, it is the only method writing headers
+		method, known = func(r *http.Request) (any, error) { return s.login(w, r) }, true
+	}
+	if !ok || !known {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, name)
+
+	if name != "auth/login" {
+		cookie, err := r.Cookie("pxt-session-cookie")
+		if err != nil || s.sessions[cookie.Value] == "" {
+			http.Error(w, "invalid session", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if message, failed := s.failures[name]; failed {
+		_ = json.NewEncoder(w).Encode(map[string]any{"success": false, "message": message})
+		return
+	}
+	result, err := method(r)
+	if err != nil {
+		_ = json.NewEncoder(w).Encode(map[string]any{"success": false, "message": err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"success": true, "result": result})
+}
+
+// decode unmarshals the JSON body of a request
+func decode(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("could not parse the request: %v", err)
+	}
+	return nil
+}
+
+// queryInt returns an integer query parameter
+func queryInt(r *http.Request, name string) (int, error) {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q", name, r.URL.Query().Get(name))
+	}
+	return n, nil
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) (any, error) {
+	var req struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	u := s.users[req.Login]
+	if u == nil || u.Password != req.Password {
+		return nil, errors.New("Either the password or username is incorrect.")
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	session := hex.EncodeToString(b)
+	s.sessions[session] = req.Login
+	http.SetCookie(w, &http.Cookie{Name: "pxt-session-cookie", Value: session, MaxAge: 3600, Path: "/"})
+	return 1, nil
+}
+
+func (s *Server) logout(r *http.Request) (any, error) {
+	if cookie, err := r.Cookie("pxt-session-cookie"); err == nil {
+		delete(s.sessions, cookie.Value)
+	}
+	return 1, nil
+}
+
+func (s *Server) groupDetails(g *group) appapi.SystemGroup {
+	return appapi.SystemGroup{Name: g.name, Description: g.description, GroupID: g.id, SystemCount: len(s.members[g.name])}
+}
+
+func groupJSON(g appapi.SystemGroup) map[string]any {
+	return map[string]any{"id": g.GroupID, "name": g.Name, "description": g.Description, "system_count": g.SystemCount, "org_id": DefaultOrg}
+}
+
+func (s *Server) group(name string) (*group, error) {
+	g := s.groups[name]
+	if g == nil {
+		return nil, fmt.Errorf("Unable to locate or access server group: %s", name)
+	}
+	return g, nil
+}
+
+func (s *Server) listAllGroups(*http.Request) (any, error) {
+	list := []map[string]any{}
+	for _, g := range s.groups {
+		list = append(list, groupJSON(s.groupDetails(g)))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i]["name"].(string) < list[j]["name"].(string) })
+	return list, nil
+}
+
+func (s *Server) getGroupDetails(r *http.Request) (any, error) {
+	g, err := s.group(r.URL.Query().Get("systemGroupName"))
+	if err != nil {
+		return nil, err
+	}
+	return groupJSON(s.groupDetails(g)), nil
+}
+
+func (s *Server) createGroup(r *http.Request) (any, error) {
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, errors.New("name must not be empty")
+	}
+	if s.groups[req.Name] != nil {
+		return nil, fmt.Errorf("A system group with the name %s already exists", req.Name)
+	}
+	s.nextID++
+	g := &group{id: s.nextID, name: req.Name, description: req.Description}
+	s.groups[req.Name] = g
+	return groupJSON(s.groupDetails(g)), nil
+}
+
+func (s *Server) updateGroup(r *http.Request) (any, error) {
+	var req struct {
+		SystemGroupName string `json:"systemGroupName"`
+		Description     string `json:"description"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	g, err := s.group(req.SystemGroupName)
+	if err != nil {
+		return nil, err
+	}
+	g.description = req.Description
+	return groupJSON(s.groupDetails(g)), nil
+}
+
+func (s *Server) deleteGroup(r *http.Request) (any, error) {
+	var req struct {
+		SystemGroupName string `json:"systemGroupName"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if _, err := s.group(req.SystemGroupName); err != nil {
+		return nil, err
+	}
+	delete(s.groups, req.SystemGroupName)
+	delete(s.members, req.SystemGroupName)
+	for _, u := range s.users {
+		u.Groups = slices.DeleteFunc(u.Groups, func(g string) bool { return g == req.SystemGroupName })
+	}
+	return 1, nil
+}
+
+func systemJSON(sys *System) map[string]any {
+	checkin := ""
+	if !sys.LastCheckin.IsZero() {
+		checkin = sys.LastCheckin.UTC().Format("2006-01-02T15:04:05")
+	}
+	return map[string]any{"id": sys.ID, "name": sys.Name, "last_checkin": checkin}
+}
+
+func (s *Server) listGroupSystems(r *http.Request) (any, error) {
+	name := r.URL.Query().Get("systemGroupName")
+	if _, err := s.group(name); err != nil {
+		return nil, err
+	}
+	list := []map[string]any{}
+	for _, id := range slices.Sorted(slices.Values(s.members[name])) {
+		if sys := s.systems[id]; sys != nil {
+			list = append(list, systemJSON(sys))
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) addOrRemoveSystems(r *http.Request) (any, error) {
+	var req appapi.SumaApiAddRemoveSystem
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if _, err := s.group(req.SystemGroupName); err != nil {
+		return nil, err
+	}
+	for _, id := range req.ServerIds {
+		if s.systems[id] == nil {
+			return nil, fmt.Errorf("No such system - sid = %d", id)
+		}
+	}
+	members := s.members[req.SystemGroupName]
+	for _, id := range req.ServerIds {
+		if req.Add && !slices.Contains(members, id) {
+			members = append(members, id)
+		} else if !req.Add {
+			members = slices.DeleteFunc(members, func(m int) bool { return m == id })
+		}
+	}
+	s.members[req.SystemGroupName] = members
+	return 1, nil
+}
+
+func (s *Server) system(r *http.Request) (*System, error) {
+	id, err := queryInt(r, "sid")
+	if err != nil {
+		return nil, err
+	}
+	sys := s.systems[id]
+	if sys == nil {
+		return nil, fmt.Errorf("No such system - sid = %d", id)
+	}
+	return sys, nil
+}
+
+func (s *Server) getSystemID(r *http.Request) (any, error) {
+	name := r.URL.Query().Get("name")
+	list := []map[string]any{}
+	for _, sys := range s.sortedSystems() {
+		if sys.Name == name {
+			list = append(list, systemJSON(sys))
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) sortedSystems() []*System {
+	systems := make([]*System, 0, len(s.systems))
+	for _, sys := range s.systems {
+		systems = append(systems, sys)
+	}
+	sort.Slice(systems, func(i, j int) bool { return systems[i].ID < systems[j].ID })
+	return systems
+}
+
+func (s *Server) getNetwork(r *http.Request) (any, error) {
+	sys, err := s.system(r)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"ip": sys.IP, "ip6": sys.IP6, "hostname": sys.Name}, nil
+}
+
+func (s *Server) getSystemDetails(r *http.Request) (any, error) {
+	sys, err := s.system(r)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"id": sys.ID, "profile_name": sys.Name, "hostname": sys.Name, "lock_status": sys.Locked, "contact_method": "default"}, nil
+}
+
+func (s *Server) listSystems(*http.Request) (any, error) {
+	list := []map[string]any{}
+	for _, sys := range s.sortedSystems() {
+		list = append(list, systemJSON(sys))
+	}
+	return list, nil
+}
+
+func (s *Server) setLockStatus(r *http.Request) (any, error) {
+	var req struct {
+		SID        int  `json:"sid"`
+		LockStatus bool `json:"lockStatus"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	sys := s.systems[req.SID]
+	if sys == nil {
+		return nil, fmt.Errorf("No such system - sid = %d", req.SID)
+	}
+	sys.Locked = req.LockStatus
+	return 1, nil
+}
+
+func (s *Server) deleteSystem(r *http.Request) (any, error) {
+	var req struct {
+		SID int `json:"sid"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if s.systems[req.SID] == nil {
+		return nil, fmt.Errorf("No such system - sid = %d", req.SID)
+	}
+	delete(s.systems, req.SID)
+	for name, members := range s.members {
+		s.members[name] = slices.DeleteFunc(members, func(m int) bool { return m == req.SID })
+	}
+	return 1, nil
+}
+
+func (s *Server) user(login string) (*User, error) {
+	u := s.users[login]
+	if u == nil {
+		return nil, fmt.Errorf("Could not find user %s", login)
+	}
+	return u, nil
+}
+
+func (s *Server) listUsers(*http.Request) (any, error) {
+	list := []map[string]any{}
+	for login := range s.users {
+		list = append(list, map[string]any{"login": login})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i]["login"].(string) < list[j]["login"].(string) })
+	return list, nil
+}
+
+func (s *Server) getUserDetails(r *http.Request) (any, error) {
+	u, err := s.user(r.URL.Query().Get("login"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"first_name": u.FirstName, "last_name": u.LastName, "email": u.Email}, nil
+}
+
+func (s *Server) createUser(r *http.Request) (any, error) {
+	var req appapi.SumaApiAddUser
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if req.Login == "" || req.Password == "" {
+		return nil, errors.New("login and password must not be empty")
+	}
+	if s.users[req.Login] != nil {
+		return nil, fmt.Errorf("A user with the login %s already exists", req.Login)
+	}
+	s.users[req.Login] = &User{SumaUser: appapi.SumaUser{
+		Login: req.Login, Password: req.Password, FirstName: req.FirstName, LastName: req.LastName, Email: req.Email,
+	}}
+	return 1, nil
+}
+
+func (s *Server) setUserDetails(r *http.Request) (any, error) {
+	var req struct {
+		Login   string            `json:"login"`
+		Details map[string]string `json:"details"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	u, err := s.user(req.Login)
+	if err != nil {
+		return nil, err
+	}
+	for field, value := range req.Details {
+		switch field {
+		case "first_name":
+			u.FirstName = value
+		case "last_name":
+			u.LastName = value
+		case "email":
+			u.Email = value
+		case "password":
+			u.Password = value
+		default:
+			return nil, fmt.Errorf("unknown user detail %s", field)
+		}
+	}
+	return 1, nil
+}
+
+func (s *Server) deleteUser(r *http.Request) (any, error) {
+	var req struct {
+		Login string `json:"login"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if _, err := s.user(req.Login); err != nil {
+		return nil, err
+	}
+	delete(s.users, req.Login)
+	return 1, nil
+}
+
+func (s *Server) listRoles(r *http.Request) (any, error) {
+	u, err := s.user(r.URL.Query().Get("login"))
+	if err != nil {
+		return nil, err
+	}
+	return append([]appapi.Role{}, u.Roles...), nil
+}
+
+func (s *Server) changeRole(add bool) apiMethod {
+	return func(r *http.Request) (any, error) {
+		var req struct {
+			Login string      `json:"login"`
+			Role  appapi.Role `json:"role"`
+		}
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		u, err := s.user(req.Login)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(s.assignableRoles, req.Role) {
+			return nil, fmt.Errorf("Invalid role: %s", req.Role)
+		}
+		u.Roles = slices.DeleteFunc(u.Roles, func(role appapi.Role) bool { return role == req.Role })
+		if add {
+			u.Roles = append(u.Roles, req.Role)
+		}
+		return 1, nil
+	}
+}
+
+func (s *Server) listAssignedGroups(r *http.Request) (any, error) {
+	u, err := s.user(r.URL.Query().Get("login"))
+	if err != nil {
+		return nil, err
+	}
+	list := []map[string]any{}
+	for _, name := range u.Groups {
+		if g := s.groups[name]; g != nil {
+			list = append(list, groupJSON(s.groupDetails(g)))
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) addAssignedGroups(r *http.Request) (any, error) {
+	var req struct {
+		Login            string   `json:"login"`
+		ServerGroupNames []string `json:"serverGroupNames"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	u, err := s.user(req.Login)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range req.ServerGroupNames {
+		if _, err := s.group(name); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range req.ServerGroupNames {
+		if !slices.Contains(u.Groups, name) {
+			u.Groups = append(u.Groups, name)
+		}
+	}
+	return 1, nil
+}
+
+func (s *Server) listActivationKeys(*http.Request) (any, error) {
+	list := []map[string]any{}
+	for _, k := range s.keys {
+		list = append(list, map[string]any{
+			"key":                k.Key,
+			"description":        k.Description,
+			"base_channel_label": k.BaseChannelLabel,
+			"usage_limit":        k.UsageLimit,
+			"universal_default":  k.UniversalDefault,
+			"entitlements":       append([]string{}, k.Entitlements...),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i]["key"].(string) < list[j]["key"].(string) })
+	return list, nil
+}
+
+func (s *Server) key(key string) (*appapi.ActivationKey, error) {
+	k := s.keys[key]
+	if k == nil {
+		return nil, fmt.Errorf("Could not find activation key: %s", key)
+	}
+	return k, nil
+}
+
+func (s *Server) createActivationKey(r *http.Request) (any, error) {
+	var req struct {
+		Key              string   `json:"key"`
+		Description      string   `json:"description"`
+		BaseChannelLabel string   `json:"baseChannelLabel"`
+		UsageLimit       int      `json:"usageLimit"`
+		UniversalDefault bool     `json:"universalDefault"`
+		Entitlements     []string `json:"entitlements"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if req.Key == "" {
+		b := make([]byte, 16)
+		_, _ = rand.Read(b)
+		req.Key = hex.EncodeToString(b)
+	}
+	key := fmt.Sprintf("%d-%s", DefaultOrg, req.Key)
+	if s.keys[key] != nil {
+		return nil, fmt.Errorf("Activation key %s already exists", key)
+	}
+	s.keys[key] = &appapi.ActivationKey{
+		Key:              key,
+		Description:      req.Description,
+		BaseChannelLabel: req.BaseChannelLabel,
+		UsageLimit:       req.UsageLimit,
+		UniversalDefault: req.UniversalDefault,
+		Entitlements:     req.Entitlements,
+	}
+	return key, nil
+}
+
+func (s *Server) setActivationKeyDetails(r *http.Request) (any, error) {
+	var req struct {
+		Key     string `json:"key"`
+		Details struct {
+			Description         *string `json:"description"`
+			BaseChannelLabel    *string `json:"base_channel_label"`
+			UsageLimit          *int    `json:"usage_limit"`
+			UnlimitedUsageLimit bool    `json:"unlimited_usage_limit"`
+			UniversalDefault    *bool   `json:"universal_default"`
+		} `json:"details"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	k, err := s.key(req.Key)
+	if err != nil {
+		return nil, err
+	}
+	d := req.Details
+	if d.Description != nil {
+		k.Description = *d.Description
+	}
+	if d.BaseChannelLabel != nil {
+		k.BaseChannelLabel = *d.BaseChannelLabel
+	}
+	if d.UsageLimit != nil {
+		k.UsageLimit = *d.UsageLimit
+	}
+	if d.UnlimitedUsageLimit {
+		k.UsageLimit = 0
+	}
+	if d.UniversalDefault != nil {
+		k.UniversalDefault = *d.UniversalDefault
+	}
+	return 1, nil
+}
+
+func (s *Server) deleteActivationKey(r *http.Request) (any, error) {
+	var req struct {
+		Key string `json:"key"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if _, err := s.key(req.Key); err != nil {
+		return nil, err
+	}
+	delete(s.keys, req.Key)
+	return 1, nil
+}
@@ -0,0 +1,151 @@
+package sumatest
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/arzieg/appapi"
+)
+
+func newClient(t *testing.T, s *Server) *appapi.SumaClient {
+	t.Helper()
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestServerSystems(t *testing.T) {
+	s := NewServer(Dataset{
+		Groups: []appapi.SystemGroup{{Name: "web", Description: "Web servers"}},
+		Systems: []System{
+			{ID: 1001, Name: "web1.example.com", IP: "10.0.0.5"},
+			{ID: 1002, Name: "web2.example.com", IP: "10.0.0.6"},
+			{ID: 1003, Name: "other.example.com", IP: "192.168.1.5"},
+		},
+		Members: map[string][]int{"web": {1001}},
+	})
+	defer s.Close()
+	c := newClient(t, s)
+
+	if c.Version.Raw != DefaultVersion {
+		t.Errorf("version = %q, want %q", c.Version.Raw, DefaultVersion)
+	}
+
+	report, err := c.EnsureGroupMembers("web", []string{"web2.example.com"}, "10.0.0.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Changed() {
+		t.Error("the members must have changed")
+	}
+	if got := s.Members("web"); !slices.Equal(got, []int{1002}) {
+		t.Errorf("members = %v, want [1002]", got)
+	}
+
+	if _, err := c.AddSystem("other.example.com", "web", "10.0.0.0/24"); err == nil {
+		t.Error("a system outside of the network must not be added")
+	}
+	if _, err := c.AddSystem("missing.example.com", "web", "10.0.0.0/24"); err == nil {
+		t.Error("an unregistered system must not be added")
+	}
+
+	if _, err := c.DeleteSystem("web2.example.com", "10.0.0.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Systems()) != 2 || len(s.Members("web")) != 0 {
+		t.Errorf("systems = %v, members = %v after delete", s.Systems(), s.Members("web"))
+	}
+}
+
+func TestServerResources(t *testing.T) {
+	s := NewServer(Dataset{
+		Users: []User{{SumaUser: appapi.SumaUser{Login: "ops", Password: "secret"}, Roles: []appapi.Role{"org_admin"}}},
+	})
+	defer s.Close()
+	c := newClient(t, s)
+
+	g := &appapi.SystemGroup{Name: "db", Description: "Databases"}
+	if err := g.Create(c); err != nil {
+		t.Fatal(err)
+	}
+	if g.GroupID == 0 {
+		t.Error("the created group has no ID")
+	}
+
+	u := &appapi.SumaUser{Login: "db", Password: "initial", Email: "db@example.com"}
+	if err := u.Create(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddRole("db", "system_group_admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddRole("db", "superuser"); err == nil {
+		t.Error("a role which is not assignable must be rejected")
+	}
+
+	k := &appapi.ActivationKey{Key: "db", Description: "Databases"}
+	if err := k.Create(c); err != nil {
+		t.Fatal(err)
+	}
+	if k.Key != "1-db" {
+		t.Errorf("key = %q, want 1-db", k.Key)
+	}
+
+	users := s.Users()
+	if len(users) != 2 || users[0].Login != "db" || !slices.Equal(users[0].Roles, []appapi.Role{"system_group_admin"}) {
+		t.Errorf("users = %+v", users)
+	}
+	if groups := s.Groups(); len(groups) != 1 || groups[0].Description != "Databases" {
+		t.Errorf("groups = %+v", groups)
+	}
+	if keys := s.ActivationKeys(); len(keys) != 1 || keys[0].Key != "1-db" {
+		t.Errorf("keys = %+v", keys)
+	}
+
+	if err := (&appapi.SystemGroup{Name: "missing"}).Read(c); !errors.Is(err, appapi.ErrNotFound) {
+		t.Errorf("reading a missing group: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestServerFail(t *testing.T) {
+	s := NewServer(Dataset{})
+	defer s.Close()
+	c := newClient(t, s)
+
+	s.Fail("systemgroup/listAllGroups", "database is down")
+	if _, err := c.SystemGroups(); err == nil || !strings.Contains(err.Error(), "database is down") {
+		t.Errorf("err = %v, want the injected failure", err)
+	}
+	s.Fail("systemgroup/listAllGroups", "")
+	if _, err := c.SystemGroups(); err != nil {
+		t.Errorf("the failure was not removed: %v", err)
+	}
+	if calls := s.Calls(); calls[0] != "auth/login" || calls[len(calls)-1] != "systemgroup/listAllGroups" {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestServerSession(t *testing.T) {
+	s := NewServer(Dataset{})
+	defer s.Close()
+
+	resp, err := http.Get(s.URL + "/rhn/manager/api/systemgroup/listAllGroups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status without session = %d, want 401", resp.StatusCode)
+	}
+
+	creds := appapi.NewStaticCredentialProvider(appapi.Credentials{SumaUsername: DefaultLogin, SumaPassword: "wrong"})
+	if _, err := appapi.NewSumaClient(s.URL, creds, appapi.WithInsecure(true)); err == nil {
+		t.Error("login with a wrong password must fail")
+	}
+}
@@ -0,0 +1,74 @@
+package appapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SumaLoginWithToken login to the SUSE Manager with a long-lived API token, e.g. a hub token,
+// instead of a username and password, so CI systems do not need the password of the org admin.
+// The token is exchanged for a session cookie like a login. A server without token authentication
+// returns an error matching ErrUnsupportedByServer, see FeatureTokenAuth.
+func SumaLoginWithToken(token, susemgr string, verbose bool, opts ...Option) (sessioncookie string, err error) {
+	sessioncookie, _, err = sumaLoginWithToken(token, susemgr, verbose, opts...)
+	return sessioncookie, err
+}
+
+// sumaLoginWithToken returns the session cookie and its Max-Age in seconds
+func sumaLoginWithToken(token, susemgr string, verbose bool, opts ...Option) (sessioncookie string, maxAge int, err error) {
+
+	apiMethod := fmt.Sprintf("%s/rhn/manager/api/auth/loginWithToken", susemgr)
+	if verbose {
+		logDebugf("SUMAAPI SumaLoginWithToken: apiMethod = %s", apiMethod)
+	}
+
+	payloadBytes, err := json.Marshal(struct {
+		Token string `json:"token"`
+	}{token})
+	if err != nil {
+		return "", 0, fmt.Errorf("error marshalling payload: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, apiMethod, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return "", 0, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("error sending request: %v", err)
+	}
+	defer func() {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// servers before token authentication do not know the method
+		return "", 0, withRequestID(resp, &UnsupportedError{Feature: FeatureTokenAuth})
+	default:
+		if verbose {
+			logDebugf("SUMAAPI SumaLoginWithToken: HTTP Request failed: HTTP %d", resp.StatusCode)
+		}
+		return "", 0, statusError(resp)
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "pxt-session-cookie" && cookie.MaxAge > 0 {
+			sessioncookie = cookie.Value
+			maxAge = cookie.MaxAge
+		}
+	}
+	if sessioncookie == "" {
+		return "", 0, fmt.Errorf("login to %s with token failed, got no session cookie", susemgr)
+	}
+	return sessioncookie, maxAge, nil
+}
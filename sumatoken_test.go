@@ -0,0 +1,68 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTokenServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/auth/loginWithToken" {
+			t.Errorf("unexpected call of %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		var payload struct {
+			Token string `json:"token"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if payload.Token != "ci-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "pxt-session-cookie", Value: "cookie", MaxAge: 3600})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSumaLoginWithToken(t *testing.T) {
+	server := newTokenServer(t, http.StatusOK)
+
+	cookie, err := SumaLoginWithToken("ci-token", server.URL, false)
+	if err != nil || cookie != "cookie" {
+		t.Errorf("SumaLoginWithToken() = %q, %v, want the session cookie", cookie, err)
+	}
+
+	_, err = SumaLoginWithToken("revoked", server.URL, false)
+	if err == nil || errors.Is(err, ErrUnsupportedByServer) {
+		t.Errorf("SumaLoginWithToken() with a revoked token returned %v, want a login error", err)
+	}
+}
+
+func TestSumaLoginWithTokenUnsupported(t *testing.T) {
+	server := newTokenServer(t, http.StatusNotFound)
+
+	_, err := SumaLoginWithToken("ci-token", server.URL, false)
+	if !errors.Is(err, ErrUnsupportedByServer) {
+		t.Errorf("SumaLoginWithToken() = %v, want ErrUnsupportedByServer", err)
+	}
+}
+
+func TestSumaLoginWithProviderToken(t *testing.T) {
+	server := newTokenServer(t, http.StatusOK)
+
+	p := NewStaticCredentialProvider(Credentials{SumaUsername: "ci", SumaToken: "ci-token"})
+	cookie, err := SumaLoginWithProvider(p, server.URL, false)
+	if err != nil || cookie != "cookie" {
+		t.Errorf("SumaLoginWithProvider() = %q, %v, want the session cookie of the token login", cookie, err)
+	}
+}
@@ -0,0 +1,260 @@
+package appapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultHostnameOutput = "hostname"
+	defaultIPOutput       = "ip"
+	buildingBlockSucceded = "SUCCEEDED"
+)
+
+// SyncOptions configure a Meshstack to SUMA synchronisation
+type SyncOptions struct {
+	// ProjectID is the Meshstack project whose VMs are synchronised
+	ProjectID string
+	// Group is the SUMA system group and user, defaults to ProjectID
+	Group string
+	// GroupPassword is the password of the SUMA user, if empty no user is created
+	GroupPassword string
+	// Network is the permitted network of the systems, defaults to the first network of the SumaClient
+	Network string
+	// DefinitionUUIDs select the building block definitions which are VMs. If empty, every
+	// building block with a hostname output is a VM.
+	DefinitionUUIDs []string
+	// HostnameOutput and IPOutput are the output keys of the VM building blocks, default "hostname" and "ip".
+	// A VM with an IP output is only synchronised if SUMA has the same IP for the hostname.
+	HostnameOutput string
+	IPOutput       string
+	// Notifier is sent the summary of Sync, if set
+	Notifier Notifier
+	// Context cancels the calls of the sync, e.g. with the request of a server, if set
+	Context context.Context
+}
+
+// callOptions returns the options of the calls of the sync
+func (o SyncOptions) callOptions() []Option {
+	if o.Context == nil {
+		return nil
+	}
+	return []Option{WithContext(o.Context)}
+}
+
+// SyncHost is a VM found in a Meshstack project
+type SyncHost struct {
+	BuildingBlock string
+	Hostname      string
+	IP            string
+}
+
+// SyncReport lists the VMs found in Meshstack and the changes made in SUMA
+type SyncReport struct {
+	ProjectID   string
+	Hosts       []SyncHost
+	Skipped     []string
+	Group       GroupChangeReport
+	UserCreated bool
+}
+
+// Sync lists the VM building blocks of a Meshstack project, extracts the hostnames and IPs from
+// their outputs and makes the SUMA system group of the project contain exactly these systems.
+// The system group and the user of the project are created if they do not exist. Building blocks
+// which are not deployed, e.g. still updating, and VMs whose IP output is not their IP in SUMA are
+// skipped and reported. Skipped VMs which are members of the group stay in it.
+func Sync(suma *SumaClient, ms *MsClient, opts SyncOptions) (report SyncReport, err error) {
+
+	defer func() { notify(opts.Notifier, SyncSummary(report, err)) }()
+
+	plan, report, err := PlanSync(suma, ms, opts)
+	if err != nil {
+		return report, err
+	}
+	return report, plan.Apply()
+}
+
+// PlanSync returns the plan of Sync without changing anything
+func PlanSync(suma *SumaClient, ms *MsClient, opts SyncOptions) (plan *Plan, report SyncReport, err error) {
+
+	if opts.ProjectID == "" {
+		return nil, report, fmt.Errorf("sync needs a meshstack project")
+	}
+	if opts.Group == "" {
+		opts.Group = opts.ProjectID
+	}
+	if opts.Network == "" && len(suma.Networks) > 0 {
+		opts.Network = suma.Networks[0]
+	}
+	if opts.HostnameOutput == "" {
+		opts.HostnameOutput = defaultHostnameOutput
+	}
+	if opts.IPOutput == "" {
+		opts.IPOutput = defaultIPOutput
+	}
+
+	report.ProjectID = opts.ProjectID
+	verbose := suma.Verbose || ms.Verbose
+
+	if verbose {
+		logDebugf("SYNC PlanSync: Enter function")
+		logDebugf("SYNC PlanSync: ==============")
+		defer logDebugf("SYNC PlanSync: Leave function")
+	}
+
+	hosts, skipped, err := syncListHosts(ms, opts)
+	if err != nil {
+		return nil, report, err
+	}
+
+	cookie := suma.SessionCookie()
+	callOpts := opts.callOptions()
+	sumaVerbose, sumaOpts := suma.options(callOpts)
+	plan = &Plan{}
+	plan.afterApply(func() {
+		suma.cache.Invalidate(cacheSumaGroups, cacheSumaUsers, cacheSumaGroupSystems+opts.Group)
+	})
+
+	groups, err := suma.SystemGroups(callOpts...)
+	if err != nil {
+		return nil, report, err
+	}
+	users, err := suma.Users(callOpts...)
+	if err != nil {
+		return nil, report, err
+	}
+
+	// a new group has no members
+	var current []SystemInfo
+	if slices.Contains(groups, opts.Group) {
+		current, err = suma.GroupSystems(opts.Group, callOpts...)
+		if err != nil {
+			return nil, report, err
+		}
+	} else {
+		plan.add(fmt.Sprintf("could not create system group %s", opts.Group), func() error {
+			return sumaCreateSystemGroup(suma.suma(), cookie, suma.URL, opts.Group, "Meshstack project "+opts.ProjectID, sumaVerbose, sumaOpts...)
+		}, PlannedChange{Action: PlanCreate, Resource: "suma system group", Name: opts.Group})
+	}
+
+	if opts.GroupPassword != "" && !containsFold(users, opts.Group) {
+		report.UserCreated = true
+		plan.add(fmt.Sprintf("could not add user %s", opts.Group), func() error {
+			_, err := sumaAddUserResult(suma.suma(), cookie, opts.Group, opts.GroupPassword, suma.URL, sumaVerbose, sumaOpts...)
+			return err
+		}, PlannedChange{Action: PlanCreate, Resource: "suma user", Name: opts.Group})
+	}
+
+	// hostnames of Meshstack outputs are often short or mixed case
+	hostnames := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host.Hostname = suma.Hostnames.Normalize(host.Hostname)
+		if host.IP != "" {
+			matches, err := syncIPMatches(suma.suma(), cookie, suma.URL, host, opts.Network, sumaVerbose, sumaOpts...)
+			if err != nil {
+				return nil, report, err
+			}
+			if !matches {
+				logWarnf("building block %s is skipped, %s has not the IP %s in SUSE Manager", host.BuildingBlock, host.Hostname, host.IP)
+				skipped = append(skipped, host)
+				continue
+			}
+		}
+		report.Hosts = append(report.Hosts, host)
+		hostnames = append(hostnames, host.Hostname)
+	}
+
+	// the systems of skipped VMs are neither added to nor removed from the group
+	var keep []string
+	for _, host := range skipped {
+		report.Skipped = append(report.Skipped, host.BuildingBlock)
+		if host.Hostname != "" {
+			keep = append(keep, suma.Hostnames.Normalize(host.Hostname))
+		}
+	}
+	var members, kept []SystemInfo
+	for _, system := range current {
+		if containsFold(keep, suma.Hostnames.Normalize(system.Name)) {
+			kept = append(kept, system)
+		} else {
+			members = append(members, system)
+		}
+	}
+
+	if verbose {
+		logDebugf("SYNC PlanSync: found %d VM(s) in project %s, skipped %v", len(report.Hosts), opts.ProjectID, report.Skipped)
+	}
+
+	report.Group, err = sumaPlanGroupMembers(suma.suma(), plan, cookie, suma.URL, opts.Group, members, hostnames, opts.Network, sumaVerbose, sumaOpts...)
+	if err != nil {
+		return nil, report, err
+	}
+	for _, system := range kept {
+		report.Group.Unchanged = append(report.Group.Unchanged, system.Name)
+	}
+
+	return plan, report, nil
+}
+
+// syncIPMatches reports if the IP output of a VM is the IP of its system in SUMA
+func syncIPMatches(api sumaAPI, sessioncookie, susemgr string, host SyncHost, network string, verbose bool, opts ...Option) (bool, error) {
+	want := net.ParseIP(host.IP)
+	if want == nil {
+		return false, nil
+	}
+
+	id, err := sumaGetSystemIDInNetwork(api, sessioncookie, susemgr, host.Hostname, network, verbose, opts...)
+	if err != nil {
+		return false, err
+	}
+	getIP := api.GetSystemIP
+	if want.To4() == nil {
+		getIP = api.GetSystemIP6
+	}
+	ip, err := getIP(sessioncookie, susemgr, id, verbose, opts...)
+	if err != nil {
+		return false, err
+	}
+	return want.Equal(net.ParseIP(ip)), nil
+}
+
+// syncListHosts returns the deployed VMs of the project and the skipped building blocks, with the
+// hostname if they have one
+func syncListHosts(ms *MsClient, opts SyncOptions) (hosts, skipped []SyncHost, err error) {
+
+	bbs, err := ms.ListBuildingBlocks(opts.ProjectID, opts.callOptions()...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not list building blocks of project %s: %v", opts.ProjectID, err)
+	}
+
+	for _, bb := range bbs {
+		details, err := ms.GetBuildingBlockDetails(bb.UUID, opts.callOptions()...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get building block %s: %v", bb.Name, err)
+		}
+
+		if len(opts.DefinitionUUIDs) > 0 && !slices.Contains(opts.DefinitionUUIDs, details.DefinitionUUID) {
+			continue
+		}
+
+		hostname := strings.TrimSpace(details.Outputs[opts.HostnameOutput])
+		if details.Status != buildingBlockSucceded || hostname == "" {
+			// selected VMs without a hostname are not deployed yet, other building blocks are no VMs
+			if len(opts.DefinitionUUIDs) > 0 || hostname != "" {
+				skipped = append(skipped, SyncHost{BuildingBlock: bb.Name, Hostname: hostname})
+			}
+			continue
+		}
+
+		hosts = append(hosts, SyncHost{
+			BuildingBlock: bb.Name,
+			Hostname:      hostname,
+			IP:            strings.TrimSpace(details.Outputs[opts.IPOutput]),
+		})
+	}
+
+	return hosts, skipped, nil
+}
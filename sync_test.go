@@ -0,0 +1,222 @@
+package appapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSync(t *testing.T) {
+	// building blocks of the project: two deployed VMs, one VM in progress and a non VM block
+	blocks := map[string]string{
+		"bb1": `{"metadata": {"uuid": "bb1", "definitionUuid": "vm"}, "spec": {"displayName": "vm1"},
+			"status": {"status": "SUCCEEDED", "outputs": [{"key": "hostname", "value": "host1"}, {"key": "ip", "value": "192.168.1.1"}]}}`,
+		"bb2": `{"metadata": {"uuid": "bb2", "definitionUuid": "vm"}, "spec": {"displayName": "vm2"},
+			"status": {"status": "SUCCEEDED", "outputs": [{"key": "hostname", "value": " host2 "}]}}`,
+		"bb3": `{"metadata": {"uuid": "bb3", "definitionUuid": "vm"}, "spec": {"displayName": "vm3"},
+			"status": {"status": "IN_PROGRESS", "outputs": []}}`,
+		"bb4": `{"metadata": {"uuid": "bb4", "definitionUuid": "bucket"}, "spec": {"displayName": "bucket"},
+			"status": {"status": "SUCCEEDED", "outputs": [{"key": "bucket", "value": "b1"}]}}`,
+	}
+
+	msmux := http.NewServeMux()
+	msmux.HandleFunc("/api/meshobjects/meshbuildingblocks", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("projectIdentifier"); got != "project" {
+			t.Errorf("projectIdentifier = %q, want project", got)
+		}
+		fmt.Fprint(w, `{"_embedded": {"meshBuildingBlocks": [
+			{"metadata": {"uuid": "bb1"}, "spec": {"displayName": "vm1"}},
+			{"metadata": {"uuid": "bb2"}, "spec": {"displayName": "vm2"}},
+			{"metadata": {"uuid": "bb3"}, "spec": {"displayName": "vm3"}},
+			{"metadata": {"uuid": "bb4"}, "spec": {"displayName": "bucket"}}]}}`)
+	})
+	msmux.HandleFunc("/api/meshobjects/meshbuildingblocks/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, blocks[r.PathValue("uuid")])
+	})
+	msserver := httptest.NewServer(msmux)
+	defer msserver.Close()
+
+	var changes []string
+	systems := map[string]int{"host1": 1, "host2": 2, "host3": 3}
+	sumamux := newFakeGroupMux(t, systems, []int{3}, &changes)
+	groups := `[]`
+	sumamux.HandleFunc("/rhn/manager/api/systemgroup/listAllGroups", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success": true, "result": %s}`, groups)
+	})
+	sumamux.HandleFunc("/rhn/manager/api/systemgroup/create", func(w http.ResponseWriter, r *http.Request) {
+		changes = append(changes, "create group")
+		fmt.Fprint(w, `{"success": true}`)
+	})
+	sumamux.HandleFunc("/rhn/manager/api/user/listUsers", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": []}`)
+	})
+	sumamux.HandleFunc("/rhn/manager/api/user/create", func(w http.ResponseWriter, r *http.Request) {
+		changes = append(changes, "create user")
+		fmt.Fprint(w, `{"success": true, "result": 1}`)
+	})
+	sumaserver := httptest.NewServer(sumamux)
+	defer sumaserver.Close()
+
+	suma := &SumaClient{URL: sumaserver.URL, Networks: []string{"192.168.1.0/24"}, sessioncookie: "cookie"}
+	ms := &MsClient{URL: msserver.URL, token: "token"}
+
+	report, err := Sync(suma, ms, SyncOptions{ProjectID: "project", GroupPassword: "secret"})
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	wantHosts := []SyncHost{
+		{BuildingBlock: "vm1", Hostname: "host1", IP: "192.168.1.1"},
+		{BuildingBlock: "vm2", Hostname: "host2"},
+	}
+	if !reflect.DeepEqual(report.Hosts, wantHosts) {
+		t.Errorf("Hosts = %+v, want %+v", report.Hosts, wantHosts)
+	}
+	if len(report.Skipped) != 0 {
+		t.Errorf("Skipped = %v, want none", report.Skipped)
+	}
+	if !report.UserCreated {
+		t.Errorf("UserCreated = false, want true")
+	}
+	if !reflect.DeepEqual(report.Group.Added, []string{"host1", "host2"}) || len(report.Group.Removed) != 0 {
+		t.Errorf("Group = %+v", report.Group)
+	}
+
+	wantChanges := []string{"create group", "create user", "add=true [1 2]"}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("changes = %v, want %v", changes, wantChanges)
+	}
+
+	// an existing group is reconciled, with definitions selected VMs which are not deployed yet are reported
+	changes = nil
+	groups = `[{"name": "project"}]`
+	report, err = Sync(suma, ms, SyncOptions{ProjectID: "project", DefinitionUUIDs: []string{"vm"}})
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if !reflect.DeepEqual(report.Skipped, []string{"vm3"}) {
+		t.Errorf("Skipped = %v, want [vm3]", report.Skipped)
+	}
+	wantChanges = []string{"add=true [1 2]", "add=false [3]"}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("changes = %v, want %v", changes, wantChanges)
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	msmux := http.NewServeMux()
+	msmux.HandleFunc("/api/meshobjects/meshbuildingblocks", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_embedded": {"meshBuildingBlocks": [{"metadata": {"uuid": "bb1"}, "spec": {"displayName": "vm1"}}]}}`)
+	})
+	msmux.HandleFunc("/api/meshobjects/meshbuildingblocks/bb1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"metadata": {"uuid": "bb1"}, "status": {"status": "SUCCEEDED", "outputs": [{"key": "hostname", "value": "host1"}]}}`)
+	})
+	msserver := httptest.NewServer(msmux)
+	defer msserver.Close()
+
+	var changes []string
+	sumamux := newFakeGroupMux(t, map[string]int{"host1": 1, "host3": 3}, []int{3}, &changes)
+	sumamux.HandleFunc("/rhn/manager/api/systemgroup/listAllGroups", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"name": "project"}]}`)
+	})
+	sumamux.HandleFunc("/rhn/manager/api/user/listUsers", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"login": "project"}]}`)
+	})
+	sumaserver := httptest.NewServer(sumamux)
+	defer sumaserver.Close()
+
+	suma := &SumaClient{URL: sumaserver.URL, Networks: []string{"192.168.1.0/24"}, sessioncookie: "cookie"}
+	ms := &MsClient{URL: msserver.URL, token: "token"}
+
+	plan, _, err := PlanSync(suma, ms, SyncOptions{ProjectID: "project", GroupPassword: "secret"})
+	if err != nil {
+		t.Fatalf("PlanSync() error = %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("PlanSync() made changes %v", changes)
+	}
+
+	want := "+ suma system group member host1 (group project)\n" +
+		"- suma system group member host3 (group project)\n" +
+		"Plan: 1 to create, 0 to update, 1 to delete.\n"
+	if got := plan.String(); got != want {
+		t.Errorf("plan = %q, want %q", got, want)
+	}
+
+	if err := plan.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if want := []string{"add=true [1]", "add=false [3]"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+
+	// the calls are sent with the context of the options
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := PlanSync(suma, ms, SyncOptions{ProjectID: "project", Context: ctx}); err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("PlanSync() with a canceled context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSyncKeepsSkippedSystems(t *testing.T) {
+	// vm2 failed to update and vm3 reports a stale IP, their systems stay in the group
+	blocks := map[string]string{
+		"bb1": `{"metadata": {"uuid": "bb1"}, "spec": {"displayName": "vm1"},
+			"status": {"status": "SUCCEEDED", "outputs": [{"key": "hostname", "value": "host1"}, {"key": "ip", "value": "192.168.1.1"}]}}`,
+		"bb2": `{"metadata": {"uuid": "bb2"}, "spec": {"displayName": "vm2"},
+			"status": {"status": "FAILED", "outputs": [{"key": "hostname", "value": "HOST2"}]}}`,
+		"bb3": `{"metadata": {"uuid": "bb3"}, "spec": {"displayName": "vm3"},
+			"status": {"status": "SUCCEEDED", "outputs": [{"key": "hostname", "value": "host3"}, {"key": "ip", "value": "192.168.1.99"}]}}`,
+	}
+	msmux := http.NewServeMux()
+	msmux.HandleFunc("/api/meshobjects/meshbuildingblocks", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_embedded": {"meshBuildingBlocks": [
+			{"metadata": {"uuid": "bb1"}, "spec": {"displayName": "vm1"}},
+			{"metadata": {"uuid": "bb2"}, "spec": {"displayName": "vm2"}},
+			{"metadata": {"uuid": "bb3"}, "spec": {"displayName": "vm3"}}]}}`)
+	})
+	msmux.HandleFunc("/api/meshobjects/meshbuildingblocks/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, blocks[r.PathValue("uuid")])
+	})
+	msserver := httptest.NewServer(msmux)
+	defer msserver.Close()
+
+	var changes []string
+	systems := map[string]int{"host1": 1, "host2": 2, "host3": 3, "host4": 4}
+	sumamux := newFakeGroupMux(t, systems, []int{2, 3, 4}, &changes)
+	sumamux.HandleFunc("/rhn/manager/api/systemgroup/listAllGroups", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"name": "project"}]}`)
+	})
+	sumamux.HandleFunc("/rhn/manager/api/user/listUsers", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "result": [{"login": "project"}]}`)
+	})
+	sumaserver := httptest.NewServer(sumamux)
+	defer sumaserver.Close()
+
+	suma := &SumaClient{URL: sumaserver.URL, Networks: []string{"192.168.1.0/24"}, sessioncookie: "cookie"}
+	ms := &MsClient{URL: msserver.URL, token: "token"}
+
+	report, err := Sync(suma, ms, SyncOptions{ProjectID: "project"})
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if want := []SyncHost{{BuildingBlock: "vm1", Hostname: "host1", IP: "192.168.1.1"}}; !reflect.DeepEqual(report.Hosts, want) {
+		t.Errorf("Hosts = %+v, want %+v", report.Hosts, want)
+	}
+	if want := []string{"vm2", "vm3"}; !reflect.DeepEqual(report.Skipped, want) {
+		t.Errorf("Skipped = %v, want %v", report.Skipped, want)
+	}
+	sort.Strings(report.Group.Unchanged)
+	if want := []string{"host2", "host3"}; !reflect.DeepEqual(report.Group.Unchanged, want) {
+		t.Errorf("Unchanged = %v, want %v", report.Group.Unchanged, want)
+	}
+	if want := []string{"add=true [1]", "add=false [4]"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+}
@@ -0,0 +1,114 @@
+package appapi
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// TransportStats are the connection statistics of the API calls, e.g. to find out if a mass
+// onboarding reuses its connections or loads SUMA with TLS handshakes. Every attempt of a call
+// counts, the retries included.
+//
+//	before := appapi.ReadTransportStats()
+//	report := suma.BulkAddSystems(hostnames, group, network, 20)
+//	stats := appapi.ReadTransportStats().Sub(before)
+//	log.Printf("%d of %d connections reused, %v in TLS handshakes", stats.ReusedConns, stats.Conns(), stats.TLSTime)
+//
+// Many new connections with a high concurrency ask for a higher MaxIdleConnsPerHost.
+type TransportStats struct {
+	NewConns      int64
+	ReusedConns   int64
+	DNSLookups    int64
+	DNSTime       time.Duration
+	TLSHandshakes int64
+	TLSTime       time.Duration
+	// SharedCalls are the GET calls answered with the response of an identical call, see CoalesceReads
+	SharedCalls int64
+}
+
+// Conns returns the number of connections the calls got
+func (s TransportStats) Conns() int64 {
+	return s.NewConns + s.ReusedConns
+}
+
+// ReuseRatio returns the share of the calls which reused a connection, 0 without calls
+func (s TransportStats) ReuseRatio() float64 {
+	if s.Conns() == 0 {
+		return 0
+	}
+	return float64(s.ReusedConns) / float64(s.Conns())
+}
+
+// Sub returns the statistics since before
+func (s TransportStats) Sub(before TransportStats) TransportStats {
+	return TransportStats{
+		NewConns:      s.NewConns - before.NewConns,
+		ReusedConns:   s.ReusedConns - before.ReusedConns,
+		DNSLookups:    s.DNSLookups - before.DNSLookups,
+		DNSTime:       s.DNSTime - before.DNSTime,
+		TLSHandshakes: s.TLSHandshakes - before.TLSHandshakes,
+		TLSTime:       s.TLSTime - before.TLSTime,
+		SharedCalls:   s.SharedCalls - before.SharedCalls,
+	}
+}
+
+// transportStats are the statistics of all API calls since the start
+var transportStats struct {
+	newConns, reusedConns  atomic.Int64
+	dnsLookups, dnsTime    atomic.Int64
+	tlsHandshakes, tlsTime atomic.Int64
+	sharedCalls            atomic.Int64
+}
+
+// ReadTransportStats returns the connection statistics of all API calls since the start
+func ReadTransportStats() TransportStats {
+	return TransportStats{
+		NewConns:      transportStats.newConns.Load(),
+		ReusedConns:   transportStats.reusedConns.Load(),
+		DNSLookups:    transportStats.dnsLookups.Load(),
+		DNSTime:       time.Duration(transportStats.dnsTime.Load()),
+		TLSHandshakes: transportStats.tlsHandshakes.Load(),
+		TLSTime:       time.Duration(transportStats.tlsTime.Load()),
+		SharedCalls:   transportStats.sharedCalls.Load(),
+	}
+}
+
+// connTrace records the connection of a request in the transport statistics
+type connTrace struct {
+	reused   atomic.Bool
+	dnsStart atomic.Int64
+	tlsStart atomic.Int64
+}
+
+// withConnTrace returns the request with a trace of its connection
+func withConnTrace(req *http.Request) (*http.Request, *connTrace) {
+	ct := &connTrace{}
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			ct.reused.Store(info.Reused)
+			if info.Reused {
+				transportStats.reusedConns.Add(1)
+			} else {
+				transportStats.newConns.Add(1)
+			}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			ct.dnsStart.Store(time.Now().UnixNano())
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			transportStats.dnsLookups.Add(1)
+			transportStats.dnsTime.Add(time.Now().UnixNano() - ct.dnsStart.Load())
+		},
+		TLSHandshakeStart: func() {
+			ct.tlsStart.Store(time.Now().UnixNano())
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			transportStats.tlsHandshakes.Add(1)
+			transportStats.tlsTime.Add(time.Now().UnixNano() - ct.tlsStart.Load())
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), ct
+}
@@ -0,0 +1,45 @@
+package appapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransportStats(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "result": "5.0.3"}`))
+	}))
+	defer server.Close()
+	// the connections of other tests are not reused for the new server
+	before := ReadTransportStats()
+
+	for range 5 {
+		if _, err := SumaGetVersion("cookie", server.URL, false, WithInsecure(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := ReadTransportStats().Sub(before)
+	if stats.NewConns != 1 || stats.ReusedConns != 4 || stats.ReuseRatio() != 0.8 {
+		t.Errorf("stats = %+v, want 1 new and 4 reused connections", stats)
+	}
+	if stats.TLSHandshakes != 1 || stats.TLSTime <= 0 {
+		t.Errorf("stats = %+v, want 1 TLS handshake", stats)
+	}
+	// httptest listens on an IP address
+	if stats.DNSLookups != 0 {
+		t.Errorf("stats = %+v, want no DNS lookup", stats)
+	}
+}
+
+func TestNewTransportLimits(t *testing.T) {
+	idle, conns := MaxIdleConnsPerHost, MaxConnsPerHost
+	defer func() { MaxIdleConnsPerHost, MaxConnsPerHost = idle, conns }()
+	MaxIdleConnsPerHost, MaxConnsPerHost = 200, 50
+
+	tr := newTransport(false)
+	if tr.MaxIdleConnsPerHost != 200 || tr.MaxIdleConns != 200 || tr.MaxConnsPerHost != 50 {
+		t.Errorf("transport limits = %d idle per host, %d idle, %d per host", tr.MaxIdleConnsPerHost, tr.MaxIdleConns, tr.MaxConnsPerHost)
+	}
+}
@@ -1,6 +1,106 @@
 package appapi
 
+import (
+	"log/slog"
+	"time"
+)
+
+// Config holds the settings of appapi, see LoadConfig for the environment variables.
 type Config struct {
-	AnsibleHashiVaultRoleID   string
+	VaultAddr          string
+	VaultRoleID        string
+	VaultSecretID      string
+	VaultSumaPath      string
+	VaultMeshstackPath string
+
+	// Deprecated: use VaultRoleID, the field is only read if VaultRoleID is empty
+	AnsibleHashiVaultRoleID string
+	// Deprecated: use VaultSecretID, the field is only read if VaultSecretID is empty
 	AnsibleHashiVaultSecretID string
+	// Deprecated: use VaultAddr, the field is only read if VaultAddr is empty
+	AnsibleHashiVaultAddr string
+
+	SumaURL      string
+	SumaUsername string
+	SumaPassword string
+	SumaToken    string
+
+	MsURL          string
+	MsClientID     string
+	MsClientSecret string
+
+	// MsOIDCTokenURL or MsOIDCIssuer get the Meshstack tokens from an identity provider, see
+	// Config.OIDC
+	MsOIDCTokenURL string
+	MsOIDCIssuer   string
+	MsOIDCScopes   []string
+	MsOIDCAudience string
+
+	// Networks are the permitted networks for systems, e.g. 192.168.1.0/24
+	Networks []string
+
+	// HostnameDomain is appended to short hostnames by the SUMA clients, see HostnameNormalization
+	HostnameDomain string
+
+	// SystemMatch is the policy of the SUMA clients for ambiguous hostnames, see WithSystemMatch
+	SystemMatch SystemMatchPolicy
+
+	// SessionCachePath is the file of the encrypted session cache, empty disables the cache
+	SessionCachePath string
+	SessionCacheKey  string
+
+	Timeout time.Duration
+	Retries int
+	Verbose bool
+
+	// LogLevel and LogFormat configure the log output, see LogLevel and SetLogger
+	LogLevel  slog.Level
+	LogFormat string
+
+	// ReadCacheTTL enables the read cache of the clients, see ReadCache
+	ReadCacheTTL time.Duration
+
+	// ETagCacheSize enables the conditional requests of the Meshstack client, see ETagCache
+	ETagCacheSize int
+
+	// AllowInsecure accepts http:// URLs and skips the verification of TLS certificates
+	AllowInsecure bool
+
+	// MaxResponseSize limits the size of API responses in bytes, 0 is unlimited
+	MaxResponseSize int64
+
+	// CompressionMinSize is the size from which request bodies are compressed, 0 disables it
+	CompressionMinSize int64
+
+	// MaxIdleConnsPerHost and MaxConnsPerHost tune the reuse of the connections, see TransportStats
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+
+	// SumaMaxConcurrency and MsMaxConcurrency limit the calls in flight per backend, see
+	// WithHostConcurrency
+	SumaMaxConcurrency int
+	MsMaxConcurrency   int
+
+	// Environments are additional named endpoint sets, e.g. dev, test and prod
+	Environments map[string]Environment
+}
+
+// Environment is a named pair of SUMA and Meshstack endpoints with their credentials and networks
+type Environment struct {
+	Name        string
+	SumaURL     string
+	MsURL       string
+	Networks    []string
+	Credentials Credentials
+}
+
+// Credentials hold the login data for SUSE Manager and Meshstack
+type Credentials struct {
+	SumaUsername string
+	SumaPassword string
+	// SumaToken is a long-lived API token used instead of username and password if set, see
+	// SumaLoginWithToken
+	SumaToken      string
+	MsClientID     string
+	MsClientSecret string
 }
@@ -0,0 +1,290 @@
+package appapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// RecorderMode decides if a Recorder calls the API or replays a cassette
+type RecorderMode string
+
+// The modes of a Recorder
+const (
+	// RecordMode calls the API and records the interactions, the cassette is written by Stop
+	RecordMode RecorderMode = "record"
+	// ReplayMode answers the requests from the cassette, a request without a recorded interaction fails
+	ReplayMode RecorderMode = "replay"
+	// ReplayOrRecordMode replays an existing cassette and records a missing one
+	ReplayOrRecordMode RecorderMode = "auto"
+)
+
+// ErrNoInteraction is returned by a replaying Recorder for a request which was not recorded
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// Cassette is the file a Recorder records the interactions to
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request with its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded request. The URL is the path with the query, so a cassette is
+// replayed against any server.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a recorded response, the body is stored decompressed
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is a transport recording the API calls to a cassette and replaying them in tests, so
+// regression tests run deterministically against the response shapes of real SUMA and Meshstack
+// servers. Passwords, session cookies and tokens are redacted before they are recorded, see Redact.
+//
+//	rec, err := NewRecorder("testdata/groups.json", ReplayOrRecordMode)
+//	defer rec.Stop()
+//	suma, err := NewSumaClient(url, creds, rec.Option())
+type Recorder struct {
+	Mode RecorderMode
+	Path string
+	// Base is the transport of the recorded calls, the default transport if nil
+	Base http.RoundTripper
+	// Sanitize changes an interaction before it is recorded or a request before it is matched,
+	// e.g. to remove host names. The response is empty while a request is matched.
+	Sanitize func(*Interaction)
+	// Match reports if a request matches a recorded request, by default the method, URL and body
+	// must be equal
+	Match func(req, recorded RecordedRequest) bool
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder creates a recorder of the cassette file. A replaying recorder reads the cassette.
+func NewRecorder(path string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{Mode: mode, Path: path}
+	switch mode {
+	case RecordMode:
+		return r, nil
+	case ReplayMode, ReplayOrRecordMode:
+	default:
+		return nil, fmt.Errorf("unknown recorder mode %q", mode)
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && mode == ReplayOrRecordMode:
+		r.Mode = RecordMode
+		return r, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("could not read cassette %s: %v", path, err)
+	}
+	r.Mode = ReplayMode
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Option returns the option sending the calls through the recorder, see WithTransport
+func (r *Recorder) Option() Option {
+	return WithTransport(r)
+}
+
+// Recording reports if the recorder calls the API instead of replaying the cassette
+func (r *Recorder) Recording() bool {
+	return r.Mode == RecordMode
+}
+
+// Interactions returns the recorded or replayed interactions
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// Unused returns the interactions of a replaying recorder which were not replayed, e.g. to fail a
+// test if the client stopped making a recorded call
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, used := range r.used {
+		if !used {
+			unused = append(unused, r.cassette.Interactions[i])
+		}
+	}
+	return unused
+}
+
+// Stop writes the cassette of a recording recorder, the directory is created if needed
+func (r *Recorder) Stop() error {
+	if !r.Recording() {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.Path, append(data, '\n'), 0o644)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	in := Interaction{Request: RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Header: recordedHeader(req.Header),
+		Body:   body,
+	}}
+
+	if !r.Recording() {
+		return r.replay(req, r.sanitize(in).Request)
+	}
+
+	base := r.Base
+	if base == nil {
+		base = defaultTransport(AllowInsecure)
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readResponseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	header := resp.Header.Clone()
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	in.Response = RecordedResponse{StatusCode: resp.StatusCode, Header: header, Body: respBody}
+	recorded := r.sanitize(in)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, recorded)
+	r.mu.Unlock()
+
+	// the caller gets the real response, the cassette the redacted one
+	resp.Header = header
+	resp.Body = io.NopCloser(strings.NewReader(respBody))
+	resp.ContentLength = int64(len(respBody))
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// replay returns the response of the first unused interaction matching the request
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	match := r.Match
+	if match == nil {
+		match = matchRecordedRequest
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || !match(recorded, in.Request) {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w for %s %s in %s", ErrNoInteraction, recorded.Method, recorded.URL, r.Path)
+}
+
+func matchRecordedRequest(req, recorded RecordedRequest) bool {
+	return req.Method == recorded.Method && req.URL == recorded.URL && req.Body == recorded.Body
+}
+
+// sanitize returns a copy of the interaction with the secrets redacted and Sanitize applied
+func (r *Recorder) sanitize(in Interaction) Interaction {
+	in.Request.URL = Redact(in.Request.URL)
+	in.Request.Body = Redact(in.Request.Body)
+	in.Request.Header = RedactHeader(in.Request.Header)
+	in.Response.Body = Redact(in.Response.Body)
+	in.Response.Header = RedactHeader(in.Response.Header)
+	if r.Sanitize != nil {
+		r.Sanitize(&in)
+	}
+	return in
+}
+
+// recordedHeader returns the headers worth recording, the request ID and the compression change on
+// every call
+func recordedHeader(h http.Header) http.Header {
+	header := h.Clone()
+	for _, key := range []string{requestIDHeader, "Accept-Encoding", "User-Agent"} {
+		header.Del(key)
+	}
+	return header
+}
+
+// readRequestBody reads the body of a request and replaces it, so it can still be sent
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
+
+// readResponseBody reads and closes the body of a response, a gzip compressed body is decompressed
+func readResponseBody(resp *http.Response) (string, error) {
+	defer resp.Body.Close()
+	var body io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("error decompressing response: %v", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+	data, err := io.ReadAll(body)
+	return string(data), err
+}
@@ -0,0 +1,150 @@
+package appapi
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newRecordedSumaServer is a SUMA with a login, which answers listAllGroups gzip compressed
+func newRecordedSumaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rhn/manager/api/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "pxt-session-cookie", Value: "s3cr3t-session", MaxAge: 3600})
+		_, _ = w.Write([]byte(`{"success": true, "result": 1}`))
+	})
+	mux.HandleFunc("/rhn/manager/api/auth/logout", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success": true, "result": 1}`))
+	})
+	mux.HandleFunc("/rhn/manager/api/api/systemVersion", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success": true, "result": "5.0.3"}`))
+	})
+	mux.HandleFunc("/rhn/manager/api/systemgroup/listAllGroups", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("pxt-session-cookie"); err != nil || c.Value != "s3cr3t-session" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(`{"success": true, "result": [{"name": "web"}, {"name": "db"}]}`))
+		_ = gz.Close()
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	server := newRecordedSumaServer(t)
+	path := filepath.Join(t.TempDir(), "cassettes", "groups.json")
+	creds := NewStaticCredentialProvider(Credentials{SumaUsername: "admin", SumaPassword: "p4ssw0rd"})
+
+	rec, err := NewRecorder(path, ReplayOrRecordMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Recording() {
+		t.Fatal("a missing cassette must be recorded")
+	}
+	c, err := NewSumaClient(server.URL, creds, rec.Option(), WithInsecure(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := c.SystemGroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"p4ssw0rd", "s3cr3t-session"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("the cassette contains the secret %s:\n%s", secret, data)
+		}
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(cassette.Interactions); n != 4 {
+		t.Errorf("recorded %d interactions, want login, version, listAllGroups and logout", n)
+	}
+	if body := cassette.Interactions[2].Response.Body; !strings.Contains(body, `"web"`) {
+		t.Errorf("the compressed response is not recorded decompressed: %q", body)
+	}
+
+	// the server is closed, the calls are answered from the cassette
+	rec, err = NewRecorder(path, ReplayOrRecordMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Recording() {
+		t.Fatal("an existing cassette must be replayed")
+	}
+	c, err = NewSumaClient(server.URL, creds, rec.Option(), WithInsecure(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := c.SystemGroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(replayed, recorded) {
+		t.Errorf("replayed %v, recorded %v", replayed, recorded)
+	}
+
+	// every interaction is replayed once
+	if _, err := c.SystemGroups(); err == nil || !strings.Contains(err.Error(), ErrNoInteraction.Error()) {
+		t.Errorf("err = %v, want %v", err, ErrNoInteraction)
+	}
+}
+
+func TestRecorderReplayMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := Cassette{Interactions: []Interaction{{
+		Request:  RecordedRequest{Method: http.MethodPost, URL: "/api/login", Body: "client_id=id&client_secret=[REDACTED]&grantType=client_credentials"},
+		Response: RecordedResponse{StatusCode: http.StatusOK, Body: `{"access_token": "[REDACTED]", "expires_in": 60}`},
+	}}}
+	data, _ := json.Marshal(cassette)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := NewRecorder(path, ReplayMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the secret of the request is redacted before it is matched
+	token, err := MsLogin("id", "any-secret", "https://meshstack.example.com", false, rec.Option())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != redacted {
+		t.Errorf("token = %q, want %q", token, redacted)
+	}
+
+	_, err = MsLogin("other", "any-secret", "https://meshstack.example.com", false, rec.Option())
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("err = %v, want ErrNoInteraction", err)
+	}
+
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ReplayMode); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("replaying a missing cassette: err = %v", err)
+	}
+	if _, err := NewRecorder(path, "rewind"); err == nil {
+		t.Error("an unknown mode must be rejected")
+	}
+}
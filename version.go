@@ -0,0 +1,167 @@
+package appapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ServerVersion is the product version of a SUSE Manager, e.g. 4.3.12 or 5.0.2. The zero value is an
+// unknown version.
+type ServerVersion struct {
+	Major int
+	Minor int
+	Patch int
+	Raw   string
+}
+
+// ParseServerVersion parses a version like 4.3.12, missing minor and patch versions are 0 and a
+// suffix of the patch version, e.g. 5.0.2-beta1 or 4.3.12.1, is ignored
+func ParseServerVersion(s string) (ServerVersion, error) {
+	v := ServerVersion{Raw: s}
+	parts := strings.SplitN(strings.TrimSpace(s), ".", 3)
+	fields := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		digits := part
+		if i == 2 {
+			if end := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+				digits = part[:end]
+			}
+		}
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return ServerVersion{}, fmt.Errorf("invalid server version %q", s)
+		}
+		*fields[i] = n
+	}
+	return v, nil
+}
+
+// Known reports if the version was detected
+func (v ServerVersion) Known() bool {
+	return v.Major > 0
+}
+
+// AtLeast reports if the version is major.minor or newer
+func (v ServerVersion) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+func (v ServerVersion) String() string {
+	if !v.Known() {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Feature is a group of SUMA API methods which is only available from a product version on
+type Feature struct {
+	Name     string
+	MinMajor int
+	MinMinor int
+}
+
+// The features which are gated by the SUMA version
+var (
+	FeatureContentManagement = Feature{Name: "contentmanagement", MinMajor: 4, MinMinor: 0}
+	FeatureAnsible           = Feature{Name: "ansible", MinMajor: 4, MinMinor: 3}
+	FeatureProxyContainer    = Feature{Name: "proxy container configuration", MinMajor: 5, MinMinor: 0}
+	FeatureTokenAuth         = Feature{Name: "token authentication", MinMajor: 5, MinMinor: 1}
+)
+
+// ErrUnsupportedByServer is returned when an API method is not supported by the version of the server
+var ErrUnsupportedByServer = errors.New("unsupported by server")
+
+// UnsupportedError is returned for a feature the server version does not support, it matches
+// ErrUnsupportedByServer
+type UnsupportedError struct {
+	Feature Feature
+	Version ServerVersion
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s is unsupported by server: SUSE Manager %s, requires %d.%d or newer", e.Feature.Name, e.Version, e.Feature.MinMajor, e.Feature.MinMinor)
+}
+
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupportedByServer
+}
+
+// Supports returns an UnsupportedError if the feature needs a newer version. An unknown version
+// supports all features, the server answers for itself then.
+func (v ServerVersion) Supports(feature Feature) error {
+	if !v.Known() || v.AtLeast(feature.MinMajor, feature.MinMinor) {
+		return nil
+	}
+	return &UnsupportedError{Feature: feature, Version: v}
+}
+
+// SumaGetVersion returns the product version of the SUSE Manager
+func SumaGetVersion(sessioncookie, susemgr string, verbose bool, opts ...Option) (version ServerVersion, err error) {
+	var raw string
+	if err := sumaGet(sessioncookie, susemgr, "api/systemVersion", nil, &raw, verbose, opts...); err != nil {
+		return version, err
+	}
+	if verbose {
+		logDebugf("SUMAAPI SumaGetVersion: systemVersion = %s", raw)
+	}
+	return ParseServerVersion(raw)
+}
+
+// MsHealth is the health of the Meshstack API
+type MsHealth struct {
+	Status  string `json:"status"`
+	Version string `json:"version,omitempty"`
+}
+
+// MsGetHealth returns the health of the Meshstack API, it needs no login
+func MsGetHealth(apiurl string, verbose bool, opts ...Option) (health MsHealth, err error) {
+
+	var functionname string = "MsGetHealth"
+
+	apiMethod := fmt.Sprintf("%s/api/health", apiurl)
+	if verbose {
+		logDebugf("MSAPI %s: apiMethod = %s", functionname, apiMethod)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, apiMethod, nil)
+	if err != nil {
+		return health, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	// the probe reports an overloaded Meshstack instead of waiting for it
+	opts = append(opts[:len(opts):len(opts)], WithRetry(RetryPolicy{}))
+	resp, err := newCallOptions(verbose, opts).do(req)
+	if err != nil {
+		return health, err
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logErrorf("error closing response body: %v", err)
+		}
+	}()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return health, err
+	}
+
+	if verbose {
+		logDebugf("MSAPI %s: Got resp.Body = %s", functionname, string(bodyBytes))
+	}
+
+	// an unhealthy Meshstack answers with 503 and the status in the body
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return health, statusError(resp)
+	}
+	if err := json.Unmarshal(bodyBytes, &health); err != nil {
+		return health, withRequestID(resp, fmt.Errorf("error unmarshaling JSON: %v", err))
+	}
+	return health, nil
+}
@@ -0,0 +1,124 @@
+package appapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ServerVersion
+		wantErr bool
+	}{
+		{in: "4.3.12", want: ServerVersion{Major: 4, Minor: 3, Patch: 12}},
+		{in: "5.0.2-beta1", want: ServerVersion{Major: 5, Minor: 0, Patch: 2}},
+		{in: "4.3.12.1", want: ServerVersion{Major: 4, Minor: 3, Patch: 12}},
+		{in: "5", want: ServerVersion{Major: 5}},
+		{in: "", wantErr: true},
+		{in: "five.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseServerVersion(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseServerVersion(%q) expected error", tt.in)
+			}
+			continue
+		}
+		tt.want.Raw = tt.in
+		if err != nil || got != tt.want {
+			t.Errorf("ParseServerVersion(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestServerVersionSupports(t *testing.T) {
+	suma43 := ServerVersion{Major: 4, Minor: 3, Patch: 12}
+	suma50 := ServerVersion{Major: 5, Minor: 0, Patch: 2}
+
+	if err := suma43.Supports(FeatureAnsible); err != nil {
+		t.Errorf("expected 4.3 to support ansible, got %v", err)
+	}
+	if err := (ServerVersion{}).Supports(FeatureProxyContainer); err != nil {
+		t.Errorf("expected an unknown version to support all features, got %v", err)
+	}
+	if err := suma50.Supports(FeatureProxyContainer); err != nil {
+		t.Errorf("expected 5.0 to support the proxy container configuration, got %v", err)
+	}
+
+	err := suma43.Supports(FeatureProxyContainer)
+	if !errors.Is(err, ErrUnsupportedByServer) {
+		t.Fatalf("expected ErrUnsupportedByServer, got %v", err)
+	}
+	want := "proxy container configuration is unsupported by server: SUSE Manager 4.3.12, requires 5.0 or newer"
+	if err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+}
+
+func TestSumaGetVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rhn/manager/api/api/systemVersion" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		io.WriteString(w, `{"success": true, "result": "5.0.2"}`)
+	}))
+	defer server.Close()
+
+	v, err := SumaGetVersion("cookie", server.URL, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.String() != "5.0.2" || !v.AtLeast(5, 0) {
+		t.Errorf("got version %s, want 5.0.2", v)
+	}
+}
+
+func TestMsGetHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus string
+		wantErr    bool
+	}{
+		{name: "healthy", status: http.StatusOK, body: `{"status": "UP", "version": "2024.1"}`, wantStatus: "UP"},
+		{name: "unhealthy", status: http.StatusServiceUnavailable, body: `{"status": "DOWN"}`, wantStatus: "DOWN"},
+		{name: "not found", status: http.StatusNotFound, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.Header.Get("Authorization") != "" {
+					t.Errorf("expected no authorization, got %q", r.Header.Get("Authorization"))
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			health, err := MsGetHealth(server.URL, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", health)
+				}
+				return
+			}
+			if err != nil || health.Status != tt.wantStatus {
+				t.Errorf("MsGetHealth() = %+v, %v, want status %s", health, err, tt.wantStatus)
+			}
+			if calls != 1 {
+				t.Errorf("expected 1 call, got %d", calls)
+			}
+		})
+	}
+}